@@ -0,0 +1,13 @@
+package anonymization_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestAnonymization(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Anonymization Suite")
+}
@@ -0,0 +1,77 @@
+package anonymization
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"unicode"
+)
+
+// entities are the personal data recognized within free texts, in order of matching, along with their placeholders.
+// Emails and URLs go first, so their digits are not mistaken for phone numbers.
+var entities = []struct {
+	pattern     *regexp.Regexp
+	placeholder string
+	// minDigits tells numbers apart from other numeric values, e.g. dates or amounts.
+	minDigits int
+}{
+	{regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), "[EMAIL]", 0},
+	{regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"]+`), "[URL]", 0},
+	{regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`), "[IP]", 0},
+	{regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`), "[CARD]", 0},
+	{regexp.MustCompile(`\+?\(?\d[\d ().-]{5,}\d`), "[PHONE]", 9},
+}
+
+// Anonymizer strips personal data from datasets while keeping them useful for analytics. Identifiers are replaced by
+// stable pseudonyms, so the same person gets the same pseudonym everywhere, and personal data within free texts is
+// replaced by placeholders of its kind.
+type Anonymizer struct {
+	key []byte
+}
+
+// NewAnonymizer returns back a newly created and ready to use Anonymizer. Pseudonyms can not be reversed without the
+// key, an empty key leaves them open to guessing of known identifiers.
+func NewAnonymizer(key string) *Anonymizer {
+	return &Anonymizer{key: []byte(key)}
+}
+
+// Pseudonym returns back the stable pseudonym of an identifier, prefixed with its kind, e.g. owner-1a2b3c4d5e6f.
+// Empty identifiers stay empty.
+func (a *Anonymizer) Pseudonym(kind, identifier string) string {
+	if identifier == "" {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, a.key)
+	_, _ = mac.Write([]byte(identifier))
+
+	return kind + "-" + hex.EncodeToString(mac.Sum(nil))[:12]
+}
+
+// Redact replaces the emails, URLs, IP addresses, card and phone numbers within the text by placeholders.
+func (a *Anonymizer) Redact(text string) string {
+	for _, entity := range entities {
+		minDigits, placeholder := entity.minDigits, entity.placeholder
+		text = entity.pattern.ReplaceAllStringFunc(text, func(match string) string {
+			if digits(match) < minDigits {
+				return match
+			}
+
+			return placeholder
+		})
+	}
+
+	return text
+}
+
+func digits(text string) int {
+	count := 0
+	for _, r := range text {
+		if unicode.IsDigit(r) {
+			count++
+		}
+	}
+
+	return count
+}
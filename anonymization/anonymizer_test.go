@@ -0,0 +1,43 @@
+package anonymization_test
+
+import (
+	"github.com/jibitters/kiosk/anonymization"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Anonymizer", func() {
+	anonymizer := anonymization.NewAnonymizer("key")
+
+	Context("When Pseudonym called", func() {
+		It("Should return the same pseudonym for the same identifier and key only", func() {
+			pseudonym := anonymizer.Pseudonym("owner", "user1@example.com")
+
+			Ω(pseudonym).Should(MatchRegexp(`^owner-[0-9a-f]{12}$`))
+			Ω(anonymizer.Pseudonym("owner", "user1@example.com")).Should(Equal(pseudonym))
+			Ω(anonymizer.Pseudonym("owner", "user2@example.com")).ShouldNot(Equal(pseudonym))
+			Ω(anonymization.NewAnonymizer("another").Pseudonym("owner", "user1@example.com")).
+				ShouldNot(Equal(pseudonym))
+		})
+
+		It("Should keep empty identifiers empty", func() {
+			Ω(anonymizer.Pseudonym("owner", "")).Should(BeEmpty())
+		})
+	})
+
+	Context("When Redact called", func() {
+		It("Should replace personal data by placeholders and keep the rest", func() {
+			text := "Mail me at john.doe@example.com or call +98 912 345 6789, see https://example.com/a?b=c " +
+				"from 10.0.0.1 paid by 4111 1111 1111 1111 for order 42"
+
+			Ω(anonymizer.Redact(text)).Should(Equal("Mail me at [EMAIL] or call [PHONE], see [URL] " +
+				"from [IP] paid by [CARD] for order 42"))
+		})
+
+		It("Should keep dates, times and amounts", func() {
+			text := "Charged 1,250.00 on 2020-01-02 at 10:30"
+
+			Ω(anonymizer.Redact(text)).Should(Equal(text))
+		})
+	})
+})
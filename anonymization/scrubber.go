@@ -0,0 +1,112 @@
+package anonymization
+
+import (
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Masked replaces the values of scrubbed fields.
+const Masked = "[REDACTED]"
+
+// Scrubber masks the values of configured fields, e.g. owner, within log entries before they are emitted, so customer
+// identifiers do not leak into log platforms. Logs mostly embed requests into their messages, so the fields are masked
+// wherever they appear as JSON members, e.g. "owner":"...", or as query parameters, e.g. owner=..., as well as in
+// structured fields of the same keys.
+type Scrubber struct {
+	keys       map[string]bool
+	patterns   []*regexp.Regexp
+	anonymizer *Anonymizer
+}
+
+// NewScrubber returns back a newly created and ready to use Scrubber masking the fields, matched case insensitively.
+// With redact the emails, URLs, IP addresses, card and phone numbers within the rest of the entries are replaced by
+// placeholders as well, see Anonymizer.Redact, at the risk of catching numeric identifiers too.
+func NewScrubber(fields []string, redact bool) *Scrubber {
+	s := &Scrubber{keys: make(map[string]bool, len(fields))}
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		s.keys[strings.ToLower(field)] = true
+
+		quoted := regexp.QuoteMeta(field)
+		s.patterns = append(s.patterns,
+			regexp.MustCompile(`(?i)("`+quoted+`"\s*:\s*)(?:"(?:[^"\\]|\\.)*"|[^\s,}\]]+)`),
+			regexp.MustCompile(`(?i)(\b`+quoted+`=)[^\s&]*`))
+	}
+
+	if redact {
+		s.anonymizer = NewAnonymizer("")
+	}
+
+	return s
+}
+
+// Scrub masks the fields within a text.
+func (s *Scrubber) Scrub(text string) string {
+	for i, pattern := range s.patterns {
+		mask := `${1}` + Masked
+		if i%2 == 0 {
+			mask = `${1}"` + Masked + `"`
+		}
+
+		text = pattern.ReplaceAllString(text, mask)
+	}
+
+	if s.anonymizer != nil {
+		text = s.anonymizer.Redact(text)
+	}
+
+	return text
+}
+
+// Wrap wraps a core so the entries written to it get scrubbed first, e.g. zap.WrapCore(scrubber.Wrap).
+func (s *Scrubber) Wrap(core zapcore.Core) zapcore.Core {
+	return &scrubbingCore{Core: core, scrubber: s}
+}
+
+func (s *Scrubber) scrubFields(fields []zapcore.Field) []zapcore.Field {
+	scrubbed := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		switch {
+		case s.keys[strings.ToLower(field.Key)]:
+			scrubbed[i] = zap.String(field.Key, Masked)
+		case field.Type == zapcore.StringType:
+			scrubbed[i] = zap.String(field.Key, s.Scrub(field.String))
+		case field.Type == zapcore.ErrorType:
+			scrubbed[i] = zap.String(field.Key, s.Scrub(field.Interface.(error).Error()))
+		default:
+			scrubbed[i] = field
+		}
+	}
+
+	return scrubbed
+}
+
+// scrubbingCore scrubs the messages and fields of entries before writing them to the wrapped core.
+type scrubbingCore struct {
+	zapcore.Core
+	scrubber *Scrubber
+}
+
+func (c *scrubbingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &scrubbingCore{Core: c.Core.With(c.scrubber.scrubFields(fields)), scrubber: c.scrubber}
+}
+
+func (c *scrubbingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+
+	return checked
+}
+
+func (c *scrubbingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = c.scrubber.Scrub(entry.Message)
+	return c.Core.Write(entry, c.scrubber.scrubFields(fields))
+}
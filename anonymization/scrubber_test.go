@@ -0,0 +1,62 @@
+package anonymization_test
+
+import (
+	"errors"
+
+	"github.com/jibitters/kiosk/anonymization"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+var _ = Describe("Scrubber", func() {
+	scrubber := anonymization.NewScrubber([]string{"owner", "phone"}, false)
+
+	Context("When Scrub called", func() {
+		It("Should mask the fields within JSON and query parameters and keep the rest", func() {
+			text := `Could not parse json: {"issuer":"Microservice-A","Owner":"john.doe@example.com","phone":98912,` +
+				`"content":"say \"hi\""} for /v1/tickets?owner=john.doe%40example.com&status=NEW`
+
+			Ω(scrubber.Scrub(text)).Should(Equal(`Could not parse json: {"issuer":"Microservice-A",` +
+				`"Owner":"[REDACTED]","phone":"[REDACTED]","content":"say \"hi\""} ` +
+				`for /v1/tickets?owner=[REDACTED]&status=NEW`))
+		})
+
+		It("Should mask escaped quotes within values as a whole", func() {
+			Ω(scrubber.Scrub(`{"owner":"john \"the\" doe","subject":"Hi"}`)).
+				Should(Equal(`{"owner":"[REDACTED]","subject":"Hi"}`))
+		})
+
+		It("Should keep fields whose names only contain the configured ones", func() {
+			text := `{"coowner":"jane@example.com","owners":"x"} ?coowner=jane`
+
+			Ω(scrubber.Scrub(text)).Should(Equal(text))
+		})
+
+		It("Should redact personal data within the rest when asked to", func() {
+			redacting := anonymization.NewScrubber([]string{"owner"}, true)
+
+			Ω(redacting.Scrub(`{"owner":"a@example.com"} reported by b@example.com`)).
+				Should(Equal(`{"owner":"[REDACTED]"} reported by [EMAIL]`))
+		})
+	})
+
+	Context("When Wrap called", func() {
+		It("Should scrub the messages and fields of entries before writing them", func() {
+			core, logs := observer.New(zapcore.InfoLevel)
+			logger := zap.New(core).WithOptions(zap.WrapCore(scrubber.Wrap)).Sugar()
+
+			logger.With("owner", "john@example.com").Warnw(`request {"owner":"john@example.com"}`,
+				"phone", 98912, "body", `{"phone":"+98912"}`, "error", errors.New("owner=john"))
+			logger.Debug(`{"owner":"john@example.com"}`)
+
+			Ω(logs.Len()).Should(Equal(1))
+			entry := logs.All()[0]
+			Ω(entry.Message).Should(Equal(`request {"owner":"[REDACTED]"}`))
+			Ω(entry.ContextMap()).Should(Equal(map[string]interface{}{"owner": "[REDACTED]",
+				"phone": "[REDACTED]", "body": `{"phone":"[REDACTED]"}`, "error": "owner=[REDACTED]"}))
+		})
+	})
+})
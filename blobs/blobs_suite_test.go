@@ -0,0 +1,13 @@
+package blobs_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestBlobs(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Blobs Suite")
+}
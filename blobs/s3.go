@@ -0,0 +1,192 @@
+// Package blobs stores the contents of attachments outside of the database, in S3 or compatible object storages,
+// e.g. minio.
+package blobs
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lireza/lib/configuring"
+	"go.uber.org/zap"
+)
+
+// Store stores blobs by their keys.
+type Store interface {
+	// Put stores size bytes of the content under the key, replacing the blob stored under it, if any.
+	Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error
+
+	// Get loads up to length bytes of the blob stored under the key, starting from offset.
+	Get(ctx context.Context, key string, offset, length int64) ([]byte, error)
+
+	// Delete deletes the blob stored under the key, if any.
+	Delete(ctx context.Context, key string) error
+}
+
+// emptyPayloadHash is the hash of the empty payloads of the requests other than Put.
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// S3 stores blobs as the objects of a bucket, addressed in path style, e.g. http://localhost:9000/bucket/key, so
+// storages other than S3 work as well. Requests are signed with AWS signature version 4.
+type S3 struct {
+	endpoint  string
+	bucket    string
+	region    string
+	accessKey string
+	secretKey string
+	client    *http.Client
+}
+
+// NewS3 returns back a newly created and ready to use S3 store.
+func NewS3(endpoint, bucket, region, accessKey, secretKey string) *S3 {
+	return &S3{endpoint: strings.TrimSuffix(endpoint, "/"), bucket: bucket, region: region, accessKey: accessKey,
+		secretKey: secretKey, client: &http.Client{Timeout: time.Minute}}
+}
+
+// Configured returns back the S3 store configured in config instance, or nil when no bucket is configured, in which
+// case blobs stay in the database.
+func Configured(logger *zap.SugaredLogger, config *configuring.Config) Store {
+	return ConfiguredAt(logger, config, "attachments.s3")
+}
+
+// ConfiguredAt returns back the S3 store configured under the path in config instance, e.g. attachments.s3, or nil
+// when no bucket is configured.
+func ConfiguredAt(logger *zap.SugaredLogger, config *configuring.Config, path string) Store {
+	endpoint := config.Get(path + ".endpoint").StringOrElse("https://s3.amazonaws.com")
+	bucket := config.Get(path + ".bucket").StringOrElse("")
+	region := config.Get(path + ".region").StringOrElse("us-east-1")
+	accessKey := config.Get(path + ".access_key").StringOrElse("")
+	secretKey := config.Get(path + ".secret_key").StringOrElse("")
+
+	logger.Info(path+".endpoint -> ", endpoint)
+	logger.Info(path+".bucket -> ", bucket)
+	logger.Info(path+".region -> ", region)
+
+	if bucket == "" {
+		return nil
+	}
+
+	return NewS3(endpoint, bucket, region, accessKey, secretKey)
+}
+
+// Put uploads the content in a single request, streaming it rather than buffering, so its payload is left unsigned.
+func (s *S3) Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error {
+	request, e := http.NewRequestWithContext(ctx, http.MethodPut, s.url(key), content)
+	if e != nil {
+		return e
+	}
+
+	request.ContentLength = size
+	request.Header.Set("Content-Type", contentType)
+	s.Sign(request, "UNSIGNED-PAYLOAD", time.Now())
+
+	_, e = s.do(request)
+	return e
+}
+
+// Get downloads the range of the object.
+func (s *S3) Get(ctx context.Context, key string, offset, length int64) ([]byte, error) {
+	request, e := http.NewRequestWithContext(ctx, http.MethodGet, s.url(key), nil)
+	if e != nil {
+		return nil, e
+	}
+
+	request.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	s.Sign(request, emptyPayloadHash, time.Now())
+
+	return s.do(request)
+}
+
+// Delete deletes the object, deleting missing objects succeeds as well.
+func (s *S3) Delete(ctx context.Context, key string) error {
+	request, e := http.NewRequestWithContext(ctx, http.MethodDelete, s.url(key), nil)
+	if e != nil {
+		return e
+	}
+
+	s.Sign(request, emptyPayloadHash, time.Now())
+
+	_, e = s.do(request)
+	return e
+}
+
+func (s *S3) url(key string) string {
+	return s.endpoint + "/" + s.bucket + "/" + key
+}
+
+// do sends the signed request and returns back the body of its response. Non 2xx responses are errors.
+func (s *S3) do(request *http.Request) ([]byte, error) {
+	response, e := s.client.Do(request)
+	if e != nil {
+		return nil, e
+	}
+	defer response.Body.Close()
+
+	body, e := ioutil.ReadAll(response.Body)
+	if e != nil {
+		return nil, e
+	}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return nil, fmt.Errorf("%v %v: %v", request.Method, request.URL.Path, response.Status)
+	}
+
+	return body, nil
+}
+
+// Sign signs the request as of the provided time with AWS signature version 4, covering its host, Range, Content-Type
+// and x-amz-* headers along with the hash of its payload.
+func (s *S3) Sign(request *http.Request, payloadHash string, at time.Time) {
+	timestamp := at.UTC().Format("20060102T150405Z")
+	scope := timestamp[:8] + "/" + s.region + "/s3/aws4_request"
+
+	request.Header.Set("X-Amz-Date", timestamp)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": request.URL.Host}
+	for name, values := range request.Header {
+		name = strings.ToLower(name)
+		if name == "range" || name == "content-type" || strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	canonicalHeaders := &strings.Builder{}
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{request.Method, request.URL.EscapedPath(), request.URL.Query().Encode(),
+		canonicalHeaders.String(), signedHeaders, payloadHash}, "\n")
+	hashedRequest := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + timestamp + "\n" + scope + "\n" + hex.EncodeToString(hashedRequest[:])
+
+	key := []byte("AWS4" + s.secretKey)
+	for _, part := range []string{timestamp[:8], s.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%v/%v, SignedHeaders=%v, Signature=%v",
+		s.accessKey, scope, signedHeaders, hex.EncodeToString(hmacSHA256(key, stringToSign))))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxBackoff caps the delay between two attempts of reaching a dependency.
+const maxBackoff = 10 * time.Second
+
+// waitFor calls attempt until it succeeds or timeout elapses, backing off exponentially between attempts. Once the
+// timeout elapses the last failure is returned back, naming the dependency so the operator knows what to check.
+func waitFor(logger *zap.SugaredLogger, dependency string, timeout time.Duration, attempt func() error) error {
+	deadline := time.Now().Add(timeout)
+	backoff := 500 * time.Millisecond
+
+	for {
+		e := attempt()
+		if e == nil {
+			return nil
+		}
+
+		if time.Now().Add(backoff).After(deadline) {
+			return fmt.Errorf("%s is not reachable after %s: %w", dependency, timeout, e)
+		}
+
+		logger.Warn(dependency, " is not reachable yet, retrying in ", backoff, ": ", e.Error())
+		time.Sleep(backoff)
+
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
@@ -2,18 +2,31 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"flag"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
+	"time"
 
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/anonymization"
+	"github.com/jibitters/kiosk/blobs"
+	"github.com/jibitters/kiosk/connectors"
 	"github.com/jibitters/kiosk/db/postgres"
+	"github.com/jibitters/kiosk/jobs"
+	"github.com/jibitters/kiosk/mailing"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/pagination"
+	"github.com/jibitters/kiosk/scheduler"
 	"github.com/jibitters/kiosk/services"
 	"github.com/jibitters/kiosk/web"
+	"github.com/jibitters/kiosk/web/data"
 	"github.com/lireza/lib/configuring"
 	nc "github.com/nats-io/nats.go"
 	"go.uber.org/zap"
@@ -21,27 +34,103 @@ import (
 
 var config = flag.String("config", "./configs/kiosk.json", "configuration file")
 
+// schedulerLeadershipKey is the advisory lock key kiosk instances compete for to run the scheduled jobs.
+const schedulerLeadershipKey = 0x6b696f736b
+
 // Kiosk is the main program encapsulation that holds all required components.
 type Kiosk struct {
 	logger     *zap.SugaredLogger
 	config     *configuring.Config
 	db         *pgxpool.Pool
+	replica    *pgxpool.Pool
+	reporting  *pgxpool.Pool
+	readOnly   *pgxpool.Pool
+	shadow     *pgxpool.Pool
 	natsClient *nc.Conn
+	mailer     *mailing.Mailer
+	scheduler  *scheduler.Scheduler
+	elector    *postgres.Elector
+	jobsPool   *jobs.Pool
+	// pageTokens issues and verifies the page tokens of all lists.
+	pageTokens *pagination.Tokens
+	// identities enriches the owners of comments and assignees of tickets of all responses.
+	identities *services.Identities
+	// instance identifies this process among all kiosk instances.
+	instance string
+	// waitTimeout bounds how long the process waits for its dependencies to become reachable on startup.
+	waitTimeout time.Duration
 	// TODO: Should we use interface for service layer components?
-	ticketService  *services.TicketService
-	commentService *services.CommentService
-	webServer      *http.Server
+	ticketService       *services.TicketService
+	commentService      *services.CommentService
+	reportService       *services.ReportService
+	issuerService       *services.IssuerService
+	notificationService *services.NotificationService
+	referenceService    *services.ReferenceService
+	pagingService       *services.PagingService
+	statusPageService   *services.StatusPageService
+	draftService        *services.DraftService
+	webhookService      *services.WebhookService
+	escalationService   *services.EscalationService
+	approvalService     *services.ApprovalService
+	sentimentService    *services.SentimentService
+	runtimeService      *services.RuntimeService
+	tenantService       *services.TenantService
+	transferService     *services.TransferService
+	attachmentService   *services.AttachmentService
+	adminService        *services.AdminService
+	webServer           *http.Server
 }
 
 func main() {
 	kiosk := setup()
 
 	kiosk.configure()
+
+	// Transfers go through the APIs of both deployments, so they need nothing else.
+	if flag.Arg(0) == "transfer" {
+		kiosk.transfer(flag.Args()[1:])
+		return
+	}
+
+	kiosk.configureContentLimits()
 	kiosk.connectToDatabase()
 	kiosk.migrateDatabase()
+
+	if flag.Arg(0) == "migrate" {
+		kiosk.stop()
+		return
+	}
+
+	if flag.Arg(0) == "seed" {
+		kiosk.seed(flag.Args()[1:])
+		return
+	}
+
 	kiosk.prepareNatsClient()
+	kiosk.prepareMailer()
+	kiosk.prepareJobsPool()
+	kiosk.preparePageTokens()
+	kiosk.prepareIdentities()
+	kiosk.startRuntimeService()
 	kiosk.startTicketService()
 	kiosk.startCommentService()
+	kiosk.startReportService()
+	kiosk.startIssuerService()
+	kiosk.startNotificationService()
+	kiosk.startReferenceService()
+	kiosk.startPagingService()
+	kiosk.startStatusPageService()
+	kiosk.startDraftService()
+	kiosk.startWebhookService()
+	kiosk.startEscalationService()
+	kiosk.startApprovalService()
+	kiosk.startSentimentService()
+	kiosk.startTenantService()
+	kiosk.startTransferService()
+	kiosk.startAttachmentService()
+	kiosk.startAdminService()
+	kiosk.startJobsPool()
+	kiosk.startScheduler()
 	kiosk.startWebServer()
 
 	kiosk.awaitTermination()
@@ -68,20 +157,96 @@ func (k *Kiosk) configure() {
 		logger, _ := zap.NewProduction()
 		k.logger = logger.Sugar()
 	}
+
+	scrubbedFields := k.config.Get("logger.scrubbing.fields").SliceOfStringOrElse([]string{"owner", "email", "phone"})
+	redact := k.config.Get("logger.scrubbing.redact").StringOrElse("false") == "true"
+	k.logger.Info("logger.scrubbing.fields -> ", scrubbedFields)
+	k.logger.Info("logger.scrubbing.redact -> ", redact)
+
+	scrubber := anonymization.NewScrubber(scrubbedFields, redact)
+	k.logger = k.logger.Desugar().WithOptions(zap.WrapCore(scrubber.Wrap)).Sugar()
+
+	hostname, _ := os.Hostname()
+	k.instance = k.config.Get("instance.id").StringOrElse(hostname + "-" + strconv.Itoa(os.Getpid()))
+	k.logger.Info("instance.id -> ", k.instance)
+
+	k.waitTimeout = k.config.Get("boot.wait_timeout").DurationOrElse(time.Minute)
+	k.logger.Info("boot.wait_timeout -> ", k.waitTimeout)
+}
+
+func (k *Kiosk) configureContentLimits() {
+	limits := data.DefaultContentLimits()
+	limits.TicketContentCharacters = k.config.Get("content.ticket_max_characters").
+		IntOrElse(limits.TicketContentCharacters)
+	limits.CommentContentCharacters = k.config.Get("content.comment_max_characters").
+		IntOrElse(limits.CommentContentCharacters)
+	limits.ContentWords = k.config.Get("content.max_words").IntOrElse(limits.ContentWords)
+	limits.PreviewCharacters = k.config.Get("content.preview_characters").IntOrElse(limits.PreviewCharacters)
+
+	k.logger.Info("content.ticket_max_characters -> ", limits.TicketContentCharacters)
+	k.logger.Info("content.comment_max_characters -> ", limits.CommentContentCharacters)
+	k.logger.Info("content.max_words -> ", limits.ContentWords)
+	k.logger.Info("content.preview_characters -> ", limits.PreviewCharacters)
+
+	data.SetContentLimits(limits)
 }
 
 func (k *Kiosk) connectToDatabase() {
-	db, e := postgres.Connect(k.logger, k.config)
+	e := waitFor(k.logger, "postgres (db.postgres.connection_string)", k.waitTimeout, func() (e error) {
+		k.db, e = postgres.Connect(k.logger, k.config)
+		return e
+	})
+	if e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	e = waitFor(k.logger, "postgres replica (db.postgres.replica.connection_string)", k.waitTimeout,
+		func() (e error) {
+			k.replica, e = postgres.ConnectReplica(k.logger, k.config)
+			return e
+		})
 	if e != nil {
 		k.stop()
 		k.logger.Fatal(e.Error())
 	}
 
-	k.db = db
+	k.reporting, e = postgres.ConnectReporting(k.logger, k.config)
+	if e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	k.readOnly, e = postgres.ConnectReadOnly(k.logger, k.config)
+	if e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	k.shadow, e = postgres.ConnectShadow(k.logger, k.config)
+	if e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
 }
 
+// migrateDatabase migrates the database, unless auto migration is disabled and the migrate command is not run, in which
+// case the database must already be migrated.
 func (k *Kiosk) migrateDatabase() {
-	if e := postgres.Migrate(k.logger, k.config); e != nil {
+	autoMigrate := k.config.Get("db.postgres.auto_migrate").StringOrElse("true") == "true"
+	k.logger.Info("db.postgres.auto_migrate -> ", autoMigrate)
+
+	migration := postgres.Migrate
+	if !autoMigrate && flag.Arg(0) != "migrate" {
+		migration = postgres.CheckSchema
+	}
+
+	if e := migration(k.logger, k.config); e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	if e := postgres.VerifyEnumConstraints(context.Background(), k.db, models.EnumConstraints); e != nil {
 		k.stop()
 		k.logger.Fatal(e.Error())
 	}
@@ -91,17 +256,93 @@ func (k *Kiosk) prepareNatsClient() {
 	addresses := k.config.Get("nats.addresses").SliceOfStringOrElse([]string{"nats://localhost:4222"})
 	k.logger.Info("nats.addresses -> ", addresses)
 
-	client, e := nc.Connect(strings.Join(addresses, ","), nc.Name("Kiosk"))
+	e := waitFor(k.logger, "nats (nats.addresses)", k.waitTimeout, func() (e error) {
+		k.natsClient, e = nc.Connect(strings.Join(addresses, ","), nc.Name("Kiosk"))
+		return e
+	})
 	if e != nil {
 		k.stop()
 		k.logger.Fatal(e.Error())
 	}
+}
+
+func (k *Kiosk) prepareMailer() {
+	k.mailer = mailing.NewMailer(k.logger, k.config)
+}
+
+func (k *Kiosk) prepareJobsPool() {
+	workers := k.config.Get("jobs.workers").IntOrElse(4)
+	k.logger.Info("jobs.workers -> ", workers)
+
+	k.jobsPool = jobs.NewPool(k.logger, k.db, k.instance, workers)
+}
+
+// preparePageTokens prepares the page tokens shared by all lists, so they are signed by the same key. Without a key
+// configured, a random one is generated, as page tokens signed by an empty key could be forged. Page tokens are then
+// only valid on the instance that issued them, until it restarts.
+func (k *Kiosk) preparePageTokens() {
+	pageTokenKey := k.config.Get("tickets.page_token_key").StringOrElse("")
+	if pageTokenKey == "" {
+		key := make([]byte, 32)
+		if _, e := rand.Read(key); e != nil {
+			k.stop()
+			k.logger.Fatal("Could not generate page token key: ", e.Error())
+		}
+
+		pageTokenKey = hex.EncodeToString(key)
+		k.logger.Warn("tickets.page_token_key is empty, page tokens are signed by a random key and only valid on this " +
+			"instance until it restarts")
+	}
+
+	k.pageTokens = pagination.NewTokens(pageTokenKey)
+}
+
+// prepareIdentities prepares the identities shared by the ticket and comment services, so they share their cache.
+func (k *Kiosk) prepareIdentities() {
+	ttl := k.config.Get("connectors.identity.cache_ttl").DurationOrElse(time.Hour)
+
+	k.logger.Info("connectors.identity.cache_ttl -> ", ttl)
+
+	k.identities = services.NewIdentities(k.logger, connectors.ConfiguredIdentityDirectory(k.logger, k.config), ttl)
+}
+
+func (k *Kiosk) startRuntimeService() {
+	runtimeService := services.NewRuntimeService(k.logger, k.db, k.natsClient)
+
+	if e := runtimeService.Start(); e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
 
-	k.natsClient = client
+	k.runtimeService = runtimeService
 }
 
 func (k *Kiosk) startTicketService() {
-	ticketService := services.NewTicketService(k.logger, k.db, k.natsClient)
+	waitingPolicy := services.WaitingPolicy{
+		NudgeAfter: k.config.Get("tickets.waiting_on_customer.nudge_after").DurationOrElse(72 * time.Hour),
+		CloseAfter: k.config.Get("tickets.waiting_on_customer.close_after").DurationOrElse(168 * time.Hour),
+		NudgeMessage: k.config.Get("tickets.waiting_on_customer.nudge_message").
+			StringOrElse("We are waiting for your reply, please let us know if you still need help."),
+	}
+
+	k.logger.Info("tickets.waiting_on_customer.nudge_after -> ", waitingPolicy.NudgeAfter)
+	k.logger.Info("tickets.waiting_on_customer.close_after -> ", waitingPolicy.CloseAfter)
+
+	deduplicationWindow := k.config.Get("tickets.deduplication_window").DurationOrElse(time.Hour)
+	k.logger.Info("tickets.deduplication_window -> ", deduplicationWindow)
+
+	archiveAfter := k.config.Get("tickets.archive_after").DurationOrElse(0)
+	k.logger.Info("tickets.archive_after -> ", archiveAfter)
+
+	shadowReads := services.ShadowReads{Percent: k.config.Get("tickets.shadow_reads.percent").IntOrElse(0)}
+	k.logger.Info("tickets.shadow_reads.percent -> ", shadowReads.Percent)
+
+	if k.shadow != nil {
+		shadowReads.Repository = models.NewTicketRepository(k.logger, k.shadow)
+	}
+
+	ticketService := services.NewTicketService(k.logger, k.db, k.replica, k.readOnly, k.natsClient, k.jobsPool,
+		waitingPolicy, deduplicationWindow, archiveAfter, k.pageTokens, shadowReads, k.identities)
 
 	if e := ticketService.Start(); e != nil {
 		k.stop()
@@ -112,7 +353,7 @@ func (k *Kiosk) startTicketService() {
 }
 
 func (k *Kiosk) startCommentService() {
-	commentService := services.NewCommentService(k.logger, k.db, k.natsClient)
+	commentService := services.NewCommentService(k.logger, k.db, k.natsClient, k.pageTokens, k.identities)
 
 	if e := commentService.Start(); e != nil {
 		k.stop()
@@ -122,12 +363,352 @@ func (k *Kiosk) startCommentService() {
 	k.commentService = commentService
 }
 
+func (k *Kiosk) startReportService() {
+	agentMetricsPrivacy := services.AgentMetricsPrivacy{
+		Mode: strings.ToUpper(k.config.Get("reports.agent_metrics.privacy").
+			StringOrElse(services.AgentMetricsPseudonymized)),
+		PseudonymKey: k.config.Get("reports.agent_metrics.pseudonym_key").StringOrElse(""),
+	}
+
+	k.logger.Info("reports.agent_metrics.privacy -> ", agentMetricsPrivacy.Mode)
+	if agentMetricsPrivacy.Mode == services.AgentMetricsPseudonymized && agentMetricsPrivacy.PseudonymKey == "" {
+		k.logger.Warn("reports.agent_metrics.pseudonym_key is empty, pseudonyms of agents can be guessed from names")
+	}
+
+	windows := k.config.Get("reports.sla_compliance.windows").SliceOfStringOrElse([]string{"24h", "168h", "720h"})
+	k.logger.Info("reports.sla_compliance.windows -> ", windows)
+
+	slaComplianceWindows := make([]services.SLAComplianceWindow, 0, len(windows))
+	for _, window := range windows {
+		duration, e := time.ParseDuration(window)
+		if e != nil || duration <= 0 {
+			k.stop()
+			k.logger.Fatal("reports.sla_compliance.windows has an invalid window: ", window)
+		}
+
+		slaComplianceWindows = append(slaComplianceWindows, services.SLAComplianceWindow{Name: window,
+			Duration: duration})
+	}
+
+	reportService := services.NewReportService(k.logger, k.db, k.reporting, k.natsClient, k.mailer,
+		agentMetricsPrivacy, slaComplianceWindows)
+
+	if e := reportService.Start(); e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	k.reportService = reportService
+}
+
+func (k *Kiosk) startIssuerService() {
+	issuerService := services.NewIssuerService(k.logger, k.db, k.natsClient)
+
+	if e := issuerService.Start(); e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	k.issuerService = issuerService
+}
+
+func (k *Kiosk) startNotificationService() {
+	enabled := k.config.Get("notifications.enabled").StringOrElse("false") == "true"
+	k.logger.Info("notifications.enabled -> ", enabled)
+
+	deduplicationWindow := k.config.Get("notifications.deduplication_window").DurationOrElse(time.Minute)
+	k.logger.Info("notifications.deduplication_window -> ", deduplicationWindow)
+
+	notificationService := services.NewNotificationService(k.logger, k.db, k.natsClient, k.mailer, k.jobsPool,
+		enabled, deduplicationWindow)
+
+	if e := notificationService.Start(); e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	k.notificationService = notificationService
+}
+
+func (k *Kiosk) startReferenceService() {
+	referenceService := services.NewReferenceService(k.logger, k.db, k.natsClient,
+		connectors.Configured(k.logger, k.config))
+
+	if e := referenceService.Start(); e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	k.referenceService = referenceService
+}
+
+func (k *Kiosk) startPagingService() {
+	pagingService := services.NewPagingService(k.logger, k.db, k.natsClient, k.jobsPool,
+		connectors.Pagers(k.logger, k.config))
+
+	if e := pagingService.Start(); e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	k.pagingService = pagingService
+}
+
+func (k *Kiosk) startStatusPageService() {
+	statusPageService := services.NewStatusPageService(k.logger, k.db, k.natsClient, k.jobsPool,
+		connectors.ConfiguredStatusPage(k.logger, k.config))
+
+	if e := statusPageService.Start(); e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	k.statusPageService = statusPageService
+}
+
+func (k *Kiosk) startDraftService() {
+	ttl := k.config.Get("tickets.drafts.ttl").DurationOrElse(168 * time.Hour)
+	k.logger.Info("tickets.drafts.ttl -> ", ttl)
+
+	draftService := services.NewDraftService(k.logger, k.db, k.natsClient, ttl)
+
+	if e := draftService.Start(); e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	k.draftService = draftService
+}
+
+func (k *Kiosk) startWebhookService() {
+	maxSkew := k.config.Get("webhooks.max_skew").DurationOrElse(5 * time.Minute)
+	k.logger.Info("webhooks.max_skew -> ", maxSkew)
+
+	webhookService := services.NewWebhookService(k.logger, k.db, k.natsClient, maxSkew)
+
+	if e := webhookService.Start(); e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	k.webhookService = webhookService
+}
+
+func (k *Kiosk) startEscalationService() {
+	escalationService := services.NewEscalationService(k.logger, k.db, k.natsClient)
+
+	if e := escalationService.Start(); e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	k.escalationService = escalationService
+}
+
+func (k *Kiosk) startApprovalService() {
+	approvalService := services.NewApprovalService(k.logger, k.db, k.natsClient)
+
+	if e := approvalService.Start(); e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	k.approvalService = approvalService
+}
+
+func (k *Kiosk) startSentimentService() {
+	sentimentService := services.NewSentimentService(k.logger, k.db, k.natsClient,
+		connectors.ConfiguredSentimentAnalyzer(k.logger, k.config))
+
+	if e := sentimentService.Start(); e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	k.sentimentService = sentimentService
+}
+
+func (k *Kiosk) startTenantService() {
+	tenantService := services.NewTenantService(k.logger, k.db, k.natsClient)
+
+	if e := tenantService.Start(); e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	k.tenantService = tenantService
+}
+
+func (k *Kiosk) startTransferService() {
+	anonymizationKey := k.config.Get("exports.anonymization.key").StringOrElse("")
+	if anonymizationKey == "" {
+		k.logger.Warn("exports.anonymization.key is empty, pseudonyms of anonymized exports can be guessed from owners")
+	}
+
+	transferService := services.NewTransferService(k.logger, k.db, k.natsClient,
+		anonymization.NewAnonymizer(anonymizationKey))
+
+	if e := transferService.Start(); e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	k.transferService = transferService
+}
+
+func (k *Kiosk) startAttachmentService() {
+	policy := services.AttachmentPolicy{
+		MaxSize: int64(k.config.Get("attachments.max_size").IntOrElse(10 << 20)),
+		ContentTypes: k.config.Get("attachments.allowed_content_types").SliceOfStringOrElse([]string{"image/png",
+			"image/jpeg", "image/gif", "application/pdf", "text/plain"}),
+	}
+
+	k.logger.Info("attachments.max_size -> ", policy.MaxSize)
+	k.logger.Info("attachments.allowed_content_types -> ", policy.ContentTypes)
+
+	attachmentService := services.NewAttachmentService(k.logger, k.db, k.natsClient,
+		blobs.Configured(k.logger, k.config), policy, k.pageTokens)
+
+	if e := attachmentService.Start(); e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	k.attachmentService = attachmentService
+}
+
+func (k *Kiosk) startAdminService() {
+	var auditSink services.AuditSink
+	if store := blobs.ConfiguredAt(k.logger, k.config, "admin.audit.s3"); store != nil {
+		auditSink = services.NewObjectAuditSink(store, "admin_audit_logs/")
+	}
+
+	adminService := services.NewAdminService(k.logger, k.db, k.natsClient, k.jobsPool, k.pageTokens, auditSink)
+
+	if e := adminService.Start(); e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	k.adminService = adminService
+}
+
+// startJobsPool starts processing jobs once all services registered their handlers.
+func (k *Kiosk) startJobsPool() {
+	k.jobsPool.Start()
+}
+
+func (k *Kiosk) startScheduler() {
+	enabled := k.config.Get("scheduler.enabled").StringOrElse("true") == "true"
+	k.logger.Info("scheduler.enabled -> ", enabled)
+
+	if !enabled {
+		return
+	}
+
+	// Scheduled jobs run on the leader only, when multiple instances share the database.
+	k.elector = postgres.NewElector(k.logger, k.db, schedulerLeadershipKey, k.instance)
+	k.elector.Start()
+
+	k.scheduler = scheduler.NewScheduler(k.logger)
+	k.scheduler.OnlyWhen(k.elector.IsLeader)
+
+	e := k.scheduler.Add("scheduled-reports", "* * * * *", time.Minute, k.reportService.RunScheduledReports)
+	if e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	e = k.scheduler.Add("sla-compliance", "*/5 * * * *", 4*time.Minute, k.reportService.ExportSLACompliance)
+	if e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	e = k.scheduler.Add("daily-counters", "0 3 * * *", 30*time.Minute, k.reportService.ReconcileDailyCounters)
+	if e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	e = k.scheduler.Add("suppressed-notifications", "* * * * *", time.Minute,
+		k.notificationService.ResumeSuppressed)
+	if e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	e = k.scheduler.Add("notification-digests", "0 * * * *", 5*time.Minute, k.notificationService.DeliverDigests)
+	if e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	e = k.scheduler.Add("snoozed-tickets", "* * * * *", time.Minute, k.ticketService.UnsnoozeDueTickets)
+	if e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	e = k.scheduler.Add("waiting-tickets", "*/15 * * * *", 10*time.Minute, k.ticketService.HandleWaitingTickets)
+	if e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	e = k.scheduler.Add("expired-drafts", "0 * * * *", 5*time.Minute, k.draftService.DeleteExpiredDrafts)
+	if e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	e = k.scheduler.Add("reference-sync", "*/5 * * * *", 4*time.Minute, k.referenceService.SyncReferences)
+	if e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	e = k.scheduler.Add("escalations", "*/5 * * * *", 4*time.Minute, k.escalationService.EscalateDueTickets)
+	if e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	e = k.scheduler.Add("invariants", "30 * * * *", 10*time.Minute, k.ticketService.CheckInvariants)
+	if e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	e = k.scheduler.Add("archive-tickets", "45 * * * *", 10*time.Minute, k.ticketService.ArchiveTickets)
+	if e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	e = k.scheduler.Add("incomplete-attachments", "0 * * * *", 5*time.Minute,
+		k.attachmentService.DeleteIncompleteUploads)
+	if e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	e = k.scheduler.Add("unreferenced-attachments", "15 * * * *", 10*time.Minute,
+		k.attachmentService.DeleteUnreferencedContents)
+	if e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	k.scheduler.Start()
+}
+
 func (k *Kiosk) startWebServer() {
 	k.webServer = web.StartServer(k.logger, k.config, k.natsClient)
 }
 
 func (k *Kiosk) awaitTermination() {
-	receiver := make(chan os.Signal)
+	receiver := make(chan os.Signal, 1)
 	signal.Notify(receiver, os.Interrupt, os.Kill)
 
 	<-receiver
@@ -145,6 +726,74 @@ func (k *Kiosk) stop() {
 		}
 	}
 
+	if k.scheduler != nil {
+		k.scheduler.Stop()
+	}
+
+	if k.elector != nil {
+		k.elector.Stop()
+	}
+
+	if k.adminService != nil {
+		k.adminService.Stop()
+	}
+
+	if k.attachmentService != nil {
+		k.attachmentService.Stop()
+	}
+
+	if k.transferService != nil {
+		k.transferService.Stop()
+	}
+
+	if k.tenantService != nil {
+		k.tenantService.Stop()
+	}
+
+	if k.sentimentService != nil {
+		k.sentimentService.Stop()
+	}
+
+	if k.approvalService != nil {
+		k.approvalService.Stop()
+	}
+
+	if k.escalationService != nil {
+		k.escalationService.Stop()
+	}
+
+	if k.webhookService != nil {
+		k.webhookService.Stop()
+	}
+
+	if k.draftService != nil {
+		k.draftService.Stop()
+	}
+
+	if k.statusPageService != nil {
+		k.statusPageService.Stop()
+	}
+
+	if k.pagingService != nil {
+		k.pagingService.Stop()
+	}
+
+	if k.referenceService != nil {
+		k.referenceService.Stop()
+	}
+
+	if k.notificationService != nil {
+		k.notificationService.Stop()
+	}
+
+	if k.issuerService != nil {
+		k.issuerService.Stop()
+	}
+
+	if k.reportService != nil {
+		k.reportService.Stop()
+	}
+
 	if k.commentService != nil {
 		k.commentService.Stop()
 	}
@@ -153,10 +802,34 @@ func (k *Kiosk) stop() {
 		k.ticketService.Stop()
 	}
 
+	if k.runtimeService != nil {
+		k.runtimeService.Stop()
+	}
+
+	if k.jobsPool != nil {
+		k.jobsPool.Stop()
+	}
+
 	if k.natsClient != nil {
 		k.natsClient.Close()
 	}
 
+	if k.reporting != nil {
+		k.reporting.Close()
+	}
+
+	if k.shadow != nil {
+		k.shadow.Close()
+	}
+
+	if k.readOnly != nil {
+		k.readOnly.Close()
+	}
+
+	if k.replica != nil {
+		k.replica.Close()
+	}
+
 	if k.db != nil {
 		k.db.Close()
 	}
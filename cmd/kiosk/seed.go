@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	"github.com/jibitters/kiosk/seed"
+)
+
+// seed parses the seed sub command arguments and fills the database with generated tickets and comments.
+func (k *Kiosk) seed(args []string) {
+	defaults := seed.DefaultOptions()
+	options := defaults
+
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	flags.Int64Var(&options.Seed, "seed", defaults.Seed, "random seed, same seeds generate same data")
+	flags.IntVar(&options.Tickets, "tickets", defaults.Tickets, "number of tickets to generate")
+	flags.IntVar(&options.MaxComments, "max-comments", defaults.MaxComments, "maximum number of comments per ticket")
+	flags.IntVar(&options.Issuers, "issuers", defaults.Issuers, "number of distinct issuers")
+	flags.IntVar(&options.Owners, "owners", defaults.Owners, "number of distinct owners")
+	flags.IntVar(&options.InsertionPageSize, "page-size", defaults.InsertionPageSize,
+		"tickets inserted per transaction")
+	from := flags.String("from", defaults.FromDate.Format(time.RFC3339), "lower bound of creation dates (RFC3339)")
+	to := flags.String("to", defaults.ToDate.Format(time.RFC3339), "upper bound of creation dates (RFC3339)")
+	importanceLevels := flags.String("importance-levels", "LOW=40,MEDIUM=35,HIGH=20,CRITICAL=5",
+		"weighted distribution of importance levels")
+	statuses := flags.String("statuses", "NEW=20,REPLIED=25,RESOLVED=30,CLOSED=20,BLOCKED=5",
+		"weighted distribution of statuses")
+	_ = flags.Parse(args)
+
+	var e error
+	if options.FromDate, e = time.Parse(time.RFC3339, *from); e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	if options.ToDate, e = time.Parse(time.RFC3339, *to); e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	if options.ImportanceLevels, e = seed.ParseWeights(*importanceLevels, seed.IsImportanceLevel); e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	if options.Statuses, e = seed.ParseWeights(*statuses, seed.IsStatus); e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	if e := seed.NewSeeder(k.logger, k.db, options).Run(context.Background()); e != nil {
+		k.stop()
+		k.logger.Fatal(e.Error())
+	}
+
+	k.logger.Info("Successfully seeded ", options.Tickets, " tickets.")
+	k.stop()
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	"github.com/jibitters/kiosk/transfer"
+)
+
+// transfer parses the transfer sub command arguments and copies the tickets of an issuer from one kiosk deployment to
+// another over their APIs.
+func (k *Kiosk) transfer(args []string) {
+	options := transfer.Options{}
+
+	flags := flag.NewFlagSet("transfer", flag.ExitOnError)
+	flags.StringVar(&options.Source, "source", "", "base URL of the source kiosk web server")
+	flags.StringVar(&options.Destination, "destination", "", "base URL of the destination kiosk web server")
+	flags.StringVar(&options.Issuer, "issuer", "", "issuer whose tickets are transferred")
+	flags.StringVar(&options.SourceName, "source-name", "", "prefix of external ids, defaults to the source URL")
+	flags.Int64Var(&options.AfterID, "after-id", 0, "resumes the transfer after this source ticket id")
+	flags.IntVar(&options.PageSize, "page-size", 100, "tickets exported per request")
+	flags.DurationVar(&options.Timeout, "timeout", 30*time.Second, "timeout of each request")
+	_ = flags.Parse(args)
+
+	if options.Source == "" || options.Destination == "" || options.Issuer == "" {
+		k.logger.Fatal("transfer requires --source, --destination and --issuer")
+	}
+
+	if options.SourceName == "" {
+		options.SourceName = options.Source
+	}
+
+	summary, e := transfer.NewTransferrer(k.logger, options).Run(context.Background())
+	if e != nil {
+		k.logger.Fatal(e.Error(), ", resume with --after-id ", summary.LastID)
+	}
+
+	k.logger.Info("Successfully transferred ", summary.Imported, " tickets with ", summary.Comments, " comments, ",
+		summary.Skipped, " tickets were transferred before.")
+}
@@ -0,0 +1,132 @@
+package connectors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/jibitters/kiosk/models"
+	"github.com/lireza/lib/configuring"
+	"go.uber.org/zap"
+)
+
+// IssueState is the coarse state of an external issue, which is all kiosk synchronizes.
+type IssueState string
+
+// Different issue state instances.
+const (
+	IssueStateOpen   IssueState = "OPEN"
+	IssueStateClosed IssueState = "CLOSED"
+)
+
+// Connector synchronizes the state of the issues of an external system, identified by their external keys.
+type Connector interface {
+	// State loads the state of an external issue.
+	State(ctx context.Context, externalKey string) (IssueState, error)
+
+	// SetState moves an external issue into the provided state.
+	SetState(ctx context.Context, externalKey string, state IssueState) error
+}
+
+// Escalation describes the issue to open for a ticket.
+type Escalation struct {
+	// Target is where the issue gets opened, e.g. a repository or a project. Empty stands for the configured default.
+	Target     string
+	Title      string
+	Body       string
+	Importance models.TicketImportanceLevel
+}
+
+// Escalator is implemented by connectors able to open an external issue for a ticket.
+type Escalator interface {
+	// Escalate opens the issue and returns back its external key and URL.
+	Escalate(ctx context.Context, escalation Escalation) (externalKey, url string, e error)
+}
+
+// WebhookEvent is a change of an external issue reported by its system.
+type WebhookEvent struct {
+	ExternalKey string
+	// State is set when the issue got closed or reopened.
+	State IssueState
+	// Comment is set when a comment got added to the issue.
+	Comment *WebhookComment
+}
+
+// WebhookComment is a comment added to an external issue.
+type WebhookComment struct {
+	Author string
+	Body   string
+}
+
+// WebhookReceiver is implemented by connectors whose systems report the changes of issues through webhooks.
+type WebhookReceiver interface {
+	// ReceiveWebhook authenticates and parses a webhook delivery of the provided kind. A nil event is returned back for
+	// deliveries kiosk does not care about.
+	ReceiveWebhook(kind, signature string, payload []byte) (*WebhookEvent, error)
+}
+
+// Configured returns back the connectors configured in config instance, keyed by the system they connect to. Systems
+// without credentials are left out, so their references are links only.
+func Configured(logger *zap.SugaredLogger, config *configuring.Config) map[models.ReferenceSystem]Connector {
+	connectors := make(map[models.ReferenceSystem]Connector)
+
+	if github := NewGitHub(logger, config); github != nil {
+		connectors[models.ReferenceSystemGitHub] = github
+	}
+
+	if jira := NewJira(logger, config); jira != nil {
+		connectors[models.ReferenceSystemJira] = jira
+	}
+
+	return connectors
+}
+
+// client is the HTTP client shared by connectors.
+var client = &http.Client{Timeout: 10 * time.Second}
+
+// call sends a JSON request and decodes the JSON response into out, if provided. Non 2xx responses are errors.
+// Authorize may be nil for APIs authenticating by the request body.
+func call(ctx context.Context, method, url string, authorize func(r *http.Request), in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		encoded, e := json.Marshal(in)
+		if e != nil {
+			return e
+		}
+
+		body = bytes.NewReader(encoded)
+	}
+
+	request, e := http.NewRequestWithContext(ctx, method, url, body)
+	if e != nil {
+		return e
+	}
+
+	request.Header.Set("Accept", "application/json")
+	if in != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if authorize != nil {
+		authorize(request)
+	}
+
+	response, e := client.Do(request)
+	if e != nil {
+		return e
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("%v %v: %v", method, url, response.Status)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.NewDecoder(response.Body).Decode(out)
+}
@@ -0,0 +1,180 @@
+package connectors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/jibitters/kiosk/webhooks"
+	"github.com/lireza/lib/configuring"
+	"go.uber.org/zap"
+)
+
+var (
+	// gitHubKey is the form of GitHub external keys, e.g. jibitters/kiosk#42.
+	gitHubKey = regexp.MustCompile(`^([\w.-]+)/([\w.-]+)#(\d+)$`)
+
+	// gitHubRepository is the form of GitHub repositories, e.g. jibitters/kiosk.
+	gitHubRepository = regexp.MustCompile(`^[\w.-]+/[\w.-]+$`)
+)
+
+// GitHub connects to GitHub issues through its REST API and receives their changes through webhooks.
+type GitHub struct {
+	baseURL           string
+	token             string
+	defaultRepository string
+	webhookSecret     string
+}
+
+// NewGitHub returns back a newly created and ready to use GitHub connector configured by the information provided in
+// config instance, or nil when no token is configured.
+func NewGitHub(logger *zap.SugaredLogger, config *configuring.Config) *GitHub {
+	baseURL := config.Get("connectors.github.base_url").StringOrElse("https://api.github.com")
+	token := config.Get("connectors.github.token").StringOrElse("")
+	defaultRepository := config.Get("connectors.github.default_repository").StringOrElse("")
+	webhookSecret := config.Get("connectors.github.webhook_secret").StringOrElse("")
+
+	logger.Info("connectors.github.base_url -> ", baseURL)
+	logger.Info("connectors.github.default_repository -> ", defaultRepository)
+
+	if token == "" {
+		return nil
+	}
+
+	return &GitHub{baseURL: strings.TrimSuffix(baseURL, "/"), token: token, defaultRepository: defaultRepository,
+		webhookSecret: webhookSecret}
+}
+
+// State loads the state of the issue.
+func (g *GitHub) State(ctx context.Context, externalKey string) (IssueState, error) {
+	url, e := g.issueURL(externalKey)
+	if e != nil {
+		return "", e
+	}
+
+	issue := &struct {
+		State string `json:"state"`
+	}{}
+
+	if e := call(ctx, http.MethodGet, url, g.authorize, nil, issue); e != nil {
+		return "", e
+	}
+
+	return gitHubState(issue.State), nil
+}
+
+// SetState closes or reopens the issue.
+func (g *GitHub) SetState(ctx context.Context, externalKey string, state IssueState) error {
+	url, e := g.issueURL(externalKey)
+	if e != nil {
+		return e
+	}
+
+	issue := &struct {
+		State string `json:"state"`
+	}{State: "open"}
+
+	if state == IssueStateClosed {
+		issue.State = "closed"
+	}
+
+	return call(ctx, http.MethodPatch, url, g.authorize, issue, nil)
+}
+
+// Escalate opens an issue in the target repository, in owner/repository form. GitHub issues have no priority, so the
+// importance is left out.
+func (g *GitHub) Escalate(ctx context.Context, escalation Escalation) (string, string, error) {
+	repository := escalation.Target
+	if repository == "" {
+		repository = g.defaultRepository
+	}
+
+	if !gitHubRepository.MatchString(repository) {
+		return "", "", fmt.Errorf("%q is not a repository in owner/repository form", repository)
+	}
+
+	in := &struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+	}{Title: escalation.Title, Body: escalation.Body}
+
+	issue := &struct {
+		Number  int64  `json:"number"`
+		HTMLURL string `json:"html_url"`
+	}{}
+
+	url := fmt.Sprintf("%v/repos/%v/issues", g.baseURL, repository)
+	if e := call(ctx, http.MethodPost, url, g.authorize, in, issue); e != nil {
+		return "", "", e
+	}
+
+	return fmt.Sprintf("%v#%d", repository, issue.Number), issue.HTMLURL, nil
+}
+
+// gitHubWebhook holds the parts of issues and issue_comment webhook payloads kiosk cares about.
+type gitHubWebhook struct {
+	Action string `json:"action"`
+	Issue  struct {
+		Number int64  `json:"number"`
+		State  string `json:"state"`
+	} `json:"issue"`
+	Comment struct {
+		Body string `json:"body"`
+		User struct {
+			Login string `json:"login"`
+			Type  string `json:"type"`
+		} `json:"user"`
+	} `json:"comment"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// ReceiveWebhook authenticates the delivery by its X-Hub-Signature-256 signature and parses issues and issue_comment
+// deliveries. Comments of bots are ignored, they are usually echoes of integrations.
+func (g *GitHub) ReceiveWebhook(kind, signature string, payload []byte) (*WebhookEvent, error) {
+	if e := webhooks.VerifySignature(g.webhookSecret, signature, payload); e != nil {
+		return nil, e
+	}
+
+	webhook := &gitHubWebhook{}
+	if e := json.Unmarshal(payload, webhook); e != nil {
+		return nil, e
+	}
+
+	event := &WebhookEvent{ExternalKey: fmt.Sprintf("%v#%d", webhook.Repository.FullName, webhook.Issue.Number)}
+	switch {
+	case kind == "issues" && (webhook.Action == "closed" || webhook.Action == "reopened"):
+		event.State = gitHubState(webhook.Issue.State)
+	case kind == "issue_comment" && webhook.Action == "created" && webhook.Comment.User.Type != "Bot":
+		event.Comment = &WebhookComment{Author: webhook.Comment.User.Login, Body: webhook.Comment.Body}
+	default:
+		return nil, nil
+	}
+
+	return event, nil
+}
+
+func gitHubState(state string) IssueState {
+	if state == "closed" {
+		return IssueStateClosed
+	}
+
+	return IssueStateOpen
+}
+
+func (g *GitHub) issueURL(externalKey string) (string, error) {
+	matches := gitHubKey.FindStringSubmatch(externalKey)
+	if matches == nil {
+		return "", fmt.Errorf("%v is not in owner/repository#number form", externalKey)
+	}
+
+	return fmt.Sprintf("%v/repos/%v/%v/issues/%v", g.baseURL, matches[1], matches[2], matches[3]), nil
+}
+
+func (g *GitHub) authorize(r *http.Request) {
+	r.Header.Set("Authorization", "token "+g.token)
+}
@@ -0,0 +1,116 @@
+package connectors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/lireza/lib/configuring"
+	"go.uber.org/zap"
+)
+
+// Identity is how an agent is known to the identity provider agents sign in with.
+type Identity struct {
+	DisplayName string
+	Email       string
+	AvatarURL   string
+}
+
+// IdentityDirectory resolves agents, as identified by the owners of comments and assignees of tickets, from the
+// identity provider.
+type IdentityDirectory interface {
+	// Identity resolves the agent by its user name, it is nil when the identity provider does not know the agent.
+	Identity(ctx context.Context, userName string) (*Identity, error)
+}
+
+// ConfiguredIdentityDirectory returns back the identity directory configured in config instance. It is nil when none
+// is configured, in which case owners and assignees are not enriched.
+func ConfiguredIdentityDirectory(logger *zap.SugaredLogger, config *configuring.Config) IdentityDirectory {
+	if directory := NewSCIMDirectory(logger, config); directory != nil {
+		return directory
+	}
+
+	return nil
+}
+
+// SCIMDirectory resolves agents through the SCIM 2.0 users endpoint of the identity provider, which OIDC providers such
+// as Okta, Azure AD or Keycloak expose next to their OIDC endpoints, filtering users by their user names.
+type SCIMDirectory struct {
+	url   string
+	token string
+}
+
+// NewSCIMDirectory returns back a newly created and ready to use SCIMDirectory configured by the information provided
+// in config instance, or nil when no url is configured.
+func NewSCIMDirectory(logger *zap.SugaredLogger, config *configuring.Config) *SCIMDirectory {
+	url := config.Get("connectors.identity.scim_url").StringOrElse("")
+	token := config.Get("connectors.identity.token").StringOrElse("")
+
+	logger.Info("connectors.identity.scim_url -> ", url)
+
+	if url == "" {
+		return nil
+	}
+
+	return &SCIMDirectory{url: strings.TrimSuffix(url, "/"), token: token}
+}
+
+// Identity looks the user up by its user name, preferring its display name over its formatted name, its primary email
+// and its primary photo.
+func (d *SCIMDirectory) Identity(ctx context.Context, userName string) (*Identity, error) {
+	type value struct {
+		Value   string `json:"value"`
+		Primary bool   `json:"primary"`
+	}
+
+	out := &struct {
+		Resources []struct {
+			DisplayName string `json:"displayName"`
+			Name        struct {
+				Formatted string `json:"formatted"`
+			} `json:"name"`
+			Emails []value `json:"emails"`
+			Photos []value `json:"photos"`
+		} `json:"Resources"`
+	}{}
+
+	filter := fmt.Sprintf(`userName eq "%v"`, strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(userName))
+	u := d.url + "/Users?count=1&filter=" + url.QueryEscape(filter)
+	if e := call(ctx, http.MethodGet, u, d.authorize, nil, out); e != nil {
+		return nil, e
+	}
+
+	if len(out.Resources) == 0 {
+		return nil, nil
+	}
+
+	primary := func(values []value) string {
+		for _, v := range values {
+			if v.Primary {
+				return v.Value
+			}
+		}
+
+		if len(values) > 0 {
+			return values[0].Value
+		}
+
+		return ""
+	}
+
+	user := out.Resources[0]
+	identity := &Identity{DisplayName: user.DisplayName, Email: primary(user.Emails), AvatarURL: primary(user.Photos)}
+	if identity.DisplayName == "" {
+		identity.DisplayName = user.Name.Formatted
+	}
+
+	return identity, nil
+}
+
+func (d *SCIMDirectory) authorize(r *http.Request) {
+	if d.token != "" {
+		r.Header.Set("Authorization", "Bearer "+d.token)
+	}
+}
@@ -0,0 +1,210 @@
+package connectors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/webhooks"
+	"github.com/lireza/lib/configuring"
+	"go.uber.org/zap"
+)
+
+// jiraPriorities maps the importance levels of tickets to the default priorities of Jira.
+var jiraPriorities = map[models.TicketImportanceLevel]string{
+	models.TicketImportanceLevelLow:      "Low",
+	models.TicketImportanceLevelMedium:   "Medium",
+	models.TicketImportanceLevelHigh:     "High",
+	models.TicketImportanceLevelCritical: "Highest",
+}
+
+// Jira connects to Jira Cloud or Server issues through its REST API and receives their changes through webhooks.
+// External keys are issue keys, e.g. OPS-42.
+type Jira struct {
+	baseURL        string
+	username       string
+	token          string
+	defaultProject string
+	issueType      string
+	webhookSecret  string
+}
+
+// NewJira returns back a newly created and ready to use Jira connector configured by the information provided in
+// config instance, or nil when no base url or token is configured.
+func NewJira(logger *zap.SugaredLogger, config *configuring.Config) *Jira {
+	baseURL := config.Get("connectors.jira.base_url").StringOrElse("")
+	username := config.Get("connectors.jira.username").StringOrElse("")
+	token := config.Get("connectors.jira.token").StringOrElse("")
+	defaultProject := config.Get("connectors.jira.default_project").StringOrElse("")
+	issueType := config.Get("connectors.jira.issue_type").StringOrElse("Task")
+	webhookSecret := config.Get("connectors.jira.webhook_secret").StringOrElse("")
+
+	logger.Info("connectors.jira.base_url -> ", baseURL)
+	logger.Info("connectors.jira.username -> ", username)
+	logger.Info("connectors.jira.default_project -> ", defaultProject)
+	logger.Info("connectors.jira.issue_type -> ", issueType)
+
+	if baseURL == "" || token == "" {
+		return nil
+	}
+
+	return &Jira{baseURL: strings.TrimSuffix(baseURL, "/"), username: username, token: token,
+		defaultProject: defaultProject, issueType: issueType, webhookSecret: webhookSecret}
+}
+
+// jiraStatus is the status of a Jira issue, Jira groups statuses of all workflows into a few categories.
+type jiraStatus struct {
+	StatusCategory struct {
+		Key string `json:"key"`
+	} `json:"statusCategory"`
+}
+
+// State loads the state of the issue. Issues whose status is in the done category are closed.
+func (j *Jira) State(ctx context.Context, externalKey string) (IssueState, error) {
+	issue := &struct {
+		Fields struct {
+			Status jiraStatus `json:"status"`
+		} `json:"fields"`
+	}{}
+
+	if e := call(ctx, http.MethodGet, j.issueURL(externalKey)+"?fields=status", j.authorize, nil, issue); e != nil {
+		return "", e
+	}
+
+	return stateOf(issue.Fields.Status), nil
+}
+
+// SetState transitions the issue into the first status of its workflow that is in the provided state.
+func (j *Jira) SetState(ctx context.Context, externalKey string, state IssueState) error {
+	transitionsURL := j.issueURL(externalKey) + "/transitions"
+
+	transitions := &struct {
+		Transitions []struct {
+			ID string     `json:"id"`
+			To jiraStatus `json:"to"`
+		} `json:"transitions"`
+	}{}
+
+	if e := call(ctx, http.MethodGet, transitionsURL, j.authorize, nil, transitions); e != nil {
+		return e
+	}
+
+	for _, transition := range transitions.Transitions {
+		if stateOf(transition.To) != state {
+			continue
+		}
+
+		in := map[string]interface{}{"transition": map[string]string{"id": transition.ID}}
+		return call(ctx, http.MethodPost, transitionsURL, j.authorize, in, nil)
+	}
+
+	return fmt.Errorf("no transition of %v leads to a %v status", externalKey, state)
+}
+
+// Escalate opens an issue in the target project, identified by its key, with the priority matching the importance.
+func (j *Jira) Escalate(ctx context.Context, escalation Escalation) (string, string, error) {
+	project := escalation.Target
+	if project == "" {
+		project = j.defaultProject
+	}
+
+	if project == "" {
+		return "", "", errors.New("no project is provided or configured")
+	}
+
+	fields := map[string]interface{}{
+		"project":     map[string]string{"key": project},
+		"issuetype":   map[string]string{"name": j.issueType},
+		"summary":     escalation.Title,
+		"description": escalation.Body,
+	}
+
+	if priority, ok := jiraPriorities[escalation.Importance]; ok {
+		fields["priority"] = map[string]string{"name": priority}
+	}
+
+	issue := &struct {
+		Key string `json:"key"`
+	}{}
+
+	in := map[string]interface{}{"fields": fields}
+	if e := call(ctx, http.MethodPost, j.baseURL+"/rest/api/2/issue", j.authorize, in, issue); e != nil {
+		return "", "", e
+	}
+
+	return issue.Key, j.baseURL + "/browse/" + url.PathEscape(issue.Key), nil
+}
+
+// jiraWebhook holds the parts of issue and comment webhook payloads kiosk cares about.
+type jiraWebhook struct {
+	WebhookEvent string `json:"webhookEvent"`
+	Issue        struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Status jiraStatus `json:"status"`
+		} `json:"fields"`
+	} `json:"issue"`
+	Changelog struct {
+		Items []struct {
+			Field string `json:"field"`
+		} `json:"items"`
+	} `json:"changelog"`
+	Comment struct {
+		Body   string `json:"body"`
+		Author struct {
+			DisplayName string `json:"displayName"`
+		} `json:"author"`
+	} `json:"comment"`
+}
+
+// ReceiveWebhook authenticates the delivery by its X-Hub-Signature signature and parses status transitions and
+// created comments. Jira carries the kind of deliveries in their payload, so kind is not used.
+func (j *Jira) ReceiveWebhook(_, signature string, payload []byte) (*WebhookEvent, error) {
+	if e := webhooks.VerifySignature(j.webhookSecret, signature, payload); e != nil {
+		return nil, e
+	}
+
+	webhook := &jiraWebhook{}
+	if e := json.Unmarshal(payload, webhook); e != nil {
+		return nil, e
+	}
+
+	event := &WebhookEvent{ExternalKey: webhook.Issue.Key}
+	switch webhook.WebhookEvent {
+	case "jira:issue_updated":
+		for _, item := range webhook.Changelog.Items {
+			if item.Field == "status" {
+				event.State = stateOf(webhook.Issue.Fields.Status)
+			}
+		}
+	case "comment_created":
+		event.Comment = &WebhookComment{Author: webhook.Comment.Author.DisplayName, Body: webhook.Comment.Body}
+	}
+
+	if event.State == "" && event.Comment == nil {
+		return nil, nil
+	}
+
+	return event, nil
+}
+
+func stateOf(status jiraStatus) IssueState {
+	if status.StatusCategory.Key == "done" {
+		return IssueStateClosed
+	}
+
+	return IssueStateOpen
+}
+
+func (j *Jira) issueURL(externalKey string) string {
+	return j.baseURL + "/rest/api/2/issue/" + url.PathEscape(externalKey)
+}
+
+func (j *Jira) authorize(r *http.Request) {
+	r.SetBasicAuth(j.username, j.token)
+}
@@ -0,0 +1,116 @@
+package connectors
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jibitters/kiosk/models"
+	"github.com/lireza/lib/configuring"
+	"go.uber.org/zap"
+)
+
+// Incident describes what the on-call staff gets paged about.
+type Incident struct {
+	// DedupKey identifies the incident, triggering it again while it is open does not page again.
+	DedupKey string
+	Summary  string
+	Details  string
+}
+
+// Pager opens and resolves incidents at an incident management provider. Routing keys select the service or team to
+// page, they are configured per issuer.
+type Pager interface {
+	// Trigger opens the incident.
+	Trigger(ctx context.Context, routingKey string, incident Incident) error
+	// Resolve resolves the incident identified by the dedup key.
+	Resolve(ctx context.Context, routingKey, dedupKey string) error
+}
+
+// Pagers returns back the pagers of all supported providers, keyed by the provider they page through.
+func Pagers(logger *zap.SugaredLogger, config *configuring.Config) map[models.PagingProvider]Pager {
+	return map[models.PagingProvider]Pager{
+		models.PagingProviderPagerDuty: NewPagerDuty(logger, config),
+		models.PagingProviderOpsgenie:  NewOpsgenie(logger, config),
+	}
+}
+
+// PagerDuty pages through the PagerDuty Events API v2, routing keys are integration keys of PagerDuty services.
+type PagerDuty struct {
+	eventsURL string
+}
+
+// NewPagerDuty returns back a newly created and ready to use PagerDuty pager configured by the information provided in
+// config instance.
+func NewPagerDuty(logger *zap.SugaredLogger, config *configuring.Config) *PagerDuty {
+	eventsURL := config.Get("connectors.pagerduty.events_url").StringOrElse("https://events.pagerduty.com/v2/enqueue")
+	logger.Info("connectors.pagerduty.events_url -> ", eventsURL)
+
+	return &PagerDuty{eventsURL: eventsURL}
+}
+
+// Trigger opens a critical incident.
+func (p *PagerDuty) Trigger(ctx context.Context, routingKey string, incident Incident) error {
+	in := map[string]interface{}{
+		"routing_key":  routingKey,
+		"event_action": "trigger",
+		"dedup_key":    incident.DedupKey,
+		"payload": map[string]interface{}{
+			"summary":        incident.Summary,
+			"source":         "kiosk",
+			"severity":       "critical",
+			"custom_details": map[string]string{"details": incident.Details},
+		},
+	}
+
+	return call(ctx, http.MethodPost, p.eventsURL, nil, in, nil)
+}
+
+// Resolve resolves the incident.
+func (p *PagerDuty) Resolve(ctx context.Context, routingKey, dedupKey string) error {
+	in := map[string]interface{}{"routing_key": routingKey, "event_action": "resolve", "dedup_key": dedupKey}
+	return call(ctx, http.MethodPost, p.eventsURL, nil, in, nil)
+}
+
+// Opsgenie pages through the Opsgenie Alert API, routing keys are API keys of Opsgenie API integrations. Dedup keys
+// are used as alert aliases.
+type Opsgenie struct {
+	baseURL string
+}
+
+// NewOpsgenie returns back a newly created and ready to use Opsgenie pager configured by the information provided in
+// config instance.
+func NewOpsgenie(logger *zap.SugaredLogger, config *configuring.Config) *Opsgenie {
+	baseURL := config.Get("connectors.opsgenie.base_url").StringOrElse("https://api.opsgenie.com")
+	logger.Info("connectors.opsgenie.base_url -> ", baseURL)
+
+	return &Opsgenie{baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// Trigger opens a P1 alert.
+func (o *Opsgenie) Trigger(ctx context.Context, routingKey string, incident Incident) error {
+	in := map[string]interface{}{
+		"message":     incident.Summary,
+		"alias":       incident.DedupKey,
+		"description": incident.Details,
+		"source":      "kiosk",
+		"priority":    "P1",
+	}
+
+	return call(ctx, http.MethodPost, o.baseURL+"/v2/alerts", authorizeGenieKey(routingKey), in, nil)
+}
+
+// Resolve closes the alert.
+func (o *Opsgenie) Resolve(ctx context.Context, routingKey, dedupKey string) error {
+	closeURL := o.baseURL + "/v2/alerts/" + url.PathEscape(dedupKey) + "/close?identifierType=alias"
+	in := map[string]interface{}{"source": "kiosk"}
+
+	return call(ctx, http.MethodPost, closeURL, authorizeGenieKey(routingKey), in, nil)
+}
+
+func authorizeGenieKey(key string) func(r *http.Request) {
+	return func(r *http.Request) {
+		r.Header.Set("Authorization", "GenieKey "+key)
+	}
+}
@@ -0,0 +1,67 @@
+package connectors
+
+import (
+	"context"
+	"math"
+	"net/http"
+
+	"github.com/lireza/lib/configuring"
+	"go.uber.org/zap"
+)
+
+// SentimentAnalyzer scores the sentiment of texts, from -1 for the most negative to 1 for the most positive.
+type SentimentAnalyzer interface {
+	// Score returns back the sentiment score of the text.
+	Score(ctx context.Context, text string) (float64, error)
+}
+
+// ConfiguredSentimentAnalyzer returns back the sentiment analyzer configured in config instance. It is nil when none
+// is configured, in which case comments are not scored.
+func ConfiguredSentimentAnalyzer(logger *zap.SugaredLogger, config *configuring.Config) SentimentAnalyzer {
+	if analyzer := NewHTTPSentimentAnalyzer(logger, config); analyzer != nil {
+		return analyzer
+	}
+
+	return nil
+}
+
+// HTTPSentimentAnalyzer scores texts through an HTTP endpoint. The text is posted as {"text": "..."} and the endpoint
+// replies {"score": 0.0}, scores out of range are clamped.
+type HTTPSentimentAnalyzer struct {
+	url   string
+	token string
+}
+
+// NewHTTPSentimentAnalyzer returns back a newly created and ready to use HTTPSentimentAnalyzer configured by the
+// information provided in config instance, or nil when no url is configured.
+func NewHTTPSentimentAnalyzer(logger *zap.SugaredLogger, config *configuring.Config) *HTTPSentimentAnalyzer {
+	url := config.Get("connectors.sentiment.url").StringOrElse("")
+	token := config.Get("connectors.sentiment.token").StringOrElse("")
+
+	logger.Info("connectors.sentiment.url -> ", url)
+
+	if url == "" {
+		return nil
+	}
+
+	return &HTTPSentimentAnalyzer{url: url, token: token}
+}
+
+// Score posts the text to the endpoint.
+func (a *HTTPSentimentAnalyzer) Score(ctx context.Context, text string) (float64, error) {
+	out := &struct {
+		Score float64 `json:"score"`
+	}{}
+
+	if e := call(ctx, http.MethodPost, a.url, a.authorize, map[string]string{"text": text}, out); e != nil {
+		return 0, e
+	}
+
+	return math.Max(-1, math.Min(1, out.Score)), nil
+}
+
+func (a *HTTPSentimentAnalyzer) authorize(r *http.Request) {
+	if a.token != "" {
+		r.Header.Set("Authorization", "Bearer "+a.token)
+	}
+}
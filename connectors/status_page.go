@@ -0,0 +1,172 @@
+package connectors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jibitters/kiosk/models"
+	"github.com/lireza/lib/configuring"
+	"go.uber.org/zap"
+)
+
+// StatusPage publishes incidents on a customer visible status page.
+type StatusPage interface {
+	// Provider returns back the provider of the status page.
+	Provider() models.StatusPageProvider
+	// Create publishes the incident and returns back its external id.
+	Create(ctx context.Context, incident *models.StatusPageIncident) (string, error)
+	// Update updates the published incident identified by its external id.
+	Update(ctx context.Context, incident *models.StatusPageIncident) error
+}
+
+// ConfiguredStatusPage returns back the status page configured in config instance, Statuspage wins when both are
+// configured. It is nil when none is configured.
+func ConfiguredStatusPage(logger *zap.SugaredLogger, config *configuring.Config) StatusPage {
+	if statuspage := NewStatuspage(logger, config); statuspage != nil {
+		return statuspage
+	}
+
+	if cachet := NewCachet(logger, config); cachet != nil {
+		return cachet
+	}
+
+	return nil
+}
+
+// Statuspage publishes incidents on an Atlassian Statuspage page through its REST API.
+type Statuspage struct {
+	baseURL string
+	pageID  string
+	apiKey  string
+}
+
+// NewStatuspage returns back a newly created and ready to use Statuspage connector configured by the information
+// provided in config instance, or nil when no page id or API key is configured.
+func NewStatuspage(logger *zap.SugaredLogger, config *configuring.Config) *Statuspage {
+	baseURL := config.Get("connectors.statuspage.base_url").StringOrElse("https://api.statuspage.io")
+	pageID := config.Get("connectors.statuspage.page_id").StringOrElse("")
+	apiKey := config.Get("connectors.statuspage.api_key").StringOrElse("")
+
+	logger.Info("connectors.statuspage.base_url -> ", baseURL)
+	logger.Info("connectors.statuspage.page_id -> ", pageID)
+
+	if pageID == "" || apiKey == "" {
+		return nil
+	}
+
+	return &Statuspage{baseURL: strings.TrimSuffix(baseURL, "/"), pageID: pageID, apiKey: apiKey}
+}
+
+// Provider returns back STATUSPAGE.
+func (s *Statuspage) Provider() models.StatusPageProvider {
+	return models.StatusPageProviderStatuspage
+}
+
+// Create opens the incident.
+func (s *Statuspage) Create(ctx context.Context, incident *models.StatusPageIncident) (string, error) {
+	created := &struct {
+		ID string `json:"id"`
+	}{}
+
+	incidentsURL := fmt.Sprintf("%v/v1/pages/%v/incidents", s.baseURL, url.PathEscape(s.pageID))
+	if e := call(ctx, http.MethodPost, incidentsURL, s.authorize, s.body(incident), created); e != nil {
+		return "", e
+	}
+
+	return created.ID, nil
+}
+
+// Update posts the current message and status of the incident as an update.
+func (s *Statuspage) Update(ctx context.Context, incident *models.StatusPageIncident) error {
+	incidentURL := fmt.Sprintf("%v/v1/pages/%v/incidents/%v", s.baseURL, url.PathEscape(s.pageID),
+		url.PathEscape(incident.ExternalID))
+
+	return call(ctx, http.MethodPatch, incidentURL, s.authorize, s.body(incident), nil)
+}
+
+func (s *Statuspage) body(incident *models.StatusPageIncident) interface{} {
+	return map[string]interface{}{
+		"incident": map[string]string{
+			"name":   incident.Title,
+			"status": strings.ToLower(string(incident.Status)),
+			"body":   incident.Message,
+		},
+	}
+}
+
+func (s *Statuspage) authorize(r *http.Request) {
+	r.Header.Set("Authorization", "OAuth "+s.apiKey)
+}
+
+// cachetStatuses maps the status of incidents to the numeric statuses of Cachet.
+var cachetStatuses = map[models.StatusPageIncidentStatus]int{
+	models.StatusPageIncidentStatusInvestigating: 1,
+	models.StatusPageIncidentStatusIdentified:    2,
+	models.StatusPageIncidentStatusMonitoring:    3,
+	models.StatusPageIncidentStatusResolved:      4,
+}
+
+// Cachet publishes incidents on a self hosted Cachet status page through its REST API.
+type Cachet struct {
+	baseURL string
+	token   string
+}
+
+// NewCachet returns back a newly created and ready to use Cachet connector configured by the information provided in
+// config instance, or nil when no base url or token is configured.
+func NewCachet(logger *zap.SugaredLogger, config *configuring.Config) *Cachet {
+	baseURL := config.Get("connectors.cachet.base_url").StringOrElse("")
+	token := config.Get("connectors.cachet.token").StringOrElse("")
+
+	logger.Info("connectors.cachet.base_url -> ", baseURL)
+
+	if baseURL == "" || token == "" {
+		return nil
+	}
+
+	return &Cachet{baseURL: strings.TrimSuffix(baseURL, "/"), token: token}
+}
+
+// Provider returns back CACHET.
+func (c *Cachet) Provider() models.StatusPageProvider {
+	return models.StatusPageProviderCachet
+}
+
+// Create opens a visible incident.
+func (c *Cachet) Create(ctx context.Context, incident *models.StatusPageIncident) (string, error) {
+	created := &struct {
+		Data struct {
+			ID int64 `json:"id"`
+		} `json:"data"`
+	}{}
+
+	incidentsURL := c.baseURL + "/api/v1/incidents"
+	if e := call(ctx, http.MethodPost, incidentsURL, c.authorize, c.body(incident), created); e != nil {
+		return "", e
+	}
+
+	return strconv.FormatInt(created.Data.ID, 10), nil
+}
+
+// Update replaces the message and status of the incident.
+func (c *Cachet) Update(ctx context.Context, incident *models.StatusPageIncident) error {
+	incidentURL := c.baseURL + "/api/v1/incidents/" + url.PathEscape(incident.ExternalID)
+	return call(ctx, http.MethodPut, incidentURL, c.authorize, c.body(incident), nil)
+}
+
+func (c *Cachet) body(incident *models.StatusPageIncident) interface{} {
+	return map[string]interface{}{
+		"name":    incident.Title,
+		"message": incident.Message,
+		"status":  cachetStatuses[incident.Status],
+		"visible": 1,
+	}
+}
+
+func (c *Cachet) authorize(r *http.Request) {
+	r.Header.Set("X-Cachet-Token", c.token)
+}
@@ -0,0 +1,172 @@
+// Package filters builds the conditions of the WHERE clauses of dynamic filters. Only whitelisted columns and operators
+// make it into the query and values are always bound as parameters, never spliced into it, so filters built from
+// request input can not inject SQL.
+package filters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Operator is a comparison operator conditions can use.
+type Operator string
+
+// Different operator instances.
+const (
+	Equal          Operator = "="
+	NotEqual       Operator = "<>"
+	Less           Operator = "<"
+	LessOrEqual    Operator = "<="
+	Greater        Operator = ">"
+	GreaterOrEqual Operator = ">="
+	// Contains matches JSONB columns containing the JSON document bound as value.
+	Contains Operator = "@>"
+)
+
+// casts of the values bound for operators, if any.
+var casts = map[Operator]string{
+	Equal:          "",
+	NotEqual:       "",
+	Less:           "",
+	LessOrEqual:    "",
+	Greater:        "",
+	GreaterOrEqual: "",
+	Contains:       "::JSONB",
+}
+
+// Builder builds the conditions of a WHERE clause, all of which must hold. Conditions on columns or with operators
+// that are not whitelisted fail the build.
+type Builder struct {
+	columns map[string]bool
+	clauses []string
+	args    []interface{}
+	e       error
+}
+
+// New returns back a newly created and ready to use Builder allowing conditions on the provided columns only.
+func New(columns ...string) *Builder {
+	b := &Builder{columns: make(map[string]bool, len(columns))}
+	for _, column := range columns {
+		b.columns[column] = true
+	}
+
+	return b
+}
+
+// Where adds the condition of column compared to value with operator.
+func (b *Builder) Where(column string, operator Operator, value interface{}) *Builder {
+	cast, ok := casts[operator]
+	if !ok {
+		return b.fail(fmt.Errorf("operator %q is not allowed", operator))
+	}
+
+	if !b.columns[column] {
+		return b.fail(fmt.Errorf("column %q is not allowed", column))
+	}
+
+	b.clauses = append(b.clauses, column+` `+string(operator)+` `+b.Bind(value)+cast)
+	return b
+}
+
+// WhereNull adds the condition of column being NULL, or not being NULL when null is false.
+func (b *Builder) WhereNull(column string, null bool) *Builder {
+	if !b.columns[column] {
+		return b.fail(fmt.Errorf("column %q is not allowed", column))
+	}
+
+	if null {
+		b.clauses = append(b.clauses, column+` IS NULL`)
+	} else {
+		b.clauses = append(b.clauses, column+` IS NOT NULL`)
+	}
+
+	return b
+}
+
+// Key is a key records are ordered by, along with its value at the position records must come after. Nil values are
+// NULL, which come last in ascending orders and first in descending ones, as PostgreSQL orders them.
+type Key struct {
+	Column     string
+	Descending bool
+	Value      interface{}
+}
+
+// WhereAfter adds the condition of records coming after the position the keys hold, in the order of the keys. Records
+// ordered by the same keys can be paged by the position of the last record of each page, so pages stay stable while
+// records get inserted or updated, unlike pages skipping an offset number of records.
+func (b *Builder) WhereAfter(keys ...Key) *Builder {
+	alternatives := make([]string, 0, len(keys))
+	equalities := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if !b.columns[key.Column] {
+			return b.fail(fmt.Errorf("column %q is not allowed", key.Column))
+		}
+
+		placeholder := ""
+		if key.Value != nil {
+			placeholder = b.Bind(key.Value)
+		}
+
+		if after := keyAfter(key.Column, key.Descending, placeholder); after != "" {
+			alternatives = append(alternatives, `(`+strings.Join(append(equalities, after), ` AND `)+`)`)
+		}
+
+		if placeholder == "" {
+			equalities = append(equalities, key.Column+` IS NULL`)
+		} else {
+			equalities = append(equalities, key.Column+` = `+placeholder)
+		}
+	}
+
+	if len(alternatives) == 0 {
+		b.clauses = append(b.clauses, `FALSE`)
+	} else {
+		b.clauses = append(b.clauses, `(`+strings.Join(alternatives, ` OR `)+`)`)
+	}
+
+	return b
+}
+
+// keyAfter returns back the condition of column coming after the value bound to placeholder, NULL when empty, or
+// empty when nothing can come after it.
+func keyAfter(column string, descending bool, placeholder string) string {
+	switch {
+	case placeholder == "" && descending:
+		return column + ` IS NOT NULL`
+	case placeholder == "":
+		return ""
+	case descending:
+		return column + ` < ` + placeholder
+	default:
+		return `(` + column + ` > ` + placeholder + ` OR ` + column + ` IS NULL)`
+	}
+}
+
+// Bind binds value as the next parameter and returns back its placeholder, e.g. for the LIMIT of the query.
+func (b *Builder) Bind(value interface{}) string {
+	b.args = append(b.args, value)
+	return `$` + strconv.Itoa(len(b.args))
+}
+
+// Build returns back the conditions, prefixed by a space so they can follow WHERE, along with the values bound to
+// their parameters. It fails on the first condition that was not allowed.
+func (b *Builder) Build() (string, []interface{}, error) {
+	if b.e != nil {
+		return "", nil, b.e
+	}
+
+	if len(b.clauses) == 0 {
+		return ` TRUE`, b.args, nil
+	}
+
+	return ` ` + strings.Join(b.clauses, ` AND `), b.args, nil
+}
+
+func (b *Builder) fail(e error) *Builder {
+	if b.e == nil {
+		b.e = e
+	}
+
+	return b
+}
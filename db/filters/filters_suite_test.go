@@ -0,0 +1,13 @@
+package filters_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestFilters(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Filters Suite")
+}
@@ -0,0 +1,129 @@
+package filters_test
+
+import (
+	"math/rand"
+	"regexp"
+	"strings"
+
+	"github.com/jibitters/kiosk/db/filters"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// payloads are fragments commonly used to break out of values or identifiers.
+var payloads = []string{"'", "\"", ";", "--", "/*", "*/", " OR 1=1", "'; DROP TABLE tickets; --", "$1", "\\", "\x00",
+	") OR (TRUE", "UNION SELECT", "::JSONB", "%", "_"}
+
+func fuzz(r *rand.Rand) string {
+	s := strings.Builder{}
+	for i := r.Intn(6); i >= 0; i-- {
+		if r.Intn(2) == 0 {
+			s.WriteString(payloads[r.Intn(len(payloads))])
+		} else {
+			s.WriteRune(rune(r.Intn(0x3000) + 1))
+		}
+	}
+
+	return s.String()
+}
+
+var _ = Describe("Filters", func() {
+	Context("When Build called", func() {
+		It("Should bind values to the whitelisted columns", func() {
+			conditions, args, e := filters.New("issuer", "metadata", "snoozed_until").
+				Where("issuer", filters.Equal, "Microservice-A").
+				Where("metadata", filters.Contains, `{"ip":"10.0.0.1"}`).
+				WhereNull("snoozed_until", true).
+				Build()
+
+			Ω(e).Should(BeNil())
+			Ω(conditions).Should(Equal(` issuer = $1 AND metadata @> $2::JSONB AND snoozed_until IS NULL`))
+			Ω(args).Should(Equal([]interface{}{"Microservice-A", `{"ip":"10.0.0.1"}`}))
+		})
+
+		It("Should match everything without conditions", func() {
+			conditions, args, e := filters.New("issuer").Build()
+
+			Ω(e).Should(BeNil())
+			Ω(conditions).Should(Equal(` TRUE`))
+			Ω(args).Should(BeEmpty())
+		})
+
+		It("Should match the records after a position, placing NULL values as PostgreSQL orders them", func() {
+			conditions, args, e := filters.New("created_at", "resolved_at", "id").
+				WhereAfter(filters.Key{Column: "created_at", Descending: true, Value: "2020-01-01T00:00:00Z"},
+					filters.Key{Column: "resolved_at"}, filters.Key{Column: "id", Value: "5"}).
+				Build()
+
+			Ω(e).Should(BeNil())
+			Ω(conditions).Should(Equal(` ((created_at < $1) OR ` +
+				`(created_at = $1 AND resolved_at IS NULL AND (id > $2 OR id IS NULL)))`))
+			Ω(args).Should(Equal([]interface{}{"2020-01-01T00:00:00Z", "5"}))
+
+			conditions, _, e = filters.New("resolved_at").
+				WhereAfter(filters.Key{Column: "resolved_at", Descending: true}).
+				Build()
+
+			Ω(e).Should(BeNil())
+			Ω(conditions).Should(Equal(` (resolved_at IS NOT NULL)`))
+
+			conditions, _, e = filters.New("resolved_at").WhereAfter(filters.Key{Column: "resolved_at"}).Build()
+
+			Ω(e).Should(BeNil())
+			Ω(conditions).Should(Equal(` FALSE`))
+		})
+
+		It("Should fail on columns and operators that are not whitelisted", func() {
+			_, _, e := filters.New("issuer").Where("owner", filters.Equal, "user@example.com").Build()
+			Ω(e).ShouldNot(BeNil())
+
+			_, _, e = filters.New("issuer").WhereNull("issuer = issuer OR issuer", true).Build()
+			Ω(e).ShouldNot(BeNil())
+
+			_, _, e = filters.New("issuer").WhereAfter(filters.Key{Column: "id", Value: "1"}).Build()
+			Ω(e).ShouldNot(BeNil())
+
+			_, _, e = filters.New("issuer").Where("issuer", filters.Operator("= '' OR TRUE OR issuer ="), "").Build()
+			Ω(e).ShouldNot(BeNil())
+		})
+
+		It("Should never let fuzzed input into the query", func() {
+			r := rand.New(rand.NewSource(GinkgoRandomSeed()))
+			operators := []filters.Operator{filters.Equal, filters.NotEqual, filters.Less, filters.LessOrEqual,
+				filters.Greater, filters.GreaterOrEqual, filters.Contains}
+			placeholder := regexp.MustCompile(`\$[0-9]+`)
+
+			for i := 0; i < 1000; i++ {
+				b := filters.New("issuer", "owner")
+				values := make([]interface{}, 0)
+				rejected := false
+
+				for j := r.Intn(5); j >= 0; j-- {
+					column, value := "issuer", fuzz(r)
+					switch r.Intn(3) {
+					case 0:
+						column = fuzz(r)
+						rejected = true
+					case 1:
+						column = "owner"
+					}
+
+					b.Where(column, operators[r.Intn(len(operators))], value)
+					values = append(values, value)
+				}
+
+				conditions, args, e := b.Build()
+				if rejected {
+					Ω(e).ShouldNot(BeNil())
+					continue
+				}
+
+				Ω(e).Should(BeNil())
+				Ω(args).Should(Equal(values))
+				Ω(placeholder.FindAllString(conditions, -1)).Should(HaveLen(len(args)))
+				Ω(placeholder.ReplaceAllString(conditions, "")).Should(MatchRegexp(
+					`^( (issuer|owner) (=|<>|<|<=|>|>=|@>) (::JSONB)?( AND)?)+$`))
+			}
+		})
+	})
+})
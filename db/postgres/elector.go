@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+)
+
+// electionInterval is how often followers campaign for the leadership and the leader checks it still holds it.
+const electionInterval = 5 * time.Second
+
+// Elector elects a single leader among the kiosk instances sharing a database, by holding a session level advisory
+// lock on a dedicated connection of the pool. Once the leader goes away its session ends, the lock gets released and
+// another instance takes over.
+type Elector struct {
+	logger   *zap.SugaredLogger
+	db       *pgxpool.Pool
+	key      int64
+	identity string
+	leader   int32
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewElector returns back a newly created and ready to use Elector. Instances campaigning with the same key compete
+// for the same leadership, identity names this instance in logs.
+func NewElector(logger *zap.SugaredLogger, db *pgxpool.Pool, key int64, identity string) *Elector {
+	return &Elector{logger: logger, db: db, key: key, identity: identity, done: make(chan struct{})}
+}
+
+// Start starts campaigning in background until Stop gets called.
+func (el *Elector) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	el.cancel = cancel
+
+	go func() {
+		defer close(el.done)
+
+		for ctx.Err() == nil {
+			if e := el.campaign(ctx); e != nil && ctx.Err() == nil {
+				el.logger.Warn("Campaigning for leadership interrupted: ", e.Error())
+			}
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(electionInterval):
+			}
+		}
+	}()
+}
+
+// IsLeader reports whether this instance currently holds the leadership.
+func (el *Elector) IsLeader() bool {
+	return atomic.LoadInt32(&el.leader) == 1
+}
+
+func (el *Elector) campaign(ctx context.Context) error {
+	connection, e := el.db.Acquire(ctx)
+	if e != nil {
+		return e
+	}
+	defer connection.Release()
+
+	var acquired bool
+	if e := connection.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", el.key).Scan(&acquired); e != nil {
+		return e
+	}
+
+	if !acquired {
+		return nil
+	}
+
+	// The lock lives as long as the session, so the connection gets closed rather than handed back to the pool.
+	defer func() { _ = connection.Conn().Close(context.Background()) }()
+
+	atomic.StoreInt32(&el.leader, 1)
+	defer atomic.StoreInt32(&el.leader, 0)
+
+	el.logger.Info("Instance ", el.identity, " took over the leadership")
+	defer el.logger.Info("Instance ", el.identity, " gave up the leadership")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(electionInterval):
+		}
+
+		if e := el.ping(ctx, connection); e != nil {
+			return e
+		}
+	}
+}
+
+func (el *Elector) ping(ctx context.Context, connection *pgxpool.Conn) error {
+	ctx, cancel := context.WithTimeout(ctx, electionInterval)
+	defer cancel()
+
+	_, e := connection.Exec(ctx, "SELECT 1")
+	return e
+}
+
+// Stop stops campaigning, gives up the leadership if held and waits for the background routine to finish.
+func (el *Elector) Stop() {
+	if el.cancel == nil {
+		return
+	}
+
+	el.cancel()
+	<-el.done
+}
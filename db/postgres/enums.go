@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// literal matches the string literals of a constraint definition, e.g. 'NEW'::character varying.
+var literal = regexp.MustCompile(`'((?:[^']|'')*)'`)
+
+// VerifyEnumConstraints verifies the check constraints of the schema, keyed by their names, allow exactly the values
+// of the enums of this binary. Otherwise values this binary writes get rejected, or values it does not know of get
+// written by other writers.
+func VerifyEnumConstraints(ctx context.Context, db *pgxpool.Pool, enums map[string][]string) error {
+	names := make([]string, 0, len(enums))
+	for name := range enums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	q := `SELECT conname, pg_get_constraintdef(oid) FROM pg_constraint WHERE contype = 'c' AND conname = ANY($1);`
+
+	rows, e := db.Query(ctx, q, names)
+	if e != nil {
+		return e
+	}
+	defer rows.Close()
+
+	definitions := make(map[string]string)
+	for rows.Next() {
+		var name, definition string
+		if e := rows.Scan(&name, &definition); e != nil {
+			return e
+		}
+
+		definitions[name] = definition
+	}
+
+	if e := rows.Err(); e != nil {
+		return e
+	}
+
+	for _, name := range names {
+		definition, ok := definitions[name]
+		if !ok {
+			return fmt.Errorf("check constraint %s is missing from the database schema", name)
+		}
+
+		allowed := constraintValues(definition)
+		expected := append([]string(nil), enums[name]...)
+		sort.Strings(expected)
+
+		if strings.Join(allowed, ",") != strings.Join(expected, ",") {
+			return fmt.Errorf("check constraint %s allows %v but this binary expects %v, add a migration altering "+
+				"the constraint along with the enum", name, allowed, expected)
+		}
+	}
+
+	return nil
+}
+
+// constraintValues returns back the distinct string literals of a constraint definition, sorted.
+func constraintValues(definition string) []string {
+	seen := make(map[string]bool)
+	values := make([]string, 0)
+	for _, match := range literal.FindAllStringSubmatch(definition, -1) {
+		value := strings.ReplaceAll(match[1], "''", "'")
+		if !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	sort.Strings(values)
+
+	return values
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+)
+
+// Listener receives the notifications of a postgres channel on a dedicated connection of the pool, so kiosk
+// instances can coordinate through the database they already share.
+type Listener struct {
+	logger  *zap.SugaredLogger
+	db      *pgxpool.Pool
+	channel string
+	handle  func(payload string)
+	reset   func()
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewListener returns back a newly created and ready to use Listener. Handle is called with the payload of every
+// notification, reset is called whenever notifications may have been missed, e.g. after a reconnect.
+func NewListener(logger *zap.SugaredLogger, db *pgxpool.Pool, channel string, handle func(payload string),
+	reset func()) *Listener {
+
+	return &Listener{logger: logger, db: db, channel: channel, handle: handle, reset: reset,
+		done: make(chan struct{})}
+}
+
+// Start starts listening in background until Stop gets called.
+func (l *Listener) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+
+	go func() {
+		defer close(l.done)
+
+		for ctx.Err() == nil {
+			if e := l.listen(ctx); e != nil && ctx.Err() == nil {
+				l.logger.Warn("Listening on ", l.channel, " interrupted: ", e.Error())
+
+				select {
+				case <-ctx.Done():
+				case <-time.After(time.Second):
+				}
+			}
+		}
+	}()
+}
+
+func (l *Listener) listen(ctx context.Context) error {
+	connection, e := l.db.Acquire(ctx)
+	if e != nil {
+		return e
+	}
+	defer connection.Release()
+
+	if _, e := connection.Exec(ctx, "LISTEN "+pgx.Identifier{l.channel}.Sanitize()); e != nil {
+		return e
+	}
+
+	// Anything could have happened before this point, so caches start over.
+	l.reset()
+
+	for {
+		notification, e := connection.Conn().WaitForNotification(ctx)
+		if e != nil {
+			return e
+		}
+
+		l.handle(notification.Payload)
+	}
+}
+
+// Stop stops listening and waits for the background routine to finish.
+func (l *Listener) Stop() {
+	if l.cancel == nil {
+		return
+	}
+
+	l.cancel()
+	<-l.done
+}
@@ -2,13 +2,27 @@ package postgres
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source/httpfs"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/migration"
 	"github.com/lireza/lib/configuring"
 	"go.uber.org/zap"
 )
 
+// SchemaVersion is the database schema version this binary works with, i.e. the version of the last migration in
+// migration directory, embedded in the binary. It must be bumped along with every new migration.
+const SchemaVersion = 59
+
+// migrationLockKey is the advisory lock key migrations run under, so instances starting at once migrate one at a time.
+const migrationLockKey = 0x6b696f736b6d
+
 // Connect tries to connect to a postgres instance with the information provided in config instance.
 func Connect(logger *zap.SugaredLogger, config *configuring.Config) (*pgxpool.Pool, error) {
 	connectionString := config.Get("db.postgres.connection_string").
@@ -21,13 +35,140 @@ func Connect(logger *zap.SugaredLogger, config *configuring.Config) (*pgxpool.Po
 		IntOrElse(8)
 
 	migrationDirectory := config.Get("db.postgres.migration_directory").
-		StringOrElse("file://migration/postgres")
+		StringOrElse("")
 
 	logger.Debug("db.postgres.connection_string -> ", connectionString)
 	logger.Info("db.postgres.pool_min_connections -> ", minPoolConnections)
 	logger.Info("db.postgres.pool_max_connections -> ", maxPoolConnections)
 	logger.Info("db.postgres.migration_directory -> ", migrationDirectory)
 
+	return connect(connectionString, minPoolConnections, maxPoolConnections, interactiveTimeout(logger, config),
+		newSlowQueryLogger(logger, config), rowLevelSecurity(logger, config), false)
+}
+
+// ConnectReporting tries to connect to the postgres instance configured in config instance with a small pool of its
+// own for reporting queries. Their statement timeout is longer than the one of interactive queries, while keeping
+// runaway analytical queries from hogging the database. The pool connects with the restricted role of
+// db.postgres.readonly when one is configured, and its sessions are read only either way.
+func ConnectReporting(logger *zap.SugaredLogger, config *configuring.Config) (*pgxpool.Pool, error) {
+	connectionString := config.Get("db.postgres.readonly.connection_string").StringOrElse("")
+	if connectionString == "" {
+		connectionString = config.Get("db.postgres.connection_string").
+			StringOrElse("postgres://localhost:5432/kiosk?sslmode=disable")
+	}
+
+	minPoolConnections := config.Get("db.postgres.reporting.pool_min_connections").IntOrElse(1)
+	maxPoolConnections := config.Get("db.postgres.reporting.pool_max_connections").IntOrElse(2)
+	statementTimeout := config.Get("db.postgres.statement_timeout.reporting").DurationOrElse(25 * time.Second)
+
+	logger.Info("db.postgres.reporting.pool_min_connections -> ", minPoolConnections)
+	logger.Info("db.postgres.reporting.pool_max_connections -> ", maxPoolConnections)
+	logger.Info("db.postgres.statement_timeout.reporting -> ", statementTimeout)
+
+	return connect(connectionString, minPoolConnections, maxPoolConnections, statementTimeout,
+		newSlowQueryLogger(logger, config), rowLevelSecurity(logger, config), true)
+}
+
+// ConnectReadOnly tries to connect to the postgres instance configured in config instance with the restricted role of
+// db.postgres.readonly, for search queries. The role should only be granted SELECT, on top of that its sessions are
+// read only, so a bug or an injection in those paths can not mutate data. A nil pool is returned back when no read
+// only role is configured, so search queries are served by the primary.
+func ConnectReadOnly(logger *zap.SugaredLogger, config *configuring.Config) (*pgxpool.Pool, error) {
+	connectionString := config.Get("db.postgres.readonly.connection_string").StringOrElse("")
+	minPoolConnections := config.Get("db.postgres.readonly.pool_min_connections").IntOrElse(1)
+	maxPoolConnections := config.Get("db.postgres.readonly.pool_max_connections").IntOrElse(4)
+
+	logger.Debug("db.postgres.readonly.connection_string -> ", connectionString)
+	logger.Info("db.postgres.readonly.pool_min_connections -> ", minPoolConnections)
+	logger.Info("db.postgres.readonly.pool_max_connections -> ", maxPoolConnections)
+
+	if connectionString == "" {
+		return nil, nil
+	}
+
+	return connect(connectionString, minPoolConnections, maxPoolConnections, interactiveTimeout(logger, config),
+		newSlowQueryLogger(logger, config), rowLevelSecurity(logger, config), true)
+}
+
+// ConnectReplica tries to connect to the read replica configured in config instance. A nil pool is returned back when
+// no replica is configured, so all reads are served by the primary.
+func ConnectReplica(logger *zap.SugaredLogger, config *configuring.Config) (*pgxpool.Pool, error) {
+	connectionString := config.Get("db.postgres.replica.connection_string").StringOrElse("")
+	minPoolConnections := config.Get("db.postgres.replica.pool_min_connections").IntOrElse(2)
+	maxPoolConnections := config.Get("db.postgres.replica.pool_max_connections").IntOrElse(8)
+
+	logger.Debug("db.postgres.replica.connection_string -> ", connectionString)
+	logger.Info("db.postgres.replica.pool_min_connections -> ", minPoolConnections)
+	logger.Info("db.postgres.replica.pool_max_connections -> ", maxPoolConnections)
+
+	if connectionString == "" {
+		return nil, nil
+	}
+
+	return connect(connectionString, minPoolConnections, maxPoolConnections, interactiveTimeout(logger, config),
+		newSlowQueryLogger(logger, config), rowLevelSecurity(logger, config), false)
+}
+
+// ConnectShadow tries to connect to the postgres instance configured in config instance to shadow ticket filters on,
+// e.g. one running a new major version or a rewritten schema. Its sessions are read only, as shadows only ever serve
+// filters whose results get compared. A nil pool is returned back when no shadow is configured.
+func ConnectShadow(logger *zap.SugaredLogger, config *configuring.Config) (*pgxpool.Pool, error) {
+	connectionString := config.Get("db.postgres.shadow.connection_string").StringOrElse("")
+	minPoolConnections := config.Get("db.postgres.shadow.pool_min_connections").IntOrElse(1)
+	maxPoolConnections := config.Get("db.postgres.shadow.pool_max_connections").IntOrElse(4)
+
+	logger.Debug("db.postgres.shadow.connection_string -> ", connectionString)
+	logger.Info("db.postgres.shadow.pool_min_connections -> ", minPoolConnections)
+	logger.Info("db.postgres.shadow.pool_max_connections -> ", maxPoolConnections)
+
+	if connectionString == "" {
+		return nil, nil
+	}
+
+	return connect(connectionString, minPoolConnections, maxPoolConnections, interactiveTimeout(logger, config),
+		newSlowQueryLogger(logger, config), rowLevelSecurity(logger, config), true)
+}
+
+// interactiveTimeout returns back the statement timeout of interactive queries configured in config instance. It is
+// kept below the timeouts services put on handling requests, so exceeded statements get reported as such.
+func interactiveTimeout(logger *zap.SugaredLogger, config *configuring.Config) time.Duration {
+	statementTimeout := config.Get("db.postgres.statement_timeout.interactive").DurationOrElse(4 * time.Second)
+	logger.Info("db.postgres.statement_timeout.interactive -> ", statementTimeout)
+
+	return statementTimeout
+}
+
+// rowLevelSecurity returns back whether queries are scoped to the tenants of their contexts, for the row level security
+// policies of the schema to isolate tenants from each other, as configured in config instance.
+func rowLevelSecurity(logger *zap.SugaredLogger, config *configuring.Config) bool {
+	enabled := config.Get("db.postgres.row_level_security").StringOrElse("false") == "true"
+	logger.Info("db.postgres.row_level_security -> ", enabled)
+
+	return enabled
+}
+
+// newSlowQueryLogger returns back the slow query logger configured in config instance, or nil when slow queries are
+// not logged.
+func newSlowQueryLogger(logger *zap.SugaredLogger, config *configuring.Config) *slowQueryLogger {
+	threshold := config.Get("db.postgres.slow_query.threshold").DurationOrElse(500 * time.Millisecond)
+	explain := config.Get("db.postgres.slow_query.explain").StringOrElse("false") == "true"
+
+	logger.Info("db.postgres.slow_query.threshold -> ", threshold)
+	logger.Info("db.postgres.slow_query.explain -> ", explain)
+
+	if threshold <= 0 {
+		return nil
+	}
+
+	return &slowQueryLogger{logger: logger, threshold: threshold, explain: explain}
+}
+
+// connect connects a pool whose statements are canceled by postgres after statementTimeout, zero disables the timeout.
+// With rowLevelSecurity, connections are scoped to the tenant of the context they are acquired with. With readOnly,
+// sessions can not write.
+func connect(connectionString string, minPoolConnections, maxPoolConnections int, statementTimeout time.Duration,
+	slowQueryLogger *slowQueryLogger, rowLevelSecurity, readOnly bool) (*pgxpool.Pool, error) {
+
 	dbConfig, e := pgxpool.ParseConfig(connectionString)
 	if e != nil {
 		return nil, e
@@ -36,31 +177,172 @@ func Connect(logger *zap.SugaredLogger, config *configuring.Config) (*pgxpool.Po
 	dbConfig.MinConns = int32(minPoolConnections)
 	dbConfig.MaxConns = int32(maxPoolConnections)
 
+	if statementTimeout > 0 {
+		dbConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(statementTimeout.Milliseconds(), 10)
+	}
+
+	if readOnly {
+		dbConfig.ConnConfig.RuntimeParams["default_transaction_read_only"] = "on"
+	}
+
+	if slowQueryLogger != nil {
+		dbConfig.ConnConfig.Logger = slowQueryLogger
+		dbConfig.ConnConfig.LogLevel = pgx.LogLevelInfo
+	}
+
+	if rowLevelSecurity {
+		dbConfig.BeforeAcquire = scopeToTenant
+	}
+
 	db, e := pgxpool.ConnectConfig(context.Background(), dbConfig)
 	if e != nil {
 		return nil, e
 	}
 
+	if slowQueryLogger != nil {
+		slowQueryLogger.db = db
+	}
+
 	return db, nil
 }
 
-// Migrate tries to connect to a postgres instance and then runs database migration.
+// Migrate tries to connect to a postgres instance and then runs database migration. It fails when the resulting schema
+// version is not the one this binary expects, rather than failing on the first query later. Migrations run under an
+// advisory lock, so instances starting at once do not race each other, the ones waiting for the lock find the schema
+// migrated once they acquire it.
 func Migrate(logger *zap.SugaredLogger, config *configuring.Config) error {
 	connectionString := config.Get("db.postgres.connection_string").
 		StringOrElse("postgres://localhost:5432/kiosk?sslmode=disable")
 
-	migrationDirectory := config.Get("db.postgres.migration_directory").
-		StringOrElse("file://migration/postgres")
+	lockTimeout := config.Get("db.postgres.migration_lock_timeout").DurationOrElse(5 * time.Minute)
+	logger.Info("db.postgres.migration_lock_timeout -> ", lockTimeout)
 
-	migratory, e := migrate.New(migrationDirectory, connectionString)
+	unlock, e := lockMigrations(logger, connectionString, lockTimeout)
 	if e != nil {
 		return e
 	}
+	defer unlock()
+
+	migratory, e := newMigratory(config, connectionString)
+	if e != nil {
+		return e
+	}
+	defer func() { _, _ = migratory.Close() }()
+
+	if e := checkSchemaVersion(migratory, SchemaVersion); e != nil {
+		return e
+	}
 
 	if e := migratory.Up(); e != nil && e != migrate.ErrNoChange {
 		return e
 	}
 
-	logger.Info("Successfully executed database migration.")
+	version, _, e := migratory.Version()
+	if e != nil {
+		return e
+	}
+
+	if version != SchemaVersion {
+		return fmt.Errorf("database schema is at version %d after migration but this binary expects version %d, "+
+			"check db.postgres.migration_directory, if set, points to the migrations of this binary",
+			version, SchemaVersion)
+	}
+
+	logger.Info("Successfully executed database migration, schema is at version ", version, ".")
+	return nil
+}
+
+// newMigratory returns back a migratory running the migrations embedded in the binary, or the ones of the directory
+// configured as db.postgres.migration_directory if any, e.g. file://migration/postgres while developing migrations.
+func newMigratory(config *configuring.Config, connectionString string) (*migrate.Migrate, error) {
+	migrationDirectory := config.Get("db.postgres.migration_directory").StringOrElse("")
+	if migrationDirectory != "" {
+		return migrate.New(migrationDirectory, connectionString)
+	}
+
+	source, e := httpfs.New(http.FS(migration.Postgres), "postgres")
+	if e != nil {
+		return nil, e
+	}
+
+	return migrate.NewWithSourceInstance("httpfs", source, connectionString)
+}
+
+// lockMigrations waits up to timeout for the advisory lock of migrations, held by a connection of its own, and returns
+// back the function releasing it.
+func lockMigrations(logger *zap.SugaredLogger, connectionString string, timeout time.Duration) (func(), error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	connection, e := pgx.Connect(ctx, connectionString)
+	if e != nil {
+		return nil, e
+	}
+
+	if _, e := connection.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); e != nil {
+		_ = connection.Close(context.Background())
+		return nil, fmt.Errorf("could not acquire the migration lock within %s, another instance may be stuck "+
+			"migrating: %w", timeout, e)
+	}
+
+	logger.Debug("Acquired the migration lock.")
+	return func() {
+		_, _ = connection.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockKey)
+		_ = connection.Close(context.Background())
+	}, nil
+}
+
+// CheckSchema tries to connect to a postgres instance and verifies its schema is at the version this binary expects,
+// without migrating it. It serves deployments that migrate with the migrate command ahead of rolling out instances.
+func CheckSchema(logger *zap.SugaredLogger, config *configuring.Config) error {
+	connectionString := config.Get("db.postgres.connection_string").
+		StringOrElse("postgres://localhost:5432/kiosk?sslmode=disable")
+
+	migratory, e := newMigratory(config, connectionString)
+	if e != nil {
+		return e
+	}
+	defer func() { _, _ = migratory.Close() }()
+
+	if e := checkSchemaVersion(migratory, SchemaVersion); e != nil {
+		return e
+	}
+
+	version, _, e := migratory.Version()
+	if e != nil && e != migrate.ErrNilVersion {
+		return e
+	}
+
+	if version != SchemaVersion {
+		return fmt.Errorf("database schema is at version %d but this binary expects version %d and "+
+			"db.postgres.auto_migrate is disabled, run the migrate command of this binary first", version,
+			SchemaVersion)
+	}
+
+	logger.Info("Database schema is at version ", version, ", skipped migration.")
+	return nil
+}
+
+// checkSchemaVersion verifies the current schema can be migrated up to the expected version.
+func checkSchemaVersion(migratory *migrate.Migrate, expected uint) error {
+	version, dirty, e := migratory.Version()
+	if e == migrate.ErrNilVersion {
+		return nil
+	}
+
+	if e != nil {
+		return e
+	}
+
+	if dirty {
+		return fmt.Errorf("database schema is dirty at version %d, fix the failed migration manually and force its "+
+			"version before starting again", version)
+	}
+
+	if version > expected {
+		return fmt.Errorf("database schema is at version %d which is newer than version %d this binary expects, "+
+			"deploy a newer kiosk release", version, expected)
+	}
+
 	return nil
 }
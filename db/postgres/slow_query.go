@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// slowQueryLogger is a pgx logger logging the statements that take longer than a threshold, so missing indexes can be
+// found in production. Parameters are redacted down to their types as they may hold personal data. When explain is
+// enabled and debug logs are on, the plan of slow statements is logged as well.
+type slowQueryLogger struct {
+	logger    *zap.SugaredLogger
+	threshold time.Duration
+	explain   bool
+	// db runs the EXPLAIN statements, it is set once the pool is connected.
+	db *pgxpool.Pool
+}
+
+// Log logs the statement described by data if it took at least as long as the threshold.
+func (l *slowQueryLogger) Log(_ context.Context, _ pgx.LogLevel, _ string, data map[string]interface{}) {
+	sql, _ := data["sql"].(string)
+	duration, ok := data["time"].(time.Duration)
+	if !ok || sql == "" || duration < l.threshold {
+		return
+	}
+
+	// Plans of slow statements are slow too, logging them again would loop.
+	if strings.HasPrefix(sql, "EXPLAIN ") {
+		return
+	}
+
+	args, _ := data["args"].([]interface{})
+	statement := strings.Join(strings.Fields(sql), " ")
+	l.logger.Warn("Slow query took ", duration, ": ", statement, " with parameters ", redact(args))
+
+	if l.explain && l.db != nil && l.logger.Desugar().Core().Enabled(zapcore.DebugLevel) {
+		go l.explainPlan(statement, args)
+	}
+}
+
+// explainPlan logs the plan of the statement at debug level. Long text parameters are truncated in logs, so the plan
+// is built for those truncated values.
+func (l *slowQueryLogger) explainPlan(statement string, args []interface{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, e := l.db.Query(ctx, "EXPLAIN "+statement, args...)
+	if e != nil {
+		l.logger.Debug("Could not explain slow query: ", e.Error())
+		return
+	}
+	defer rows.Close()
+
+	plan := make([]string, 0)
+	for rows.Next() {
+		var line string
+		if e := rows.Scan(&line); e != nil {
+			l.logger.Debug("Could not explain slow query: ", e.Error())
+			return
+		}
+
+		plan = append(plan, line)
+	}
+
+	l.logger.Debug("Plan of slow query ", statement, ":\n", strings.Join(plan, "\n"))
+}
+
+// redact replaces the parameters of a statement with their types.
+func redact(args []interface{}) []string {
+	redacted := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == nil {
+			redacted = append(redacted, "NULL")
+			continue
+		}
+
+		redacted = append(redacted, fmt.Sprintf("%T", arg))
+	}
+
+	return redacted
+}
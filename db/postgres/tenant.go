@@ -0,0 +1,31 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// tenantKey is the key of the tenant within contexts.
+type tenantKey struct{}
+
+// WithTenant returns back a copy of ctx whose queries act on behalf of the tenant, i.e. an issuer. With row level
+// security enabled, they only see and write the rows of that tenant.
+func WithTenant(ctx context.Context, issuer string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, issuer)
+}
+
+// TenantOf returns back the tenant queries of ctx act on behalf of, empty when they act on behalf of no tenant in
+// particular, e.g. the ones of scheduled jobs.
+func TenantOf(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// scopeToTenant sets the tenant of a connection acquired from the pool to the one of ctx, for the row level security
+// policies to apply. It is set on every acquisition, so the tenant of a request never leaks into the next one. The
+// connection gets destroyed when setting it fails.
+func scopeToTenant(ctx context.Context, conn *pgx.Conn) bool {
+	_, e := conn.Exec(ctx, `SELECT set_config('kiosk.tenant', $1, FALSE);`, TenantOf(ctx))
+	return e == nil
+}
@@ -0,0 +1,187 @@
+package diffing
+
+import (
+	"unicode"
+)
+
+// Operation tells how a chunk of text changed between two revisions.
+type Operation string
+
+// Different operation instances.
+const (
+	OperationEqual  Operation = "EQUAL"
+	OperationInsert Operation = "INSERT"
+	OperationDelete Operation = "DELETE"
+)
+
+// Change is a chunk of text kept, inserted or deleted between two revisions.
+type Change struct {
+	Operation Operation
+	Text      string
+}
+
+// maxCells bounds the work of diffing two texts, texts with more words than that are reported as entirely replaced.
+const maxCells = 4000000
+
+// Words diffs two texts word by word, keeping white spaces as words of their own so nothing gets lost. Joining the
+// equal and deleted chunks gives back from, and joining the equal and inserted ones gives back to. Deletions precede
+// insertions wherever a chunk of text got replaced, and replaced words separated by nothing but white spaces are
+// reported as a single replaced chunk.
+func Words(from, to string) []Change {
+	a, b := tokenize(from), tokenize(to)
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	changes := make([]Change, 0)
+	changes = appendChange(changes, OperationEqual, a[:prefix]...)
+	changes = append(changes, diff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	changes = appendChange(changes, OperationEqual, a[len(a)-suffix:]...)
+
+	return cleanup(changes)
+}
+
+// diff finds the longest common subsequence of the words and reports the rest as deleted or inserted.
+func diff(a, b []string) []Change {
+	changes := make([]Change, 0)
+	if len(a) == 0 || len(b) == 0 || len(a)*len(b) > maxCells {
+		changes = appendChange(changes, OperationDelete, a...)
+		return appendChange(changes, OperationInsert, b...)
+	}
+
+	// lengths[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	deleted, inserted := make([]string, 0), make([]string, 0)
+	flush := func() {
+		changes = appendChange(changes, OperationDelete, deleted...)
+		changes = appendChange(changes, OperationInsert, inserted...)
+		deleted, inserted = deleted[:0], inserted[:0]
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			flush()
+			changes = appendChange(changes, OperationEqual, a[i])
+			i, j = i+1, j+1
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			deleted = append(deleted, a[i])
+			i++
+		default:
+			inserted = append(inserted, b[j])
+			j++
+		}
+	}
+
+	deleted, inserted = append(deleted, a[i:]...), append(inserted, b[j:]...)
+	flush()
+
+	return changes
+}
+
+// cleanup merges the changes surrounding white spaces that are kept in between, so replacing a few words in a row
+// reads as a single replacement.
+func cleanup(changes []Change) []Change {
+	cleaned := make([]Change, 0, len(changes))
+	deleted, inserted := "", ""
+	flush := func() {
+		if deleted != "" {
+			cleaned = append(cleaned, Change{Operation: OperationDelete, Text: deleted})
+		}
+
+		if inserted != "" {
+			cleaned = append(cleaned, Change{Operation: OperationInsert, Text: inserted})
+		}
+
+		deleted, inserted = "", ""
+	}
+
+	for k, change := range changes {
+		switch {
+		case change.Operation == OperationDelete:
+			deleted += change.Text
+		case change.Operation == OperationInsert:
+			inserted += change.Text
+		case (deleted != "" || inserted != "") && k+1 < len(changes) && isSpace(change.Text):
+			deleted, inserted = deleted+change.Text, inserted+change.Text
+		default:
+			flush()
+			cleaned = append(cleaned, change)
+		}
+	}
+
+	flush()
+	return cleaned
+}
+
+func isSpace(text string) bool {
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// appendChange appends the words as a change, merging them into the last change when it is of the same operation.
+func appendChange(changes []Change, operation Operation, words ...string) []Change {
+	if len(words) == 0 {
+		return changes
+	}
+
+	text := ""
+	for _, word := range words {
+		text += word
+	}
+
+	if last := len(changes) - 1; last >= 0 && changes[last].Operation == operation {
+		changes[last].Text += text
+		return changes
+	}
+
+	return append(changes, Change{Operation: operation, Text: text})
+}
+
+// tokenize splits the text into runs of white spaces and runs of anything else.
+func tokenize(text string) []string {
+	tokens := make([]string, 0)
+	start, space := 0, false
+	for i, r := range text {
+		if i > start && unicode.IsSpace(r) != space {
+			tokens = append(tokens, text[start:i])
+			start = i
+		}
+		space = unicode.IsSpace(r)
+	}
+
+	if start < len(text) {
+		tokens = append(tokens, text[start:])
+	}
+
+	return tokens
+}
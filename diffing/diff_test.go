@@ -0,0 +1,49 @@
+package diffing_test
+
+import (
+	"github.com/jibitters/kiosk/diffing"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Diff", func() {
+	Context("When Words called", func() {
+		It("Should report the replaced, inserted and deleted words", func() {
+			changes := diffing.Words("We will refund you tomorrow.", "We have refunded you today, sorry.")
+
+			Ω(changes).Should(Equal([]diffing.Change{
+				{Operation: diffing.OperationEqual, Text: "We "},
+				{Operation: diffing.OperationDelete, Text: "will refund"},
+				{Operation: diffing.OperationInsert, Text: "have refunded"},
+				{Operation: diffing.OperationEqual, Text: " you "},
+				{Operation: diffing.OperationDelete, Text: "tomorrow."},
+				{Operation: diffing.OperationInsert, Text: "today, sorry."},
+			}))
+		})
+
+		It("Should give back both texts from the changes", func() {
+			from, to := "Hello,\n\nthe issue is fixed now.", "Hello,\nthe issue is not fixed yet, stay tuned."
+
+			a, b := "", ""
+			for _, change := range diffing.Words(from, to) {
+				if change.Operation != diffing.OperationInsert {
+					a += change.Text
+				}
+
+				if change.Operation != diffing.OperationDelete {
+					b += change.Text
+				}
+			}
+
+			Ω(a).Should(Equal(from))
+			Ω(b).Should(Equal(to))
+		})
+
+		It("Should report equal texts as a single equal change", func() {
+			Ω(diffing.Words("Same text", "Same text")).Should(Equal([]diffing.Change{
+				{Operation: diffing.OperationEqual, Text: "Same text"},
+			}))
+			Ω(diffing.Words("", "")).Should(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package diffing_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestDiffing(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Diffing Suite")
+}
@@ -0,0 +1,35 @@
+package documents
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"strings"
+
+	"github.com/jibitters/kiosk/models"
+)
+
+// CSVCell neutralizes values that spreadsheet applications would otherwise interpret as formulas. Quoting of
+// separators, quotes and new lines is handled by the csv writer itself.
+func CSVCell(value string) string {
+	if value != "" && strings.ContainsAny(value[:1], "=+-@\t\r") {
+		return "'" + value
+	}
+
+	return value
+}
+
+// RenderDailyReportCSV renders the daily report rows as a CSV document.
+func RenderDailyReportCSV(rows []*models.DailyReportRow) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	writer := csv.NewWriter(buffer)
+
+	_ = writer.Write([]string{"day", "issuer", "importanceLevel", "status", "count"})
+	for _, row := range rows {
+		_ = writer.Write([]string{row.Day.Format("2006-01-02"), CSVCell(row.Issuer), string(row.ImportanceLevel),
+			string(row.Status), strconv.FormatInt(row.Count, 10)})
+	}
+
+	writer.Flush()
+	return buffer.Bytes(), writer.Error()
+}
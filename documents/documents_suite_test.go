@@ -0,0 +1,21 @@
+package documents_test
+
+import (
+	"flag"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// update records the rendered documents as their golden outputs, instead of verifying them.
+var update bool
+
+func init() {
+	flag.BoolVar(&update, "golden.update", false, "")
+}
+
+func TestDocuments(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Documents Suite")
+}
@@ -0,0 +1,43 @@
+package documents
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/jibitters/kiosk/models"
+)
+
+// IncidentMessage is what messages posted on the tickets of incidents are rendered with, e.g.
+// "Hi {{.Ticket.Owner}}, {{.Incident.Title}} is over, sorry for the trouble with #{{.Ticket.ID}}."
+type IncidentMessage struct {
+	Incident *models.Incident
+	Ticket   *models.Ticket
+}
+
+// ParseIncidentMessage parses the message as a template rendered per ticket of an incident, failing on templates
+// referring to anything but the fields of IncidentMessage.
+func ParseIncidentMessage(message string) (*template.Template, error) {
+	t, e := template.New("incident_message").Option("missingkey=error").Parse(message)
+	if e != nil {
+		return nil, e
+	}
+
+	sample := &IncidentMessage{Incident: &models.Incident{}, Ticket: &models.Ticket{}}
+	if e := t.Execute(&bytes.Buffer{}, sample); e != nil {
+		return nil, e
+	}
+
+	return t, nil
+}
+
+// RenderIncidentMessage renders the message parsed by ParseIncidentMessage for a ticket of the incident.
+func RenderIncidentMessage(message *template.Template, incident *models.Incident, ticket *models.Ticket) (string,
+	error) {
+
+	text := &bytes.Buffer{}
+	if e := message.Execute(text, &IncidentMessage{Incident: incident, Ticket: ticket}); e != nil {
+		return "", e
+	}
+
+	return text.String(), nil
+}
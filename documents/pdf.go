@@ -0,0 +1,167 @@
+package documents
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Page dimensions and margins in points, A4 portrait.
+const (
+	pageWidth  = 595.0
+	pageHeight = 842.0
+	margin     = 50.0
+)
+
+// Line is a single styled line of text in a PDF document. Long lines are wrapped when the document is rendered.
+type Line struct {
+	Text string
+	Size float64
+	Bold bool
+}
+
+// PDF is a minimal PDF writer that lays out lines of text using the standard Helvetica fonts, so no font embedding
+// or third party dependency is required.
+type PDF struct {
+	lines []Line
+}
+
+// NewPDF returns back a newly created and ready to use PDF.
+func NewPDF() *PDF {
+	return &PDF{}
+}
+
+// Add appends a line of text to the document.
+func (p *PDF) Add(line Line) {
+	p.lines = append(p.lines, line)
+}
+
+// Bytes lays out the lines on as many pages as required and returns back the encoded document.
+func (p *PDF) Bytes() []byte {
+	pages := p.paginate()
+
+	buffer := &bytes.Buffer{}
+	offsets := make([]int, 0)
+	object := func(body string) {
+		offsets = append(offsets, buffer.Len())
+		fmt.Fprintf(buffer, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buffer.WriteString("%PDF-1.4\n")
+
+	// Objects 1 to 4 are catalog, page tree and fonts, then each page takes two objects: the page and its content.
+	kids := make([]string, 0, len(pages))
+	for i := range pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 5+2*i))
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%v] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %v %v] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", pageWidth, pageHeight, 6+2*i))
+
+		content := page.String()
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%v\nendstream", len(content), content))
+	}
+
+	xref := buffer.Len()
+	fmt.Fprintf(buffer, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(buffer, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(buffer, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buffer.Bytes()
+}
+
+func (p *PDF) paginate() []*bytes.Buffer {
+	pages := []*bytes.Buffer{{}}
+	y := pageHeight - margin
+
+	for _, line := range p.lines {
+		size := line.Size
+		if size <= 0 {
+			size = 10
+		}
+
+		font := "F1"
+		if line.Bold {
+			font = "F2"
+		}
+
+		leading := size * 1.4
+		for _, text := range wrap(line.Text, int((pageWidth-2*margin)/(size*0.5))) {
+			if y-leading < margin {
+				pages = append(pages, &bytes.Buffer{})
+				y = pageHeight - margin
+			}
+
+			y -= leading
+			fmt.Fprintf(pages[len(pages)-1], "BT /%v %v Tf %v %.2f Td (%v) Tj ET\n", font, size, margin, y,
+				escape(text))
+		}
+	}
+
+	return pages
+}
+
+// wrap breaks text into lines of at most width characters, preferring to break on spaces.
+func wrap(text string, width int) []string {
+	if width < 1 {
+		width = 1
+	}
+
+	lines := make([]string, 0)
+	for _, paragraph := range strings.Split(text, "\n") {
+		current := ""
+		for _, word := range strings.Fields(paragraph) {
+			for len([]rune(word)) > width {
+				if current != "" {
+					lines = append(lines, current)
+					current = ""
+				}
+
+				lines = append(lines, string([]rune(word)[:width]))
+				word = string([]rune(word)[width:])
+			}
+
+			switch {
+			case current == "":
+				current = word
+			case len([]rune(current))+1+len([]rune(word)) <= width:
+				current += " " + word
+			default:
+				lines = append(lines, current)
+				current = word
+			}
+		}
+
+		lines = append(lines, current)
+	}
+
+	return lines
+}
+
+// escape escapes PDF string delimiters and replaces characters outside of WinAnsiEncoding's Latin-1 range.
+func escape(text string) string {
+	builder := strings.Builder{}
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			builder.WriteRune('\\')
+			builder.WriteRune(r)
+		case r < 32 || r > 255:
+			builder.WriteRune('?')
+		case r > 126:
+			builder.WriteString(fmt.Sprintf("\\%03o", r))
+		default:
+			builder.WriteRune(r)
+		}
+	}
+
+	return builder.String()
+}
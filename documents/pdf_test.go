@@ -0,0 +1,61 @@
+package documents_test
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/jibitters/kiosk/documents"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// expectWellFormed verifies the structure of the PDF document, i.e. its header and trailer, that its cross reference
+// table points to each of its objects and that its streams are as long as they declare, and returns back its number of
+// pages.
+func expectWellFormed(pdf []byte) int {
+	Ω(bytes.HasPrefix(pdf, []byte("%PDF-1.4\n"))).Should(BeTrue())
+
+	trailer := regexp.MustCompile(`trailer\n<< /Size (\d+) /Root 1 0 R >>\nstartxref\n(\d+)\n%%EOF\n$`).
+		FindSubmatch(pdf)
+	Ω(trailer).ShouldNot(BeNil())
+
+	xref, _ := strconv.Atoi(string(trailer[2]))
+	Ω(bytes.HasPrefix(pdf[xref:], []byte("xref\n"))).Should(BeTrue())
+
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n \n`).FindAllSubmatch(pdf[xref:], -1)
+	Ω(string(trailer[1])).Should(Equal(strconv.Itoa(len(offsets) + 1)))
+	for i, offset := range offsets {
+		at, _ := strconv.Atoi(string(offset[1]))
+		Ω(bytes.HasPrefix(pdf[at:], []byte(fmt.Sprintf("%d 0 obj\n", i+1)))).Should(BeTrue(), "object %d", i+1)
+	}
+
+	for _, stream := range regexp.MustCompile(`<< /Length (\d+) >>\nstream\n`).FindAllSubmatchIndex(pdf, -1) {
+		length, _ := strconv.Atoi(string(pdf[stream[2]:stream[3]]))
+		Ω(bytes.HasPrefix(pdf[stream[1]+length:], []byte("\nendstream\nendobj\n"))).Should(BeTrue())
+	}
+
+	count := regexp.MustCompile(`/Type /Pages /Kids \[[^\]]*\] /Count (\d+)`).FindSubmatch(pdf)
+	Ω(count).ShouldNot(BeNil())
+	pages, _ := strconv.Atoi(string(count[1]))
+	Ω(bytes.Count(pdf, []byte("/Type /Page /Parent 2 0 R"))).Should(Equal(pages))
+
+	return pages
+}
+
+var _ = Describe("PDF", func() {
+	Context("When laying out more lines than fit on a page", func() {
+		It("Should render a well formed document of several pages", func() {
+			pdf := documents.NewPDF()
+			pdf.Add(documents.Line{Text: "Title (draft) \\ ünïcode ☃", Size: 16, Bold: true})
+			for i := 0; i < 100; i++ {
+				pdf.Add(documents.Line{Text: fmt.Sprintf("Line %d", i)})
+			}
+
+			content := pdf.Bytes()
+			Ω(expectWellFormed(content)).Should(Equal(3))
+			Ω(string(content)).Should(ContainSubstring(`(Title \(draft\) \\ \374n\357code ?) Tj`))
+		})
+	})
+})
@@ -0,0 +1,27 @@
+package documents
+
+import (
+	"fmt"
+
+	"github.com/jibitters/kiosk/models"
+)
+
+// RenderDailyReportPDF renders the daily report rows as a PDF document with one line per row.
+func RenderDailyReportPDF(title string, rows []*models.DailyReportRow) []byte {
+	pdf := NewPDF()
+	pdf.Add(Line{Text: title, Size: 16, Bold: true})
+	pdf.Add(Line{Text: ""})
+	pdf.Add(Line{Text: "Day         Issuer                    Importance  Status      Count", Size: 10, Bold: true})
+
+	var total int64
+	for _, row := range rows {
+		pdf.Add(Line{Text: fmt.Sprintf("%-11v %-25.25v %-11v %-11v %v", row.Day.Format("2006-01-02"), row.Issuer,
+			row.ImportanceLevel, row.Status, row.Count), Size: 10})
+		total += row.Count
+	}
+
+	pdf.Add(Line{Text: ""})
+	pdf.Add(Line{Text: fmt.Sprintf("Total: %v", total), Size: 10, Bold: true})
+
+	return pdf.Bytes()
+}
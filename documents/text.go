@@ -0,0 +1,69 @@
+package documents
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jibitters/kiosk/models"
+)
+
+// ticketTextTemplate is the plaintext transcript of a ticket and its comment thread, oldest comment first.
+var ticketTextTemplate = template.Must(template.New("ticket_text").Funcs(template.FuncMap{
+	"date":      func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04:05 MST") },
+	"underline": func(text, mark string) string { return strings.Repeat(mark, len([]rune(text))) },
+	"clean":     clean,
+}).Parse(`{{$title := printf "Ticket #%d: %s" .ID .Subject}}{{$title}}
+{{underline $title "="}}
+Issuer: {{.Issuer}}
+Owner: {{.Owner}}
+Importance level: {{.ImportanceLevel}}
+Status: {{.Status}}
+Created at: {{date .CreatedAt}}
+Modified at: {{date .ModifiedAt}}
+
+Content
+-------
+{{clean .Content}}
+{{if .Attachments}}{{$heading := printf "Attachments (%d)" (len .Attachments)}}
+{{$heading}}
+{{underline $heading "-"}}
+{{range .Attachments}}* {{.Name}} ({{.ContentType}}, {{.Size}} bytes)
+{{end}}{{end}}{{if .Comments}}{{$heading := printf "Comments (%d)" (len .Comments)}}
+{{$heading}}
+{{underline $heading "-"}}
+{{range .Comments}}
+[{{date .CreatedAt}}] {{.Owner}}{{if .AuthorType}} ({{.AuthorType}}){{end}}:
+{{clean .Content}}
+{{end}}{{end}}`))
+
+// RenderTicketText renders the ticket, its attachments and comments into a plaintext transcript, meant to be pasted
+// into emails, postmortems or answers to legal requests. Attachments are listed by their names, content types and
+// sizes, and comments in the order they were made.
+func RenderTicketText(ticket *models.Ticket, attachments []*models.Attachment) ([]byte, error) {
+	t := *ticket
+	t.Comments = make([]*models.Comment, len(ticket.Comments))
+	copy(t.Comments, ticket.Comments)
+	sort.SliceStable(t.Comments, func(i, j int) bool {
+		return t.Comments[i].CreatedAt.Before(t.Comments[j].CreatedAt)
+	})
+
+	text := &bytes.Buffer{}
+	if e := ticketTextTemplate.Execute(text, &ticketDocument{Ticket: &t, Attachments: attachments}); e != nil {
+		return nil, e
+	}
+
+	return text.Bytes(), nil
+}
+
+// clean normalizes line endings and strips trailing whitespaces of the lines, leaving the text as is otherwise.
+func clean(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
@@ -0,0 +1,31 @@
+package documents_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/jibitters/kiosk/documents"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RenderTicketText", func() {
+	Context("When rendering a ticket with attachments and comments", func() {
+		It("Should render the golden transcript", func() {
+			ticket, attachments := ticketFixture()
+
+			text, e := documents.RenderTicketText(ticket, attachments)
+			Ω(e).Should(BeNil())
+
+			golden := filepath.Join("testdata", "ticket.txt")
+			if update {
+				Ω(ioutil.WriteFile(golden, text, 0644)).Should(Succeed())
+				return
+			}
+
+			expected, e := ioutil.ReadFile(golden)
+			Ω(e).Should(BeNil())
+			Ω(string(text)).Should(Equal(string(expected)))
+		})
+	})
+})
@@ -0,0 +1,65 @@
+package documents
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jibitters/kiosk/models"
+)
+
+// ticketTemplate is the layout of a ticket and its comment thread. Lines starting with `# ` are rendered as titles and
+// lines starting with `## ` as headings, the rest as regular text.
+var ticketTemplate = template.Must(template.New("ticket").Funcs(template.FuncMap{
+	"date": func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04:05 MST") },
+}).Parse(`# Ticket #{{.ID}}: {{.Subject}}
+Issuer: {{.Issuer}}
+Owner: {{.Owner}}
+Importance level: {{.ImportanceLevel}}
+Status: {{.Status}}
+Created at: {{date .CreatedAt}}
+Modified at: {{date .ModifiedAt}}
+{{if .Metadata}}Metadata: {{.Metadata}}
+{{end}}
+## Content
+{{.Content}}
+{{if .Attachments}}
+## Attachments ({{len .Attachments}})
+{{range .Attachments}}{{.Name}} ({{.ContentType}}, {{.Size}} bytes)
+{{end}}{{end}}{{if .Comments}}
+## Comments ({{len .Comments}})
+{{range .Comments}}
+## {{.Owner}} at {{date .CreatedAt}}
+{{.Content}}
+{{end}}{{end}}`))
+
+// ticketDocument is what the ticket templates get rendered with, the ticket along with the attachments of it and its
+// comments.
+type ticketDocument struct {
+	*models.Ticket
+	Attachments []*models.Attachment
+}
+
+// RenderTicketPDF renders the ticket, its attachments and comments into a printable PDF document. Attachments are only
+// listed, by their names, content types and sizes.
+func RenderTicketPDF(ticket *models.Ticket, attachments []*models.Attachment) ([]byte, error) {
+	text := &bytes.Buffer{}
+	if e := ticketTemplate.Execute(text, &ticketDocument{Ticket: ticket, Attachments: attachments}); e != nil {
+		return nil, e
+	}
+
+	pdf := NewPDF()
+	for _, line := range strings.Split(text.String(), "\n") {
+		switch {
+		case strings.HasPrefix(line, "# "):
+			pdf.Add(Line{Text: strings.TrimPrefix(line, "# "), Size: 16, Bold: true})
+		case strings.HasPrefix(line, "## "):
+			pdf.Add(Line{Text: strings.TrimPrefix(line, "## "), Size: 11, Bold: true})
+		default:
+			pdf.Add(Line{Text: line, Size: 10})
+		}
+	}
+
+	return pdf.Bytes(), nil
+}
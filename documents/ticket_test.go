@@ -0,0 +1,57 @@
+package documents_test
+
+import (
+	"time"
+
+	"github.com/jibitters/kiosk/documents"
+	"github.com/jibitters/kiosk/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// ticketFixture returns back a ticket with comments out of order and the attachments of it.
+func ticketFixture() (*models.Ticket, []*models.Attachment) {
+	at := func(hour, minute, second int) time.Time {
+		return time.Date(2020, 1, 2, hour, minute, second, 0, time.UTC)
+	}
+
+	ticket := &models.Ticket{
+		Model:           models.Model{ID: 1, CreatedAt: at(3, 4, 5), ModifiedAt: at(4, 5, 6)},
+		Issuer:          "shop",
+		Owner:           "user@example.com",
+		Subject:         "Card declined",
+		Content:         "My card got declined.  \r\nTwice.\n",
+		ImportanceLevel: models.TicketImportanceLevelHigh,
+		Status:          models.TicketStatusReplied,
+		Comments: []*models.Comment{
+			{Model: models.Model{ID: 2, CreatedAt: at(3, 10, 0)}, TicketID: 1, Owner: "agent@example.com",
+				Content: "We are on it.", AuthorType: models.CommentAuthorTypeAgent},
+			{Model: models.Model{ID: 1, CreatedAt: at(3, 5, 0)}, TicketID: 1, Owner: "user@example.com",
+				Content: "Tried again,\r\nstill declined."},
+		},
+	}
+
+	attachments := []*models.Attachment{
+		{Model: models.Model{ID: 1}, TicketID: 1, Name: "receipt.pdf", ContentType: "application/pdf", Size: 2048},
+		{Model: models.Model{ID: 2}, TicketID: 1, CommentID: 1, Name: "screenshot.png", ContentType: "image/png",
+			Size: 512},
+	}
+
+	return ticket, attachments
+}
+
+var _ = Describe("RenderTicketPDF", func() {
+	Context("When rendering a ticket with attachments", func() {
+		It("Should render a well formed document listing the attachments", func() {
+			ticket, attachments := ticketFixture()
+
+			pdf, e := documents.RenderTicketPDF(ticket, attachments)
+			Ω(e).Should(BeNil())
+
+			Ω(expectWellFormed(pdf)).Should(Equal(1))
+			Ω(string(pdf)).Should(ContainSubstring(`(Attachments \(2\)) Tj`))
+			Ω(string(pdf)).Should(ContainSubstring(`(receipt.pdf \(application/pdf, 2048 bytes\)) Tj`))
+			Ω(string(pdf)).Should(ContainSubstring(`(screenshot.png \(image/png, 512 bytes\)) Tj`))
+		})
+	})
+})
@@ -0,0 +1,64 @@
+package errors
+
+import (
+	"strconv"
+	"time"
+)
+
+// Domain is the domain of the reasons of kiosk errors, see ErrorInfo.
+const Domain = "kiosk"
+
+// defaultRetryDelay is the delay retryable errors hint clients to wait when nothing better is known.
+const defaultRetryDelay = time.Second
+
+// Details carries machine readable details of an error, so retry policies of clients can be driven by kiosk. Its
+// fields mirror google.rpc.RetryInfo and google.rpc.ErrorInfo in their JSON form, so they can be attached to gRPC
+// statuses as they are.
+type Details struct {
+	RetryInfo *RetryInfo `json:"retryInfo,omitempty"`
+	ErrorInfo *ErrorInfo `json:"errorInfo,omitempty"`
+}
+
+// RetryInfo tells clients how long to wait before retrying.
+type RetryInfo struct {
+	// RetryDelay is formatted as a google.protobuf.Duration, e.g. 1.5s.
+	RetryDelay string `json:"retryDelay"`
+}
+
+// ErrorInfo tells clients the reason of an error within a domain, along with metadata about it.
+type ErrorInfo struct {
+	Reason   string            `json:"reason"`
+	Domain   string            `json:"domain"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// WithRetryDelay hints clients to retry after delay and returns back current type.
+func (t *Type) WithRetryDelay(delay time.Duration) *Type {
+	if t.Details == nil {
+		t.Details = &Details{}
+	}
+
+	t.Details.RetryInfo = &RetryInfo{RetryDelay: strconv.FormatFloat(delay.Seconds(), 'f', -1, 64) + "s"}
+	return t
+}
+
+// WithReason records the reason of current type within kiosk domain, along with metadata about it, and returns it
+// back. Reasons are UPPER_SNAKE_CASE by convention, e.g. RATE_LIMITED.
+func (t *Type) WithReason(reason string, metadata map[string]string) *Type {
+	if t.Details == nil {
+		t.Details = &Details{}
+	}
+
+	t.Details.ErrorInfo = &ErrorInfo{Reason: reason, Domain: Domain, Metadata: metadata}
+	return t
+}
+
+// RetryDelay returns back the delay clients are hinted to wait before retrying, ok is false when there is none.
+func (t *Type) RetryDelay() (delay time.Duration, ok bool) {
+	if t.Details == nil || t.Details.RetryInfo == nil {
+		return 0, false
+	}
+
+	delay, e := time.ParseDuration(t.Details.RetryInfo.RetryDelay)
+	return delay, e == nil
+}
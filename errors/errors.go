@@ -1,17 +1,29 @@
 package errors
 
 import (
+	"encoding/json"
 	"fmt"
-	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 )
 
-// Type encapsulates a general error type that can be used in all layers.
+// Type encapsulates a general error type that can be used in all layers. It is also the envelope errors travel in as
+// NATS replies, see Envelope and FromEnvelope.
 type Type struct {
 	FingerPrint    string  `json:"fingerprint"`
 	Errors         []Error `json:"errors"`
 	HTTPStatusCode int     `json:"status"`
+	Kind           Kind    `json:"kind,omitempty"`
+	// RequestID is the id of the request the error is replied to, set by the transport it is replied through, e.g. the
+	// web server.
+	RequestID string `json:"requestId,omitempty"`
+	// Details are set on errors of kinds clients are expected to retry, i.e. those of gRPC Unavailable or
+	// ResourceExhausted codes, and optionally on others.
+	Details *Details `json:"details,omitempty"`
+
+	// cause is the underlying error, it stays in the process and is never sent to clients.
+	cause error
 }
 
 // Error encapsulates an specific error. An error type may include two or more errors.
@@ -30,62 +42,124 @@ func (t *Type) Error() string {
 	return t.String()
 }
 
+// Unwrap returns back the underlying error, if any.
+func (t *Type) Unwrap() error {
+	return t.cause
+}
+
+// Wrap records the underlying error of current type and returns it back.
+func (t *Type) Wrap(cause error) *Type {
+	t.cause = cause
+	return t
+}
+
+// GRPCCode returns back the canonical gRPC status code of current type.
+func (t *Type) GRPCCode() uint32 {
+	return t.Kind.GRPCCode()
+}
+
+// Envelope returns back current type encoded as a NATS reply.
+func (t *Type) Envelope() []byte {
+	out, _ := json.Marshal(t)
+	return out
+}
+
+// FromEnvelope decodes a NATS reply and returns back the error it carries, ok is false when the reply is not an
+// error. Kinds of errors replied by peers that predate them are derived from their HTTP statuses.
+func FromEnvelope(reply []byte) (t *Type, ok bool) {
+	t = &Type{}
+	if e := json.Unmarshal(reply, t); e != nil || t.FingerPrint == "" {
+		return nil, false
+	}
+
+	if t.Kind == "" {
+		t.Kind = kindOf(t.HTTPStatusCode)
+	}
+
+	return t, true
+}
+
+// New returns back a new type of the provided kind, with the HTTP status of the kind. Types of retryable kinds get the
+// kind as their reason and hint clients to retry after a second, which can be overridden with WithRetryDelay.
+func New(kind Kind, code, message string) *Type {
+	t := &Type{FingerPrint: uuid.New().String(), Errors: []Error{{code, message}},
+		HTTPStatusCode: kind.HTTPStatus(), Kind: kind}
+
+	if kind.retryable() {
+		t.WithReason(string(kind), map[string]string{"code": code}).WithRetryDelay(defaultRetryDelay)
+	}
+
+	return t
+}
+
 // InvalidRequestBody is a helper method that indicates the request body is not valid.
 func InvalidRequestBody() *Type {
-	return &Type{uuid.New().String(), []Error{{"invalid.json.format", ""}},
-		http.StatusBadRequest}
+	return New(KindValidation, "invalid.json.format", "")
 }
 
 // InvalidArgument is a helper method that indicates the provided argument is not valid.
 func InvalidArgument(code, message string) *Type {
-	return &Type{uuid.New().String(), []Error{{code, message}},
-		http.StatusBadRequest}
+	return New(KindValidation, code, message)
 }
 
 // Unauthorized is a helper method that indicates the request is not authenticated.
 func Unauthorized(message string) *Type {
-	return &Type{uuid.New().String(), []Error{{"unauthorized", message}},
-		http.StatusUnauthorized}
+	return New(KindUnauthorized, "unauthorized", message)
 }
 
 // NotFound is a helper method that indicates the resource not found.
 func NotFound(code, message string) *Type {
-	return &Type{uuid.New().String(), []Error{{code, message}},
-		http.StatusNotFound}
+	return New(KindNotFound, code, message)
+}
+
+// Conflict is a helper method that indicates the request conflicts with the current state of the resource.
+func Conflict(code, message string) *Type {
+	return New(KindConflict, code, message)
 }
 
 // AlreadyExists is a helper method that indicates the resource already exists.
 func AlreadyExists(code, message string) *Type {
-	return &Type{uuid.New().String(), []Error{{code, message}},
-		http.StatusPreconditionFailed}
+	return New(KindConflict, code, message)
 }
 
 // PreconditionFailed is a helper method that indicates some precondition failure.
 func PreconditionFailed(code, message string) *Type {
-	return &Type{uuid.New().String(), []Error{{code, message}},
-		http.StatusPreconditionFailed}
+	return New(KindPrecondition, code, message)
 }
 
 // RequestTimeout is a helper method that indicates request timeout occurred.
 func RequestTimeout(message string) *Type {
-	return &Type{uuid.New().String(), []Error{{"request.timeout", message}},
-		http.StatusRequestTimeout}
+	return New(KindTimeout, "request.timeout", message)
+}
+
+// DeadlineExceeded is a helper method that indicates the operation did not complete before its deadline, e.g. a query
+// canceled by its statement timeout.
+func DeadlineExceeded(code, message string) *Type {
+	return New(KindTimeout, code, message)
+}
+
+// Dependency is a helper method that indicates an external system kiosk depends on failed.
+func Dependency(code, message string) *Type {
+	return New(KindDependency, code, message)
 }
 
 // ServiceUnavailable is a helper method that indicates the server is not available for now.
 func ServiceUnavailable(message string) *Type {
-	return &Type{uuid.New().String(), []Error{{"service.not_available", message}},
-		http.StatusServiceUnavailable}
+	return New(KindUnavailable, "service.not_available", message)
+}
+
+// ResourceExhausted is a helper method that indicates a quota or rate limit of the client is exhausted, clients are
+// hinted to retry after retryDelay.
+func ResourceExhausted(code, message string, retryDelay time.Duration) *Type {
+	return New(KindResourceExhausted, code, message).WithRetryDelay(retryDelay)
 }
 
 // InternalServerError is a helper method that indicates an internal server error occurred.
 func InternalServerError(code, message string) *Type {
-	return &Type{uuid.New().String(), []Error{{code, message}},
-		http.StatusInternalServerError}
+	return New(KindInternal, code, message)
 }
 
 // NotImplemented is a helper method that indicates the service is not implemented yet.
 func NotImplemented() *Type {
-	return &Type{uuid.New().String(), []Error{{"service.not_implemented", ""}},
-		http.StatusNotImplemented}
+	return New(KindNotImplemented, "service.not_implemented", "")
 }
@@ -0,0 +1,105 @@
+package errors_test
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/test"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Type", func() {
+	Context("When created by helpers", func() {
+		It("Should map kinds to HTTP statuses and gRPC codes uniformly", func() {
+			Ω(errors.InvalidArgument("subject.invalid_length", "").HTTPStatusCode).Should(Equal(http.StatusBadRequest))
+			Ω(errors.NotFound("ticket.not_found", "").GRPCCode()).Should(Equal(uint32(5)))
+			Ω(errors.AlreadyExists("reference.already_exists", "").Kind).Should(Equal(errors.KindConflict))
+			Ω(errors.Conflict("ticket.conflict", "").HTTPStatusCode).Should(Equal(http.StatusConflict))
+			Ω(errors.Dependency("escalation.failed", "").HTTPStatusCode).Should(Equal(http.StatusBadGateway))
+			Ω(errors.DeadlineExceeded("query.timeout", "").GRPCCode()).Should(Equal(uint32(4)))
+			Ω(errors.InternalServerError("unknown", "").GRPCCode()).Should(Equal(uint32(13)))
+		})
+	})
+
+	Context("When retryable", func() {
+		It("Should hint clients why and when to retry", func() {
+			t := errors.Dependency("escalation.failed", "")
+			Ω(t.Details.ErrorInfo.Reason).Should(Equal("DEPENDENCY"))
+			Ω(t.Details.ErrorInfo.Domain).Should(Equal(errors.Domain))
+			Ω(t.Details.ErrorInfo.Metadata).Should(HaveKeyWithValue("code", "escalation.failed"))
+			Ω(t.Details.RetryInfo.RetryDelay).Should(Equal("1s"))
+
+			t = errors.ResourceExhausted("tickets.rate_limited", "", 1500*time.Millisecond)
+			Ω(t.HTTPStatusCode).Should(Equal(http.StatusTooManyRequests))
+			Ω(t.GRPCCode()).Should(Equal(uint32(8)))
+			Ω(t.Details.RetryInfo.RetryDelay).Should(Equal("1.5s"))
+
+			decoded, ok := errors.FromEnvelope(t.Envelope())
+			Ω(ok).Should(BeTrue())
+			delay, ok := decoded.RetryDelay()
+			Ω(ok).Should(BeTrue())
+			Ω(delay).Should(Equal(1500 * time.Millisecond))
+		})
+
+		It("Should leave other errors without details", func() {
+			Ω(errors.NotFound("ticket.not_found", "").Details).Should(BeNil())
+
+			_, ok := errors.InvalidArgument("subject.invalid_length", "").RetryDelay()
+			Ω(ok).Should(BeFalse())
+		})
+	})
+
+	Context("When wrapping an error", func() {
+		It("Should keep the cause in the process only", func() {
+			cause := stderrors.New("connection refused")
+			e := fmt.Errorf("escalating: %w", errors.Dependency("escalation.failed", "").Wrap(cause))
+
+			Ω(stderrors.Is(e, cause)).Should(BeTrue())
+			Ω(errors.IsKind(e, errors.KindDependency)).Should(BeTrue())
+			Ω(errors.KindOf(cause)).Should(Equal(errors.KindInternal))
+			Ω(string(errors.Dependency("escalation.failed", "").Wrap(cause).Envelope())).
+				ShouldNot(ContainSubstring("connection refused"))
+		})
+	})
+
+	Context("When decoding NATS replies", func() {
+		It("Should return back the carried errors only", func() {
+			t, ok := errors.FromEnvelope(errors.NotFound("ticket.not_found", "").Envelope())
+			Ω(ok).Should(BeTrue())
+			Ω(t.Kind).Should(Equal(errors.KindNotFound))
+			Ω(t.Errors[0].Code).Should(Equal("ticket.not_found"))
+
+			_, ok = errors.FromEnvelope([]byte(`{"id":1,"subject":"Technical Problem"}`))
+			Ω(ok).Should(BeFalse())
+		})
+
+		It("Should derive the kinds of errors without one from their statuses", func() {
+			t, ok := errors.FromEnvelope([]byte(`{"fingerprint":"f","errors":[{"code":"x"}],"status":412}`))
+			Ω(ok).Should(BeTrue())
+			Ω(t.Kind).Should(Equal(errors.KindPrecondition))
+		})
+
+		It("Should decode malformed replies without panicking and re-encode decoded errors as they were", func() {
+			seeds := [][]byte{errors.NotFound("ticket.not_found", "").Envelope(),
+				errors.ResourceExhausted("tickets.rate_limited", "", time.Second).Envelope(),
+				[]byte(`{"fingerprint":"f","errors":[{"code":"x"}],"status":412}`), []byte(`{"id":1}`)}
+
+			test.Fuzz(GinkgoRandomSeed(), seeds, 5000, func(input []byte) {
+				var t *errors.Type
+				var ok bool
+				Ω(func() { t, ok = errors.FromEnvelope(input) }).ShouldNot(Panic(), "reply: %q", input)
+				if !ok {
+					return
+				}
+
+				again, ok := errors.FromEnvelope(t.Envelope())
+				Ω(ok).Should(BeTrue(), "reply: %q", input)
+				Ω(again.Envelope()).Should(MatchJSON(t.Envelope()), "reply: %q", input)
+			})
+		})
+	})
+})
@@ -0,0 +1,156 @@
+package errors
+
+import (
+	stderrors "errors"
+	"net/http"
+)
+
+// Kind classifies errors regardless of the transport they travel through, so they map uniformly to HTTP statuses,
+// gRPC codes and NATS replies.
+type Kind string
+
+// Different error kind instances.
+const (
+	// KindValidation indicates the request is malformed or carries invalid arguments.
+	KindValidation Kind = "VALIDATION"
+	// KindUnauthorized indicates the request is not authenticated.
+	KindUnauthorized Kind = "UNAUTHORIZED"
+	// KindNotFound indicates the requested resource does not exist.
+	KindNotFound Kind = "NOT_FOUND"
+	// KindConflict indicates the request conflicts with the current state of a resource, e.g. it already exists.
+	KindConflict Kind = "CONFLICT"
+	// KindPrecondition indicates the resource is not in a state the request can be applied to.
+	KindPrecondition Kind = "PRECONDITION"
+	// KindTimeout indicates the request did not complete in time.
+	KindTimeout Kind = "TIMEOUT"
+	// KindDependency indicates an external system kiosk depends on failed, e.g. a connector or mail server.
+	KindDependency Kind = "DEPENDENCY"
+	// KindUnavailable indicates kiosk itself can not serve the request for now.
+	KindUnavailable Kind = "UNAVAILABLE"
+	// KindResourceExhausted indicates a quota or rate limit of the client is exhausted for now.
+	KindResourceExhausted Kind = "RESOURCE_EXHAUSTED"
+	// KindNotImplemented indicates the requested functionality is not implemented.
+	KindNotImplemented Kind = "NOT_IMPLEMENTED"
+	// KindInternal indicates an unexpected failure.
+	KindInternal Kind = "INTERNAL"
+)
+
+// Canonical gRPC status codes, as numbered by google.golang.org/grpc/codes. They are kept here so this package does
+// not depend on gRPC, convert with codes.Code(t.GRPCCode()).
+const (
+	grpcInvalidArgument    uint32 = 3
+	grpcDeadlineExceeded   uint32 = 4
+	grpcNotFound           uint32 = 5
+	grpcAlreadyExists      uint32 = 6
+	grpcResourceExhausted  uint32 = 8
+	grpcFailedPrecondition uint32 = 9
+	grpcUnimplemented      uint32 = 12
+	grpcInternal           uint32 = 13
+	grpcUnavailable        uint32 = 14
+	grpcUnauthenticated    uint32 = 16
+)
+
+// HTTPStatus returns back the HTTP status of the kind.
+func (k Kind) HTTPStatus() int {
+	switch k {
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindPrecondition:
+		return http.StatusPreconditionFailed
+	case KindTimeout:
+		return http.StatusRequestTimeout
+	case KindDependency:
+		return http.StatusBadGateway
+	case KindUnavailable:
+		return http.StatusServiceUnavailable
+	case KindResourceExhausted:
+		return http.StatusTooManyRequests
+	case KindNotImplemented:
+		return http.StatusNotImplemented
+	}
+
+	return http.StatusInternalServerError
+}
+
+// GRPCCode returns back the canonical gRPC status code of the kind.
+func (k Kind) GRPCCode() uint32 {
+	switch k {
+	case KindValidation:
+		return grpcInvalidArgument
+	case KindUnauthorized:
+		return grpcUnauthenticated
+	case KindNotFound:
+		return grpcNotFound
+	case KindConflict:
+		return grpcAlreadyExists
+	case KindPrecondition:
+		return grpcFailedPrecondition
+	case KindTimeout:
+		return grpcDeadlineExceeded
+	case KindDependency, KindUnavailable:
+		return grpcUnavailable
+	case KindResourceExhausted:
+		return grpcResourceExhausted
+	case KindNotImplemented:
+		return grpcUnimplemented
+	}
+
+	return grpcInternal
+}
+
+// retryable reports whether errors of the kind are worth retrying as they are, i.e. those of gRPC Unavailable or
+// ResourceExhausted codes.
+func (k Kind) retryable() bool {
+	code := k.GRPCCode()
+	return code == grpcUnavailable || code == grpcResourceExhausted
+}
+
+// kindOf returns back the kind of an HTTP status, for errors of peers that predate kinds.
+func kindOf(status int) Kind {
+	switch status {
+	case http.StatusBadRequest:
+		return KindValidation
+	case http.StatusUnauthorized:
+		return KindUnauthorized
+	case http.StatusNotFound:
+		return KindNotFound
+	case http.StatusConflict:
+		return KindConflict
+	case http.StatusPreconditionFailed:
+		return KindPrecondition
+	case http.StatusRequestTimeout:
+		return KindTimeout
+	case http.StatusBadGateway:
+		return KindDependency
+	case http.StatusServiceUnavailable:
+		return KindUnavailable
+	case http.StatusTooManyRequests:
+		return KindResourceExhausted
+	case http.StatusNotImplemented:
+		return KindNotImplemented
+	}
+
+	return KindInternal
+}
+
+// KindOf returns back the kind of an error, which is KindInternal for errors that are not of Type.
+func KindOf(e error) Kind {
+	var t *Type
+	if stderrors.As(e, &t) {
+		return t.Kind
+	}
+
+	return KindInternal
+}
+
+// IsKind reports whether the error, or any error it wraps, is of Type with the provided kind.
+func IsKind(e error, kind Kind) bool {
+	var t *Type
+	return stderrors.As(e, &t) && t.Kind == kind
+}
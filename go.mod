@@ -1,19 +1,22 @@
 module github.com/jibitters/kiosk
 
-go 1.15
+go 1.16
 
 require (
 	github.com/docker/go-connections v0.4.0
 	github.com/golang-migrate/migrate/v4 v4.12.2
+	github.com/golang/mock v1.4.4
 	github.com/google/uuid v1.1.2
 	github.com/gorilla/mux v1.8.0
+	github.com/gorilla/websocket v1.4.2
 	github.com/jackc/pgx/v4 v4.8.1
 	github.com/lireza/lib v0.0.13
-	github.com/nats-io/nats-server/v2 v2.1.8 // indirect
+	github.com/nats-io/nats-server/v2 v2.1.8
 	github.com/nats-io/nats.go v1.10.0
 	github.com/onsi/ginkgo v1.14.1
 	github.com/onsi/gomega v1.10.2
 	github.com/prometheus/client_golang v1.7.1
 	github.com/testcontainers/testcontainers-go v0.7.0
 	go.uber.org/zap v1.16.0
+	golang.org/x/text v0.3.3
 )
@@ -0,0 +1,202 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+	"go.uber.org/zap"
+)
+
+const (
+	// pollInterval is how often idle workers look for due jobs.
+	pollInterval = time.Second
+
+	// maxBackoff caps the delay before retrying a failed job.
+	maxBackoff = time.Hour
+)
+
+// Handler handles the payload of a job. A returned error makes the job run again later, until it runs out of attempts.
+type Handler func(ctx context.Context, payload []byte) error
+
+type registration struct {
+	handler Handler
+	timeout time.Duration
+}
+
+// Pool is a pool of workers processing the jobs table, which is shared by the pools of all kiosk instances. Failed
+// jobs are retried with exponential backoff and the jobs of crashed workers are taken over once they become stale.
+type Pool struct {
+	logger        *zap.SugaredLogger
+	repository    *models.JobRepository
+	identity      string
+	size          int32
+	busy          int32
+	registrations map[string]*registration
+	kinds         []string
+	cancel        context.CancelFunc
+	done          chan struct{}
+	running       sync.WaitGroup
+}
+
+// NewPool returns back a newly created and ready to use Pool of size workers. Identity names this instance as the
+// worker of the jobs it claims.
+func NewPool(logger *zap.SugaredLogger, db *pgxpool.Pool, identity string, size int) *Pool {
+	return &Pool{
+		logger:        logger,
+		repository:    models.NewJobRepository(logger, db),
+		identity:      identity,
+		size:          int32(size),
+		registrations: make(map[string]*registration),
+		done:          make(chan struct{}),
+	}
+}
+
+// Register registers the handler of a kind of jobs, each run is cancelled when it exceeds the provided timeout. Should
+// be called before Start, only registered kinds are claimed by this pool.
+func (p *Pool) Register(kind string, timeout time.Duration, handler Handler) {
+	p.registrations[kind] = &registration{handler: handler, timeout: timeout}
+	p.kinds = append(p.kinds, kind)
+	p.logger.Info("Registered ", kind, " jobs handler")
+}
+
+// Enqueue enqueues a job of the provided kind with the JSON representation of payload. The returned value is false
+// when a pending job with the same unique key already exists, an empty key never conflicts.
+func (p *Pool) Enqueue(ctx context.Context, kind, uniqueKey string, payload interface{}) (bool, *errors.Type) {
+	encoded, e := json.Marshal(payload)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		p.logger.Error(et.FingerPrint, ": ", e.Error())
+		return false, et
+	}
+
+	id, et := p.repository.Enqueue(ctx, models.Job{Kind: kind, UniqueKey: uniqueKey, Payload: string(encoded)})
+	if et != nil {
+		return false, et
+	}
+
+	return id != 0, nil
+}
+
+// Start starts polling for due jobs in background until Stop gets called.
+func (p *Pool) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	go func() {
+		defer close(p.done)
+
+		requeuedAt := time.Time{}
+		for ctx.Err() == nil {
+			if time.Since(requeuedAt) > time.Minute {
+				p.requeueStale(ctx)
+				requeuedAt = time.Now()
+			}
+
+			if p.poll(ctx) > 0 {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(pollInterval):
+			}
+		}
+	}()
+}
+
+// requeueStale takes over the jobs whose workers went away. Jobs running longer than their timeout, plus a margin for
+// recording their outcome, are not running anymore.
+func (p *Pool) requeueStale(ctx context.Context) {
+	for _, kind := range p.kinds {
+		lockedBefore := time.Now().Add(-p.registrations[kind].timeout - time.Minute)
+		if count, e := p.repository.RequeueStale(ctx, kind, lockedBefore); e == nil && count > 0 {
+			p.logger.Warn("Requeued ", count, " stale jobs of kind ", kind)
+		}
+	}
+}
+
+// poll claims as many due jobs as there are idle workers and returns back the number of claimed jobs.
+func (p *Pool) poll(ctx context.Context) int {
+	idle := int(p.size - atomic.LoadInt32(&p.busy))
+	if idle <= 0 || len(p.kinds) == 0 {
+		return 0
+	}
+
+	jobs, e := p.repository.Claim(ctx, p.identity, p.kinds, idle, time.Now())
+	if e != nil {
+		return 0
+	}
+
+	for _, job := range jobs {
+		atomic.AddInt32(&p.busy, 1)
+		p.running.Add(1)
+		go p.run(job)
+	}
+
+	return len(jobs)
+}
+
+func (p *Pool) run(job *models.Job) {
+	defer p.running.Done()
+	defer atomic.AddInt32(&p.busy, -1)
+
+	r := p.registrations[job.Kind]
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	e := invoke(ctx, r.handler, job)
+	cancel()
+
+	// Jobs outlive the pool polling them, so their outcome is recorded regardless of Stop.
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if e == nil {
+		_ = p.repository.Complete(ctx, job.ID)
+		return
+	}
+
+	p.logger.Warn("Job ", job.ID, " of kind ", job.Kind, " failed on attempt ", job.Attempts, ": ", e.Error())
+	_ = p.repository.Fail(ctx, job.ID, e.Error(), time.Now().Add(backoff(job.Attempts)))
+}
+
+// invoke calls the handler of the job, a panic is reported as its failure.
+func invoke(ctx context.Context, handler Handler, job *models.Job) (e error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e = fmt.Errorf("panicked: %v", r)
+		}
+	}()
+
+	return handler(ctx, []byte(job.Payload))
+}
+
+// backoff returns back the delay before the next attempt of a job, doubling from ten seconds on every attempt.
+func backoff(attempts int) time.Duration {
+	delay := 10 * time.Second
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > maxBackoff {
+		return maxBackoff
+	}
+
+	return delay
+}
+
+// Stop stops polling and waits for the running jobs to finish.
+func (p *Pool) Stop() {
+	if p.cancel == nil {
+		return
+	}
+
+	p.cancel()
+	<-p.done
+	p.running.Wait()
+}
@@ -0,0 +1,128 @@
+package mailing
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/lireza/lib/configuring"
+	"go.uber.org/zap"
+)
+
+// Attachment is a file attached to an email.
+type Attachment struct {
+	Name        string
+	ContentType string
+	Content     []byte
+}
+
+// Mailer sends emails through an SMTP server.
+type Mailer struct {
+	logger   *zap.SugaredLogger
+	host     string
+	port     uint
+	username string
+	password string
+	from     string
+}
+
+// NewMailer returns back a newly created and ready to use Mailer configured by the information provided in config
+// instance.
+func NewMailer(logger *zap.SugaredLogger, config *configuring.Config) *Mailer {
+	host := config.Get("mailing.smtp.host").StringOrElse("localhost")
+	port := config.Get("mailing.smtp.port").UintOrElse(25)
+	username := config.Get("mailing.smtp.username").StringOrElse("")
+	password := config.Get("mailing.smtp.password").StringOrElse("")
+	from := config.Get("mailing.from").StringOrElse("kiosk@localhost")
+
+	logger.Info("mailing.smtp.host -> ", host)
+	logger.Info("mailing.smtp.port -> ", port)
+	logger.Info("mailing.smtp.username -> ", username)
+	logger.Info("mailing.from -> ", from)
+
+	return &Mailer{logger: logger, host: host, port: port, username: username, password: password, from: from}
+}
+
+// Send sends an email with a plain text body and optional attachments to provided recipients.
+func (m *Mailer) Send(to []string, subject, body string, attachments ...Attachment) error {
+	message, e := m.compose(to, subject, body, attachments)
+	if e != nil {
+		return e
+	}
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	return smtp.SendMail(fmt.Sprintf("%v:%v", m.host, m.port), auth, m.from, to, message)
+}
+
+// IsBounce checks whether the error returned by Send is a permanent rejection of the recipients by the SMTP server,
+// so retrying the same email would not help.
+func IsBounce(e error) bool {
+	if te, ok := e.(*textproto.Error); ok {
+		return te.Code >= 500
+	}
+
+	return false
+}
+
+func (m *Mailer) compose(to []string, subject, body string, attachments []Attachment) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	writer := multipart.NewWriter(buffer)
+
+	fmt.Fprintf(buffer, "From: %v\r\n", m.from)
+	fmt.Fprintf(buffer, "To: %v\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(buffer, "Subject: %v\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(buffer, "Date: %v\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(buffer, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(buffer, "Content-Type: multipart/mixed; boundary=%v\r\n\r\n", writer.Boundary())
+
+	part, e := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if e != nil {
+		return nil, e
+	}
+	_, _ = part.Write(encode([]byte(body)))
+
+	for _, a := range attachments {
+		part, e := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {a.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Name})},
+		})
+		if e != nil {
+			return nil, e
+		}
+		_, _ = part.Write(encode(a.Content))
+	}
+
+	if e := writer.Close(); e != nil {
+		return nil, e
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// encode encodes content as base64 with lines no longer than 76 characters as required by RFC 2045.
+func encode(content []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(content)
+
+	buffer := &bytes.Buffer{}
+	for len(encoded) > 76 {
+		buffer.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buffer.WriteString(encoded)
+
+	return buffer.Bytes()
+}
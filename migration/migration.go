@@ -0,0 +1,10 @@
+// Package migration embeds the database migrations into the binary, so they always match the schema version the
+// binary expects and deployments need not ship the migration directory along with it.
+package migration
+
+import "embed"
+
+// Postgres holds the migrations of postgres database under postgres directory.
+//
+//go:embed postgres/*.sql
+var Postgres embed.FS
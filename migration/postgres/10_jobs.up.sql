@@ -0,0 +1,23 @@
+-- Jobs table definition. It is the queue of background work shared by the worker pools of all kiosk instances.
+CREATE TABLE jobs
+(
+    id           BIGSERIAL    NOT NULL,
+    kind         VARCHAR(50)  NOT NULL,
+    unique_key   VARCHAR(255),
+    payload      TEXT         NOT NULL,
+    status       VARCHAR(25)  NOT NULL,
+    attempts     INT          NOT NULL,
+    max_attempts INT          NOT NULL,
+    run_at       TIMESTAMP    NOT NULL,
+    failure      TEXT,
+    locked_by    VARCHAR(100),
+    locked_at    TIMESTAMP,
+    created_at   TIMESTAMP    NOT NULL,
+    modified_at  TIMESTAMP    NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX jobs_status_run_at ON jobs (status, run_at);
+
+-- At most one pending job per unique key, so the same work does not get enqueued twice.
+CREATE UNIQUE INDEX jobs_pending_unique_key ON jobs (unique_key) WHERE status IN ('QUEUED', 'RUNNING');
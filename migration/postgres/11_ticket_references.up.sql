@@ -0,0 +1,17 @@
+-- Ticket references table definition. It links tickets to the issues they escalated to in external systems.
+CREATE TABLE ticket_references
+(
+    id           BIGSERIAL     NOT NULL,
+    ticket_id    BIGINT REFERENCES tickets,
+    system       VARCHAR(25)   NOT NULL,
+    url          VARCHAR(2048) NOT NULL,
+    external_key VARCHAR(255)  NOT NULL,
+    sync_status  VARCHAR(25)   NOT NULL,
+    sync_failure TEXT,
+    created_at   TIMESTAMP     NOT NULL,
+    modified_at  TIMESTAMP     NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE (ticket_id, system, external_key)
+);
+
+CREATE INDEX ticket_references_sync_status ON ticket_references (sync_status);
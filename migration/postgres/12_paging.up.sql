@@ -0,0 +1,27 @@
+-- Paging policies table definition. It holds how the on-call staff of an issuer gets paged about critical tickets.
+CREATE TABLE paging_policies
+(
+    issuer               VARCHAR(50)  NOT NULL,
+    provider             VARCHAR(25)  NOT NULL,
+    routing_key          VARCHAR(255) NOT NULL,
+    business_hours_start VARCHAR(5)   NOT NULL,
+    business_hours_end   VARCHAR(5)   NOT NULL,
+    business_days        VARCHAR(25)  NOT NULL,
+    time_zone            VARCHAR(50)  NOT NULL,
+    created_at           TIMESTAMP    NOT NULL,
+    modified_at          TIMESTAMP    NOT NULL,
+    PRIMARY KEY (issuer)
+);
+
+-- Ticket pages table definition. It records the incidents opened for tickets, so they can be resolved later on. Pages
+-- outlive their tickets to resolve the incidents of deleted tickets too.
+CREATE TABLE ticket_pages
+(
+    ticket_id   BIGINT       NOT NULL,
+    provider    VARCHAR(25)  NOT NULL,
+    routing_key VARCHAR(255) NOT NULL,
+    dedup_key   VARCHAR(100) NOT NULL,
+    paged_at    TIMESTAMP    NOT NULL,
+    resolved_at TIMESTAMP,
+    PRIMARY KEY (ticket_id)
+);
@@ -0,0 +1,16 @@
+-- Status page incidents table definition. It holds the customer visible incidents published for tickets. Incidents
+-- outlive their tickets to resolve the incidents of deleted tickets too.
+CREATE TABLE status_page_incidents
+(
+    ticket_id    BIGINT       NOT NULL,
+    provider     VARCHAR(25)  NOT NULL,
+    external_id  VARCHAR(100),
+    title        VARCHAR(255) NOT NULL,
+    message      TEXT         NOT NULL,
+    status       VARCHAR(25)  NOT NULL,
+    sync_status  VARCHAR(25)  NOT NULL,
+    sync_failure TEXT,
+    created_at   TIMESTAMP    NOT NULL,
+    modified_at  TIMESTAMP    NOT NULL,
+    PRIMARY KEY (ticket_id)
+);
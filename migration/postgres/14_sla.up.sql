@@ -0,0 +1,33 @@
+-- Customer tiers of issuers, issuers without a tier get the bronze service level.
+ALTER TABLE issuer_settings ADD COLUMN tier VARCHAR(25) NOT NULL DEFAULT 'BRONZE';
+
+-- SLA targets table definition. It holds the contractual response and resolution times of each tier and importance.
+CREATE TABLE sla_targets
+(
+    tier                   VARCHAR(25) NOT NULL,
+    importance_level       VARCHAR(25) NOT NULL,
+    first_response_minutes INTEGER     NOT NULL,
+    resolution_minutes     INTEGER     NOT NULL,
+    created_at             TIMESTAMP   NOT NULL,
+    modified_at            TIMESTAMP   NOT NULL,
+    PRIMARY KEY (tier, importance_level)
+);
+
+INSERT INTO sla_targets (tier, importance_level, first_response_minutes, resolution_minutes, created_at, modified_at)
+VALUES ('GOLD', 'CRITICAL', 15, 240, NOW(), NOW()),
+       ('GOLD', 'HIGH', 60, 480, NOW(), NOW()),
+       ('GOLD', 'MEDIUM', 240, 1440, NOW(), NOW()),
+       ('GOLD', 'LOW', 480, 2880, NOW(), NOW()),
+       ('SILVER', 'CRITICAL', 30, 480, NOW(), NOW()),
+       ('SILVER', 'HIGH', 120, 1440, NOW(), NOW()),
+       ('SILVER', 'MEDIUM', 480, 2880, NOW(), NOW()),
+       ('SILVER', 'LOW', 1440, 5760, NOW(), NOW()),
+       ('BRONZE', 'CRITICAL', 60, 1440, NOW(), NOW()),
+       ('BRONZE', 'HIGH', 240, 2880, NOW(), NOW()),
+       ('BRONZE', 'MEDIUM', 1440, 5760, NOW(), NOW()),
+       ('BRONZE', 'LOW', 2880, 10080, NOW(), NOW());
+
+-- The tier and SLA deadlines of tickets are resolved once, when they get created.
+ALTER TABLE tickets ADD COLUMN tier VARCHAR(25);
+ALTER TABLE tickets ADD COLUMN first_response_due_at TIMESTAMP;
+ALTER TABLE tickets ADD COLUMN resolution_due_at TIMESTAMP;
@@ -0,0 +1,3 @@
+-- Snoozed tickets are hidden from the active queues until snoozed_until, or until their owner replies.
+ALTER TABLE tickets ADD COLUMN snoozed_until TIMESTAMP;
+CREATE INDEX tickets_snoozed_until_idx ON tickets (snoozed_until) WHERE snoozed_until IS NOT NULL;
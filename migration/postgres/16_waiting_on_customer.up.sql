@@ -0,0 +1,4 @@
+-- Tickets waiting on their customers are nudged and eventually closed after configurable periods of silence.
+ALTER TABLE tickets ADD COLUMN waiting_since TIMESTAMP;
+ALTER TABLE tickets ADD COLUMN nudged_at TIMESTAMP;
+CREATE INDEX tickets_waiting_since_idx ON tickets (waiting_since) WHERE waiting_since IS NOT NULL;
@@ -0,0 +1,12 @@
+-- Comments are attributed to the type of their authors and to the channel they arrived through, if known.
+ALTER TABLE comments ADD COLUMN author_type VARCHAR(25);
+ALTER TABLE comments ADD COLUMN source VARCHAR(25);
+
+UPDATE comments AS c
+SET author_type = CASE WHEN c.owner = 'kiosk' THEN 'SYSTEM' WHEN c.owner = t.owner THEN 'CUSTOMER' ELSE 'AGENT' END,
+    source      = CASE WHEN c.owner = 'kiosk' THEN NULL ELSE 'API' END
+FROM tickets AS t
+WHERE t.id = c.ticket_id;
+
+UPDATE comments SET author_type = 'AGENT' WHERE author_type IS NULL;
+ALTER TABLE comments ALTER COLUMN author_type SET NOT NULL;
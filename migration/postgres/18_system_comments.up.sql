@@ -0,0 +1,2 @@
+-- Issuers may opt out of the system comments recording the lifecycle of their tickets in their threads.
+ALTER TABLE issuer_settings ADD COLUMN system_comments BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,15 @@
+-- Status changes of tickets along with who made them, recorded for the activity metrics of agents.
+CREATE TABLE ticket_status_changes
+(
+    id              BIGSERIAL   NOT NULL,
+    ticket_id       BIGINT      NOT NULL,
+    issuer          VARCHAR(50) NOT NULL,
+    previous_status VARCHAR(25) NOT NULL,
+    status          VARCHAR(25) NOT NULL,
+    actor           VARCHAR(50),
+    changed_at      TIMESTAMP   NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX ticket_status_changes_changed_at ON ticket_status_changes (changed_at);
+CREATE INDEX ticket_status_changes_ticket_id_changed_at ON ticket_status_changes (ticket_id, changed_at);
@@ -0,0 +1,15 @@
+-- Drafts of tickets being composed, autosaved per requester and removed once expired or submitted.
+CREATE TABLE ticket_drafts
+(
+    issuer           VARCHAR(50)  NOT NULL,
+    owner            VARCHAR(50)  NOT NULL,
+    subject          VARCHAR(255) NOT NULL,
+    content          TEXT         NOT NULL,
+    metadata         TEXT,
+    importance_level VARCHAR(25),
+    created_at       TIMESTAMP    NOT NULL,
+    modified_at      TIMESTAMP    NOT NULL,
+    PRIMARY KEY (issuer, owner)
+);
+
+CREATE INDEX ticket_drafts_modified_at ON ticket_drafts (modified_at);
@@ -0,0 +1,16 @@
+-- Revision of the subject and content of tickets, incremented on each edit of them.
+ALTER TABLE tickets ADD COLUMN revision INT NOT NULL DEFAULT 1;
+
+-- Previous revisions of the subject and content of tickets, recorded when they get edited.
+CREATE TABLE ticket_revisions
+(
+    id          BIGSERIAL    NOT NULL,
+    ticket_id   BIGINT       NOT NULL REFERENCES tickets,
+    revision    INT          NOT NULL,
+    subject     VARCHAR(255) NOT NULL,
+    content     TEXT         NOT NULL,
+    editor      VARCHAR(50),
+    replaced_at TIMESTAMP    NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE (ticket_id, revision)
+);
@@ -0,0 +1,26 @@
+-- Webhook sources table definition. It holds the systems allowed to act on tickets through inbound webhooks, e.g.
+-- monitoring systems opening tickets for their alerts, along with the secrets their deliveries are signed with.
+CREATE TABLE webhook_sources
+(
+    name             VARCHAR(50)  NOT NULL,
+    issuer           VARCHAR(50)  NOT NULL,
+    owner            VARCHAR(50)  NOT NULL,
+    importance_level VARCHAR(25)  NOT NULL,
+    secret           VARCHAR(255) NOT NULL,
+    created_at       TIMESTAMP    NOT NULL,
+    modified_at      TIMESTAMP    NOT NULL,
+    PRIMARY KEY (name)
+);
+
+-- Webhook tickets table definition. It links the tickets opened through inbound webhooks to the keys their sources
+-- identify them with, so later deliveries can comment on or close them.
+CREATE TABLE webhook_tickets
+(
+    source       VARCHAR(50)  NOT NULL,
+    external_key VARCHAR(255) NOT NULL,
+    ticket_id    BIGINT       NOT NULL,
+    created_at   TIMESTAMP    NOT NULL,
+    PRIMARY KEY (source, external_key)
+);
+
+CREATE INDEX webhook_tickets_ticket_id ON webhook_tickets (ticket_id);
@@ -0,0 +1,6 @@
+-- Fingerprints identify the tickets of automated issuers, tickets created again with the same fingerprint within the
+-- deduplication window are appended to the existing ones as comments.
+ALTER TABLE tickets ADD COLUMN fingerprint VARCHAR(255);
+
+CREATE INDEX tickets_issuer_fingerprint_created_at ON tickets (issuer, fingerprint, created_at)
+    WHERE fingerprint IS NOT NULL;
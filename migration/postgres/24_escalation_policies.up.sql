@@ -0,0 +1,23 @@
+-- Escalation levels table definition. It holds the chains unresolved tickets of an issuer and importance level are
+-- escalated through, each level notifying its recipient once the ticket has been open for its delay.
+CREATE TABLE escalation_levels
+(
+    issuer           VARCHAR(50)  NOT NULL,
+    importance_level VARCHAR(25)  NOT NULL,
+    level            SMALLINT     NOT NULL,
+    delay_minutes    INT          NOT NULL,
+    recipient        VARCHAR(255) NOT NULL,
+    created_at       TIMESTAMP    NOT NULL,
+    PRIMARY KEY (issuer, importance_level, level)
+);
+
+-- Ticket escalations table definition. It records every level a ticket has been escalated to, so each level is only
+-- executed once per ticket.
+CREATE TABLE ticket_escalations
+(
+    ticket_id    BIGINT       NOT NULL,
+    level        SMALLINT     NOT NULL,
+    recipient    VARCHAR(255) NOT NULL,
+    escalated_at TIMESTAMP    NOT NULL,
+    PRIMARY KEY (ticket_id, level)
+);
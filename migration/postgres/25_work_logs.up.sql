@@ -0,0 +1,19 @@
+-- Work logs table definition. It records the time agents spent on tickets, for the contracts billed by time spent.
+-- Work logs are kept once their tickets get deleted, like their status changes.
+CREATE TABLE work_logs
+(
+    id        BIGSERIAL     NOT NULL,
+    ticket_id BIGINT        NOT NULL,
+    issuer    VARCHAR(50)   NOT NULL,
+    agent     VARCHAR(50)   NOT NULL,
+    minutes   INT           NOT NULL,
+    note      VARCHAR(1000),
+    logged_at TIMESTAMP     NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX work_logs_ticket_id ON work_logs (ticket_id);
+CREATE INDEX work_logs_logged_at ON work_logs (logged_at);
+
+-- Total time spent on each ticket, maintained along with its work logs so ticket reads do not aggregate them.
+ALTER TABLE tickets ADD COLUMN time_spent_minutes INT NOT NULL DEFAULT 0;
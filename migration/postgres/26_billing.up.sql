@@ -0,0 +1,14 @@
+-- Billable tickets are invoiced by the time logged on them.
+ALTER TABLE tickets ADD COLUMN billable BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Billing rates table definition. It holds the hourly rate the billable time of an issuer is invoiced at, in minor
+-- units of its currency.
+CREATE TABLE billing_rates
+(
+    issuer      VARCHAR(50) NOT NULL,
+    hourly_rate BIGINT      NOT NULL,
+    currency    VARCHAR(3)  NOT NULL,
+    created_at  TIMESTAMP   NOT NULL,
+    modified_at TIMESTAMP   NOT NULL,
+    PRIMARY KEY (issuer)
+);
@@ -0,0 +1,33 @@
+-- Approvers table definition. Tickets of an issuer can only be moved to a status that has approvers once one of them
+-- approves the transition.
+CREATE TABLE approvers
+(
+    issuer     VARCHAR(50)  NOT NULL,
+    status     VARCHAR(25)  NOT NULL,
+    approver   VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP    NOT NULL,
+    PRIMARY KEY (issuer, status, approver)
+);
+
+-- Ticket approvals table definition. It records the approvals requested to move tickets to a status and their
+-- decisions, a ticket has at most one pending approval at a time.
+CREATE TABLE ticket_approvals
+(
+    id           BIGSERIAL     NOT NULL,
+    ticket_id    BIGINT        NOT NULL,
+    status       VARCHAR(25)   NOT NULL,
+    state        VARCHAR(10)   NOT NULL,
+    requested_by VARCHAR(50)   NOT NULL,
+    reason       VARCHAR(1000),
+    decided_by   VARCHAR(255),
+    note         VARCHAR(1000),
+    requested_at TIMESTAMP     NOT NULL,
+    decided_at   TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX ticket_approvals_ticket_id ON ticket_approvals (ticket_id);
+CREATE UNIQUE INDEX ticket_approvals_pending ON ticket_approvals (ticket_id) WHERE state = 'PENDING';
+
+-- The state of the latest approval of the ticket, if any.
+ALTER TABLE tickets ADD COLUMN approval_state VARCHAR(10);
@@ -0,0 +1,10 @@
+-- Transition requirements table definition. Tickets of an issuer can only be moved to a status once they have all
+-- the fields required by that status.
+CREATE TABLE transition_requirements
+(
+    issuer     VARCHAR(50) NOT NULL,
+    status     VARCHAR(25) NOT NULL,
+    field      VARCHAR(25) NOT NULL,
+    created_at TIMESTAMP   NOT NULL,
+    PRIMARY KEY (issuer, status, field)
+);
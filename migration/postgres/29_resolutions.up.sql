@@ -0,0 +1,18 @@
+-- Resolution categories table definition. It is the taxonomy tickets get categorized with on resolution, each category
+-- with its optional sub-categories.
+CREATE TABLE resolution_categories
+(
+    name           VARCHAR(50)   NOT NULL,
+    sub_categories VARCHAR(50)[] NOT NULL,
+    created_at     TIMESTAMP     NOT NULL,
+    modified_at    TIMESTAMP     NOT NULL,
+    PRIMARY KEY (name)
+);
+
+-- The resolution of the ticket, captured when resolving it, and when it got resolved last.
+ALTER TABLE tickets ADD COLUMN resolution_category VARCHAR(50);
+ALTER TABLE tickets ADD COLUMN resolution_sub_category VARCHAR(50);
+ALTER TABLE tickets ADD COLUMN root_cause VARCHAR(25);
+ALTER TABLE tickets ADD COLUMN resolved_at TIMESTAMP;
+
+CREATE INDEX tickets_resolved_at ON tickets (resolved_at);
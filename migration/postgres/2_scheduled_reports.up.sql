@@ -0,0 +1,15 @@
+-- Scheduled reports table definition.
+CREATE TABLE scheduled_reports
+(
+    id          BIGSERIAL    NOT NULL,
+    name        VARCHAR(100) NOT NULL,
+    cron        VARCHAR(100) NOT NULL,
+    report      VARCHAR(25)  NOT NULL,
+    issuer      VARCHAR(50)  NOT NULL,
+    period_days INTEGER      NOT NULL,
+    format      VARCHAR(10)  NOT NULL,
+    recipients  TEXT         NOT NULL,
+    created_at  TIMESTAMP    NOT NULL,
+    modified_at TIMESTAMP    NOT NULL,
+    PRIMARY KEY (id)
+);
@@ -0,0 +1,11 @@
+-- The sentiment of customer comments, scored from -1 for the most negative to 1 for the most positive. It is null for
+-- comments that are not scored.
+ALTER TABLE comments ADD COLUMN sentiment_score DOUBLE PRECISION;
+
+-- The sentiment of the customer on the ticket, a moving average of the scores of their comments, along with how much
+-- the latest scored comment changed it.
+ALTER TABLE tickets ADD COLUMN sentiment_score DOUBLE PRECISION;
+ALTER TABLE tickets ADD COLUMN sentiment_trend DOUBLE PRECISION;
+ALTER TABLE tickets ADD COLUMN sentiment_scored_at TIMESTAMP;
+
+CREATE INDEX tickets_sentiment_score ON tickets (sentiment_score) WHERE sentiment_score IS NOT NULL;
@@ -0,0 +1,34 @@
+-- Runtime entries table definition. It holds the entities editable at runtime, e.g. feature flags, templates and
+-- auto-responder rules, as JSON values. The version is incremented on each save.
+CREATE TABLE runtime_entries
+(
+    kind        VARCHAR(25)  NOT NULL,
+    name        VARCHAR(100) NOT NULL,
+    value       TEXT         NOT NULL,
+    version     BIGINT       NOT NULL,
+    created_at  TIMESTAMP    NOT NULL,
+    modified_at TIMESTAMP    NOT NULL,
+    PRIMARY KEY (kind, name)
+);
+
+-- Broadcasts the changes of runtime entries to all kiosk instances listening on kiosk_runtime_changes channel.
+CREATE FUNCTION notify_runtime_entry_change() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM pg_notify('kiosk_runtime_changes',
+                          json_build_object('operation', TG_OP, 'kind', OLD.kind, 'name', OLD.name)::TEXT);
+        RETURN OLD;
+    END IF;
+
+    PERFORM pg_notify('kiosk_runtime_changes',
+                      json_build_object('operation', TG_OP, 'kind', NEW.kind, 'name', NEW.name)::TEXT);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER runtime_entries_notify_change
+    AFTER INSERT OR UPDATE OR DELETE
+    ON runtime_entries
+    FOR EACH ROW
+EXECUTE PROCEDURE notify_runtime_entry_change();
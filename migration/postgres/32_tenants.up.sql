@@ -0,0 +1,27 @@
+-- Tenants table definition. It holds the issuers onboarded through provisioning, issuers that predate provisioning
+-- have no tenant and stay active.
+CREATE TABLE tenants
+(
+    issuer         VARCHAR(50)  NOT NULL,
+    name           VARCHAR(100) NOT NULL,
+    active         BOOLEAN      NOT NULL,
+    created_at     TIMESTAMP    NOT NULL,
+    modified_at    TIMESTAMP    NOT NULL,
+    deactivated_at TIMESTAMP,
+    PRIMARY KEY (issuer)
+);
+
+-- Tenant API keys table definition. Only the SHA-256 hashes of keys are stored, keys are shown once on creation.
+CREATE TABLE tenant_api_keys
+(
+    id         BIGSERIAL   NOT NULL,
+    issuer     VARCHAR(50) NOT NULL REFERENCES tenants (issuer) ON DELETE CASCADE,
+    prefix     VARCHAR(25) NOT NULL,
+    key_hash   VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP   NOT NULL,
+    revoked_at TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX tenant_api_keys_key_hash ON tenant_api_keys (key_hash);
+CREATE INDEX tenant_api_keys_issuer ON tenant_api_keys (issuer);
@@ -0,0 +1,5 @@
+-- External ids identify the tickets transferred from other kiosk deployments, so transfers can be resumed or repeated
+-- without duplicating them.
+ALTER TABLE tickets ADD COLUMN external_id VARCHAR(100);
+
+CREATE UNIQUE INDEX tickets_issuer_external_id ON tickets (issuer, external_id) WHERE external_id IS NOT NULL;
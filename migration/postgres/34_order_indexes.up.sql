@@ -0,0 +1,7 @@
+-- Indexes backing the fields tickets and comments can be ordered by with order_by, resolved_at is indexed already.
+CREATE INDEX tickets_created_at ON tickets (created_at);
+CREATE INDEX tickets_modified_at ON tickets (modified_at);
+CREATE INDEX tickets_first_response_due_at ON tickets (first_response_due_at);
+CREATE INDEX tickets_resolution_due_at ON tickets (resolution_due_at);
+
+CREATE INDEX comments_ticket_id_modified_at ON comments (ticket_id, modified_at);
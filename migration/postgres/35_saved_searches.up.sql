@@ -0,0 +1,16 @@
+-- Saved searches table definition. Agents subscribed to a saved search are notified about the new tickets matching it,
+-- an empty issuer or importance level matches any.
+CREATE TABLE saved_searches
+(
+    id               BIGSERIAL    NOT NULL,
+    agent            VARCHAR(255) NOT NULL,
+    name             VARCHAR(100) NOT NULL,
+    query            VARCHAR(255) NOT NULL,
+    issuer           VARCHAR(50)  NOT NULL,
+    importance_level VARCHAR(25)  NOT NULL,
+    created_at       TIMESTAMP    NOT NULL,
+    modified_at      TIMESTAMP    NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX saved_searches_agent ON saved_searches (agent);
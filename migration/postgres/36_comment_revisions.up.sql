@@ -0,0 +1,16 @@
+-- Revision of the content of comments, incremented on each edit of it, and when it got edited last.
+ALTER TABLE comments ADD COLUMN revision INT NOT NULL DEFAULT 1;
+ALTER TABLE comments ADD COLUMN edited_at TIMESTAMP;
+
+-- Previous revisions of the content of comments, recorded when they get edited.
+CREATE TABLE comment_revisions
+(
+    id          BIGSERIAL NOT NULL,
+    comment_id  BIGINT    NOT NULL REFERENCES comments,
+    revision    INT       NOT NULL,
+    content     TEXT      NOT NULL,
+    editor      VARCHAR(50),
+    replaced_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE (comment_id, revision)
+);
@@ -0,0 +1,22 @@
+-- Converts the metadata of tickets to JSON documents. Metadata that is not valid JSON is kept as a JSON string, empty
+-- metadata as NULL. The function is only needed by the conversion, later writes are converted by the application.
+CREATE FUNCTION to_metadata(value TEXT) RETURNS JSONB AS
+$$
+BEGIN
+    IF value IS NULL OR value = '' THEN
+        RETURN NULL;
+    END IF;
+
+    RETURN value::JSONB;
+EXCEPTION
+    WHEN invalid_text_representation THEN
+        RETURN to_jsonb(value);
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+ALTER TABLE tickets ALTER COLUMN metadata TYPE JSONB USING to_metadata(metadata);
+
+DROP FUNCTION to_metadata(TEXT);
+
+-- Serves the containment queries of filtering tickets by their metadata.
+CREATE INDEX tickets_metadata ON tickets USING GIN (metadata jsonb_path_ops);
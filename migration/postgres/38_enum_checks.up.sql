@@ -0,0 +1,18 @@
+-- Restricts the enum columns of tickets and comments to the values kiosk knows of, so other writers of the database can
+-- not sneak invalid values in. Kiosk verifies on startup these constraints allow exactly the values of its enums, see
+-- models.EnumConstraints.
+ALTER TABLE tickets
+    ADD CONSTRAINT tickets_importance_level_check
+        CHECK (importance_level IN ('LOW', 'MEDIUM', 'HIGH', 'CRITICAL'));
+
+ALTER TABLE tickets
+    ADD CONSTRAINT tickets_status_check
+        CHECK (status IN ('NEW', 'REPLIED', 'RESOLVED', 'CLOSED', 'BLOCKED', 'WAITING_ON_CUSTOMER'));
+
+ALTER TABLE comments
+    ADD CONSTRAINT comments_author_type_check
+        CHECK (author_type IN ('AGENT', 'CUSTOMER', 'SYSTEM', 'BOT'));
+
+ALTER TABLE comments
+    ADD CONSTRAINT comments_source_check
+        CHECK (source IN ('API', 'EMAIL', 'CHAT'));
@@ -0,0 +1,22 @@
+-- Keeps the modification time of rows up to date on every update of what they hold, so writers can not forget it.
+CREATE FUNCTION touch_modified_at() RETURNS TRIGGER AS
+$$
+BEGIN
+    NEW.modified_at := NOW();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Bookkeeping columns, e.g. the search document, nudges, time spent and sentiment, do not modify tickets.
+CREATE TRIGGER tickets_touch_modified_at
+    BEFORE UPDATE OF owner, subject, content, metadata, importance_level, status, billable, snoozed_until,
+        approval_state, resolution_category, resolution_sub_category, root_cause
+    ON tickets
+    FOR EACH ROW
+EXECUTE PROCEDURE touch_modified_at();
+
+CREATE TRIGGER comments_touch_modified_at
+    BEFORE UPDATE OF content, metadata
+    ON comments
+    FOR EACH ROW
+EXECUTE PROCEDURE touch_modified_at();
@@ -0,0 +1,10 @@
+-- Issuer settings table definition.
+CREATE TABLE issuer_settings
+(
+    issuer                   VARCHAR(50) NOT NULL,
+    default_importance_level VARCHAR(25) NOT NULL,
+    default_status           VARCHAR(25) NOT NULL,
+    created_at               TIMESTAMP   NOT NULL,
+    modified_at              TIMESTAMP   NOT NULL,
+    PRIMARY KEY (issuer)
+);
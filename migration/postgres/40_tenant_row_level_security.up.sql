@@ -0,0 +1,21 @@
+-- Tenant the current request acts on behalf of, i.e. an issuer, or NULL when it acts on behalf of no tenant in
+-- particular. Kiosk sets it on every query when db.postgres.row_level_security is enabled.
+CREATE FUNCTION kiosk_tenant() RETURNS TEXT AS
+$$
+SELECT NULLIF(current_setting('kiosk.tenant', TRUE), '');
+$$ LANGUAGE sql STABLE;
+
+-- Isolates tenants from each other as defense in depth on top of the filtering of kiosk itself. Policies apply to the
+-- owner of the tables too, but not to superusers or roles with BYPASSRLS, so kiosk must connect with neither.
+ALTER TABLE tickets ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tickets FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY tickets_tenant_isolation ON tickets
+    USING (kiosk_tenant() IS NULL OR issuer = kiosk_tenant());
+
+-- Comments belong to the tenant of their ticket, which is only visible to that tenant.
+ALTER TABLE comments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE comments FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY comments_tenant_isolation ON comments
+    USING (kiosk_tenant() IS NULL OR EXISTS (SELECT 1 FROM tickets AS t WHERE t.id = ticket_id));
@@ -0,0 +1,19 @@
+-- Read only role for the reporting and search queries, e.g. granted to the login role of db.postgres.readonly with
+-- CREATE ROLE kiosk_reporter LOGIN PASSWORD '...' IN ROLE kiosk_readonly. It is skipped when the migrating role may not
+-- create roles, operators then set the role up themselves.
+DO
+$$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'kiosk_readonly') THEN
+        CREATE ROLE kiosk_readonly NOLOGIN;
+    END IF;
+
+    EXECUTE format('GRANT CONNECT ON DATABASE %I TO kiosk_readonly', current_database());
+    GRANT USAGE ON SCHEMA public TO kiosk_readonly;
+    GRANT SELECT ON ALL TABLES IN SCHEMA public TO kiosk_readonly;
+    ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT ON TABLES TO kiosk_readonly;
+EXCEPTION
+    WHEN insufficient_privilege THEN
+        RAISE NOTICE 'Skipped setting the kiosk_readonly role up: %', SQLERRM;
+END;
+$$;
@@ -0,0 +1,6 @@
+-- Type of the event notifications are sent about, identical notifications of a recipient, ticket and event type are
+-- deduplicated within a window.
+ALTER TABLE notifications ADD COLUMN event_type VARCHAR(50) NOT NULL DEFAULT '';
+
+CREATE INDEX notifications_recipient_ticket_id_event_type_created_at
+    ON notifications (recipient, ticket_id, event_type, created_at);
@@ -0,0 +1,14 @@
+-- Macros table definition. Macros are named bundles of actions agents apply to tickets at once, i.e. moving them to a
+-- status and posting a canned comment. Empty actions are skipped.
+CREATE TABLE macros
+(
+    id          BIGSERIAL    NOT NULL,
+    name        VARCHAR(100) NOT NULL,
+    status      VARCHAR(25)  NOT NULL,
+    comment     TEXT         NOT NULL,
+    created_at  TIMESTAMP    NOT NULL,
+    modified_at TIMESTAMP    NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX macros_name ON macros (name);
@@ -0,0 +1,3 @@
+-- Translations of the canned comments of macros, keyed by their locales, e.g. fa-IR. The comment column stays the one
+-- posted when none of the translations matches the languages agents ask for.
+ALTER TABLE macros ADD COLUMN translations JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,5 @@
+-- The ticket a duplicate got merged into, it is NULL for tickets that are not merged. Merged tickets are closed and
+-- their comments moved to the ticket they got merged into.
+ALTER TABLE tickets ADD COLUMN merged_into BIGINT REFERENCES tickets ON DELETE SET NULL;
+
+CREATE INDEX tickets_merged_into ON tickets (merged_into) WHERE merged_into IS NOT NULL;
@@ -0,0 +1,23 @@
+-- Incidents table definition. Incidents are the outages many tickets get reported for, the tickets attached to them
+-- get resolved or commented on at once when they get resolved.
+CREATE TABLE incidents
+(
+    id          BIGSERIAL    NOT NULL,
+    title       VARCHAR(255) NOT NULL,
+    description TEXT         NOT NULL,
+    resolved_at TIMESTAMP,
+    created_at  TIMESTAMP    NOT NULL,
+    modified_at TIMESTAMP    NOT NULL,
+    PRIMARY KEY (id)
+);
+
+-- Tickets attached to incidents, each ticket to a single incident at a time. Deleting tickets detaches them.
+CREATE TABLE incident_tickets
+(
+    ticket_id   BIGINT    NOT NULL REFERENCES tickets ON DELETE CASCADE,
+    incident_id BIGINT    NOT NULL REFERENCES incidents,
+    attached_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (ticket_id)
+);
+
+CREATE INDEX incident_tickets_incident_id ON incident_tickets (incident_id);
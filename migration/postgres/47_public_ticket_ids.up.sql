@@ -0,0 +1,4 @@
+-- Issuers may obfuscate the ids of their tickets exposed to customers, e.g. within emails, with a salt of their own.
+-- Tickets are referred to by their plain ids while the salt is empty.
+ALTER TABLE issuer_settings ADD COLUMN public_id_salt VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN public_id_min_length INTEGER NOT NULL DEFAULT 0;
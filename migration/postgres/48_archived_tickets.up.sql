@@ -0,0 +1,32 @@
+-- Closed tickets moved out of the hot tables once they get old, along with their comments as a JSON array, so the hot
+-- tables and their indexes stay small. Reads of tickets missing from the hot tables fall back to this table, which can
+-- be moved to a tablespace on cheaper storage.
+CREATE TABLE archived_tickets
+(
+    id                      BIGINT       NOT NULL,
+    issuer                  VARCHAR(50)  NOT NULL,
+    owner                   VARCHAR(50)  NOT NULL,
+    subject                 VARCHAR(255) NOT NULL,
+    content                 TEXT         NOT NULL,
+    metadata                TEXT,
+    importance_level        VARCHAR(25)  NOT NULL,
+    status                  VARCHAR(25)  NOT NULL,
+    resolution_category     VARCHAR(50),
+    resolution_sub_category VARCHAR(50),
+    root_cause              VARCHAR(25),
+    resolved_at             TIMESTAMP,
+    comments                JSONB        NOT NULL,
+    created_at              TIMESTAMP    NOT NULL,
+    modified_at             TIMESTAMP    NOT NULL,
+    archived_at             TIMESTAMP    NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX archived_tickets_issuer_modified_at ON archived_tickets (issuer, modified_at);
+CREATE INDEX archived_tickets_owner_modified_at ON archived_tickets (owner, modified_at);
+
+ALTER TABLE archived_tickets ENABLE ROW LEVEL SECURITY;
+ALTER TABLE archived_tickets FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY archived_tickets_tenant_isolation ON archived_tickets
+    USING (kiosk_tenant() IS NULL OR issuer = kiosk_tenant());
@@ -0,0 +1,79 @@
+-- Number of tickets created per day, issuer, importance level and status, so the daily reports read a few counters
+-- rather than counting tickets. Counters are kept up to date by the triggers below on every write of tickets, so
+-- writers can not forget them, and get reconciled against tickets nightly.
+CREATE TABLE daily_ticket_counters
+(
+    day              DATE        NOT NULL,
+    issuer           VARCHAR(50) NOT NULL,
+    importance_level VARCHAR(25) NOT NULL,
+    status           VARCHAR(25) NOT NULL,
+    count            BIGINT      NOT NULL,
+    PRIMARY KEY (day, issuer, importance_level, status)
+);
+
+INSERT INTO daily_ticket_counters (day, issuer, importance_level, status, count)
+SELECT created_at::DATE, issuer, importance_level, status, COUNT(*)
+FROM tickets
+GROUP BY 1, 2, 3, 4;
+
+ALTER TABLE daily_ticket_counters ENABLE ROW LEVEL SECURITY;
+ALTER TABLE daily_ticket_counters FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY daily_ticket_counters_tenant_isolation ON daily_ticket_counters
+    USING (kiosk_tenant() IS NULL OR issuer = kiosk_tenant());
+
+-- Adds the deltas of a statement to the counters at once through upserts, so concurrent writers never lose updates.
+-- Counters are upserted in the order of their keys, so writers do not deadlock on each other.
+CREATE FUNCTION count_daily_tickets() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO daily_ticket_counters AS c (day, issuer, importance_level, status, count)
+        SELECT created_at::DATE, issuer, importance_level, status, COUNT(*)
+        FROM new_tickets
+        GROUP BY 1, 2, 3, 4
+        ORDER BY 1, 2, 3, 4
+        ON CONFLICT (day, issuer, importance_level, status) DO UPDATE SET count = c.count + EXCLUDED.count;
+    ELSIF TG_OP = 'UPDATE' THEN
+        INSERT INTO daily_ticket_counters AS c (day, issuer, importance_level, status, count)
+        SELECT day, issuer, importance_level, status, SUM(delta)
+        FROM (SELECT created_at::DATE AS day, issuer, importance_level, status, -1 AS delta FROM old_tickets
+              UNION ALL
+              SELECT created_at::DATE, issuer, importance_level, status, 1 FROM new_tickets) AS deltas
+        GROUP BY 1, 2, 3, 4
+        HAVING SUM(delta) <> 0
+        ORDER BY 1, 2, 3, 4
+        ON CONFLICT (day, issuer, importance_level, status) DO UPDATE SET count = c.count + EXCLUDED.count;
+    ELSE
+        INSERT INTO daily_ticket_counters AS c (day, issuer, importance_level, status, count)
+        SELECT created_at::DATE, issuer, importance_level, status, -COUNT(*)
+        FROM old_tickets
+        GROUP BY 1, 2, 3, 4
+        ORDER BY 1, 2, 3, 4
+        ON CONFLICT (day, issuer, importance_level, status) DO UPDATE SET count = c.count + EXCLUDED.count;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tickets_count_inserts
+    AFTER INSERT
+    ON tickets
+    REFERENCING NEW TABLE AS new_tickets
+    FOR EACH STATEMENT
+EXECUTE PROCEDURE count_daily_tickets();
+
+CREATE TRIGGER tickets_count_updates
+    AFTER UPDATE
+    ON tickets
+    REFERENCING OLD TABLE AS old_tickets NEW TABLE AS new_tickets
+    FOR EACH STATEMENT
+EXECUTE PROCEDURE count_daily_tickets();
+
+CREATE TRIGGER tickets_count_deletes
+    AFTER DELETE
+    ON tickets
+    REFERENCING OLD TABLE AS old_tickets
+    FOR EACH STATEMENT
+EXECUTE PROCEDURE count_daily_tickets();
@@ -0,0 +1,14 @@
+-- Maintenance windows table definition.
+CREATE TABLE maintenance_windows
+(
+    id          BIGSERIAL    NOT NULL,
+    issuer      VARCHAR(50)  NOT NULL,
+    starts_at   TIMESTAMP    NOT NULL,
+    ends_at     TIMESTAMP    NOT NULL,
+    reason      VARCHAR(255) NOT NULL,
+    created_at  TIMESTAMP    NOT NULL,
+    modified_at TIMESTAMP    NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX maintenance_windows_ends_at ON maintenance_windows (ends_at);
@@ -0,0 +1,37 @@
+-- Files attached to tickets, or to their comments. Their contents get uploaded in chunks into attachment_chunks table,
+-- where they stay unless an object storage is configured, in which case they are moved there once completed. Deleting
+-- tickets or comments deletes their attachments.
+CREATE TABLE attachments
+(
+    id           BIGSERIAL    NOT NULL,
+    ticket_id    BIGINT       NOT NULL REFERENCES tickets ON DELETE CASCADE,
+    comment_id   BIGINT REFERENCES comments ON DELETE CASCADE,
+    owner        VARCHAR(50)  NOT NULL,
+    name         VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size         BIGINT       NOT NULL,
+    storage      VARCHAR(25)  NOT NULL,
+    completed_at TIMESTAMP,
+    created_at   TIMESTAMP    NOT NULL,
+    modified_at  TIMESTAMP    NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX attachments_ticket_id ON attachments (ticket_id);
+CREATE INDEX attachments_comment_id ON attachments (comment_id);
+
+-- Chunks of the contents of attachments, by their byte offsets.
+CREATE TABLE attachment_chunks
+(
+    attachment_id BIGINT NOT NULL REFERENCES attachments ON DELETE CASCADE,
+    position      BIGINT NOT NULL,
+    content       BYTEA  NOT NULL,
+    PRIMARY KEY (attachment_id, position)
+);
+
+-- Attachments belong to the tenant of their ticket, which is only visible to that tenant.
+ALTER TABLE attachments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE attachments FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY attachments_tenant_isolation ON attachments
+    USING (kiosk_tenant() IS NULL OR EXISTS (SELECT 1 FROM tickets AS t WHERE t.id = ticket_id));
@@ -0,0 +1,16 @@
+-- Audit log of the operational actions taken through the admin API, e.g. flushing caches or rotating API keys. Rows
+-- are only ever inserted, parameters hold the JSON request of the action and failure the error code of failed ones.
+CREATE TABLE admin_audit_logs
+(
+    id         BIGSERIAL    NOT NULL,
+    operator   VARCHAR(50)  NOT NULL,
+    action     VARCHAR(50)  NOT NULL,
+    reason     VARCHAR(255) NOT NULL,
+    parameters TEXT         NOT NULL,
+    succeeded  BOOLEAN      NOT NULL,
+    failure    VARCHAR(100),
+    created_at TIMESTAMP    NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX admin_audit_logs_action_created_at ON admin_audit_logs (action, created_at);
@@ -0,0 +1,16 @@
+-- The agent a ticket is assigned to, if any. Agents claim tickets by getting them assigned, and transitions can require
+-- an assignee through the ASSIGNEE field of transition_requirements.
+ALTER TABLE tickets ADD COLUMN assignee VARCHAR(50);
+
+-- Serves the queues of agents, the most recently modified tickets first.
+CREATE INDEX tickets_assignee ON tickets (assignee, modified_at DESC) WHERE assignee IS NOT NULL;
+
+-- Assigning tickets modifies them.
+DROP TRIGGER tickets_touch_modified_at ON tickets;
+
+CREATE TRIGGER tickets_touch_modified_at
+    BEFORE UPDATE OF owner, subject, content, metadata, importance_level, status, billable, snoozed_until,
+        approval_state, resolution_category, resolution_sub_category, root_cause, assignee
+    ON tickets
+    FOR EACH ROW
+EXECUTE PROCEDURE touch_modified_at();
@@ -0,0 +1,15 @@
+-- On-call shifts table definition. It is the built-in rota of issuers, the agent of the shift covering the time a
+-- critical ticket arrives outside business hours gets it assigned.
+CREATE TABLE on_call_shifts
+(
+    id         BIGSERIAL   NOT NULL,
+    issuer     VARCHAR(50) NOT NULL,
+    agent      VARCHAR(50) NOT NULL,
+    starts_at  TIMESTAMP   NOT NULL,
+    ends_at    TIMESTAMP   NOT NULL,
+    created_at TIMESTAMP   NOT NULL,
+    PRIMARY KEY (id),
+    CHECK (starts_at < ends_at)
+);
+
+CREATE INDEX on_call_shifts_issuer_ends_at ON on_call_shifts (issuer, ends_at);
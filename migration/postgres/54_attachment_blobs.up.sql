@@ -0,0 +1,60 @@
+-- Contents of attachments by their SHA-256 hashes, so identical files are stored once however many attachments refer
+-- to them. Contents stored in the database are chunked into attachment_blob_chunks table, the ones moved to an object
+-- storage are stored there under their hashes. Attachments completed before keep their own contents.
+CREATE TABLE attachment_blobs
+(
+    hash            CHAR(64)    NOT NULL,
+    size            BIGINT      NOT NULL,
+    storage         VARCHAR(25) NOT NULL,
+    reference_count BIGINT      NOT NULL,
+    created_at      TIMESTAMP   NOT NULL,
+    PRIMARY KEY (hash)
+);
+
+CREATE INDEX attachment_blobs_unreferenced ON attachment_blobs (hash) WHERE reference_count = 0;
+
+-- Chunks of the contents stored in the database, by their byte offsets.
+CREATE TABLE attachment_blob_chunks
+(
+    hash     CHAR(64) NOT NULL REFERENCES attachment_blobs ON DELETE CASCADE,
+    position BIGINT   NOT NULL,
+    content  BYTEA    NOT NULL,
+    PRIMARY KEY (hash, position)
+);
+
+ALTER TABLE attachments ADD COLUMN content_hash CHAR(64) REFERENCES attachment_blobs;
+
+-- Releases the contents of deleted attachments, including the ones deleted along with their tickets or comments.
+-- Contents are locked in the order of their hashes, so deleting attachments concurrently does not deadlock. Contents
+-- stored in the database are deleted once unreferenced, the ones in an object storage are left for the scheduler, as
+-- they must be deleted from there first.
+CREATE FUNCTION release_attachment_blobs() RETURNS TRIGGER AS
+$$
+BEGIN
+    PERFORM 1
+    FROM attachment_blobs
+    WHERE hash IN (SELECT content_hash FROM old_attachments)
+    ORDER BY hash
+    FOR UPDATE;
+
+    UPDATE attachment_blobs AS b
+    SET reference_count = b.reference_count - d.count
+    FROM (SELECT content_hash, COUNT(*) AS count FROM old_attachments GROUP BY 1) AS d
+    WHERE b.hash = d.content_hash;
+
+    DELETE
+    FROM attachment_blobs
+    WHERE hash IN (SELECT content_hash FROM old_attachments)
+      AND reference_count = 0
+      AND storage = 'DATABASE';
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER attachments_release_blobs
+    AFTER DELETE
+    ON attachments
+    REFERENCING OLD TABLE AS old_attachments
+    FOR EACH STATEMENT
+EXECUTE PROCEDURE release_attachment_blobs();
@@ -0,0 +1,21 @@
+-- Keeps admin_audit_logs table append only, so recorded actions can not be altered or erased afterwards, whatever role
+-- kiosk connects with. Getting around it takes dropping or disabling the triggers, which only the owner of the table
+-- can do.
+CREATE FUNCTION reject_audit_log_changes() RETURNS TRIGGER AS
+$$
+BEGIN
+    RAISE EXCEPTION 'admin_audit_logs is append only, % is not allowed', TG_OP;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER admin_audit_logs_append_only
+    BEFORE UPDATE OR DELETE
+    ON admin_audit_logs
+    FOR EACH ROW
+EXECUTE PROCEDURE reject_audit_log_changes();
+
+CREATE TRIGGER admin_audit_logs_no_truncate
+    BEFORE TRUNCATE
+    ON admin_audit_logs
+    FOR EACH STATEMENT
+EXECUTE PROCEDURE reject_audit_log_changes();
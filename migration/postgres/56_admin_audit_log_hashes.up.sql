@@ -0,0 +1,6 @@
+-- Chains audit logs by their hashes, each one holding the hash of the one recorded right before it, so altering or
+-- erasing recorded ones around the append only triggers gets detected when the chain is verified. Audit logs recorded
+-- before are not chained.
+ALTER TABLE admin_audit_logs
+    ADD COLUMN previous_hash CHAR(64),
+    ADD COLUMN hash          CHAR(64);
@@ -0,0 +1,34 @@
+-- Broadcasts the assignees of changed tickets as well, the previous one too when reassigned, so the counters of the
+-- queues of agents get invalidated along with the ones of owners.
+CREATE OR REPLACE FUNCTION notify_ticket_change() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM pg_notify('kiosk_ticket_changes',
+                          json_build_object('operation', TG_OP, 'id', OLD.id, 'owner', OLD.owner, 'assignee',
+                                            OLD.assignee)::TEXT);
+        RETURN OLD;
+    END IF;
+
+    IF TG_OP = 'UPDATE' AND OLD.assignee IS DISTINCT FROM NEW.assignee THEN
+        PERFORM pg_notify('kiosk_ticket_changes',
+                          json_build_object('operation', TG_OP, 'id', NEW.id, 'owner', NEW.owner, 'assignee',
+                                            NEW.assignee, 'previous_assignee', OLD.assignee)::TEXT);
+        RETURN NEW;
+    END IF;
+
+    PERFORM pg_notify('kiosk_ticket_changes',
+                      json_build_object('operation', TG_OP, 'id', NEW.id, 'owner', NEW.owner, 'assignee',
+                                        NEW.assignee)::TEXT);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Assigning tickets changes what listeners care about.
+DROP TRIGGER tickets_notify_change ON tickets;
+
+CREATE TRIGGER tickets_notify_change
+    AFTER INSERT OR DELETE OR UPDATE OF owner, status, assignee
+    ON tickets
+    FOR EACH ROW
+EXECUTE PROCEDURE notify_ticket_change();
@@ -0,0 +1,23 @@
+-- Counts archived tickets along with the hot ones, as archiving moves closed tickets out of tickets table by deleting
+-- them, which the counters would otherwise see as the tickets going away and lower the counts of days already over.
+-- Archiving a ticket now decrements its counter on tickets table and increments it back on archived_tickets table.
+INSERT INTO daily_ticket_counters AS c (day, issuer, importance_level, status, count)
+SELECT created_at::DATE, issuer, importance_level, status, COUNT(*)
+FROM archived_tickets
+GROUP BY 1, 2, 3, 4
+ORDER BY 1, 2, 3, 4
+ON CONFLICT (day, issuer, importance_level, status) DO UPDATE SET count = c.count + EXCLUDED.count;
+
+CREATE TRIGGER archived_tickets_count_inserts
+    AFTER INSERT
+    ON archived_tickets
+    REFERENCING NEW TABLE AS new_tickets
+    FOR EACH STATEMENT
+EXECUTE PROCEDURE count_daily_tickets();
+
+CREATE TRIGGER archived_tickets_count_deletes
+    AFTER DELETE
+    ON archived_tickets
+    REFERENCING OLD TABLE AS old_tickets
+    FOR EACH STATEMENT
+EXECUTE PROCEDURE count_daily_tickets();
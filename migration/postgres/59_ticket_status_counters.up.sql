@@ -0,0 +1,93 @@
+-- Number of tickets per issuer, owner, assignee and status, so counters of UI badges read a few rows rather than
+-- counting tickets on every page load. Each ticket is counted under its owner and assignee, under each of them alone
+-- and under neither, an empty owner or assignee standing for all of them, so every counters request reads the rows of
+-- a single owner and assignee. Unassigned tickets are only counted under an empty assignee. Counters are kept up to
+-- date by the triggers below on every write of tickets, within the same transactions.
+CREATE TABLE ticket_status_counters
+(
+    issuer   VARCHAR(50) NOT NULL,
+    owner    VARCHAR(50) NOT NULL,
+    assignee VARCHAR(50) NOT NULL,
+    status   VARCHAR(25) NOT NULL,
+    count    BIGINT      NOT NULL,
+    PRIMARY KEY (owner, assignee, issuer, status)
+);
+
+INSERT INTO ticket_status_counters (issuer, owner, assignee, status, count)
+SELECT t.issuer, k.owner, k.assignee, t.status, COUNT(*)
+FROM tickets AS t
+         CROSS JOIN LATERAL (VALUES (t.owner, ''), ('', ''), (t.owner, t.assignee), ('', t.assignee))
+    AS k (owner, assignee)
+WHERE k.assignee IS NOT NULL
+GROUP BY 1, 2, 3, 4;
+
+ALTER TABLE ticket_status_counters ENABLE ROW LEVEL SECURITY;
+ALTER TABLE ticket_status_counters FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY ticket_status_counters_tenant_isolation ON ticket_status_counters
+    USING (kiosk_tenant() IS NULL OR issuer = kiosk_tenant());
+
+-- Adds the deltas of a statement to the counters at once through upserts, so concurrent writers never lose updates.
+-- Counters are upserted in the order of their keys, so writers do not deadlock on each other.
+CREATE FUNCTION count_tickets_by_status() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO ticket_status_counters AS c (issuer, owner, assignee, status, count)
+        SELECT t.issuer, k.owner, k.assignee, t.status, COUNT(*)
+        FROM new_tickets AS t
+                 CROSS JOIN LATERAL (VALUES (t.owner, ''), ('', ''), (t.owner, t.assignee), ('', t.assignee))
+            AS k (owner, assignee)
+        WHERE k.assignee IS NOT NULL
+        GROUP BY 1, 2, 3, 4
+        ORDER BY 2, 3, 1, 4
+        ON CONFLICT (owner, assignee, issuer, status) DO UPDATE SET count = c.count + EXCLUDED.count;
+    ELSIF TG_OP = 'UPDATE' THEN
+        INSERT INTO ticket_status_counters AS c (issuer, owner, assignee, status, count)
+        SELECT t.issuer, k.owner, k.assignee, t.status, SUM(t.delta)
+        FROM (SELECT issuer, owner, assignee, status, -1 AS delta FROM old_tickets
+              UNION ALL
+              SELECT issuer, owner, assignee, status, 1 FROM new_tickets) AS t
+                 CROSS JOIN LATERAL (VALUES (t.owner, ''), ('', ''), (t.owner, t.assignee), ('', t.assignee))
+            AS k (owner, assignee)
+        WHERE k.assignee IS NOT NULL
+        GROUP BY 1, 2, 3, 4
+        HAVING SUM(t.delta) <> 0
+        ORDER BY 2, 3, 1, 4
+        ON CONFLICT (owner, assignee, issuer, status) DO UPDATE SET count = c.count + EXCLUDED.count;
+    ELSE
+        INSERT INTO ticket_status_counters AS c (issuer, owner, assignee, status, count)
+        SELECT t.issuer, k.owner, k.assignee, t.status, -COUNT(*)
+        FROM old_tickets AS t
+                 CROSS JOIN LATERAL (VALUES (t.owner, ''), ('', ''), (t.owner, t.assignee), ('', t.assignee))
+            AS k (owner, assignee)
+        WHERE k.assignee IS NOT NULL
+        GROUP BY 1, 2, 3, 4
+        ORDER BY 2, 3, 1, 4
+        ON CONFLICT (owner, assignee, issuer, status) DO UPDATE SET count = c.count + EXCLUDED.count;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tickets_count_statuses_inserts
+    AFTER INSERT
+    ON tickets
+    REFERENCING NEW TABLE AS new_tickets
+    FOR EACH STATEMENT
+EXECUTE PROCEDURE count_tickets_by_status();
+
+CREATE TRIGGER tickets_count_statuses_updates
+    AFTER UPDATE
+    ON tickets
+    REFERENCING OLD TABLE AS old_tickets NEW TABLE AS new_tickets
+    FOR EACH STATEMENT
+EXECUTE PROCEDURE count_tickets_by_status();
+
+CREATE TRIGGER tickets_count_statuses_deletes
+    AFTER DELETE
+    ON tickets
+    REFERENCING OLD TABLE AS old_tickets
+    FOR EACH STATEMENT
+EXECUTE PROCEDURE count_tickets_by_status();
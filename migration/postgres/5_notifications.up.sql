@@ -0,0 +1,18 @@
+-- Notifications table definition.
+CREATE TABLE notifications
+(
+    id          BIGSERIAL    NOT NULL,
+    ticket_id   BIGINT       NOT NULL,
+    issuer      VARCHAR(50)  NOT NULL,
+    recipient   VARCHAR(255) NOT NULL,
+    subject     VARCHAR(255) NOT NULL,
+    body        TEXT         NOT NULL,
+    status      VARCHAR(25)  NOT NULL,
+    failure     TEXT,
+    created_at  TIMESTAMP    NOT NULL,
+    modified_at TIMESTAMP    NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX notifications_ticket_id_created_at ON notifications (ticket_id, created_at);
+CREATE INDEX notifications_status_created_at ON notifications (status, created_at);
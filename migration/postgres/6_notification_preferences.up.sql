@@ -0,0 +1,13 @@
+-- Notification preferences table definition.
+CREATE TABLE notification_preferences
+(
+    recipient         VARCHAR(255) NOT NULL,
+    channels          VARCHAR(100) NOT NULL,
+    mode              VARCHAR(25)  NOT NULL,
+    quiet_hours_start VARCHAR(5)   NOT NULL,
+    quiet_hours_end   VARCHAR(5)   NOT NULL,
+    time_zone         VARCHAR(50)  NOT NULL,
+    created_at        TIMESTAMP    NOT NULL,
+    modified_at       TIMESTAMP    NOT NULL,
+    PRIMARY KEY (recipient)
+);
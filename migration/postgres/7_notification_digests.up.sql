@@ -0,0 +1,8 @@
+-- Digest frequencies of recipients and issuer wide notification defaults.
+ALTER TABLE notification_preferences ADD COLUMN digest_frequency VARCHAR(25) NOT NULL DEFAULT 'DAILY';
+
+ALTER TABLE issuer_settings ADD COLUMN notification_mode VARCHAR(25) NOT NULL DEFAULT 'IMMEDIATE';
+ALTER TABLE issuer_settings ADD COLUMN digest_frequency VARCHAR(25) NOT NULL DEFAULT 'DAILY';
+
+-- Importance level of the ticket at the time of notification, only low priority notifications get batched.
+ALTER TABLE notifications ADD COLUMN importance_level VARCHAR(25) NOT NULL DEFAULT 'MEDIUM';
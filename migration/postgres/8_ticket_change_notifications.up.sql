@@ -0,0 +1,21 @@
+-- Broadcasts the changes of tickets to all kiosk instances listening on kiosk_ticket_changes channel.
+CREATE FUNCTION notify_ticket_change() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM pg_notify('kiosk_ticket_changes',
+                          json_build_object('operation', TG_OP, 'id', OLD.id, 'owner', OLD.owner)::TEXT);
+        RETURN OLD;
+    END IF;
+
+    PERFORM pg_notify('kiosk_ticket_changes',
+                      json_build_object('operation', TG_OP, 'id', NEW.id, 'owner', NEW.owner)::TEXT);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tickets_notify_change
+    AFTER INSERT OR UPDATE OR DELETE
+    ON tickets
+    FOR EACH ROW
+EXECUTE PROCEDURE notify_ticket_change();
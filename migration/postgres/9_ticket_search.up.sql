@@ -0,0 +1,34 @@
+-- Full text search document of tickets, derived from their subject and content.
+CREATE FUNCTION ticket_search_vector(subject TEXT, content TEXT) RETURNS TSVECTOR AS
+$$
+SELECT setweight(to_tsvector('simple', subject), 'A') || setweight(to_tsvector('simple', content), 'B');
+$$ LANGUAGE sql IMMUTABLE;
+
+ALTER TABLE tickets ADD COLUMN search_vector TSVECTOR;
+UPDATE tickets SET search_vector = ticket_search_vector(subject, content);
+
+CREATE INDEX tickets_search_vector ON tickets USING GIN (search_vector);
+
+-- Keeps the search document of tickets in sync with their subject and content.
+CREATE FUNCTION update_ticket_search_vector() RETURNS TRIGGER AS
+$$
+BEGIN
+    NEW.search_vector := ticket_search_vector(NEW.subject, NEW.content);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tickets_update_search_vector
+    BEFORE INSERT OR UPDATE OF subject, content
+    ON tickets
+    FOR EACH ROW
+EXECUTE PROCEDURE update_ticket_search_vector();
+
+-- Rebuilding search documents should not broadcast ticket changes, only changes of what listeners care about do.
+DROP TRIGGER tickets_notify_change ON tickets;
+
+CREATE TRIGGER tickets_notify_change
+    AFTER INSERT OR DELETE OR UPDATE OF owner, status
+    ON tickets
+    FOR EACH ROW
+EXECUTE PROCEDURE notify_ticket_change();
@@ -0,0 +1,245 @@
+package models
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// AdminAction model, an operational action taken through the admin API.
+type AdminAction string
+
+// Different admin action instances.
+const (
+	AdminActionFlushCaches    AdminAction = "FLUSH_CACHES"
+	AdminActionRotateAPIKeys  AdminAction = "ROTATE_API_KEYS"
+	AdminActionArchiveTickets AdminAction = "ARCHIVE_TICKETS"
+	AdminActionRecomputeSLA   AdminAction = "RECOMPUTE_SLA"
+)
+
+// IsValid reports whether the action is one of the known actions.
+func (a AdminAction) IsValid() bool {
+	switch a {
+	case AdminActionFlushCaches, AdminActionRotateAPIKeys, AdminActionArchiveTickets, AdminActionRecomputeSLA:
+		return true
+	}
+
+	return false
+}
+
+// AdminAuditLog is the entity model of admin_audit_logs table. It records who took an admin action, why, with which
+// parameters and whether it succeeded. Failure is the error code of failed actions. The table is append only, updating,
+// deleting or truncating it fails. Audit logs are chained as well, Hash is the Digest of the audit log chained to the
+// PreviousHash of the one recorded right before it, both empty for audit logs recorded before chaining.
+type AdminAuditLog struct {
+	ID           int64
+	Operator     string
+	Action       AdminAction
+	Reason       string
+	Parameters   string
+	Succeeded    bool
+	Failure      string
+	CreatedAt    time.Time
+	PreviousHash string
+	Hash         string
+}
+
+// Cursor returns back the position of the audit log among audit logs, the most recent first.
+func (l *AdminAuditLog) Cursor() Cursor {
+	return intCursor(l.ID)
+}
+
+// Digest returns back the hash of the audit log chained to the previous hash, i.e. the SHA-256 of both in hex.
+func (l *AdminAuditLog) Digest(previousHash string) string {
+	record, _ := json.Marshal([]interface{}{previousHash, l.ID, l.Operator, l.Action, l.Reason, l.Parameters,
+		l.Succeeded, l.Failure, l.CreatedAt.UTC().Format(time.RFC3339Nano)})
+
+	sum := sha256.Sum256(record)
+	return hex.EncodeToString(sum[:])
+}
+
+// AdminAuditChain is the outcome of verifying the chain of audit logs. Verified is the number of chained audit logs
+// verified, BrokenAt is the id of the first one not matching its hash or not following the previous one, if any, and
+// Head is the hash of the last one, which auditors can keep elsewhere to detect erasing the most recent ones.
+type AdminAuditChain struct {
+	Verified int64
+	BrokenAt int64
+	Head     string
+}
+
+// AdminRepository is the repository implementation of admin actions and their AdminAuditLog records.
+type AdminRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewAdminRepository returns back a newly created and ready to use AdminRepository.
+func NewAdminRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *AdminRepository {
+	return &AdminRepository{logger: logger, db: db}
+}
+
+// Notify tries to send an empty notification on each of the channels, which listeners of all kiosk instances take as
+// a change of everything they keep, e.g. to drop their caches.
+func (r *AdminRepository) Notify(ctx context.Context, channels []string) *errors.Type {
+	q := `SELECT pg_notify(channel, '') FROM UNNEST($1::TEXT[]) AS channel;`
+
+	if _, e := r.db.Exec(ctx, q, channels); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// InsertAuditLog tries to insert an audit log into admin_audit_logs table, chained to the last one, setting its id,
+// creation time and hashes as recorded. Audit logs are inserted one at a time, so the chain does not fork.
+func (r *AdminRepository) InsertAuditLog(ctx context.Context, log *AdminAuditLog) *errors.Type {
+	lockQ := `LOCK TABLE admin_audit_logs IN EXCLUSIVE MODE;`
+	previousQ := `SELECT COALESCE((SELECT hash FROM admin_audit_logs ORDER BY id DESC LIMIT 1), ''),
+			NEXTVAL('admin_audit_logs_id_seq');`
+	q := `INSERT INTO admin_audit_logs (id, operator, action, reason, parameters, succeeded, failure, created_at,
+			previous_hash, hash) VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, NULLIF($9, ''), $10);`
+
+	tx, e := r.db.Begin(ctx)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, e := tx.Exec(ctx, lockQ); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	if e := tx.QueryRow(ctx, previousQ).Scan(&log.PreviousHash, &log.ID); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	// Timestamps are stored in microseconds, the hashed one must be the stored one.
+	log.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	log.Hash = log.Digest(log.PreviousHash)
+
+	_, e = tx.Exec(ctx, q, log.ID, log.Operator, log.Action, log.Reason, log.Parameters, log.Succeeded, log.Failure,
+		log.CreatedAt, log.PreviousHash, log.Hash)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	if e := tx.Commit(ctx); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// VerifyAuditLogs tries to verify the chain of audit logs from the first chained one to the last one, stopping at the
+// first broken link.
+func (r *AdminRepository) VerifyAuditLogs(ctx context.Context) (*AdminAuditChain, *errors.Type) {
+	q := `SELECT ` + adminAuditLogColumns + ` FROM admin_audit_logs WHERE hash IS NOT NULL ORDER BY id;`
+
+	rows, e := r.db.Query(ctx, q)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	chain := &AdminAuditChain{}
+	for rows.Next() {
+		log, e := r.scan(rows)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		if log.PreviousHash != chain.Head || log.Digest(log.PreviousHash) != log.Hash {
+			chain.BrokenAt = log.ID
+			return chain, nil
+		}
+
+		chain.Verified++
+		chain.Head = log.Hash
+	}
+
+	if e := rows.Err(); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	return chain, nil
+}
+
+// LoadAuditLogs tries to load up to limit audit logs of the action, or of all actions if it is empty, the most recent
+// first. They start right after the audit log at the cursor when one is given, as returned back by its Cursor. The
+// second returned value tells whether there are more audit logs.
+func (r *AdminRepository) LoadAuditLogs(ctx context.Context, action AdminAction, after Cursor,
+	limit int) ([]*AdminAuditLog, bool, *errors.Type) {
+
+	// One more audit log is loaded to tell whether there are more.
+	q := `SELECT ` + adminAuditLogColumns + ` FROM admin_audit_logs WHERE ($1 = '' OR action = $1)
+			AND ($2::BIGINT IS NULL OR id < $2) ORDER BY id DESC LIMIT $3 + 1;`
+
+	rows, e := r.db.Query(ctx, q, action, after.key(), limit)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, false, et
+	}
+	defer rows.Close()
+
+	logs := make([]*AdminAuditLog, 0)
+	for rows.Next() {
+		log, e := r.scan(rows)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, false, et
+		}
+
+		logs = append(logs, log)
+	}
+
+	hasMore := len(logs) > limit
+	if hasMore {
+		// Drop the extra one.
+		logs = logs[:limit]
+	}
+
+	return logs, hasMore, nil
+}
+
+// adminAuditLogColumns are the columns audit logs are loaded with, see scan.
+const adminAuditLogColumns = `id, operator, action, reason, parameters, succeeded, COALESCE(failure, ''), created_at,
+		COALESCE(previous_hash, ''), COALESCE(hash, '')`
+
+func (r *AdminRepository) scan(row pgx.Row) (*AdminAuditLog, error) {
+	log := &AdminAuditLog{}
+
+	e := row.Scan(&log.ID, &log.Operator, &log.Action, &log.Reason, &log.Parameters, &log.Succeeded, &log.Failure,
+		&log.CreatedAt, &log.PreviousHash, &log.Hash)
+	if e != nil {
+		return nil, e
+	}
+
+	return log, nil
+}
@@ -0,0 +1,148 @@
+package models_test
+
+import (
+	"context"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Admin", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.AdminRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewAdminRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	Describe("AdminRepository", func() {
+		Context("When InsertAuditLog called", func() {
+			It("Should load audit logs back, the most recent first and filtered by action", func() {
+				logs := []models.AdminAuditLog{
+					{Operator: "ops@example.com", Action: models.AdminActionFlushCaches, Reason: "Stale counters",
+						Parameters: `{}`, Succeeded: true},
+					{Operator: "ops@example.com", Action: models.AdminActionRotateAPIKeys, Reason: "Leaked key",
+						Parameters: `{"issuer":"Microservice-A"}`, Failure: "tenant.not_found"},
+					{Operator: "ops@example.com", Action: models.AdminActionFlushCaches, Reason: "Stale entries",
+						Parameters: `{}`, Succeeded: true},
+				}
+
+				for i := range logs {
+					Ω(repository.InsertAuditLog(context.Background(), &logs[i])).Should(BeNil())
+				}
+
+				Ω(logs[2].ID).ShouldNot(BeZero())
+				Ω(logs[2].PreviousHash).Should(Equal(logs[1].Hash))
+
+				loaded, hasMore, e := repository.LoadAuditLogs(context.Background(), "", nil, 2)
+				Ω(e).Should(BeNil())
+				Ω(hasMore).Should(BeTrue())
+				Ω(loaded).Should(HaveLen(2))
+				Ω(loaded[0].Reason).Should(Equal("Stale entries"))
+				Ω(loaded[1].Succeeded).Should(BeFalse())
+				Ω(loaded[1].Failure).Should(Equal("tenant.not_found"))
+
+				older, hasMore, e := repository.LoadAuditLogs(context.Background(), "", loaded[1].Cursor(), 2)
+				Ω(e).Should(BeNil())
+				Ω(hasMore).Should(BeFalse())
+				Ω(older).Should(HaveLen(1))
+				Ω(older[0].Reason).Should(Equal("Stale counters"))
+
+				loaded, _, e = repository.LoadAuditLogs(context.Background(), models.AdminActionFlushCaches, nil, 10)
+				Ω(e).Should(BeNil())
+				Ω(loaded).Should(HaveLen(2))
+				Ω(loaded[1].Reason).Should(Equal("Stale counters"))
+			})
+		})
+
+		Context("When audit logs get updated or deleted", func() {
+			It("Should reject the change as the table is append only", func() {
+				log := models.AdminAuditLog{Operator: "ops@example.com", Action: models.AdminActionFlushCaches,
+					Reason: "Stale counters", Parameters: `{}`, Succeeded: true}
+				Ω(repository.InsertAuditLog(context.Background(), &log)).Should(BeNil())
+
+				_, e := db.Exec(context.Background(), `UPDATE admin_audit_logs SET reason = 'Nothing happened';`)
+				Ω(e).ShouldNot(BeNil())
+
+				_, e = db.Exec(context.Background(), `DELETE FROM admin_audit_logs;`)
+				Ω(e).ShouldNot(BeNil())
+
+				_, e = db.Exec(context.Background(), `TRUNCATE admin_audit_logs;`)
+				Ω(e).ShouldNot(BeNil())
+
+				loaded, _, et := repository.LoadAuditLogs(context.Background(), "", nil, 10)
+				Ω(et).Should(BeNil())
+				Ω(loaded).Should(HaveLen(1))
+				Ω(loaded[0].Reason).Should(Equal("Stale counters"))
+			})
+		})
+
+		Context("When VerifyAuditLogs called", func() {
+			It("Should verify the chain and detect audit logs tampered with", func() {
+				for _, reason := range []string{"Stale counters", "Stale entries", "Stale settings"} {
+					log := models.AdminAuditLog{Operator: "ops@example.com", Action: models.AdminActionFlushCaches,
+						Reason: reason, Parameters: `{}`, Succeeded: true}
+					Ω(repository.InsertAuditLog(context.Background(), &log)).Should(BeNil())
+				}
+
+				logs, _, e := repository.LoadAuditLogs(context.Background(), "", nil, 10)
+				Ω(e).Should(BeNil())
+				Ω(logs[2].PreviousHash).Should(BeEmpty())
+				Ω(logs[1].PreviousHash).Should(Equal(logs[2].Hash))
+				Ω(logs[0].PreviousHash).Should(Equal(logs[1].Hash))
+
+				chain, e := repository.VerifyAuditLogs(context.Background())
+				Ω(e).Should(BeNil())
+				Ω(chain.Verified).Should(Equal(int64(3)))
+				Ω(chain.BrokenAt).Should(BeZero())
+				Ω(chain.Head).Should(Equal(logs[0].Hash))
+
+				// Only the owner of the table can get around the append only triggers, as the tests do.
+				_, err := db.Exec(context.Background(), `ALTER TABLE admin_audit_logs DISABLE TRIGGER USER;`)
+				Ω(err).Should(BeNil())
+
+				_, err = db.Exec(context.Background(), `UPDATE admin_audit_logs SET reason = 'Nothing happened'
+					WHERE id = $1;`, logs[1].ID)
+				Ω(err).Should(BeNil())
+
+				chain, e = repository.VerifyAuditLogs(context.Background())
+				Ω(e).Should(BeNil())
+				Ω(chain.Verified).Should(Equal(int64(1)))
+				Ω(chain.BrokenAt).Should(Equal(logs[1].ID))
+			})
+		})
+
+		Context("When Notify called", func() {
+			It("Should notify the channels", func() {
+				e := repository.Notify(context.Background(), []string{"kiosk_ticket_changes", "kiosk_runtime_changes"})
+				Ω(e).Should(BeNil())
+			})
+		})
+	})
+})
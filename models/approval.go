@@ -0,0 +1,316 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// ApprovalState model.
+type ApprovalState string
+
+// Different approval state instances.
+const (
+	ApprovalStatePending  ApprovalState = "PENDING"
+	ApprovalStateApproved ApprovalState = "APPROVED"
+	ApprovalStateRejected ApprovalState = "REJECTED"
+)
+
+// Approver is the entity model of approvers table. Tickets of the issuer can only be moved to the status once one of
+// its approvers approves the transition.
+type Approver struct {
+	Issuer   string
+	Status   TicketStatus
+	Approver string
+}
+
+// ApproverRepository is the repository implementation of Approver model.
+type ApproverRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewApproverRepository returns back a newly created and ready to use ApproverRepository.
+func NewApproverRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *ApproverRepository {
+	return &ApproverRepository{logger: logger, db: db}
+}
+
+// Save tries to replace the approvers of an issuer and status with the provided ones.
+func (r *ApproverRepository) Save(ctx context.Context, issuer string, status TicketStatus,
+	approvers []string) *errors.Type {
+
+	begin := `BEGIN;`
+	deleteQ := `DELETE FROM approvers WHERE issuer = $1 AND status = $2;`
+	insertQ := `INSERT INTO approvers (issuer, status, approver, created_at) VALUES ($1, $2, $3, NOW())
+			ON CONFLICT DO NOTHING;`
+	commit := `COMMIT;`
+
+	batch := &pgx.Batch{}
+	batch.Queue(begin)
+	batch.Queue(deleteQ, issuer, status)
+	for _, approver := range approvers {
+		batch.Queue(insertQ, issuer, status, approver)
+	}
+	batch.Queue(commit)
+
+	if e := r.db.SendBatch(ctx, batch).Close(); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// LoadByIssuer tries to load the approvers of an issuer, ordered by status and approver.
+func (r *ApproverRepository) LoadByIssuer(ctx context.Context, issuer string) ([]*Approver, *errors.Type) {
+	q := `SELECT issuer, status, approver FROM approvers WHERE issuer = $1 ORDER BY status, approver;`
+
+	rows, e := r.db.Query(ctx, q, issuer)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	approvers := make([]*Approver, 0)
+	for rows.Next() {
+		approver := &Approver{}
+		if e := rows.Scan(&approver.Issuer, &approver.Status, &approver.Approver); e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		approvers = append(approvers, approver)
+	}
+
+	return approvers, nil
+}
+
+// LoadForTransition tries to load the approvers required to move a ticket to the status. It is empty when the
+// transition needs no approval, including when the ticket already has the status.
+func (r *ApproverRepository) LoadForTransition(ctx context.Context, ticketID int64, status TicketStatus) ([]string,
+	*errors.Type) {
+
+	return loadTransitionApprovers(ctx, r.logger, r.db, ticketID, status)
+}
+
+// Delete tries to delete the approvers of an issuer and status. Pending approvals can no longer be decided
+// afterwards, they need to be requested again once the status has approvers.
+func (r *ApproverRepository) Delete(ctx context.Context, issuer string, status TicketStatus) *errors.Type {
+	q := `DELETE FROM approvers WHERE issuer = $1 AND status = $2;`
+
+	if _, e := r.db.Exec(ctx, q, issuer, status); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// TicketApproval is the entity model of ticket_approvals table. It is an approval requested to move a ticket to a
+// status, along with its decision once made.
+type TicketApproval struct {
+	ID          int64
+	TicketID    int64
+	Status      TicketStatus
+	State       ApprovalState
+	RequestedBy string
+	Reason      string
+	DecidedBy   string
+	Note        string
+	RequestedAt time.Time
+	DecidedAt   *time.Time
+	// PreviousStatus is the status of the ticket before an approved transition, it is only set by Decide.
+	PreviousStatus TicketStatus
+}
+
+// TicketApprovalRepository is the repository implementation of TicketApproval model.
+type TicketApprovalRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewTicketApprovalRepository returns back a newly created and ready to use TicketApprovalRepository.
+func NewTicketApprovalRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *TicketApprovalRepository {
+	return &TicketApprovalRepository{logger: logger, db: db}
+}
+
+// Request tries to insert a pending approval and mark its ticket as pending approval, within the same statement. The
+// returned approval holds the id and time of the inserted record.
+func (r *TicketApprovalRepository) Request(ctx context.Context, approval TicketApproval) (*TicketApproval,
+	*errors.Type) {
+
+	q := `WITH approval AS (
+				INSERT INTO ticket_approvals (ticket_id, status, state, requested_by, reason, requested_at)
+				SELECT id, $2, $3, $4, NULLIF($5, ''), NOW() FROM tickets WHERE id = $1
+				ON CONFLICT (ticket_id) WHERE state = 'PENDING' DO NOTHING RETURNING id, requested_at
+			), ticket AS (
+				UPDATE tickets SET approval_state = $3 WHERE id = $1
+				AND EXISTS (SELECT 1 FROM approval)
+			)
+			SELECT id, requested_at FROM approval;`
+
+	approval.State = ApprovalStatePending
+	e := r.db.QueryRow(ctx, q, approval.TicketID, approval.Status, approval.State, approval.RequestedBy,
+		approval.Reason).Scan(&approval.ID, &approval.RequestedAt)
+	if e != nil {
+		if e == pgx.ErrNoRows {
+			return nil, errors.PreconditionFailed("approval.already_pending", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	return &approval, nil
+}
+
+// Decide tries to approve or reject the pending approval of a ticket on behalf of one of the approvers of its
+// transition. Approving moves the ticket to the requested status within the same transaction, failing when the
+// ticket is missing any field the status requires by then.
+func (r *TicketApprovalRepository) Decide(ctx context.Context, ticketID int64, approver string, state ApprovalState,
+	note string) (*TicketApproval, *errors.Type) {
+
+	tx, e := r.db.Begin(ctx)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	lockQ := `SELECT a.status FROM tickets AS t JOIN ticket_approvals AS a ON a.ticket_id = t.id AND a.state = $2
+			WHERE t.id = $1
+			AND EXISTS (SELECT 1 FROM approvers WHERE issuer = t.issuer AND status = a.status AND approver = $3)
+			FOR UPDATE OF t;`
+
+	var status TicketStatus
+	if e := tx.QueryRow(ctx, lockQ, ticketID, ApprovalStatePending, approver).Scan(&status); e != nil {
+		if e == pgx.ErrNoRows {
+			return nil, errors.PreconditionFailed("approval.not_found", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	if state == ApprovalStateApproved {
+		if e := checkRequiredFields(ctx, r.logger, tx, ticketID, status, Resolution{}); e != nil {
+			return nil, e
+		}
+	}
+
+	q := `WITH approval AS (
+				UPDATE ticket_approvals AS a SET state = $3, decided_by = $2, note = NULLIF($4, ''), decided_at = NOW()
+				FROM tickets AS t WHERE a.ticket_id = $1 AND a.state = $5 AND t.id = a.ticket_id
+				AND EXISTS (SELECT 1 FROM approvers WHERE issuer = t.issuer AND status = a.status AND approver = $2)
+				RETURNING a.id, a.ticket_id, a.status, a.requested_by, a.reason, a.requested_at, a.decided_at,
+				t.status AS previous_status
+			)
+			UPDATE tickets AS t SET approval_state = $3,
+			status = CASE WHEN $3 = $6 THEN approval.status ELSE t.status END,
+			waiting_since = CASE WHEN $3 = $6 THEN NULL ELSE t.waiting_since END,
+			nudged_at = CASE WHEN $3 = $6 THEN NULL ELSE t.nudged_at END,
+			resolved_at = CASE WHEN $3 = $6 AND approval.status = $7 AND approval.previous_status <> $7 THEN NOW()
+			ELSE t.resolved_at END
+			FROM approval WHERE t.id = approval.ticket_id
+			RETURNING approval.id, approval.ticket_id, approval.status, approval.requested_by, approval.reason,
+			approval.requested_at, approval.decided_at, approval.previous_status;`
+
+	approval := &TicketApproval{State: state, DecidedBy: approver, Note: note}
+	var reason sql.NullString
+
+	e = tx.QueryRow(ctx, q, ticketID, approver, state, note, ApprovalStatePending, ApprovalStateApproved,
+		TicketStatusResolved).Scan(
+		&approval.ID, &approval.TicketID, &approval.Status, &approval.RequestedBy, &reason, &approval.RequestedAt,
+		&approval.DecidedAt, &approval.PreviousStatus)
+	if e != nil {
+		if e == pgx.ErrNoRows {
+			return nil, errors.PreconditionFailed("approval.not_found", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	if e := tx.Commit(ctx); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	approval.Reason = reason.String
+	return approval, nil
+}
+
+// LoadByTicketID tries to load the approvals of a ticket, oldest first.
+func (r *TicketApprovalRepository) LoadByTicketID(ctx context.Context, ticketID int64) ([]*TicketApproval,
+	*errors.Type) {
+
+	q := `SELECT id, ticket_id, status, state, requested_by, reason, decided_by, note, requested_at, decided_at
+			FROM ticket_approvals WHERE ticket_id = $1 ORDER BY requested_at, id;`
+
+	rows, e := r.db.Query(ctx, q, ticketID)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	approvals := make([]*TicketApproval, 0)
+	for rows.Next() {
+		approval := &TicketApproval{}
+		var reason, decidedBy, note sql.NullString
+
+		e := rows.Scan(&approval.ID, &approval.TicketID, &approval.Status, &approval.State, &approval.RequestedBy,
+			&reason, &decidedBy, &note, &approval.RequestedAt, &approval.DecidedAt)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		approval.Reason = reason.String
+		approval.DecidedBy = decidedBy.String
+		approval.Note = note.String
+		approvals = append(approvals, approval)
+	}
+
+	return approvals, nil
+}
+
+// loadTransitionApprovers is LoadForTransition run on db, which is either a pool or a transaction.
+func loadTransitionApprovers(ctx context.Context, logger *zap.SugaredLogger, db querier, ticketID int64,
+	status TicketStatus) ([]string, *errors.Type) {
+
+	q := `SELECT t.id, COALESCE(array_agg(a.approver ORDER BY a.approver) FILTER (WHERE a.approver IS NOT NULL), '{}')
+			FROM tickets AS t LEFT JOIN approvers AS a ON a.issuer = t.issuer AND a.status = $2 AND t.status <> $2
+			WHERE t.id = $1 GROUP BY t.id;`
+
+	var id int64
+	approvers := make([]string, 0)
+	if e := db.QueryRow(ctx, q, ticketID, status).Scan(&id, &approvers); e != nil {
+		if e == pgx.ErrNoRows {
+			return nil, errors.PreconditionFailed("ticket.not_found", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	return approvers, nil
+}
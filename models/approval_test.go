@@ -0,0 +1,187 @@
+package models_test
+
+import (
+	"context"
+	"net/http"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Approval", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.TicketApprovalRepository
+	var approverRepository *models.ApproverRepository
+	var ticketRepository *models.TicketRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewTicketApprovalRepository(zap.S(), db)
+			approverRepository = models.NewApproverRepository(zap.S(), db)
+			ticketRepository = models.NewTicketRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	insertTicket := func() int64 {
+		ticket := models.Ticket{Issuer: "Microservice-A", Owner: "user-1", Subject: "Subject", Content: "Content",
+			ImportanceLevel: models.TicketImportanceLevelHigh}
+		id, e := ticketRepository.Insert(context.Background(), ticket)
+		Ω(e).Should(BeNil())
+
+		return id
+	}
+
+	Describe("ApproverRepository", func() {
+		Context("When Save, LoadForTransition and Delete called", func() {
+			It("Should guard the transitions to the status until the approvers are deleted", func() {
+				id := insertTicket()
+
+				e := approverRepository.Save(context.Background(), "Microservice-A", models.TicketStatusResolved,
+					[]string{"lead@example.com", "finance@example.com"})
+				Ω(e).Should(BeNil())
+
+				approvers, e := approverRepository.LoadForTransition(context.Background(), id,
+					models.TicketStatusResolved)
+				Ω(e).Should(BeNil())
+				Ω(approvers).Should(Equal([]string{"finance@example.com", "lead@example.com"}))
+
+				approvers, e = approverRepository.LoadForTransition(context.Background(), id, models.TicketStatusClosed)
+				Ω(e).Should(BeNil())
+				Ω(approvers).Should(BeEmpty())
+
+				e = approverRepository.Delete(context.Background(), "Microservice-A", models.TicketStatusResolved)
+				Ω(e).Should(BeNil())
+
+				approvers, e = approverRepository.LoadForTransition(context.Background(), id,
+					models.TicketStatusResolved)
+				Ω(e).Should(BeNil())
+				Ω(approvers).Should(BeEmpty())
+
+				_, e = approverRepository.LoadForTransition(context.Background(), 1000, models.TicketStatusResolved)
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusPreconditionFailed))
+			})
+		})
+	})
+
+	Describe("TicketApprovalRepository", func() {
+		Context("When Request and Decide called", func() {
+			It("Should move the ticket to the requested status once approved by an approver", func() {
+				id := insertTicket()
+
+				e := approverRepository.Save(context.Background(), "Microservice-A", models.TicketStatusResolved,
+					[]string{"lead@example.com"})
+				Ω(e).Should(BeNil())
+
+				approval := models.TicketApproval{TicketID: id, Status: models.TicketStatusResolved,
+					RequestedBy: "agent-1", Reason: "Refunded"}
+				_, e = repository.Request(context.Background(), approval)
+				Ω(e).Should(BeNil())
+
+				_, e = repository.Request(context.Background(), approval)
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusPreconditionFailed))
+
+				ticket, e := ticketRepository.LoadByID(context.Background(), id)
+				Ω(e).Should(BeNil())
+				Ω(ticket.ApprovalState).Should(Equal(models.ApprovalStatePending))
+
+				_, e = repository.Decide(context.Background(), id, "someone@example.com", models.ApprovalStateApproved,
+					"")
+				Ω(e).ShouldNot(BeNil())
+
+				decided, e := repository.Decide(context.Background(), id, "lead@example.com",
+					models.ApprovalStateApproved, "Looks good")
+				Ω(e).Should(BeNil())
+				Ω(decided.PreviousStatus).Should(Equal(models.TicketStatusNew))
+				Ω(decided.Reason).Should(Equal("Refunded"))
+
+				ticket, e = ticketRepository.LoadByID(context.Background(), id)
+				Ω(e).Should(BeNil())
+				Ω(ticket.Status).Should(Equal(models.TicketStatusResolved))
+				Ω(ticket.ApprovalState).Should(Equal(models.ApprovalStateApproved))
+
+				approvals, e := repository.LoadByTicketID(context.Background(), id)
+				Ω(e).Should(BeNil())
+				Ω(approvals).Should(HaveLen(1))
+				Ω(approvals[0].DecidedBy).Should(Equal("lead@example.com"))
+				Ω(approvals[0].Note).Should(Equal("Looks good"))
+			})
+
+			It("Should not approve moving a ticket missing a field the status requires by then", func() {
+				id := insertTicket()
+
+				e := approverRepository.Save(context.Background(), "Microservice-A", models.TicketStatusResolved,
+					[]string{"lead@example.com"})
+				Ω(e).Should(BeNil())
+
+				_, e = repository.Request(context.Background(), models.TicketApproval{TicketID: id,
+					Status: models.TicketStatusResolved, RequestedBy: "agent-1"})
+				Ω(e).Should(BeNil())
+
+				requirementRepository := models.NewTransitionRequirementRepository(zap.S(), db)
+				Ω(requirementRepository.Save(context.Background(), "Microservice-A", models.TicketStatusResolved,
+					[]models.RequiredField{models.RequiredFieldAssignee})).Should(BeNil())
+
+				_, e = repository.Decide(context.Background(), id, "lead@example.com", models.ApprovalStateApproved, "")
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("assignee.is_required"))
+
+				ticket, e := ticketRepository.LoadByID(context.Background(), id)
+				Ω(e).Should(BeNil())
+				Ω(ticket.Status).Should(Equal(models.TicketStatusNew))
+				Ω(ticket.ApprovalState).Should(Equal(models.ApprovalStatePending))
+
+				_, e = ticketRepository.Assign(context.Background(), id, "agent-1", false)
+				Ω(e).Should(BeNil())
+
+				_, e = repository.Decide(context.Background(), id, "lead@example.com", models.ApprovalStateApproved, "")
+				Ω(e).Should(BeNil())
+			})
+
+			It("Should leave the ticket as is once rejected", func() {
+				id := insertTicket()
+
+				e := approverRepository.Save(context.Background(), "Microservice-A", models.TicketStatusResolved,
+					[]string{"lead@example.com"})
+				Ω(e).Should(BeNil())
+
+				_, e = repository.Request(context.Background(), models.TicketApproval{TicketID: id,
+					Status: models.TicketStatusResolved, RequestedBy: "agent-1"})
+				Ω(e).Should(BeNil())
+
+				_, e = repository.Decide(context.Background(), id, "lead@example.com", models.ApprovalStateRejected, "")
+				Ω(e).Should(BeNil())
+
+				ticket, e := ticketRepository.LoadByID(context.Background(), id)
+				Ω(e).Should(BeNil())
+				Ω(ticket.Status).Should(Equal(models.TicketStatusNew))
+				Ω(ticket.ApprovalState).Should(Equal(models.ApprovalStateRejected))
+			})
+		})
+	})
+})
@@ -0,0 +1,236 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/db/filters"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// ArchiveRepository is the repository implementation of archived tickets, i.e. closed tickets moved from tickets table
+// to archived_tickets table along with their comments once they get old, so the hot tables stay small. Archived
+// tickets are read only.
+type ArchiveRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewArchiveRepository returns back a newly created and ready to use ArchiveRepository.
+func NewArchiveRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *ArchiveRepository {
+	return &ArchiveRepository{logger: logger, db: db}
+}
+
+// Archive tries to archive up to limit closed tickets not modified since before, the least recently modified first,
+// in a single transaction. Archived tickets are deleted from the hot tables the same way DeleteByID deletes tickets,
+// so the records depending on them other than their comments are dropped. Tickets with attachments stay in the hot
+// tables, since the archive does not keep attachments. It returns back the archived tickets as they were, i.e. their
+// id, issuer, owner, importance level and status.
+func (r *ArchiveRepository) Archive(ctx context.Context, before time.Time, limit int) ([]*Ticket, *errors.Type) {
+	tx, e := r.db.Begin(ctx)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	q := `WITH candidates AS (SELECT id FROM tickets WHERE status = $1 AND modified_at < $2
+			AND NOT EXISTS (SELECT 1 FROM attachments AS a WHERE a.ticket_id = tickets.id) ORDER BY modified_at
+			LIMIT $3 FOR UPDATE SKIP LOCKED)
+			INSERT INTO archived_tickets (id, issuer, owner, subject, content, metadata, importance_level, status,
+			resolution_category, resolution_sub_category, root_cause, resolved_at, comments, created_at, modified_at,
+			archived_at)
+			SELECT t.id, t.issuer, t.owner, t.subject, t.content, t.metadata #>> '{}', t.importance_level, t.status,
+			t.resolution_category, t.resolution_sub_category, t.root_cause, t.resolved_at,
+			COALESCE((SELECT jsonb_agg(jsonb_build_object('id', c.id, 'owner', c.owner, 'content', c.content,
+			'metadata', c.metadata, 'authorType', c.author_type, 'source', c.source, 'revision', c.revision,
+			'editedAt', c.edited_at, 'createdAt', c.created_at, 'modifiedAt', c.modified_at) ORDER BY c.created_at DESC)
+			FROM comments AS c WHERE c.ticket_id = t.id), '[]'), t.created_at, t.modified_at, NOW()
+			FROM tickets AS t WHERE t.id IN (SELECT id FROM candidates)
+			RETURNING id, issuer, owner, importance_level, status;`
+
+	rows, e := tx.Query(ctx, q, TicketStatusClosed, utc(&before), limit)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	archived := make([]*Ticket, 0, limit)
+	ids := make([]int64, 0, limit)
+	for rows.Next() {
+		ticket := &Ticket{Archived: true}
+		if e := rows.Scan(&ticket.ID, &ticket.Issuer, &ticket.Owner, &ticket.ImportanceLevel,
+			&ticket.Status); e != nil {
+
+			rows.Close()
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		archived = append(archived, ticket)
+		ids = append(ids, ticket.ID)
+	}
+	rows.Close()
+
+	if e := rows.Err(); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	if len(archived) == 0 {
+		return archived, nil
+	}
+
+	for _, dependentsQ := range ticketDependentsQueries {
+		if _, e := tx.Exec(ctx, dependentsQ, ids); e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+	}
+
+	if _, e := tx.Exec(ctx, `DELETE FROM tickets WHERE id = ANY($1);`, ids); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	if e := tx.Commit(ctx); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	return archived, nil
+}
+
+// archivedTicketColumns are the columns archived tickets are loaded with, see scan.
+const archivedTicketColumns = `id, issuer, owner, subject, content, metadata, importance_level, status,
+		COALESCE(resolution_category, ''), COALESCE(resolution_sub_category, ''), COALESCE(root_cause, ''), resolved_at,
+		created_at, modified_at`
+
+// LoadByID tries to load an archived ticket along with its comments.
+func (r *ArchiveRepository) LoadByID(ctx context.Context, id int64) (*Ticket, *errors.Type) {
+	q := `SELECT ` + archivedTicketColumns + `, comments FROM archived_tickets WHERE id = $1;`
+
+	var comments []byte
+	ticket, e := r.scan(r.db.QueryRow(ctx, q, id), &comments)
+	if e != nil {
+		if e == pgx.ErrNoRows {
+			return nil, errors.NotFound("ticket.not_found", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	if ticket.Comments, e = decodeComments(ticket.ID, comments); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	return ticket, nil
+}
+
+// Filter tries to filter archived tickets the way TicketRepository.Filter filters tickets, except tickets can not be
+// matched by their metadata and are always ordered by their modification time, the most recent first, and paged by
+// skipping pageNumber pages. Their comments are not loaded. If there is another page of result, the second returned
+// value is true.
+func (r *ArchiveRepository) Filter(ctx context.Context, issuer, owner string, importanceLevel TicketImportanceLevel,
+	status TicketStatus, resolution Resolution, fromDate, toDate string, pageNumber, pageSize int) ([]*Ticket, bool,
+	*errors.Type) {
+
+	b := filters.New("modified_at", "issuer", "owner", "importance_level", "status", "resolution_category",
+		"resolution_sub_category", "root_cause").
+		Where("modified_at", filters.GreaterOrEqual, fromDate).
+		Where("modified_at", filters.Less, toDate)
+
+	if issuer != "" {
+		b.Where("issuer", filters.Equal, issuer)
+	}
+
+	if owner != "" {
+		b.Where("owner", filters.Equal, owner)
+	}
+
+	if importanceLevel != "" {
+		b.Where("importance_level", filters.Equal, importanceLevel)
+	}
+
+	if status != "" {
+		b.Where("status", filters.Equal, status)
+	}
+
+	if resolution.Category != "" {
+		b.Where("resolution_category", filters.Equal, resolution.Category)
+	}
+
+	if resolution.SubCategory != "" {
+		b.Where("resolution_sub_category", filters.Equal, resolution.SubCategory)
+	}
+
+	if resolution.RootCause != "" {
+		b.Where("root_cause", filters.Equal, resolution.RootCause)
+	}
+
+	limit, offset := b.Bind(pageSize+1), b.Bind((pageNumber-1)*pageSize)
+	conditions, args, e := b.Build()
+	if e != nil {
+		r.logger.Error("failed to build filter conditions: ", e.Error())
+		return make([]*Ticket, 0), false, nil
+	}
+
+	q := `SELECT ` + archivedTicketColumns + ` FROM archived_tickets WHERE` + conditions +
+		` ORDER BY modified_at DESC, id DESC LIMIT ` + limit + ` OFFSET ` + offset + `;`
+
+	rows, e := r.db.Query(ctx, q, args...)
+	if e != nil {
+		return nil, false, queryFailed(r.logger, e)
+	}
+	defer rows.Close()
+
+	tickets := make([]*Ticket, 0, pageSize)
+	for rows.Next() {
+		ticket, e := r.scan(rows)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, false, et
+		}
+
+		tickets = append(tickets, ticket)
+	}
+
+	if len(tickets) > pageSize {
+		return tickets[:pageSize], true, nil
+	}
+
+	return tickets, false, nil
+}
+
+// scan scans an archived ticket loaded with archivedTicketColumns, followed by the provided destinations if any.
+func (r *ArchiveRepository) scan(row pgx.Row, rest ...interface{}) (*Ticket, error) {
+	ticket := &Ticket{Archived: true}
+	var metadata sql.NullString
+
+	destinations := append([]interface{}{&ticket.ID, &ticket.Issuer, &ticket.Owner, &ticket.Subject, &ticket.Content,
+		&metadata, &ticket.ImportanceLevel, &ticket.Status, &ticket.Resolution.Category,
+		&ticket.Resolution.SubCategory, &ticket.Resolution.RootCause, &ticket.ResolvedAt, &ticket.CreatedAt,
+		&ticket.ModifiedAt}, rest...)
+	if e := row.Scan(destinations...); e != nil {
+		return nil, e
+	}
+
+	ticket.Metadata = metadata.String
+	return ticket, nil
+}
@@ -0,0 +1,115 @@
+package models_test
+
+import (
+	"context"
+	"time"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Archive", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.ArchiveRepository
+	var ticketRepository *models.TicketRepository
+	var commentRepository *models.CommentRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewArchiveRepository(zap.S(), db)
+			ticketRepository = models.NewTicketRepository(zap.S(), db)
+			commentRepository = models.NewCommentRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	insertTicket := func(status models.TicketStatus) int64 {
+		id, e := ticketRepository.Insert(context.Background(), models.Ticket{Issuer: "Microservice-A",
+			Owner: "user@example.com", Subject: "Payments fail", Content: "Content",
+			ImportanceLevel: models.TicketImportanceLevelHigh, Status: status})
+		Ω(e).Should(BeNil())
+		return id
+	}
+
+	Describe("ArchiveRepository", func() {
+		Context("When Archive called", func() {
+			It("Should move the old closed tickets along with their comments to the archive", func() {
+				closed, open := insertTicket(models.TicketStatusClosed), insertTicket(models.TicketStatusReplied)
+
+				_, e := commentRepository.Insert(context.Background(), models.Comment{TicketID: closed,
+					Owner: "agent@example.com", Content: "Payments are back.",
+					AuthorType: models.CommentAuthorTypeAgent})
+				Ω(e).Should(BeNil())
+
+				archived, e := repository.Archive(context.Background(), time.Now().Add(-time.Minute), 10)
+				Ω(e).Should(BeNil())
+				Ω(archived).Should(BeEmpty())
+
+				archived, e = repository.Archive(context.Background(), time.Now().Add(time.Minute), 10)
+				Ω(e).Should(BeNil())
+				Ω(archived).Should(HaveLen(1))
+				Ω(archived[0].ID).Should(Equal(closed))
+				Ω(archived[0].Owner).Should(Equal("user@example.com"))
+				Ω(archived[0].Status).Should(Equal(models.TicketStatusClosed))
+
+				_, e = ticketRepository.LoadByID(context.Background(), closed)
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("ticket.not_found"))
+
+				_, e = ticketRepository.LoadByID(context.Background(), open)
+				Ω(e).Should(BeNil())
+
+				ticket, e := repository.LoadByID(context.Background(), closed)
+				Ω(e).Should(BeNil())
+				Ω(ticket.Archived).Should(BeTrue())
+				Ω(ticket.Subject).Should(Equal("Payments fail"))
+				Ω(ticket.Comments).Should(HaveLen(1))
+				Ω(ticket.Comments[0].Content).Should(Equal("Payments are back."))
+				Ω(ticket.Comments[0].AuthorType).Should(Equal(models.CommentAuthorTypeAgent))
+
+				_, e = repository.LoadByID(context.Background(), open)
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("ticket.not_found"))
+
+				fromDate := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339Nano)
+				toDate := time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano)
+
+				ts, hasNextPage, e := repository.Filter(context.Background(), "Microservice-A", "user@example.com",
+					"", models.TicketStatusClosed, models.Resolution{}, fromDate, toDate, 1, 10)
+				Ω(e).Should(BeNil())
+				Ω(hasNextPage).Should(BeFalse())
+				Ω(ts).Should(HaveLen(1))
+				Ω(ts[0].ID).Should(Equal(closed))
+				Ω(ts[0].Archived).Should(BeTrue())
+
+				ts, _, e = repository.Filter(context.Background(), "Microservice-B", "", "", "", models.Resolution{},
+					fromDate, toDate, 1, 10)
+				Ω(e).Should(BeNil())
+				Ω(ts).Should(BeEmpty())
+			})
+		})
+	})
+})
@@ -0,0 +1,525 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// Attachment is the entity model of attachments table. It is a file attached to a ticket, or to one of its comments
+// when CommentID is not zero. Its content gets uploaded in chunks, Size is the number of bytes uploaded so far and
+// CompletedAt is when the upload got completed or nil if it is still in progress. Completed contents are stored by
+// their SHA-256 ContentHash, so identical files attached many times are stored once.
+type Attachment struct {
+	Model
+
+	TicketID    int64
+	CommentID   int64
+	Owner       string
+	Name        string
+	ContentType string
+	Size        int64
+	Storage     AttachmentStorage
+	ContentHash string
+	CompletedAt *time.Time
+}
+
+// Key returns back the key the content of the attachment is stored under in object storages. Attachments completed
+// before contents got stored by their hashes have their own keys.
+func (a *Attachment) Key() string {
+	if a.ContentHash == "" {
+		return fmt.Sprintf("attachments/%d", a.ID)
+	}
+
+	return blobKey(a.ContentHash)
+}
+
+// Cursor returns back the position of the attachment among attachments, in the order they got created.
+func (a *Attachment) Cursor() Cursor {
+	return intCursor(a.ID)
+}
+
+// blobKey returns back the key a content is stored under in object storages.
+func blobKey(hash string) string {
+	return "attachments/sha256/" + hash
+}
+
+// AttachmentStorage model, where the content of an attachment is stored.
+type AttachmentStorage string
+
+// Different attachment storage instances.
+const (
+	AttachmentStorageDatabase AttachmentStorage = "DATABASE"
+	AttachmentStorageS3       AttachmentStorage = "S3"
+)
+
+// AttachmentRepository is the repository implementation of Attachment model.
+type AttachmentRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewAttachmentRepository returns back a newly created and ready to use AttachmentRepository.
+func NewAttachmentRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *AttachmentRepository {
+	return &AttachmentRepository{logger: logger, db: db}
+}
+
+// Insert tries to insert an attachment without any content into attachments table and returns back its id. The
+// comment it is attached to, if any, must be a comment of its ticket.
+func (r *AttachmentRepository) Insert(ctx context.Context, attachment Attachment) (int64, *errors.Type) {
+	if attachment.CommentID != 0 {
+		q := `SELECT EXISTS (SELECT 1 FROM comments WHERE id = $1 AND ticket_id = $2);`
+
+		var exists bool
+		if e := r.db.QueryRow(ctx, q, attachment.CommentID, attachment.TicketID).Scan(&exists); e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return 0, et
+		}
+
+		if !exists {
+			return 0, errors.PreconditionFailed("comment.not_exists", "")
+		}
+	}
+
+	q := `INSERT INTO attachments (ticket_id, comment_id, owner, name, content_type, size, storage, created_at,
+			modified_at) VALUES ($1, NULLIF($2, 0), $3, $4, $5, 0, $6, NOW(), NOW()) RETURNING id;`
+
+	var id int64
+	e := r.db.QueryRow(ctx, q, attachment.TicketID, attachment.CommentID, attachment.Owner, attachment.Name,
+		attachment.ContentType, AttachmentStorageDatabase).Scan(&id)
+	if e != nil {
+		if strings.Contains(e.Error(), "attachments_ticket_id_fkey") {
+			return 0, errors.PreconditionFailed("ticket.not_exists", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return 0, et
+	}
+
+	return id, nil
+}
+
+// attachmentColumns are the columns attachments are loaded with, see scan.
+const attachmentColumns = `id, ticket_id, COALESCE(comment_id, 0), owner, name, content_type, size, storage,
+		COALESCE(content_hash, ''), completed_at, created_at, modified_at`
+
+// LoadByID tries to load an attachment from attachments table.
+func (r *AttachmentRepository) LoadByID(ctx context.Context, id int64) (*Attachment, *errors.Type) {
+	q := `SELECT ` + attachmentColumns + ` FROM attachments WHERE id = $1;`
+
+	attachment, e := r.scan(r.db.QueryRow(ctx, q, id))
+	if e != nil {
+		if e == pgx.ErrNoRows {
+			return nil, errors.NotFound("attachment.not_found", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	return attachment, nil
+}
+
+// LoadByIDs tries to load the attachments of the provided ids within the tenant of the ticket, those of other tenants
+// are left out as if they did not exist.
+func (r *AttachmentRepository) LoadByIDs(ctx context.Context, ticketID int64, ids []int64) ([]*Attachment,
+	*errors.Type) {
+
+	q := `SELECT ` + attachmentColumns + ` FROM attachments WHERE id = ANY($1)
+			AND ticket_id IN (SELECT id FROM tickets WHERE issuer = (SELECT issuer FROM tickets WHERE id = $2))
+			ORDER BY id;`
+
+	rows, e := r.db.Query(ctx, q, ids, ticketID)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	attachments := make([]*Attachment, 0)
+	for rows.Next() {
+		attachment, e := r.scan(rows)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments, nil
+}
+
+// LoadByTicketID tries to load up to limit completed attachments of a ticket and its comments, or all of them when
+// zero, in the order they got created. They start right after the attachment at the cursor when one is given, as
+// returned back by its Cursor. The second returned value tells whether there are more attachments.
+func (r *AttachmentRepository) LoadByTicketID(ctx context.Context, ticketID int64, after Cursor,
+	limit int) ([]*Attachment, bool, *errors.Type) {
+
+	// One more attachment is loaded to tell whether there are more, a NULL limit loads all of them.
+	q := `SELECT ` + attachmentColumns + ` FROM attachments WHERE ticket_id = $1 AND completed_at IS NOT NULL
+			AND ($2::BIGINT IS NULL OR id > $2) ORDER BY id LIMIT NULLIF($3::INT, 0) + 1;`
+
+	rows, e := r.db.Query(ctx, q, ticketID, after.key(), limit)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, false, et
+	}
+	defer rows.Close()
+
+	attachments := make([]*Attachment, 0)
+	for rows.Next() {
+		attachment, e := r.scan(rows)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, false, et
+		}
+
+		attachments = append(attachments, attachment)
+	}
+
+	hasMore := limit > 0 && len(attachments) > limit
+	if hasMore {
+		// Drop the extra one.
+		attachments = attachments[:limit]
+	}
+
+	return attachments, hasMore, nil
+}
+
+// Append tries to append a chunk to the content of an attachment still being uploaded. The chunk must start right
+// where the uploaded content ends, so lost or repeated chunks are caught, and the content may not outgrow maxSize. It
+// returns back the attachment along with the chunk.
+func (r *AttachmentRepository) Append(ctx context.Context, id, offset int64, chunk []byte, maxSize int64) (*Attachment,
+	*errors.Type) {
+
+	tx, e := r.db.Begin(ctx)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	q := `SELECT ` + attachmentColumns + ` FROM attachments WHERE id = $1 FOR UPDATE;`
+
+	attachment, e := r.scan(tx.QueryRow(ctx, q, id))
+	if e != nil {
+		if e == pgx.ErrNoRows {
+			return nil, errors.NotFound("attachment.not_found", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	if attachment.CompletedAt != nil {
+		return nil, errors.PreconditionFailed("attachment.already_completed", "")
+	}
+
+	if offset != attachment.Size {
+		return nil, errors.PreconditionFailed("offset.not_valid", "")
+	}
+
+	if attachment.Size+int64(len(chunk)) > maxSize {
+		return nil, errors.InvalidArgument("attachment.too_large", "")
+	}
+
+	if len(chunk) == 0 {
+		return attachment, nil
+	}
+
+	chunkQ := `INSERT INTO attachment_chunks (attachment_id, position, content) VALUES ($1, $2, $3);`
+	sizeQ := `UPDATE attachments SET size = size + $1, modified_at = NOW() WHERE id = $2;`
+
+	if _, e := tx.Exec(ctx, chunkQ, id, offset, chunk); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	if _, e := tx.Exec(ctx, sizeQ, len(chunk), id); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	if e := tx.Commit(ctx); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	attachment.Size += int64(len(chunk))
+	return attachment, nil
+}
+
+// Read tries to read up to length bytes of the content of an attachment stored in the database, starting from offset.
+// Completed attachments whose contents are stored by their hashes are read by ReadBlob instead.
+func (r *AttachmentRepository) Read(ctx context.Context, id, offset, length int64) ([]byte, *errors.Type) {
+	q := `SELECT position, content FROM attachment_chunks WHERE attachment_id = $1 AND position < $3
+			AND position + LENGTH(content) > $2 ORDER BY position;`
+
+	return r.read(ctx, q, id, offset, length)
+}
+
+// ReadBlob tries to read up to length bytes of a content stored in the database by its hash, starting from offset.
+func (r *AttachmentRepository) ReadBlob(ctx context.Context, hash string, offset, length int64) ([]byte,
+	*errors.Type) {
+
+	q := `SELECT position, content FROM attachment_blob_chunks WHERE hash = $1 AND position < $3
+			AND position + LENGTH(content) > $2 ORDER BY position;`
+
+	return r.read(ctx, q, hash, offset, length)
+}
+
+// read reads up to length bytes of the chunks the query loads by the key, starting from offset.
+func (r *AttachmentRepository) read(ctx context.Context, q string, key interface{}, offset, length int64) ([]byte,
+	*errors.Type) {
+
+	rows, e := r.db.Query(ctx, q, key, offset, offset+length)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	content := make([]byte, 0, length)
+	for rows.Next() {
+		var position int64
+		var chunk []byte
+		if e := rows.Scan(&position, &chunk); e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		// Chunks are contiguous, so only the first and the last ones may be cut.
+		if offset > position {
+			chunk = chunk[offset-position:]
+		}
+
+		if remaining := length - int64(len(content)); int64(len(chunk)) > remaining {
+			chunk = chunk[:remaining]
+		}
+
+		content = append(content, chunk...)
+	}
+
+	return content, nil
+}
+
+// Reference tries to refer the uploaded content of an attachment to the content stored by its hash, storing it in the
+// provided storage unless it is stored already. It returns back the storage the content is stored in and whether the
+// content was not referred to by any other attachment. Such contents are copied from the uploaded chunks when stored
+// in the database, otherwise the caller must store them before completing the upload.
+func (r *AttachmentRepository) Reference(ctx context.Context, id int64, hash string, storage AttachmentStorage) (
+	AttachmentStorage, bool, *errors.Type) {
+
+	tx, e := r.db.Begin(ctx)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return "", false, et
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	q := `SELECT size FROM attachments WHERE id = $1 AND completed_at IS NULL AND content_hash IS NULL FOR UPDATE;`
+
+	var size int64
+	if e := tx.QueryRow(ctx, q, id).Scan(&size); e != nil {
+		if e == pgx.ErrNoRows {
+			return "", false, errors.PreconditionFailed("attachment.already_completed", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return "", false, et
+	}
+
+	blobQ := `INSERT INTO attachment_blobs AS b (hash, size, storage, reference_count, created_at)
+			VALUES ($1, $2, $3, 1, NOW())
+			ON CONFLICT (hash) DO UPDATE SET reference_count = b.reference_count + 1
+			RETURNING b.storage, b.reference_count = 1;`
+	chunksQ := `INSERT INTO attachment_blob_chunks (hash, position, content)
+			SELECT $1, position, content FROM attachment_chunks WHERE attachment_id = $2;`
+	attachmentQ := `UPDATE attachments SET content_hash = $1, storage = $2, modified_at = NOW() WHERE id = $3;`
+
+	var fresh bool
+	if e := tx.QueryRow(ctx, blobQ, hash, size, storage).Scan(&storage, &fresh); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return "", false, et
+	}
+
+	if fresh && storage == AttachmentStorageDatabase {
+		if _, e := tx.Exec(ctx, chunksQ, hash, id); e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return "", false, et
+		}
+	}
+
+	if _, e := tx.Exec(ctx, attachmentQ, hash, storage, id); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return "", false, et
+	}
+
+	if e := tx.Commit(ctx); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return "", false, et
+	}
+
+	return storage, fresh, nil
+}
+
+// Complete tries to mark the upload of an attachment as completed, once its content is referenced. The uploaded chunks
+// are deleted, as the content is read by its hash from then on.
+func (r *AttachmentRepository) Complete(ctx context.Context, id int64) *errors.Type {
+	tx, e := r.db.Begin(ctx)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	q := `UPDATE attachments SET completed_at = NOW(), modified_at = NOW() WHERE id = $1 AND completed_at IS NULL;`
+
+	tag, e := tx.Exec(ctx, q, id)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errors.PreconditionFailed("attachment.already_completed", "")
+	}
+
+	if _, e := tx.Exec(ctx, `DELETE FROM attachment_chunks WHERE attachment_id = $1;`, id); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	if e := tx.Commit(ctx); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// DeleteByID tries to delete an attachment along with its chunks, releasing its reference to the content stored by its
+// hash. The returned attachment is the deleted record or nil when there was no such record.
+func (r *AttachmentRepository) DeleteByID(ctx context.Context, id int64) (*Attachment, *errors.Type) {
+	q := `DELETE FROM attachments WHERE id = $1 RETURNING ` + attachmentColumns + `;`
+
+	attachment, e := r.scan(r.db.QueryRow(ctx, q, id))
+	if e != nil {
+		if e == pgx.ErrNoRows {
+			return nil, nil
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	return attachment, nil
+}
+
+// DeleteIncomplete tries to delete the attachments whose uploads got started before the provided time and are still
+// in progress, and returns back the number of deleted ones.
+func (r *AttachmentRepository) DeleteIncomplete(ctx context.Context, before time.Time) (int64, *errors.Type) {
+	q := `DELETE FROM attachments WHERE completed_at IS NULL AND created_at < $1;`
+
+	tag, e := r.db.Exec(ctx, q, utc(&before))
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return 0, et
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// ReleaseBlob tries to delete one of the contents no attachment refers to anymore and reports whether there was any.
+// The content stays locked while release deletes it from the storage it is stored in under its key, so it is not
+// referenced meanwhile, e.g. by an upload of the same file.
+func (r *AttachmentRepository) ReleaseBlob(ctx context.Context, release func(ctx context.Context, key string) error) (
+	bool, *errors.Type) {
+
+	tx, e := r.db.Begin(ctx)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return false, et
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	q := `SELECT hash FROM attachment_blobs WHERE reference_count = 0 LIMIT 1 FOR UPDATE SKIP LOCKED;`
+
+	var hash string
+	if e := tx.QueryRow(ctx, q).Scan(&hash); e != nil {
+		if e == pgx.ErrNoRows {
+			return false, nil
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return false, et
+	}
+
+	if e := release(ctx, blobKey(hash)); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": could not release content ", hash, ": ", e.Error())
+		return false, et
+	}
+
+	if _, e := tx.Exec(ctx, `DELETE FROM attachment_blobs WHERE hash = $1;`, hash); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return false, et
+	}
+
+	if e := tx.Commit(ctx); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return false, et
+	}
+
+	return true, nil
+}
+
+func (r *AttachmentRepository) scan(row pgx.Row) (*Attachment, error) {
+	attachment := &Attachment{}
+
+	e := row.Scan(&attachment.ID, &attachment.TicketID, &attachment.CommentID, &attachment.Owner, &attachment.Name,
+		&attachment.ContentType, &attachment.Size, &attachment.Storage, &attachment.ContentHash, &attachment.CompletedAt,
+		&attachment.CreatedAt, &attachment.ModifiedAt)
+	if e != nil {
+		return nil, e
+	}
+
+	return attachment, nil
+}
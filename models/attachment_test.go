@@ -0,0 +1,396 @@
+package models_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"time"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Attachment", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var ticketRepository *models.TicketRepository
+	var commentRepository *models.CommentRepository
+	var repository *models.AttachmentRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			ticketRepository = models.NewTicketRepository(zap.S(), db)
+			commentRepository = models.NewCommentRepository(zap.S(), db)
+			repository = models.NewAttachmentRepository(zap.S(), db)
+		}
+
+		ticket := models.Ticket{
+			Issuer:          "Microservice-A",
+			Owner:           "user@example.com",
+			Subject:         "Technical Problem",
+			Content:         "Hello, i have some issues with REST API Docs!",
+			Metadata:        `{"ip":"192.168.1.1"}`,
+			ImportanceLevel: models.TicketImportanceLevelMedium,
+		}
+
+		_, et := ticketRepository.Insert(context.Background(), ticket)
+		Ω(et).Should(BeNil())
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	Describe("AttachmentRepository", func() {
+		emptyHash := fmt.Sprintf("%x", sha256.Sum256(nil))
+		helloHash := fmt.Sprintf("%x", sha256.Sum256([]byte("Hello, world!")))
+
+		attachment := models.Attachment{
+			TicketID:    1,
+			Owner:       "user@example.com",
+			Name:        "screenshot.png",
+			ContentType: "image/png",
+		}
+
+		Context("When Insert called", func() {
+			It("Should insert an attachment without any content", func() {
+				id, e := repository.Insert(context.Background(), attachment)
+				Ω(e).Should(BeNil())
+
+				loaded, e := repository.LoadByID(context.Background(), id)
+				Ω(e).Should(BeNil())
+				Ω(loaded.Name).Should(Equal("screenshot.png"))
+				Ω(loaded.Size).Should(BeZero())
+				Ω(loaded.Storage).Should(Equal(models.AttachmentStorageDatabase))
+				Ω(loaded.CompletedAt).Should(BeNil())
+			})
+
+			It("Should return error when ticket does not exists", func() {
+				notExisting := attachment
+				notExisting.TicketID = 2
+
+				_, e := repository.Insert(context.Background(), notExisting)
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusPreconditionFailed))
+				Ω(e.Errors[0].Code).Should(Equal("ticket.not_exists"))
+			})
+
+			It("Should return error when comment is not a comment of the ticket", func() {
+				onComment := attachment
+				onComment.CommentID = 1
+
+				_, e := repository.Insert(context.Background(), onComment)
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusPreconditionFailed))
+				Ω(e.Errors[0].Code).Should(Equal("comment.not_exists"))
+			})
+		})
+
+		Context("When Append called", func() {
+			It("Should append chunks and read them back across their boundaries", func() {
+				id, e := repository.Insert(context.Background(), attachment)
+				Ω(e).Should(BeNil())
+
+				_, e = repository.Append(context.Background(), id, 0, []byte("Hello, "), 100)
+				Ω(e).Should(BeNil())
+
+				appended, e := repository.Append(context.Background(), id, 7, []byte("world!"), 100)
+				Ω(e).Should(BeNil())
+				Ω(appended.Size).Should(Equal(int64(13)))
+
+				content, e := repository.Read(context.Background(), id, 5, 4)
+				Ω(e).Should(BeNil())
+				Ω(string(content)).Should(Equal(", wo"))
+
+				content, e = repository.Read(context.Background(), id, 0, 100)
+				Ω(e).Should(BeNil())
+				Ω(string(content)).Should(Equal("Hello, world!"))
+			})
+
+			It("Should return error when offset is not where the content ends", func() {
+				id, e := repository.Insert(context.Background(), attachment)
+				Ω(e).Should(BeNil())
+
+				_, e = repository.Append(context.Background(), id, 0, []byte("Hello, "), 100)
+				Ω(e).Should(BeNil())
+
+				_, e = repository.Append(context.Background(), id, 0, []byte("Hello, "), 100)
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusPreconditionFailed))
+				Ω(e.Errors[0].Code).Should(Equal("offset.not_valid"))
+			})
+
+			It("Should return error when content outgrows max size", func() {
+				id, e := repository.Insert(context.Background(), attachment)
+				Ω(e).Should(BeNil())
+
+				_, e = repository.Append(context.Background(), id, 0, []byte("Hello, world!"), 10)
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusBadRequest))
+				Ω(e.Errors[0].Code).Should(Equal("attachment.too_large"))
+			})
+
+			It("Should return error when upload is completed", func() {
+				id, e := repository.Insert(context.Background(), attachment)
+				Ω(e).Should(BeNil())
+
+				_, _, e = repository.Reference(context.Background(), id, emptyHash, models.AttachmentStorageDatabase)
+				Ω(e).Should(BeNil())
+
+				e = repository.Complete(context.Background(), id)
+				Ω(e).Should(BeNil())
+
+				_, e = repository.Append(context.Background(), id, 0, []byte("Hello, "), 100)
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("attachment.already_completed"))
+			})
+		})
+
+		Context("When Complete called", func() {
+			It("Should list completed attachments only", func() {
+				id, e := repository.Insert(context.Background(), attachment)
+				Ω(e).Should(BeNil())
+
+				_, e = repository.Insert(context.Background(), attachment)
+				Ω(e).Should(BeNil())
+
+				_, _, e = repository.Reference(context.Background(), id, emptyHash, models.AttachmentStorageDatabase)
+				Ω(e).Should(BeNil())
+
+				e = repository.Complete(context.Background(), id)
+				Ω(e).Should(BeNil())
+
+				attachments, hasMore, e := repository.LoadByTicketID(context.Background(), 1, nil, 0)
+				Ω(e).Should(BeNil())
+				Ω(hasMore).Should(BeFalse())
+				Ω(attachments).Should(HaveLen(1))
+				Ω(attachments[0].ID).Should(Equal(id))
+				Ω(attachments[0].CompletedAt).ShouldNot(BeNil())
+			})
+
+			It("Should drop chunks of contents stored elsewhere", func() {
+				id, e := repository.Insert(context.Background(), attachment)
+				Ω(e).Should(BeNil())
+
+				_, e = repository.Append(context.Background(), id, 0, []byte("Hello, world!"), 100)
+				Ω(e).Should(BeNil())
+
+				storage, fresh, e := repository.Reference(context.Background(), id, helloHash,
+					models.AttachmentStorageS3)
+				Ω(e).Should(BeNil())
+				Ω(storage).Should(Equal(models.AttachmentStorageS3))
+				Ω(fresh).Should(BeTrue())
+
+				e = repository.Complete(context.Background(), id)
+				Ω(e).Should(BeNil())
+
+				content, e := repository.Read(context.Background(), id, 0, 100)
+				Ω(e).Should(BeNil())
+				Ω(content).Should(BeEmpty())
+
+				completed, e := repository.LoadByID(context.Background(), id)
+				Ω(e).Should(BeNil())
+				Ω(completed.Key()).Should(Equal("attachments/sha256/" + helloHash))
+			})
+		})
+
+		Context("When Reference called with identical contents", func() {
+			It("Should store the content once and delete it along with its last attachment", func() {
+				first, e := repository.Insert(context.Background(), attachment)
+				Ω(e).Should(BeNil())
+
+				second, e := repository.Insert(context.Background(), attachment)
+				Ω(e).Should(BeNil())
+
+				for _, id := range []int64{first, second} {
+					_, e = repository.Append(context.Background(), id, 0, []byte("Hello, world!"), 100)
+					Ω(e).Should(BeNil())
+				}
+
+				_, fresh, e := repository.Reference(context.Background(), first, helloHash,
+					models.AttachmentStorageDatabase)
+				Ω(e).Should(BeNil())
+				Ω(fresh).Should(BeTrue())
+
+				storage, fresh, e := repository.Reference(context.Background(), second, helloHash,
+					models.AttachmentStorageS3)
+				Ω(e).Should(BeNil())
+				Ω(storage).Should(Equal(models.AttachmentStorageDatabase))
+				Ω(fresh).Should(BeFalse())
+
+				_, _, e = repository.Reference(context.Background(), second, helloHash, models.AttachmentStorageDatabase)
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("attachment.already_completed"))
+
+				Ω(repository.Complete(context.Background(), first)).Should(BeNil())
+				Ω(repository.Complete(context.Background(), second)).Should(BeNil())
+
+				_, e = repository.DeleteByID(context.Background(), first)
+				Ω(e).Should(BeNil())
+
+				content, e := repository.ReadBlob(context.Background(), helloHash, 7, 100)
+				Ω(e).Should(BeNil())
+				Ω(string(content)).Should(Equal("world!"))
+
+				_, e = repository.DeleteByID(context.Background(), second)
+				Ω(e).Should(BeNil())
+
+				content, e = repository.ReadBlob(context.Background(), helloHash, 0, 100)
+				Ω(e).Should(BeNil())
+				Ω(content).Should(BeEmpty())
+			})
+		})
+
+		Context("When ReleaseBlob called", func() {
+			It("Should release the contents stored elsewhere once no attachment refers to them", func() {
+				id, e := repository.Insert(context.Background(), attachment)
+				Ω(e).Should(BeNil())
+
+				_, e = repository.Append(context.Background(), id, 0, []byte("Hello, world!"), 100)
+				Ω(e).Should(BeNil())
+
+				_, _, e = repository.Reference(context.Background(), id, helloHash, models.AttachmentStorageS3)
+				Ω(e).Should(BeNil())
+
+				released := make([]string, 0)
+				release := func(ctx context.Context, key string) error {
+					released = append(released, key)
+					return nil
+				}
+
+				ok, e := repository.ReleaseBlob(context.Background(), release)
+				Ω(e).Should(BeNil())
+				Ω(ok).Should(BeFalse())
+
+				_, e = repository.DeleteByID(context.Background(), id)
+				Ω(e).Should(BeNil())
+
+				ok, e = repository.ReleaseBlob(context.Background(), release)
+				Ω(e).Should(BeNil())
+				Ω(ok).Should(BeTrue())
+				Ω(released).Should(Equal([]string{"attachments/sha256/" + helloHash}))
+
+				ok, e = repository.ReleaseBlob(context.Background(), release)
+				Ω(e).Should(BeNil())
+				Ω(ok).Should(BeFalse())
+			})
+		})
+
+		Context("When a comment referring to attachments gets inserted", func() {
+			It("Should attach them to the comment only when all of them can be referred to", func() {
+				completed, e := repository.Insert(context.Background(), attachment)
+				Ω(e).Should(BeNil())
+
+				_, _, e = repository.Reference(context.Background(), completed, emptyHash,
+					models.AttachmentStorageDatabase)
+				Ω(e).Should(BeNil())
+				Ω(repository.Complete(context.Background(), completed)).Should(BeNil())
+
+				incomplete, e := repository.Insert(context.Background(), attachment)
+				Ω(e).Should(BeNil())
+
+				attachments, e := repository.LoadByIDs(context.Background(), 1, []int64{completed, incomplete, 100})
+				Ω(e).Should(BeNil())
+				Ω(attachments).Should(HaveLen(2))
+
+				comment := models.Comment{
+					TicketID:      1,
+					Owner:         "user@example.com",
+					Content:       "See the screenshots.",
+					Metadata:      `{}`,
+					AttachmentIDs: []int64{completed, incomplete},
+				}
+
+				_, e = commentRepository.Insert(context.Background(), comment)
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("attachmentIDs.not_referable"))
+
+				comment.AttachmentIDs = []int64{completed}
+				id, e := commentRepository.Insert(context.Background(), comment)
+				Ω(e).Should(BeNil())
+
+				attached, e := repository.LoadByID(context.Background(), completed)
+				Ω(e).Should(BeNil())
+				Ω(attached.CommentID).Should(Equal(id))
+			})
+		})
+
+		Context("When DeleteIncomplete called", func() {
+			It("Should delete abandoned uploads only", func() {
+				id, e := repository.Insert(context.Background(), attachment)
+				Ω(e).Should(BeNil())
+
+				abandoned, e := repository.Insert(context.Background(), attachment)
+				Ω(e).Should(BeNil())
+
+				_, _, e = repository.Reference(context.Background(), id, emptyHash, models.AttachmentStorageDatabase)
+				Ω(e).Should(BeNil())
+
+				e = repository.Complete(context.Background(), id)
+				Ω(e).Should(BeNil())
+
+				deleted, e := repository.DeleteIncomplete(context.Background(), time.Now().Add(time.Minute))
+				Ω(e).Should(BeNil())
+				Ω(deleted).Should(Equal(int64(1)))
+
+				_, e = repository.LoadByID(context.Background(), abandoned)
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusNotFound))
+			})
+		})
+
+		Context("When the ticket or the comment gets deleted", func() {
+			It("Should delete their attachments as well", func() {
+				comment := models.Comment{
+					TicketID: 1,
+					Owner:    "user@example.com",
+					Content:  "Hello, we are working on these.",
+					Metadata: `{"ip":"192.168.1.1"}`,
+				}
+
+				_, e := commentRepository.Insert(context.Background(), comment)
+				Ω(e).Should(BeNil())
+
+				onComment := attachment
+				onComment.CommentID = 1
+
+				id, e := repository.Insert(context.Background(), onComment)
+				Ω(e).Should(BeNil())
+
+				_, e = repository.Append(context.Background(), id, 0, []byte("Hello, world!"), 100)
+				Ω(e).Should(BeNil())
+
+				_, e = ticketRepository.DeleteByID(context.Background(), 1)
+				Ω(e).Should(BeNil())
+
+				_, e = repository.LoadByID(context.Background(), id)
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusNotFound))
+
+				content, e := repository.Read(context.Background(), id, 0, 100)
+				Ω(e).Should(BeNil())
+				Ω(content).Should(BeEmpty())
+			})
+		})
+	})
+})
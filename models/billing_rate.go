@@ -0,0 +1,78 @@
+package models
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// BillingRate is the entity model of billing_rates table. It holds the hourly rate the billable time of an issuer is
+// invoiced at.
+type BillingRate struct {
+	Issuer string
+	// HourlyRate is in minor units of the currency, e.g. cents.
+	HourlyRate int64
+	// Currency is an ISO 4217 code.
+	Currency string
+}
+
+// BillingRateRepository is the repository implementation of BillingRate model.
+type BillingRateRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewBillingRateRepository returns back a newly created and ready to use BillingRateRepository.
+func NewBillingRateRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *BillingRateRepository {
+	return &BillingRateRepository{logger: logger, db: db}
+}
+
+// Save tries to insert the billing rate of an issuer or update it if it already exists.
+func (r *BillingRateRepository) Save(ctx context.Context, rate BillingRate) *errors.Type {
+	q := `INSERT INTO billing_rates (issuer, hourly_rate, currency, created_at, modified_at)
+			VALUES ($1, $2, $3, NOW(), NOW()) ON CONFLICT (issuer) DO UPDATE SET hourly_rate = EXCLUDED.hourly_rate,
+			currency = EXCLUDED.currency, modified_at = NOW();`
+
+	if _, e := r.db.Exec(ctx, q, rate.Issuer, rate.HourlyRate, rate.Currency); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// LoadByIssuer tries to load the billing rate of an issuer.
+func (r *BillingRateRepository) LoadByIssuer(ctx context.Context, issuer string) (*BillingRate, *errors.Type) {
+	q := `SELECT issuer, hourly_rate, currency FROM billing_rates WHERE issuer = $1;`
+
+	rate := &BillingRate{}
+	if e := r.db.QueryRow(ctx, q, issuer).Scan(&rate.Issuer, &rate.HourlyRate, &rate.Currency); e != nil {
+		if e == pgx.ErrNoRows {
+			return nil, errors.NotFound("billing_rate.not_found", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	return rate, nil
+}
+
+// DeleteByIssuer tries to delete the billing rate of an issuer. Its billable time is exported without an amount
+// afterwards.
+func (r *BillingRateRepository) DeleteByIssuer(ctx context.Context, issuer string) *errors.Type {
+	q := `DELETE FROM billing_rates WHERE issuer = $1;`
+
+	if _, e := r.db.Exec(ctx, q, issuer); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package models_test
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("BillingRate", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.BillingRateRepository
+	var ticketRepository *models.TicketRepository
+	var workLogRepository *models.WorkLogRepository
+	var reportRepository *models.ReportRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewBillingRateRepository(zap.S(), db)
+			ticketRepository = models.NewTicketRepository(zap.S(), db)
+			workLogRepository = models.NewWorkLogRepository(zap.S(), db)
+			reportRepository = models.NewReportRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	Describe("BillingRateRepository", func() {
+		Context("When Save, LoadByIssuer and DeleteByIssuer called", func() {
+			It("Should store, update and then delete the rate of an issuer", func() {
+				rate := models.BillingRate{Issuer: "Microservice-A", HourlyRate: 5000, Currency: "USD"}
+				Ω(repository.Save(context.Background(), rate)).Should(BeNil())
+
+				rate.HourlyRate = 6000
+				Ω(repository.Save(context.Background(), rate)).Should(BeNil())
+
+				loaded, e := repository.LoadByIssuer(context.Background(), "Microservice-A")
+				Ω(e).Should(BeNil())
+				Ω(*loaded).Should(Equal(rate))
+
+				Ω(repository.DeleteByIssuer(context.Background(), "Microservice-A")).Should(BeNil())
+
+				_, e = repository.LoadByIssuer(context.Background(), "Microservice-A")
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusNotFound))
+			})
+		})
+	})
+
+	Describe("ReportRepository", func() {
+		Context("When Billing called", func() {
+			It("Should sum the time logged on billable tickets per issuer", func() {
+				ticket := models.Ticket{Issuer: "Microservice-A", Owner: "user-1", Subject: "Subject",
+					Content: "Content", ImportanceLevel: models.TicketImportanceLevelHigh, Billable: true}
+				billableID, e := ticketRepository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				ticket.Billable = false
+				id, e := ticketRepository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				for _, ticketID := range []int64{billableID, id} {
+					_, e = workLogRepository.Insert(context.Background(), models.WorkLog{TicketID: ticketID,
+						Agent: "agent-1", Duration: 90 * time.Minute})
+					Ω(e).Should(BeNil())
+				}
+
+				rate := models.BillingRate{Issuer: "Microservice-A", HourlyRate: 5000, Currency: "USD"}
+				Ω(repository.Save(context.Background(), rate)).Should(BeNil())
+
+				from := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339Nano)
+				to := time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano)
+
+				rows, e := reportRepository.Billing(context.Background(), from, to)
+				Ω(e).Should(BeNil())
+				Ω(rows).Should(HaveLen(1))
+				Ω(rows[0].BillableTickets).Should(Equal(int64(1)))
+				Ω(rows[0].BillableTime).Should(Equal(90 * time.Minute))
+				Ω(rows[0].Currency).Should(Equal("USD"))
+				Ω(rows[0].Amount()).Should(Equal(int64(7500)))
+
+				Ω(ticketRepository.SetBillable(context.Background(), billableID, false)).Should(BeNil())
+
+				rows, e = reportRepository.Billing(context.Background(), from, to)
+				Ω(e).Should(BeNil())
+				Ω(rows).Should(BeEmpty())
+			})
+		})
+	})
+})
@@ -3,10 +3,13 @@ package models
 import (
 	"context"
 	"database/sql"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/db/filters"
 	"github.com/jibitters/kiosk/errors"
 	"go.uber.org/zap"
 )
@@ -19,6 +22,43 @@ type Comment struct {
 	Owner    string
 	Content  string
 	Metadata string
+	// AuthorType tells humans apart from automations, it is resolved from the owner of the ticket on insertion when
+	// empty.
+	AuthorType CommentAuthorType
+	// Source is the channel the comment arrived through, it is empty for comments kiosk adds on its own.
+	Source CommentSource
+	// Revision is the revision of the content, starting from 1 and incremented on each edit of it. EditedAt is when
+	// the content got edited last or nil if never.
+	Revision int
+	EditedAt *time.Time
+	// AttachmentIDs are the attachments the comment refers to on insertion, which get attached to it. They are not
+	// loaded along with the comment, its attachments are listed with the ones of its ticket.
+	AttachmentIDs []int64
+}
+
+// commentDefaultOrders orders comments when no order is given, the newest first.
+var commentDefaultOrders = []Order{{Field: "createdAt", Descending: true}}
+
+// Cursor returns back the position of the comment among comments ordered by orders, the newest first when empty.
+func (c *Comment) Cursor(orders []Order) Cursor {
+	keys := orderKeys(orders, commentDefaultOrders, CommentOrderColumns)
+
+	cursor := make(Cursor, 0, len(keys))
+	for _, key := range keys {
+		var value string
+		switch key.column {
+		case "id":
+			value = strconv.FormatInt(c.ID, 10)
+		case "created_at":
+			value = c.CreatedAt.UTC().Format(time.RFC3339Nano)
+		case "modified_at":
+			value = c.ModifiedAt.UTC().Format(time.RFC3339Nano)
+		}
+
+		cursor = append(cursor, &value)
+	}
+
+	return cursor
 }
 
 // CommentRepository is the repository implementation of Comment model.
@@ -32,35 +72,68 @@ func NewCommentRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *CommentR
 	return &CommentRepository{logger: logger, db: db}
 }
 
-// Insert tries to insert a comment into comments table.
-func (r *CommentRepository) Insert(ctx context.Context, comment Comment) *errors.Type {
-	q := `INSERT INTO comments (ticket_id, owner, content, metadata, created_at, modified_at) VALUES
-			($1, $2, $3, $4, NOW(), NOW());`
+// Insert tries to insert a comment into comments table and returns back its id. Comments without author type are
+// attributed to the customer when they are owned by the owner of the ticket, and to an agent otherwise. The comment is
+// only inserted along with all of its attachments, which must be completed attachments of its ticket not attached to
+// another comment.
+func (r *CommentRepository) Insert(ctx context.Context, comment Comment) (int64, *errors.Type) {
+	q := `INSERT INTO comments (ticket_id, owner, content, metadata, author_type, source, created_at, modified_at)
+			VALUES ($1, $2, $3, $4, COALESCE(NULLIF($5, ''),
+			(SELECT CASE WHEN owner = $2 THEN $7 ELSE $8 END FROM tickets WHERE id = $1), $8), NULLIF($6, ''),
+			NOW(), NOW()) RETURNING id;`
+
+	attachQ := `UPDATE attachments SET comment_id = $1, modified_at = NOW() WHERE id = ANY($2) AND ticket_id = $3
+			AND completed_at IS NOT NULL AND comment_id IS NULL;`
 
-	_, e := r.db.Exec(ctx, q, comment.TicketID, comment.Owner, comment.Content, comment.Metadata)
+	tx, e := r.db.Begin(ctx)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return 0, et
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var id int64
+	e = tx.QueryRow(ctx, q, comment.TicketID, comment.Owner, comment.Content, comment.Metadata,
+		comment.AuthorType, comment.Source, CommentAuthorTypeCustomer, CommentAuthorTypeAgent).Scan(&id)
 	if e != nil {
 		if strings.Contains(e.Error(), "comments_ticket_id_fkey") {
-			return errors.PreconditionFailed("ticket.not_exists", "")
+			return 0, errors.PreconditionFailed("ticket.not_exists", "")
 		}
 
 		et := errors.InternalServerError("unknown", "")
 		r.logger.Error(et.FingerPrint, ": ", e.Error())
-		return et
+		return 0, et
 	}
 
-	return nil
+	if len(comment.AttachmentIDs) > 0 {
+		tag, e := tx.Exec(ctx, attachQ, id, comment.AttachmentIDs, comment.TicketID)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return 0, et
+		}
+
+		if tag.RowsAffected() != int64(len(comment.AttachmentIDs)) {
+			return 0, errors.PreconditionFailed("attachmentIDs.not_referable", "")
+		}
+	}
+
+	if e := tx.Commit(ctx); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return 0, et
+	}
+
+	return id, nil
 }
 
 // LoadByID tries to load a comment from comments table.
 func (r *CommentRepository) LoadByID(ctx context.Context, id int64) (*Comment, *errors.Type) {
-	q := `SELECT id, ticket_id, owner, content, metadata, created_at, modified_at FROM comments WHERE id = $1;`
+	q := `SELECT id, ticket_id, owner, content, metadata, author_type, COALESCE(source, ''), revision, edited_at,
+			created_at, modified_at FROM comments WHERE id = $1;`
 
-	comment := &Comment{}
-	var metadata sql.NullString
-
-	row := r.db.QueryRow(ctx, q, id)
-	e := row.Scan(&comment.ID, &comment.TicketID, &comment.Owner, &comment.Content, &metadata, &comment.CreatedAt,
-		&comment.ModifiedAt)
+	comment, e := r.scan(r.db.QueryRow(ctx, q, id))
 	if e != nil {
 		if e == pgx.ErrNoRows {
 			return nil, errors.NotFound("comment.not_found", "")
@@ -71,6 +144,186 @@ func (r *CommentRepository) LoadByID(ctx context.Context, id int64) (*Comment, *
 		return nil, et
 	}
 
+	return comment, nil
+}
+
+// Filter tries to load up to limit comments of a ticket, or all of them when zero, ordered by orders or newest first
+// when empty. If authorTypes or sources are not empty only the comments of those author types or sources are loaded.
+// Comments start right after the comment at the cursor when one is given, as returned back by its Cursor. The second
+// returned value tells whether there are more comments.
+func (r *CommentRepository) Filter(ctx context.Context, ticketID int64, authorTypes []CommentAuthorType,
+	sources []CommentSource, orders []Order, after Cursor, limit int) ([]*Comment, bool, *errors.Type) {
+
+	types, channels := commentFilterArgs(authorTypes, sources)
+
+	// The parameters of the filter conditions are bound first, so they keep their numbers. One more comment is loaded
+	// to tell whether there are more, a NULL limit loads all of them.
+	b := filters.New("id", "created_at", "modified_at")
+	b.Bind(ticketID)
+	b.Bind(types)
+	b.Bind(channels)
+	limitPlaceholder := b.Bind(limit)
+	if after != nil {
+		b.WhereAfter(keysetAfter(after, orders, commentDefaultOrders, CommentOrderColumns)...)
+	}
+
+	conditions, args, e := b.Build()
+	if e != nil {
+		return nil, false, queryFailed(r.logger, e)
+	}
+
+	q := `SELECT id, ticket_id, owner, content, metadata, author_type, COALESCE(source, ''), revision, edited_at,
+			created_at, modified_at FROM comments WHERE` + commentFilterConditions + ` AND` + conditions +
+		orderClause(orders, commentDefaultOrders, CommentOrderColumns) + ` LIMIT NULLIF(` + limitPlaceholder +
+		`::INT, 0) + 1;`
+
+	rows, e := r.db.Query(ctx, q, args...)
+	if e != nil {
+		return nil, false, queryFailed(r.logger, e)
+	}
+	defer rows.Close()
+
+	comments := make([]*Comment, 0)
+	for rows.Next() {
+		comment, e := r.scan(rows)
+		if e != nil {
+			return nil, false, queryFailed(r.logger, e)
+		}
+
+		comments = append(comments, comment)
+	}
+
+	hasMore := limit > 0 && len(comments) > limit
+	if hasMore {
+		// Drop the extra one.
+		comments = comments[:limit]
+	}
+
+	return comments, hasMore, nil
+}
+
+// FilterAround tries to load the window comments Filter would return back right before and right after the comment
+// with the provided id, along with the comment itself, or around the newest comment when the id is zero, so long
+// threads can be opened at any of their comments. The second and third returned values tell whether there are more
+// comments before and after the window.
+func (r *CommentRepository) FilterAround(ctx context.Context, ticketID int64, authorTypes []CommentAuthorType,
+	sources []CommentSource, orders []Order, aroundID int64, window int) ([]*Comment, bool, bool, *errors.Type) {
+
+	// One more comment is loaded on each side of the window to tell whether there are more.
+	q := `WITH ordered AS (SELECT id, ticket_id, owner, content, metadata, author_type, source, revision, edited_at,
+			created_at, modified_at, row_number() OVER (` +
+		orderClause(orders, commentDefaultOrders, CommentOrderColumns) + `) AS position
+			FROM comments WHERE` + commentFilterConditions + `),
+			anchor AS (SELECT position FROM ordered WHERE id = $4 OR $4 = 0 ORDER BY created_at DESC, id DESC LIMIT 1)
+			SELECT o.id, o.ticket_id, o.owner, o.content, o.metadata, o.author_type, COALESCE(o.source, ''), o.revision,
+			o.edited_at, o.created_at, o.modified_at, o.position - anchor.position FROM ordered AS o, anchor
+			WHERE o.position BETWEEN anchor.position - $5 - 1 AND anchor.position + $5 + 1 ORDER BY o.position;`
+
+	types, channels := commentFilterArgs(authorTypes, sources)
+	rows, e := r.db.Query(ctx, q, ticketID, types, channels, aroundID, window)
+	if e != nil {
+		return nil, false, false, queryFailed(r.logger, e)
+	}
+	defer rows.Close()
+
+	comments := make([]*Comment, 0)
+	hasBefore, hasAfter, found := false, false, false
+	for rows.Next() {
+		comment := &Comment{}
+		var metadata sql.NullString
+		var offset int
+
+		e := rows.Scan(&comment.ID, &comment.TicketID, &comment.Owner, &comment.Content, &metadata,
+			&comment.AuthorType, &comment.Source, &comment.Revision, &comment.EditedAt, &comment.CreatedAt,
+			&comment.ModifiedAt, &offset)
+		if e != nil {
+			return nil, false, false, queryFailed(r.logger, e)
+		}
+
+		found = true
+		if offset < -window {
+			hasBefore = true
+			continue
+		}
+
+		if offset > window {
+			hasAfter = true
+			continue
+		}
+
+		if metadata.Valid {
+			comment.Metadata = metadata.String
+		}
+
+		comments = append(comments, comment)
+	}
+
+	if !found && aroundID != 0 {
+		return nil, false, false, errors.NotFound("comment.not_found", "")
+	}
+
+	return comments, hasBefore, hasAfter, nil
+}
+
+// FilterCount tries to count the comments Filter would return back, without loading them.
+func (r *CommentRepository) FilterCount(ctx context.Context, ticketID int64, authorTypes []CommentAuthorType,
+	sources []CommentSource) (int64, *errors.Type) {
+
+	types, channels := commentFilterArgs(authorTypes, sources)
+
+	var count int64
+	q := `SELECT count(*) FROM comments WHERE` + commentFilterConditions + `;`
+	if e := r.db.QueryRow(ctx, q, ticketID, types, channels).Scan(&count); e != nil {
+		return 0, queryFailed(r.logger, e)
+	}
+
+	return count, nil
+}
+
+// FilterExists tries to check whether Filter would return back any comment, stopping at the first match.
+func (r *CommentRepository) FilterExists(ctx context.Context, ticketID int64, authorTypes []CommentAuthorType,
+	sources []CommentSource) (bool, *errors.Type) {
+
+	types, channels := commentFilterArgs(authorTypes, sources)
+
+	var exists bool
+	q := `SELECT EXISTS (SELECT 1 FROM comments WHERE` + commentFilterConditions + `);`
+	if e := r.db.QueryRow(ctx, q, ticketID, types, channels).Scan(&exists); e != nil {
+		return false, queryFailed(r.logger, e)
+	}
+
+	return exists, nil
+}
+
+// commentFilterConditions are the conditions of the WHERE clause shared by Filter, FilterCount and FilterExists, their
+// arguments are the ticket id followed by the result of commentFilterArgs.
+const commentFilterConditions = ` ticket_id = $1 AND (cardinality($2::TEXT[]) = 0 OR author_type = ANY($2))
+			AND (cardinality($3::TEXT[]) = 0 OR source = ANY($3))`
+
+func commentFilterArgs(authorTypes []CommentAuthorType, sources []CommentSource) ([]string, []string) {
+	types := make([]string, 0, len(authorTypes))
+	for _, authorType := range authorTypes {
+		types = append(types, string(authorType))
+	}
+
+	channels := make([]string, 0, len(sources))
+	for _, source := range sources {
+		channels = append(channels, string(source))
+	}
+
+	return types, channels
+}
+
+func (r *CommentRepository) scan(row pgx.Row) (*Comment, error) {
+	comment := &Comment{}
+	var metadata sql.NullString
+
+	e := row.Scan(&comment.ID, &comment.TicketID, &comment.Owner, &comment.Content, &metadata, &comment.AuthorType,
+		&comment.Source, &comment.Revision, &comment.EditedAt, &comment.CreatedAt, &comment.ModifiedAt)
+	if e != nil {
+		return nil, e
+	}
+
 	if metadata.Valid {
 		comment.Metadata = metadata.String
 	}
@@ -78,31 +331,61 @@ func (r *CommentRepository) LoadByID(ctx context.Context, id int64) (*Comment, *
 	return comment, nil
 }
 
-// Update tries to update a comment record.
-func (r *CommentRepository) Update(ctx context.Context, comment *Comment) *errors.Type {
-	q := `UPDATE comments SET metadata = $1, modified_at = NOW() WHERE id = $2;`
+// Update tries to update a comment record. An empty content keeps the current one. When the content changes, its
+// previous revision gets recorded along with the editor. The modification time of comment is set to the one of the
+// record after the update.
+func (r *CommentRepository) Update(ctx context.Context, comment *Comment, editor string) *errors.Type {
+	q := `WITH previous AS (SELECT id, content, revision, content <> COALESCE(NULLIF($3, ''), content) AS edited
+			FROM comments WHERE id = $2 FOR UPDATE),
+			revision AS (INSERT INTO comment_revisions (comment_id, revision, content, editor, replaced_at)
+			SELECT id, revision, content, NULLIF($4, ''), NOW() FROM previous WHERE edited)
+			UPDATE comments AS c SET metadata = $1, content = COALESCE(NULLIF($3, ''), c.content),
+			revision = CASE WHEN previous.edited THEN c.revision + 1 ELSE c.revision END,
+			edited_at = CASE WHEN previous.edited THEN NOW() ELSE c.edited_at END
+			FROM previous
+			WHERE c.id = previous.id
+			RETURNING c.modified_at;`
 
-	command, e := r.db.Exec(ctx, q, comment.Metadata, comment.ID)
+	e := r.db.QueryRow(ctx, q, comment.Metadata, comment.ID, comment.Content, editor).Scan(&comment.ModifiedAt)
 	if e != nil {
+		if e == pgx.ErrNoRows {
+			return errors.NotFound("comment.not_found", "")
+		}
+
 		et := errors.InternalServerError("unknown", "")
 		r.logger.Error(et.FingerPrint, ": ", e.Error())
 		return et
 	}
 
-	if command.RowsAffected() == 0 {
-		et := errors.NotFound("comment.not_found", "")
-		return et
-	}
-
 	return nil
 }
 
-// DeleteByID tries to delete a comment from comments table.
+// DeleteByID tries to delete a comment, along with its revisions, from comments table.
 func (r *CommentRepository) DeleteByID(ctx context.Context, id int64) *errors.Type {
+	tx, e := r.db.Begin(ctx)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	revisionsQ := `DELETE FROM comment_revisions WHERE comment_id=$1;`
 	q := `DELETE FROM comments WHERE id=$1;`
 
-	_, e := r.db.Exec(ctx, q, id)
-	if e != nil {
+	if _, e := tx.Exec(ctx, revisionsQ, id); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	if _, e := tx.Exec(ctx, q, id); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	if e := tx.Commit(ctx); e != nil {
 		et := errors.InternalServerError("unknown", "")
 		r.logger.Error(et.FingerPrint, ": ", e.Error())
 		return et
@@ -110,3 +393,44 @@ func (r *CommentRepository) DeleteByID(ctx context.Context, id int64) *errors.Ty
 
 	return nil
 }
+
+// CommentAuthorType model.
+type CommentAuthorType string
+
+// Different comment author type instances.
+const (
+	CommentAuthorTypeAgent    CommentAuthorType = "AGENT"
+	CommentAuthorTypeCustomer CommentAuthorType = "CUSTOMER"
+	CommentAuthorTypeSystem   CommentAuthorType = "SYSTEM"
+	CommentAuthorTypeBot      CommentAuthorType = "BOT"
+)
+
+// IsValid reports whether the author type is one of the known author types.
+func (t CommentAuthorType) IsValid() bool {
+	switch t {
+	case CommentAuthorTypeAgent, CommentAuthorTypeCustomer, CommentAuthorTypeSystem, CommentAuthorTypeBot:
+		return true
+	}
+
+	return false
+}
+
+// CommentSource model.
+type CommentSource string
+
+// Different comment source instances.
+const (
+	CommentSourceAPI   CommentSource = "API"
+	CommentSourceEmail CommentSource = "EMAIL"
+	CommentSourceChat  CommentSource = "CHAT"
+)
+
+// IsValid reports whether the source is one of the known sources.
+func (s CommentSource) IsValid() bool {
+	switch s {
+	case CommentSourceAPI, CommentSourceEmail, CommentSourceChat:
+		return true
+	}
+
+	return false
+}
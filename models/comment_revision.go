@@ -0,0 +1,44 @@
+package models
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// CommentRevisionRepository is the repository implementation of the revisions of comment contents. Previous revisions
+// are recorded by CommentRepository.Update as comments get edited, the current one is the comment itself.
+type CommentRevisionRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewCommentRevisionRepository returns back a newly created and ready to use CommentRevisionRepository.
+func NewCommentRevisionRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *CommentRevisionRepository {
+	return &CommentRevisionRepository{logger: logger, db: db}
+}
+
+// LoadContent tries to load the content of a comment as it was at the provided revision, either a previous or the
+// current one.
+func (r *CommentRevisionRepository) LoadContent(ctx context.Context, commentID int64, revision int) (string,
+	*errors.Type) {
+
+	q := `SELECT content FROM comment_revisions WHERE comment_id = $1 AND revision = $2
+			UNION ALL SELECT content FROM comments WHERE id = $1 AND revision = $2;`
+
+	var content string
+	if e := r.db.QueryRow(ctx, q, commentID, revision).Scan(&content); e != nil {
+		if e == pgx.ErrNoRows {
+			return "", errors.NotFound("revision.not_found", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return "", et
+	}
+
+	return content, nil
+}
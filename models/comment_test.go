@@ -56,7 +56,7 @@ var _ = Describe("Comment", func() {
 					ImportanceLevel: models.TicketImportanceLevelMedium,
 				}
 
-				e := ticketRepository.Insert(context.Background(), ticket)
+				_, e := ticketRepository.Insert(context.Background(), ticket)
 				Ω(e).Should(BeNil())
 
 				comment := models.Comment{
@@ -66,7 +66,7 @@ var _ = Describe("Comment", func() {
 					Metadata: `{"ip":"192.168.1.1"}`,
 				}
 
-				e = repository.Insert(context.Background(), comment)
+				_, e = repository.Insert(context.Background(), comment)
 				Ω(e).Should(BeNil())
 			})
 
@@ -78,7 +78,7 @@ var _ = Describe("Comment", func() {
 					Metadata: `{"ip":"192.168.1.1"}`,
 				}
 
-				e := repository.Insert(context.Background(), comment)
+				_, e := repository.Insert(context.Background(), comment)
 				Ω(e).ShouldNot(BeNil())
 				Ω(e.FingerPrint).ShouldNot(BeEmpty())
 				Ω(e.Errors[0].Code).Should(Equal("ticket.not_exists"))
@@ -98,7 +98,7 @@ var _ = Describe("Comment", func() {
 					ImportanceLevel: models.TicketImportanceLevelMedium,
 				}
 
-				e := ticketRepository.Insert(context.Background(), ticket)
+				_, e := ticketRepository.Insert(context.Background(), ticket)
 				Ω(e).Should(BeNil())
 
 				comment := models.Comment{
@@ -108,7 +108,7 @@ var _ = Describe("Comment", func() {
 					Metadata: `{"ip":"192.168.1.11"}`,
 				}
 
-				e = repository.Insert(context.Background(), comment)
+				_, e = repository.Insert(context.Background(), comment)
 				Ω(e).Should(BeNil())
 
 				t, e := repository.LoadByID(context.Background(), 1)
@@ -143,7 +143,7 @@ var _ = Describe("Comment", func() {
 					ImportanceLevel: models.TicketImportanceLevelMedium,
 				}
 
-				e := ticketRepository.Insert(context.Background(), ticket)
+				_, e := ticketRepository.Insert(context.Background(), ticket)
 				Ω(e).Should(BeNil())
 
 				comment := models.Comment{
@@ -153,7 +153,7 @@ var _ = Describe("Comment", func() {
 					Metadata: `{"ip":"192.168.1.1"}`,
 				}
 
-				e = repository.Insert(context.Background(), comment)
+				_, e = repository.Insert(context.Background(), comment)
 				Ω(e).Should(BeNil())
 
 				c, e := repository.LoadByID(context.Background(), 1)
@@ -161,9 +161,58 @@ var _ = Describe("Comment", func() {
 
 				c.Metadata = `{"ip":"192.168.1.10"}`
 
-				e = repository.Update(context.Background(), c)
+				e = repository.Update(context.Background(), c, "")
 				Ω(e).Should(BeNil())
 				Ω(c.Metadata).Should(Equal(`{"ip":"192.168.1.10"}`))
+
+				c, e = repository.LoadByID(context.Background(), 1)
+				Ω(e).Should(BeNil())
+				Ω(c.Revision).Should(Equal(1))
+				Ω(c.EditedAt).Should(BeNil())
+			})
+
+			It("Should record the previous revision when the content changes", func() {
+				ticket := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user@example.com",
+					Subject:         "Technical Problem",
+					Content:         "Hello, i have some issues with REST API Docs!",
+					ImportanceLevel: models.TicketImportanceLevelMedium,
+				}
+
+				_, e := ticketRepository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				comment := models.Comment{TicketID: 1, Owner: "agent@kiosk.io", Content: "We are working on it."}
+				id, e := repository.Insert(context.Background(), comment)
+				Ω(e).Should(BeNil())
+
+				edited := &models.Comment{Model: models.Model{ID: id}, Content: "We have fixed it."}
+				e = repository.Update(context.Background(), edited, "agent@kiosk.io")
+				Ω(e).Should(BeNil())
+
+				c, e := repository.LoadByID(context.Background(), id)
+				Ω(e).Should(BeNil())
+				Ω(c.Content).Should(Equal("We have fixed it."))
+				Ω(c.Revision).Should(Equal(2))
+				Ω(c.EditedAt).ShouldNot(BeNil())
+
+				revisionRepository := models.NewCommentRevisionRepository(zap.S(), db)
+
+				content, e := revisionRepository.LoadContent(context.Background(), id, 1)
+				Ω(e).Should(BeNil())
+				Ω(content).Should(Equal("We are working on it."))
+
+				content, e = revisionRepository.LoadContent(context.Background(), id, 2)
+				Ω(e).Should(BeNil())
+				Ω(content).Should(Equal("We have fixed it."))
+
+				_, e = revisionRepository.LoadContent(context.Background(), id, 3)
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("revision.not_found"))
+
+				e = repository.DeleteByID(context.Background(), id)
+				Ω(e).Should(BeNil())
 			})
 
 			It("Should return error when comment does not exists", func() {
@@ -172,7 +221,7 @@ var _ = Describe("Comment", func() {
 					Metadata: `{"ip":"192.168.1.1"}`,
 				}
 
-				e := repository.Update(context.Background(), &comment)
+				e := repository.Update(context.Background(), &comment, "")
 				Ω(e).ShouldNot(BeNil())
 				Ω(e.FingerPrint).ShouldNot(BeEmpty())
 				Ω(e.Errors[0].Code).Should(Equal("comment.not_found"))
@@ -192,7 +241,7 @@ var _ = Describe("Comment", func() {
 					ImportanceLevel: models.TicketImportanceLevelMedium,
 				}
 
-				e := ticketRepository.Insert(context.Background(), ticket)
+				_, e := ticketRepository.Insert(context.Background(), ticket)
 				Ω(e).Should(BeNil())
 
 				comment := models.Comment{
@@ -202,7 +251,7 @@ var _ = Describe("Comment", func() {
 					Metadata: `{"ip":"192.168.1.11"}`,
 				}
 
-				e = repository.Insert(context.Background(), comment)
+				_, e = repository.Insert(context.Background(), comment)
 				Ω(e).Should(BeNil())
 
 				e = repository.DeleteByID(context.Background(), 1)
@@ -217,5 +266,123 @@ var _ = Describe("Comment", func() {
 				Ω(e.HTTPStatusCode).Should(Equal(http.StatusNotFound))
 			})
 		})
+
+		Context("When Filter called", func() {
+			It("Should filter comments of a ticket by author type and source", func() {
+				ticket := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user@example.com",
+					Subject:         "Technical Problem",
+					Content:         "Hello, i have some issues with REST API Docs!",
+					ImportanceLevel: models.TicketImportanceLevelMedium,
+				}
+
+				ticketID, e := ticketRepository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				comments := []models.Comment{
+					{TicketID: ticketID, Owner: "user@example.com", Content: "Any news?",
+						Source: models.CommentSourceEmail},
+					{TicketID: ticketID, Owner: "admin@example.com", Content: "We are on it.",
+						Source: models.CommentSourceAPI},
+					{TicketID: ticketID, Owner: "deploy-bot", Content: "Fix deployed.",
+						AuthorType: models.CommentAuthorTypeBot, Source: models.CommentSourceAPI},
+				}
+
+				for _, comment := range comments {
+					_, e = repository.Insert(context.Background(), comment)
+					Ω(e).Should(BeNil())
+				}
+
+				all, hasMore, e := repository.Filter(context.Background(), ticketID, nil, nil, nil, nil, 0)
+				Ω(e).Should(BeNil())
+				Ω(hasMore).Should(BeFalse())
+				Ω(all).Should(HaveLen(3))
+
+				orders := []models.Order{{Field: "createdAt"}}
+				page, hasMore, e := repository.Filter(context.Background(), ticketID, nil, nil, orders, nil, 2)
+				Ω(e).Should(BeNil())
+				Ω(hasMore).Should(BeTrue())
+				Ω(page).Should(HaveLen(2))
+
+				page, hasMore, e = repository.Filter(context.Background(), ticketID, nil, nil, orders,
+					page[1].Cursor(orders), 2)
+				Ω(e).Should(BeNil())
+				Ω(hasMore).Should(BeFalse())
+				Ω(page).Should(HaveLen(1))
+				Ω(page[0].Owner).Should(Equal("deploy-bot"))
+
+				humans, _, e := repository.Filter(context.Background(), ticketID,
+					[]models.CommentAuthorType{models.CommentAuthorTypeCustomer, models.CommentAuthorTypeAgent}, nil,
+					[]models.Order{{Field: "createdAt"}}, nil, 0)
+				Ω(e).Should(BeNil())
+				Ω(humans).Should(HaveLen(2))
+				Ω(humans[0].Owner).Should(Equal("user@example.com"))
+
+				emails, _, e := repository.Filter(context.Background(), ticketID, nil,
+					[]models.CommentSource{models.CommentSourceEmail}, nil, nil, 0)
+				Ω(e).Should(BeNil())
+				Ω(emails).Should(HaveLen(1))
+				Ω(emails[0].AuthorType).Should(Equal(models.CommentAuthorTypeCustomer))
+
+				count, e := repository.FilterCount(context.Background(), ticketID, nil,
+					[]models.CommentSource{models.CommentSourceAPI})
+				Ω(e).Should(BeNil())
+				Ω(count).Should(Equal(int64(2)))
+
+				exists, e := repository.FilterExists(context.Background(), ticketID,
+					[]models.CommentAuthorType{models.CommentAuthorTypeBot}, []models.CommentSource{models.CommentSourceEmail})
+				Ω(e).Should(BeNil())
+				Ω(exists).Should(BeFalse())
+			})
+		})
+
+		Context("When FilterAround called", func() {
+			It("Should load the window of comments around the comment or the newest one", func() {
+				ticketID, e := ticketRepository.Insert(context.Background(), models.Ticket{Issuer: "Microservice-A",
+					Owner: "user@example.com", Subject: "Subject", Content: "Content",
+					ImportanceLevel: models.TicketImportanceLevelMedium})
+				Ω(e).Should(BeNil())
+
+				ids := make([]int64, 0)
+				for i := 0; i < 5; i++ {
+					id, e := repository.Insert(context.Background(), models.Comment{TicketID: ticketID,
+						Owner: "user@example.com", Content: "Any news?"})
+					Ω(e).Should(BeNil())
+					ids = append(ids, id)
+				}
+
+				comments, hasMoreBefore, hasMoreAfter, e := repository.FilterAround(context.Background(), ticketID,
+					nil, nil, []models.Order{{Field: "id"}}, ids[2], 1)
+				Ω(e).Should(BeNil())
+				Ω(comments).Should(HaveLen(3))
+				Ω(comments[0].ID).Should(Equal(ids[1]))
+				Ω(comments[1].ID).Should(Equal(ids[2]))
+				Ω(comments[2].ID).Should(Equal(ids[3]))
+				Ω(hasMoreBefore).Should(BeTrue())
+				Ω(hasMoreAfter).Should(BeTrue())
+
+				comments, hasMoreBefore, hasMoreAfter, e = repository.FilterAround(context.Background(), ticketID,
+					nil, nil, []models.Order{{Field: "createdAt"}}, 0, 2)
+				Ω(e).Should(BeNil())
+				Ω(comments).Should(HaveLen(3))
+				Ω(comments[0].ID).Should(Equal(ids[2]))
+				Ω(comments[2].ID).Should(Equal(ids[4]))
+				Ω(hasMoreBefore).Should(BeTrue())
+				Ω(hasMoreAfter).Should(BeFalse())
+
+				comments, hasMoreBefore, _, e = repository.FilterAround(context.Background(), ticketID, nil, nil,
+					nil, 0, 10)
+				Ω(e).Should(BeNil())
+				Ω(comments).Should(HaveLen(5))
+				Ω(comments[0].ID).Should(Equal(ids[4]))
+				Ω(hasMoreBefore).Should(BeFalse())
+
+				_, _, _, e = repository.FilterAround(context.Background(), ticketID,
+					[]models.CommentAuthorType{models.CommentAuthorTypeBot}, nil, nil, ids[2], 1)
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("comment.not_found"))
+			})
+		})
 	})
 })
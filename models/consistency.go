@@ -0,0 +1,54 @@
+package models
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// ConsistencyRepository issues and checks consistency tokens. A token is the write ahead log position of the primary
+// right after a mutation, any replica that has replayed up to that position observes the mutation.
+type ConsistencyRepository struct {
+	logger  *zap.SugaredLogger
+	primary *pgxpool.Pool
+	replica *pgxpool.Pool
+}
+
+// NewConsistencyRepository returns back a newly created and ready to use ConsistencyRepository. Replica may be nil.
+func NewConsistencyRepository(logger *zap.SugaredLogger, primary, replica *pgxpool.Pool) *ConsistencyRepository {
+	return &ConsistencyRepository{logger: logger, primary: primary, replica: replica}
+}
+
+// Token tries to return back the current write ahead log position of the primary.
+func (r *ConsistencyRepository) Token(ctx context.Context) (string, *errors.Type) {
+	q := `SELECT pg_current_wal_lsn()::TEXT;`
+
+	var token string
+	if e := r.primary.QueryRow(ctx, q).Scan(&token); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return "", et
+	}
+
+	return token, nil
+}
+
+// ReplicaCaughtUp checks whether the replica has replayed the write ahead log up to the token. It is false when there
+// is no replica or it could not be asked.
+func (r *ConsistencyRepository) ReplicaCaughtUp(ctx context.Context, token string) bool {
+	if r.replica == nil {
+		return false
+	}
+
+	q := `SELECT COALESCE(pg_last_wal_replay_lsn() >= $1::PG_LSN, FALSE);`
+
+	var caughtUp bool
+	if e := r.replica.QueryRow(ctx, q, token).Scan(&caughtUp); e != nil {
+		r.logger.Warn("Could not check replica replay position: ", e.Error())
+		return false
+	}
+
+	return caughtUp
+}
@@ -0,0 +1,64 @@
+package models_test
+
+import (
+	"context"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Consistency", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	Describe("ConsistencyRepository", func() {
+		Context("When Token called", func() {
+			It("Should return back the write ahead log position of the primary", func() {
+				repository := models.NewConsistencyRepository(zap.S(), db, nil)
+
+				token, e := repository.Token(context.Background())
+				Ω(e).Should(BeNil())
+				Ω(token).Should(MatchRegexp(`^[0-9A-F]+/[0-9A-F]+$`))
+			})
+		})
+
+		Context("When ReplicaCaughtUp called", func() {
+			It("Should be false without a replica or when the replica is not replaying", func() {
+				repository := models.NewConsistencyRepository(zap.S(), db, nil)
+				Ω(repository.ReplicaCaughtUp(context.Background(), "0/0")).Should(BeFalse())
+
+				// A primary does not replay any write ahead log.
+				repository = models.NewConsistencyRepository(zap.S(), db, db)
+				Ω(repository.ReplicaCaughtUp(context.Background(), "0/0")).Should(BeFalse())
+			})
+		})
+	})
+})
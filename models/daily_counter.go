@@ -0,0 +1,91 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// DailyCounterDrift is a daily ticket counter that did not match the number of tickets it counts, i.e. the tickets
+// created in Day per issuer, importance level and status.
+type DailyCounterDrift struct {
+	Day             time.Time
+	Issuer          string
+	ImportanceLevel TicketImportanceLevel
+	Status          TicketStatus
+	Counted         int64
+	Actual          int64
+}
+
+// DailyCounterRepository is the repository implementation of daily_ticket_counters table, whose counters are kept up
+// to date by triggers on tickets and archived_tickets tables.
+type DailyCounterRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewDailyCounterRepository returns back a newly created and ready to use DailyCounterRepository.
+func NewDailyCounterRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *DailyCounterRepository {
+	return &DailyCounterRepository{logger: logger, db: db}
+}
+
+// Reconcile tries to verify the daily ticket counters against tickets, archived ones included, and fixes the drifted
+// ones, returning them back as they were. Drifts are measured within a single snapshot and added to the counters
+// rather than overwriting them, so the tickets written meanwhile are still counted.
+func (r *DailyCounterRepository) Reconcile(ctx context.Context) ([]*DailyCounterDrift, *errors.Type) {
+	q := `WITH actual AS (
+				SELECT created_at::DATE AS day, issuer, importance_level, status, COUNT(*) AS count
+				FROM (SELECT created_at, issuer, importance_level, status FROM tickets
+				UNION ALL
+				SELECT created_at, issuer, importance_level, status FROM archived_tickets) AS t
+				GROUP BY 1, 2, 3, 4
+			), drifts AS (
+				SELECT COALESCE(a.day, c.day) AS day, COALESCE(a.issuer, c.issuer) AS issuer,
+				COALESCE(a.importance_level, c.importance_level) AS importance_level,
+				COALESCE(a.status, c.status) AS status, COALESCE(c.count, 0) AS counted, COALESCE(a.count, 0) AS actual
+				FROM actual AS a FULL JOIN daily_ticket_counters AS c ON c.day = a.day AND c.issuer = a.issuer
+				AND c.importance_level = a.importance_level AND c.status = a.status
+				WHERE COALESCE(c.count, 0) <> COALESCE(a.count, 0)
+			), fixed AS (
+				INSERT INTO daily_ticket_counters AS c (day, issuer, importance_level, status, count)
+				SELECT day, issuer, importance_level, status, actual - counted FROM drifts
+				ORDER BY day, issuer, importance_level, status
+				ON CONFLICT (day, issuer, importance_level, status) DO UPDATE SET count = c.count + EXCLUDED.count
+			)
+			SELECT day, issuer, importance_level, status, counted, actual FROM drifts
+			ORDER BY day, issuer, importance_level, status;`
+
+	rows, e := r.db.Query(ctx, q)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	drifts := make([]*DailyCounterDrift, 0)
+	for rows.Next() {
+		drift := &DailyCounterDrift{}
+
+		e := rows.Scan(&drift.Day, &drift.Issuer, &drift.ImportanceLevel, &drift.Status, &drift.Counted,
+			&drift.Actual)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		drifts = append(drifts, drift)
+	}
+
+	if e := rows.Err(); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	return drifts, nil
+}
@@ -0,0 +1,292 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// maxDuplicateCandidates bounds the number of open tickets clustered at once.
+const maxDuplicateCandidates = 10000
+
+// DuplicateCluster is a group of open tickets of the same issuer and owner with similar subjects, likely reported for
+// the same issue, e.g. during an incident. Tickets are ordered oldest first, the first one being the one to merge the
+// rest into.
+type DuplicateCluster struct {
+	Issuer  string
+	Owner   string
+	Tickets []*Ticket
+}
+
+// DuplicateRepository is the repository implementation of DuplicateCluster model.
+type DuplicateRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewDuplicateRepository returns back a newly created and ready to use DuplicateRepository.
+func NewDuplicateRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *DuplicateRepository {
+	return &DuplicateRepository{logger: logger, db: db}
+}
+
+// LoadClusters tries to cluster the open tickets created after the provided time whose subjects are at least
+// minSimilarity similar, the largest clusters first. If issuer is not empty only the tickets of that issuer are
+// clustered. Merged tickets are left out.
+func (r *DuplicateRepository) LoadClusters(ctx context.Context, issuer string, createdAfter time.Time,
+	minSimilarity float64, limit int) ([]*DuplicateCluster, *errors.Type) {
+
+	q := `SELECT id, issuer, owner, subject, importance_level, status, created_at, modified_at FROM tickets
+			WHERE status NOT IN ($1, $2) AND merged_into IS NULL AND created_at > $3 AND ($4 = '' OR issuer = $4)
+			ORDER BY issuer, owner, created_at, id LIMIT $5;`
+
+	rows, e := r.db.Query(ctx, q, TicketStatusResolved, TicketStatusClosed, createdAfter, issuer,
+		maxDuplicateCandidates)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	tickets := make([]*Ticket, 0)
+	for rows.Next() {
+		ticket := &Ticket{}
+		e := rows.Scan(&ticket.ID, &ticket.Issuer, &ticket.Owner, &ticket.Subject, &ticket.ImportanceLevel,
+			&ticket.Status, &ticket.CreatedAt, &ticket.ModifiedAt)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		tickets = append(tickets, ticket)
+	}
+
+	clusters := clusterDuplicates(tickets, minSimilarity)
+	if len(clusters) > limit {
+		clusters = clusters[:limit]
+	}
+
+	return clusters, nil
+}
+
+// clusterDuplicates groups the tickets, ordered by issuer, owner and age, with the first ticket of a cluster of the
+// same issuer and owner whose subject is at least minSimilarity similar to theirs. Clusters of a single ticket are
+// left out.
+func clusterDuplicates(tickets []*Ticket, minSimilarity float64) []*DuplicateCluster {
+	clusters := make([]*DuplicateCluster, 0)
+
+	var candidates []*DuplicateCluster
+	var seeds []map[string]bool
+	for _, ticket := range tickets {
+		if len(candidates) > 0 && (candidates[0].Issuer != ticket.Issuer || candidates[0].Owner != ticket.Owner) {
+			clusters = appendDuplicateClusters(clusters, candidates)
+			candidates, seeds = nil, nil
+		}
+
+		tokens := subjectTokens(ticket.Subject)
+
+		clustered := false
+		for i, seed := range seeds {
+			if similarity(seed, tokens) >= minSimilarity {
+				candidates[i].Tickets = append(candidates[i].Tickets, ticket)
+				clustered = true
+				break
+			}
+		}
+
+		if !clustered {
+			candidates = append(candidates, &DuplicateCluster{Issuer: ticket.Issuer, Owner: ticket.Owner,
+				Tickets: []*Ticket{ticket}})
+			seeds = append(seeds, tokens)
+		}
+	}
+
+	clusters = appendDuplicateClusters(clusters, candidates)
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return len(clusters[i].Tickets) > len(clusters[j].Tickets)
+	})
+
+	return clusters
+}
+
+func appendDuplicateClusters(clusters, candidates []*DuplicateCluster) []*DuplicateCluster {
+	for _, candidate := range candidates {
+		if len(candidate.Tickets) > 1 {
+			clusters = append(clusters, candidate)
+		}
+	}
+
+	return clusters
+}
+
+// subjectTokens returns back the distinct lower cased words of the subject. Numbers are folded into a single token,
+// so subjects only differing in ids, amounts and the like are identical.
+func subjectTokens(subject string) map[string]bool {
+	tokens := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(subject), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if strings.IndexFunc(word, func(r rune) bool { return !unicode.IsDigit(r) }) == -1 {
+			word = "#"
+		}
+
+		tokens[word] = true
+	}
+
+	return tokens
+}
+
+// similarity returns back the Jaccard similarity of the token sets, from 0 for disjoint to 1 for identical ones.
+func similarity(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+
+	common := 0
+	for token := range a {
+		if b[token] {
+			common++
+		}
+	}
+
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// Merge tries to merge the duplicates into the target ticket in a single transaction. The subject and content of
+// each duplicate are appended to the target as a comment of its owner, dated when the duplicate got created, the
+// comments of the duplicates are moved to the target and the duplicates get closed. A note listing the merged tickets
+// is posted on the target on behalf of the agent. Tickets of other issuers, tickets already merged and tickets missing
+// any field closing them requires or needing an approval to get closed are not merged.
+// It returns back the duplicates as they were before, i.e. their id, issuer, owner, importance level and status,
+// along with the id of the note.
+func (r *DuplicateRepository) Merge(ctx context.Context, targetID int64, duplicateIDs []int64, agent string) (
+	[]*Ticket, int64, *errors.Type) {
+
+	tx, e := r.db.Begin(ctx)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, 0, et
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	q := `SELECT id, issuer, owner, importance_level, status, merged_into IS NOT NULL FROM tickets
+			WHERE id = $1 OR id = ANY($2) ORDER BY id FOR UPDATE;`
+
+	rows, e := tx.Query(ctx, q, targetID, duplicateIDs)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, 0, et
+	}
+
+	var target *Ticket
+	merged := false
+	previous := make([]*Ticket, 0, len(duplicateIDs))
+	for rows.Next() {
+		ticket := &Ticket{}
+		var m bool
+		if e := rows.Scan(&ticket.ID, &ticket.Issuer, &ticket.Owner, &ticket.ImportanceLevel, &ticket.Status,
+			&m); e != nil {
+
+			rows.Close()
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, 0, et
+		}
+
+		merged = merged || m
+		if ticket.ID == targetID {
+			target = ticket
+		} else {
+			previous = append(previous, ticket)
+		}
+	}
+	rows.Close()
+
+	if e := rows.Err(); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, 0, et
+	}
+
+	if target == nil || len(previous) != len(duplicateIDs) {
+		return nil, 0, errors.PreconditionFailed("ticket.not_found", "")
+	}
+
+	if merged {
+		return nil, 0, errors.PreconditionFailed("ticket.already_merged", "")
+	}
+
+	for _, ticket := range previous {
+		if ticket.Issuer != target.Issuer {
+			return nil, 0, errors.PreconditionFailed("ticket.issuer_mismatch", "")
+		}
+	}
+
+	// Duplicates get closed subject to the same checks TicketRepository.Update makes.
+	for _, ticket := range previous {
+		if e := checkTransition(ctx, r.logger, tx, ticket.ID, TicketStatusClosed, Resolution{}); e != nil {
+			return nil, 0, e
+		}
+	}
+
+	appendQ := `INSERT INTO comments (ticket_id, owner, content, metadata, author_type, created_at, modified_at)
+			SELECT $1, owner, subject || E'\n\n' || content, COALESCE(metadata #>> '{}', ''), $3, created_at, created_at
+			FROM tickets WHERE id = ANY($2);`
+	moveQ := `UPDATE comments SET ticket_id = $1 WHERE ticket_id = ANY($2);`
+	closeQ := `UPDATE tickets SET status = $3, merged_into = $1, waiting_since = NULL,
+			nudged_at = CASE WHEN status = $3 THEN nudged_at END WHERE id = ANY($2);`
+
+	if _, e := tx.Exec(ctx, moveQ, targetID, duplicateIDs); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, 0, et
+	}
+
+	if _, e := tx.Exec(ctx, appendQ, targetID, duplicateIDs, CommentAuthorTypeCustomer); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, 0, et
+	}
+
+	if _, e := tx.Exec(ctx, closeQ, targetID, duplicateIDs, TicketStatusClosed); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, 0, et
+	}
+
+	ids := make([]string, 0, len(previous))
+	for _, ticket := range previous {
+		ids = append(ids, fmt.Sprintf("#%d", ticket.ID))
+	}
+
+	noteQ := `INSERT INTO comments (ticket_id, owner, content, metadata, author_type, source, created_at, modified_at)
+			VALUES ($1, $2, $3, '', $4, $5, NOW(), NOW()) RETURNING id;`
+
+	var noteID int64
+	e = tx.QueryRow(ctx, noteQ, targetID, agent, "Merged duplicate tickets "+strings.Join(ids, ", ")+".",
+		CommentAuthorTypeAgent, CommentSourceAPI).Scan(&noteID)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, 0, et
+	}
+
+	if e := tx.Commit(ctx); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, 0, et
+	}
+
+	return previous, noteID, nil
+}
@@ -0,0 +1,175 @@
+package models_test
+
+import (
+	"context"
+	"time"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Duplicate", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.DuplicateRepository
+	var ticketRepository *models.TicketRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewDuplicateRepository(zap.S(), db)
+			ticketRepository = models.NewTicketRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	insert := func(issuer, owner, subject string, status models.TicketStatus) int64 {
+		id, e := ticketRepository.Insert(context.Background(), models.Ticket{Issuer: issuer, Owner: owner,
+			Subject: subject, Content: "Content of " + subject, ImportanceLevel: models.TicketImportanceLevelHigh,
+			Status: status})
+		Ω(e).Should(BeNil())
+		return id
+	}
+
+	Describe("DuplicateRepository", func() {
+		Context("When LoadClusters called", func() {
+			It("Should cluster the open tickets of the same owner with similar subjects, the largest first", func() {
+				first := insert("Microservice-A", "user1@example.com", "Payment 1001 failed", models.TicketStatusNew)
+				second := insert("Microservice-A", "user1@example.com", "payment 1002 FAILED!",
+					models.TicketStatusNew)
+				third := insert("Microservice-A", "user1@example.com", "Payment 1003 failed", models.TicketStatusNew)
+				insert("Microservice-A", "user1@example.com", "Payment 1004 failed", models.TicketStatusResolved)
+				insert("Microservice-A", "user1@example.com", "Cannot login", models.TicketStatusNew)
+				insert("Microservice-A", "user2@example.com", "Payment 1005 failed", models.TicketStatusNew)
+				other := insert("Microservice-B", "user3@example.com", "Cannot login", models.TicketStatusNew)
+				another := insert("Microservice-B", "user3@example.com", "Cannot login", models.TicketStatusNew)
+
+				clusters, e := repository.LoadClusters(context.Background(), "", time.Now().Add(-time.Hour), 0.8, 10)
+				Ω(e).Should(BeNil())
+				Ω(clusters).Should(HaveLen(2))
+				Ω(clusters[0].Owner).Should(Equal("user1@example.com"))
+				Ω(clusters[0].Tickets).Should(HaveLen(3))
+				Ω(clusters[0].Tickets[0].ID).Should(Equal(first))
+				Ω(clusters[0].Tickets[1].ID).Should(Equal(second))
+				Ω(clusters[0].Tickets[2].ID).Should(Equal(third))
+				Ω(clusters[1].Tickets[0].ID).Should(Equal(other))
+				Ω(clusters[1].Tickets[1].ID).Should(Equal(another))
+
+				clusters, e = repository.LoadClusters(context.Background(), "Microservice-B",
+					time.Now().Add(-time.Hour), 0.8, 10)
+				Ω(e).Should(BeNil())
+				Ω(clusters).Should(HaveLen(1))
+
+				clusters, e = repository.LoadClusters(context.Background(), "", time.Now().Add(time.Hour), 0.8, 10)
+				Ω(e).Should(BeNil())
+				Ω(clusters).Should(BeEmpty())
+			})
+		})
+
+		Context("When Merge called", func() {
+			It("Should move the duplicates and their comments to the target and close them", func() {
+				target := insert("Microservice-A", "user1@example.com", "Payment failed", models.TicketStatusNew)
+				duplicate := insert("Microservice-A", "user1@example.com", "Payment failed again",
+					models.TicketStatusNew)
+
+				commentRepository := models.NewCommentRepository(zap.S(), db)
+				_, e := commentRepository.Insert(context.Background(), models.Comment{TicketID: duplicate,
+					Owner: "user1@example.com", Content: "Any news?"})
+				Ω(e).Should(BeNil())
+
+				previous, noteID, e := repository.Merge(context.Background(), target, []int64{duplicate},
+					"agent@example.com")
+				Ω(e).Should(BeNil())
+				Ω(previous).Should(HaveLen(1))
+				Ω(previous[0].ID).Should(Equal(duplicate))
+				Ω(previous[0].Status).Should(Equal(models.TicketStatusNew))
+				Ω(noteID).ShouldNot(BeZero())
+
+				ticket, e := ticketRepository.LoadByID(context.Background(), target)
+				Ω(e).Should(BeNil())
+				Ω(ticket.Comments).Should(HaveLen(3))
+				Ω(ticket.Comments[0].ID).Should(Equal(noteID))
+				Ω(ticket.Comments[0].Content).Should(Equal("Merged duplicate tickets #2."))
+				Ω(ticket.Comments[0].Owner).Should(Equal("agent@example.com"))
+
+				merged, e := ticketRepository.LoadByID(context.Background(), duplicate)
+				Ω(e).Should(BeNil())
+				Ω(merged.Status).Should(Equal(models.TicketStatusClosed))
+				Ω(merged.MergedInto).Should(Equal(target))
+				Ω(merged.Comments).Should(BeEmpty())
+
+				_, _, e = repository.Merge(context.Background(), target, []int64{duplicate}, "agent@example.com")
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("ticket.already_merged"))
+			})
+
+			It("Should not merge duplicates missing a field or an approval closing them requires", func() {
+				target := insert("Microservice-A", "user1@example.com", "Payment failed", models.TicketStatusNew)
+				duplicate := insert("Microservice-A", "user1@example.com", "Payment failed again",
+					models.TicketStatusNew)
+
+				requirementRepository := models.NewTransitionRequirementRepository(zap.S(), db)
+				Ω(requirementRepository.Save(context.Background(), "Microservice-A", models.TicketStatusClosed,
+					[]models.RequiredField{models.RequiredFieldAssignee})).Should(BeNil())
+
+				_, _, e := repository.Merge(context.Background(), target, []int64{duplicate}, "agent@example.com")
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("assignee.is_required"))
+
+				_, e = ticketRepository.Assign(context.Background(), duplicate, "agent-a", false)
+				Ω(e).Should(BeNil())
+
+				approverRepository := models.NewApproverRepository(zap.S(), db)
+				Ω(approverRepository.Save(context.Background(), "Microservice-A", models.TicketStatusClosed,
+					[]string{"lead@example.com"})).Should(BeNil())
+
+				_, _, e = repository.Merge(context.Background(), target, []int64{duplicate}, "agent@example.com")
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("ticket.approval_required"))
+
+				ticket, e := ticketRepository.LoadByID(context.Background(), duplicate)
+				Ω(e).Should(BeNil())
+				Ω(ticket.Status).Should(Equal(models.TicketStatusNew))
+				Ω(ticket.MergedInto).Should(BeZero())
+			})
+
+			It("Should not merge tickets of other issuers or missing tickets", func() {
+				target := insert("Microservice-A", "user1@example.com", "Payment failed", models.TicketStatusNew)
+				other := insert("Microservice-B", "user1@example.com", "Payment failed", models.TicketStatusNew)
+
+				_, _, e := repository.Merge(context.Background(), target, []int64{other}, "agent@example.com")
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("ticket.issuer_mismatch"))
+
+				_, _, e = repository.Merge(context.Background(), target, []int64{other + 1}, "agent@example.com")
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("ticket.not_found"))
+
+				ticket, e := ticketRepository.LoadByID(context.Background(), other)
+				Ω(e).Should(BeNil())
+				Ω(ticket.Status).Should(Equal(models.TicketStatusNew))
+			})
+		})
+	})
+})
@@ -0,0 +1,14 @@
+package models
+
+// EnumConstraints maps the check constraints of the schema to the values of the enums they restrict their columns to.
+// The schema is verified against them on startup, so a value added to an enum must come along with a migration
+// altering its constraint.
+var EnumConstraints = map[string][]string{
+	"tickets_importance_level_check": {string(TicketImportanceLevelLow), string(TicketImportanceLevelMedium),
+		string(TicketImportanceLevelHigh), string(TicketImportanceLevelCritical)},
+	"tickets_status_check": {string(TicketStatusNew), string(TicketStatusReplied), string(TicketStatusResolved),
+		string(TicketStatusClosed), string(TicketStatusBlocked), string(TicketStatusWaitingOnCustomer)},
+	"comments_author_type_check": {string(CommentAuthorTypeAgent), string(CommentAuthorTypeCustomer),
+		string(CommentAuthorTypeSystem), string(CommentAuthorTypeBot)},
+	"comments_source_check": {string(CommentSourceAPI), string(CommentSourceEmail), string(CommentSourceChat)},
+}
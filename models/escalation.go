@@ -0,0 +1,222 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// EscalationLevel is the entity model of escalation_levels table. It is a step of the escalation chain of an issuer
+// and importance level, unresolved tickets open for longer than its delay get escalated to its recipient.
+type EscalationLevel struct {
+	Issuer          string
+	ImportanceLevel TicketImportanceLevel
+	// Level starts at 1, higher levels have longer delays.
+	Level     int
+	Delay     time.Duration
+	Recipient string
+}
+
+// DelayFor returns back the delay of the level for the tickets of a tier, scaled by the escalation factor of the
+// tier.
+func (l *EscalationLevel) DelayFor(tier CustomerTier) time.Duration {
+	return time.Duration(float64(l.Delay) * tier.EscalationFactor())
+}
+
+// EscalationLevelRepository is the repository implementation of EscalationLevel model.
+type EscalationLevelRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewEscalationLevelRepository returns back a newly created and ready to use EscalationLevelRepository.
+func NewEscalationLevelRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *EscalationLevelRepository {
+	return &EscalationLevelRepository{logger: logger, db: db}
+}
+
+// Save tries to replace the escalation chain of an issuer and importance level with the provided levels. Delays are
+// stored with a minute precision.
+func (r *EscalationLevelRepository) Save(ctx context.Context, issuer string, importanceLevel TicketImportanceLevel,
+	levels []EscalationLevel) *errors.Type {
+
+	begin := `BEGIN;`
+	deleteQ := `DELETE FROM escalation_levels WHERE issuer = $1 AND importance_level = $2;`
+	insertQ := `INSERT INTO escalation_levels (issuer, importance_level, level, delay_minutes, recipient, created_at)
+			VALUES ($1, $2, $3, $4, $5, NOW());`
+	commit := `COMMIT;`
+
+	batch := &pgx.Batch{}
+	batch.Queue(begin)
+	batch.Queue(deleteQ, issuer, importanceLevel)
+	for _, level := range levels {
+		batch.Queue(insertQ, issuer, importanceLevel, level.Level, int(level.Delay/time.Minute), level.Recipient)
+	}
+	batch.Queue(commit)
+
+	if e := r.db.SendBatch(ctx, batch).Close(); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// LoadByIssuer tries to load the escalation levels of an issuer, ordered by importance level and level.
+func (r *EscalationLevelRepository) LoadByIssuer(ctx context.Context, issuer string) ([]*EscalationLevel,
+	*errors.Type) {
+
+	q := `SELECT issuer, importance_level, level, delay_minutes, recipient FROM escalation_levels WHERE issuer = $1
+			ORDER BY importance_level, level;`
+
+	return r.load(ctx, q, issuer)
+}
+
+// LoadAll tries to load the escalation levels of all issuers.
+func (r *EscalationLevelRepository) LoadAll(ctx context.Context) ([]*EscalationLevel, *errors.Type) {
+	q := `SELECT issuer, importance_level, level, delay_minutes, recipient FROM escalation_levels
+			ORDER BY issuer, importance_level, level;`
+
+	return r.load(ctx, q)
+}
+
+// Delete tries to delete the escalation chain of an issuer and importance level. Escalations already executed are
+// kept.
+func (r *EscalationLevelRepository) Delete(ctx context.Context, issuer string,
+	importanceLevel TicketImportanceLevel) *errors.Type {
+
+	q := `DELETE FROM escalation_levels WHERE issuer = $1 AND importance_level = $2;`
+
+	if _, e := r.db.Exec(ctx, q, issuer, importanceLevel); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+func (r *EscalationLevelRepository) load(ctx context.Context, q string, args ...interface{}) ([]*EscalationLevel,
+	*errors.Type) {
+
+	rows, e := r.db.Query(ctx, q, args...)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	levels := make([]*EscalationLevel, 0)
+	for rows.Next() {
+		level := &EscalationLevel{}
+		var delay int
+
+		e := rows.Scan(&level.Issuer, &level.ImportanceLevel, &level.Level, &delay, &level.Recipient)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		level.Delay = time.Duration(delay) * time.Minute
+		levels = append(levels, level)
+	}
+
+	return levels, nil
+}
+
+// TicketEscalation is the entity model of ticket_escalations table. It is the record of a ticket escalated to a level.
+type TicketEscalation struct {
+	TicketID    int64
+	Level       int
+	Recipient   string
+	EscalatedAt time.Time
+}
+
+// TicketEscalationRepository is the repository implementation of TicketEscalation model.
+type TicketEscalationRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewTicketEscalationRepository returns back a newly created and ready to use TicketEscalationRepository.
+func NewTicketEscalationRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *TicketEscalationRepository {
+	return &TicketEscalationRepository{logger: logger, db: db}
+}
+
+// ClaimDue tries to record now as the escalation of the unresolved and not snoozed tickets of the tier that are due
+// for the level, i.e. were created at openSince or earlier, and returns back their ids. Tickets already escalated to
+// the level are not claimed again.
+func (r *TicketEscalationRepository) ClaimDue(ctx context.Context, level *EscalationLevel, tier CustomerTier,
+	openSince, now time.Time) ([]int64, *errors.Type) {
+
+	q := `INSERT INTO ticket_escalations (ticket_id, level, recipient, escalated_at)
+			SELECT id, $1, $2, $3 FROM tickets WHERE issuer = $4 AND importance_level = $5 AND COALESCE(tier, '') = $6
+			AND created_at <= $7 AND status NOT IN ($8, $9) AND (snoozed_until IS NULL OR snoozed_until <= $3)
+			ON CONFLICT (ticket_id, level) DO NOTHING RETURNING ticket_id;`
+
+	rows, e := r.db.Query(ctx, q, level.Level, level.Recipient, now.UTC(), level.Issuer, level.ImportanceLevel,
+		tier, openSince.UTC(), TicketStatusResolved, TicketStatusClosed)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if e := rows.Scan(&id); e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		ids = append(ids, id)
+	}
+
+	if e := rows.Err(); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	return ids, nil
+}
+
+// LoadByTicketID tries to load the escalations of a ticket, ordered by level.
+func (r *TicketEscalationRepository) LoadByTicketID(ctx context.Context, ticketID int64) ([]*TicketEscalation,
+	*errors.Type) {
+
+	q := `SELECT ticket_id, level, recipient, escalated_at FROM ticket_escalations WHERE ticket_id = $1
+			ORDER BY level;`
+
+	rows, e := r.db.Query(ctx, q, ticketID)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	escalations := make([]*TicketEscalation, 0)
+	for rows.Next() {
+		escalation := &TicketEscalation{}
+		e := rows.Scan(&escalation.TicketID, &escalation.Level, &escalation.Recipient, &escalation.EscalatedAt)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		escalations = append(escalations, escalation)
+	}
+
+	return escalations, nil
+}
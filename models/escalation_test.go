@@ -0,0 +1,126 @@
+package models_test
+
+import (
+	"context"
+	"time"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Escalation", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.EscalationLevelRepository
+	var ticketEscalationRepository *models.TicketEscalationRepository
+	var ticketRepository *models.TicketRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewEscalationLevelRepository(zap.S(), db)
+			ticketEscalationRepository = models.NewTicketEscalationRepository(zap.S(), db)
+			ticketRepository = models.NewTicketRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	levelsOf := func(recipients ...string) []models.EscalationLevel {
+		levels := make([]models.EscalationLevel, 0)
+		for i, recipient := range recipients {
+			levels = append(levels, models.EscalationLevel{Issuer: "Microservice-A",
+				ImportanceLevel: models.TicketImportanceLevelHigh, Level: i + 1, Delay: time.Duration(i+1) * time.Hour,
+				Recipient: recipient})
+		}
+
+		return levels
+	}
+
+	Describe("EscalationLevelRepository", func() {
+		Context("When Save, LoadByIssuer and Delete called", func() {
+			It("Should replace and then delete the chain of an issuer and importance level", func() {
+				e := repository.Save(context.Background(), "Microservice-A", models.TicketImportanceLevelHigh,
+					levelsOf("lead@example.com", "manager@example.com", "director@example.com"))
+				Ω(e).Should(BeNil())
+
+				e = repository.Save(context.Background(), "Microservice-A", models.TicketImportanceLevelHigh,
+					levelsOf("lead@example.com", "manager@example.com"))
+				Ω(e).Should(BeNil())
+
+				levels, e := repository.LoadByIssuer(context.Background(), "Microservice-A")
+				Ω(e).Should(BeNil())
+				Ω(levels).Should(HaveLen(2))
+				Ω(levels[1].Recipient).Should(Equal("manager@example.com"))
+				Ω(levels[1].Delay).Should(Equal(2 * time.Hour))
+
+				e = repository.Delete(context.Background(), "Microservice-A", models.TicketImportanceLevelHigh)
+				Ω(e).Should(BeNil())
+
+				levels, e = repository.LoadAll(context.Background())
+				Ω(e).Should(BeNil())
+				Ω(levels).Should(BeEmpty())
+			})
+		})
+	})
+
+	Describe("TicketEscalationRepository", func() {
+		Context("When ClaimDue called", func() {
+			It("Should escalate the due tickets of the tier once", func() {
+				ticket := models.Ticket{Issuer: "Microservice-A", Owner: "user-1", Subject: "Subject",
+					Content: "Content", ImportanceLevel: models.TicketImportanceLevelHigh,
+					Tier: models.CustomerTierGold}
+				id, e := ticketRepository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				level := &levelsOf("lead@example.com")[0]
+				now := time.Now()
+
+				ids, e := ticketEscalationRepository.ClaimDue(context.Background(), level, models.CustomerTierSilver,
+					now, now)
+				Ω(e).Should(BeNil())
+				Ω(ids).Should(BeEmpty())
+
+				ids, e = ticketEscalationRepository.ClaimDue(context.Background(), level, models.CustomerTierGold,
+					now.Add(-time.Hour), now)
+				Ω(e).Should(BeNil())
+				Ω(ids).Should(BeEmpty())
+
+				ids, e = ticketEscalationRepository.ClaimDue(context.Background(), level, models.CustomerTierGold,
+					now, now)
+				Ω(e).Should(BeNil())
+				Ω(ids).Should(Equal([]int64{id}))
+
+				ids, e = ticketEscalationRepository.ClaimDue(context.Background(), level, models.CustomerTierGold,
+					now, now)
+				Ω(e).Should(BeNil())
+				Ω(ids).Should(BeEmpty())
+
+				escalations, e := ticketEscalationRepository.LoadByTicketID(context.Background(), id)
+				Ω(e).Should(BeNil())
+				Ω(escalations).Should(HaveLen(1))
+				Ω(escalations[0].Recipient).Should(Equal("lead@example.com"))
+			})
+		})
+	})
+})
@@ -0,0 +1,365 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// Incident is the entity model of incidents table. It is an outage many tickets get reported for, the tickets attached
+// to it get resolved or commented on at once when it gets resolved. ResolvedAt is nil while the incident is open.
+type Incident struct {
+	Model
+
+	Title       string
+	Description string
+	ResolvedAt  *time.Time
+	TicketIDs   []int64
+}
+
+// IncidentRepository is the repository implementation of Incident model.
+type IncidentRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewIncidentRepository returns back a newly created and ready to use IncidentRepository.
+func NewIncidentRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *IncidentRepository {
+	return &IncidentRepository{logger: logger, db: db}
+}
+
+// Insert tries to insert an incident into incidents table and returns back its id.
+func (r *IncidentRepository) Insert(ctx context.Context, incident Incident) (int64, *errors.Type) {
+	q := `INSERT INTO incidents (title, description, created_at, modified_at) VALUES ($1, $2, NOW(), NOW())
+			RETURNING id;`
+
+	var id int64
+	if e := r.db.QueryRow(ctx, q, incident.Title, incident.Description).Scan(&id); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return 0, et
+	}
+
+	return id, nil
+}
+
+// LoadByID tries to load an incident along with the ids of its tickets, ordered by when they got attached.
+func (r *IncidentRepository) LoadByID(ctx context.Context, id int64) (*Incident, *errors.Type) {
+	q := `SELECT i.id, i.title, i.description, i.resolved_at, i.created_at, i.modified_at,
+			COALESCE(array_agg(t.ticket_id ORDER BY t.attached_at, t.ticket_id) FILTER (WHERE t.ticket_id IS NOT NULL),
+			'{}') FROM incidents AS i LEFT JOIN incident_tickets AS t ON t.incident_id = i.id WHERE i.id = $1
+			GROUP BY i.id;`
+
+	incidents, e := r.load(ctx, q, id)
+	if e != nil {
+		return nil, e
+	}
+
+	if len(incidents) == 0 {
+		return nil, errors.NotFound("incident.not_found", "")
+	}
+
+	return incidents[0], nil
+}
+
+// LoadAll tries to load the incidents along with the ids of their tickets, the latest first. Resolved incidents are
+// left out unless asked for.
+func (r *IncidentRepository) LoadAll(ctx context.Context, resolved bool) ([]*Incident, *errors.Type) {
+	q := `SELECT i.id, i.title, i.description, i.resolved_at, i.created_at, i.modified_at,
+			COALESCE(array_agg(t.ticket_id ORDER BY t.attached_at, t.ticket_id) FILTER (WHERE t.ticket_id IS NOT NULL),
+			'{}') FROM incidents AS i LEFT JOIN incident_tickets AS t ON t.incident_id = i.id
+			WHERE $1 OR i.resolved_at IS NULL GROUP BY i.id ORDER BY i.id DESC;`
+
+	return r.load(ctx, q, resolved)
+}
+
+func (r *IncidentRepository) load(ctx context.Context, q string, args ...interface{}) ([]*Incident, *errors.Type) {
+	rows, e := r.db.Query(ctx, q, args...)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	incidents := make([]*Incident, 0)
+	for rows.Next() {
+		incident := &Incident{}
+
+		e := rows.Scan(&incident.ID, &incident.Title, &incident.Description, &incident.ResolvedAt,
+			&incident.CreatedAt, &incident.ModifiedAt, &incident.TicketIDs)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		incidents = append(incidents, incident)
+	}
+
+	return incidents, nil
+}
+
+// Attach tries to attach tickets to an open incident. Tickets attached to another incident are not attached, the
+// ones already attached to this incident are left as they are.
+func (r *IncidentRepository) Attach(ctx context.Context, incidentID int64, ticketIDs []int64) *errors.Type {
+	tx, e := r.db.Begin(ctx)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if e := r.lockOpen(ctx, tx, incidentID); e != nil {
+		return e
+	}
+
+	q := `INSERT INTO incident_tickets (ticket_id, incident_id, attached_at) SELECT id, $1, NOW() FROM tickets
+			WHERE id = ANY($2) ON CONFLICT (ticket_id) DO UPDATE SET ticket_id = EXCLUDED.ticket_id
+			RETURNING incident_id;`
+
+	rows, e := tx.Query(ctx, q, incidentID, ticketIDs)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	attached, other := 0, false
+	for rows.Next() {
+		var id int64
+		if e := rows.Scan(&id); e != nil {
+			rows.Close()
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return et
+		}
+
+		attached++
+		other = other || id != incidentID
+	}
+	rows.Close()
+
+	if e := rows.Err(); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	if attached != len(ticketIDs) {
+		return errors.PreconditionFailed("ticket.not_found", "")
+	}
+
+	if other {
+		return errors.PreconditionFailed("ticket.attached_to_other_incident", "")
+	}
+
+	if e := tx.Commit(ctx); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// Detach tries to detach a ticket from an incident.
+func (r *IncidentRepository) Detach(ctx context.Context, incidentID, ticketID int64) *errors.Type {
+	q := `DELETE FROM incident_tickets WHERE incident_id = $1 AND ticket_id = $2;`
+
+	tag, e := r.db.Exec(ctx, q, incidentID, ticketID)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errors.NotFound("ticket.not_attached", "")
+	}
+
+	return nil
+}
+
+// LoadTickets tries to load the tickets attached to an incident, i.e. their id, issuer, owner, subject, importance
+// level and status, ordered by id.
+func (r *IncidentRepository) LoadTickets(ctx context.Context, incidentID int64) ([]*Ticket, *errors.Type) {
+	q := `SELECT t.id, t.issuer, t.owner, t.subject, t.importance_level, t.status FROM incident_tickets AS i
+			JOIN tickets AS t ON t.id = i.ticket_id WHERE i.incident_id = $1 ORDER BY t.id;`
+
+	rows, e := r.db.Query(ctx, q, incidentID)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	tickets := make([]*Ticket, 0)
+	for rows.Next() {
+		ticket := &Ticket{}
+		e := rows.Scan(&ticket.ID, &ticket.Issuer, &ticket.Owner, &ticket.Subject, &ticket.ImportanceLevel,
+			&ticket.Status)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		tickets = append(tickets, ticket)
+	}
+
+	return tickets, nil
+}
+
+// Resolve tries to resolve an open incident in a single transaction, along with posting the comments on its tickets on
+// behalf of the agent and resolving the tickets with the provided ids, capturing the resolution. Resolved and closed
+// tickets are not resolved again. Tickets missing any field resolving them requires, the posted comments counting, or
+// needing an approval to get resolved are skipped. It returns back the tickets it resolved as they were before, i.e.
+// their id, issuer, owner, importance level and status, along with the ids of the skipped ones. The ids of the
+// comments are set on them.
+func (r *IncidentRepository) Resolve(ctx context.Context, incidentID int64, comments []*Comment, ticketIDs []int64,
+	resolution Resolution) ([]*Ticket, []int64, *errors.Type) {
+
+	tx, e := r.db.Begin(ctx)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, nil, et
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if e := r.lockOpen(ctx, tx, incidentID); e != nil {
+		return nil, nil, e
+	}
+
+	commentQ := `INSERT INTO comments (ticket_id, owner, content, metadata, author_type, source, created_at,
+			modified_at) VALUES ($1, $2, $3, '', $4, $5, NOW(), NOW()) RETURNING id;`
+
+	for _, comment := range comments {
+		e := tx.QueryRow(ctx, commentQ, comment.TicketID, comment.Owner, comment.Content, CommentAuthorTypeAgent,
+			CommentSourceAPI).Scan(&comment.ID)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, nil, et
+		}
+	}
+
+	lockQ := `SELECT id FROM tickets WHERE id = ANY($1) AND status NOT IN ($2, $3) ORDER BY id FOR UPDATE;`
+
+	rows, e := tx.Query(ctx, lockQ, ticketIDs, TicketStatusResolved, TicketStatusClosed)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, nil, et
+	}
+
+	locked := make([]int64, 0, len(ticketIDs))
+	for rows.Next() {
+		var id int64
+		if e := rows.Scan(&id); e != nil {
+			rows.Close()
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, nil, et
+		}
+
+		locked = append(locked, id)
+	}
+	rows.Close()
+
+	if e := rows.Err(); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, nil, et
+	}
+
+	allowed, skipped, et := transitionable(ctx, r.logger, tx, locked, TicketStatusResolved, resolution)
+	if et != nil {
+		return nil, nil, et
+	}
+
+	// The status changes the same way TicketRepository.Update changes it.
+	q := `WITH previous AS (SELECT id, issuer, owner, importance_level, status FROM tickets
+			WHERE id = ANY($1) AND status NOT IN ($2, $3) FOR UPDATE)
+			UPDATE tickets AS t SET status = $2, waiting_since = NULL, nudged_at = NULL, resolved_at = NOW(),
+			resolution_category = COALESCE(NULLIF($4, ''), t.resolution_category),
+			resolution_sub_category = CASE WHEN $4 = '' THEN t.resolution_sub_category ELSE NULLIF($5, '') END,
+			root_cause = COALESCE(NULLIF($6, ''), t.root_cause)
+			FROM previous WHERE t.id = previous.id
+			RETURNING previous.id, previous.issuer, previous.owner, previous.importance_level, previous.status;`
+
+	rows, e = tx.Query(ctx, q, allowed, TicketStatusResolved, TicketStatusClosed, resolution.Category,
+		resolution.SubCategory, resolution.RootCause)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, nil, et
+	}
+
+	resolved := make([]*Ticket, 0, len(ticketIDs))
+	for rows.Next() {
+		ticket := &Ticket{}
+		if e := rows.Scan(&ticket.ID, &ticket.Issuer, &ticket.Owner, &ticket.ImportanceLevel,
+			&ticket.Status); e != nil {
+
+			rows.Close()
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, nil, et
+		}
+
+		resolved = append(resolved, ticket)
+	}
+	rows.Close()
+
+	if e := rows.Err(); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, nil, et
+	}
+
+	if _, e := tx.Exec(ctx, `UPDATE incidents SET resolved_at = NOW(), modified_at = NOW() WHERE id = $1;`,
+		incidentID); e != nil {
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, nil, et
+	}
+
+	if e := tx.Commit(ctx); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, nil, et
+	}
+
+	return resolved, skipped, nil
+}
+
+// lockOpen locks an incident for the rest of the transaction, failing when it is missing or already resolved.
+func (r *IncidentRepository) lockOpen(ctx context.Context, tx pgx.Tx, id int64) *errors.Type {
+	var resolved bool
+	e := tx.QueryRow(ctx, `SELECT resolved_at IS NOT NULL FROM incidents WHERE id = $1 FOR UPDATE;`, id).
+		Scan(&resolved)
+	if e != nil {
+		if e == pgx.ErrNoRows {
+			return errors.PreconditionFailed("incident.not_found", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	if resolved {
+		return errors.PreconditionFailed("incident.already_resolved", "")
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package models_test
+
+import (
+	"context"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Incident", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.IncidentRepository
+	var ticketRepository *models.TicketRepository
+	var commentRepository *models.CommentRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewIncidentRepository(zap.S(), db)
+			ticketRepository = models.NewTicketRepository(zap.S(), db)
+			commentRepository = models.NewCommentRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	insertTicket := func(status models.TicketStatus) int64 {
+		id, e := ticketRepository.Insert(context.Background(), models.Ticket{Issuer: "Microservice-A",
+			Owner: "user@example.com", Subject: "Payments fail", Content: "Content",
+			ImportanceLevel: models.TicketImportanceLevelHigh, Status: status})
+		Ω(e).Should(BeNil())
+		return id
+	}
+
+	Describe("IncidentRepository", func() {
+		Context("When Attach and Detach called", func() {
+			It("Should attach each ticket to one incident at most", func() {
+				first, second := insertTicket(models.TicketStatusNew), insertTicket(models.TicketStatusNew)
+
+				id, e := repository.Insert(context.Background(), models.Incident{Title: "Payments are down"})
+				Ω(e).Should(BeNil())
+
+				other, e := repository.Insert(context.Background(), models.Incident{Title: "Logins are slow"})
+				Ω(e).Should(BeNil())
+
+				Ω(repository.Attach(context.Background(), id, []int64{first, second})).Should(BeNil())
+				Ω(repository.Attach(context.Background(), id, []int64{first})).Should(BeNil())
+
+				e = repository.Attach(context.Background(), other, []int64{second})
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("ticket.attached_to_other_incident"))
+
+				e = repository.Attach(context.Background(), other, []int64{second + 1})
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("ticket.not_found"))
+
+				incident, e := repository.LoadByID(context.Background(), id)
+				Ω(e).Should(BeNil())
+				Ω(incident.Title).Should(Equal("Payments are down"))
+				Ω(incident.TicketIDs).Should(Equal([]int64{first, second}))
+
+				Ω(repository.Detach(context.Background(), id, second)).Should(BeNil())
+
+				e = repository.Detach(context.Background(), id, second)
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("ticket.not_attached"))
+
+				Ω(repository.Attach(context.Background(), other, []int64{second})).Should(BeNil())
+
+				incidents, e := repository.LoadAll(context.Background(), false)
+				Ω(e).Should(BeNil())
+				Ω(incidents).Should(HaveLen(2))
+				Ω(incidents[0].ID).Should(Equal(other))
+				Ω(incidents[0].TicketIDs).Should(Equal([]int64{second}))
+				Ω(incidents[1].TicketIDs).Should(Equal([]int64{first}))
+			})
+		})
+
+		Context("When Resolve called", func() {
+			It("Should comment on the tickets and resolve the open ones at once", func() {
+				open, closed := insertTicket(models.TicketStatusReplied), insertTicket(models.TicketStatusClosed)
+
+				id, e := repository.Insert(context.Background(), models.Incident{Title: "Payments are down"})
+				Ω(e).Should(BeNil())
+				Ω(repository.Attach(context.Background(), id, []int64{open, closed})).Should(BeNil())
+
+				tickets, e := repository.LoadTickets(context.Background(), id)
+				Ω(e).Should(BeNil())
+				Ω(tickets).Should(HaveLen(2))
+				Ω(tickets[0].Status).Should(Equal(models.TicketStatusReplied))
+				Ω(tickets[1].Subject).Should(Equal("Payments fail"))
+
+				comments := []*models.Comment{
+					{TicketID: open, Owner: "agent@example.com", Content: "Payments are back."},
+					{TicketID: closed, Owner: "agent@example.com", Content: "Payments are back."},
+				}
+
+				previous, skipped, e := repository.Resolve(context.Background(), id, comments, []int64{open, closed},
+					models.Resolution{})
+				Ω(e).Should(BeNil())
+				Ω(skipped).Should(BeEmpty())
+				Ω(previous).Should(HaveLen(1))
+				Ω(previous[0].ID).Should(Equal(open))
+				Ω(previous[0].Status).Should(Equal(models.TicketStatusReplied))
+
+				ticket, e := ticketRepository.LoadByID(context.Background(), open)
+				Ω(e).Should(BeNil())
+				Ω(ticket.Status).Should(Equal(models.TicketStatusResolved))
+				Ω(ticket.ResolvedAt).ShouldNot(BeNil())
+
+				ticket, e = ticketRepository.LoadByID(context.Background(), closed)
+				Ω(e).Should(BeNil())
+				Ω(ticket.Status).Should(Equal(models.TicketStatusClosed))
+
+				comment, e := commentRepository.LoadByID(context.Background(), comments[1].ID)
+				Ω(e).Should(BeNil())
+				Ω(comment.TicketID).Should(Equal(closed))
+				Ω(comment.AuthorType).Should(Equal(models.CommentAuthorTypeAgent))
+
+				incident, e := repository.LoadByID(context.Background(), id)
+				Ω(e).Should(BeNil())
+				Ω(incident.ResolvedAt).ShouldNot(BeNil())
+
+				_, _, e = repository.Resolve(context.Background(), id, nil, nil, models.Resolution{})
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("incident.already_resolved"))
+
+				e = repository.Attach(context.Background(), id, []int64{insertTicket(models.TicketStatusNew)})
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("incident.already_resolved"))
+			})
+
+			It("Should skip the tickets missing required fields or an approval, the posted comments counting", func() {
+				commented, unassigned := insertTicket(models.TicketStatusNew), insertTicket(models.TicketStatusNew)
+				guarded, e := ticketRepository.Insert(context.Background(), models.Ticket{Issuer: "Microservice-B",
+					Owner: "user@example.com", Subject: "Payments fail", Content: "Content",
+					ImportanceLevel: models.TicketImportanceLevelHigh, Status: models.TicketStatusNew})
+				Ω(e).Should(BeNil())
+
+				requirementRepository := models.NewTransitionRequirementRepository(zap.S(), db)
+				e = requirementRepository.Save(context.Background(), "Microservice-A", models.TicketStatusResolved,
+					[]models.RequiredField{models.RequiredFieldAgentComment, models.RequiredFieldAssignee})
+				Ω(e).Should(BeNil())
+
+				_, e = ticketRepository.Assign(context.Background(), commented, "agent-a", false)
+				Ω(e).Should(BeNil())
+
+				approverRepository := models.NewApproverRepository(zap.S(), db)
+				Ω(approverRepository.Save(context.Background(), "Microservice-B", models.TicketStatusResolved,
+					[]string{"lead@example.com"})).Should(BeNil())
+
+				id, e := repository.Insert(context.Background(), models.Incident{Title: "Payments are down"})
+				Ω(e).Should(BeNil())
+
+				comments := []*models.Comment{
+					{TicketID: commented, Owner: "agent@example.com", Content: "Payments are back."},
+					{TicketID: unassigned, Owner: "agent@example.com", Content: "Payments are back."},
+					{TicketID: guarded, Owner: "agent@example.com", Content: "Payments are back."},
+				}
+
+				previous, skipped, e := repository.Resolve(context.Background(), id, comments,
+					[]int64{commented, unassigned, guarded}, models.Resolution{})
+				Ω(e).Should(BeNil())
+				Ω(previous).Should(HaveLen(1))
+				Ω(previous[0].ID).Should(Equal(commented))
+				Ω(skipped).Should(Equal([]int64{unassigned, guarded}))
+
+				ticket, e := ticketRepository.LoadByID(context.Background(), unassigned)
+				Ω(e).Should(BeNil())
+				Ω(ticket.Status).Should(Equal(models.TicketStatusNew))
+			})
+		})
+	})
+})
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// Invariant is a condition every row of a table must hold. Violation is the condition of the rows breaking it, e.g.
+// rows written by other writers of the database or by a bug.
+type Invariant struct {
+	Name      string
+	Table     string
+	Violation string
+}
+
+// Invariants lists the invariants of the schema checked for drift.
+var Invariants = []Invariant{
+	{Name: "ticket_modified_before_created", Table: "tickets", Violation: "modified_at < created_at"},
+	{Name: "ticket_resolved_before_created", Table: "tickets", Violation: "resolved_at < created_at"},
+	{Name: "ticket_waiting_without_since", Table: "tickets",
+		Violation: "status = '" + string(TicketStatusWaitingOnCustomer) + "' AND waiting_since IS NULL"},
+	{Name: "ticket_since_without_waiting", Table: "tickets",
+		Violation: "status <> '" + string(TicketStatusWaitingOnCustomer) + "' AND waiting_since IS NOT NULL"},
+	{Name: "comment_modified_before_created", Table: "comments", Violation: "modified_at < created_at"},
+	{Name: "comment_edited_before_created", Table: "comments", Violation: "edited_at < created_at"},
+}
+
+// InvariantViolation is the number of rows breaking an invariant, along with the ids of a few of them.
+type InvariantViolation struct {
+	Invariant Invariant
+	Count     int64
+	SampleIDs []int64
+}
+
+// InvariantRepository is the repository implementation of checking the invariants of the schema.
+type InvariantRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewInvariantRepository returns back a newly created and ready to use InvariantRepository.
+func NewInvariantRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *InvariantRepository {
+	return &InvariantRepository{logger: logger, db: db}
+}
+
+// Check tries to check the invariants and returns back the broken ones, with up to samples ids of their rows.
+func (r *InvariantRepository) Check(ctx context.Context, invariants []Invariant, samples int) ([]*InvariantViolation,
+	*errors.Type) {
+
+	violations := make([]*InvariantViolation, 0)
+	for _, invariant := range invariants {
+		q := `SELECT COUNT(*), COALESCE((ARRAY_AGG(id ORDER BY id))[1:$1], '{}') FROM ` + invariant.Table +
+			` WHERE ` + invariant.Violation + `;`
+
+		violation := &InvariantViolation{Invariant: invariant}
+		if e := r.db.QueryRow(ctx, q, samples).Scan(&violation.Count, &violation.SampleIDs); e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		if violation.Count > 0 {
+			violations = append(violations, violation)
+		}
+	}
+
+	return violations, nil
+}
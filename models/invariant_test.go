@@ -0,0 +1,85 @@
+package models_test
+
+import (
+	"context"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Invariant", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.InvariantRepository
+	var ticketRepository *models.TicketRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewInvariantRepository(zap.S(), db)
+			ticketRepository = models.NewTicketRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	Describe("InvariantRepository", func() {
+		Context("When Check called", func() {
+			It("Should return back the broken invariants along with sample ids", func() {
+				ticket := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user@example.com",
+					Subject:         "Technical Problem",
+					Content:         "Hello, i have some issues with REST API Docs!",
+					ImportanceLevel: models.TicketImportanceLevelMedium,
+				}
+
+				id, e := ticketRepository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				violations, e := repository.Check(context.Background(), models.Invariants, 10)
+				Ω(e).Should(BeNil())
+				Ω(violations).Should(BeEmpty())
+
+				_, err := db.Exec(context.Background(),
+					"UPDATE tickets SET modified_at = created_at - INTERVAL '1 day' WHERE id = $1", id)
+				Ω(err).Should(BeNil())
+
+				violations, e = repository.Check(context.Background(), models.Invariants, 10)
+				Ω(e).Should(BeNil())
+				Ω(violations).Should(HaveLen(1))
+				Ω(violations[0].Invariant.Name).Should(Equal("ticket_modified_before_created"))
+				Ω(violations[0].Count).Should(Equal(int64(1)))
+				Ω(violations[0].SampleIDs).Should(Equal([]int64{id}))
+
+				_, err = db.Exec(context.Background(), "UPDATE tickets SET subject = 'Edited elsewhere' WHERE id = $1",
+					id)
+				Ω(err).Should(BeNil())
+
+				violations, e = repository.Check(context.Background(), models.Invariants, 10)
+				Ω(e).Should(BeNil())
+				Ω(violations).Should(BeEmpty())
+			})
+		})
+	})
+})
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/obfuscation"
+	"go.uber.org/zap"
+)
+
+// IssuerSettings is the entity model of issuer_settings table. It holds the per issuer customizations.
+type IssuerSettings struct {
+	Issuer                 string
+	DefaultImportanceLevel TicketImportanceLevel
+	DefaultStatus          TicketStatus
+	// NotificationMode and DigestFrequency apply to recipients without their own notification preferences.
+	NotificationMode NotificationMode
+	DigestFrequency  DigestFrequency
+	// Tier is the customer tier of the issuer, which selects the SLA targets of its tickets.
+	Tier CustomerTier
+	// SystemComments records the lifecycle events of tickets, e.g. status changes, as system comments in their threads.
+	SystemComments bool
+	// PublicIDSalt obfuscates the ids of tickets exposed to customers, padded to PublicIDMinLength, plain ids are
+	// exposed while it is empty. Changing it changes the public ids of all tickets of the issuer.
+	PublicIDSalt      string
+	PublicIDMinLength int
+}
+
+// PublicTicketID returns back the id of the ticket as exposed to customers, e.g. within emails and URLs.
+func (s *IssuerSettings) PublicTicketID(id int64) string {
+	if s.PublicIDSalt == "" {
+		return strconv.FormatInt(id, 10)
+	}
+
+	return obfuscation.NewCodec(s.PublicIDSalt, s.PublicIDMinLength).Encode(id)
+}
+
+// TicketID returns back the id of the ticket with the public id, false when it is not a public id of the issuer.
+func (s *IssuerSettings) TicketID(publicID string) (int64, bool) {
+	if s.PublicIDSalt == "" {
+		id, e := strconv.ParseInt(publicID, 10, 64)
+		return id, e == nil && id > 0 && strconv.FormatInt(id, 10) == publicID
+	}
+
+	return obfuscation.NewCodec(s.PublicIDSalt, s.PublicIDMinLength).Decode(publicID)
+}
+
+// DefaultIssuerSettings returns back the settings of issuers that have not customized anything.
+func DefaultIssuerSettings(issuer string) *IssuerSettings {
+	return &IssuerSettings{
+		Issuer:                 issuer,
+		DefaultImportanceLevel: TicketImportanceLevelMedium,
+		DefaultStatus:          TicketStatusNew,
+		NotificationMode:       NotificationModeImmediate,
+		DigestFrequency:        DigestFrequencyDaily,
+		Tier:                   CustomerTierBronze,
+		SystemComments:         true,
+	}
+}
+
+// IssuerSettingsRepository is the repository implementation of IssuerSettings model.
+type IssuerSettingsRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewIssuerSettingsRepository returns back a newly created and ready to use IssuerSettingsRepository.
+func NewIssuerSettingsRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *IssuerSettingsRepository {
+	return &IssuerSettingsRepository{logger: logger, db: db}
+}
+
+// saveIssuerSettingsQuery inserts the settings of an issuer or updates them if they already exist, it is shared with
+// tenant provisioning.
+const saveIssuerSettingsQuery = `INSERT INTO issuer_settings (issuer, default_importance_level, default_status,
+		notification_mode, digest_frequency, tier, system_comments, public_id_salt, public_id_min_length, created_at,
+		modified_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+		ON CONFLICT (issuer) DO UPDATE SET default_importance_level = EXCLUDED.default_importance_level,
+		default_status = EXCLUDED.default_status, notification_mode = EXCLUDED.notification_mode,
+		digest_frequency = EXCLUDED.digest_frequency, tier = EXCLUDED.tier,
+		system_comments = EXCLUDED.system_comments, public_id_salt = EXCLUDED.public_id_salt,
+		public_id_min_length = EXCLUDED.public_id_min_length, modified_at = NOW();`
+
+// Save tries to insert the settings of an issuer or update them if they already exist.
+func (r *IssuerSettingsRepository) Save(ctx context.Context, settings IssuerSettings) *errors.Type {
+	_, e := r.db.Exec(ctx, saveIssuerSettingsQuery, settings.Issuer, settings.DefaultImportanceLevel,
+		settings.DefaultStatus, settings.NotificationMode, settings.DigestFrequency, settings.Tier,
+		settings.SystemComments, settings.PublicIDSalt, settings.PublicIDMinLength)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// LoadByIssuer tries to load the settings of an issuer. Issuers without any stored settings get the defaults.
+func (r *IssuerSettingsRepository) LoadByIssuer(ctx context.Context, issuer string) (*IssuerSettings, *errors.Type) {
+	q := `SELECT issuer, default_importance_level, default_status, notification_mode, digest_frequency, tier,
+			system_comments, public_id_salt, public_id_min_length FROM issuer_settings WHERE issuer = $1;`
+
+	settings := &IssuerSettings{}
+
+	row := r.db.QueryRow(ctx, q, issuer)
+	e := row.Scan(&settings.Issuer, &settings.DefaultImportanceLevel, &settings.DefaultStatus,
+		&settings.NotificationMode, &settings.DigestFrequency, &settings.Tier, &settings.SystemComments,
+		&settings.PublicIDSalt, &settings.PublicIDMinLength)
+	if e != nil {
+		if e == pgx.ErrNoRows {
+			return DefaultIssuerSettings(issuer), nil
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,112 @@
+package models_test
+
+import (
+	"context"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("IssuerSettings", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.IssuerSettingsRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewIssuerSettingsRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	Describe("IssuerSettingsRepository", func() {
+		Context("When LoadByIssuer called", func() {
+			It("Should return back the defaults for issuers without settings", func() {
+				settings, e := repository.LoadByIssuer(context.Background(), "Microservice-A")
+				Ω(e).Should(BeNil())
+				Ω(settings).Should(Equal(models.DefaultIssuerSettings("Microservice-A")))
+			})
+		})
+
+		Context("When Save called", func() {
+			It("Should insert and then update the settings of an issuer", func() {
+				settings := models.IssuerSettings{
+					Issuer:                 "Microservice-A",
+					DefaultImportanceLevel: models.TicketImportanceLevelLow,
+					DefaultStatus:          models.TicketStatusNew,
+					NotificationMode:       models.NotificationModeImmediate,
+					DigestFrequency:        models.DigestFrequencyDaily,
+				}
+
+				e := repository.Save(context.Background(), settings)
+				Ω(e).Should(BeNil())
+
+				settings.DefaultImportanceLevel = models.TicketImportanceLevelHigh
+				settings.DefaultStatus = models.TicketStatusBlocked
+				settings.NotificationMode = models.NotificationModeDigest
+				settings.PublicIDSalt = "salt"
+				settings.PublicIDMinLength = 6
+
+				e = repository.Save(context.Background(), settings)
+				Ω(e).Should(BeNil())
+
+				loaded, e := repository.LoadByIssuer(context.Background(), "Microservice-A")
+				Ω(e).Should(BeNil())
+				Ω(*loaded).Should(Equal(settings))
+			})
+		})
+	})
+
+	Context("When PublicTicketID and TicketID called", func() {
+		It("Should expose plain ids unless the issuer has a salt", func() {
+			settings := models.DefaultIssuerSettings("Microservice-A")
+			Ω(settings.PublicTicketID(42)).Should(Equal("42"))
+
+			id, ok := settings.TicketID("42")
+			Ω(ok).Should(BeTrue())
+			Ω(id).Should(Equal(int64(42)))
+
+			_, ok = settings.TicketID("042")
+			Ω(ok).Should(BeFalse())
+		})
+
+		It("Should obfuscate ids reversibly with the salt of the issuer", func() {
+			settings := models.DefaultIssuerSettings("Microservice-A")
+			settings.PublicIDSalt = "salt"
+			settings.PublicIDMinLength = 6
+
+			publicID := settings.PublicTicketID(42)
+			Ω(publicID).Should(HaveLen(6))
+			Ω(publicID).ShouldNot(ContainSubstring("42"))
+
+			id, ok := settings.TicketID(publicID)
+			Ω(ok).Should(BeTrue())
+			Ω(id).Should(Equal(int64(42)))
+
+			_, ok = settings.TicketID("42")
+			Ω(ok).Should(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,165 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// DefaultJobMaxAttempts is the number of attempts of jobs enqueued without one.
+const DefaultJobMaxAttempts = 5
+
+// Job is the entity model of jobs table. It is a unit of background work, identified by its kind.
+type Job struct {
+	Model
+
+	Kind string
+	// UniqueKey, when not empty, prevents enqueuing the job while another one with the same key is pending.
+	UniqueKey   string
+	Payload     string
+	Status      JobStatus
+	Attempts    int
+	MaxAttempts int
+	RunAt       time.Time
+	Failure     string
+}
+
+// JobRepository is the repository implementation of Job model.
+type JobRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewJobRepository returns back a newly created and ready to use JobRepository.
+func NewJobRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *JobRepository {
+	return &JobRepository{logger: logger, db: db}
+}
+
+// Enqueue tries to insert a job into jobs table and returns back its id. Jobs run as soon as possible unless RunAt is
+// set. The returned id is zero when a pending job with the same unique key already exists.
+func (r *JobRepository) Enqueue(ctx context.Context, job Job) (int64, *errors.Type) {
+	q := `INSERT INTO jobs (kind, unique_key, payload, status, attempts, max_attempts, run_at, created_at, modified_at)
+			VALUES ($1, NULLIF($2, ''), $3, $4, 0, $5, $6, NOW(), NOW())
+			ON CONFLICT (unique_key) WHERE status IN ('QUEUED', 'RUNNING') DO NOTHING RETURNING id;`
+
+	if job.MaxAttempts == 0 {
+		job.MaxAttempts = DefaultJobMaxAttempts
+	}
+
+	if job.RunAt.IsZero() {
+		job.RunAt = time.Now()
+	}
+
+	var id int64
+	e := r.db.QueryRow(ctx, q, job.Kind, job.UniqueKey, job.Payload, JobStatusQueued, job.MaxAttempts,
+		job.RunAt.UTC()).Scan(&id)
+	if e != nil {
+		if e == pgx.ErrNoRows {
+			return 0, nil
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return 0, et
+	}
+
+	return id, nil
+}
+
+// Claim tries to mark at most limit due jobs of the provided kinds as running by worker and returns them back. Jobs
+// claimed by other workers concurrently are skipped, so each job is claimed once.
+func (r *JobRepository) Claim(ctx context.Context, worker string, kinds []string, limit int,
+	now time.Time) ([]*Job, *errors.Type) {
+
+	q := `UPDATE jobs SET status = $1, attempts = attempts + 1, locked_by = $2, locked_at = $3, modified_at = NOW()
+			WHERE id IN (SELECT id FROM jobs WHERE status = $4 AND run_at <= $3 AND kind = ANY($5)
+			ORDER BY run_at, id LIMIT $6 FOR UPDATE SKIP LOCKED)
+			RETURNING id, kind, COALESCE(unique_key, ''), payload, status, attempts, max_attempts, run_at,
+			COALESCE(failure, ''), created_at, modified_at;`
+
+	rows, e := r.db.Query(ctx, q, JobStatusRunning, worker, now.UTC(), JobStatusQueued, kinds, limit)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	jobs := make([]*Job, 0)
+	for rows.Next() {
+		job := &Job{}
+
+		e := rows.Scan(&job.ID, &job.Kind, &job.UniqueKey, &job.Payload, &job.Status, &job.Attempts,
+			&job.MaxAttempts, &job.RunAt, &job.Failure, &job.CreatedAt, &job.ModifiedAt)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+// Complete tries to mark a running job as done.
+func (r *JobRepository) Complete(ctx context.Context, id int64) *errors.Type {
+	q := `UPDATE jobs SET status = $1, failure = NULL, locked_by = NULL, locked_at = NULL, modified_at = NOW()
+			WHERE id = $2;`
+
+	if _, e := r.db.Exec(ctx, q, JobStatusDone, id); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// Fail tries to record the failure of a running job. The job is queued again to run at retryAt, unless it has run out
+// of attempts and is marked as failed.
+func (r *JobRepository) Fail(ctx context.Context, id int64, failure string, retryAt time.Time) *errors.Type {
+	q := `UPDATE jobs SET status = CASE WHEN attempts >= max_attempts THEN $1 ELSE $2 END, run_at = $3, failure = $4,
+			locked_by = NULL, locked_at = NULL, modified_at = NOW() WHERE id = $5;`
+
+	if _, e := r.db.Exec(ctx, q, JobStatusFailed, JobStatusQueued, retryAt.UTC(), failure, id); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// RequeueStale tries to queue the jobs of a kind again that were claimed before lockedBefore and never finished, e.g.
+// because their worker crashed. It returns back the number of such jobs.
+func (r *JobRepository) RequeueStale(ctx context.Context, kind string, lockedBefore time.Time) (int64, *errors.Type) {
+	q := `UPDATE jobs SET status = CASE WHEN attempts >= max_attempts THEN $1 ELSE $2 END,
+			failure = 'abandoned by ' || locked_by, locked_by = NULL, locked_at = NULL, modified_at = NOW()
+			WHERE status = $3 AND kind = $4 AND locked_at < $5;`
+
+	cmd, e := r.db.Exec(ctx, q, JobStatusFailed, JobStatusQueued, JobStatusRunning, kind, lockedBefore.UTC())
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return 0, et
+	}
+
+	return cmd.RowsAffected(), nil
+}
+
+// JobStatus model.
+type JobStatus string
+
+// Different job status instances.
+const (
+	JobStatusQueued  JobStatus = "QUEUED"
+	JobStatusRunning JobStatus = "RUNNING"
+	JobStatusDone    JobStatus = "DONE"
+	JobStatusFailed  JobStatus = "FAILED"
+)
@@ -0,0 +1,146 @@
+package models_test
+
+import (
+	"context"
+	"time"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Job", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.JobRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewJobRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	Describe("JobRepository", func() {
+		Context("When Enqueue called", func() {
+			It("Should not enqueue a job while another one with the same unique key is pending", func() {
+				job := models.Job{Kind: "notifications.deliver", UniqueKey: "notifications.deliver:1", Payload: "{}"}
+
+				id, e := repository.Enqueue(context.Background(), job)
+				Ω(e).Should(BeNil())
+				Ω(id).Should(Equal(int64(1)))
+
+				id, e = repository.Enqueue(context.Background(), job)
+				Ω(e).Should(BeNil())
+				Ω(id).Should(BeZero())
+
+				claimed, e := repository.Claim(context.Background(), "kiosk-1", []string{job.Kind}, 10, time.Now())
+				Ω(e).Should(BeNil())
+				Ω(claimed).Should(HaveLen(1))
+				Ω(repository.Complete(context.Background(), claimed[0].ID)).Should(BeNil())
+
+				id, e = repository.Enqueue(context.Background(), job)
+				Ω(e).Should(BeNil())
+				Ω(id).Should(Equal(int64(3)))
+			})
+		})
+
+		Context("When Claim called", func() {
+			It("Should claim due jobs of the provided kinds once", func() {
+				_, e := repository.Enqueue(context.Background(), models.Job{Kind: "tickets.reindex", Payload: "{}"})
+				Ω(e).Should(BeNil())
+
+				other := models.Job{Kind: "notifications.deliver", Payload: "{}"}
+				_, e = repository.Enqueue(context.Background(), other)
+				Ω(e).Should(BeNil())
+
+				later := models.Job{Kind: "tickets.reindex", Payload: "{}", RunAt: time.Now().Add(time.Hour)}
+				_, e = repository.Enqueue(context.Background(), later)
+				Ω(e).Should(BeNil())
+
+				claimed, e := repository.Claim(context.Background(), "kiosk-1", []string{"tickets.reindex"}, 10,
+					time.Now())
+				Ω(e).Should(BeNil())
+				Ω(claimed).Should(HaveLen(1))
+				Ω(claimed[0].ID).Should(Equal(int64(1)))
+				Ω(claimed[0].Status).Should(Equal(models.JobStatusRunning))
+				Ω(claimed[0].Attempts).Should(Equal(1))
+
+				claimed, e = repository.Claim(context.Background(), "kiosk-2", []string{"tickets.reindex"}, 10,
+					time.Now())
+				Ω(e).Should(BeNil())
+				Ω(claimed).Should(BeEmpty())
+			})
+		})
+
+		Context("When Fail called", func() {
+			It("Should retry the job until it runs out of attempts", func() {
+				job := models.Job{Kind: "notifications.deliver", Payload: "{}", MaxAttempts: 2}
+				_, e := repository.Enqueue(context.Background(), job)
+				Ω(e).Should(BeNil())
+
+				for attempt := 1; attempt <= 2; attempt++ {
+					claimed, e := repository.Claim(context.Background(), "kiosk-1", []string{job.Kind}, 10,
+						time.Now())
+					Ω(e).Should(BeNil())
+					Ω(claimed).Should(HaveLen(1))
+					Ω(claimed[0].Attempts).Should(Equal(attempt))
+
+					Ω(repository.Fail(context.Background(), claimed[0].ID, "timeout", time.Now())).Should(BeNil())
+				}
+
+				claimed, e := repository.Claim(context.Background(), "kiosk-1", []string{job.Kind}, 10, time.Now())
+				Ω(e).Should(BeNil())
+				Ω(claimed).Should(BeEmpty())
+			})
+		})
+
+		Context("When RequeueStale called", func() {
+			It("Should take over the jobs of workers that went away", func() {
+				_, e := repository.Enqueue(context.Background(), models.Job{Kind: "tickets.reindex", Payload: "{}"})
+				Ω(e).Should(BeNil())
+
+				claimed, e := repository.Claim(context.Background(), "kiosk-1", []string{"tickets.reindex"}, 10,
+					time.Now())
+				Ω(e).Should(BeNil())
+				Ω(claimed).Should(HaveLen(1))
+
+				count, e := repository.RequeueStale(context.Background(), "tickets.reindex",
+					time.Now().Add(-time.Minute))
+				Ω(e).Should(BeNil())
+				Ω(count).Should(BeZero())
+
+				count, e = repository.RequeueStale(context.Background(), "tickets.reindex", time.Now().Add(time.Minute))
+				Ω(e).Should(BeNil())
+				Ω(count).Should(Equal(int64(1)))
+
+				claimed, e = repository.Claim(context.Background(), "kiosk-2", []string{"tickets.reindex"}, 10,
+					time.Now())
+				Ω(e).Should(BeNil())
+				Ω(claimed).Should(HaveLen(1))
+				Ω(claimed[0].Failure).Should(Equal("abandoned by kiosk-1"))
+			})
+		})
+	})
+})
@@ -0,0 +1,279 @@
+package models
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+	"golang.org/x/text/language"
+)
+
+// Macro is the entity model of macros table. It is a named bundle of actions agents apply to tickets at once, i.e.
+// moving them to Status and posting Comment as a canned comment. Empty actions are skipped. Translations holds the
+// canned comment in other languages, keyed by their canonical BCP 47 locales.
+//
+// TODO: Add tags and assign teams as actions once tickets can have tags and teams, tickets are only assigned to agents.
+type Macro struct {
+	Model
+
+	Name         string
+	Status       TicketStatus
+	Comment      string
+	Translations map[string]string
+}
+
+// Cursor returns back the position of the macro among macros ordered by name, which is unique.
+func (m *Macro) Cursor() Cursor {
+	name := m.Name
+	return Cursor{&name}
+}
+
+// Localize returns back the canned comment in the language best matching the Accept-Language styled list of languages,
+// e.g. fa-IR, en;q=0.8, falling back to Comment when none of the translations matches.
+func (m *Macro) Localize(languages string) string {
+	if len(m.Translations) == 0 {
+		return m.Comment
+	}
+
+	preferred, _, e := language.ParseAcceptLanguage(languages)
+	if e != nil || len(preferred) == 0 {
+		return m.Comment
+	}
+
+	locales := make([]string, 0, len(m.Translations))
+	for locale := range m.Translations {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	supported := make([]language.Tag, 0, len(locales))
+	for _, locale := range locales {
+		supported = append(supported, language.Make(locale))
+	}
+
+	_, i, confidence := language.NewMatcher(supported).Match(preferred...)
+	if confidence == language.No {
+		return m.Comment
+	}
+
+	return m.Translations[locales[i]]
+}
+
+// MacroRepository is the repository implementation of Macro model.
+type MacroRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewMacroRepository returns back a newly created and ready to use MacroRepository.
+func NewMacroRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *MacroRepository {
+	return &MacroRepository{logger: logger, db: db}
+}
+
+// Insert tries to insert a macro into macros table and returns back its id. Names of macros are unique.
+func (r *MacroRepository) Insert(ctx context.Context, macro Macro) (int64, *errors.Type) {
+	q := `INSERT INTO macros (name, status, comment, translations, created_at, modified_at)
+			VALUES ($1, $2, $3, $4, NOW(), NOW()) ON CONFLICT (name) DO NOTHING RETURNING id;`
+
+	var id int64
+	e := r.db.QueryRow(ctx, q, macro.Name, macro.Status, macro.Comment, translations(macro.Translations)).Scan(&id)
+	if e != nil {
+		if e == pgx.ErrNoRows {
+			return 0, errors.AlreadyExists("macro.already_exists", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return 0, et
+	}
+
+	return id, nil
+}
+
+// LoadByID tries to load a macro from macros table.
+func (r *MacroRepository) LoadByID(ctx context.Context, id int64) (*Macro, *errors.Type) {
+	q := `SELECT id, name, status, comment, translations, created_at, modified_at FROM macros WHERE id = $1;`
+
+	macros, e := r.load(ctx, q, id)
+	if e != nil {
+		return nil, e
+	}
+
+	if len(macros) == 0 {
+		return nil, errors.NotFound("macro.not_found", "")
+	}
+
+	return macros[0], nil
+}
+
+// LoadAll tries to load up to limit macros, or all of them when zero, ordered by name. They start right after the macro
+// at the cursor when one is given, as returned back by its Cursor. The second returned value tells whether there are
+// more macros.
+func (r *MacroRepository) LoadAll(ctx context.Context, after Cursor, limit int) ([]*Macro, bool, *errors.Type) {
+	// One more macro is loaded to tell whether there are more, a NULL limit loads all of them.
+	q := `SELECT id, name, status, comment, translations, created_at, modified_at FROM macros
+			WHERE $1::TEXT IS NULL OR name > $1 ORDER BY name LIMIT NULLIF($2::INT, 0) + 1;`
+
+	macros, e := r.load(ctx, q, after.key(), limit)
+	if e != nil {
+		return nil, false, e
+	}
+
+	hasMore := limit > 0 && len(macros) > limit
+	if hasMore {
+		// Drop the extra one.
+		macros = macros[:limit]
+	}
+
+	return macros, hasMore, nil
+}
+
+func (r *MacroRepository) load(ctx context.Context, q string, args ...interface{}) ([]*Macro, *errors.Type) {
+	rows, e := r.db.Query(ctx, q, args...)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	macros := make([]*Macro, 0)
+	for rows.Next() {
+		macro := &Macro{}
+
+		e := rows.Scan(&macro.ID, &macro.Name, &macro.Status, &macro.Comment, &macro.Translations, &macro.CreatedAt,
+			&macro.ModifiedAt)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		macros = append(macros, macro)
+	}
+
+	return macros, nil
+}
+
+// Update tries to replace the name and actions of a macro.
+func (r *MacroRepository) Update(ctx context.Context, macro Macro) *errors.Type {
+	q := `UPDATE macros SET name = $1, status = $2, comment = $3, translations = $4, modified_at = NOW()
+			WHERE id = $5;`
+
+	tag, e := r.db.Exec(ctx, q, macro.Name, macro.Status, macro.Comment, translations(macro.Translations), macro.ID)
+	if e != nil {
+		if strings.Contains(e.Error(), "macros_name") {
+			return errors.AlreadyExists("macro.already_exists", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errors.NotFound("macro.not_found", "")
+	}
+
+	return nil
+}
+
+// translations keeps macros without translations from storing them as JSON null.
+func translations(translations map[string]string) map[string]string {
+	if translations == nil {
+		return map[string]string{}
+	}
+
+	return translations
+}
+
+// DeleteByID tries to delete a macro from macros table.
+func (r *MacroRepository) DeleteByID(ctx context.Context, id int64) *errors.Type {
+	q := `DELETE FROM macros WHERE id = $1;`
+
+	if _, e := r.db.Exec(ctx, q, id); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// Apply tries to apply the actions of a macro to a ticket in a single transaction, so either all of them take effect
+// or none. The canned comment, Comment of the macro as given rather than a translation, is posted on behalf of the
+// agent. It returns back the ticket as it was before, i.e. its id, issuer, owner, importance level and status, along
+// with the id of the comment, zero if there is none.
+func (r *MacroRepository) Apply(ctx context.Context, ticketID int64, macro *Macro, agent string) (*Ticket, int64,
+	*errors.Type) {
+
+	tx, e := r.db.Begin(ctx)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, 0, et
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	q := `SELECT id, issuer, owner, importance_level, status FROM tickets WHERE id = $1 FOR UPDATE;`
+
+	previous := &Ticket{}
+	e = tx.QueryRow(ctx, q, ticketID).Scan(&previous.ID, &previous.Issuer, &previous.Owner,
+		&previous.ImportanceLevel, &previous.Status)
+	if e != nil {
+		if e == pgx.ErrNoRows {
+			return nil, 0, errors.PreconditionFailed("ticket.not_found", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, 0, et
+	}
+
+	// The status changes the same way TicketRepository.Update changes it, subject to the same checks.
+	if macro.Status != "" {
+		if e := checkTransition(ctx, r.logger, tx, ticketID, macro.Status, Resolution{}); e != nil {
+			return nil, 0, e
+		}
+
+		q := `UPDATE tickets SET status = $1,
+				waiting_since = CASE WHEN $1 <> $3 THEN NULL WHEN status = $3 THEN waiting_since ELSE NOW() END,
+				nudged_at = CASE WHEN status = $1 THEN nudged_at END,
+				resolved_at = CASE WHEN $1 = $4 AND status <> $4 THEN NOW() ELSE resolved_at END
+				WHERE id = $2;`
+
+		if _, e := tx.Exec(ctx, q, macro.Status, ticketID, TicketStatusWaitingOnCustomer,
+			TicketStatusResolved); e != nil {
+
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, 0, et
+		}
+	}
+
+	var commentID int64
+	if macro.Comment != "" {
+		q := `INSERT INTO comments (ticket_id, owner, content, metadata, author_type, source, created_at, modified_at)
+				VALUES ($1, $2, $3, '', $4, $5, NOW(), NOW()) RETURNING id;`
+
+		e := tx.QueryRow(ctx, q, ticketID, agent, macro.Comment, CommentAuthorTypeAgent, CommentSourceAPI).
+			Scan(&commentID)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, 0, et
+		}
+	}
+
+	if e := tx.Commit(ctx); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, 0, et
+	}
+
+	return previous, commentID, nil
+}
@@ -0,0 +1,205 @@
+package models_test
+
+import (
+	"context"
+	"strings"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Macro", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.MacroRepository
+	var ticketRepository *models.TicketRepository
+	var commentRepository *models.CommentRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewMacroRepository(zap.S(), db)
+			ticketRepository = models.NewTicketRepository(zap.S(), db)
+			commentRepository = models.NewCommentRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	Describe("MacroRepository", func() {
+		Context("When Insert, Update, LoadAll and DeleteByID called", func() {
+			It("Should manage macros by their unique names", func() {
+				id, e := repository.Insert(context.Background(), models.Macro{Name: "Ask for logs",
+					Status: models.TicketStatusWaitingOnCustomer, Comment: "Could you send us the logs?"})
+				Ω(e).Should(BeNil())
+
+				_, e = repository.Insert(context.Background(), models.Macro{Name: "Ask for logs",
+					Comment: "Logs please."})
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Kind).Should(Equal(errors.KindConflict))
+				Ω(e.Errors[0].Code).Should(Equal("macro.already_exists"))
+
+				other, e := repository.Insert(context.Background(), models.Macro{Name: "Close as fixed",
+					Status: models.TicketStatusClosed})
+				Ω(e).Should(BeNil())
+
+				e = repository.Update(context.Background(), models.Macro{Model: models.Model{ID: other},
+					Name: "Ask for logs", Status: models.TicketStatusClosed})
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("macro.already_exists"))
+
+				e = repository.Update(context.Background(), models.Macro{Model: models.Model{ID: other},
+					Name: "Close as resolved", Status: models.TicketStatusResolved, Comment: "Glad it works."})
+				Ω(e).Should(BeNil())
+
+				macros, hasMore, e := repository.LoadAll(context.Background(), nil, 0)
+				Ω(e).Should(BeNil())
+				Ω(hasMore).Should(BeFalse())
+				Ω(macros).Should(HaveLen(2))
+				Ω(macros[0].ID).Should(Equal(id))
+				Ω(macros[1].Name).Should(Equal("Close as resolved"))
+				Ω(macros[1].Status).Should(Equal(models.TicketStatusResolved))
+				Ω(macros[1].Comment).Should(Equal("Glad it works."))
+
+				macros, hasMore, e = repository.LoadAll(context.Background(), nil, 1)
+				Ω(e).Should(BeNil())
+				Ω(hasMore).Should(BeTrue())
+				Ω(macros).Should(HaveLen(1))
+
+				macros, hasMore, e = repository.LoadAll(context.Background(), macros[0].Cursor(), 1)
+				Ω(e).Should(BeNil())
+				Ω(hasMore).Should(BeFalse())
+				Ω(macros).Should(HaveLen(1))
+				Ω(macros[0].Name).Should(Equal("Close as resolved"))
+
+				Ω(repository.DeleteByID(context.Background(), id)).Should(BeNil())
+
+				_, e = repository.LoadByID(context.Background(), id)
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("macro.not_found"))
+
+				e = repository.Update(context.Background(), models.Macro{Model: models.Model{ID: id}, Name: "Gone"})
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("macro.not_found"))
+			})
+		})
+
+		Context("When a macro with translations inserted", func() {
+			It("Should load its translations back and localize its canned comment", func() {
+				id, e := repository.Insert(context.Background(), models.Macro{Name: "Ask for logs",
+					Comment: "Could you send us the logs?", Translations: map[string]string{
+						"fa-IR": "لطفا لاگ‌ها را ارسال کنید.", "de": "Könnten Sie uns die Logs schicken?"}})
+				Ω(e).Should(BeNil())
+
+				macro, e := repository.LoadByID(context.Background(), id)
+				Ω(e).Should(BeNil())
+				Ω(macro.Translations).Should(HaveLen(2))
+
+				Ω(macro.Localize("fa-IR, en;q=0.8")).Should(Equal("لطفا لاگ‌ها را ارسال کنید."))
+				Ω(macro.Localize("fa")).Should(Equal("لطفا لاگ‌ها را ارسال کنید."))
+				Ω(macro.Localize("de-AT")).Should(Equal("Könnten Sie uns die Logs schicken?"))
+				Ω(macro.Localize("fr")).Should(Equal("Could you send us the logs?"))
+				Ω(macro.Localize("")).Should(Equal("Could you send us the logs?"))
+
+				other, e := repository.Insert(context.Background(), models.Macro{Name: "Close",
+					Status: models.TicketStatusClosed})
+				Ω(e).Should(BeNil())
+
+				macro, e = repository.LoadByID(context.Background(), other)
+				Ω(e).Should(BeNil())
+				Ω(macro.Translations).Should(BeEmpty())
+			})
+		})
+
+		Context("When Apply called", func() {
+			It("Should move the ticket to the status and post the canned comment on behalf of the agent", func() {
+				ticketID, e := ticketRepository.Insert(context.Background(), models.Ticket{Issuer: "Microservice-A",
+					Owner: "user@example.com", Subject: "Subject", Content: "Content",
+					ImportanceLevel: models.TicketImportanceLevelHigh, Status: models.TicketStatusNew})
+				Ω(e).Should(BeNil())
+
+				macro := &models.Macro{Name: "Ask for logs", Status: models.TicketStatusWaitingOnCustomer,
+					Comment: "Could you send us the logs?"}
+
+				previous, commentID, e := repository.Apply(context.Background(), ticketID, macro, "agent@example.com")
+				Ω(e).Should(BeNil())
+				Ω(previous.Status).Should(Equal(models.TicketStatusNew))
+				Ω(previous.Owner).Should(Equal("user@example.com"))
+				Ω(commentID).ShouldNot(BeZero())
+
+				ticket, e := ticketRepository.LoadByID(context.Background(), ticketID)
+				Ω(e).Should(BeNil())
+				Ω(ticket.Status).Should(Equal(models.TicketStatusWaitingOnCustomer))
+
+				comment, e := commentRepository.LoadByID(context.Background(), commentID)
+				Ω(e).Should(BeNil())
+				Ω(comment.TicketID).Should(Equal(ticketID))
+				Ω(comment.Owner).Should(Equal("agent@example.com"))
+				Ω(comment.Content).Should(Equal("Could you send us the logs?"))
+				Ω(comment.AuthorType).Should(Equal(models.CommentAuthorTypeAgent))
+				Ω(comment.Source).Should(Equal(models.CommentSourceAPI))
+			})
+
+			It("Should skip empty actions", func() {
+				ticketID, e := ticketRepository.Insert(context.Background(), models.Ticket{Issuer: "Microservice-A",
+					Owner: "user@example.com", Subject: "Subject", Content: "Content",
+					ImportanceLevel: models.TicketImportanceLevelHigh, Status: models.TicketStatusNew})
+				Ω(e).Should(BeNil())
+
+				_, commentID, e := repository.Apply(context.Background(), ticketID,
+					&models.Macro{Name: "Resolve", Status: models.TicketStatusResolved}, "agent@example.com")
+				Ω(e).Should(BeNil())
+				Ω(commentID).Should(BeZero())
+
+				ticket, e := ticketRepository.LoadByID(context.Background(), ticketID)
+				Ω(e).Should(BeNil())
+				Ω(ticket.Status).Should(Equal(models.TicketStatusResolved))
+				Ω(ticket.ResolvedAt).ShouldNot(BeNil())
+			})
+
+			It("Should leave the ticket untouched when any of the actions fails", func() {
+				ticketID, e := ticketRepository.Insert(context.Background(), models.Ticket{Issuer: "Microservice-A",
+					Owner: "user@example.com", Subject: "Subject", Content: "Content",
+					ImportanceLevel: models.TicketImportanceLevelHigh, Status: models.TicketStatusNew})
+				Ω(e).Should(BeNil())
+
+				// Owners of comments are at most 50 characters long, so posting the comment fails.
+				_, _, e = repository.Apply(context.Background(), ticketID, &models.Macro{Name: "Ask for logs",
+					Status: models.TicketStatusWaitingOnCustomer, Comment: "Logs please."}, strings.Repeat("a", 51))
+				Ω(e).ShouldNot(BeNil())
+
+				ticket, e := ticketRepository.LoadByID(context.Background(), ticketID)
+				Ω(e).Should(BeNil())
+				Ω(ticket.Status).Should(Equal(models.TicketStatusNew))
+			})
+
+			It("Should return precondition failed error for missing tickets", func() {
+				_, _, e := repository.Apply(context.Background(), 1, &models.Macro{Name: "Resolve",
+					Status: models.TicketStatusResolved}, "agent@example.com")
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("ticket.not_found"))
+			})
+		})
+	})
+})
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// MaintenanceWindow is the entity model of maintenance_windows table. Notifications of the issuer are suppressed from
+// StartsAt until EndsAt. An empty issuer means the window applies to all issuers.
+type MaintenanceWindow struct {
+	Model
+
+	Issuer   string
+	StartsAt time.Time
+	EndsAt   time.Time
+	Reason   string
+}
+
+// Covers checks whether this window suppresses the notifications of provided issuer at the provided time.
+func (w *MaintenanceWindow) Covers(issuer string, at time.Time) bool {
+	if w.Issuer != "" && w.Issuer != issuer {
+		return false
+	}
+
+	return !at.Before(w.StartsAt) && at.Before(w.EndsAt)
+}
+
+// MaintenanceWindowRepository is the repository implementation of MaintenanceWindow model.
+type MaintenanceWindowRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewMaintenanceWindowRepository returns back a newly created and ready to use MaintenanceWindowRepository.
+func NewMaintenanceWindowRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *MaintenanceWindowRepository {
+	return &MaintenanceWindowRepository{logger: logger, db: db}
+}
+
+// Insert tries to insert a maintenance window into maintenance_windows table.
+func (r *MaintenanceWindowRepository) Insert(ctx context.Context, window MaintenanceWindow) (int64, *errors.Type) {
+	q := `INSERT INTO maintenance_windows (issuer, starts_at, ends_at, reason, created_at, modified_at)
+			VALUES ($1, $2, $3, $4, NOW(), NOW()) RETURNING id;`
+
+	var id int64
+	e := r.db.QueryRow(ctx, q, window.Issuer, window.StartsAt.UTC(), window.EndsAt.UTC(), window.Reason).Scan(&id)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return 0, et
+	}
+
+	return id, nil
+}
+
+// LoadNotEndedYet tries to load the maintenance windows that are ongoing or upcoming at the provided time.
+func (r *MaintenanceWindowRepository) LoadNotEndedYet(ctx context.Context, at time.Time) ([]*MaintenanceWindow,
+	*errors.Type) {
+
+	q := `SELECT id, issuer, starts_at, ends_at, reason, created_at, modified_at FROM maintenance_windows
+			WHERE ends_at > $1 ORDER BY starts_at, id;`
+
+	rows, e := r.db.Query(ctx, q, at.UTC())
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	windows := make([]*MaintenanceWindow, 0)
+	for rows.Next() {
+		window := &MaintenanceWindow{}
+
+		e := rows.Scan(&window.ID, &window.Issuer, &window.StartsAt, &window.EndsAt, &window.Reason,
+			&window.CreatedAt, &window.ModifiedAt)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		windows = append(windows, window)
+	}
+
+	return windows, nil
+}
+
+// DeleteByID tries to delete a maintenance window from maintenance_windows table.
+func (r *MaintenanceWindowRepository) DeleteByID(ctx context.Context, id int64) *errors.Type {
+	q := `DELETE FROM maintenance_windows WHERE id=$1;`
+
+	_, e := r.db.Exec(ctx, q, id)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
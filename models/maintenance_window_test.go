@@ -0,0 +1,77 @@
+package models_test
+
+import (
+	"context"
+	"time"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("MaintenanceWindow", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.MaintenanceWindowRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewMaintenanceWindowRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	Describe("MaintenanceWindowRepository", func() {
+		Context("When Insert, LoadNotEndedYet and DeleteByID called", func() {
+			It("Should store, load and delete only not ended maintenance windows", func() {
+				now := time.Now().UTC().Truncate(time.Second)
+
+				ended := models.MaintenanceWindow{Issuer: "Microservice-A", StartsAt: now.Add(-2 * time.Hour),
+					EndsAt: now.Add(-time.Hour), Reason: "Database upgrade"}
+				_, e := repository.Insert(context.Background(), ended)
+				Ω(e).Should(BeNil())
+
+				ongoing := models.MaintenanceWindow{StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour),
+					Reason: "Mail server migration"}
+				id, e := repository.Insert(context.Background(), ongoing)
+				Ω(e).Should(BeNil())
+
+				windows, e := repository.LoadNotEndedYet(context.Background(), now)
+				Ω(e).Should(BeNil())
+				Ω(windows).Should(HaveLen(1))
+				Ω(windows[0].ID).Should(Equal(id))
+				Ω(windows[0].Reason).Should(Equal("Mail server migration"))
+				Ω(windows[0].Covers("Microservice-B", now)).Should(BeTrue())
+				Ω(windows[0].Covers("Microservice-B", now.Add(2*time.Hour))).Should(BeFalse())
+
+				e = repository.DeleteByID(context.Background(), id)
+				Ω(e).Should(BeNil())
+
+				windows, e = repository.LoadNotEndedYet(context.Background(), now)
+				Ω(e).Should(BeNil())
+				Ω(windows).Should(HaveLen(0))
+			})
+		})
+	})
+})
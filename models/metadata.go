@@ -0,0 +1,78 @@
+package models
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// MetadataMatch filters tickets by their metadata, mapping dot separated paths within the metadata document, e.g.
+// owner_ip or customer.plan, to the values they must hold. Values are matched as JSON when they are valid JSON, e.g.
+// numbers and booleans, and as strings otherwise.
+type MetadataMatch map[string]string
+
+// Document builds the JSON document the metadata of matching tickets contains. It returns false when a path is a prefix
+// of another one, as a value can not be held at both.
+func (m MetadataMatch) Document() (string, bool) {
+	paths := make([]string, 0, len(m))
+	for path := range m {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	document := make(map[string]interface{})
+	for _, path := range paths {
+		segments := strings.Split(path, ".")
+
+		node := document
+		for _, segment := range segments[:len(segments)-1] {
+			child, exists := node[segment]
+			if !exists {
+				child = make(map[string]interface{})
+				node[segment] = child
+			}
+
+			object, ok := child.(map[string]interface{})
+			if !ok {
+				return "", false
+			}
+
+			node = object
+		}
+
+		leaf := segments[len(segments)-1]
+		if _, exists := node[leaf]; exists {
+			return "", false
+		}
+
+		if value := m[path]; json.Valid([]byte(value)) {
+			node[leaf] = json.RawMessage(value)
+		} else {
+			node[leaf] = value
+		}
+	}
+
+	encoded, e := json.Marshal(document)
+	if e != nil {
+		return "", false
+	}
+
+	return string(encoded), true
+}
+
+// metadataDocument converts the metadata of a ticket to the JSON document it is stored as. Metadata that is not valid
+// JSON is stored as a JSON string and empty metadata as NULL, reading the document back as text returns back both as
+// provided.
+func metadataDocument(metadata string) *string {
+	if metadata == "" {
+		return nil
+	}
+
+	if json.Valid([]byte(metadata)) {
+		return &metadata
+	}
+
+	encoded, _ := json.Marshal(metadata)
+	document := string(encoded)
+	return &document
+}
@@ -0,0 +1,81 @@
+package models_test
+
+import (
+	"encoding/json"
+	"math/rand"
+	"strings"
+
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Metadata", func() {
+	Describe("MetadataMatch", func() {
+		Context("When Document called", func() {
+			It("Should hold every path at its value unless a path is a prefix of another one", func() {
+				r := rand.New(rand.NewSource(GinkgoRandomSeed()))
+				segments := []string{"customer", "plan", "ip", "seats", ""}
+				values := []string{"gold", "5", "true", "null", `{"nested":1}`, `"quoted"`, "not json", ""}
+
+				for i := 0; i < 2000; i++ {
+					match := models.MetadataMatch{}
+					for j := r.Intn(5); j >= 0; j-- {
+						path := make([]string, r.Intn(3)+1)
+						for k := range path {
+							path[k] = segments[r.Intn(len(segments))]
+						}
+						match[strings.Join(path, ".")] = values[r.Intn(len(values))]
+					}
+
+					conflicting := false
+					for path := range match {
+						for other := range match {
+							conflicting = conflicting || strings.HasPrefix(other, path+".")
+						}
+					}
+
+					document, ok := match.Document()
+					Ω(ok).Should(Equal(!conflicting), "match: %v", match)
+					if !ok {
+						continue
+					}
+
+					decoded := make(map[string]interface{})
+					Ω(json.Unmarshal([]byte(document), &decoded)).Should(Succeed(), "match: %v", match)
+
+					for path, value := range match {
+						var node interface{} = decoded
+						for _, segment := range strings.Split(path, ".") {
+							node = node.(map[string]interface{})[segment]
+						}
+
+						var expected interface{} = value
+						if json.Valid([]byte(value)) {
+							_ = json.Unmarshal([]byte(value), &expected)
+						}
+						Ω(node).Should(Equal(expected), "match: %v, path: %v", match, path)
+					}
+				}
+			})
+
+			It("Should not panic on malformed paths and values", func() {
+				seeds := [][]byte{[]byte(`{"owner_ip":"10.0.0.1","customer.plan":"gold"}`),
+					[]byte(`{"customer.seats":"5","customer.trial":"true","a..b":"{\"c\":[1]}"}`)}
+
+				test.Fuzz(GinkgoRandomSeed(), seeds, 5000, func(input []byte) {
+					match := models.MetadataMatch{}
+					if e := json.Unmarshal(input, &match); e != nil {
+						return
+					}
+
+					var document string
+					var ok bool
+					Ω(func() { document, ok = match.Document() }).ShouldNot(Panic(), "match: %q", input)
+					Ω(!ok || json.Valid([]byte(document))).Should(BeTrue(), "match: %q", input)
+				})
+			})
+		})
+	})
+})
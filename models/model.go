@@ -8,3 +8,13 @@ type Model struct {
 	CreatedAt  time.Time
 	ModifiedAt time.Time
 }
+
+// utc converts an optional time to UTC, as timestamp columns carry no time zone.
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	u := t.UTC()
+	return &u
+}
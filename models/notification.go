@@ -0,0 +1,222 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// Notification is the entity model of notifications table. It is the delivery receipt of an email sent about a ticket.
+type Notification struct {
+	Model
+
+	TicketID  int64
+	Issuer    string
+	Recipient string
+	Subject   string
+	Body      string
+	Status    NotificationStatus
+	Failure   string
+	// ImportanceLevel of the ticket at the time of notification.
+	ImportanceLevel TicketImportanceLevel
+	// EventType is the type of the event the notification is sent about, if any.
+	EventType string
+}
+
+// IsLowPriority checks whether the notification may wait for a digest instead of being delivered immediately.
+func (n *Notification) IsLowPriority() bool {
+	return n.ImportanceLevel == TicketImportanceLevelLow || n.ImportanceLevel == TicketImportanceLevelMedium
+}
+
+// NotificationRepository is the repository implementation of Notification model.
+type NotificationRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewNotificationRepository returns back a newly created and ready to use NotificationRepository.
+func NewNotificationRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *NotificationRepository {
+	return &NotificationRepository{logger: logger, db: db}
+}
+
+// Insert tries to insert a notification into notifications table. The status defaults to QUEUED.
+func (r *NotificationRepository) Insert(ctx context.Context, notification Notification) (int64, *errors.Type) {
+	q := `INSERT INTO notifications (ticket_id, issuer, recipient, subject, body, status, failure, importance_level,
+			event_type, created_at, modified_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+			RETURNING id;`
+
+	if notification.Status == "" {
+		notification.Status = NotificationStatusQueued
+	}
+
+	if notification.ImportanceLevel == "" {
+		notification.ImportanceLevel = TicketImportanceLevelMedium
+	}
+
+	var id int64
+	e := r.db.QueryRow(ctx, q, notification.TicketID, notification.Issuer, notification.Recipient,
+		notification.Subject, notification.Body, notification.Status, notification.Failure,
+		notification.ImportanceLevel, notification.EventType).Scan(&id)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return 0, et
+	}
+
+	return id, nil
+}
+
+// UpdateStatus tries to update the delivery status of a notification. Failure describes why the delivery failed and
+// should be empty otherwise.
+func (r *NotificationRepository) UpdateStatus(ctx context.Context, id int64, status NotificationStatus,
+	failure string) *errors.Type {
+
+	q := `UPDATE notifications SET status = $1, failure = $2, modified_at = NOW() WHERE id = $3;`
+
+	cmd, e := r.db.Exec(ctx, q, status, failure, id)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return errors.PreconditionFailed("notification.not_found", "")
+	}
+
+	return nil
+}
+
+// UpdateStatuses tries to update the delivery statuses of many notifications in a single round trip. Each
+// notification is updated to its own Status and Failure values.
+func (r *NotificationRepository) UpdateStatuses(ctx context.Context, notifications []*Notification) *errors.Type {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	q := `UPDATE notifications SET status = $1, failure = $2, modified_at = NOW() WHERE id = $3;`
+
+	batch := &pgx.Batch{}
+	for _, n := range notifications {
+		batch.Queue(q, n.Status, n.Failure, n.ID)
+	}
+
+	results := r.db.SendBatch(ctx, batch)
+
+	var e error
+	for range notifications {
+		if _, e = results.Exec(); e != nil {
+			break
+		}
+	}
+
+	if ce := results.Close(); e == nil {
+		e = ce
+	}
+
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// LoadByID tries to load a notification from notifications table.
+func (r *NotificationRepository) LoadByID(ctx context.Context, id int64) (*Notification, *errors.Type) {
+	q := `SELECT id, ticket_id, issuer, recipient, subject, body, status, COALESCE(failure, ''), importance_level,
+			event_type, created_at, modified_at FROM notifications WHERE id = $1;`
+
+	notifications, e := r.load(ctx, q, id)
+	if e != nil {
+		return nil, e
+	}
+
+	if len(notifications) == 0 {
+		return nil, errors.NotFound("notification.not_found", "")
+	}
+
+	return notifications[0], nil
+}
+
+// LoadByTicketID tries to load the notifications of a ticket, oldest first.
+func (r *NotificationRepository) LoadByTicketID(ctx context.Context, ticketID int64) ([]*Notification, *errors.Type) {
+	q := `SELECT id, ticket_id, issuer, recipient, subject, body, status, COALESCE(failure, ''), importance_level,
+			event_type, created_at, modified_at FROM notifications WHERE ticket_id = $1 ORDER BY created_at, id;`
+
+	return r.load(ctx, q, ticketID)
+}
+
+// LoadQueuedBefore tries to load the notifications that have been queued before the provided time, oldest first.
+func (r *NotificationRepository) LoadQueuedBefore(ctx context.Context, before time.Time) ([]*Notification,
+	*errors.Type) {
+
+	q := `SELECT id, ticket_id, issuer, recipient, subject, body, status, COALESCE(failure, ''), importance_level,
+			event_type, created_at, modified_at FROM notifications WHERE status = $1 AND created_at < $2
+			ORDER BY created_at, id;`
+
+	return r.load(ctx, q, NotificationStatusQueued, before.UTC())
+}
+
+// Notified checks whether the recipient has been notified about the same type of events of a ticket after the
+// provided time, no matter whether the notification got delivered.
+func (r *NotificationRepository) Notified(ctx context.Context, recipient string, ticketID int64, eventType string,
+	createdAfter time.Time) (bool, *errors.Type) {
+
+	q := `SELECT EXISTS (SELECT 1 FROM notifications WHERE recipient = $1 AND ticket_id = $2 AND event_type = $3
+			AND created_at > $4);`
+
+	var notified bool
+	if e := r.db.QueryRow(ctx, q, recipient, ticketID, eventType, createdAfter.UTC()).Scan(&notified); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return false, et
+	}
+
+	return notified, nil
+}
+
+func (r *NotificationRepository) load(ctx context.Context, q string, args ...interface{}) ([]*Notification,
+	*errors.Type) {
+
+	rows, e := r.db.Query(ctx, q, args...)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	notifications := make([]*Notification, 0)
+	for rows.Next() {
+		n := &Notification{}
+
+		e := rows.Scan(&n.ID, &n.TicketID, &n.Issuer, &n.Recipient, &n.Subject, &n.Body, &n.Status, &n.Failure,
+			&n.ImportanceLevel, &n.EventType, &n.CreatedAt, &n.ModifiedAt)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		notifications = append(notifications, n)
+	}
+
+	return notifications, nil
+}
+
+// NotificationStatus model.
+type NotificationStatus string
+
+// Different notification status instances.
+const (
+	NotificationStatusQueued  NotificationStatus = "QUEUED"
+	NotificationStatusSent    NotificationStatus = "SENT"
+	NotificationStatusFailed  NotificationStatus = "FAILED"
+	NotificationStatusBounced NotificationStatus = "BOUNCED"
+)
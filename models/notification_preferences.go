@@ -0,0 +1,245 @@
+package models
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// NotificationPreferences is the entity model of notification_preferences table. It holds how and when a recipient
+// likes to be notified.
+type NotificationPreferences struct {
+	Recipient       string
+	Channels        []NotificationChannel
+	Mode            NotificationMode
+	DigestFrequency DigestFrequency
+	// QuietHoursStart and QuietHoursEnd are HH:MM clock times in TimeZone, both empty means no quiet hours.
+	QuietHoursStart string
+	QuietHoursEnd   string
+	TimeZone        string
+}
+
+// DefaultNotificationPreferences returns back the preferences of recipients that have not customized anything.
+func DefaultNotificationPreferences(recipient string) *NotificationPreferences {
+	return &NotificationPreferences{
+		Recipient:       recipient,
+		Channels:        []NotificationChannel{NotificationChannelEmail},
+		Mode:            NotificationModeImmediate,
+		DigestFrequency: DigestFrequencyDaily,
+		TimeZone:        "UTC",
+	}
+}
+
+// Accepts checks whether the recipient likes to be notified through the provided channel.
+func (p *NotificationPreferences) Accepts(channel NotificationChannel) bool {
+	for _, c := range p.Channels {
+		if c == channel {
+			return true
+		}
+	}
+
+	return false
+}
+
+// InQuietHours checks whether the provided time falls into the quiet hours of the recipient. Quiet hours may span
+// midnight, e.g. 22:00 to 07:00.
+func (p *NotificationPreferences) InQuietHours(at time.Time) bool {
+	if p.QuietHoursStart == "" || p.QuietHoursEnd == "" {
+		return false
+	}
+
+	clock := at.In(p.location()).Format("15:04")
+	if p.QuietHoursStart <= p.QuietHoursEnd {
+		return clock >= p.QuietHoursStart && clock < p.QuietHoursEnd
+	}
+
+	return clock >= p.QuietHoursStart || clock < p.QuietHoursEnd
+}
+
+// DigestDue checks whether a digest of the recipient should be delivered at the provided time. Jobs delivering digests
+// are expected to run at the start of every hour, daily digests go out at DailyDigestHour in the recipient time zone.
+func (p *NotificationPreferences) DigestDue(at time.Time) bool {
+	if p.DigestFrequency == DigestFrequencyHourly {
+		return true
+	}
+
+	return at.In(p.location()).Hour() == DailyDigestHour
+}
+
+func (p *NotificationPreferences) location() *time.Location {
+	location, e := time.LoadLocation(p.TimeZone)
+	if e != nil {
+		return time.UTC
+	}
+
+	return location
+}
+
+// DailyDigestHour is the hour of day, in the recipient time zone, that daily digests are delivered at.
+const DailyDigestHour = 8
+
+// NotificationPreferencesRepository is the repository implementation of NotificationPreferences model.
+type NotificationPreferencesRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewNotificationPreferencesRepository returns back a newly created and ready to use
+// NotificationPreferencesRepository.
+func NewNotificationPreferencesRepository(logger *zap.SugaredLogger,
+	db *pgxpool.Pool) *NotificationPreferencesRepository {
+
+	return &NotificationPreferencesRepository{logger: logger, db: db}
+}
+
+// Save tries to insert the preferences of a recipient or update them if they already exist.
+func (r *NotificationPreferencesRepository) Save(ctx context.Context,
+	preferences NotificationPreferences) *errors.Type {
+
+	q := `INSERT INTO notification_preferences (recipient, channels, mode, digest_frequency, quiet_hours_start,
+			quiet_hours_end, time_zone, created_at, modified_at) VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+			ON CONFLICT (recipient) DO UPDATE SET channels = EXCLUDED.channels, mode = EXCLUDED.mode,
+			digest_frequency = EXCLUDED.digest_frequency, quiet_hours_start = EXCLUDED.quiet_hours_start,
+			quiet_hours_end = EXCLUDED.quiet_hours_end, time_zone = EXCLUDED.time_zone, modified_at = NOW();`
+
+	channels := make([]string, 0, len(preferences.Channels))
+	for _, channel := range preferences.Channels {
+		channels = append(channels, string(channel))
+	}
+
+	_, e := r.db.Exec(ctx, q, preferences.Recipient, strings.Join(channels, ","), preferences.Mode,
+		preferences.DigestFrequency, preferences.QuietHoursStart, preferences.QuietHoursEnd, preferences.TimeZone)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// LoadByRecipient tries to load the preferences of a recipient. Recipients without any stored preferences get the
+// defaults.
+func (r *NotificationPreferencesRepository) LoadByRecipient(ctx context.Context,
+	recipient string) (*NotificationPreferences, *errors.Type) {
+
+	q := `SELECT recipient, channels, mode, digest_frequency, quiet_hours_start, quiet_hours_end, time_zone
+			FROM notification_preferences WHERE recipient = $1;`
+
+	preferences, e := r.scan(r.db.QueryRow(ctx, q, recipient))
+	if e != nil {
+		if e == pgx.ErrNoRows {
+			return DefaultNotificationPreferences(recipient), nil
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	return preferences, nil
+}
+
+// Resolve tries to load the effective preferences of a recipient about the tickets of an issuer. Stored preferences
+// of the recipient win, otherwise the notification defaults of the issuer apply.
+func (r *NotificationPreferencesRepository) Resolve(ctx context.Context, recipient,
+	issuer string) (*NotificationPreferences, *errors.Type) {
+
+	q := `SELECT i.recipient, COALESCE(p.channels, $3), COALESCE(p.mode, NULLIF(s.notification_mode, ''), $4),
+			COALESCE(p.digest_frequency, NULLIF(s.digest_frequency, ''), $5), COALESCE(p.quiet_hours_start, ''),
+			COALESCE(p.quiet_hours_end, ''), COALESCE(p.time_zone, $6)
+			FROM (SELECT $1::VARCHAR AS recipient, $2::VARCHAR AS issuer) AS i
+			LEFT JOIN notification_preferences AS p ON p.recipient = i.recipient
+			LEFT JOIN issuer_settings AS s ON s.issuer = i.issuer;`
+
+	defaults := DefaultNotificationPreferences(recipient)
+	preferences, e := r.scan(r.db.QueryRow(ctx, q, recipient, issuer, NotificationChannelEmail, defaults.Mode,
+		defaults.DigestFrequency, defaults.TimeZone))
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	return preferences, nil
+}
+
+func (r *NotificationPreferencesRepository) scan(row pgx.Row) (*NotificationPreferences, error) {
+	preferences := &NotificationPreferences{}
+	var channels string
+
+	e := row.Scan(&preferences.Recipient, &channels, &preferences.Mode, &preferences.DigestFrequency,
+		&preferences.QuietHoursStart, &preferences.QuietHoursEnd, &preferences.TimeZone)
+	if e != nil {
+		return nil, e
+	}
+
+	preferences.Channels = make([]NotificationChannel, 0)
+	for _, channel := range strings.Split(channels, ",") {
+		if channel != "" {
+			preferences.Channels = append(preferences.Channels, NotificationChannel(channel))
+		}
+	}
+
+	return preferences, nil
+}
+
+// DeleteByRecipient tries to delete the preferences of a recipient, so the defaults apply again.
+func (r *NotificationPreferencesRepository) DeleteByRecipient(ctx context.Context, recipient string) *errors.Type {
+	q := `DELETE FROM notification_preferences WHERE recipient = $1;`
+
+	_, e := r.db.Exec(ctx, q, recipient)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// NotificationChannel model.
+type NotificationChannel string
+
+// Different notification channel instances.
+const (
+	NotificationChannelEmail NotificationChannel = "EMAIL"
+)
+
+// IsValid checks whether the channel is one of the defined instances.
+func (c NotificationChannel) IsValid() bool {
+	return c == NotificationChannelEmail
+}
+
+// NotificationMode model.
+type NotificationMode string
+
+// Different notification mode instances.
+const (
+	NotificationModeImmediate NotificationMode = "IMMEDIATE"
+	NotificationModeDigest    NotificationMode = "DIGEST"
+)
+
+// IsValid checks whether the mode is one of the defined instances.
+func (m NotificationMode) IsValid() bool {
+	return m == NotificationModeImmediate || m == NotificationModeDigest
+}
+
+// DigestFrequency model.
+type DigestFrequency string
+
+// Different digest frequency instances.
+const (
+	DigestFrequencyHourly DigestFrequency = "HOURLY"
+	DigestFrequencyDaily  DigestFrequency = "DAILY"
+)
+
+// IsValid checks whether the frequency is one of the defined instances.
+func (f DigestFrequency) IsValid() bool {
+	return f == DigestFrequencyHourly || f == DigestFrequencyDaily
+}
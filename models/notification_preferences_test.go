@@ -0,0 +1,144 @@
+package models_test
+
+import (
+	"context"
+	"time"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("NotificationPreferences", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.NotificationPreferencesRepository
+	var issuerSettingsRepository *models.IssuerSettingsRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewNotificationPreferencesRepository(zap.S(), db)
+			issuerSettingsRepository = models.NewIssuerSettingsRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	Describe("NotificationPreferencesRepository", func() {
+		Context("When LoadByRecipient called", func() {
+			It("Should return back the defaults for recipients without preferences", func() {
+				preferences, e := repository.LoadByRecipient(context.Background(), "user1@example.com")
+				Ω(e).Should(BeNil())
+				Ω(preferences).Should(Equal(models.DefaultNotificationPreferences("user1@example.com")))
+			})
+		})
+
+		Context("When Save and DeleteByRecipient called", func() {
+			It("Should store, update and then delete the preferences of a recipient", func() {
+				preferences := models.NotificationPreferences{
+					Recipient:       "user1@example.com",
+					Channels:        []models.NotificationChannel{models.NotificationChannelEmail},
+					Mode:            models.NotificationModeDigest,
+					DigestFrequency: models.DigestFrequencyHourly,
+					QuietHoursStart: "22:00",
+					QuietHoursEnd:   "07:00",
+					TimeZone:        "UTC",
+				}
+
+				e := repository.Save(context.Background(), preferences)
+				Ω(e).Should(BeNil())
+
+				preferences.Channels = []models.NotificationChannel{}
+				e = repository.Save(context.Background(), preferences)
+				Ω(e).Should(BeNil())
+
+				loaded, e := repository.LoadByRecipient(context.Background(), "user1@example.com")
+				Ω(e).Should(BeNil())
+				Ω(*loaded).Should(Equal(preferences))
+				Ω(loaded.Accepts(models.NotificationChannelEmail)).Should(BeFalse())
+
+				e = repository.DeleteByRecipient(context.Background(), "user1@example.com")
+				Ω(e).Should(BeNil())
+
+				loaded, e = repository.LoadByRecipient(context.Background(), "user1@example.com")
+				Ω(e).Should(BeNil())
+				Ω(loaded).Should(Equal(models.DefaultNotificationPreferences("user1@example.com")))
+			})
+		})
+
+		Context("When Resolve called", func() {
+			It("Should prefer recipient preferences over the defaults of the issuer", func() {
+				settings := *models.DefaultIssuerSettings("Microservice-A")
+				settings.NotificationMode = models.NotificationModeDigest
+				settings.DigestFrequency = models.DigestFrequencyHourly
+				e := issuerSettingsRepository.Save(context.Background(), settings)
+				Ω(e).Should(BeNil())
+
+				resolved, e := repository.Resolve(context.Background(), "user1@example.com", "Microservice-A")
+				Ω(e).Should(BeNil())
+				Ω(resolved.Mode).Should(Equal(models.NotificationModeDigest))
+				Ω(resolved.DigestFrequency).Should(Equal(models.DigestFrequencyHourly))
+				Ω(resolved.Channels).Should(Equal([]models.NotificationChannel{models.NotificationChannelEmail}))
+
+				resolved, e = repository.Resolve(context.Background(), "user1@example.com", "Microservice-B")
+				Ω(e).Should(BeNil())
+				Ω(resolved).Should(Equal(models.DefaultNotificationPreferences("user1@example.com")))
+
+				preferences := *models.DefaultNotificationPreferences("user1@example.com")
+				e = repository.Save(context.Background(), preferences)
+				Ω(e).Should(BeNil())
+
+				resolved, e = repository.Resolve(context.Background(), "user1@example.com", "Microservice-A")
+				Ω(e).Should(BeNil())
+				Ω(*resolved).Should(Equal(preferences))
+			})
+		})
+	})
+
+	Describe("NotificationPreferences", func() {
+		Context("When InQuietHours called", func() {
+			It("Should handle quiet hours spanning midnight", func() {
+				preferences := models.NotificationPreferences{QuietHoursStart: "22:00", QuietHoursEnd: "07:00",
+					TimeZone: "UTC"}
+
+				Ω(preferences.InQuietHours(time.Date(2020, 1, 1, 23, 30, 0, 0, time.UTC))).Should(BeTrue())
+				Ω(preferences.InQuietHours(time.Date(2020, 1, 1, 6, 59, 0, 0, time.UTC))).Should(BeTrue())
+				Ω(preferences.InQuietHours(time.Date(2020, 1, 1, 7, 0, 0, 0, time.UTC))).Should(BeFalse())
+				Ω(preferences.InQuietHours(time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC))).Should(BeFalse())
+			})
+		})
+
+		Context("When DigestDue called", func() {
+			It("Should be due every hour for hourly digests and once a day for daily digests", func() {
+				preferences := models.NotificationPreferences{DigestFrequency: models.DigestFrequencyHourly,
+					TimeZone: "UTC"}
+				Ω(preferences.DigestDue(time.Date(2020, 1, 1, 13, 0, 0, 0, time.UTC))).Should(BeTrue())
+
+				preferences.DigestFrequency = models.DigestFrequencyDaily
+				Ω(preferences.DigestDue(time.Date(2020, 1, 1, 13, 0, 0, 0, time.UTC))).Should(BeFalse())
+				Ω(preferences.DigestDue(time.Date(2020, 1, 1, models.DailyDigestHour, 0, 0, 0, time.UTC))).
+					Should(BeTrue())
+			})
+		})
+	})
+})
@@ -0,0 +1,156 @@
+package models_test
+
+import (
+	"context"
+	"time"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Notification", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.NotificationRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewNotificationRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	Describe("NotificationRepository", func() {
+		Context("When Insert and UpdateStatus called", func() {
+			It("Should track the delivery status of notifications per ticket", func() {
+				notification := models.Notification{
+					TicketID:  1,
+					Issuer:    "Microservice-A",
+					Recipient: "user1@example.com",
+					Subject:   "Ticket #1 received: Technical Problem",
+					Body:      "We have received your ticket #1 and will get back to you soon.",
+				}
+
+				sent, e := repository.Insert(context.Background(), notification)
+				Ω(e).Should(BeNil())
+
+				queued, e := repository.Insert(context.Background(), notification)
+				Ω(e).Should(BeNil())
+
+				e = repository.UpdateStatus(context.Background(), sent, models.NotificationStatusSent, "")
+				Ω(e).Should(BeNil())
+
+				notifications, e := repository.LoadByTicketID(context.Background(), 1)
+				Ω(e).Should(BeNil())
+				Ω(notifications).Should(HaveLen(2))
+				Ω(notifications[0].ID).Should(Equal(sent))
+				Ω(notifications[0].Status).Should(Equal(models.NotificationStatusSent))
+				Ω(notifications[1].ID).Should(Equal(queued))
+				Ω(notifications[1].Status).Should(Equal(models.NotificationStatusQueued))
+
+				notifications, e = repository.LoadQueuedBefore(context.Background(), time.Now().Add(time.Minute))
+				Ω(e).Should(BeNil())
+				Ω(notifications).Should(HaveLen(1))
+				Ω(notifications[0].ID).Should(Equal(queued))
+
+				e = repository.UpdateStatus(context.Background(), queued, models.NotificationStatusBounced, "550")
+				Ω(e).Should(BeNil())
+
+				notifications, e = repository.LoadByTicketID(context.Background(), 1)
+				Ω(e).Should(BeNil())
+				Ω(notifications[1].Status).Should(Equal(models.NotificationStatusBounced))
+				Ω(notifications[1].Failure).Should(Equal("550"))
+			})
+		})
+
+		Context("When UpdateStatuses called", func() {
+			It("Should update each notification to its own status in one go", func() {
+				notification := models.Notification{TicketID: 1, Issuer: "Microservice-A",
+					Recipient: "user1@example.com", Subject: "New reply on ticket #1", Body: "Still on it."}
+
+				first, e := repository.Insert(context.Background(), notification)
+				Ω(e).Should(BeNil())
+
+				second, e := repository.Insert(context.Background(), notification)
+				Ω(e).Should(BeNil())
+
+				e = repository.UpdateStatuses(context.Background(), []*models.Notification{
+					{Model: models.Model{ID: first}, Status: models.NotificationStatusSent},
+					{Model: models.Model{ID: second}, Status: models.NotificationStatusFailed, Failure: "timeout"},
+				})
+				Ω(e).Should(BeNil())
+
+				notifications, e := repository.LoadByTicketID(context.Background(), 1)
+				Ω(e).Should(BeNil())
+				Ω(notifications[0].Status).Should(Equal(models.NotificationStatusSent))
+				Ω(notifications[1].Status).Should(Equal(models.NotificationStatusFailed))
+				Ω(notifications[1].Failure).Should(Equal("timeout"))
+			})
+		})
+
+		Context("When Notified called", func() {
+			It("Should tell whether the recipient got notified about the same events of the ticket lately", func() {
+				notification := models.Notification{TicketID: 1, Issuer: "Microservice-A",
+					Recipient: "user1@example.com", Subject: "Ticket #1 is IN_PROGRESS", Body: "Still on it.",
+					EventType: "TICKET_UPDATED"}
+
+				_, e := repository.Insert(context.Background(), notification)
+				Ω(e).Should(BeNil())
+
+				notified, e := repository.Notified(context.Background(), "user1@example.com", 1, "TICKET_UPDATED",
+					time.Now().Add(-time.Minute))
+				Ω(e).Should(BeNil())
+				Ω(notified).Should(BeTrue())
+
+				notified, e = repository.Notified(context.Background(), "user1@example.com", 1, "TICKET_UPDATED",
+					time.Now().Add(time.Minute))
+				Ω(e).Should(BeNil())
+				Ω(notified).Should(BeFalse())
+
+				notified, e = repository.Notified(context.Background(), "user1@example.com", 1, "COMMENT_CREATED",
+					time.Now().Add(-time.Minute))
+				Ω(e).Should(BeNil())
+				Ω(notified).Should(BeFalse())
+
+				notified, e = repository.Notified(context.Background(), "user2@example.com", 1, "TICKET_UPDATED",
+					time.Now().Add(-time.Minute))
+				Ω(e).Should(BeNil())
+				Ω(notified).Should(BeFalse())
+
+				notifications, e := repository.LoadByTicketID(context.Background(), 1)
+				Ω(e).Should(BeNil())
+				Ω(notifications[0].EventType).Should(Equal("TICKET_UPDATED"))
+			})
+		})
+
+		Context("When UpdateStatus called for a missing notification", func() {
+			It("Should return precondition failed error", func() {
+				e := repository.UpdateStatus(context.Background(), 1, models.NotificationStatusSent, "")
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("notification.not_found"))
+			})
+		})
+	})
+})
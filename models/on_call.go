@@ -0,0 +1,113 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// OnCallShift is the entity model of on_call_shifts table. It is a shift of the built-in rota of an issuer, Agent is on
+// call from StartsAt until EndsAt.
+type OnCallShift struct {
+	ID        int64
+	Issuer    string
+	Agent     string
+	StartsAt  time.Time
+	EndsAt    time.Time
+	CreatedAt time.Time
+}
+
+// OnCallRepository is the repository implementation of OnCallShift model.
+type OnCallRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewOnCallRepository returns back a newly created and ready to use OnCallRepository.
+func NewOnCallRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *OnCallRepository {
+	return &OnCallRepository{logger: logger, db: db}
+}
+
+// Insert tries to insert a shift into the rota of its issuer.
+func (r *OnCallRepository) Insert(ctx context.Context, shift OnCallShift) (int64, *errors.Type) {
+	q := `INSERT INTO on_call_shifts (issuer, agent, starts_at, ends_at, created_at) VALUES ($1, $2, $3, $4, NOW())
+			RETURNING id;`
+
+	var id int64
+	e := r.db.QueryRow(ctx, q, shift.Issuer, shift.Agent, shift.StartsAt.UTC(), shift.EndsAt.UTC()).Scan(&id)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return 0, et
+	}
+
+	return id, nil
+}
+
+// LoadByIssuer tries to load the shifts of an issuer not ended by the provided time, the earliest first.
+func (r *OnCallRepository) LoadByIssuer(ctx context.Context, issuer string, from time.Time) ([]*OnCallShift,
+	*errors.Type) {
+
+	q := `SELECT id, issuer, agent, starts_at, ends_at, created_at FROM on_call_shifts
+			WHERE issuer = $1 AND ends_at > $2 ORDER BY starts_at, id;`
+
+	rows, e := r.db.Query(ctx, q, issuer, from.UTC())
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	shifts := make([]*OnCallShift, 0)
+	for rows.Next() {
+		shift := &OnCallShift{}
+		e := rows.Scan(&shift.ID, &shift.Issuer, &shift.Agent, &shift.StartsAt, &shift.EndsAt, &shift.CreatedAt)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		shifts = append(shifts, shift)
+	}
+
+	return shifts, nil
+}
+
+// Current tries to load the agent on call for an issuer at the provided time, it is empty when no shift covers it.
+// Overlapping shifts are resolved in favor of the most recently started one, e.g. a swap covering part of a shift.
+func (r *OnCallRepository) Current(ctx context.Context, issuer string, at time.Time) (string, *errors.Type) {
+	q := `SELECT agent FROM on_call_shifts WHERE issuer = $1 AND starts_at <= $2 AND ends_at > $2
+			ORDER BY starts_at DESC, id DESC LIMIT 1;`
+
+	var agent string
+	if e := r.db.QueryRow(ctx, q, issuer, at.UTC()).Scan(&agent); e != nil {
+		if e == pgx.ErrNoRows {
+			return "", nil
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return "", et
+	}
+
+	return agent, nil
+}
+
+// Delete tries to delete a shift from the rota of its issuer.
+func (r *OnCallRepository) Delete(ctx context.Context, issuer string, id int64) *errors.Type {
+	q := `DELETE FROM on_call_shifts WHERE issuer = $1 AND id = $2;`
+
+	if _, e := r.db.Exec(ctx, q, issuer, id); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package models_test
+
+import (
+	"context"
+	"time"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("OnCall", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.OnCallRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewOnCallRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	Describe("OnCallRepository", func() {
+		Context("When Insert, LoadByIssuer, Current and Delete called", func() {
+			It("Should pick the most recently started shift covering the time", func() {
+				now := time.Now().UTC().Truncate(time.Second)
+
+				_, e := repository.Insert(context.Background(), models.OnCallShift{Issuer: "Microservice-A",
+					Agent: "Agent-A", StartsAt: now.Add(-4 * time.Hour), EndsAt: now.Add(-time.Hour)})
+				Ω(e).Should(BeNil())
+
+				_, e = repository.Insert(context.Background(), models.OnCallShift{Issuer: "Microservice-A",
+					Agent: "Agent-B", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(8 * time.Hour)})
+				Ω(e).Should(BeNil())
+
+				swap, e := repository.Insert(context.Background(), models.OnCallShift{Issuer: "Microservice-A",
+					Agent: "Agent-C", StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour)})
+				Ω(e).Should(BeNil())
+
+				shifts, e := repository.LoadByIssuer(context.Background(), "Microservice-A", now)
+				Ω(e).Should(BeNil())
+				Ω(shifts).Should(HaveLen(2))
+				Ω(shifts[0].Agent).Should(Equal("Agent-B"))
+				Ω(shifts[1].ID).Should(Equal(swap))
+
+				agent, e := repository.Current(context.Background(), "Microservice-A", now)
+				Ω(e).Should(BeNil())
+				Ω(agent).Should(Equal("Agent-C"))
+
+				agent, e = repository.Current(context.Background(), "Microservice-B", now)
+				Ω(e).Should(BeNil())
+				Ω(agent).Should(BeEmpty())
+
+				e = repository.Delete(context.Background(), "Microservice-A", swap)
+				Ω(e).Should(BeNil())
+
+				agent, e = repository.Current(context.Background(), "Microservice-A", now)
+				Ω(e).Should(BeNil())
+				Ω(agent).Should(Equal("Agent-B"))
+			})
+		})
+	})
+})
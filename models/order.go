@@ -0,0 +1,123 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/jibitters/kiosk/db/filters"
+)
+
+// Order is a key listed records are ordered by, e.g. the creation time of tickets, newest first.
+type Order struct {
+	Field      string `json:"field"`
+	Descending bool   `json:"descending"`
+}
+
+// TicketOrderColumns whitelists the fields tickets can be ordered by, along with their columns. Each column is backed
+// by an index.
+var TicketOrderColumns = map[string]string{
+	"id":                 "id",
+	"createdAt":          "created_at",
+	"modifiedAt":         "modified_at",
+	"resolvedAt":         "resolved_at",
+	"firstResponseDueAt": "first_response_due_at",
+	"resolutionDueAt":    "resolution_due_at",
+}
+
+// CommentOrderColumns whitelists the fields comments can be ordered by, along with their columns.
+var CommentOrderColumns = map[string]string{
+	"id":         "id",
+	"createdAt":  "created_at",
+	"modifiedAt": "modified_at",
+}
+
+// Cursor is the position of a record among ordered records, i.e. its values of the keys they are ordered by, followed
+// by its id unless ordered by id already. Values are in their text form, NULL values are nil.
+type Cursor []*string
+
+// key returns back the value of cursors of records ordered by a single unique key, or nil when there is no cursor.
+func (c Cursor) key() *string {
+	if len(c) != 1 {
+		return nil
+	}
+
+	return c[0]
+}
+
+// intCursor returns back the cursor of records ordered by a single unique integer key, e.g. their ids.
+func intCursor(key int64) Cursor {
+	value := strconv.FormatInt(key, 10)
+	return Cursor{&value}
+}
+
+// orderKey is a column records are ordered by.
+type orderKey struct {
+	column     string
+	descending bool
+}
+
+// orderKeys returns back the keys of orders, or of defaults when orders are empty. Fields missing from columns are
+// skipped, so orders must be validated beforehand. Ties are broken by id, in the direction of the last key, so pages
+// stay stable.
+func orderKeys(orders []Order, defaults []Order, columns map[string]string) []orderKey {
+	if len(orders) == 0 {
+		orders = defaults
+	}
+
+	keys := make([]orderKey, 0, len(orders)+1)
+	descending, hasID := false, false
+	for _, order := range orders {
+		column, ok := columns[order.Field]
+		if !ok {
+			continue
+		}
+
+		descending, hasID = order.Descending, hasID || column == "id"
+		keys = append(keys, orderKey{column: column, descending: descending})
+	}
+
+	if !hasID {
+		keys = append(keys, orderKey{column: "id", descending: descending})
+	}
+
+	return keys
+}
+
+// orderClause builds the ORDER BY clause of orders, or of defaults when orders are empty.
+func orderClause(orders []Order, defaults []Order, columns map[string]string) string {
+	keys := orderKeys(orders, defaults, columns)
+
+	clauses := make([]string, 0, len(keys))
+	for _, key := range keys {
+		clauses = append(clauses, direction(key.column, key.descending))
+	}
+
+	return ` ORDER BY ` + strings.Join(clauses, `, `)
+}
+
+// keysetAfter returns back the keys of records coming after the cursor, in the order of orders, or of defaults when
+// orders are empty. Cursors not matching the keys return back no key at all, which no record comes after.
+func keysetAfter(cursor Cursor, orders []Order, defaults []Order, columns map[string]string) []filters.Key {
+	keys := orderKeys(orders, defaults, columns)
+	if len(keys) != len(cursor) {
+		return nil
+	}
+
+	after := make([]filters.Key, 0, len(keys))
+	for i, key := range keys {
+		after = append(after, filters.Key{Column: key.column, Descending: key.descending})
+		if cursor[i] != nil {
+			after[i].Value = *cursor[i]
+		}
+	}
+
+	return after
+}
+
+func direction(column string, descending bool) string {
+	if descending {
+		return column + ` DESC`
+	}
+
+	return column
+}
@@ -0,0 +1,233 @@
+package models
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// PagingPolicy is the entity model of paging_policies table. It holds how and when the on-call staff of an issuer gets
+// paged, critical tickets arriving outside the business hours of the issuer page them.
+type PagingPolicy struct {
+	Issuer   string
+	Provider PagingProvider
+	// RoutingKey is the integration key of a PagerDuty service or the API key of an Opsgenie integration.
+	RoutingKey string
+	// BusinessHoursStart and BusinessHoursEnd are HH:MM clock times in TimeZone.
+	BusinessHoursStart string
+	BusinessHoursEnd   string
+	BusinessDays       []time.Weekday
+	TimeZone           string
+}
+
+// InBusinessHours checks whether the provided time falls into the business hours of the issuer. Business hours may
+// span midnight, in which case they belong to the day they start on.
+func (p *PagingPolicy) InBusinessHours(at time.Time) bool {
+	local := at.In(p.location())
+	clock := local.Format("15:04")
+
+	day := local.Weekday()
+	inHours := clock >= p.BusinessHoursStart && clock < p.BusinessHoursEnd
+	if p.BusinessHoursStart > p.BusinessHoursEnd {
+		inHours = clock >= p.BusinessHoursStart || clock < p.BusinessHoursEnd
+		if clock < p.BusinessHoursEnd {
+			day = local.AddDate(0, 0, -1).Weekday()
+		}
+	}
+
+	return inHours && p.isBusinessDay(day)
+}
+
+func (p *PagingPolicy) isBusinessDay(day time.Weekday) bool {
+	for _, d := range p.BusinessDays {
+		if d == day {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *PagingPolicy) location() *time.Location {
+	location, e := time.LoadLocation(p.TimeZone)
+	if e != nil {
+		return time.UTC
+	}
+
+	return location
+}
+
+// PagingPolicyRepository is the repository implementation of PagingPolicy model.
+type PagingPolicyRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewPagingPolicyRepository returns back a newly created and ready to use PagingPolicyRepository.
+func NewPagingPolicyRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *PagingPolicyRepository {
+	return &PagingPolicyRepository{logger: logger, db: db}
+}
+
+// Save tries to insert the paging policy of an issuer or update it if it already exists.
+func (r *PagingPolicyRepository) Save(ctx context.Context, policy PagingPolicy) *errors.Type {
+	q := `INSERT INTO paging_policies (issuer, provider, routing_key, business_hours_start, business_hours_end,
+			business_days, time_zone, created_at, modified_at) VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+			ON CONFLICT (issuer) DO UPDATE SET provider = EXCLUDED.provider, routing_key = EXCLUDED.routing_key,
+			business_hours_start = EXCLUDED.business_hours_start, business_hours_end = EXCLUDED.business_hours_end,
+			business_days = EXCLUDED.business_days, time_zone = EXCLUDED.time_zone, modified_at = NOW();`
+
+	days := make([]string, 0, len(policy.BusinessDays))
+	for _, day := range policy.BusinessDays {
+		days = append(days, strconv.Itoa(int(day)))
+	}
+
+	_, e := r.db.Exec(ctx, q, policy.Issuer, policy.Provider, policy.RoutingKey, policy.BusinessHoursStart,
+		policy.BusinessHoursEnd, strings.Join(days, ","), policy.TimeZone)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// LoadByIssuer tries to load the paging policy of an issuer.
+func (r *PagingPolicyRepository) LoadByIssuer(ctx context.Context, issuer string) (*PagingPolicy, *errors.Type) {
+	q := `SELECT issuer, provider, routing_key, business_hours_start, business_hours_end, business_days, time_zone
+			FROM paging_policies WHERE issuer = $1;`
+
+	policy := &PagingPolicy{}
+	var days string
+
+	e := r.db.QueryRow(ctx, q, issuer).Scan(&policy.Issuer, &policy.Provider, &policy.RoutingKey,
+		&policy.BusinessHoursStart, &policy.BusinessHoursEnd, &days, &policy.TimeZone)
+	if e != nil {
+		if e == pgx.ErrNoRows {
+			return nil, errors.NotFound("paging_policy.not_found", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	policy.BusinessDays = make([]time.Weekday, 0)
+	for _, day := range strings.Split(days, ",") {
+		if d, e := strconv.Atoi(day); e == nil {
+			policy.BusinessDays = append(policy.BusinessDays, time.Weekday(d))
+		}
+	}
+
+	return policy, nil
+}
+
+// DeleteByIssuer tries to delete the paging policy of an issuer, so its staff does not get paged anymore.
+func (r *PagingPolicyRepository) DeleteByIssuer(ctx context.Context, issuer string) *errors.Type {
+	q := `DELETE FROM paging_policies WHERE issuer = $1;`
+
+	if _, e := r.db.Exec(ctx, q, issuer); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// TicketPage is the entity model of ticket_pages table. It is the incident opened for a ticket.
+type TicketPage struct {
+	TicketID   int64
+	Provider   PagingProvider
+	RoutingKey string
+	// DedupKey identifies the incident at the provider.
+	DedupKey   string
+	PagedAt    time.Time
+	ResolvedAt *time.Time
+}
+
+// TicketPageRepository is the repository implementation of TicketPage model.
+type TicketPageRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewTicketPageRepository returns back a newly created and ready to use TicketPageRepository.
+func NewTicketPageRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *TicketPageRepository {
+	return &TicketPageRepository{logger: logger, db: db}
+}
+
+// Insert tries to record the page of a ticket. Tickets are paged at most once, so recording another page of the same
+// ticket has no effect.
+func (r *TicketPageRepository) Insert(ctx context.Context, page TicketPage) *errors.Type {
+	q := `INSERT INTO ticket_pages (ticket_id, provider, routing_key, dedup_key, paged_at) VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (ticket_id) DO NOTHING;`
+
+	_, e := r.db.Exec(ctx, q, page.TicketID, page.Provider, page.RoutingKey, page.DedupKey, page.PagedAt.UTC())
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// LoadByTicketID tries to load the page of a ticket, it is nil when the ticket was never paged.
+func (r *TicketPageRepository) LoadByTicketID(ctx context.Context, ticketID int64) (*TicketPage, *errors.Type) {
+	q := `SELECT ticket_id, provider, routing_key, dedup_key, paged_at, resolved_at FROM ticket_pages
+			WHERE ticket_id = $1;`
+
+	page := &TicketPage{}
+	e := r.db.QueryRow(ctx, q, ticketID).Scan(&page.TicketID, &page.Provider, &page.RoutingKey, &page.DedupKey,
+		&page.PagedAt, &page.ResolvedAt)
+	if e != nil {
+		if e == pgx.ErrNoRows {
+			return nil, nil
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	return page, nil
+}
+
+// MarkResolved tries to record that the incident of a ticket got resolved.
+func (r *TicketPageRepository) MarkResolved(ctx context.Context, ticketID int64, resolvedAt time.Time) *errors.Type {
+	q := `UPDATE ticket_pages SET resolved_at = $1 WHERE ticket_id = $2 AND resolved_at IS NULL;`
+
+	if _, e := r.db.Exec(ctx, q, resolvedAt.UTC(), ticketID); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// PagingProvider model.
+type PagingProvider string
+
+// Different paging provider instances.
+const (
+	PagingProviderPagerDuty PagingProvider = "PAGERDUTY"
+	PagingProviderOpsgenie  PagingProvider = "OPSGENIE"
+)
+
+// IsValid reports whether the provider is one of the known providers.
+func (p PagingProvider) IsValid() bool {
+	switch p {
+	case PagingProviderPagerDuty, PagingProviderOpsgenie:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,141 @@
+package models_test
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Paging", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.PagingPolicyRepository
+	var pageRepository *models.TicketPageRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewPagingPolicyRepository(zap.S(), db)
+			pageRepository = models.NewTicketPageRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	Describe("PagingPolicyRepository", func() {
+		Context("When Save, LoadByIssuer and DeleteByIssuer called", func() {
+			It("Should store, update and then delete the policy of an issuer", func() {
+				_, e := repository.LoadByIssuer(context.Background(), "Microservice-A")
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusNotFound))
+
+				policy := models.PagingPolicy{
+					Issuer:             "Microservice-A",
+					Provider:           models.PagingProviderPagerDuty,
+					RoutingKey:         "R0UT1NGK3Y",
+					BusinessHoursStart: "09:00",
+					BusinessHoursEnd:   "17:00",
+					BusinessDays:       []time.Weekday{time.Monday, time.Tuesday},
+					TimeZone:           "UTC",
+				}
+
+				e = repository.Save(context.Background(), policy)
+				Ω(e).Should(BeNil())
+
+				policy.Provider = models.PagingProviderOpsgenie
+				e = repository.Save(context.Background(), policy)
+				Ω(e).Should(BeNil())
+
+				loaded, e := repository.LoadByIssuer(context.Background(), "Microservice-A")
+				Ω(e).Should(BeNil())
+				Ω(*loaded).Should(Equal(policy))
+
+				e = repository.DeleteByIssuer(context.Background(), "Microservice-A")
+				Ω(e).Should(BeNil())
+
+				_, e = repository.LoadByIssuer(context.Background(), "Microservice-A")
+				Ω(e).ShouldNot(BeNil())
+			})
+		})
+	})
+
+	Describe("TicketPageRepository", func() {
+		Context("When Insert and MarkResolved called", func() {
+			It("Should record a single page per ticket and its resolution", func() {
+				page, e := pageRepository.LoadByTicketID(context.Background(), 1)
+				Ω(e).Should(BeNil())
+				Ω(page).Should(BeNil())
+
+				e = pageRepository.Insert(context.Background(), models.TicketPage{TicketID: 1,
+					Provider: models.PagingProviderPagerDuty, RoutingKey: "R0UT1NGK3Y", DedupKey: "kiosk-ticket-1",
+					PagedAt: time.Now()})
+				Ω(e).Should(BeNil())
+
+				e = pageRepository.Insert(context.Background(), models.TicketPage{TicketID: 1,
+					Provider: models.PagingProviderOpsgenie, RoutingKey: "R0UT1NGK3Y", DedupKey: "kiosk-ticket-1",
+					PagedAt: time.Now()})
+				Ω(e).Should(BeNil())
+
+				page, e = pageRepository.LoadByTicketID(context.Background(), 1)
+				Ω(e).Should(BeNil())
+				Ω(page.Provider).Should(Equal(models.PagingProviderPagerDuty))
+				Ω(page.ResolvedAt).Should(BeNil())
+
+				e = pageRepository.MarkResolved(context.Background(), 1, time.Now())
+				Ω(e).Should(BeNil())
+
+				page, e = pageRepository.LoadByTicketID(context.Background(), 1)
+				Ω(e).Should(BeNil())
+				Ω(page.ResolvedAt).ShouldNot(BeNil())
+			})
+		})
+	})
+
+	Describe("PagingPolicy", func() {
+		Context("When InBusinessHours called", func() {
+			It("Should check both the clock and the day in the time zone of the issuer", func() {
+				policy := &models.PagingPolicy{BusinessHoursStart: "09:00", BusinessHoursEnd: "17:00",
+					BusinessDays: []time.Weekday{time.Monday}, TimeZone: "Asia/Tehran"}
+
+				// Monday 10:30 in Tehran.
+				Ω(policy.InBusinessHours(time.Date(2020, 11, 2, 7, 0, 0, 0, time.UTC))).Should(BeTrue())
+				// Monday 20:30 in Tehran.
+				Ω(policy.InBusinessHours(time.Date(2020, 11, 2, 17, 0, 0, 0, time.UTC))).Should(BeFalse())
+				// Tuesday 10:30 in Tehran.
+				Ω(policy.InBusinessHours(time.Date(2020, 11, 3, 7, 0, 0, 0, time.UTC))).Should(BeFalse())
+			})
+
+			It("Should attribute hours spanning midnight to the day they start on", func() {
+				policy := &models.PagingPolicy{BusinessHoursStart: "22:00", BusinessHoursEnd: "06:00",
+					BusinessDays: []time.Weekday{time.Monday}, TimeZone: "UTC"}
+
+				Ω(policy.InBusinessHours(time.Date(2020, 11, 2, 23, 0, 0, 0, time.UTC))).Should(BeTrue())
+				Ω(policy.InBusinessHours(time.Date(2020, 11, 3, 2, 0, 0, 0, time.UTC))).Should(BeTrue())
+				Ω(policy.InBusinessHours(time.Date(2020, 11, 2, 2, 0, 0, 0, time.UTC))).Should(BeFalse())
+			})
+		})
+	})
+})
@@ -0,0 +1,27 @@
+package models
+
+import (
+	stderrors "errors"
+
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// queryCanceled is the SQLSTATE of statements canceled by postgres, e.g. by their statement timeout.
+const queryCanceled = "57014"
+
+// queryFailed returns back the error of a failed query. Statements that exceeded the statement timeout of their query
+// class are reported as deadline exceeded, with guidance to narrow the request down, other failures are internal.
+func queryFailed(logger *zap.SugaredLogger, e error) *errors.Type {
+	var pgError interface{ SQLState() string }
+	if stderrors.As(e, &pgError) && pgError.SQLState() == queryCanceled {
+		et := errors.DeadlineExceeded("query.timeout",
+			"The query took too long, narrow the filters or the date range down and try again.")
+		logger.Warn(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	et := errors.InternalServerError("unknown", "")
+	logger.Error(et.FingerPrint, ": ", e.Error())
+	return et
+}
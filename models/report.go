@@ -0,0 +1,407 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// DailyReportRow is a row of daily report that holds the number of tickets created in a day per issuer, importance
+// level and status.
+type DailyReportRow struct {
+	Day             time.Time
+	Issuer          string
+	ImportanceLevel TicketImportanceLevel
+	Status          TicketStatus
+	Count           int64
+}
+
+// ReportRepository is the repository implementation of reporting queries.
+type ReportRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewReportRepository returns back a newly created and ready to use ReportRepository.
+func NewReportRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *ReportRepository {
+	return &ReportRepository{logger: logger, db: db}
+}
+
+// Daily reads the number of tickets created between from and to dates per day, issuer, importance level and status
+// off the daily ticket counters, so days are reported whole, i.e. from the day of from date until to date. If issuer
+// is not empty only the tickets of that issuer are reported.
+func (r *ReportRepository) Daily(ctx context.Context, issuer, fromDate, toDate string) ([]*DailyReportRow,
+	*errors.Type) {
+
+	q := `SELECT day, issuer, importance_level, status, count FROM daily_ticket_counters
+			WHERE day >= DATE_TRUNC('day', $1::TIMESTAMP) AND day < $2::TIMESTAMP AND ($3 = '' OR issuer = $3)
+			AND count > 0 ORDER BY day, issuer, importance_level, status;`
+
+	rows, e := r.db.Query(ctx, q, fromDate, toDate, issuer)
+	if e != nil {
+		return nil, queryFailed(r.logger, e)
+	}
+	defer rows.Close()
+
+	report := make([]*DailyReportRow, 0)
+	for rows.Next() {
+		row := &DailyReportRow{}
+
+		e := rows.Scan(&row.Day, &row.Issuer, &row.ImportanceLevel, &row.Status, &row.Count)
+		if e != nil {
+			return nil, queryFailed(r.logger, e)
+		}
+
+		report = append(report, row)
+	}
+
+	return report, nil
+}
+
+// AgentMetrics holds the activity of an agent in a period. Resolutions are the status changes to RESOLVED made by the
+// agent, and a resolution counts as reopened once its ticket leaves RESOLVED for any status but CLOSED.
+type AgentMetrics struct {
+	Agent             string
+	TicketsResolved   int64
+	AverageHandleTime time.Duration
+	RepliesSent       int64
+	TicketsReopened   int64
+}
+
+// ReopenRate returns back the ratio of the resolutions of the agent that got reopened.
+func (m *AgentMetrics) ReopenRate() float64 {
+	if m.TicketsResolved == 0 {
+		return 0
+	}
+
+	return float64(m.TicketsReopened) / float64(m.TicketsResolved)
+}
+
+// AgentMetrics computes the activity of agents between from and to dates. The handle time of a resolution is the time
+// from the creation of its ticket until it got resolved, and replies are the comments of agents. If issuer is not
+// empty only the tickets of that issuer are considered, and if agent is not empty only that agent is reported.
+func (r *ReportRepository) AgentMetrics(ctx context.Context, issuer, agent, fromDate, toDate string) ([]*AgentMetrics,
+	*errors.Type) {
+
+	q := `WITH resolutions AS (
+				SELECT c.actor, c.changed_at - t.created_at AS handle_time, EXISTS (
+					SELECT 1 FROM ticket_status_changes AS r WHERE r.ticket_id = c.ticket_id
+					AND r.changed_at > c.changed_at AND r.previous_status = $5 AND r.status <> $6
+				) AS reopened
+				FROM ticket_status_changes AS c JOIN tickets AS t ON t.id = c.ticket_id
+				WHERE c.status = $5 AND c.actor IS NOT NULL AND c.changed_at >= $1 AND c.changed_at < $2
+				AND ($3 = '' OR c.issuer = $3) AND ($4 = '' OR c.actor = $4)
+			), resolved AS (
+				SELECT actor, COUNT(*) AS resolved, EXTRACT(EPOCH FROM AVG(handle_time)) AS handle_seconds,
+				COUNT(*) FILTER (WHERE reopened) AS reopened FROM resolutions GROUP BY actor
+			), replies AS (
+				SELECT c.owner AS actor, COUNT(*) AS replies FROM comments AS c JOIN tickets AS t ON t.id = c.ticket_id
+				WHERE c.author_type = $7 AND c.created_at >= $1 AND c.created_at < $2
+				AND ($3 = '' OR t.issuer = $3) AND ($4 = '' OR c.owner = $4) GROUP BY c.owner
+			)
+			SELECT COALESCE(resolved.actor, replies.actor) AS agent, COALESCE(resolved.resolved, 0),
+			COALESCE(resolved.handle_seconds, 0), COALESCE(replies.replies, 0), COALESCE(resolved.reopened, 0)
+			FROM resolved FULL OUTER JOIN replies ON replies.actor = resolved.actor ORDER BY agent;`
+
+	rows, e := r.db.Query(ctx, q, fromDate, toDate, issuer, agent, TicketStatusResolved, TicketStatusClosed,
+		CommentAuthorTypeAgent)
+	if e != nil {
+		return nil, queryFailed(r.logger, e)
+	}
+	defer rows.Close()
+
+	report := make([]*AgentMetrics, 0)
+	for rows.Next() {
+		metrics := &AgentMetrics{}
+		var handleSeconds float64
+
+		e := rows.Scan(&metrics.Agent, &metrics.TicketsResolved, &handleSeconds, &metrics.RepliesSent,
+			&metrics.TicketsReopened)
+		if e != nil {
+			return nil, queryFailed(r.logger, e)
+		}
+
+		metrics.AverageHandleTime = time.Duration(handleSeconds * float64(time.Second))
+		report = append(report, metrics)
+	}
+
+	return report, nil
+}
+
+// TimeSpentRow holds the time logged on the tickets of an issuer in a period, per agent unless the report is not split
+// per agent, in which case the agent is empty.
+type TimeSpentRow struct {
+	Issuer    string
+	Agent     string
+	Tickets   int64
+	Entries   int64
+	TimeSpent time.Duration
+}
+
+// TimeSpent sums the work logs between from and to dates, one row per issuer and, if byAgent is true, per agent. If
+// issuer is not empty only the work logs of that issuer are summed, and if agent is not empty only those of that agent.
+func (r *ReportRepository) TimeSpent(ctx context.Context, issuer, agent string, byAgent bool, fromDate,
+	toDate string) ([]*TimeSpentRow, *errors.Type) {
+
+	q := `SELECT issuer, CASE WHEN $5 THEN agent ELSE '' END AS agent, COUNT(DISTINCT ticket_id), COUNT(*),
+			SUM(minutes) FROM work_logs WHERE logged_at >= $1 AND logged_at < $2 AND ($3 = '' OR issuer = $3)
+			AND ($4 = '' OR agent = $4) GROUP BY 1, 2 ORDER BY 1, 2;`
+
+	rows, e := r.db.Query(ctx, q, fromDate, toDate, issuer, agent, byAgent)
+	if e != nil {
+		return nil, queryFailed(r.logger, e)
+	}
+	defer rows.Close()
+
+	report := make([]*TimeSpentRow, 0)
+	for rows.Next() {
+		row := &TimeSpentRow{}
+		var minutes int64
+
+		if e := rows.Scan(&row.Issuer, &row.Agent, &row.Tickets, &row.Entries, &minutes); e != nil {
+			return nil, queryFailed(r.logger, e)
+		}
+
+		row.TimeSpent = time.Duration(minutes) * time.Minute
+		report = append(report, row)
+	}
+
+	return report, nil
+}
+
+// BillingRow holds the billable time logged on the tickets of an issuer in a period. The hourly rate and currency are
+// the current billing rate of the issuer, they are zero and empty when the issuer has no rate.
+type BillingRow struct {
+	Issuer          string
+	BillableTickets int64
+	BillableTime    time.Duration
+	HourlyRate      int64
+	Currency        string
+}
+
+// Amount returns back the billable time priced at the hourly rate, in minor units of the currency and rounded to the
+// nearest unit.
+func (r *BillingRow) Amount() int64 {
+	minutes := int64(r.BillableTime / time.Minute)
+	return (minutes*r.HourlyRate + 30) / 60
+}
+
+// Billing sums the work logs of billable tickets between from and to dates, one row per issuer. Tickets count once
+// per issuer when any time was logged on them in the period.
+func (r *ReportRepository) Billing(ctx context.Context, fromDate, toDate string) ([]*BillingRow, *errors.Type) {
+	q := `SELECT w.issuer, COUNT(DISTINCT w.ticket_id), SUM(w.minutes), COALESCE(MAX(b.hourly_rate), 0),
+			COALESCE(MAX(b.currency), '') FROM work_logs AS w JOIN tickets AS t ON t.id = w.ticket_id AND t.billable
+			LEFT JOIN billing_rates AS b ON b.issuer = w.issuer WHERE w.logged_at >= $1 AND w.logged_at < $2
+			GROUP BY w.issuer ORDER BY w.issuer;`
+
+	rows, e := r.db.Query(ctx, q, fromDate, toDate)
+	if e != nil {
+		return nil, queryFailed(r.logger, e)
+	}
+	defer rows.Close()
+
+	report := make([]*BillingRow, 0)
+	for rows.Next() {
+		row := &BillingRow{}
+		var minutes int64
+
+		e := rows.Scan(&row.Issuer, &row.BillableTickets, &minutes, &row.HourlyRate, &row.Currency)
+		if e != nil {
+			return nil, queryFailed(r.logger, e)
+		}
+
+		row.BillableTime = time.Duration(minutes) * time.Minute
+		report = append(report, row)
+	}
+
+	return report, nil
+}
+
+// ResolutionRow holds the number of tickets resolved in a period with a resolution category, sub-category and root
+// cause. Any of them is empty when the tickets got resolved without it.
+type ResolutionRow struct {
+	Category    string
+	SubCategory string
+	RootCause   RootCause
+	Tickets     int64
+}
+
+// Resolutions counts the tickets resolved between from and to dates per resolution category, sub-category and root
+// cause, most frequent first. If issuer is not empty only the tickets of that issuer are counted.
+func (r *ReportRepository) Resolutions(ctx context.Context, issuer, fromDate, toDate string) ([]*ResolutionRow,
+	*errors.Type) {
+
+	q := `SELECT COALESCE(resolution_category, ''), COALESCE(resolution_sub_category, ''), COALESCE(root_cause, ''),
+			COUNT(*) FROM tickets WHERE resolved_at >= $1 AND resolved_at < $2 AND ($3 = '' OR issuer = $3)
+			GROUP BY 1, 2, 3 ORDER BY 4 DESC, 1, 2, 3;`
+
+	rows, e := r.db.Query(ctx, q, fromDate, toDate, issuer)
+	if e != nil {
+		return nil, queryFailed(r.logger, e)
+	}
+	defer rows.Close()
+
+	report := make([]*ResolutionRow, 0)
+	for rows.Next() {
+		row := &ResolutionRow{}
+		if e := rows.Scan(&row.Category, &row.SubCategory, &row.RootCause, &row.Tickets); e != nil {
+			return nil, queryFailed(r.logger, e)
+		}
+
+		report = append(report, row)
+	}
+
+	return report, nil
+}
+
+// SLAComplianceRow holds how many tickets of an issuer and importance level met their SLA targets. Only the tickets
+// whose targets are settled count, i.e. that got responded or resolved or whose due times have passed.
+type SLAComplianceRow struct {
+	Issuer            string
+	ImportanceLevel   TicketImportanceLevel
+	FirstResponses    int64
+	FirstResponsesMet int64
+	Resolutions       int64
+	ResolutionsMet    int64
+}
+
+// FirstResponseCompliance returns back the percentage of the first responses within target, or false if none counts.
+func (r *SLAComplianceRow) FirstResponseCompliance() (float64, bool) {
+	return percentage(r.FirstResponsesMet, r.FirstResponses)
+}
+
+// ResolutionCompliance returns back the percentage of the resolutions within target, or false if none counts.
+func (r *SLAComplianceRow) ResolutionCompliance() (float64, bool) {
+	return percentage(r.ResolutionsMet, r.Resolutions)
+}
+
+func percentage(part, total int64) (float64, bool) {
+	if total == 0 {
+		return 0, false
+	}
+
+	return float64(part) * 100 / float64(total), true
+}
+
+// SLACompliance counts the tickets created between from and to dates that met their SLA targets, per issuer and
+// importance level. A ticket is first responded by the first comment of an agent, and resolved when it got resolved
+// last. Targets are settled as of the to date.
+func (r *ReportRepository) SLACompliance(ctx context.Context, fromDate, toDate string) ([]*SLAComplianceRow,
+	*errors.Type) {
+
+	q := `SELECT t.issuer, t.importance_level,
+			COUNT(*) FILTER (WHERE t.first_response_due_at IS NOT NULL AND
+				(responses.responded_at IS NOT NULL OR t.first_response_due_at <= $2)),
+			COUNT(*) FILTER (WHERE responses.responded_at <= t.first_response_due_at),
+			COUNT(*) FILTER (WHERE t.resolution_due_at IS NOT NULL AND
+				(t.resolved_at IS NOT NULL OR t.resolution_due_at <= $2)),
+			COUNT(*) FILTER (WHERE t.resolved_at <= t.resolution_due_at)
+			FROM tickets AS t LEFT JOIN LATERAL (SELECT MIN(created_at) AS responded_at FROM comments
+				WHERE ticket_id = t.id AND author_type = $3) AS responses ON TRUE
+			WHERE t.created_at >= $1 AND t.created_at < $2 GROUP BY 1, 2 ORDER BY 1, 2;`
+
+	rows, e := r.db.Query(ctx, q, fromDate, toDate, CommentAuthorTypeAgent)
+	if e != nil {
+		return nil, queryFailed(r.logger, e)
+	}
+	defer rows.Close()
+
+	report := make([]*SLAComplianceRow, 0)
+	for rows.Next() {
+		row := &SLAComplianceRow{}
+		e := rows.Scan(&row.Issuer, &row.ImportanceLevel, &row.FirstResponses, &row.FirstResponsesMet,
+			&row.Resolutions, &row.ResolutionsMet)
+		if e != nil {
+			return nil, queryFailed(r.logger, e)
+		}
+
+		report = append(report, row)
+	}
+
+	return report, nil
+}
+
+// WorkloadRow is a row of workload series that holds the number of tickets arrived and resolved in a period. The
+// issuer is empty when the series is not split per issuer.
+type WorkloadRow struct {
+	Period      time.Time
+	Issuer      string
+	Arrivals    int64
+	Resolutions int64
+}
+
+// Workload builds the series of ticket arrivals and resolutions between from and to dates, one row per period of the
+// provided granularity and, if byIssuer is true, per issuer. Periods without any activity are included with zero
+// counts so the series is continuous. If issuer is not empty only the tickets of that issuer are counted.
+func (r *ReportRepository) Workload(ctx context.Context, issuer string, granularity WorkloadGranularity,
+	byIssuer bool, fromDate, toDate string) ([]*WorkloadRow, *errors.Type) {
+
+	q := `WITH arrivals AS (
+				SELECT DATE_TRUNC($1, created_at) AS period, CASE WHEN $5 THEN issuer ELSE '' END AS issuer,
+				COUNT(*) AS count FROM tickets WHERE created_at >= $2 AND created_at < $3 AND ($4 = '' OR issuer = $4)
+				GROUP BY 1, 2
+			), resolutions AS (
+				SELECT DATE_TRUNC($1, changed_at) AS period, CASE WHEN $5 THEN issuer ELSE '' END AS issuer,
+				COUNT(*) AS count FROM ticket_status_changes WHERE status = $6 AND changed_at >= $2 AND changed_at < $3
+				AND ($4 = '' OR issuer = $4) GROUP BY 1, 2
+			), issuers AS (
+				SELECT issuer FROM arrivals UNION SELECT issuer FROM resolutions
+			), periods AS (
+				SELECT GENERATE_SERIES(DATE_TRUNC($1, $2::TIMESTAMP), $3::TIMESTAMP - INTERVAL '1 microsecond',
+				('1 ' || $1)::INTERVAL) AS period
+			)
+			SELECT p.period, i.issuer, COALESCE(a.count, 0), COALESCE(r.count, 0)
+			FROM periods AS p CROSS JOIN issuers AS i
+			LEFT JOIN arrivals AS a ON a.period = p.period AND a.issuer = i.issuer
+			LEFT JOIN resolutions AS r ON r.period = p.period AND r.issuer = i.issuer ORDER BY p.period, i.issuer;`
+
+	rows, e := r.db.Query(ctx, q, granularity.unit(), fromDate, toDate, issuer, byIssuer, TicketStatusResolved)
+	if e != nil {
+		return nil, queryFailed(r.logger, e)
+	}
+	defer rows.Close()
+
+	series := make([]*WorkloadRow, 0)
+	for rows.Next() {
+		row := &WorkloadRow{}
+
+		if e := rows.Scan(&row.Period, &row.Issuer, &row.Arrivals, &row.Resolutions); e != nil {
+			return nil, queryFailed(r.logger, e)
+		}
+
+		series = append(series, row)
+	}
+
+	return series, nil
+}
+
+// WorkloadGranularity model.
+type WorkloadGranularity string
+
+// Different workload granularity instances.
+const (
+	WorkloadGranularityHour WorkloadGranularity = "HOUR"
+	WorkloadGranularityDay  WorkloadGranularity = "DAY"
+)
+
+// IsValid reports whether the granularity is one of the known granularities.
+func (g WorkloadGranularity) IsValid() bool {
+	switch g {
+	case WorkloadGranularityHour, WorkloadGranularityDay:
+		return true
+	}
+
+	return false
+}
+
+// unit returns back the field of DATE_TRUNC function matching the granularity.
+func (g WorkloadGranularity) unit() string {
+	if g == WorkloadGranularityHour {
+		return "hour"
+	}
+
+	return "day"
+}
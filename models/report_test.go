@@ -0,0 +1,378 @@
+package models_test
+
+import (
+	"context"
+	"time"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Report", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var ticketRepository *models.TicketRepository
+	var repository *models.ReportRepository
+	var scheduledReportRepository *models.ScheduledReportRepository
+	var commentRepository *models.CommentRepository
+	var statusChangeRepository *models.TicketStatusChangeRepository
+	var dailyCounterRepository *models.DailyCounterRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			ticketRepository = models.NewTicketRepository(zap.S(), db)
+			repository = models.NewReportRepository(zap.S(), db)
+			scheduledReportRepository = models.NewScheduledReportRepository(zap.S(), db)
+			commentRepository = models.NewCommentRepository(zap.S(), db)
+			statusChangeRepository = models.NewTicketStatusChangeRepository(zap.S(), db)
+			dailyCounterRepository = models.NewDailyCounterRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	Describe("ReportRepository", func() {
+		Context("When Daily called", func() {
+			It("Should count created tickets per day, issuer, importance level and status", func() {
+				ticket1 := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user1@example.com",
+					Subject:         "Technical Problem",
+					Content:         "Hello, i have some issues with REST API Docs!",
+					ImportanceLevel: models.TicketImportanceLevelMedium,
+				}
+
+				_, e := ticketRepository.Insert(context.Background(), ticket1)
+				Ω(e).Should(BeNil())
+
+				_, e = ticketRepository.Insert(context.Background(), ticket1)
+				Ω(e).Should(BeNil())
+
+				ticket2 := models.Ticket{
+					Issuer:          "Microservice-B",
+					Owner:           "user2@example.com",
+					Subject:         "UI Problem",
+					Content:         "Hello, i have some issues with panel!",
+					ImportanceLevel: models.TicketImportanceLevelLow,
+				}
+
+				_, e = ticketRepository.Insert(context.Background(), ticket2)
+				Ω(e).Should(BeNil())
+
+				from := time.Now().UTC().Add(-24 * time.Hour).Format(time.RFC3339Nano)
+				to := time.Now().UTC().Add(24 * time.Hour).Format(time.RFC3339Nano)
+
+				rows, e := repository.Daily(context.Background(), "", from, to)
+				Ω(e).Should(BeNil())
+				Ω(len(rows)).Should(Equal(2))
+				Ω(rows[0].Issuer).Should(Equal("Microservice-A"))
+				Ω(rows[0].Count).Should(Equal(int64(2)))
+				Ω(rows[1].Issuer).Should(Equal("Microservice-B"))
+				Ω(rows[1].Count).Should(Equal(int64(1)))
+
+				rows, e = repository.Daily(context.Background(), "Microservice-B", from, to)
+				Ω(e).Should(BeNil())
+				Ω(len(rows)).Should(Equal(1))
+				Ω(rows[0].Status).Should(Equal(models.TicketStatusNew))
+			})
+		})
+
+		Context("When AgentMetrics called", func() {
+			It("Should report resolutions, handle times, replies and reopens per agent", func() {
+				ticket := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user1@example.com",
+					Subject:         "Technical Problem",
+					Content:         "Hello, i have some issues with REST API Docs!",
+					ImportanceLevel: models.TicketImportanceLevelMedium,
+				}
+
+				id1, e := ticketRepository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				id2, e := ticketRepository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				_, e = commentRepository.Insert(context.Background(), models.Comment{TicketID: id1,
+					Owner: "agent1@example.com", Content: "Please check the docs again."})
+				Ω(e).Should(BeNil())
+
+				now := time.Now().UTC()
+				change := func(id int64, previous, status models.TicketStatus, actor string,
+					after time.Duration) models.TicketStatusChange {
+
+					return models.TicketStatusChange{TicketID: id, Issuer: ticket.Issuer, PreviousStatus: previous,
+						Status: status, Actor: actor, ChangedAt: now.Add(after)}
+				}
+
+				changes := []models.TicketStatusChange{
+					change(id1, models.TicketStatusNew, models.TicketStatusResolved, "agent1@example.com", time.Hour),
+					change(id1, models.TicketStatusResolved, models.TicketStatusReplied, "", 2*time.Hour),
+					change(id2, models.TicketStatusNew, models.TicketStatusResolved, "agent1@example.com", 3*time.Hour),
+					change(id2, models.TicketStatusResolved, models.TicketStatusClosed, "", 4*time.Hour),
+				}
+
+				for _, change := range changes {
+					Ω(statusChangeRepository.Insert(context.Background(), change)).Should(BeNil())
+				}
+
+				from := now.Add(-24 * time.Hour).Format(time.RFC3339Nano)
+				to := now.Add(24 * time.Hour).Format(time.RFC3339Nano)
+
+				metrics, e := repository.AgentMetrics(context.Background(), "", "", from, to)
+				Ω(e).Should(BeNil())
+				Ω(len(metrics)).Should(Equal(1))
+				Ω(metrics[0].Agent).Should(Equal("agent1@example.com"))
+				Ω(metrics[0].TicketsResolved).Should(Equal(int64(2)))
+				Ω(metrics[0].RepliesSent).Should(Equal(int64(1)))
+				Ω(metrics[0].TicketsReopened).Should(Equal(int64(1)))
+				Ω(metrics[0].ReopenRate()).Should(Equal(0.5))
+				Ω(metrics[0].AverageHandleTime).Should(BeNumerically("~", 2*time.Hour, time.Minute))
+
+				metrics, e = repository.AgentMetrics(context.Background(), "", "agent2@example.com", from, to)
+				Ω(e).Should(BeNil())
+				Ω(metrics).Should(BeEmpty())
+			})
+		})
+
+		Context("When SLACompliance called", func() {
+			It("Should count the tickets meeting their settled SLA targets per issuer and importance level", func() {
+				now := time.Now().UTC()
+				soon, passed := now.Add(time.Hour), now.Add(-time.Minute)
+
+				responded, e := ticketRepository.Insert(context.Background(), models.Ticket{Issuer: "Microservice-A",
+					Owner: "user1@example.com", Subject: "Subject", Content: "Content",
+					ImportanceLevel: models.TicketImportanceLevelMedium, FirstResponseDueAt: &soon,
+					ResolutionDueAt: &passed})
+				Ω(e).Should(BeNil())
+
+				_, e = commentRepository.Insert(context.Background(), models.Comment{TicketID: responded,
+					Owner: "agent1@example.com", Content: "We are on it."})
+				Ω(e).Should(BeNil())
+
+				_, e = ticketRepository.Insert(context.Background(), models.Ticket{Issuer: "Microservice-A",
+					Owner: "user2@example.com", Subject: "Subject", Content: "Content",
+					ImportanceLevel: models.TicketImportanceLevelMedium, FirstResponseDueAt: &passed,
+					ResolutionDueAt: &soon})
+				Ω(e).Should(BeNil())
+
+				_, e = ticketRepository.Insert(context.Background(), models.Ticket{Issuer: "Microservice-A",
+					Owner: "user3@example.com", Subject: "Subject", Content: "Content",
+					ImportanceLevel: models.TicketImportanceLevelHigh})
+				Ω(e).Should(BeNil())
+
+				rows, e := repository.SLACompliance(context.Background(),
+					now.Add(-time.Hour).Format(time.RFC3339Nano), time.Now().UTC().Format(time.RFC3339Nano))
+				Ω(e).Should(BeNil())
+				Ω(rows).Should(HaveLen(2))
+
+				Ω(rows[0].ImportanceLevel).Should(Equal(models.TicketImportanceLevelHigh))
+				_, ok := rows[0].FirstResponseCompliance()
+				Ω(ok).Should(BeFalse())
+
+				Ω(rows[1].ImportanceLevel).Should(Equal(models.TicketImportanceLevelMedium))
+				Ω(rows[1].FirstResponses).Should(Equal(int64(2)))
+				Ω(rows[1].FirstResponsesMet).Should(Equal(int64(1)))
+				Ω(rows[1].Resolutions).Should(Equal(int64(1)))
+				Ω(rows[1].ResolutionsMet).Should(BeZero())
+
+				compliance, ok := rows[1].FirstResponseCompliance()
+				Ω(ok).Should(BeTrue())
+				Ω(compliance).Should(Equal(50.0))
+			})
+		})
+
+		Context("When Workload called", func() {
+			It("Should build continuous series of arrivals and resolutions", func() {
+				ticket := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user1@example.com",
+					Subject:         "Technical Problem",
+					Content:         "Hello, i have some issues with REST API Docs!",
+					ImportanceLevel: models.TicketImportanceLevelMedium,
+				}
+
+				id, e := ticketRepository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				ticket.Issuer = "Microservice-B"
+				_, e = ticketRepository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				now := time.Now().UTC()
+				e = statusChangeRepository.Insert(context.Background(), models.TicketStatusChange{TicketID: id,
+					Issuer: "Microservice-A", PreviousStatus: models.TicketStatusNew,
+					Status: models.TicketStatusResolved, ChangedAt: now})
+				Ω(e).Should(BeNil())
+
+				from := now.Add(-48 * time.Hour).Format(time.RFC3339Nano)
+				to := now.Add(24 * time.Hour).Format(time.RFC3339Nano)
+
+				rows, e := repository.Workload(context.Background(), "", models.WorkloadGranularityDay, false, from, to)
+				Ω(e).Should(BeNil())
+				Ω(len(rows)).Should(BeNumerically(">=", 3))
+
+				var arrivals, resolutions int64
+				for _, row := range rows {
+					Ω(row.Issuer).Should(BeEmpty())
+					arrivals += row.Arrivals
+					resolutions += row.Resolutions
+				}
+				Ω(arrivals).Should(Equal(int64(2)))
+				Ω(resolutions).Should(Equal(int64(1)))
+
+				rows, e = repository.Workload(context.Background(), "", models.WorkloadGranularityHour, true, from, to)
+				Ω(e).Should(BeNil())
+				Ω(len(rows) % 2).Should(Equal(0))
+				Ω(rows[0].Issuer).Should(Equal("Microservice-A"))
+				Ω(rows[1].Issuer).Should(Equal("Microservice-B"))
+			})
+		})
+	})
+
+	Describe("DailyCounterRepository", func() {
+		Context("When tickets change and Reconcile called", func() {
+			It("Should keep the counters up to date and fix the drifted ones", func() {
+				ticket := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user1@example.com",
+					Subject:         "Technical Problem",
+					Content:         "Hello, i have some issues with REST API Docs!",
+					ImportanceLevel: models.TicketImportanceLevelMedium,
+				}
+
+				first, e := ticketRepository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				second, e := ticketRepository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				_, err := db.Exec(context.Background(), `UPDATE tickets SET status = $1 WHERE id = $2;`,
+					models.TicketStatusResolved, first)
+				Ω(err).Should(BeNil())
+
+				_, err = db.Exec(context.Background(), `DELETE FROM tickets WHERE id = $1;`, second)
+				Ω(err).Should(BeNil())
+
+				from := time.Now().UTC().Add(-24 * time.Hour).Format(time.RFC3339Nano)
+				to := time.Now().UTC().Add(24 * time.Hour).Format(time.RFC3339Nano)
+
+				rows, e := repository.Daily(context.Background(), "", from, to)
+				Ω(e).Should(BeNil())
+				Ω(rows).Should(HaveLen(1))
+				Ω(rows[0].Status).Should(Equal(models.TicketStatusResolved))
+				Ω(rows[0].Count).Should(Equal(int64(1)))
+
+				drifts, e := dailyCounterRepository.Reconcile(context.Background())
+				Ω(e).Should(BeNil())
+				Ω(drifts).Should(BeEmpty())
+
+				_, err = db.Exec(context.Background(), `UPDATE daily_ticket_counters SET count = count + 2;`)
+				Ω(err).Should(BeNil())
+
+				drifts, e = dailyCounterRepository.Reconcile(context.Background())
+				Ω(e).Should(BeNil())
+				Ω(drifts).Should(HaveLen(2))
+				Ω(drifts[0].Status).Should(Equal(models.TicketStatusNew))
+				Ω(drifts[0].Counted).Should(Equal(int64(2)))
+				Ω(drifts[0].Actual).Should(Equal(int64(0)))
+				Ω(drifts[1].Status).Should(Equal(models.TicketStatusResolved))
+				Ω(drifts[1].Counted).Should(Equal(int64(3)))
+				Ω(drifts[1].Actual).Should(Equal(int64(1)))
+
+				rows, e = repository.Daily(context.Background(), "", from, to)
+				Ω(e).Should(BeNil())
+				Ω(rows).Should(HaveLen(1))
+				Ω(rows[0].Count).Should(Equal(int64(1)))
+			})
+
+			It("Should keep counting tickets once they get archived", func() {
+				ticket := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user1@example.com",
+					Subject:         "Technical Problem",
+					Content:         "Hello, i have some issues with REST API Docs!",
+					ImportanceLevel: models.TicketImportanceLevelMedium,
+					Status:          models.TicketStatusClosed,
+				}
+
+				_, e := ticketRepository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				from := time.Now().UTC().Add(-24 * time.Hour).Format(time.RFC3339Nano)
+				to := time.Now().UTC().Add(24 * time.Hour).Format(time.RFC3339Nano)
+
+				before, e := repository.Daily(context.Background(), "", from, to)
+				Ω(e).Should(BeNil())
+				Ω(before).Should(HaveLen(1))
+				Ω(before[0].Count).Should(Equal(int64(1)))
+
+				archiveRepository := models.NewArchiveRepository(zap.S(), db)
+				archived, e := archiveRepository.Archive(context.Background(), time.Now().Add(time.Minute), 10)
+				Ω(e).Should(BeNil())
+				Ω(archived).Should(HaveLen(1))
+
+				drifts, e := dailyCounterRepository.Reconcile(context.Background())
+				Ω(e).Should(BeNil())
+				Ω(drifts).Should(BeEmpty())
+
+				after, e := repository.Daily(context.Background(), "", from, to)
+				Ω(e).Should(BeNil())
+				Ω(after).Should(Equal(before))
+			})
+		})
+	})
+
+	Describe("ScheduledReportRepository", func() {
+		Context("When Insert, LoadAll and DeleteByID called", func() {
+			It("Should store, load and delete scheduled reports successfully", func() {
+				report := models.ScheduledReport{
+					Name:       "Weekly support review",
+					Cron:       "0 8 * * 1",
+					Report:     models.ReportTypeDaily,
+					PeriodDays: 7,
+					Format:     models.ReportFormatCSV,
+					Recipients: []string{"lead@example.com", "ops@example.com"},
+				}
+
+				e := scheduledReportRepository.Insert(context.Background(), report)
+				Ω(e).Should(BeNil())
+
+				reports, e := scheduledReportRepository.LoadAll(context.Background())
+				Ω(e).Should(BeNil())
+				Ω(len(reports)).Should(Equal(1))
+				Ω(reports[0].Name).Should(Equal(report.Name))
+				Ω(reports[0].Cron).Should(Equal(report.Cron))
+				Ω(reports[0].Recipients).Should(Equal(report.Recipients))
+
+				e = scheduledReportRepository.DeleteByID(context.Background(), reports[0].ID)
+				Ω(e).Should(BeNil())
+
+				reports, e = scheduledReportRepository.LoadAll(context.Background())
+				Ω(e).Should(BeNil())
+				Ω(reports).Should(BeEmpty())
+			})
+		})
+	})
+})
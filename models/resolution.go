@@ -0,0 +1,133 @@
+package models
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// RootCause model. It is what caused the issue a ticket got resolved for.
+type RootCause string
+
+// Different root cause instances.
+const (
+	RootCauseBug            RootCause = "BUG"
+	RootCauseConfiguration  RootCause = "CONFIGURATION"
+	RootCauseInfrastructure RootCause = "INFRASTRUCTURE"
+	RootCauseThirdParty     RootCause = "THIRD_PARTY"
+	RootCauseUserError      RootCause = "USER_ERROR"
+	RootCauseDocumentation  RootCause = "DOCUMENTATION"
+	RootCauseFeatureRequest RootCause = "FEATURE_REQUEST"
+	RootCauseUnknown        RootCause = "UNKNOWN"
+)
+
+// IsValid reports whether the root cause is one of the known root causes.
+func (c RootCause) IsValid() bool {
+	switch c {
+	case RootCauseBug, RootCauseConfiguration, RootCauseInfrastructure, RootCauseThirdParty, RootCauseUserError,
+		RootCauseDocumentation, RootCauseFeatureRequest, RootCauseUnknown:
+		return true
+	}
+
+	return false
+}
+
+// Resolution is how a ticket got resolved, captured when resolving it. All of its fields are optional.
+type Resolution struct {
+	Category    string
+	SubCategory string
+	RootCause   RootCause
+}
+
+// ResolutionCategory is the entity model of resolution_categories table. Categories and their sub-categories are the
+// taxonomy resolutions are categorized with.
+type ResolutionCategory struct {
+	Name          string
+	SubCategories []string
+}
+
+// ResolutionCategoryRepository is the repository implementation of ResolutionCategory model.
+type ResolutionCategoryRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewResolutionCategoryRepository returns back a newly created and ready to use ResolutionCategoryRepository.
+func NewResolutionCategoryRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *ResolutionCategoryRepository {
+	return &ResolutionCategoryRepository{logger: logger, db: db}
+}
+
+// Save tries to insert a resolution category or replace its sub-categories if it already exists. Tickets already
+// resolved with a removed sub-category keep it.
+func (r *ResolutionCategoryRepository) Save(ctx context.Context, category ResolutionCategory) *errors.Type {
+	q := `INSERT INTO resolution_categories (name, sub_categories, created_at, modified_at)
+			VALUES ($1, $2, NOW(), NOW())
+			ON CONFLICT (name) DO UPDATE SET sub_categories = EXCLUDED.sub_categories, modified_at = NOW();`
+
+	if _, e := r.db.Exec(ctx, q, category.Name, category.SubCategories); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// LoadAll tries to load all resolution categories, ordered by name.
+func (r *ResolutionCategoryRepository) LoadAll(ctx context.Context) ([]*ResolutionCategory, *errors.Type) {
+	q := `SELECT name, sub_categories FROM resolution_categories ORDER BY name;`
+
+	rows, e := r.db.Query(ctx, q)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	categories := make([]*ResolutionCategory, 0)
+	for rows.Next() {
+		category := &ResolutionCategory{}
+		if e := rows.Scan(&category.Name, &category.SubCategories); e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		categories = append(categories, category)
+	}
+
+	return categories, nil
+}
+
+// Exists checks whether the category exists, along with the sub-category if it is not empty.
+func (r *ResolutionCategoryRepository) Exists(ctx context.Context, category, subCategory string) (bool,
+	*errors.Type) {
+
+	q := `SELECT EXISTS (SELECT 1 FROM resolution_categories WHERE name = $1
+			AND ($2 = '' OR $2 = ANY (sub_categories)));`
+
+	var exists bool
+	if e := r.db.QueryRow(ctx, q, category, subCategory).Scan(&exists); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return false, et
+	}
+
+	return exists, nil
+}
+
+// Delete tries to delete a resolution category. Tickets already resolved with it keep it.
+func (r *ResolutionCategoryRepository) Delete(ctx context.Context, name string) *errors.Type {
+	q := `DELETE FROM resolution_categories WHERE name = $1;`
+
+	if _, e := r.db.Exec(ctx, q, name); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
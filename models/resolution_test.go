@@ -0,0 +1,103 @@
+package models_test
+
+import (
+	"context"
+	"time"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Resolution", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.ResolutionCategoryRepository
+	var ticketRepository *models.TicketRepository
+	var reportRepository *models.ReportRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewResolutionCategoryRepository(zap.S(), db)
+			ticketRepository = models.NewTicketRepository(zap.S(), db)
+			reportRepository = models.NewReportRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	Describe("ResolutionCategoryRepository", func() {
+		Context("When Save and Exists called", func() {
+			It("Should only find the saved categories and sub-categories", func() {
+				e := repository.Save(context.Background(), models.ResolutionCategory{Name: "Payments",
+					SubCategories: []string{"Refund", "Settlement"}})
+				Ω(e).Should(BeNil())
+
+				exists, e := repository.Exists(context.Background(), "Payments", "Refund")
+				Ω(e).Should(BeNil())
+				Ω(exists).Should(BeTrue())
+
+				exists, e = repository.Exists(context.Background(), "Payments", "Chargeback")
+				Ω(e).Should(BeNil())
+				Ω(exists).Should(BeFalse())
+
+				e = repository.Delete(context.Background(), "Payments")
+				Ω(e).Should(BeNil())
+
+				categories, e := repository.LoadAll(context.Background())
+				Ω(e).Should(BeNil())
+				Ω(categories).Should(BeEmpty())
+			})
+		})
+	})
+
+	Describe("ReportRepository", func() {
+		Context("When a ticket is resolved with a resolution", func() {
+			It("Should count it per category, sub-category and root cause", func() {
+				ticket := models.Ticket{Issuer: "Microservice-A", Owner: "user-1", Subject: "Subject",
+					Content: "Content", ImportanceLevel: models.TicketImportanceLevelHigh}
+				id, e := ticketRepository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				ticket.ID = id
+				ticket.Status = models.TicketStatusResolved
+				ticket.Resolution = models.Resolution{Category: "Payments", SubCategory: "Refund",
+					RootCause: models.RootCauseBug}
+				_, e = ticketRepository.Update(context.Background(), &ticket, "agent-1")
+				Ω(e).Should(BeNil())
+
+				loaded, e := ticketRepository.LoadByID(context.Background(), id)
+				Ω(e).Should(BeNil())
+				Ω(loaded.Resolution).Should(Equal(ticket.Resolution))
+				Ω(loaded.ResolvedAt).ShouldNot(BeNil())
+
+				from := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339Nano)
+				to := time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano)
+				rows, e := reportRepository.Resolutions(context.Background(), "Microservice-A", from, to)
+				Ω(e).Should(BeNil())
+				Ω(rows).Should(Equal([]*models.ResolutionRow{{Category: "Payments", SubCategory: "Refund",
+					RootCause: models.RootCauseBug, Tickets: 1}}))
+			})
+		})
+	})
+})
@@ -0,0 +1,139 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// RuntimeEntryKind model.
+type RuntimeEntryKind string
+
+// Different runtime entry kind instances.
+const (
+	RuntimeEntryKindFeatureFlag       RuntimeEntryKind = "FEATURE_FLAG"
+	RuntimeEntryKindTemplate          RuntimeEntryKind = "TEMPLATE"
+	RuntimeEntryKindAutoResponderRule RuntimeEntryKind = "AUTO_RESPONDER_RULE"
+)
+
+// IsValid reports whether the kind is one of the known kinds.
+func (k RuntimeEntryKind) IsValid() bool {
+	switch k {
+	case RuntimeEntryKindFeatureFlag, RuntimeEntryKindTemplate, RuntimeEntryKindAutoResponderRule:
+		return true
+	}
+
+	return false
+}
+
+// RuntimeEntry is the entity model of runtime_entries table. It is an entity editable at runtime, whose changes apply
+// to all kiosk instances within seconds. The value is a JSON document whose shape depends on the kind.
+type RuntimeEntry struct {
+	Kind       RuntimeEntryKind
+	Name       string
+	Value      string
+	Version    int64
+	ModifiedAt time.Time
+}
+
+// RuntimeEntryRepository is the repository implementation of RuntimeEntry model.
+type RuntimeEntryRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewRuntimeEntryRepository returns back a newly created and ready to use RuntimeEntryRepository.
+func NewRuntimeEntryRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *RuntimeEntryRepository {
+	return &RuntimeEntryRepository{logger: logger, db: db}
+}
+
+// Save tries to insert the entry or replace its value if it already exists. A positive version of the entry must be
+// its current version, so concurrent edits do not overwrite each other, while a zero version saves unconditionally.
+// The returned entry holds the new version.
+func (r *RuntimeEntryRepository) Save(ctx context.Context, entry RuntimeEntry) (*RuntimeEntry, *errors.Type) {
+	q := `INSERT INTO runtime_entries AS e (kind, name, value, version, created_at, modified_at)
+			VALUES ($1, $2, $3, 1, NOW(), NOW())
+			ON CONFLICT (kind, name) DO UPDATE SET value = EXCLUDED.value, version = e.version + 1, modified_at = NOW()
+			WHERE $4 = 0 OR e.version = $4
+			RETURNING version, modified_at;`
+
+	e := r.db.QueryRow(ctx, q, entry.Kind, entry.Name, entry.Value, entry.Version).Scan(&entry.Version,
+		&entry.ModifiedAt)
+	if e != nil {
+		if e == pgx.ErrNoRows {
+			return nil, errors.Conflict("runtime_entry.version_mismatch", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	return &entry, nil
+}
+
+// Load tries to load an entry by its kind and name.
+func (r *RuntimeEntryRepository) Load(ctx context.Context, kind RuntimeEntryKind, name string) (*RuntimeEntry,
+	*errors.Type) {
+
+	q := `SELECT kind, name, value, version, modified_at FROM runtime_entries WHERE kind = $1 AND name = $2;`
+
+	entry := &RuntimeEntry{}
+	e := r.db.QueryRow(ctx, q, kind, name).Scan(&entry.Kind, &entry.Name, &entry.Value, &entry.Version,
+		&entry.ModifiedAt)
+	if e != nil {
+		if e == pgx.ErrNoRows {
+			return nil, errors.NotFound("runtime_entry.not_found", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	return entry, nil
+}
+
+// LoadAll tries to load all entries, ordered by kind and name.
+func (r *RuntimeEntryRepository) LoadAll(ctx context.Context) ([]*RuntimeEntry, *errors.Type) {
+	q := `SELECT kind, name, value, version, modified_at FROM runtime_entries ORDER BY kind, name;`
+
+	rows, e := r.db.Query(ctx, q)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	entries := make([]*RuntimeEntry, 0)
+	for rows.Next() {
+		entry := &RuntimeEntry{}
+		if e := rows.Scan(&entry.Kind, &entry.Name, &entry.Value, &entry.Version, &entry.ModifiedAt); e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// Delete tries to delete an entry by its kind and name.
+func (r *RuntimeEntryRepository) Delete(ctx context.Context, kind RuntimeEntryKind, name string) *errors.Type {
+	q := `DELETE FROM runtime_entries WHERE kind = $1 AND name = $2;`
+
+	if _, e := r.db.Exec(ctx, q, kind, name); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package models_test
+
+import (
+	"context"
+	"net/http"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("RuntimeEntry", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.RuntimeEntryRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewRuntimeEntryRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	Describe("RuntimeEntryRepository", func() {
+		Context("When Save called with the current version", func() {
+			It("Should replace the value and bump the version", func() {
+				entry := models.RuntimeEntry{Kind: models.RuntimeEntryKindFeatureFlag, Name: "sentiment", Value: "false"}
+
+				saved, e := repository.Save(context.Background(), entry)
+				Ω(e).Should(BeNil())
+				Ω(saved.Version).Should(Equal(int64(1)))
+
+				saved.Value = "true"
+				saved, e = repository.Save(context.Background(), *saved)
+				Ω(e).Should(BeNil())
+				Ω(saved.Version).Should(Equal(int64(2)))
+
+				loaded, e := repository.Load(context.Background(), entry.Kind, entry.Name)
+				Ω(e).Should(BeNil())
+				Ω(loaded.Value).Should(Equal("true"))
+				Ω(loaded.Version).Should(Equal(int64(2)))
+			})
+		})
+
+		Context("When Save called with a stale version", func() {
+			It("Should return conflict error", func() {
+				entry := models.RuntimeEntry{Kind: models.RuntimeEntryKindTemplate, Name: "greeting", Value: `"Hi"`}
+
+				_, e := repository.Save(context.Background(), entry)
+				Ω(e).Should(BeNil())
+				_, e = repository.Save(context.Background(), entry)
+				Ω(e).Should(BeNil())
+
+				entry.Version = 1
+				_, e = repository.Save(context.Background(), entry)
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusConflict))
+			})
+		})
+
+		Context("When LoadAll and Delete called", func() {
+			It("Should list the remaining entries ordered by kind and name", func() {
+				for _, name := range []string{"b", "a"} {
+					entry := models.RuntimeEntry{Kind: models.RuntimeEntryKindTemplate, Name: name, Value: `""`}
+					_, e := repository.Save(context.Background(), entry)
+					Ω(e).Should(BeNil())
+				}
+
+				entry := models.RuntimeEntry{Kind: models.RuntimeEntryKindFeatureFlag, Name: "c", Value: "true"}
+				_, e := repository.Save(context.Background(), entry)
+				Ω(e).Should(BeNil())
+
+				Ω(repository.Delete(context.Background(), models.RuntimeEntryKindTemplate, "b")).Should(BeNil())
+
+				entries, e := repository.LoadAll(context.Background())
+				Ω(e).Should(BeNil())
+				Ω(entries).Should(HaveLen(2))
+				Ω(entries[0].Name).Should(Equal("c"))
+				Ω(entries[1].Name).Should(Equal("a"))
+
+				_, e = repository.Load(context.Background(), models.RuntimeEntryKindTemplate, "b")
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusNotFound))
+			})
+		})
+	})
+})
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// SavedSearch is the entity model of saved_searches table. The agent is notified about every new ticket matching it,
+// i.e. mentioning the query within its subject or content, case insensitively. An empty issuer or importance level
+// matches any.
+type SavedSearch struct {
+	Model
+
+	Agent           string
+	Name            string
+	Query           string
+	Issuer          string
+	ImportanceLevel TicketImportanceLevel
+}
+
+// SavedSearchRepository is the repository implementation of SavedSearch model.
+type SavedSearchRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewSavedSearchRepository returns back a newly created and ready to use SavedSearchRepository.
+func NewSavedSearchRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *SavedSearchRepository {
+	return &SavedSearchRepository{logger: logger, db: db}
+}
+
+// Insert tries to insert a saved search into saved_searches table.
+func (r *SavedSearchRepository) Insert(ctx context.Context, search SavedSearch) (int64, *errors.Type) {
+	q := `INSERT INTO saved_searches (agent, name, query, issuer, importance_level, created_at, modified_at)
+			VALUES ($1, $2, $3, $4, $5, NOW(), NOW()) RETURNING id;`
+
+	var id int64
+	e := r.db.QueryRow(ctx, q, search.Agent, search.Name, search.Query, search.Issuer, search.ImportanceLevel).Scan(&id)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return 0, et
+	}
+
+	return id, nil
+}
+
+// LoadByAgent tries to load the saved searches of an agent, oldest first.
+func (r *SavedSearchRepository) LoadByAgent(ctx context.Context, agent string) ([]*SavedSearch, *errors.Type) {
+	q := `SELECT id, agent, name, query, issuer, importance_level, created_at, modified_at FROM saved_searches
+			WHERE agent = $1 ORDER BY id;`
+
+	return r.load(ctx, q, agent)
+}
+
+// LoadMatching tries to load the saved searches matched by a ticket of the issuer with the importance level, whose
+// subject and content are given as text.
+func (r *SavedSearchRepository) LoadMatching(ctx context.Context, issuer string, importanceLevel TicketImportanceLevel,
+	text string) ([]*SavedSearch, *errors.Type) {
+
+	q := `SELECT id, agent, name, query, issuer, importance_level, created_at, modified_at FROM saved_searches
+			WHERE (issuer = '' OR issuer = $1) AND (importance_level = '' OR importance_level = $2)
+			AND STRPOS(LOWER($3), LOWER(query)) > 0 ORDER BY id;`
+
+	return r.load(ctx, q, issuer, importanceLevel, text)
+}
+
+func (r *SavedSearchRepository) load(ctx context.Context, q string, args ...interface{}) ([]*SavedSearch,
+	*errors.Type) {
+
+	rows, e := r.db.Query(ctx, q, args...)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	searches := make([]*SavedSearch, 0)
+	for rows.Next() {
+		search := &SavedSearch{}
+
+		e := rows.Scan(&search.ID, &search.Agent, &search.Name, &search.Query, &search.Issuer, &search.ImportanceLevel,
+			&search.CreatedAt, &search.ModifiedAt)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		searches = append(searches, search)
+	}
+
+	return searches, nil
+}
+
+// DeleteByID tries to delete a saved search of an agent from saved_searches table.
+func (r *SavedSearchRepository) DeleteByID(ctx context.Context, id int64, agent string) *errors.Type {
+	q := `DELETE FROM saved_searches WHERE id = $1 AND agent = $2;`
+
+	_, e := r.db.Exec(ctx, q, id, agent)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package models_test
+
+import (
+	"context"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("SavedSearch", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.SavedSearchRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewSavedSearchRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	Describe("SavedSearchRepository", func() {
+		Context("When Insert, LoadByAgent and DeleteByID called", func() {
+			It("Should store, load and delete the saved searches of an agent", func() {
+				search := models.SavedSearch{Agent: "agent@kiosk.io", Name: "Chargebacks", Query: "chargeback",
+					ImportanceLevel: models.TicketImportanceLevelHigh}
+				id, e := repository.Insert(context.Background(), search)
+				Ω(e).Should(BeNil())
+
+				searches, e := repository.LoadByAgent(context.Background(), "agent@kiosk.io")
+				Ω(e).Should(BeNil())
+				Ω(searches).Should(HaveLen(1))
+				Ω(searches[0].ID).Should(Equal(id))
+				Ω(searches[0].Name).Should(Equal("Chargebacks"))
+				Ω(searches[0].ImportanceLevel).Should(Equal(models.TicketImportanceLevelHigh))
+
+				e = repository.DeleteByID(context.Background(), id, "another@kiosk.io")
+				Ω(e).Should(BeNil())
+
+				searches, e = repository.LoadByAgent(context.Background(), "agent@kiosk.io")
+				Ω(e).Should(BeNil())
+				Ω(searches).Should(HaveLen(1))
+
+				e = repository.DeleteByID(context.Background(), id, "agent@kiosk.io")
+				Ω(e).Should(BeNil())
+
+				searches, e = repository.LoadByAgent(context.Background(), "agent@kiosk.io")
+				Ω(e).Should(BeNil())
+				Ω(searches).Should(HaveLen(0))
+			})
+		})
+
+		Context("When LoadMatching called", func() {
+			It("Should load the saved searches matching the issuer, importance level and text", func() {
+				chargebacks := models.SavedSearch{Agent: "agent@kiosk.io", Name: "Chargebacks", Query: "chargeback",
+					ImportanceLevel: models.TicketImportanceLevelHigh}
+				id, e := repository.Insert(context.Background(), chargebacks)
+				Ω(e).Should(BeNil())
+
+				refunds := models.SavedSearch{Agent: "agent@kiosk.io", Name: "Refunds", Query: "refund",
+					Issuer: "Microservice-A"}
+				_, e = repository.Insert(context.Background(), refunds)
+				Ω(e).Should(BeNil())
+
+				searches, e := repository.LoadMatching(context.Background(), "Microservice-B",
+					models.TicketImportanceLevelHigh, "Disputed payment\nA ChargeBack got filed for a refund.")
+				Ω(e).Should(BeNil())
+				Ω(searches).Should(HaveLen(1))
+				Ω(searches[0].ID).Should(Equal(id))
+
+				searches, e = repository.LoadMatching(context.Background(), "Microservice-B",
+					models.TicketImportanceLevelLow, "Disputed payment\nA chargeback got filed.")
+				Ω(e).Should(BeNil())
+				Ω(searches).Should(HaveLen(0))
+			})
+		})
+	})
+})
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// ScheduledReport is the entity model of scheduled_reports table. It describes a reporting query that runs on a cron
+// schedule and gets emailed to its recipients.
+type ScheduledReport struct {
+	Model
+
+	Name       string
+	Cron       string
+	Report     ReportType
+	Issuer     string
+	PeriodDays int
+	Format     ReportFormat
+	Recipients []string
+}
+
+// ScheduledReportRepository is the repository implementation of ScheduledReport model.
+type ScheduledReportRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewScheduledReportRepository returns back a newly created and ready to use ScheduledReportRepository.
+func NewScheduledReportRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *ScheduledReportRepository {
+	return &ScheduledReportRepository{logger: logger, db: db}
+}
+
+// Insert tries to insert a scheduled report into scheduled_reports table.
+func (r *ScheduledReportRepository) Insert(ctx context.Context, report ScheduledReport) *errors.Type {
+	q := `INSERT INTO scheduled_reports (name, cron, report, issuer, period_days, format, recipients, created_at,
+			modified_at) VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW());`
+
+	_, e := r.db.Exec(ctx, q, report.Name, report.Cron, report.Report, report.Issuer, report.PeriodDays, report.Format,
+		strings.Join(report.Recipients, ","))
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// LoadAll tries to load all scheduled reports.
+func (r *ScheduledReportRepository) LoadAll(ctx context.Context) ([]*ScheduledReport, *errors.Type) {
+	q := `SELECT id, name, cron, report, issuer, period_days, format, recipients, created_at, modified_at
+			FROM scheduled_reports ORDER BY id;`
+
+	rows, e := r.db.Query(ctx, q)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	reports := make([]*ScheduledReport, 0)
+	for rows.Next() {
+		report := &ScheduledReport{}
+		var recipients string
+
+		e := rows.Scan(&report.ID, &report.Name, &report.Cron, &report.Report, &report.Issuer, &report.PeriodDays,
+			&report.Format, &recipients, &report.CreatedAt, &report.ModifiedAt)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		report.Recipients = strings.Split(recipients, ",")
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// DeleteByID tries to delete a scheduled report from scheduled_reports table.
+func (r *ScheduledReportRepository) DeleteByID(ctx context.Context, id int64) *errors.Type {
+	q := `DELETE FROM scheduled_reports WHERE id=$1;`
+
+	_, e := r.db.Exec(ctx, q, id)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// ReportType model.
+type ReportType string
+
+// Different report type instances.
+const (
+	ReportTypeDaily ReportType = "DAILY"
+)
+
+// ReportFormat model.
+type ReportFormat string
+
+// Different report format instances.
+const (
+	ReportFormatCSV ReportFormat = "CSV"
+	ReportFormatPDF ReportFormat = "PDF"
+)
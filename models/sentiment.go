@@ -0,0 +1,110 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// sentimentWeight is the weight of the score of the latest comment in the moving average of the ticket.
+const sentimentWeight = 0.5
+
+// Sentiment is the sentiment of the customer on a ticket, scored from -1 for the most negative to 1 for the most
+// positive.
+type Sentiment struct {
+	// Score is the moving average of the scores of the comments of the customer.
+	Score float64
+	// Trend is how much the latest scored comment changed the score, it is negative when the sentiment gets worse.
+	Trend    float64
+	ScoredAt time.Time
+}
+
+// FrustratedTicket is an open ticket whose customer sentiment is negative or getting worse.
+type FrustratedTicket struct {
+	TicketID        int64
+	Issuer          string
+	Owner           string
+	Subject         string
+	ImportanceLevel TicketImportanceLevel
+	Status          TicketStatus
+	Sentiment       Sentiment
+}
+
+// SentimentRepository is the repository implementation of Sentiment model.
+type SentimentRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewSentimentRepository returns back a newly created and ready to use SentimentRepository.
+func NewSentimentRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *SentimentRepository {
+	return &SentimentRepository{logger: logger, db: db}
+}
+
+// Record tries to store the score of a comment and fold it into the sentiment of its ticket, within the same
+// statement. The returned sentiment is the updated sentiment of the ticket.
+func (r *SentimentRepository) Record(ctx context.Context, commentID int64, score float64) (*Sentiment,
+	*errors.Type) {
+
+	q := `WITH comment AS (UPDATE comments SET sentiment_score = $2 WHERE id = $1 RETURNING ticket_id)
+			UPDATE tickets AS t
+			SET sentiment_score = COALESCE(t.sentiment_score * (1 - $3) + $2 * $3, $2),
+			sentiment_trend = COALESCE((t.sentiment_score * (1 - $3) + $2 * $3) - t.sentiment_score, 0),
+			sentiment_scored_at = NOW()
+			FROM comment WHERE t.id = comment.ticket_id
+			RETURNING t.sentiment_score, t.sentiment_trend, t.sentiment_scored_at;`
+
+	sentiment := &Sentiment{}
+	e := r.db.QueryRow(ctx, q, commentID, score, sentimentWeight).Scan(&sentiment.Score, &sentiment.Trend,
+		&sentiment.ScoredAt)
+	if e != nil {
+		if e == pgx.ErrNoRows {
+			return nil, errors.NotFound("comment.not_found", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	return sentiment, nil
+}
+
+// LoadFrustrated tries to load the open tickets whose sentiment score is at most maxScore or whose trend is at most
+// maxTrend, the most negative first. If issuer is not empty only the tickets of that issuer are loaded.
+func (r *SentimentRepository) LoadFrustrated(ctx context.Context, issuer string, maxScore, maxTrend float64,
+	limit int) ([]*FrustratedTicket, *errors.Type) {
+
+	q := `SELECT id, issuer, owner, subject, importance_level, status, sentiment_score, sentiment_trend,
+			sentiment_scored_at FROM tickets WHERE sentiment_score IS NOT NULL AND status NOT IN ($1, $2)
+			AND (sentiment_score <= $3 OR sentiment_trend <= $4) AND ($5 = '' OR issuer = $5)
+			ORDER BY sentiment_score, sentiment_trend, id LIMIT $6;`
+
+	rows, e := r.db.Query(ctx, q, TicketStatusResolved, TicketStatusClosed, maxScore, maxTrend, issuer, limit)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	tickets := make([]*FrustratedTicket, 0)
+	for rows.Next() {
+		ticket := &FrustratedTicket{}
+		e := rows.Scan(&ticket.TicketID, &ticket.Issuer, &ticket.Owner, &ticket.Subject, &ticket.ImportanceLevel,
+			&ticket.Status, &ticket.Sentiment.Score, &ticket.Sentiment.Trend, &ticket.Sentiment.ScoredAt)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		tickets = append(tickets, ticket)
+	}
+
+	return tickets, nil
+}
@@ -0,0 +1,90 @@
+package models_test
+
+import (
+	"context"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Sentiment", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.SentimentRepository
+	var ticketRepository *models.TicketRepository
+	var commentRepository *models.CommentRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewSentimentRepository(zap.S(), db)
+			ticketRepository = models.NewTicketRepository(zap.S(), db)
+			commentRepository = models.NewCommentRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	Describe("SentimentRepository", func() {
+		Context("When Record and LoadFrustrated called", func() {
+			It("Should average the scores and find the ticket once it gets negative", func() {
+				ticket := models.Ticket{Issuer: "Microservice-A", Owner: "user-1", Subject: "Subject",
+					Content: "Content", ImportanceLevel: models.TicketImportanceLevelHigh}
+				id, e := ticketRepository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				first, e := commentRepository.Insert(context.Background(), models.Comment{TicketID: id,
+					Owner: "user-1", Content: "Thanks for the quick reply"})
+				Ω(e).Should(BeNil())
+
+				sentiment, e := repository.Record(context.Background(), first, 0.2)
+				Ω(e).Should(BeNil())
+				Ω(sentiment.Score).Should(BeNumerically("~", 0.2))
+				Ω(sentiment.Trend).Should(BeNumerically("~", 0))
+
+				tickets, e := repository.LoadFrustrated(context.Background(), "Microservice-A", -0.5, -0.3, 10)
+				Ω(e).Should(BeNil())
+				Ω(tickets).Should(BeEmpty())
+
+				second, e := commentRepository.Insert(context.Background(), models.Comment{TicketID: id,
+					Owner: "user-1", Content: "This is still broken, unacceptable!"})
+				Ω(e).Should(BeNil())
+
+				sentiment, e = repository.Record(context.Background(), second, -0.8)
+				Ω(e).Should(BeNil())
+				Ω(sentiment.Score).Should(BeNumerically("~", -0.3))
+				Ω(sentiment.Trend).Should(BeNumerically("~", -0.5))
+
+				tickets, e = repository.LoadFrustrated(context.Background(), "Microservice-A", -0.5, -0.3, 10)
+				Ω(e).Should(BeNil())
+				Ω(tickets).Should(HaveLen(1))
+				Ω(tickets[0].TicketID).Should(Equal(id))
+
+				loaded, e := ticketRepository.LoadByID(context.Background(), id)
+				Ω(e).Should(BeNil())
+				Ω(loaded.Sentiment).ShouldNot(BeNil())
+				Ω(loaded.Sentiment.Score).Should(BeNumerically("~", -0.3))
+			})
+		})
+	})
+})
@@ -0,0 +1,189 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// SLATarget is the entity model of sla_targets table. It holds the contractual times of a customer tier for tickets of
+// an importance level.
+type SLATarget struct {
+	Tier            CustomerTier
+	ImportanceLevel TicketImportanceLevel
+	FirstResponse   time.Duration
+	Resolution      time.Duration
+}
+
+// Apply sets the SLA deadlines of a ticket created at the provided time. A nil target leaves the ticket without
+// deadlines.
+func (t *SLATarget) Apply(ticket *Ticket, createdAt time.Time) {
+	if t == nil {
+		return
+	}
+
+	firstResponseDueAt := createdAt.Add(t.FirstResponse)
+	resolutionDueAt := createdAt.Add(t.Resolution)
+	ticket.FirstResponseDueAt = &firstResponseDueAt
+	ticket.ResolutionDueAt = &resolutionDueAt
+}
+
+// SLATargetRepository is the repository implementation of SLATarget model.
+type SLATargetRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewSLATargetRepository returns back a newly created and ready to use SLATargetRepository.
+func NewSLATargetRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *SLATargetRepository {
+	return &SLATargetRepository{logger: logger, db: db}
+}
+
+// Save tries to insert the target of a tier and importance level or update it if it already exists. Targets are
+// stored with a minute precision.
+func (r *SLATargetRepository) Save(ctx context.Context, target SLATarget) *errors.Type {
+	q := `INSERT INTO sla_targets (tier, importance_level, first_response_minutes, resolution_minutes, created_at,
+			modified_at) VALUES ($1, $2, $3, $4, NOW(), NOW())
+			ON CONFLICT (tier, importance_level) DO UPDATE SET first_response_minutes = EXCLUDED.first_response_minutes,
+			resolution_minutes = EXCLUDED.resolution_minutes, modified_at = NOW();`
+
+	_, e := r.db.Exec(ctx, q, target.Tier, target.ImportanceLevel, int(target.FirstResponse/time.Minute),
+		int(target.Resolution/time.Minute))
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// Load tries to load the target of a tier and importance level, it is nil when there is no such target.
+func (r *SLATargetRepository) Load(ctx context.Context, tier CustomerTier,
+	importanceLevel TicketImportanceLevel) (*SLATarget, *errors.Type) {
+
+	q := `SELECT tier, importance_level, first_response_minutes, resolution_minutes FROM sla_targets
+			WHERE tier = $1 AND importance_level = $2;`
+
+	target, e := r.scan(r.db.QueryRow(ctx, q, tier, importanceLevel))
+	if e != nil {
+		if e == pgx.ErrNoRows {
+			return nil, nil
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	return target, nil
+}
+
+// LoadAll tries to load the targets of all tiers.
+func (r *SLATargetRepository) LoadAll(ctx context.Context) ([]*SLATarget, *errors.Type) {
+	q := `SELECT tier, importance_level, first_response_minutes, resolution_minutes FROM sla_targets
+			ORDER BY tier, first_response_minutes;`
+
+	rows, e := r.db.Query(ctx, q)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	targets := make([]*SLATarget, 0)
+	for rows.Next() {
+		target, e := r.scan(rows)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
+
+// RecomputeDeadlines tries to resolve the tier and SLA deadlines of up to batchSize tickets created within the provided
+// dates, of the issuer if not empty, again from the current tiers and targets, e.g. after a target got corrected. Only
+// tickets after afterID are recomputed, in the order of their ids. Tickets without a target are left without deadlines,
+// as they are on creation. It returns back the id of the last recomputed ticket and the number of recomputed tickets.
+func (r *SLATargetRepository) RecomputeDeadlines(ctx context.Context, issuer, fromDate, toDate string, afterID int64,
+	batchSize int) (int64, int, *errors.Type) {
+
+	q := `WITH batch AS (SELECT t.id, COALESCE(s.tier, $6) AS tier, st.first_response_minutes, st.resolution_minutes
+			FROM tickets AS t LEFT JOIN issuer_settings AS s ON s.issuer = t.issuer
+			LEFT JOIN sla_targets AS st ON st.tier = COALESCE(s.tier, $6) AND st.importance_level = t.importance_level
+			WHERE t.id > $1 AND ($2 = '' OR t.issuer = $2) AND t.created_at >= $3 AND t.created_at < $4
+			ORDER BY t.id LIMIT $5),
+			recomputed AS (UPDATE tickets AS t SET tier = batch.tier,
+			first_response_due_at = t.created_at + batch.first_response_minutes * INTERVAL '1 minute',
+			resolution_due_at = t.created_at + batch.resolution_minutes * INTERVAL '1 minute'
+			FROM batch WHERE t.id = batch.id RETURNING t.id)
+			SELECT COALESCE(MAX(id), $1), COUNT(*) FROM recomputed;`
+
+	var lastID int64
+	var count int
+	e := r.db.QueryRow(ctx, q, afterID, issuer, fromDate, toDate, batchSize, CustomerTierBronze).Scan(&lastID, &count)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return 0, 0, et
+	}
+
+	return lastID, count, nil
+}
+
+func (r *SLATargetRepository) scan(row pgx.Row) (*SLATarget, error) {
+	target := &SLATarget{}
+	var firstResponse, resolution int
+
+	if e := row.Scan(&target.Tier, &target.ImportanceLevel, &firstResponse, &resolution); e != nil {
+		return nil, e
+	}
+
+	target.FirstResponse = time.Duration(firstResponse) * time.Minute
+	target.Resolution = time.Duration(resolution) * time.Minute
+
+	return target, nil
+}
+
+// CustomerTier model.
+type CustomerTier string
+
+// Different customer tier instances.
+const (
+	CustomerTierGold   CustomerTier = "GOLD"
+	CustomerTierSilver CustomerTier = "SILVER"
+	CustomerTierBronze CustomerTier = "BRONZE"
+)
+
+// IsValid reports whether the tier is one of the known tiers.
+func (t CustomerTier) IsValid() bool {
+	switch t {
+	case CustomerTierGold, CustomerTierSilver, CustomerTierBronze:
+		return true
+	}
+
+	return false
+}
+
+// EscalationFactor returns back the factor scaling the delays before escalating the tickets of the tier, so tickets
+// of higher tiers escalate sooner.
+func (t CustomerTier) EscalationFactor() float64 {
+	switch t {
+	case CustomerTierGold:
+		return 0.5
+	case CustomerTierSilver:
+		return 0.75
+	}
+
+	return 1
+}
@@ -0,0 +1,140 @@
+package models_test
+
+import (
+	"context"
+	"time"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("SLATarget", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.SLATargetRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewSLATargetRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	Describe("SLATargetRepository", func() {
+		Context("When LoadAll called on a fresh database", func() {
+			It("Should return back the seeded targets of all tiers", func() {
+				targets, e := repository.LoadAll(context.Background())
+				Ω(e).Should(BeNil())
+				Ω(targets).Should(HaveLen(12))
+			})
+		})
+
+		Context("When Save called for an existing target", func() {
+			It("Should replace its times", func() {
+				target := models.SLATarget{Tier: models.CustomerTierGold,
+					ImportanceLevel: models.TicketImportanceLevelCritical, FirstResponse: 10 * time.Minute,
+					Resolution: 2 * time.Hour}
+
+				e := repository.Save(context.Background(), target)
+				Ω(e).Should(BeNil())
+
+				loaded, e := repository.Load(context.Background(), models.CustomerTierGold,
+					models.TicketImportanceLevelCritical)
+				Ω(e).Should(BeNil())
+				Ω(*loaded).Should(Equal(target))
+			})
+		})
+
+		Context("When RecomputeDeadlines called", func() {
+			It("Should resolve the deadlines of tickets from the current targets", func() {
+				ticket := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user@example.com",
+					Subject:         "Technical Problem",
+					Content:         "Hello, i have some issues with REST API Docs!",
+					Metadata:        `{"ip":"192.168.1.1"}`,
+					ImportanceLevel: models.TicketImportanceLevelMedium,
+				}
+
+				ticketRepository := models.NewTicketRepository(zap.S(), db)
+				for i := 0; i < 3; i++ {
+					_, e := ticketRepository.Insert(context.Background(), ticket)
+					Ω(e).Should(BeNil())
+				}
+
+				fromDate := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339Nano)
+				toDate := time.Now().Add(time.Hour).UTC().Format(time.RFC3339Nano)
+
+				lastID, count, e := repository.RecomputeDeadlines(context.Background(), "", fromDate, toDate, 0, 2)
+				Ω(e).Should(BeNil())
+				Ω(lastID).Should(Equal(int64(2)))
+				Ω(count).Should(Equal(2))
+
+				lastID, count, e = repository.RecomputeDeadlines(context.Background(), "", fromDate, toDate, lastID, 2)
+				Ω(e).Should(BeNil())
+				Ω(lastID).Should(Equal(int64(3)))
+				Ω(count).Should(Equal(1))
+
+				var tier string
+				var firstResponse, resolution int
+				q := `SELECT tier, EXTRACT(EPOCH FROM first_response_due_at - created_at)::INT,
+						EXTRACT(EPOCH FROM resolution_due_at - created_at)::INT FROM tickets WHERE id = 3;`
+				Ω(db.QueryRow(context.Background(), q).Scan(&tier, &firstResponse, &resolution)).Should(BeNil())
+				Ω(tier).Should(Equal(string(models.CustomerTierBronze)))
+				Ω(firstResponse).Should(Equal(1440 * 60))
+				Ω(resolution).Should(Equal(5760 * 60))
+			})
+		})
+
+		Context("When Load called for an unknown tier", func() {
+			It("Should return back nil", func() {
+				target, e := repository.Load(context.Background(), "PLATINUM", models.TicketImportanceLevelLow)
+				Ω(e).Should(BeNil())
+				Ω(target).Should(BeNil())
+			})
+		})
+	})
+
+	Describe("Apply", func() {
+		It("Should set the deadlines of tickets relative to their creation", func() {
+			createdAt := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+			target := &models.SLATarget{FirstResponse: time.Hour, Resolution: 4 * time.Hour}
+			ticket := &models.Ticket{}
+
+			target.Apply(ticket, createdAt)
+			Ω(*ticket.FirstResponseDueAt).Should(Equal(createdAt.Add(time.Hour)))
+			Ω(*ticket.ResolutionDueAt).Should(Equal(createdAt.Add(4 * time.Hour)))
+		})
+
+		It("Should leave tickets without deadlines when there is no target", func() {
+			var target *models.SLATarget
+			ticket := &models.Ticket{}
+
+			target.Apply(ticket, time.Now())
+			Ω(ticket.FirstResponseDueAt).Should(BeNil())
+			Ω(ticket.ResolutionDueAt).Should(BeNil())
+		})
+	})
+})
@@ -0,0 +1,144 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// StatusPageIncident is the entity model of status_page_incidents table. It is the customer visible incident published
+// for a ticket on a status page, e.g. Statuspage or Cachet.
+type StatusPageIncident struct {
+	TicketID int64
+	Provider StatusPageProvider
+	// ExternalID identifies the incident at the provider, it is empty until the incident gets published.
+	ExternalID string
+	Title      string
+	Message    string
+	Status     StatusPageIncidentStatus
+	SyncStatus ReferenceSyncStatus
+	// SyncFailure describes why the last publishing failed.
+	SyncFailure string
+	CreatedAt   time.Time
+	ModifiedAt  time.Time
+}
+
+// StatusPageIncidentRepository is the repository implementation of StatusPageIncident model.
+type StatusPageIncidentRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewStatusPageIncidentRepository returns back a newly created and ready to use StatusPageIncidentRepository.
+func NewStatusPageIncidentRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *StatusPageIncidentRepository {
+	return &StatusPageIncidentRepository{logger: logger, db: db}
+}
+
+// Save tries to insert the incident of a ticket or update its title, message and status if it already exists. Either
+// way the incident is marked as pending to be published.
+func (r *StatusPageIncidentRepository) Save(ctx context.Context, incident StatusPageIncident) *errors.Type {
+	q := `INSERT INTO status_page_incidents (ticket_id, provider, title, message, status, sync_status, created_at,
+			modified_at) VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+			ON CONFLICT (ticket_id) DO UPDATE SET title = EXCLUDED.title, message = EXCLUDED.message,
+			status = EXCLUDED.status, sync_status = EXCLUDED.sync_status, modified_at = NOW();`
+
+	_, e := r.db.Exec(ctx, q, incident.TicketID, incident.Provider, incident.Title, incident.Message, incident.Status,
+		ReferenceSyncStatusPending)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// LoadByTicketID tries to load the incident of a ticket.
+func (r *StatusPageIncidentRepository) LoadByTicketID(ctx context.Context, ticketID int64) (*StatusPageIncident,
+	*errors.Type) {
+
+	q := `SELECT ticket_id, provider, external_id, title, message, status, sync_status, sync_failure, created_at,
+			modified_at FROM status_page_incidents WHERE ticket_id = $1;`
+
+	incident := &StatusPageIncident{}
+	var externalID, failure sql.NullString
+
+	e := r.db.QueryRow(ctx, q, ticketID).Scan(&incident.TicketID, &incident.Provider, &externalID, &incident.Title,
+		&incident.Message, &incident.Status, &incident.SyncStatus, &failure, &incident.CreatedAt, &incident.ModifiedAt)
+	if e != nil {
+		if e == pgx.ErrNoRows {
+			return nil, errors.NotFound("incident.not_found", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	incident.ExternalID = externalID.String
+	incident.SyncFailure = failure.String
+
+	return incident, nil
+}
+
+// UpdateSyncStatus tries to record the external id of the incident and the outcome of publishing it as it was
+// modified at modifiedAt. The outcome is only recorded when the incident was not modified meanwhile, the returned
+// value is false otherwise so it gets published again.
+func (r *StatusPageIncidentRepository) UpdateSyncStatus(ctx context.Context, ticketID int64, modifiedAt time.Time,
+	externalID string, status ReferenceSyncStatus, failure string) (bool, *errors.Type) {
+
+	q := `UPDATE status_page_incidents SET external_id = COALESCE(NULLIF($1, ''), external_id),
+			sync_status = CASE WHEN modified_at = $5 THEN $2 ELSE sync_status END,
+			sync_failure = CASE WHEN modified_at = $5 THEN NULLIF($3, '') ELSE sync_failure END
+			WHERE ticket_id = $4 RETURNING modified_at = $5;`
+
+	var unmodified bool
+	e := r.db.QueryRow(ctx, q, externalID, status, failure, ticketID, modifiedAt).Scan(&unmodified)
+	if e != nil {
+		if e == pgx.ErrNoRows {
+			return true, nil
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return false, et
+	}
+
+	return unmodified, nil
+}
+
+// StatusPageProvider model.
+type StatusPageProvider string
+
+// Different status page provider instances.
+const (
+	StatusPageProviderStatuspage StatusPageProvider = "STATUSPAGE"
+	StatusPageProviderCachet     StatusPageProvider = "CACHET"
+)
+
+// StatusPageIncidentStatus model.
+type StatusPageIncidentStatus string
+
+// Different status page incident status instances, the common statuses of status page providers.
+const (
+	StatusPageIncidentStatusInvestigating StatusPageIncidentStatus = "INVESTIGATING"
+	StatusPageIncidentStatusIdentified    StatusPageIncidentStatus = "IDENTIFIED"
+	StatusPageIncidentStatusMonitoring    StatusPageIncidentStatus = "MONITORING"
+	StatusPageIncidentStatusResolved      StatusPageIncidentStatus = "RESOLVED"
+)
+
+// IsValid reports whether the status is one of the known statuses.
+func (s StatusPageIncidentStatus) IsValid() bool {
+	switch s {
+	case StatusPageIncidentStatusInvestigating, StatusPageIncidentStatusIdentified, StatusPageIncidentStatusMonitoring,
+		StatusPageIncidentStatusResolved:
+		return true
+	}
+
+	return false
+}
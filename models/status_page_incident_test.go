@@ -0,0 +1,95 @@
+package models_test
+
+import (
+	"context"
+	"net/http"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("StatusPageIncident", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.StatusPageIncidentRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewStatusPageIncidentRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	Describe("StatusPageIncidentRepository", func() {
+		Context("When LoadByTicketID called for tickets that are not incidents", func() {
+			It("Should return back not found error", func() {
+				_, e := repository.LoadByTicketID(context.Background(), 1)
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusNotFound))
+			})
+		})
+
+		Context("When Save and UpdateSyncStatus called", func() {
+			It("Should record the outcome of publishing only for unmodified incidents", func() {
+				incident := models.StatusPageIncident{TicketID: 1, Provider: models.StatusPageProviderCachet,
+					Title: "Payments are delayed", Message: "We are investigating.",
+					Status: models.StatusPageIncidentStatusInvestigating}
+
+				e := repository.Save(context.Background(), incident)
+				Ω(e).Should(BeNil())
+
+				loaded, e := repository.LoadByTicketID(context.Background(), 1)
+				Ω(e).Should(BeNil())
+				Ω(loaded.SyncStatus).Should(Equal(models.ReferenceSyncStatusPending))
+				Ω(loaded.ExternalID).Should(BeEmpty())
+
+				incident.Status = models.StatusPageIncidentStatusIdentified
+				e = repository.Save(context.Background(), incident)
+				Ω(e).Should(BeNil())
+
+				unmodified, e := repository.UpdateSyncStatus(context.Background(), 1, loaded.ModifiedAt, "42",
+					models.ReferenceSyncStatusSynced, "")
+				Ω(e).Should(BeNil())
+				Ω(unmodified).Should(BeFalse())
+
+				loaded, e = repository.LoadByTicketID(context.Background(), 1)
+				Ω(e).Should(BeNil())
+				Ω(loaded.ExternalID).Should(Equal("42"))
+				Ω(loaded.Status).Should(Equal(models.StatusPageIncidentStatusIdentified))
+				Ω(loaded.SyncStatus).Should(Equal(models.ReferenceSyncStatusPending))
+
+				unmodified, e = repository.UpdateSyncStatus(context.Background(), 1, loaded.ModifiedAt, "",
+					models.ReferenceSyncStatusSynced, "")
+				Ω(e).Should(BeNil())
+				Ω(unmodified).Should(BeTrue())
+
+				loaded, e = repository.LoadByTicketID(context.Background(), 1)
+				Ω(e).Should(BeNil())
+				Ω(loaded.ExternalID).Should(Equal("42"))
+				Ω(loaded.SyncStatus).Should(Equal(models.ReferenceSyncStatusSynced))
+			})
+		})
+	})
+})
@@ -0,0 +1,298 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// tenantAPIKeyPrefix starts every tenant API key, so leaked keys are easy to spot.
+const tenantAPIKeyPrefix = "kiosk_"
+
+// Tenant is the entity model of tenants table. A tenant is an issuer onboarded through provisioning, which can be
+// deactivated once the product it represents is retired.
+type Tenant struct {
+	Issuer        string
+	Name          string
+	Active        bool
+	CreatedAt     time.Time
+	ModifiedAt    time.Time
+	DeactivatedAt *time.Time
+}
+
+// TenantAPIKey is the entity model of tenant_api_keys table. The key itself is never stored, only its hash.
+type TenantAPIKey struct {
+	ID        int64
+	Issuer    string
+	Prefix    string
+	KeyHash   string
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
+
+// GenerateTenantAPIKey returns back a new random API key for the issuer along with its entity model.
+func GenerateTenantAPIKey(issuer string) (string, *TenantAPIKey, error) {
+	random := make([]byte, 32)
+	if _, e := rand.Read(random); e != nil {
+		return "", nil, e
+	}
+
+	key := tenantAPIKeyPrefix + hex.EncodeToString(random)
+	return key, &TenantAPIKey{Issuer: issuer, Prefix: key[:len(tenantAPIKeyPrefix)+8], KeyHash: HashTenantAPIKey(key)},
+		nil
+}
+
+// HashTenantAPIKey returns back the hex encoded SHA-256 hash of the key, as it is stored.
+func HashTenantAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// TenantRepository is the repository implementation of Tenant model.
+type TenantRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewTenantRepository returns back a newly created and ready to use TenantRepository.
+func NewTenantRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *TenantRepository {
+	return &TenantRepository{logger: logger, db: db}
+}
+
+// Provision tries to insert the tenant along with its issuer settings and first API key in a single transaction, so
+// a tenant is either fully onboarded or not at all. The tier of the settings selects the SLA targets of the tenant.
+func (r *TenantRepository) Provision(ctx context.Context, tenant Tenant, settings IssuerSettings,
+	apiKey TenantAPIKey) *errors.Type {
+
+	tx, e := r.db.Begin(ctx)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	q := `INSERT INTO tenants (issuer, name, active, created_at, modified_at) VALUES ($1, $2, TRUE, NOW(), NOW())
+			ON CONFLICT (issuer) DO NOTHING;`
+
+	tag, e := tx.Exec(ctx, q, tenant.Issuer, tenant.Name)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errors.AlreadyExists("tenant.already_exists", "")
+	}
+
+	_, e = tx.Exec(ctx, saveIssuerSettingsQuery, settings.Issuer, settings.DefaultImportanceLevel,
+		settings.DefaultStatus, settings.NotificationMode, settings.DigestFrequency, settings.Tier,
+		settings.SystemComments, settings.PublicIDSalt, settings.PublicIDMinLength)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	q = `INSERT INTO tenant_api_keys (issuer, prefix, key_hash, created_at) VALUES ($1, $2, $3, NOW());`
+
+	if _, e := tx.Exec(ctx, q, apiKey.Issuer, apiKey.Prefix, apiKey.KeyHash); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	if e := tx.Commit(ctx); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// Deactivate tries to deactivate the tenant of an issuer and revoke all of its API keys in a single transaction.
+func (r *TenantRepository) Deactivate(ctx context.Context, issuer string) *errors.Type {
+	tx, e := r.db.Begin(ctx)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	q := `UPDATE tenants SET active = FALSE, deactivated_at = COALESCE(deactivated_at, NOW()), modified_at = NOW()
+			WHERE issuer = $1;`
+
+	tag, e := tx.Exec(ctx, q, issuer)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errors.NotFound("tenant.not_found", "")
+	}
+
+	q = `UPDATE tenant_api_keys SET revoked_at = NOW() WHERE issuer = $1 AND revoked_at IS NULL;`
+
+	if _, e := tx.Exec(ctx, q, issuer); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	if e := tx.Commit(ctx); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// RotateAPIKey tries to insert a new API key of an active tenant and revoke all of its other keys in a single
+// transaction, and returns back the number of revoked keys.
+func (r *TenantRepository) RotateAPIKey(ctx context.Context, apiKey TenantAPIKey) (int64, *errors.Type) {
+	tx, e := r.db.Begin(ctx)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return 0, et
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var active bool
+	if e := tx.QueryRow(ctx, `SELECT active FROM tenants WHERE issuer = $1 FOR UPDATE;`, apiKey.Issuer).
+		Scan(&active); e != nil {
+
+		if e == pgx.ErrNoRows {
+			return 0, errors.NotFound("tenant.not_found", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return 0, et
+	}
+
+	if !active {
+		return 0, errors.PreconditionFailed("tenant.not_active", "")
+	}
+
+	q := `UPDATE tenant_api_keys SET revoked_at = NOW() WHERE issuer = $1 AND revoked_at IS NULL;`
+
+	tag, e := tx.Exec(ctx, q, apiKey.Issuer)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return 0, et
+	}
+
+	q = `INSERT INTO tenant_api_keys (issuer, prefix, key_hash, created_at) VALUES ($1, $2, $3, NOW());`
+
+	if _, e := tx.Exec(ctx, q, apiKey.Issuer, apiKey.Prefix, apiKey.KeyHash); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return 0, et
+	}
+
+	if e := tx.Commit(ctx); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return 0, et
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// Load tries to load the tenant of an issuer.
+func (r *TenantRepository) Load(ctx context.Context, issuer string) (*Tenant, *errors.Type) {
+	q := `SELECT issuer, name, active, created_at, modified_at, deactivated_at FROM tenants WHERE issuer = $1;`
+
+	tenant := &Tenant{}
+	e := r.db.QueryRow(ctx, q, issuer).Scan(&tenant.Issuer, &tenant.Name, &tenant.Active, &tenant.CreatedAt,
+		&tenant.ModifiedAt, &tenant.DeactivatedAt)
+	if e != nil {
+		if e == pgx.ErrNoRows {
+			return nil, errors.NotFound("tenant.not_found", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	return tenant, nil
+}
+
+// LoadAll tries to load all tenants ordered by issuer.
+func (r *TenantRepository) LoadAll(ctx context.Context) ([]*Tenant, *errors.Type) {
+	q := `SELECT issuer, name, active, created_at, modified_at, deactivated_at FROM tenants ORDER BY issuer;`
+
+	rows, e := r.db.Query(ctx, q)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	tenants := make([]*Tenant, 0)
+	for rows.Next() {
+		tenant := &Tenant{}
+		e := rows.Scan(&tenant.Issuer, &tenant.Name, &tenant.Active, &tenant.CreatedAt, &tenant.ModifiedAt,
+			&tenant.DeactivatedAt)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		tenants = append(tenants, tenant)
+	}
+
+	return tenants, nil
+}
+
+// IsActive reports whether the issuer may open tickets, i.e. it is not a deactivated tenant. Issuers that predate
+// provisioning have no tenant and are active.
+func (r *TenantRepository) IsActive(ctx context.Context, issuer string) (bool, *errors.Type) {
+	q := `SELECT NOT EXISTS(SELECT 1 FROM tenants WHERE issuer = $1 AND active = FALSE);`
+
+	var active bool
+	if e := r.db.QueryRow(ctx, q, issuer).Scan(&active); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return false, et
+	}
+
+	return active, nil
+}
+
+// Authenticate tries to find the issuer of an API key, which must be neither revoked nor of a deactivated tenant.
+func (r *TenantRepository) Authenticate(ctx context.Context, key string) (string, *errors.Type) {
+	q := `SELECT t.issuer FROM tenant_api_keys k JOIN tenants t ON t.issuer = k.issuer
+			WHERE k.key_hash = $1 AND k.revoked_at IS NULL AND t.active;`
+
+	var issuer string
+	if e := r.db.QueryRow(ctx, q, HashTenantAPIKey(key)).Scan(&issuer); e != nil {
+		if e == pgx.ErrNoRows {
+			return "", errors.Unauthorized("")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return "", et
+	}
+
+	return issuer, nil
+}
@@ -0,0 +1,168 @@
+package models_test
+
+import (
+	"context"
+	"net/http"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Tenant", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.TenantRepository
+	var settingsRepository *models.IssuerSettingsRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewTenantRepository(zap.S(), db)
+			settingsRepository = models.NewIssuerSettingsRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	provision := func(issuer string) string {
+		key, apiKey, err := models.GenerateTenantAPIKey(issuer)
+		Ω(err).Should(BeNil())
+
+		settings := models.DefaultIssuerSettings(issuer)
+		settings.Tier = models.CustomerTierGold
+
+		tenant := models.Tenant{Issuer: issuer, Name: "Microservice A"}
+		Ω(repository.Provision(context.Background(), tenant, *settings, *apiKey)).Should(BeNil())
+		return key
+	}
+
+	Describe("TenantRepository", func() {
+		Context("When Provision called", func() {
+			It("Should store the tenant, its settings and an API key that authenticates", func() {
+				key := provision("Microservice-A")
+
+				tenant, e := repository.Load(context.Background(), "Microservice-A")
+				Ω(e).Should(BeNil())
+				Ω(tenant.Active).Should(BeTrue())
+				Ω(tenant.DeactivatedAt).Should(BeNil())
+
+				settings, e := settingsRepository.LoadByIssuer(context.Background(), "Microservice-A")
+				Ω(e).Should(BeNil())
+				Ω(settings.Tier).Should(Equal(models.CustomerTierGold))
+
+				issuer, e := repository.Authenticate(context.Background(), key)
+				Ω(e).Should(BeNil())
+				Ω(issuer).Should(Equal("Microservice-A"))
+			})
+		})
+
+		Context("When Provision called twice for an issuer", func() {
+			It("Should return already exists error and keep the first API key only", func() {
+				provision("Microservice-A")
+
+				key, apiKey, err := models.GenerateTenantAPIKey("Microservice-A")
+				Ω(err).Should(BeNil())
+
+				tenant := models.Tenant{Issuer: "Microservice-A", Name: "Microservice A"}
+				e := repository.Provision(context.Background(), tenant, *models.DefaultIssuerSettings("Microservice-A"),
+					*apiKey)
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusConflict))
+
+				_, e = repository.Authenticate(context.Background(), key)
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusUnauthorized))
+			})
+		})
+
+		Context("When Deactivate called", func() {
+			It("Should deactivate the tenant and revoke its API keys", func() {
+				key := provision("Microservice-A")
+
+				Ω(repository.Deactivate(context.Background(), "Microservice-A")).Should(BeNil())
+
+				active, e := repository.IsActive(context.Background(), "Microservice-A")
+				Ω(e).Should(BeNil())
+				Ω(active).Should(BeFalse())
+
+				_, e = repository.Authenticate(context.Background(), key)
+				Ω(e).ShouldNot(BeNil())
+
+				tenants, e := repository.LoadAll(context.Background())
+				Ω(e).Should(BeNil())
+				Ω(tenants).Should(HaveLen(1))
+				Ω(tenants[0].DeactivatedAt).ShouldNot(BeNil())
+			})
+		})
+
+		Context("When RotateAPIKey called", func() {
+			It("Should revoke the previous API keys and store the new one", func() {
+				previous := provision("Microservice-A")
+
+				key, apiKey, err := models.GenerateTenantAPIKey("Microservice-A")
+				Ω(err).Should(BeNil())
+
+				revoked, e := repository.RotateAPIKey(context.Background(), *apiKey)
+				Ω(e).Should(BeNil())
+				Ω(revoked).Should(Equal(int64(1)))
+
+				_, e = repository.Authenticate(context.Background(), previous)
+				Ω(e).ShouldNot(BeNil())
+
+				issuer, e := repository.Authenticate(context.Background(), key)
+				Ω(e).Should(BeNil())
+				Ω(issuer).Should(Equal("Microservice-A"))
+			})
+
+			It("Should return error when tenant is deactivated or does not exist", func() {
+				provision("Microservice-A")
+				Ω(repository.Deactivate(context.Background(), "Microservice-A")).Should(BeNil())
+
+				_, apiKey, err := models.GenerateTenantAPIKey("Microservice-A")
+				Ω(err).Should(BeNil())
+
+				_, e := repository.RotateAPIKey(context.Background(), *apiKey)
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusPreconditionFailed))
+
+				_, apiKey, err = models.GenerateTenantAPIKey("Microservice-B")
+				Ω(err).Should(BeNil())
+
+				_, e = repository.RotateAPIKey(context.Background(), *apiKey)
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusNotFound))
+			})
+		})
+
+		Context("When IsActive called for an issuer without tenant", func() {
+			It("Should report it active", func() {
+				active, e := repository.IsActive(context.Background(), "Microservice-B")
+				Ω(e).Should(BeNil())
+				Ω(active).Should(BeTrue())
+
+				Ω(repository.Deactivate(context.Background(), "Microservice-B").HTTPStatusCode).
+					Should(Equal(http.StatusNotFound))
+			})
+		})
+	})
+})
@@ -3,11 +3,15 @@ package models
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/db/filters"
 	"github.com/jibitters/kiosk/errors"
 	"go.uber.org/zap"
 )
@@ -23,7 +27,76 @@ type Ticket struct {
 	Metadata        string
 	ImportanceLevel TicketImportanceLevel
 	Status          TicketStatus
-	Comments        []*Comment
+	// Tier, FirstResponseDueAt and ResolutionDueAt are resolved from the SLA targets of the issuer on creation, the
+	// deadlines are nil when there is no target.
+	Tier               CustomerTier
+	FirstResponseDueAt *time.Time
+	ResolutionDueAt    *time.Time
+	// SnoozedUntil hides the ticket from the active queues until then, it is nil for tickets that are not snoozed.
+	SnoozedUntil *time.Time
+	// Revision is the revision of the subject and content, starting from 1 and incremented on each edit of them.
+	Revision int
+	// Fingerprint identifies the ticket for its issuer, e.g. an alert of a monitoring system. It is optional.
+	Fingerprint string
+	// ExternalID identifies the ticket a transferred ticket originates from, it is empty for tickets created here.
+	ExternalID string
+	// TimeSpent is the total duration of the work logs of the ticket.
+	TimeSpent time.Duration
+	// Billable tickets are invoiced by their time spent.
+	Billable bool
+	// ApprovalState is the state of the latest approval requested for the ticket, it is empty when there is none.
+	ApprovalState ApprovalState
+	// Assignee is the agent the ticket is assigned to, it is empty for unassigned tickets.
+	Assignee string
+	// Resolution is captured when resolving the ticket, ResolvedAt is when it got resolved last or nil if never.
+	Resolution Resolution
+	ResolvedAt *time.Time
+	// Sentiment is the sentiment of the customer on the ticket, it is nil until a comment of the customer is scored.
+	Sentiment *Sentiment
+	// MergedInto is the id of the ticket this one got merged into as a duplicate, it is zero when not merged.
+	MergedInto int64
+	// Archived tickets are loaded from archived_tickets table and are read only, see ArchiveRepository.
+	Archived bool
+	Comments []*Comment
+}
+
+// ticketDefaultOrders orders tickets when no order is given, the most recently modified first.
+var ticketDefaultOrders = []Order{{Field: "modifiedAt", Descending: true}}
+
+// Cursor returns back the position of the ticket among tickets ordered by orders, the most recently modified first
+// when empty.
+func (t *Ticket) Cursor(orders []Order) Cursor {
+	keys := orderKeys(orders, ticketDefaultOrders, TicketOrderColumns)
+
+	cursor := make(Cursor, 0, len(keys))
+	for _, key := range keys {
+		var value *time.Time
+		switch key.column {
+		case "id":
+			id := strconv.FormatInt(t.ID, 10)
+			cursor = append(cursor, &id)
+			continue
+		case "created_at":
+			value = &t.CreatedAt
+		case "modified_at":
+			value = &t.ModifiedAt
+		case "resolved_at":
+			value = t.ResolvedAt
+		case "first_response_due_at":
+			value = t.FirstResponseDueAt
+		case "resolution_due_at":
+			value = t.ResolutionDueAt
+		}
+
+		if value == nil {
+			cursor = append(cursor, nil)
+		} else {
+			formatted := value.UTC().Format(time.RFC3339Nano)
+			cursor = append(cursor, &formatted)
+		}
+	}
+
+	return cursor
 }
 
 // TicketRepository is the repository implementation of Ticket model.
@@ -37,43 +110,61 @@ func NewTicketRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *TicketRep
 	return &TicketRepository{logger: logger, db: db}
 }
 
-// Insert tries to insert a ticket into tickets table.
-func (r *TicketRepository) Insert(ctx context.Context, ticket Ticket) *errors.Type {
-	q := `INSERT INTO tickets (issuer, owner, subject, content, metadata, importance_level, status, created_at,
-			modified_at) VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW());`
+// Insert tries to insert a ticket into tickets table and returns back its id. Tickets without status are inserted as
+// NEW.
+func (r *TicketRepository) Insert(ctx context.Context, ticket Ticket) (int64, *errors.Type) {
+	q := `INSERT INTO tickets (issuer, owner, subject, content, metadata, importance_level, status, tier,
+			first_response_due_at, resolution_due_at, waiting_since, fingerprint, billable, created_at, modified_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9, $10, CASE WHEN $7 = $11 THEN NOW() END,
+			NULLIF($12, ''), $13, NOW(), NOW()) RETURNING id;`
+
+	status := ticket.Status
+	if status == "" {
+		status = TicketStatusNew
+	}
 
-	_, e := r.db.Exec(ctx, q, ticket.Issuer, ticket.Owner, ticket.Subject, ticket.Content, ticket.Metadata,
-		ticket.ImportanceLevel, TicketStatusNew)
+	var id int64
+	e := r.db.QueryRow(ctx, q, ticket.Issuer, ticket.Owner, ticket.Subject, ticket.Content,
+		metadataDocument(ticket.Metadata), ticket.ImportanceLevel, status, ticket.Tier, utc(ticket.FirstResponseDueAt),
+		utc(ticket.ResolutionDueAt), TicketStatusWaitingOnCustomer, ticket.Fingerprint, ticket.Billable).Scan(&id)
 	if e != nil {
 		et := errors.InternalServerError("unknown", "")
 		r.logger.Error(et.FingerPrint, ": ", e.Error())
-		return et
+		return 0, et
 	}
 
-	return nil
+	return id, nil
 }
 
-// LoadByID tries to load a ticket and its comments from tickets table.
+// LoadByID tries to load a ticket and its comments from tickets table. Comments are aggregated as JSON within the same
+// query, so busy tickets are loaded in a single round trip.
 func (r *TicketRepository) LoadByID(ctx context.Context, id int64) (*Ticket, *errors.Type) {
-	q := `SELECT id, issuer, owner, subject, content, metadata, importance_level, status, created_at, modified_at
-			FROM tickets WHERE id = $1;`
-
-	commentsQ := `SELECT id, ticket_id, owner, content, metadata, created_at, modified_at FROM comments WHERE
-					ticket_id = $1 ORDER BY created_at DESC;`
-
-	batch := &pgx.Batch{}
-	batch.Queue(q, id)
-	batch.Queue(commentsQ, id)
-
-	results := r.db.SendBatch(ctx, batch)
-	defer func() { _ = results.Close() }()
+	q := `SELECT t.id, t.issuer, t.owner, t.subject, t.content, t.metadata #>> '{}', t.importance_level, t.status,
+			COALESCE(t.tier, ''), t.first_response_due_at, t.resolution_due_at, t.snoozed_until, t.revision,
+			t.time_spent_minutes, t.billable, COALESCE(t.approval_state, ''), COALESCE(t.assignee, ''),
+			COALESCE(t.resolution_category, ''), COALESCE(t.resolution_sub_category, ''), COALESCE(t.root_cause, ''),
+			t.resolved_at, t.sentiment_score,
+			t.sentiment_trend, t.sentiment_scored_at, COALESCE(t.merged_into, 0), t.created_at, t.modified_at,
+			COALESCE(json_agg(json_build_object('id', c.id, 'owner', c.owner, 'content', c.content,
+			'metadata', c.metadata, 'authorType', c.author_type, 'source', c.source, 'revision', c.revision,
+			'editedAt', c.edited_at, 'createdAt', c.created_at, 'modifiedAt', c.modified_at) ORDER BY c.created_at DESC)
+			FILTER (WHERE c.id IS NOT NULL), '[]')
+			FROM tickets AS t LEFT JOIN comments AS c ON c.ticket_id = t.id WHERE t.id = $1 GROUP BY t.id;`
 
 	ticket := &Ticket{}
 	var metadata sql.NullString
+	var timeSpent int
+	var comments []byte
+	var sentimentScore, sentimentTrend *float64
+	var sentimentScoredAt *time.Time
 
-	row := results.QueryRow()
+	row := r.db.QueryRow(ctx, q, id)
 	e := row.Scan(&ticket.ID, &ticket.Issuer, &ticket.Owner, &ticket.Subject, &ticket.Content, &metadata,
-		&ticket.ImportanceLevel, &ticket.Status, &ticket.CreatedAt, &ticket.ModifiedAt)
+		&ticket.ImportanceLevel, &ticket.Status, &ticket.Tier, &ticket.FirstResponseDueAt, &ticket.ResolutionDueAt,
+		&ticket.SnoozedUntil, &ticket.Revision, &timeSpent, &ticket.Billable, &ticket.ApprovalState, &ticket.Assignee,
+		&ticket.Resolution.Category, &ticket.Resolution.SubCategory, &ticket.Resolution.RootCause, &ticket.ResolvedAt,
+		&sentimentScore, &sentimentTrend, &sentimentScoredAt, &ticket.MergedInto, &ticket.CreatedAt, &ticket.ModifiedAt,
+		&comments)
 	if e != nil {
 		if e == pgx.ErrNoRows {
 			return nil, errors.NotFound("ticket.not_found", "")
@@ -88,7 +179,248 @@ func (r *TicketRepository) LoadByID(ctx context.Context, id int64) (*Ticket, *er
 		ticket.Metadata = metadata.String
 	}
 
-	rows, e := results.Query()
+	ticket.TimeSpent = time.Duration(timeSpent) * time.Minute
+
+	if sentimentScore != nil && sentimentTrend != nil && sentimentScoredAt != nil {
+		ticket.Sentiment = &Sentiment{Score: *sentimentScore, Trend: *sentimentTrend, ScoredAt: *sentimentScoredAt}
+	}
+
+	if ticket.Comments, e = decodeComments(ticket.ID, comments); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	return ticket, nil
+}
+
+// LoadIDByFingerprint tries to load the id of the latest ticket of an issuer with the fingerprint, created after the
+// provided time. Resolved and closed tickets are left out, so recurring issues get new tickets.
+func (r *TicketRepository) LoadIDByFingerprint(ctx context.Context, issuer, fingerprint string,
+	createdAfter time.Time) (int64, *errors.Type) {
+
+	q := `SELECT id FROM tickets WHERE issuer = $1 AND fingerprint = $2 AND created_at > $3 AND status NOT IN ($4, $5)
+			ORDER BY created_at DESC LIMIT 1;`
+
+	var id int64
+	e := r.db.QueryRow(ctx, q, issuer, fingerprint, createdAfter, TicketStatusResolved, TicketStatusClosed).Scan(&id)
+	if e != nil {
+		if e == pgx.ErrNoRows {
+			return 0, errors.NotFound("ticket.not_found", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return 0, et
+	}
+
+	return id, nil
+}
+
+// aggregatedComment is the JSON representation of a comment row built by json_build_object.
+type aggregatedComment struct {
+	ID         int64   `json:"id"`
+	Owner      string  `json:"owner"`
+	Content    string  `json:"content"`
+	Metadata   *string `json:"metadata"`
+	AuthorType string  `json:"authorType"`
+	Source     *string `json:"source"`
+	Revision   int     `json:"revision"`
+	EditedAt   *string `json:"editedAt"`
+	CreatedAt  string  `json:"createdAt"`
+	ModifiedAt string  `json:"modifiedAt"`
+}
+
+// timestampLayout is the JSON representation of timestamp columns, which carry no time zone.
+const timestampLayout = "2006-01-02T15:04:05.999999999"
+
+func decodeComments(ticketID int64, aggregated []byte) ([]*Comment, error) {
+	rows := make([]aggregatedComment, 0)
+	if e := json.Unmarshal(aggregated, &rows); e != nil {
+		return nil, e
+	}
+
+	var comments []*Comment
+	for _, row := range rows {
+		comment := &Comment{TicketID: ticketID, Owner: row.Owner, Content: row.Content,
+			AuthorType: CommentAuthorType(row.AuthorType), Revision: row.Revision}
+		comment.ID = row.ID
+
+		if row.Metadata != nil {
+			comment.Metadata = *row.Metadata
+		}
+
+		if row.Source != nil {
+			comment.Source = CommentSource(*row.Source)
+		}
+
+		var e error
+		if comment.CreatedAt, e = time.Parse(timestampLayout, row.CreatedAt); e != nil {
+			return nil, e
+		}
+
+		if comment.ModifiedAt, e = time.Parse(timestampLayout, row.ModifiedAt); e != nil {
+			return nil, e
+		}
+
+		if row.EditedAt != nil {
+			editedAt, e := time.Parse(timestampLayout, *row.EditedAt)
+			if e != nil {
+				return nil, e
+			}
+
+			comment.EditedAt = &editedAt
+		}
+
+		comments = append(comments, comment)
+	}
+
+	return comments, nil
+}
+
+// Update tries to update a ticket record. Tickets moved to WAITING_ON_CUSTOMER start waiting at the update. An empty
+// content keeps the current one. When the subject or content changes, their previous revision gets recorded along
+// with the editor. A non-empty resolution category replaces the category and sub-category, a non-empty root cause
+// replaces the root cause, and tickets moved to RESOLVED get their resolution time recorded. The returned ticket holds
+// the issuer, owner, importance level and status of the record as they were before the update, the modification time of
+// ticket is set to the one of the record after it. Moving the ticket to another status fails when the ticket is
+// missing any field the status requires or the transition needs an approval, both checked with the ticket locked.
+func (r *TicketRepository) Update(ctx context.Context, ticket *Ticket, editor string) (*Ticket, *errors.Type) {
+	tx, e := r.db.Begin(ctx)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var id int64
+	if e := tx.QueryRow(ctx, `SELECT id FROM tickets WHERE id = $1 FOR UPDATE;`, ticket.ID).Scan(&id); e != nil {
+		if e == pgx.ErrNoRows {
+			return nil, errors.PreconditionFailed("ticket.not_found", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	if e := checkTransition(ctx, r.logger, tx, ticket.ID, ticket.Status, ticket.Resolution); e != nil {
+		return nil, e
+	}
+
+	q := `WITH previous AS (SELECT id, issuer, owner, subject, content, importance_level, status, revision,
+			subject <> $1 OR content <> COALESCE(NULLIF($7, ''), content) AS edited FROM tickets WHERE id = $5
+			FOR UPDATE),
+			revision AS (INSERT INTO ticket_revisions (ticket_id, revision, subject, content, editor, replaced_at)
+			SELECT id, revision, subject, content, NULLIF($8, ''), NOW() FROM previous WHERE edited)
+			UPDATE tickets AS t SET subject = $1, content = COALESCE(NULLIF($7, ''), t.content),
+			metadata = $2, importance_level = $3, status = $4,
+			revision = CASE WHEN previous.edited THEN t.revision + 1 ELSE t.revision END,
+			waiting_since = CASE WHEN $4 <> $6 THEN NULL WHEN previous.status = $6 THEN t.waiting_since ELSE NOW() END,
+			nudged_at = CASE WHEN previous.status = $4 THEN t.nudged_at END,
+			resolution_category = COALESCE(NULLIF($9, ''), t.resolution_category),
+			resolution_sub_category = CASE WHEN $9 = '' THEN t.resolution_sub_category ELSE NULLIF($10, '') END,
+			root_cause = COALESCE(NULLIF($11, ''), t.root_cause),
+			resolved_at = CASE WHEN $4 = $12 AND previous.status <> $12 THEN NOW() ELSE t.resolved_at END
+			FROM previous
+			WHERE t.id = previous.id
+			RETURNING previous.id, previous.issuer, previous.owner, previous.importance_level, previous.status,
+			t.modified_at;`
+
+	previous := &Ticket{}
+	row := tx.QueryRow(ctx, q, ticket.Subject, metadataDocument(ticket.Metadata), ticket.ImportanceLevel,
+		ticket.Status, ticket.ID, TicketStatusWaitingOnCustomer, ticket.Content, editor, ticket.Resolution.Category,
+		ticket.Resolution.SubCategory, ticket.Resolution.RootCause, TicketStatusResolved)
+	e = row.Scan(&previous.ID, &previous.Issuer, &previous.Owner, &previous.ImportanceLevel, &previous.Status,
+		&ticket.ModifiedAt)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	if e := tx.Commit(ctx); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	return previous, nil
+}
+
+// SetBillable tries to flag a ticket as billable or not billable.
+func (r *TicketRepository) SetBillable(ctx context.Context, id int64, billable bool) *errors.Type {
+	q := `UPDATE tickets SET billable = $1 WHERE id = $2;`
+
+	tag, e := r.db.Exec(ctx, q, billable, id)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errors.PreconditionFailed("ticket.not_found", "")
+	}
+
+	return nil
+}
+
+// Snooze tries to hide a ticket from the active queues until the provided time.
+func (r *TicketRepository) Snooze(ctx context.Context, id int64, until time.Time) *errors.Type {
+	q := `UPDATE tickets SET snoozed_until = $1 WHERE id = $2;`
+
+	tag, e := r.db.Exec(ctx, q, until.UTC(), id)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errors.PreconditionFailed("ticket.not_found", "")
+	}
+
+	return nil
+}
+
+// Unsnooze tries to return a snoozed ticket to the active queues. If owner is not empty the ticket is only returned
+// when it belongs to that owner. The returned value is false when no snoozed ticket matched.
+func (r *TicketRepository) Unsnooze(ctx context.Context, id int64, owner string) (bool, *errors.Type) {
+	q := `UPDATE tickets SET snoozed_until = NULL
+			WHERE id = $1 AND snoozed_until IS NOT NULL AND ($2 = '' OR owner = $2);`
+
+	tag, e := r.db.Exec(ctx, q, id, owner)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return false, et
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// UnsnoozeDue tries to return the tickets snoozed until now or earlier to the active queues and returns back their
+// ids.
+func (r *TicketRepository) UnsnoozeDue(ctx context.Context, now time.Time) ([]int64, *errors.Type) {
+	q := `UPDATE tickets SET snoozed_until = NULL WHERE snoozed_until <= $1 RETURNING id;`
+
+	return r.updateReturningIDs(ctx, q, now.UTC())
+}
+
+// updateReturningIDs runs an update query returning the ids of the updated tickets.
+func (r *TicketRepository) updateReturningIDs(ctx context.Context, q string, args ...interface{}) ([]int64,
+	*errors.Type) {
+
+	return r.queryIDs(ctx, r.db, q, args...)
+}
+
+// queryIDs runs a query returning ticket ids on db, which is either a pool or a transaction.
+func (r *TicketRepository) queryIDs(ctx context.Context, db querier, q string, args ...interface{}) ([]int64,
+	*errors.Type) {
+
+	rows, e := db.Query(ctx, q, args...)
 	if e != nil {
 		et := errors.InternalServerError("unknown", "")
 		r.logger.Error(et.FingerPrint, ": ", e.Error())
@@ -96,82 +428,330 @@ func (r *TicketRepository) LoadByID(ctx context.Context, id int64) (*Ticket, *er
 	}
 	defer rows.Close()
 
+	ids := make([]int64, 0)
 	for rows.Next() {
-		comment := &Comment{}
-		var metadata sql.NullString
-
-		e := rows.Scan(&comment.ID, &comment.TicketID, &comment.Owner, &comment.Content, &metadata, &comment.CreatedAt,
-			&comment.ModifiedAt)
-		if e != nil {
+		var id int64
+		if e := rows.Scan(&id); e != nil {
 			et := errors.InternalServerError("unknown", "")
 			r.logger.Error(et.FingerPrint, ": ", e.Error())
 			return nil, et
 		}
 
-		if metadata.Valid {
-			comment.Metadata = metadata.String
-		}
+		ids = append(ids, id)
+	}
 
-		ticket.Comments = append(ticket.Comments, comment)
+	if e := rows.Err(); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
 	}
 
-	return ticket, nil
+	return ids, nil
 }
 
-// Update tries to update a ticket record.
-func (r *TicketRepository) Update(ctx context.Context, ticket *Ticket) *errors.Type {
-	q := `UPDATE tickets SET subject = $1, metadata = $2, importance_level = $3, status = $4, modified_at = NOW()
-			WHERE id = $5;`
+// CloseWaiting tries to close the tickets that have been waiting on their owners since silentSince or earlier and
+// returns back their ids. Tickets missing any field closing them requires, or needing an approval to get closed, keep
+// waiting.
+func (r *TicketRepository) CloseWaiting(ctx context.Context, silentSince time.Time) ([]int64, *errors.Type) {
+	tx, e := r.db.Begin(ctx)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	lockQ := `SELECT id FROM tickets WHERE status = $1 AND waiting_since <= $2 ORDER BY id FOR UPDATE;`
+	closeQ := `UPDATE tickets SET status = $1, waiting_since = NULL, nudged_at = NULL WHERE id = ANY($2) RETURNING id;`
 
-	command, e := r.db.Exec(ctx, q, ticket.Subject, ticket.Metadata, ticket.ImportanceLevel, ticket.Status, ticket.ID)
+	waiting, et := r.queryIDs(ctx, tx, lockQ, TicketStatusWaitingOnCustomer, silentSince.UTC())
+	if et != nil {
+		return nil, et
+	}
+
+	allowed, _, et := transitionable(ctx, r.logger, tx, waiting, TicketStatusClosed, Resolution{})
+	if et != nil {
+		return nil, et
+	}
+
+	ids, et := r.queryIDs(ctx, tx, closeQ, TicketStatusClosed, allowed)
+	if et != nil {
+		return nil, et
+	}
+
+	if e := tx.Commit(ctx); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	return ids, nil
+}
+
+// ClaimNudges tries to record now as the last nudge of the tickets whose owners have been silent since silentSince or
+// earlier, counting from the last nudge if any, and returns back their ids.
+func (r *TicketRepository) ClaimNudges(ctx context.Context, silentSince, now time.Time) ([]int64, *errors.Type) {
+	q := `UPDATE tickets SET nudged_at = $1 WHERE status = $2 AND COALESCE(nudged_at, waiting_since) <= $3
+			RETURNING id;`
+
+	return r.updateReturningIDs(ctx, q, now.UTC(), TicketStatusWaitingOnCustomer, silentSince.UTC())
+}
+
+// ReopenWaiting tries to move a ticket waiting on its owner back to NEW, if it belongs to the provided owner. The
+// returned value is false when no waiting ticket matched, or when the ticket is missing any field NEW requires or needs
+// an approval to be moved to it.
+func (r *TicketRepository) ReopenWaiting(ctx context.Context, id int64, owner string) (bool, *errors.Type) {
+	tx, e := r.db.Begin(ctx)
 	if e != nil {
 		et := errors.InternalServerError("unknown", "")
 		r.logger.Error(et.FingerPrint, ": ", e.Error())
-		return et
+		return false, et
 	}
+	defer func() { _ = tx.Rollback(ctx) }()
 
-	if command.RowsAffected() == 0 {
-		et := errors.PreconditionFailed("ticket.not_found", "")
-		return et
+	lockQ := `SELECT id FROM tickets WHERE id = $1 AND status = $2 AND owner = $3 FOR UPDATE;`
+	reopenQ := `UPDATE tickets SET status = $1, waiting_since = NULL, nudged_at = NULL WHERE id = $2;`
+
+	if e := tx.QueryRow(ctx, lockQ, id, TicketStatusWaitingOnCustomer, owner).Scan(&id); e != nil {
+		if e == pgx.ErrNoRows {
+			return false, nil
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return false, et
 	}
 
-	return nil
+	if et := checkTransition(ctx, r.logger, tx, id, TicketStatusNew, Resolution{}); et != nil {
+		if et.Kind == errors.KindPrecondition {
+			return false, nil
+		}
+
+		return false, et
+	}
+
+	if _, e := tx.Exec(ctx, reopenQ, TicketStatusNew, id); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return false, et
+	}
+
+	if e := tx.Commit(ctx); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return false, et
+	}
+
+	return true, nil
 }
 
-// DeleteByID tries to delete a ticket and all of its comments.
-func (r *TicketRepository) DeleteByID(ctx context.Context, id int64) *errors.Type {
+// Assign tries to assign a ticket to the assignee and returns back its previous assignee, which is empty when it was
+// unassigned. Tickets assigned to another agent are only reassigned when reassign is true, so agents do not claim the
+// same ticket at once. Assigning a ticket to its assignee changes nothing.
+func (r *TicketRepository) Assign(ctx context.Context, id int64, assignee string, reassign bool) (string,
+	*errors.Type) {
+
+	q := `WITH previous AS (SELECT id, COALESCE(assignee, '') AS assignee FROM tickets WHERE id = $1 FOR UPDATE),
+			assigned AS (UPDATE tickets AS t SET assignee = $2 FROM previous
+			WHERE t.id = previous.id AND previous.assignee <> $2 AND (previous.assignee = '' OR $3)
+			RETURNING t.id)
+			SELECT previous.assignee, EXISTS (SELECT 1 FROM assigned) FROM previous;`
+
+	var previous string
+	var assigned bool
+	if e := r.db.QueryRow(ctx, q, id, assignee, reassign).Scan(&previous, &assigned); e != nil {
+		if e == pgx.ErrNoRows {
+			return "", errors.PreconditionFailed("ticket.not_found", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return "", et
+	}
+
+	if !assigned && previous != assignee {
+		return "", errors.PreconditionFailed("ticket.already_assigned", "")
+	}
+
+	return previous, nil
+}
+
+// Unassign tries to unassign a ticket and returns back its previous assignee, which is empty when it was unassigned
+// already. If assignee is not empty the ticket is only unassigned when it is assigned to that agent.
+func (r *TicketRepository) Unassign(ctx context.Context, id int64, assignee string) (string, *errors.Type) {
+	q := `WITH previous AS (SELECT id, COALESCE(assignee, '') AS assignee FROM tickets WHERE id = $1 FOR UPDATE),
+			unassigned AS (UPDATE tickets AS t SET assignee = NULL FROM previous
+			WHERE t.id = previous.id AND previous.assignee <> '' AND ($2 = '' OR previous.assignee = $2)
+			RETURNING t.id)
+			SELECT previous.assignee, EXISTS (SELECT 1 FROM unassigned) FROM previous;`
+
+	var previous string
+	var unassigned bool
+	if e := r.db.QueryRow(ctx, q, id, assignee).Scan(&previous, &unassigned); e != nil {
+		if e == pgx.ErrNoRows {
+			return "", errors.PreconditionFailed("ticket.not_found", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return "", et
+	}
+
+	if !unassigned && previous != "" {
+		return "", errors.PreconditionFailed("ticket.assigned_to_another", "")
+	}
+
+	return previous, nil
+}
+
+// ticketDependentsQueries delete the records depending on the tickets with the ids bound to $1, i.e. all of their
+// comments and their revisions, their external references, their revisions, their links to webhook sources, their
+// escalations and their approvals, ahead of deleting the tickets themselves.
+var ticketDependentsQueries = []string{
+	`DELETE FROM comment_revisions WHERE comment_id IN (SELECT id FROM comments WHERE ticket_id = ANY($1));`,
+	`DELETE FROM comments WHERE ticket_id = ANY($1);`,
+	`DELETE FROM ticket_references WHERE ticket_id = ANY($1);`,
+	`DELETE FROM ticket_revisions WHERE ticket_id = ANY($1);`,
+	`DELETE FROM webhook_tickets WHERE ticket_id = ANY($1);`,
+	`DELETE FROM ticket_escalations WHERE ticket_id = ANY($1);`,
+	`DELETE FROM ticket_approvals WHERE ticket_id = ANY($1);`,
+}
+
+// DeleteByID tries to delete a ticket along with the records depending on it, see ticketDependentsQueries. The
+// returned ticket holds the issuer, owner, importance level and status of the deleted record or is nil when there was
+// no such record.
+func (r *TicketRepository) DeleteByID(ctx context.Context, id int64) (*Ticket, *errors.Type) {
 	begin := `BEGIN;`
-	commentsQ := `DELETE FROM comments WHERE ticket_id=$1;`
-	q := `DELETE FROM tickets WHERE id=$1;`
+	q := `DELETE FROM tickets WHERE id=$1 RETURNING id, issuer, owner, importance_level, status;`
 	commit := `COMMIT;`
 
 	batch := &pgx.Batch{}
 	batch.Queue(begin)
-	batch.Queue(commentsQ, id)
+	for _, dependentsQ := range ticketDependentsQueries {
+		batch.Queue(dependentsQ, []int64{id})
+	}
 	batch.Queue(q, id)
 	batch.Queue(commit)
 
 	results := r.db.SendBatch(ctx, batch)
-	if e := results.Close(); e != nil {
+
+	var deleted *Ticket
+	_, e := results.Exec()
+	for range ticketDependentsQueries {
+		if e == nil {
+			_, e = results.Exec()
+		}
+	}
+
+	if e == nil {
+		deleted = &Ticket{}
+		e = results.QueryRow().Scan(&deleted.ID, &deleted.Issuer, &deleted.Owner, &deleted.ImportanceLevel,
+			&deleted.Status)
+		if e == pgx.ErrNoRows {
+			deleted, e = nil, nil
+		}
+	}
+
+	if ce := results.Close(); e == nil {
+		e = ce
+	}
+
+	if e != nil {
 		et := errors.InternalServerError("unknown", "")
 		r.logger.Error(et.FingerPrint, ": ", e.Error())
-		return et
+		return nil, et
 	}
 
-	return nil
+	return deleted, nil
 }
 
-// Filter tries to filter tickets. If there is another page of result when loading tickets, the second returned value
-// will be true, otherwise false.
-func (r *TicketRepository) Filter(ctx context.Context, issuer, owner string, importanceLevel TicketImportanceLevel,
-	status TicketStatus, fromDate, toDate string, pageNumber, pageSize int) ([]*Ticket, bool, *errors.Type) {
+// CountByStatus counts tickets grouped by their status. If owner or assignee are not empty only the tickets of that
+// owner or assigned to that agent are counted, so agents can tell the size of their own queues. Tickets are not counted
+// on each call, counters are summed up from ticket_status_counters table, which the triggers of tickets table keep up
+// to date per issuer.
+func (r *TicketRepository) CountByStatus(ctx context.Context, owner, assignee string) (map[TicketStatus]int64,
+	*errors.Type) {
 
-	q, args := r.buildFilterQuery(issuer, owner, importanceLevel, status, fromDate, toDate, pageNumber, pageSize)
-	rows, e := r.db.Query(ctx, q, args...)
+	q := `SELECT status, SUM(count)::BIGINT FROM ticket_status_counters WHERE owner = $1 AND assignee = $2
+			GROUP BY status HAVING SUM(count) <> 0;`
+
+	rows, e := r.db.Query(ctx, q, owner, assignee)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	counters := make(map[TicketStatus]int64)
+	for rows.Next() {
+		var status TicketStatus
+		var count int64
+
+		if e := rows.Scan(&status, &count); e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		counters[status] = count
+	}
+
+	return counters, nil
+}
+
+// CountForReindex counts the tickets whose search document would be rebuilt by Reindex with the same filter.
+func (r *TicketRepository) CountForReindex(ctx context.Context, issuer, fromDate, toDate string) (int64, *errors.Type) {
+	q := `SELECT COUNT(*) FROM tickets WHERE ($1 = '' OR issuer = $1) AND modified_at >= $2 AND modified_at < $3;`
+
+	var count int64
+	if e := r.db.QueryRow(ctx, q, issuer, fromDate, toDate).Scan(&count); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return 0, et
+	}
+
+	return count, nil
+}
+
+// Reindex rebuilds the search document of the next batch of tickets after afterID from their subject and content. It
+// returns back the id of the last reindexed ticket and the number of reindexed tickets, which falls below batchSize
+// once there is nothing left to reindex.
+func (r *TicketRepository) Reindex(ctx context.Context, issuer, fromDate, toDate string, afterID int64,
+	batchSize int) (int64, int, *errors.Type) {
+
+	q := `WITH batch AS (SELECT id FROM tickets WHERE id > $1 AND ($2 = '' OR issuer = $2) AND modified_at >= $3
+			AND modified_at < $4 ORDER BY id LIMIT $5),
+			reindexed AS (UPDATE tickets AS t SET search_vector = ticket_search_vector(t.subject, t.content)
+			FROM batch WHERE t.id = batch.id RETURNING t.id)
+			SELECT COALESCE(MAX(id), $1), COUNT(*) FROM reindexed;`
+
+	var lastID int64
+	var count int
+	e := r.db.QueryRow(ctx, q, afterID, issuer, fromDate, toDate, batchSize).Scan(&lastID, &count)
 	if e != nil {
 		et := errors.InternalServerError("unknown", "")
 		r.logger.Error(et.FingerPrint, ": ", e.Error())
-		return nil, false, et
+		return 0, 0, et
+	}
+
+	return lastID, count, nil
+}
+
+// Filter tries to filter tickets. Snoozed tickets are only returned, and exclusively, when snoozed is true. Tickets are
+// matched against the non-empty fields of the resolution. Tickets are ordered by orders, the most recently modified
+// first when empty. Pages start right after the ticket at the cursor when one is given, as returned back by its Cursor,
+// otherwise pageNumber pages are skipped. If there is another page of result when loading tickets, the second returned
+// value will be true, otherwise false.
+func (r *TicketRepository) Filter(ctx context.Context, issuer, owner string, importanceLevel TicketImportanceLevel,
+	status TicketStatus, resolution Resolution, metadata MetadataMatch, snoozed bool, fromDate, toDate string,
+	orders []Order, after Cursor, pageNumber, pageSize int) ([]*Ticket, bool, *errors.Type) {
+
+	q, args := r.buildFilterQuery(issuer, owner, importanceLevel, status, resolution, metadata, snoozed, fromDate,
+		toDate, orders, after, pageNumber, pageSize)
+	rows, e := r.db.Query(ctx, q, args...)
+	if e != nil {
+		return nil, false, queryFailed(r.logger, e)
 	}
 	defer rows.Close()
 
@@ -180,19 +760,22 @@ func (r *TicketRepository) Filter(ctx context.Context, issuer, owner string, imp
 	for rows.Next() {
 		ticket := &Ticket{}
 		var metadata sql.NullString
+		var timeSpent int
 
 		e := rows.Scan(&ticket.ID, &ticket.Issuer, &ticket.Owner, &ticket.Subject, &ticket.Content, &metadata,
-			&ticket.ImportanceLevel, &ticket.Status, &ticket.CreatedAt, &ticket.ModifiedAt)
+			&ticket.ImportanceLevel, &ticket.Status, &ticket.Tier, &ticket.FirstResponseDueAt, &ticket.ResolutionDueAt,
+			&ticket.SnoozedUntil, &timeSpent, &ticket.Billable, &ticket.ApprovalState, &ticket.Assignee,
+			&ticket.Resolution.Category, &ticket.Resolution.SubCategory, &ticket.Resolution.RootCause,
+			&ticket.ResolvedAt, &ticket.CreatedAt, &ticket.ModifiedAt)
 		if e != nil {
-			et := errors.InternalServerError("unknown", "")
-			r.logger.Error(et.FingerPrint, ": ", e.Error())
-			return nil, false, et
+			return nil, false, queryFailed(r.logger, e)
 		}
 
 		if metadata.Valid {
 			ticket.Metadata = metadata.String
 		}
 
+		ticket.TimeSpent = time.Duration(timeSpent) * time.Minute
 		tickets = append(tickets, ticket)
 		ticketsMap[ticket.ID] = ticket
 	}
@@ -207,9 +790,7 @@ func (r *TicketRepository) Filter(ctx context.Context, issuer, owner string, imp
 		q, args = r.buildLoadCommentsQuery(tickets)
 		rows, e = r.db.Query(ctx, q, args...)
 		if e != nil {
-			et := errors.InternalServerError("unknown", "")
-			r.logger.Error(et.FingerPrint, ": ", e.Error())
-			return nil, false, et
+			return nil, false, queryFailed(r.logger, e)
 		}
 		defer rows.Close()
 
@@ -218,11 +799,10 @@ func (r *TicketRepository) Filter(ctx context.Context, issuer, owner string, imp
 			var metadata sql.NullString
 
 			e := rows.Scan(&comment.ID, &comment.TicketID, &comment.Owner, &comment.Content, &metadata,
-				&comment.CreatedAt, &comment.ModifiedAt)
+				&comment.AuthorType, &comment.Source, &comment.Revision, &comment.EditedAt, &comment.CreatedAt,
+				&comment.ModifiedAt)
 			if e != nil {
-				et := errors.InternalServerError("unknown", "")
-				r.logger.Error(et.FingerPrint, ": ", e.Error())
-				return nil, false, et
+				return nil, false, queryFailed(r.logger, e)
 			}
 
 			if metadata.Valid {
@@ -236,6 +816,216 @@ func (r *TicketRepository) Filter(ctx context.Context, issuer, owner string, imp
 	return tickets, hasNextPage, nil
 }
 
+// FilterCount tries to count the tickets Filter would return back across all pages, without loading them.
+func (r *TicketRepository) FilterCount(ctx context.Context, issuer, owner string,
+	importanceLevel TicketImportanceLevel, status TicketStatus, resolution Resolution, metadata MetadataMatch,
+	snoozed bool, fromDate, toDate string) (int64, *errors.Type) {
+
+	conditions, args := r.buildFilterConditions(issuer, owner, importanceLevel, status, resolution, metadata, snoozed,
+		fromDate, toDate)
+
+	var count int64
+	q := `SELECT count(*) FROM tickets WHERE` + conditions + `;`
+	if e := r.db.QueryRow(ctx, q, args...).Scan(&count); e != nil {
+		return 0, queryFailed(r.logger, e)
+	}
+
+	return count, nil
+}
+
+// FilterEstimate tries to estimate the number of tickets Filter would return back across all pages from the statistics
+// of the query planner, without scanning them. Unlike FilterCount it stays fast on huge tables, but the estimate can be
+// far off for selective filters or stale statistics.
+func (r *TicketRepository) FilterEstimate(ctx context.Context, issuer, owner string,
+	importanceLevel TicketImportanceLevel, status TicketStatus, resolution Resolution, metadata MetadataMatch,
+	snoozed bool, fromDate, toDate string) (int64, *errors.Type) {
+
+	conditions, args := r.buildFilterConditions(issuer, owner, importanceLevel, status, resolution, metadata, snoozed,
+		fromDate, toDate)
+
+	var plan string
+	q := `EXPLAIN (FORMAT JSON) SELECT 1 FROM tickets WHERE` + conditions + `;`
+	if e := r.db.QueryRow(ctx, q, args...).Scan(&plan); e != nil {
+		return 0, queryFailed(r.logger, e)
+	}
+
+	estimate, e := planRows(plan)
+	if e != nil {
+		return 0, queryFailed(r.logger, e)
+	}
+
+	return estimate, nil
+}
+
+// TicketFacets holds the number of tickets per value of the fields filters are refined by, e.g. in filter sidebars.
+type TicketFacets struct {
+	Statuses         map[TicketStatus]int64
+	ImportanceLevels map[TicketImportanceLevel]int64
+}
+
+// FilterFacets tries to count the tickets Filter would return back across all pages per status and importance level.
+// Each facet is counted regardless of its own field, so it shows the tickets the filter would match when switching
+// to other values of that field.
+func (r *TicketRepository) FilterFacets(ctx context.Context, issuer, owner string,
+	importanceLevel TicketImportanceLevel, status TicketStatus, resolution Resolution, metadata MetadataMatch,
+	snoozed bool, fromDate, toDate string) (*TicketFacets, *errors.Type) {
+
+	facets := &TicketFacets{Statuses: make(map[TicketStatus]int64),
+		ImportanceLevels: make(map[TicketImportanceLevel]int64)}
+
+	conditions, args := r.buildFilterConditions(issuer, owner, importanceLevel, "", resolution, metadata, snoozed,
+		fromDate, toDate)
+	statuses, e := r.countBy(ctx, "status", conditions, args)
+	if e != nil {
+		return nil, queryFailed(r.logger, e)
+	}
+
+	for value, count := range statuses {
+		facets.Statuses[TicketStatus(value)] = count
+	}
+
+	conditions, args = r.buildFilterConditions(issuer, owner, "", status, resolution, metadata, snoozed, fromDate,
+		toDate)
+	importanceLevels, e := r.countBy(ctx, "importance_level", conditions, args)
+	if e != nil {
+		return nil, queryFailed(r.logger, e)
+	}
+
+	for value, count := range importanceLevels {
+		facets.ImportanceLevels[TicketImportanceLevel(value)] = count
+	}
+
+	return facets, nil
+}
+
+// TicketGroupColumns whitelists the fields tickets can be grouped by, along with their columns. Unassigned tickets are
+// grouped under an empty assignee.
+var TicketGroupColumns = map[string]string{
+	"status":     "status",
+	"importance": "importance_level",
+	"assignee":   "COALESCE(assignee, '')",
+}
+
+// TicketGroup holds the first tickets of a group of tickets sharing the same value of the field they are grouped by,
+// along with the number of tickets in the group.
+type TicketGroup struct {
+	Value   string
+	Count   int64
+	Tickets []*Ticket
+}
+
+// FilterGroups tries to group the tickets Filter would return back across all pages by the field groupBy, which must
+// be one of TicketGroupColumns. Each group holds its first size tickets, ordered by orders or the most recently
+// modified first when empty, without their comments. Groups are ordered by their value and empty groups are left out.
+func (r *TicketRepository) FilterGroups(ctx context.Context, groupBy, issuer, owner string,
+	importanceLevel TicketImportanceLevel, status TicketStatus, resolution Resolution, metadata MetadataMatch,
+	snoozed bool, fromDate, toDate string, orders []Order, size int) ([]*TicketGroup, *errors.Type) {
+
+	column, ok := TicketGroupColumns[groupBy]
+	if !ok {
+		return nil, errors.InvalidArgument("groupBy.field_not_valid", "")
+	}
+
+	conditions, args := r.buildFilterConditions(issuer, owner, importanceLevel, status, resolution, metadata, snoozed,
+		fromDate, toDate)
+	args = append(args, size)
+
+	q := `SELECT id, issuer, owner, subject, content, metadata #>> '{}', importance_level, status,
+			COALESCE(tier, ''), first_response_due_at, resolution_due_at, snoozed_until, time_spent_minutes, billable,
+			COALESCE(approval_state, ''), COALESCE(assignee, ''), COALESCE(resolution_category, ''),
+			COALESCE(resolution_sub_category, ''), COALESCE(root_cause, ''), resolved_at, created_at, modified_at,
+			grouped, total
+			FROM (SELECT *, ` + column + ` AS grouped, count(*) OVER (PARTITION BY ` + column + `) AS total,
+			row_number() OVER (PARTITION BY ` + column +
+		orderClause(orders, ticketDefaultOrders, TicketOrderColumns) + `) AS position
+			FROM tickets WHERE` + conditions + `) AS t
+			WHERE position <= $` + strconv.Itoa(len(args)) + ` ORDER BY grouped, position;`
+
+	rows, e := r.db.Query(ctx, q, args...)
+	if e != nil {
+		return nil, queryFailed(r.logger, e)
+	}
+	defer rows.Close()
+
+	groups := make([]*TicketGroup, 0)
+	for rows.Next() {
+		ticket := &Ticket{}
+		var metadata sql.NullString
+		var timeSpent int
+		var value string
+		var count int64
+
+		e := rows.Scan(&ticket.ID, &ticket.Issuer, &ticket.Owner, &ticket.Subject, &ticket.Content, &metadata,
+			&ticket.ImportanceLevel, &ticket.Status, &ticket.Tier, &ticket.FirstResponseDueAt, &ticket.ResolutionDueAt,
+			&ticket.SnoozedUntil, &timeSpent, &ticket.Billable, &ticket.ApprovalState, &ticket.Assignee,
+			&ticket.Resolution.Category, &ticket.Resolution.SubCategory, &ticket.Resolution.RootCause,
+			&ticket.ResolvedAt, &ticket.CreatedAt, &ticket.ModifiedAt, &value, &count)
+		if e != nil {
+			return nil, queryFailed(r.logger, e)
+		}
+
+		if metadata.Valid {
+			ticket.Metadata = metadata.String
+		}
+
+		ticket.TimeSpent = time.Duration(timeSpent) * time.Minute
+
+		if len(groups) == 0 || groups[len(groups)-1].Value != value {
+			groups = append(groups, &TicketGroup{Value: value, Count: count})
+		}
+
+		group := groups[len(groups)-1]
+		group.Tickets = append(group.Tickets, ticket)
+	}
+
+	if e := rows.Err(); e != nil {
+		return nil, queryFailed(r.logger, e)
+	}
+
+	return groups, nil
+}
+
+// countBy counts the tickets matching the conditions per value of column.
+func (r *TicketRepository) countBy(ctx context.Context, column, conditions string, args []interface{}) (
+	map[string]int64, error) {
+
+	rows, e := r.db.Query(ctx, `SELECT `+column+`, count(*) FROM tickets WHERE`+conditions+` GROUP BY 1;`, args...)
+	if e != nil {
+		return nil, e
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var value string
+		var count int64
+		if e := rows.Scan(&value, &count); e != nil {
+			return nil, e
+		}
+
+		counts[value] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// FilterExists tries to check whether Filter would return back any ticket, stopping at the first match.
+func (r *TicketRepository) FilterExists(ctx context.Context, issuer, owner string,
+	importanceLevel TicketImportanceLevel, status TicketStatus, resolution Resolution, metadata MetadataMatch,
+	snoozed bool, fromDate, toDate string) (bool, *errors.Type) {
+
+	conditions, args := r.buildFilterConditions(issuer, owner, importanceLevel, status, resolution, metadata, snoozed,
+		fromDate, toDate)
+
+	var exists bool
+	q := `SELECT EXISTS (SELECT 1 FROM tickets WHERE` + conditions + `);`
+	if e := r.db.QueryRow(ctx, q, args...).Scan(&exists); e != nil {
+		return false, queryFailed(r.logger, e)
+	}
+
+	return exists, nil
+}
+
 // TicketImportanceLevel model.
 type TicketImportanceLevel string
 
@@ -247,6 +1037,17 @@ const (
 	TicketImportanceLevelCritical TicketImportanceLevel = "CRITICAL"
 )
 
+// IsValid reports whether the importance level is one of the known levels.
+func (l TicketImportanceLevel) IsValid() bool {
+	switch l {
+	case TicketImportanceLevelLow, TicketImportanceLevelMedium, TicketImportanceLevelHigh,
+		TicketImportanceLevelCritical:
+		return true
+	}
+
+	return false
+}
+
 // TicketStatus model.
 type TicketStatus string
 
@@ -257,70 +1058,207 @@ const (
 	TicketStatusResolved TicketStatus = "RESOLVED"
 	TicketStatusClosed   TicketStatus = "CLOSED"
 	TicketStatusBlocked  TicketStatus = "BLOCKED"
+	// TicketStatusWaitingOnCustomer tickets get nudged and eventually closed when their owners stay silent.
+	TicketStatusWaitingOnCustomer TicketStatus = "WAITING_ON_CUSTOMER"
 )
 
+// IsValid reports whether the status is one of the known statuses.
+func (s TicketStatus) IsValid() bool {
+	switch s {
+	case TicketStatusNew, TicketStatusReplied, TicketStatusResolved, TicketStatusClosed, TicketStatusBlocked,
+		TicketStatusWaitingOnCustomer:
+		return true
+	}
+
+	return false
+}
+
+// planRows returns back the number of rows the top node of a plan explained in JSON format is estimated to return.
+func planRows(plan string) (int64, error) {
+	explained := make([]struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}, 0)
+
+	if e := json.Unmarshal([]byte(plan), &explained); e != nil {
+		return 0, e
+	}
+
+	if len(explained) == 0 {
+		return 0, fmt.Errorf("no plan explained")
+	}
+
+	return int64(explained[0].Plan.Rows), nil
+}
+
+// FilterByAssignee tries to filter the tickets of an issuer assigned to the assignee, of the status if not empty, the
+// most recently modified first and without their comments. Snoozed tickets are returned as well, with their snooze
+// time. If there is another page of result when loading tickets, the second returned value will be true, otherwise
+// false.
+func (r *TicketRepository) FilterByAssignee(ctx context.Context, issuer, assignee string, status TicketStatus,
+	pageNumber, pageSize int) ([]*Ticket, bool, *errors.Type) {
+
+	q := `SELECT id, issuer, owner, subject, content, metadata #>> '{}', importance_level, status,
+			COALESCE(tier, ''), first_response_due_at, resolution_due_at, snoozed_until, time_spent_minutes, billable,
+			COALESCE(approval_state, ''), assignee, COALESCE(resolution_category, ''),
+			COALESCE(resolution_sub_category, ''), COALESCE(root_cause, ''), resolved_at, created_at, modified_at
+			FROM tickets WHERE assignee = $1 AND ($2 = '' OR issuer = $2) AND ($3 = '' OR status = $3)
+			ORDER BY modified_at DESC, id DESC OFFSET $4 LIMIT $5;`
+
+	rows, e := r.db.Query(ctx, q, assignee, issuer, status, (pageNumber-1)*pageSize, pageSize+1)
+	if e != nil {
+		return nil, false, queryFailed(r.logger, e)
+	}
+	defer rows.Close()
+
+	tickets := make([]*Ticket, 0)
+	for rows.Next() {
+		ticket := &Ticket{}
+		var metadata sql.NullString
+		var timeSpent int
+
+		e := rows.Scan(&ticket.ID, &ticket.Issuer, &ticket.Owner, &ticket.Subject, &ticket.Content, &metadata,
+			&ticket.ImportanceLevel, &ticket.Status, &ticket.Tier, &ticket.FirstResponseDueAt, &ticket.ResolutionDueAt,
+			&ticket.SnoozedUntil, &timeSpent, &ticket.Billable, &ticket.ApprovalState, &ticket.Assignee,
+			&ticket.Resolution.Category, &ticket.Resolution.SubCategory, &ticket.Resolution.RootCause,
+			&ticket.ResolvedAt, &ticket.CreatedAt, &ticket.ModifiedAt)
+		if e != nil {
+			return nil, false, queryFailed(r.logger, e)
+		}
+
+		if metadata.Valid {
+			ticket.Metadata = metadata.String
+		}
+
+		ticket.TimeSpent = time.Duration(timeSpent) * time.Minute
+		tickets = append(tickets, ticket)
+	}
+
+	if e := rows.Err(); e != nil {
+		return nil, false, queryFailed(r.logger, e)
+	}
+
+	hasNextPage := len(tickets) > pageSize
+	if hasNextPage {
+		// Drop the extra one.
+		tickets = tickets[:len(tickets)-1]
+	}
+
+	return tickets, hasNextPage, nil
+}
+
 func (r *TicketRepository) buildFilterQuery(issuer, owner string, importanceLevel TicketImportanceLevel,
-	status TicketStatus, fromDate, toDate string, pageNumber, pageSize int) (string, []interface{}) {
+	status TicketStatus, resolution Resolution, metadata MetadataMatch, snoozed bool, fromDate, toDate string,
+	orders []Order, after Cursor, pageNumber, pageSize int) (string, []interface{}) {
 
 	offset := (pageNumber - 1) * pageSize
 	limit := pageSize
 
-	args := make([]interface{}, 0)
+	b := r.filterBuilder(issuer, owner, importanceLevel, status, resolution, metadata, snoozed, fromDate, toDate)
+	if after != nil {
+		b.WhereAfter(keysetAfter(after, orders, ticketDefaultOrders, TicketOrderColumns)...)
+		offset = 0
+	}
+
 	q := strings.Builder{}
 
-	q.WriteString(`SELECT id, issuer, owner, subject, content, metadata, importance_level, status, created_at,
-						modified_at FROM tickets WHERE`)
+	q.WriteString(`SELECT id, issuer, owner, subject, content, metadata #>> '{}', importance_level, status,
+						COALESCE(tier, ''), first_response_due_at, resolution_due_at, snoozed_until, time_spent_minutes,
+						billable, COALESCE(approval_state, ''), COALESCE(assignee, ''),
+						COALESCE(resolution_category, ''), COALESCE(resolution_sub_category, ''),
+						COALESCE(root_cause, ''), resolved_at, created_at, modified_at FROM tickets WHERE`)
 
-	counter := 0
-	counter++
-	q.WriteString(` modified_at >= $` + strconv.Itoa(counter))
-	args = append(args, fromDate)
+	conditions, args := r.build(b)
+	q.WriteString(conditions)
+	q.WriteString(orderClause(orders, ticketDefaultOrders, TicketOrderColumns))
+
+	args = append(args, offset)
+	q.WriteString(` OFFSET $` + strconv.Itoa(len(args)))
 
-	counter++
-	q.WriteString(` AND modified_at < $` + strconv.Itoa(counter))
-	args = append(args, toDate)
+	args = append(args, limit+1)
+	q.WriteString(` LIMIT $` + strconv.Itoa(len(args)))
+
+	return q.String(), args
+}
+
+// ticketFilterColumns whitelists the columns filters can have conditions on, including the ones pages start after.
+var ticketFilterColumns = []string{"modified_at", "issuer", "owner", "importance_level", "status",
+	"resolution_category", "resolution_sub_category", "root_cause", "metadata", "snoozed_until", "id",
+	"created_at", "resolved_at", "first_response_due_at", "resolution_due_at"}
+
+// buildFilterConditions builds the conditions of the WHERE clause shared by Filter and its count variants.
+func (r *TicketRepository) buildFilterConditions(issuer, owner string, importanceLevel TicketImportanceLevel,
+	status TicketStatus, resolution Resolution, metadata MetadataMatch, snoozed bool, fromDate, toDate string) (string,
+	[]interface{}) {
+
+	return r.build(r.filterBuilder(issuer, owner, importanceLevel, status, resolution, metadata, snoozed, fromDate,
+		toDate))
+}
+
+// filterBuilder returns back the builder of the conditions shared by Filter and its count variants.
+func (r *TicketRepository) filterBuilder(issuer, owner string, importanceLevel TicketImportanceLevel,
+	status TicketStatus, resolution Resolution, metadata MetadataMatch, snoozed bool,
+	fromDate, toDate string) *filters.Builder {
+
+	b := filters.New(ticketFilterColumns...).
+		Where("modified_at", filters.GreaterOrEqual, fromDate).
+		Where("modified_at", filters.Less, toDate)
 
 	if issuer != "" {
-		counter++
-		q.WriteString(` AND issuer = $` + strconv.Itoa(counter))
-		args = append(args, issuer)
+		b.Where("issuer", filters.Equal, issuer)
 	}
 
 	if owner != "" {
-		counter++
-		q.WriteString(` AND owner = $` + strconv.Itoa(counter))
-		args = append(args, owner)
+		b.Where("owner", filters.Equal, owner)
 	}
 
 	if importanceLevel != "" {
-		counter++
-		q.WriteString(` AND importance_level = $` + strconv.Itoa(counter))
-		args = append(args, importanceLevel)
+		b.Where("importance_level", filters.Equal, importanceLevel)
 	}
 
 	if status != "" {
-		counter++
-		q.WriteString(` AND status = $` + strconv.Itoa(counter))
-		args = append(args, status)
+		b.Where("status", filters.Equal, status)
 	}
 
-	counter++
-	q.WriteString(` ORDER BY modified_at DESC OFFSET $` + strconv.Itoa(counter))
-	args = append(args, offset)
+	if resolution.Category != "" {
+		b.Where("resolution_category", filters.Equal, resolution.Category)
+	}
 
-	counter++
-	q.WriteString(` LIMIT $` + strconv.Itoa(counter))
-	args = append(args, limit+1)
+	if resolution.SubCategory != "" {
+		b.Where("resolution_sub_category", filters.Equal, resolution.SubCategory)
+	}
 
-	return q.String(), args
+	if resolution.RootCause != "" {
+		b.Where("root_cause", filters.Equal, resolution.RootCause)
+	}
+
+	// Served by the GIN index of metadata, the paths must be validated beforehand so the document can be built.
+	if document, ok := metadata.Document(); ok && len(metadata) > 0 {
+		b.Where("metadata", filters.Contains, document)
+	}
+
+	return b.WhereNull("snoozed_until", !snoozed)
+}
+
+// build builds the conditions of filters. Conditions failing to build match no ticket at all rather than leaking into
+// the query.
+func (r *TicketRepository) build(b *filters.Builder) (string, []interface{}) {
+	conditions, args, e := b.Build()
+	if e != nil {
+		r.logger.Error("failed to build filter conditions: ", e.Error())
+		return ` FALSE`, make([]interface{}, 0)
+	}
+
+	return conditions, args
 }
 
 func (r *TicketRepository) buildLoadCommentsQuery(tickets []*Ticket) (string, []interface{}) {
 	q := strings.Builder{}
 	args := make([]interface{}, 0)
 
-	q.WriteString(`SELECT id, ticket_id, owner, content, metadata, created_at, modified_at FROM comments WHERE
-						ticket_id IN (`)
+	q.WriteString(`SELECT id, ticket_id, owner, content, metadata, author_type, COALESCE(source, ''), revision,
+						edited_at, created_at, modified_at FROM comments WHERE ticket_id IN (`)
 
 	counter := 0
 	for _, t := range tickets {
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// TicketDraft is the entity model of ticket_drafts table. It is a ticket being composed by a requester, there is at
+// most one draft per requester which is identified by its issuer and owner.
+type TicketDraft struct {
+	Issuer          string
+	Owner           string
+	Subject         string
+	Content         string
+	Metadata        string
+	ImportanceLevel TicketImportanceLevel
+	CreatedAt       time.Time
+	ModifiedAt      time.Time
+}
+
+// TicketDraftRepository is the repository implementation of TicketDraft model.
+type TicketDraftRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewTicketDraftRepository returns back a newly created and ready to use TicketDraftRepository.
+func NewTicketDraftRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *TicketDraftRepository {
+	return &TicketDraftRepository{logger: logger, db: db}
+}
+
+// Save tries to insert the draft of a requester or replace it if it already exists.
+func (r *TicketDraftRepository) Save(ctx context.Context, draft TicketDraft) *errors.Type {
+	q := `INSERT INTO ticket_drafts (issuer, owner, subject, content, metadata, importance_level, created_at,
+			modified_at) VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), NOW(), NOW())
+			ON CONFLICT (issuer, owner) DO UPDATE SET subject = EXCLUDED.subject, content = EXCLUDED.content,
+			metadata = EXCLUDED.metadata, importance_level = EXCLUDED.importance_level, modified_at = NOW();`
+
+	_, e := r.db.Exec(ctx, q, draft.Issuer, draft.Owner, draft.Subject, draft.Content, draft.Metadata,
+		draft.ImportanceLevel)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// Load tries to load the draft of a requester that was modified after the provided time, older drafts are expired.
+func (r *TicketDraftRepository) Load(ctx context.Context, issuer, owner string, modifiedAfter time.Time) (*TicketDraft,
+	*errors.Type) {
+
+	q := `SELECT issuer, owner, subject, content, metadata, importance_level, created_at, modified_at
+			FROM ticket_drafts WHERE issuer = $1 AND owner = $2 AND modified_at > $3;`
+
+	draft := &TicketDraft{}
+	var metadata, importanceLevel sql.NullString
+
+	e := r.db.QueryRow(ctx, q, issuer, owner, modifiedAfter).Scan(&draft.Issuer, &draft.Owner, &draft.Subject,
+		&draft.Content, &metadata, &importanceLevel, &draft.CreatedAt, &draft.ModifiedAt)
+	if e != nil {
+		if e == pgx.ErrNoRows {
+			return nil, errors.NotFound("draft.not_found", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	draft.Metadata = metadata.String
+	draft.ImportanceLevel = TicketImportanceLevel(importanceLevel.String)
+
+	return draft, nil
+}
+
+// Delete tries to delete the draft of a requester, deleting a missing draft is not an error.
+func (r *TicketDraftRepository) Delete(ctx context.Context, issuer, owner string) *errors.Type {
+	q := `DELETE FROM ticket_drafts WHERE issuer = $1 AND owner = $2;`
+
+	if _, e := r.db.Exec(ctx, q, issuer, owner); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// DeleteExpired tries to delete the drafts that were not modified since the provided time and returns back the
+// number of deleted drafts.
+func (r *TicketDraftRepository) DeleteExpired(ctx context.Context, modifiedBefore time.Time) (int64, *errors.Type) {
+	q := `DELETE FROM ticket_drafts WHERE modified_at <= $1;`
+
+	tag, e := r.db.Exec(ctx, q, modifiedBefore)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return 0, et
+	}
+
+	return tag.RowsAffected(), nil
+}
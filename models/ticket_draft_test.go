@@ -0,0 +1,99 @@
+package models_test
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("TicketDraft", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.TicketDraftRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewTicketDraftRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	Describe("TicketDraftRepository", func() {
+		Context("When Save called twice for a requester", func() {
+			It("Should keep the last draft only", func() {
+				draft := models.TicketDraft{Issuer: "Microservice-A", Owner: "user1@example.com",
+					Subject: "Technical Problem"}
+
+				Ω(repository.Save(context.Background(), draft)).Should(BeNil())
+
+				draft.Content = "Hello, i have some issues with"
+				draft.ImportanceLevel = models.TicketImportanceLevelHigh
+				Ω(repository.Save(context.Background(), draft)).Should(BeNil())
+
+				loaded, e := repository.Load(context.Background(), draft.Issuer, draft.Owner,
+					time.Now().UTC().Add(-time.Hour))
+				Ω(e).Should(BeNil())
+				Ω(loaded.Subject).Should(Equal(draft.Subject))
+				Ω(loaded.Content).Should(Equal(draft.Content))
+				Ω(loaded.ImportanceLevel).Should(Equal(models.TicketImportanceLevelHigh))
+				Ω(loaded.Metadata).Should(BeEmpty())
+			})
+		})
+
+		Context("When Load called for an expired draft", func() {
+			It("Should return not found error", func() {
+				draft := models.TicketDraft{Issuer: "Microservice-A", Owner: "user1@example.com"}
+				Ω(repository.Save(context.Background(), draft)).Should(BeNil())
+
+				_, e := repository.Load(context.Background(), draft.Issuer, draft.Owner,
+					time.Now().UTC().Add(time.Hour))
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusNotFound))
+			})
+		})
+
+		Context("When DeleteExpired and Delete called", func() {
+			It("Should delete the expired drafts and drafts of requesters", func() {
+				Ω(repository.Save(context.Background(), models.TicketDraft{Issuer: "Microservice-A",
+					Owner: "user1@example.com"})).Should(BeNil())
+				Ω(repository.Save(context.Background(), models.TicketDraft{Issuer: "Microservice-A",
+					Owner: "user2@example.com"})).Should(BeNil())
+
+				deleted, e := repository.DeleteExpired(context.Background(), time.Now().UTC().Add(-time.Hour))
+				Ω(e).Should(BeNil())
+				Ω(deleted).Should(Equal(int64(0)))
+
+				Ω(repository.Delete(context.Background(), "Microservice-A", "user1@example.com")).Should(BeNil())
+
+				deleted, e = repository.DeleteExpired(context.Background(), time.Now().UTC().Add(time.Hour))
+				Ω(e).Should(BeNil())
+				Ω(deleted).Should(Equal(int64(1)))
+			})
+		})
+	})
+})
@@ -0,0 +1,189 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// TicketReference is the entity model of ticket_references table. It links a ticket to the issue it escalated to in an
+// external system, e.g. a Jira or GitHub issue.
+type TicketReference struct {
+	Model
+
+	TicketID    int64
+	System      ReferenceSystem
+	URL         string
+	ExternalKey string
+	SyncStatus  ReferenceSyncStatus
+	// SyncFailure describes why the last synchronization failed.
+	SyncFailure string
+}
+
+// TicketReferenceRepository is the repository implementation of TicketReference model.
+type TicketReferenceRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewTicketReferenceRepository returns back a newly created and ready to use TicketReferenceRepository.
+func NewTicketReferenceRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *TicketReferenceRepository {
+	return &TicketReferenceRepository{logger: logger, db: db}
+}
+
+// Insert tries to insert a reference into ticket_references table and returns back its id. The sync status defaults
+// to NONE, i.e. not synchronized.
+func (r *TicketReferenceRepository) Insert(ctx context.Context, reference TicketReference) (int64, *errors.Type) {
+	q := `INSERT INTO ticket_references (ticket_id, system, url, external_key, sync_status, created_at, modified_at)
+			VALUES ($1, $2, $3, $4, $5, NOW(), NOW()) ON CONFLICT (ticket_id, system, external_key) DO NOTHING
+			RETURNING id;`
+
+	if reference.SyncStatus == "" {
+		reference.SyncStatus = ReferenceSyncStatusNone
+	}
+
+	var id int64
+	e := r.db.QueryRow(ctx, q, reference.TicketID, reference.System, reference.URL, reference.ExternalKey,
+		reference.SyncStatus).Scan(&id)
+	if e != nil {
+		if e == pgx.ErrNoRows {
+			return 0, errors.AlreadyExists("reference.already_exists", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return 0, et
+	}
+
+	return id, nil
+}
+
+// LoadByTicketID tries to load the references of a ticket, oldest first.
+func (r *TicketReferenceRepository) LoadByTicketID(ctx context.Context, ticketID int64) ([]*TicketReference,
+	*errors.Type) {
+
+	q := `SELECT id, ticket_id, system, url, external_key, sync_status, sync_failure, created_at, modified_at
+			FROM ticket_references WHERE ticket_id = $1 ORDER BY created_at, id;`
+
+	return r.load(ctx, q, ticketID)
+}
+
+// LoadSynchronized tries to load the references whose state is synchronized with their external issues.
+func (r *TicketReferenceRepository) LoadSynchronized(ctx context.Context) ([]*TicketReference, *errors.Type) {
+	q := `SELECT id, ticket_id, system, url, external_key, sync_status, sync_failure, created_at, modified_at
+			FROM ticket_references WHERE sync_status <> $1 ORDER BY ticket_id, id;`
+
+	return r.load(ctx, q, ReferenceSyncStatusNone)
+}
+
+// LoadSynchronizedByExternalKey tries to load the synchronized references to an external issue, which may be linked
+// to more than one ticket.
+func (r *TicketReferenceRepository) LoadSynchronizedByExternalKey(ctx context.Context, system ReferenceSystem,
+	externalKey string) ([]*TicketReference, *errors.Type) {
+
+	q := `SELECT id, ticket_id, system, url, external_key, sync_status, sync_failure, created_at, modified_at
+			FROM ticket_references WHERE system = $1 AND external_key = $2 AND sync_status <> $3
+			ORDER BY ticket_id, id;`
+
+	return r.load(ctx, q, system, externalKey, ReferenceSyncStatusNone)
+}
+
+func (r *TicketReferenceRepository) load(ctx context.Context, q string, args ...interface{}) ([]*TicketReference,
+	*errors.Type) {
+
+	rows, e := r.db.Query(ctx, q, args...)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	references := make([]*TicketReference, 0)
+	for rows.Next() {
+		reference := &TicketReference{}
+		var failure sql.NullString
+
+		e := rows.Scan(&reference.ID, &reference.TicketID, &reference.System, &reference.URL, &reference.ExternalKey,
+			&reference.SyncStatus, &failure, &reference.CreatedAt, &reference.ModifiedAt)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		if failure.Valid {
+			reference.SyncFailure = failure.String
+		}
+
+		references = append(references, reference)
+	}
+
+	return references, nil
+}
+
+// UpdateSyncStatus tries to record the outcome of the last synchronization of a reference. Failure should be empty
+// unless the status is FAILED.
+func (r *TicketReferenceRepository) UpdateSyncStatus(ctx context.Context, id int64, status ReferenceSyncStatus,
+	failure string) *errors.Type {
+
+	q := `UPDATE ticket_references SET sync_status = $1, sync_failure = NULLIF($2, ''), modified_at = NOW()
+			WHERE id = $3;`
+
+	if _, e := r.db.Exec(ctx, q, status, failure, id); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// DeleteByID tries to delete a reference, detaching the external issue from its ticket.
+func (r *TicketReferenceRepository) DeleteByID(ctx context.Context, id int64) *errors.Type {
+	q := `DELETE FROM ticket_references WHERE id = $1;`
+
+	if _, e := r.db.Exec(ctx, q, id); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// ReferenceSystem model.
+type ReferenceSystem string
+
+// Different external system instances.
+const (
+	ReferenceSystemJira   ReferenceSystem = "JIRA"
+	ReferenceSystemGitHub ReferenceSystem = "GITHUB"
+	ReferenceSystemOther  ReferenceSystem = "OTHER"
+)
+
+// IsValid reports whether the system is one of the known systems.
+func (s ReferenceSystem) IsValid() bool {
+	switch s {
+	case ReferenceSystemJira, ReferenceSystemGitHub, ReferenceSystemOther:
+		return true
+	}
+
+	return false
+}
+
+// ReferenceSyncStatus model.
+type ReferenceSyncStatus string
+
+// Different reference sync status instances.
+const (
+	// ReferenceSyncStatusNone means the reference is only a link and is not synchronized.
+	ReferenceSyncStatusNone    ReferenceSyncStatus = "NONE"
+	ReferenceSyncStatusPending ReferenceSyncStatus = "PENDING"
+	ReferenceSyncStatusSynced  ReferenceSyncStatus = "SYNCED"
+	ReferenceSyncStatusFailed  ReferenceSyncStatus = "FAILED"
+)
@@ -0,0 +1,151 @@
+package models_test
+
+import (
+	"context"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("TicketReference", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var ticketRepository *models.TicketRepository
+	var repository *models.TicketReferenceRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			ticketRepository = models.NewTicketRepository(zap.S(), db)
+			repository = models.NewTicketReferenceRepository(zap.S(), db)
+		}
+
+		ticket := models.Ticket{
+			Issuer:          "Microservice-A",
+			Owner:           "user@example.com",
+			Subject:         "Technical Problem",
+			Content:         "Hello, i have some issues with REST API Docs!",
+			ImportanceLevel: models.TicketImportanceLevelMedium,
+		}
+
+		_, e = ticketRepository.Insert(context.Background(), ticket)
+		Ω(e).Should(BeNil())
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	Describe("TicketReferenceRepository", func() {
+		Context("When Insert called", func() {
+			It("Should attach an external issue to a ticket once", func() {
+				reference := models.TicketReference{
+					TicketID:    1,
+					System:      models.ReferenceSystemGitHub,
+					URL:         "https://github.com/jibitters/kiosk/issues/42",
+					ExternalKey: "jibitters/kiosk#42",
+				}
+
+				id, e := repository.Insert(context.Background(), reference)
+				Ω(e).Should(BeNil())
+				Ω(id).Should(Equal(int64(1)))
+
+				_, e = repository.Insert(context.Background(), reference)
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("reference.already_exists"))
+
+				references, e := repository.LoadByTicketID(context.Background(), 1)
+				Ω(e).Should(BeNil())
+				Ω(references).Should(HaveLen(1))
+				Ω(references[0].SyncStatus).Should(Equal(models.ReferenceSyncStatusNone))
+				Ω(references[0].ExternalKey).Should(Equal("jibitters/kiosk#42"))
+			})
+		})
+
+		Context("When UpdateSyncStatus called", func() {
+			It("Should record the outcome of synchronizing synchronized references", func() {
+				_, e := repository.Insert(context.Background(), models.TicketReference{TicketID: 1,
+					System: models.ReferenceSystemJira, URL: "https://jira.example.com/browse/OPS-1",
+					ExternalKey: "OPS-1", SyncStatus: models.ReferenceSyncStatusPending})
+				Ω(e).Should(BeNil())
+
+				_, e = repository.Insert(context.Background(), models.TicketReference{TicketID: 1,
+					System: models.ReferenceSystemOther, URL: "https://tracker.example.com/2", ExternalKey: "2"})
+				Ω(e).Should(BeNil())
+
+				references, e := repository.LoadSynchronized(context.Background())
+				Ω(e).Should(BeNil())
+				Ω(references).Should(HaveLen(1))
+
+				e = repository.UpdateSyncStatus(context.Background(), references[0].ID,
+					models.ReferenceSyncStatusFailed, "401 Unauthorized")
+				Ω(e).Should(BeNil())
+
+				references, e = repository.LoadSynchronized(context.Background())
+				Ω(e).Should(BeNil())
+				Ω(references[0].SyncStatus).Should(Equal(models.ReferenceSyncStatusFailed))
+				Ω(references[0].SyncFailure).Should(Equal("401 Unauthorized"))
+			})
+		})
+
+		Context("When LoadSynchronizedByExternalKey called", func() {
+			It("Should load only the synchronized references to the issue", func() {
+				_, e := repository.Insert(context.Background(), models.TicketReference{TicketID: 1,
+					System: models.ReferenceSystemGitHub, URL: "https://github.com/jibitters/kiosk/issues/42",
+					ExternalKey: "jibitters/kiosk#42", SyncStatus: models.ReferenceSyncStatusSynced})
+				Ω(e).Should(BeNil())
+
+				_, e = repository.Insert(context.Background(), models.TicketReference{TicketID: 1,
+					System: models.ReferenceSystemGitHub, URL: "https://github.com/jibitters/kiosk/issues/43",
+					ExternalKey: "jibitters/kiosk#43"})
+				Ω(e).Should(BeNil())
+
+				references, e := repository.LoadSynchronizedByExternalKey(context.Background(),
+					models.ReferenceSystemGitHub, "jibitters/kiosk#42")
+				Ω(e).Should(BeNil())
+				Ω(references).Should(HaveLen(1))
+				Ω(references[0].TicketID).Should(Equal(int64(1)))
+
+				references, e = repository.LoadSynchronizedByExternalKey(context.Background(),
+					models.ReferenceSystemGitHub, "jibitters/kiosk#43")
+				Ω(e).Should(BeNil())
+				Ω(references).Should(BeEmpty())
+			})
+		})
+
+		Context("When the ticket gets deleted", func() {
+			It("Should delete its references too", func() {
+				_, e := repository.Insert(context.Background(), models.TicketReference{TicketID: 1,
+					System: models.ReferenceSystemJira, URL: "https://jira.example.com/browse/OPS-1",
+					ExternalKey: "OPS-1"})
+				Ω(e).Should(BeNil())
+
+				deleted, e := ticketRepository.DeleteByID(context.Background(), 1)
+				Ω(e).Should(BeNil())
+				Ω(deleted).ShouldNot(BeNil())
+
+				references, e := repository.LoadByTicketID(context.Background(), 1)
+				Ω(e).Should(BeNil())
+				Ω(references).Should(BeEmpty())
+			})
+		})
+	})
+})
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// TicketRevision is the entity model of ticket_revisions table. It holds a previous revision of the subject and
+// content of a ticket, along with who replaced it, if known, and when.
+type TicketRevision struct {
+	ID         int64
+	TicketID   int64
+	Revision   int
+	Subject    string
+	Content    string
+	Editor     string
+	ReplacedAt time.Time
+}
+
+// Cursor returns back the position of the revision among the revisions of its ticket, oldest first.
+func (r *TicketRevision) Cursor() Cursor {
+	return intCursor(int64(r.Revision))
+}
+
+// TicketRevisionRepository is the repository implementation of TicketRevision model. Revisions are recorded by
+// TicketRepository.Update as tickets get edited. They are the editing history of tickets rather than an audit trail,
+// the tamper evident one is the append only AdminAuditLog.
+type TicketRevisionRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewTicketRevisionRepository returns back a newly created and ready to use TicketRevisionRepository.
+func NewTicketRevisionRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *TicketRevisionRepository {
+	return &TicketRevisionRepository{logger: logger, db: db}
+}
+
+// LoadByTicketID tries to load up to limit previous revisions of a ticket, or all of them when zero, oldest first.
+// They start right after the revision at the cursor when one is given, as returned back by its Cursor. The second
+// returned value tells whether there are more revisions.
+func (r *TicketRevisionRepository) LoadByTicketID(ctx context.Context, ticketID int64, after Cursor,
+	limit int) ([]*TicketRevision, bool, *errors.Type) {
+
+	// One more revision is loaded to tell whether there are more, a NULL limit loads all of them.
+	q := `SELECT id, ticket_id, revision, subject, content, editor, replaced_at FROM ticket_revisions
+			WHERE ticket_id = $1 AND ($2::INT IS NULL OR revision > $2) ORDER BY revision LIMIT NULLIF($3::INT, 0) + 1;`
+
+	rows, e := r.db.Query(ctx, q, ticketID, after.key(), limit)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, false, et
+	}
+	defer rows.Close()
+
+	revisions := make([]*TicketRevision, 0)
+	for rows.Next() {
+		revision := &TicketRevision{}
+		var editor sql.NullString
+
+		e := rows.Scan(&revision.ID, &revision.TicketID, &revision.Revision, &revision.Subject, &revision.Content,
+			&editor, &revision.ReplacedAt)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, false, et
+		}
+
+		if editor.Valid {
+			revision.Editor = editor.String
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	hasMore := limit > 0 && len(revisions) > limit
+	if hasMore {
+		// Drop the extra one.
+		revisions = revisions[:limit]
+	}
+
+	return revisions, hasMore, nil
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// TicketStatusChange is the entity model of ticket_status_changes table. It records a status change of a ticket along
+// with who made it, if known.
+type TicketStatusChange struct {
+	ID             int64
+	TicketID       int64
+	Issuer         string
+	PreviousStatus TicketStatus
+	Status         TicketStatus
+	Actor          string
+	ChangedAt      time.Time
+}
+
+// TicketStatusChangeRepository is the repository implementation of TicketStatusChange model.
+type TicketStatusChangeRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewTicketStatusChangeRepository returns back a newly created and ready to use TicketStatusChangeRepository.
+func NewTicketStatusChangeRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *TicketStatusChangeRepository {
+	return &TicketStatusChangeRepository{logger: logger, db: db}
+}
+
+// Insert tries to insert a status change into database.
+func (r *TicketStatusChangeRepository) Insert(ctx context.Context, change TicketStatusChange) *errors.Type {
+	q := `INSERT INTO ticket_status_changes (ticket_id, issuer, previous_status, status, actor, changed_at)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6);`
+
+	_, e := r.db.Exec(ctx, q, change.TicketID, change.Issuer, change.PreviousStatus, change.Status, change.Actor,
+		change.ChangedAt)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
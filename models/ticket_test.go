@@ -2,6 +2,8 @@ package models_test
 
 import (
 	"context"
+	"fmt"
+	"math/rand"
 	"net/http"
 	"time"
 
@@ -57,9 +59,27 @@ var _ = Describe("Ticket", func() {
 					ImportanceLevel: models.TicketImportanceLevelMedium,
 				}
 
-				e := repository.Insert(context.Background(), ticket)
+				_, e := repository.Insert(context.Background(), ticket)
 				Ω(e).Should(BeNil())
 			})
+
+			It("Should reject unknown statuses and importance levels", func() {
+				ticket := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user@example.com",
+					Subject:         "Technical Problem",
+					Content:         "Hello, i have some issues with REST API Docs!",
+					ImportanceLevel: "URGENT",
+				}
+
+				_, e := repository.Insert(context.Background(), ticket)
+				Ω(e).ShouldNot(BeNil())
+
+				ticket.ImportanceLevel = models.TicketImportanceLevelMedium
+				ticket.Status = "PENDING"
+				_, e = repository.Insert(context.Background(), ticket)
+				Ω(e).ShouldNot(BeNil())
+			})
 		})
 
 		Context("When LoadByID called", func() {
@@ -73,7 +93,7 @@ var _ = Describe("Ticket", func() {
 					ImportanceLevel: models.TicketImportanceLevelMedium,
 				}
 
-				e := repository.Insert(context.Background(), ticket)
+				_, e := repository.Insert(context.Background(), ticket)
 				Ω(e).Should(BeNil())
 
 				t, e := repository.LoadByID(context.Background(), 1)
@@ -82,7 +102,7 @@ var _ = Describe("Ticket", func() {
 				Ω(t.Owner).Should(Equal(ticket.Owner))
 				Ω(t.Subject).Should(Equal(ticket.Subject))
 				Ω(t.Content).Should(Equal(ticket.Content))
-				Ω(t.Metadata).Should(Equal(ticket.Metadata))
+				Ω(t.Metadata).Should(MatchJSON(ticket.Metadata))
 				Ω(t.ImportanceLevel).Should(Equal(ticket.ImportanceLevel))
 				Ω(t.Status).Should(Equal(models.TicketStatusNew))
 				Ω(t.CreatedAt).ShouldNot(BeNil())
@@ -100,7 +120,7 @@ var _ = Describe("Ticket", func() {
 					ImportanceLevel: models.TicketImportanceLevelMedium,
 				}
 
-				e := repository.Insert(context.Background(), ticket)
+				_, e := repository.Insert(context.Background(), ticket)
 				Ω(e).Should(BeNil())
 
 				comment := models.Comment{
@@ -110,7 +130,7 @@ var _ = Describe("Ticket", func() {
 					Metadata: `{"ip":"192.168.1.11"}`,
 				}
 
-				e = commentRepository.Insert(context.Background(), comment)
+				_, e = commentRepository.Insert(context.Background(), comment)
 				Ω(e).Should(BeNil())
 
 				t, e := repository.LoadByID(context.Background(), 1)
@@ -119,7 +139,7 @@ var _ = Describe("Ticket", func() {
 				Ω(t.Owner).Should(Equal(ticket.Owner))
 				Ω(t.Subject).Should(Equal(ticket.Subject))
 				Ω(t.Content).Should(Equal(ticket.Content))
-				Ω(t.Metadata).Should(Equal(ticket.Metadata))
+				Ω(t.Metadata).Should(MatchJSON(ticket.Metadata))
 				Ω(t.ImportanceLevel).Should(Equal(ticket.ImportanceLevel))
 				Ω(t.Status).Should(Equal(models.TicketStatusNew))
 				Ω(t.CreatedAt).ShouldNot(BeNil())
@@ -143,6 +163,31 @@ var _ = Describe("Ticket", func() {
 				Ω(e.Errors[0].Message).Should(BeEmpty())
 				Ω(e.HTTPStatusCode).Should(Equal(http.StatusNotFound))
 			})
+
+			Measure("Should load a busy ticket in a single round trip", func(b Benchmarker) {
+				ticket := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user@example.com",
+					Subject:         "Technical Problem",
+					Content:         "Hello, i have some issues with REST API Docs!",
+					ImportanceLevel: models.TicketImportanceLevelMedium,
+				}
+
+				id, e := repository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				for i := 0; i < 200; i++ {
+					comment := models.Comment{TicketID: id, Owner: "agent@example.com", Content: "Still on it."}
+					_, e = commentRepository.Insert(context.Background(), comment)
+					Ω(e).Should(BeNil())
+				}
+
+				b.Time("LoadByID", func() {
+					t, e := repository.LoadByID(context.Background(), id)
+					Ω(e).Should(BeNil())
+					Ω(t.Comments).Should(HaveLen(200))
+				})
+			}, 10)
 		})
 
 		Context("When Update called", func() {
@@ -156,7 +201,7 @@ var _ = Describe("Ticket", func() {
 					ImportanceLevel: models.TicketImportanceLevelMedium,
 				}
 
-				e := repository.Insert(context.Background(), ticket)
+				_, e := repository.Insert(context.Background(), ticket)
 				Ω(e).Should(BeNil())
 
 				t, e := repository.LoadByID(context.Background(), 1)
@@ -167,17 +212,112 @@ var _ = Describe("Ticket", func() {
 				t.ImportanceLevel = models.TicketImportanceLevelHigh
 				t.Status = models.TicketStatusClosed
 
-				e = repository.Update(context.Background(), t)
+				_, e = repository.Update(context.Background(), t, "")
 				Ω(e).Should(BeNil())
 
 				t, e = repository.LoadByID(context.Background(), 1)
 				Ω(e).Should(BeNil())
 				Ω(t.Subject).Should(Equal("Technical Documentation Problem"))
-				Ω(t.Metadata).Should(Equal(`{"ip":"192.168.1.10"}`))
+				Ω(t.Metadata).Should(MatchJSON(`{"ip":"192.168.1.10"}`))
 				Ω(t.ImportanceLevel).Should(Equal(models.TicketImportanceLevelHigh))
 				Ω(t.Status).Should(Equal(models.TicketStatusClosed))
 			})
 
+			It("Should refuse transitions the ticket is missing required fields or an approval for", func() {
+				ticket := models.Ticket{Issuer: "Microservice-A", Owner: "user@example.com", Subject: "Subject",
+					Content: "Content", ImportanceLevel: models.TicketImportanceLevelMedium}
+				id, e := repository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				requirementRepository := models.NewTransitionRequirementRepository(zap.S(), db)
+				e = requirementRepository.Save(context.Background(), "Microservice-A", models.TicketStatusReplied,
+					[]models.RequiredField{models.RequiredFieldAssignee})
+				Ω(e).Should(BeNil())
+
+				approverRepository := models.NewApproverRepository(zap.S(), db)
+				e = approverRepository.Save(context.Background(), "Microservice-A", models.TicketStatusClosed,
+					[]string{"lead@example.com"})
+				Ω(e).Should(BeNil())
+
+				t, e := repository.LoadByID(context.Background(), id)
+				Ω(e).Should(BeNil())
+
+				t.Status = models.TicketStatusReplied
+				_, e = repository.Update(context.Background(), t, "")
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("assignee.is_required"))
+
+				_, e = repository.Assign(context.Background(), id, "agent-a", false)
+				Ω(e).Should(BeNil())
+
+				_, e = repository.Update(context.Background(), t, "")
+				Ω(e).Should(BeNil())
+
+				t.Status = models.TicketStatusClosed
+				_, e = repository.Update(context.Background(), t, "")
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("ticket.approval_required"))
+
+				t, e = repository.LoadByID(context.Background(), id)
+				Ω(e).Should(BeNil())
+				Ω(t.Status).Should(Equal(models.TicketStatusReplied))
+
+				t.Subject = "Edited subject"
+				_, e = repository.Update(context.Background(), t, "")
+				Ω(e).Should(BeNil())
+			})
+
+			It("Should record the previous revision when the subject or content gets edited", func() {
+				ticket := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user@example.com",
+					Subject:         "Technical Problem",
+					Content:         "Hello, i have some issues with REST API Docs!",
+					ImportanceLevel: models.TicketImportanceLevelMedium,
+					Status:          models.TicketStatusNew,
+				}
+
+				_, e := repository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				t, e := repository.LoadByID(context.Background(), 1)
+				Ω(e).Should(BeNil())
+				Ω(t.Revision).Should(Equal(1))
+
+				t.Status = models.TicketStatusReplied
+				_, e = repository.Update(context.Background(), t, "agent@example.com")
+				Ω(e).Should(BeNil())
+
+				t.Content = ""
+				t.Subject = "Technical Documentation Problem"
+				_, e = repository.Update(context.Background(), t, "agent@example.com")
+				Ω(e).Should(BeNil())
+
+				t.Content = "Hello, i have some issues with gRPC API Docs!"
+				_, e = repository.Update(context.Background(), t, "")
+				Ω(e).Should(BeNil())
+
+				t, e = repository.LoadByID(context.Background(), 1)
+				Ω(e).Should(BeNil())
+				Ω(t.Revision).Should(Equal(3))
+				Ω(t.Subject).Should(Equal("Technical Documentation Problem"))
+				Ω(t.Content).Should(Equal("Hello, i have some issues with gRPC API Docs!"))
+
+				revisionRepository := models.NewTicketRevisionRepository(zap.S(), db)
+				revisions, hasMore, e := revisionRepository.LoadByTicketID(context.Background(), 1, nil, 0)
+				Ω(e).Should(BeNil())
+				Ω(hasMore).Should(BeFalse())
+				Ω(revisions).Should(HaveLen(2))
+				Ω(revisions[0].Revision).Should(Equal(1))
+				Ω(revisions[0].Subject).Should(Equal("Technical Problem"))
+				Ω(revisions[0].Content).Should(Equal("Hello, i have some issues with REST API Docs!"))
+				Ω(revisions[0].Editor).Should(Equal("agent@example.com"))
+				Ω(revisions[1].Revision).Should(Equal(2))
+				Ω(revisions[1].Subject).Should(Equal("Technical Documentation Problem"))
+				Ω(revisions[1].Content).Should(Equal("Hello, i have some issues with REST API Docs!"))
+				Ω(revisions[1].Editor).Should(BeEmpty())
+			})
+
 			It("Should return error when provided id does not exists", func() {
 				ticket := models.Ticket{
 					Issuer:          "Microservice-A",
@@ -188,20 +328,117 @@ var _ = Describe("Ticket", func() {
 					ImportanceLevel: models.TicketImportanceLevelMedium,
 				}
 
-				e := repository.Insert(context.Background(), ticket)
+				_, e := repository.Insert(context.Background(), ticket)
 				Ω(e).Should(BeNil())
 
 				t, e := repository.LoadByID(context.Background(), 1)
 				Ω(e).Should(BeNil())
 				t.ID = 100
 
-				e = repository.Update(context.Background(), t)
+				_, e = repository.Update(context.Background(), t, "")
 				Ω(e).ShouldNot(BeNil())
 				Ω(e.FingerPrint).ShouldNot(BeNil())
 				Ω(e.Errors[0].Code).Should(Equal("ticket.not_found"))
 				Ω(e.Errors[0].Message).Should(BeEmpty())
 				Ω(e.HTTPStatusCode).Should(Equal(http.StatusPreconditionFailed))
 			})
+
+			It("Should keep the invariants of statuses along any sequence of transitions", func() {
+				r := rand.New(rand.NewSource(GinkgoRandomSeed()))
+				invariantRepository := models.NewInvariantRepository(zap.S(), db)
+				statuses := []models.TicketStatus{models.TicketStatusNew, models.TicketStatusReplied,
+					models.TicketStatusResolved, models.TicketStatusClosed, models.TicketStatusBlocked,
+					models.TicketStatusWaitingOnCustomer}
+
+				ticket := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user@example.com",
+					Subject:         "Technical Problem",
+					Content:         "Hello, i have some issues with REST API Docs!",
+					ImportanceLevel: models.TicketImportanceLevelMedium,
+					Status:          models.TicketStatusNew,
+				}
+
+				id, e := repository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				current, e := repository.LoadByID(context.Background(), id)
+				Ω(e).Should(BeNil())
+				Ω(current.ResolvedAt).Should(BeNil())
+
+				for i := 0; i < 50; i++ {
+					status := statuses[r.Intn(len(statuses))]
+					transition := fmt.Sprintf("transition %d: %v -> %v", i, current.Status, status)
+
+					previous, e := repository.Update(context.Background(), &models.Ticket{ID: id,
+						Subject: current.Subject, ImportanceLevel: current.ImportanceLevel, Status: status}, "")
+					Ω(e).Should(BeNil(), transition)
+					Ω(previous.Status).Should(Equal(current.Status), transition)
+
+					next, e := repository.LoadByID(context.Background(), id)
+					Ω(e).Should(BeNil(), transition)
+					Ω(next.Status).Should(Equal(status), transition)
+					Ω(next.ModifiedAt).ShouldNot(BeTemporally("<", current.ModifiedAt), transition)
+
+					// Resolved at is only set when entering RESOLVED and survives reopening.
+					if status == models.TicketStatusResolved && current.Status != models.TicketStatusResolved {
+						Ω(next.ResolvedAt).ShouldNot(BeNil(), transition)
+						if current.ResolvedAt != nil {
+							Ω(*next.ResolvedAt).Should(BeTemporally(">=", *current.ResolvedAt), transition)
+						}
+					} else {
+						Ω(next.ResolvedAt).Should(Equal(current.ResolvedAt), transition)
+					}
+
+					violations, e := invariantRepository.Check(context.Background(), models.Invariants, 1)
+					Ω(e).Should(BeNil(), transition)
+					Ω(violations).Should(BeEmpty(), transition)
+
+					current = next
+				}
+			})
+		})
+
+		Context("When LoadIDByFingerprint called", func() {
+			It("Should load the latest open ticket of the issuer with the fingerprint", func() {
+				ticket := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "monitoring",
+					Subject:         "Disk is almost full",
+					Content:         "db-1 disk usage is 95%",
+					ImportanceLevel: models.TicketImportanceLevelHigh,
+					Fingerprint:     "disk-full/db-1",
+				}
+
+				_, e := repository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				id, e := repository.LoadIDByFingerprint(context.Background(), "Microservice-A", "disk-full/db-1",
+					time.Now().UTC().Add(-time.Hour))
+				Ω(e).Should(BeNil())
+				Ω(id).Should(Equal(int64(1)))
+
+				_, e = repository.LoadIDByFingerprint(context.Background(), "Microservice-B", "disk-full/db-1",
+					time.Now().UTC().Add(-time.Hour))
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusNotFound))
+
+				_, e = repository.LoadIDByFingerprint(context.Background(), "Microservice-A", "disk-full/db-1",
+					time.Now().UTC().Add(time.Hour))
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusNotFound))
+
+				t, e := repository.LoadByID(context.Background(), 1)
+				Ω(e).Should(BeNil())
+				t.Status = models.TicketStatusResolved
+				_, e = repository.Update(context.Background(), t, "")
+				Ω(e).Should(BeNil())
+
+				_, e = repository.LoadIDByFingerprint(context.Background(), "Microservice-A", "disk-full/db-1",
+					time.Now().UTC().Add(-time.Hour))
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusNotFound))
+			})
 		})
 
 		Context("When DeleteByID called", func() {
@@ -215,10 +452,10 @@ var _ = Describe("Ticket", func() {
 					ImportanceLevel: models.TicketImportanceLevelMedium,
 				}
 
-				e := repository.Insert(context.Background(), ticket)
+				_, e := repository.Insert(context.Background(), ticket)
 				Ω(e).Should(BeNil())
 
-				e = repository.DeleteByID(context.Background(), 1)
+				_, e = repository.DeleteByID(context.Background(), 1)
 				Ω(e).Should(BeNil())
 
 				t, e := repository.LoadByID(context.Background(), 1)
@@ -240,7 +477,7 @@ var _ = Describe("Ticket", func() {
 					ImportanceLevel: models.TicketImportanceLevelMedium,
 				}
 
-				e := repository.Insert(context.Background(), ticket)
+				_, e := repository.Insert(context.Background(), ticket)
 				Ω(e).Should(BeNil())
 
 				comment := models.Comment{
@@ -250,10 +487,10 @@ var _ = Describe("Ticket", func() {
 					Metadata: `{"ip":"192.168.1.11"}`,
 				}
 
-				e = commentRepository.Insert(context.Background(), comment)
+				_, e = commentRepository.Insert(context.Background(), comment)
 				Ω(e).Should(BeNil())
 
-				e = repository.DeleteByID(context.Background(), 1)
+				_, e = repository.DeleteByID(context.Background(), 1)
 				Ω(e).Should(BeNil())
 
 				t, e := repository.LoadByID(context.Background(), 1)
@@ -285,7 +522,7 @@ var _ = Describe("Ticket", func() {
 					ImportanceLevel: models.TicketImportanceLevelMedium,
 				}
 
-				e := repository.Insert(context.Background(), ticket1)
+				_, e := repository.Insert(context.Background(), ticket1)
 				Ω(e).Should(BeNil())
 
 				comment1 := models.Comment{
@@ -295,7 +532,7 @@ var _ = Describe("Ticket", func() {
 					Metadata: `{"ip":"192.168.1.11"}`,
 				}
 
-				e = commentRepository.Insert(context.Background(), comment1)
+				_, e = commentRepository.Insert(context.Background(), comment1)
 				Ω(e).Should(BeNil())
 
 				comment2 := models.Comment{
@@ -305,7 +542,7 @@ var _ = Describe("Ticket", func() {
 					Metadata: `{"ip":"192.168.1.1"}`,
 				}
 
-				e = commentRepository.Insert(context.Background(), comment2)
+				_, e = commentRepository.Insert(context.Background(), comment2)
 				Ω(e).Should(BeNil())
 
 				ticket2 := models.Ticket{
@@ -317,7 +554,7 @@ var _ = Describe("Ticket", func() {
 					ImportanceLevel: models.TicketImportanceLevelLow,
 				}
 
-				e = repository.Insert(context.Background(), ticket2)
+				_, e = repository.Insert(context.Background(), ticket2)
 				Ω(e).Should(BeNil())
 
 				comment3 := models.Comment{
@@ -327,12 +564,12 @@ var _ = Describe("Ticket", func() {
 					Metadata: `{"ip":"192.168.1.11"}`,
 				}
 
-				e = commentRepository.Insert(context.Background(), comment3)
+				_, e = commentRepository.Insert(context.Background(), comment3)
 				Ω(e).Should(BeNil())
 
 				ts, hasNextPage, e := repository.Filter(context.Background(), "", "", "",
-					"", time.Now().UTC().Add(-time.Hour).Format(time.RFC3339Nano), time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano),
-					1, 10)
+					"", models.Resolution{}, nil, false, time.Now().UTC().Add(-time.Hour).Format(time.RFC3339Nano), time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano),
+					nil, nil, 1, 10)
 
 				Ω(e).Should(BeNil())
 				Ω(len(ts)).Should(Equal(2))
@@ -352,7 +589,7 @@ var _ = Describe("Ticket", func() {
 					ImportanceLevel: models.TicketImportanceLevelMedium,
 				}
 
-				e := repository.Insert(context.Background(), ticket1)
+				_, e := repository.Insert(context.Background(), ticket1)
 				Ω(e).Should(BeNil())
 
 				ticket2 := models.Ticket{
@@ -364,12 +601,12 @@ var _ = Describe("Ticket", func() {
 					ImportanceLevel: models.TicketImportanceLevelLow,
 				}
 
-				e = repository.Insert(context.Background(), ticket2)
+				_, e = repository.Insert(context.Background(), ticket2)
 				Ω(e).Should(BeNil())
 
 				ts, hasNextPage, e := repository.Filter(context.Background(), "Microservice-A", "", "",
-					"", time.Now().UTC().Add(-time.Hour).Format(time.RFC3339Nano), time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano),
-					1, 10)
+					"", models.Resolution{}, nil, false, time.Now().UTC().Add(-time.Hour).Format(time.RFC3339Nano), time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano),
+					nil, nil, 1, 10)
 
 				Ω(e).Should(BeNil())
 				Ω(len(ts)).Should(Equal(1))
@@ -386,7 +623,7 @@ var _ = Describe("Ticket", func() {
 					ImportanceLevel: models.TicketImportanceLevelMedium,
 				}
 
-				e := repository.Insert(context.Background(), ticket1)
+				_, e := repository.Insert(context.Background(), ticket1)
 				Ω(e).Should(BeNil())
 
 				ticket2 := models.Ticket{
@@ -398,12 +635,12 @@ var _ = Describe("Ticket", func() {
 					ImportanceLevel: models.TicketImportanceLevelLow,
 				}
 
-				e = repository.Insert(context.Background(), ticket2)
+				_, e = repository.Insert(context.Background(), ticket2)
 				Ω(e).Should(BeNil())
 
 				ts, hasNextPage, e := repository.Filter(context.Background(), "Microservice-A", "user1@example.com", "",
-					"", time.Now().UTC().Add(-time.Hour).Format(time.RFC3339Nano), time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano),
-					1, 10)
+					"", models.Resolution{}, nil, false, time.Now().UTC().Add(-time.Hour).Format(time.RFC3339Nano), time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano),
+					nil, nil, 1, 10)
 
 				Ω(e).Should(BeNil())
 				Ω(len(ts)).Should(Equal(1))
@@ -420,7 +657,7 @@ var _ = Describe("Ticket", func() {
 					ImportanceLevel: models.TicketImportanceLevelMedium,
 				}
 
-				e := repository.Insert(context.Background(), ticket1)
+				_, e := repository.Insert(context.Background(), ticket1)
 				Ω(e).Should(BeNil())
 
 				ticket2 := models.Ticket{
@@ -432,24 +669,757 @@ var _ = Describe("Ticket", func() {
 					ImportanceLevel: models.TicketImportanceLevelLow,
 				}
 
-				e = repository.Insert(context.Background(), ticket2)
+				_, e = repository.Insert(context.Background(), ticket2)
 				Ω(e).Should(BeNil())
 
 				ts, hasNextPage, e := repository.Filter(context.Background(), "", "", "",
-					"", time.Now().UTC().Add(-time.Hour).Format(time.RFC3339Nano), time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano),
-					1, 1)
+					"", models.Resolution{}, nil, false, time.Now().UTC().Add(-time.Hour).Format(time.RFC3339Nano), time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano),
+					nil, nil, 1, 1)
 
 				Ω(e).Should(BeNil())
 				Ω(len(ts)).Should(Equal(1))
 				Ω(hasNextPage).Should(Equal(true))
 
 				ts, hasNextPage, e = repository.Filter(context.Background(), "", "", "",
-					"", time.Now().UTC().Add(-time.Hour).Format(time.RFC3339Nano), time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano),
-					2, 1)
+					"", models.Resolution{}, nil, false, time.Now().UTC().Add(-time.Hour).Format(time.RFC3339Nano), time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano),
+					nil, nil, 2, 1)
 
 				Ω(e).Should(BeNil())
 				Ω(len(ts)).Should(Equal(1))
 				Ω(hasNextPage).Should(Equal(false))
+
+				ts, _, e = repository.Filter(context.Background(), "", "", "", "", models.Resolution{}, nil, false,
+					time.Now().UTC().Add(-time.Hour).Format(time.RFC3339Nano),
+					time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano), []models.Order{{Field: "createdAt"}}, nil,
+					1, 10)
+
+				Ω(e).Should(BeNil())
+				Ω(len(ts)).Should(Equal(2))
+				Ω(ts[0].Owner).Should(Equal("user1@example.com"))
+				Ω(ts[1].Owner).Should(Equal("user2@example.com"))
+			})
+
+			It("Should page tickets after the cursor of the last one while tickets get modified", func() {
+				for _, owner := range []string{"user1@example.com", "user2@example.com", "user3@example.com"} {
+					_, e := repository.Insert(context.Background(), models.Ticket{Issuer: "Microservice-A", Owner: owner,
+						Subject: "Subject", Content: "Content", ImportanceLevel: models.TicketImportanceLevelLow})
+					Ω(e).Should(BeNil())
+				}
+
+				fromDate := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339Nano)
+				toDate := time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano)
+
+				ts, hasNextPage, e := repository.Filter(context.Background(), "", "", "", "", models.Resolution{}, nil,
+					false, fromDate, toDate, nil, nil, 1, 1)
+				Ω(e).Should(BeNil())
+				Ω(hasNextPage).Should(BeTrue())
+				Ω(ts[0].Owner).Should(Equal("user3@example.com"))
+				cursor := ts[0].Cursor(nil)
+
+				// Modifying the oldest ticket moves it to the first page, shifting the rest a page further.
+				t, e := repository.LoadByID(context.Background(), 1)
+				Ω(e).Should(BeNil())
+				t.Subject = "Modified"
+				_, e = repository.Update(context.Background(), t, "")
+				Ω(e).Should(BeNil())
+
+				ts, hasNextPage, e = repository.Filter(context.Background(), "", "", "", "", models.Resolution{}, nil,
+					false, fromDate, toDate, nil, cursor, 1, 1)
+				Ω(e).Should(BeNil())
+				Ω(hasNextPage).Should(BeFalse())
+				Ω(ts).Should(HaveLen(1))
+				Ω(ts[0].Owner).Should(Equal("user2@example.com"))
+
+				// Tickets that were never resolved tie on their NULL resolution times, so they are paged by their ids.
+				orders := []models.Order{{Field: "resolvedAt"}}
+				ts, _, e = repository.Filter(context.Background(), "", "", "", "", models.Resolution{}, nil, false,
+					fromDate, toDate, orders, nil, 1, 2)
+				Ω(e).Should(BeNil())
+
+				id := "2"
+				Ω(ts[1].Cursor(orders)).Should(Equal(models.Cursor{nil, &id}))
+
+				ts, hasNextPage, e = repository.Filter(context.Background(), "", "", "", "", models.Resolution{}, nil,
+					false, fromDate, toDate, orders, ts[1].Cursor(orders), 1, 2)
+				Ω(e).Should(BeNil())
+				Ω(hasNextPage).Should(BeFalse())
+				Ω(ts).Should(HaveLen(1))
+				Ω(ts[0].Owner).Should(Equal("user3@example.com"))
+			})
+
+			It("Should count and check the existence of matching tickets", func() {
+				ticket := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user@example.com",
+					Subject:         "Technical Problem",
+					Content:         "Hello, i have some issues with REST API Docs!",
+					ImportanceLevel: models.TicketImportanceLevelMedium,
+				}
+
+				for i := 0; i < 3; i++ {
+					_, e := repository.Insert(context.Background(), ticket)
+					Ω(e).Should(BeNil())
+				}
+
+				fromDate := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339Nano)
+				toDate := time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano)
+
+				count, e := repository.FilterCount(context.Background(), "Microservice-A", "", "", "",
+					models.Resolution{}, nil, false, fromDate, toDate)
+				Ω(e).Should(BeNil())
+				Ω(count).Should(Equal(int64(3)))
+
+				exists, e := repository.FilterExists(context.Background(), "Microservice-A", "", "", "",
+					models.Resolution{}, nil, false, fromDate, toDate)
+				Ω(e).Should(BeNil())
+				Ω(exists).Should(BeTrue())
+
+				exists, e = repository.FilterExists(context.Background(), "Microservice-B", "", "", "",
+					models.Resolution{}, nil, false, fromDate, toDate)
+				Ω(e).Should(BeNil())
+				Ω(exists).Should(BeFalse())
+			})
+
+			It("Should count matching tickets per status and importance level regardless of their own field", func() {
+				tickets := []models.Ticket{
+					{Issuer: "Microservice-A", Owner: "user@example.com", Subject: "Technical Problem",
+						Content: "Hello!", ImportanceLevel: models.TicketImportanceLevelMedium},
+					{Issuer: "Microservice-A", Owner: "user@example.com", Subject: "Technical Problem",
+						Content: "Hello!", ImportanceLevel: models.TicketImportanceLevelHigh},
+					{Issuer: "Microservice-A", Owner: "user@example.com", Subject: "Technical Problem",
+						Content: "Hello!", ImportanceLevel: models.TicketImportanceLevelHigh,
+						Status: models.TicketStatusResolved},
+					{Issuer: "Microservice-B", Owner: "user@example.com", Subject: "Technical Problem",
+						Content: "Hello!", ImportanceLevel: models.TicketImportanceLevelHigh},
+				}
+
+				for _, ticket := range tickets {
+					_, e := repository.Insert(context.Background(), ticket)
+					Ω(e).Should(BeNil())
+				}
+
+				facets, e := repository.FilterFacets(context.Background(), "Microservice-A", "",
+					models.TicketImportanceLevelHigh, models.TicketStatusNew, models.Resolution{}, nil, false,
+					time.Now().UTC().Add(-time.Hour).Format(time.RFC3339Nano),
+					time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano))
+				Ω(e).Should(BeNil())
+				Ω(facets.Statuses).Should(Equal(map[models.TicketStatus]int64{models.TicketStatusNew: 1,
+					models.TicketStatusResolved: 1}))
+				Ω(facets.ImportanceLevels).Should(Equal(map[models.TicketImportanceLevel]int64{
+					models.TicketImportanceLevelMedium: 1, models.TicketImportanceLevelHigh: 1}))
+			})
+
+			It("Should group matching tickets by importance level with their counts and first tickets", func() {
+				tickets := []models.Ticket{
+					{Issuer: "Microservice-A", Owner: "user@example.com", Subject: "First",
+						Content: "Hello!", ImportanceLevel: models.TicketImportanceLevelHigh},
+					{Issuer: "Microservice-A", Owner: "user@example.com", Subject: "Second",
+						Content: "Hello!", ImportanceLevel: models.TicketImportanceLevelLow},
+					{Issuer: "Microservice-A", Owner: "user@example.com", Subject: "Third",
+						Content: "Hello!", ImportanceLevel: models.TicketImportanceLevelHigh},
+					{Issuer: "Microservice-A", Owner: "user@example.com", Subject: "Fourth",
+						Content: "Hello!", ImportanceLevel: models.TicketImportanceLevelHigh},
+				}
+
+				for _, ticket := range tickets {
+					_, e := repository.Insert(context.Background(), ticket)
+					Ω(e).Should(BeNil())
+				}
+
+				groups, e := repository.FilterGroups(context.Background(), "importance", "Microservice-A", "", "",
+					models.TicketStatusNew, models.Resolution{}, nil, false,
+					time.Now().UTC().Add(-time.Hour).Format(time.RFC3339Nano),
+					time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano),
+					[]models.Order{{Field: "id"}}, 2)
+				Ω(e).Should(BeNil())
+				Ω(groups).Should(HaveLen(2))
+
+				Ω(groups[0].Value).Should(Equal(string(models.TicketImportanceLevelHigh)))
+				Ω(groups[0].Count).Should(Equal(int64(3)))
+				Ω(groups[0].Tickets).Should(HaveLen(2))
+				Ω(groups[0].Tickets[0].Subject).Should(Equal("First"))
+				Ω(groups[0].Tickets[1].Subject).Should(Equal("Third"))
+
+				Ω(groups[1].Value).Should(Equal(string(models.TicketImportanceLevelLow)))
+				Ω(groups[1].Count).Should(Equal(int64(1)))
+				Ω(groups[1].Tickets).Should(HaveLen(1))
+			})
+
+			It("Should estimate the number of matching tickets from planner statistics", func() {
+				ticket := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user@example.com",
+					Subject:         "Technical Problem",
+					Content:         "Hello, i have some issues with REST API Docs!",
+					ImportanceLevel: models.TicketImportanceLevelMedium,
+				}
+
+				for i := 0; i < 10; i++ {
+					_, e := repository.Insert(context.Background(), ticket)
+					Ω(e).Should(BeNil())
+				}
+
+				_, err := db.Exec(context.Background(), "ANALYZE tickets")
+				Ω(err).Should(BeNil())
+
+				estimate, e := repository.FilterEstimate(context.Background(), "Microservice-A", "", "", "",
+					models.Resolution{}, nil, false, time.Now().UTC().Add(-time.Hour).Format(time.RFC3339Nano),
+					time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano))
+				Ω(e).Should(BeNil())
+				Ω(estimate).Should(BeNumerically(">", 0))
+			})
+
+			It("Should filter tickets by the paths of their metadata", func() {
+				ticket := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user@example.com",
+					Subject:         "Technical Problem",
+					Content:         "Hello, i have some issues with REST API Docs!",
+					Metadata:        `{"owner_ip":"10.0.0.1","customer":{"plan":"gold","seats":5}}`,
+					ImportanceLevel: models.TicketImportanceLevelMedium,
+				}
+
+				id, e := repository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				ticket.Metadata = "not a json document"
+				_, e = repository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				fromDate := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339Nano)
+				toDate := time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano)
+
+				ts, _, e := repository.Filter(context.Background(), "", "", "", "", models.Resolution{},
+					models.MetadataMatch{"owner_ip": "10.0.0.1", "customer.seats": "5"}, false, fromDate, toDate, nil,
+					nil, 1, 10)
+				Ω(e).Should(BeNil())
+				Ω(ts).Should(HaveLen(1))
+				Ω(ts[0].ID).Should(Equal(id))
+
+				count, e := repository.FilterCount(context.Background(), "", "", "", "", models.Resolution{},
+					models.MetadataMatch{"customer.plan": "silver"}, false, fromDate, toDate)
+				Ω(e).Should(BeNil())
+				Ω(count).Should(Equal(int64(0)))
+
+				ts, _, e = repository.Filter(context.Background(), "", "", "", "", models.Resolution{}, nil, false,
+					fromDate, toDate, nil, nil, 1, 10)
+				Ω(e).Should(BeNil())
+				Ω(ts).Should(HaveLen(2))
+				Ω(ts[0].Metadata).Should(Equal("not a json document"))
+			})
+
+			It("Should bind injected filter values rather than running them", func() {
+				ticket := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user@example.com",
+					Subject:         "Technical Problem",
+					Content:         "Hello, i have some issues with REST API Docs!",
+					ImportanceLevel: models.TicketImportanceLevelMedium,
+				}
+
+				_, e := repository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				fromDate := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339Nano)
+				toDate := time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano)
+				payloads := []string{"' OR '1'='1", "Microservice-A' --", "'; DROP TABLE tickets; --",
+					"Microservice-A\"; DELETE FROM comments; --", "$1", "\\' OR TRUE --"}
+
+				for _, payload := range payloads {
+					ts, _, e := repository.Filter(context.Background(), payload, payload, "", "",
+						models.Resolution{Category: payload, RootCause: payload},
+						models.MetadataMatch{"ip": payload}, false, fromDate, toDate, nil, nil, 1, 10)
+					Ω(e).Should(BeNil())
+					Ω(ts).Should(BeEmpty())
+
+					count, e := repository.FilterCount(context.Background(), "Microservice-A", payload, "", "",
+						models.Resolution{}, nil, false, fromDate, toDate)
+					Ω(e).Should(BeNil())
+					Ω(count).Should(Equal(int64(0)))
+				}
+
+				count, e := repository.FilterCount(context.Background(), "Microservice-A", "", "", "",
+					models.Resolution{}, nil, false, fromDate, toDate)
+				Ω(e).Should(BeNil())
+				Ω(count).Should(Equal(int64(1)))
+			})
+		})
+
+		Context("When Snooze called", func() {
+			It("Should hide the ticket from filters until it gets unsnoozed", func() {
+				ticket := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user@example.com",
+					Subject:         "Technical Problem",
+					Content:         "Hello, i have some issues with REST API Docs!",
+					ImportanceLevel: models.TicketImportanceLevelMedium,
+				}
+
+				id, e := repository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				e = repository.Snooze(context.Background(), id, time.Now().Add(time.Hour))
+				Ω(e).Should(BeNil())
+
+				fromDate := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339Nano)
+				toDate := time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano)
+
+				ts, _, e := repository.Filter(context.Background(), "", "", "", "", models.Resolution{}, nil, false,
+					fromDate, toDate, nil, nil, 1, 10)
+				Ω(e).Should(BeNil())
+				Ω(ts).Should(BeEmpty())
+
+				ts, _, e = repository.Filter(context.Background(), "", "", "", "", models.Resolution{}, nil, true,
+					fromDate, toDate, nil, nil, 1, 10)
+				Ω(e).Should(BeNil())
+				Ω(ts).Should(HaveLen(1))
+				Ω(ts[0].SnoozedUntil).ShouldNot(BeNil())
+
+				unsnoozed, e := repository.Unsnooze(context.Background(), id, "agent@example.com")
+				Ω(e).Should(BeNil())
+				Ω(unsnoozed).Should(BeFalse())
+
+				unsnoozed, e = repository.Unsnooze(context.Background(), id, "user@example.com")
+				Ω(e).Should(BeNil())
+				Ω(unsnoozed).Should(BeTrue())
+
+				t, e := repository.LoadByID(context.Background(), id)
+				Ω(e).Should(BeNil())
+				Ω(t.SnoozedUntil).Should(BeNil())
+			})
+
+			It("Should return back precondition failed error for missing tickets", func() {
+				e := repository.Snooze(context.Background(), 1, time.Now().Add(time.Hour))
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusPreconditionFailed))
+			})
+		})
+
+		Context("When Assign called", func() {
+			It("Should let one agent claim the ticket unless it gets reassigned", func() {
+				ticket := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user@example.com",
+					Subject:         "Technical Problem",
+					Content:         "Hello, i have some issues with REST API Docs!",
+					ImportanceLevel: models.TicketImportanceLevelMedium,
+				}
+
+				id, e := repository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				previous, e := repository.Assign(context.Background(), id, "agent-a", false)
+				Ω(e).Should(BeNil())
+				Ω(previous).Should(BeEmpty())
+
+				previous, e = repository.Assign(context.Background(), id, "agent-a", false)
+				Ω(e).Should(BeNil())
+				Ω(previous).Should(Equal("agent-a"))
+
+				_, e = repository.Assign(context.Background(), id, "agent-b", false)
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusPreconditionFailed))
+				Ω(e.Errors[0].Code).Should(Equal("ticket.already_assigned"))
+
+				previous, e = repository.Assign(context.Background(), id, "agent-b", true)
+				Ω(e).Should(BeNil())
+				Ω(previous).Should(Equal("agent-a"))
+
+				t, e := repository.LoadByID(context.Background(), id)
+				Ω(e).Should(BeNil())
+				Ω(t.Assignee).Should(Equal("agent-b"))
+			})
+
+			It("Should return back precondition failed error for missing tickets", func() {
+				_, e := repository.Assign(context.Background(), 1, "agent-a", false)
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusPreconditionFailed))
+			})
+		})
+
+		Context("When Unassign called", func() {
+			It("Should only release the ticket from its assignee", func() {
+				ticket := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user@example.com",
+					Subject:         "Technical Problem",
+					Content:         "Hello, i have some issues with REST API Docs!",
+					ImportanceLevel: models.TicketImportanceLevelMedium,
+				}
+
+				id, e := repository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				_, e = repository.Assign(context.Background(), id, "agent-a", false)
+				Ω(e).Should(BeNil())
+
+				_, e = repository.Unassign(context.Background(), id, "agent-b")
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.Errors[0].Code).Should(Equal("ticket.assigned_to_another"))
+
+				previous, e := repository.Unassign(context.Background(), id, "agent-a")
+				Ω(e).Should(BeNil())
+				Ω(previous).Should(Equal("agent-a"))
+
+				previous, e = repository.Unassign(context.Background(), id, "")
+				Ω(e).Should(BeNil())
+				Ω(previous).Should(BeEmpty())
+
+				t, e := repository.LoadByID(context.Background(), id)
+				Ω(e).Should(BeNil())
+				Ω(t.Assignee).Should(BeEmpty())
+			})
+		})
+
+		Context("When FilterByAssignee called", func() {
+			It("Should return back the tickets of the assignee, the most recently modified first", func() {
+				ticket := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user@example.com",
+					Subject:         "Technical Problem",
+					Content:         "Hello, i have some issues with REST API Docs!",
+					ImportanceLevel: models.TicketImportanceLevelMedium,
+				}
+
+				ids := make([]int64, 0, 3)
+				for i := 0; i < 3; i++ {
+					id, e := repository.Insert(context.Background(), ticket)
+					Ω(e).Should(BeNil())
+					ids = append(ids, id)
+				}
+
+				_, e := repository.Assign(context.Background(), ids[0], "agent-a", false)
+				Ω(e).Should(BeNil())
+				_, e = repository.Assign(context.Background(), ids[1], "agent-b", false)
+				Ω(e).Should(BeNil())
+				_, e = repository.Assign(context.Background(), ids[2], "agent-a", false)
+				Ω(e).Should(BeNil())
+
+				ts, hasNextPage, e := repository.FilterByAssignee(context.Background(), "Microservice-A", "agent-a",
+					"", 1, 1)
+				Ω(e).Should(BeNil())
+				Ω(hasNextPage).Should(BeTrue())
+				Ω(ts).Should(HaveLen(1))
+				Ω(ts[0].ID).Should(Equal(ids[2]))
+				Ω(ts[0].Assignee).Should(Equal("agent-a"))
+
+				ts, hasNextPage, e = repository.FilterByAssignee(context.Background(), "Microservice-A", "agent-a",
+					"", 2, 1)
+				Ω(e).Should(BeNil())
+				Ω(hasNextPage).Should(BeFalse())
+				Ω(ts).Should(HaveLen(1))
+				Ω(ts[0].ID).Should(Equal(ids[0]))
+
+				ts, _, e = repository.FilterByAssignee(context.Background(), "Microservice-A", "agent-a",
+					models.TicketStatusClosed, 1, 10)
+				Ω(e).Should(BeNil())
+				Ω(ts).Should(BeEmpty())
+			})
+		})
+
+		Context("When UnsnoozeDue called", func() {
+			It("Should only unsnooze the tickets whose snooze time has come", func() {
+				ticket := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user@example.com",
+					Subject:         "Technical Problem",
+					Content:         "Hello, i have some issues with REST API Docs!",
+					ImportanceLevel: models.TicketImportanceLevelMedium,
+				}
+
+				id1, e := repository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+				id2, e := repository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				Ω(repository.Snooze(context.Background(), id1, time.Now().Add(time.Minute))).Should(BeNil())
+				Ω(repository.Snooze(context.Background(), id2, time.Now().Add(time.Hour))).Should(BeNil())
+
+				ids, e := repository.UnsnoozeDue(context.Background(), time.Now().Add(10*time.Minute))
+				Ω(e).Should(BeNil())
+				Ω(ids).Should(Equal([]int64{id1}))
+
+				ids, e = repository.UnsnoozeDue(context.Background(), time.Now().Add(10*time.Minute))
+				Ω(e).Should(BeNil())
+				Ω(ids).Should(BeEmpty())
+			})
+		})
+
+		Context("When a ticket waits on its customer", func() {
+			It("Should be nudged, closed or reopened depending on the silence of its owner", func() {
+				ticket := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user@example.com",
+					Subject:         "Technical Problem",
+					Content:         "Hello, i have some issues with REST API Docs!",
+					ImportanceLevel: models.TicketImportanceLevelMedium,
+				}
+
+				id1, e := repository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+				id2, e := repository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				for _, id := range []int64{id1, id2} {
+					t, e := repository.LoadByID(context.Background(), id)
+					Ω(e).Should(BeNil())
+					t.Status = models.TicketStatusWaitingOnCustomer
+
+					_, e = repository.Update(context.Background(), t, "")
+					Ω(e).Should(BeNil())
+				}
+
+				now := time.Now()
+				ids, e := repository.ClaimNudges(context.Background(), now.Add(time.Minute), now)
+				Ω(e).Should(BeNil())
+				Ω(ids).Should(ConsistOf(id1, id2))
+
+				ids, e = repository.ClaimNudges(context.Background(), now.Add(-time.Minute), now)
+				Ω(e).Should(BeNil())
+				Ω(ids).Should(BeEmpty())
+
+				reopened, e := repository.ReopenWaiting(context.Background(), id1, "agent@example.com")
+				Ω(e).Should(BeNil())
+				Ω(reopened).Should(BeFalse())
+
+				reopened, e = repository.ReopenWaiting(context.Background(), id1, "user@example.com")
+				Ω(e).Should(BeNil())
+				Ω(reopened).Should(BeTrue())
+
+				ids, e = repository.CloseWaiting(context.Background(), now.Add(time.Minute))
+				Ω(e).Should(BeNil())
+				Ω(ids).Should(Equal([]int64{id2}))
+
+				t, e := repository.LoadByID(context.Background(), id1)
+				Ω(e).Should(BeNil())
+				Ω(t.Status).Should(Equal(models.TicketStatusNew))
+
+				t, e = repository.LoadByID(context.Background(), id2)
+				Ω(e).Should(BeNil())
+				Ω(t.Status).Should(Equal(models.TicketStatusClosed))
+			})
+
+			It("Should keep tickets waiting when closing or reopening them needs a field or an approval", func() {
+				ticket := models.Ticket{Issuer: "Microservice-A", Owner: "user@example.com", Subject: "Subject",
+					Content: "Content", ImportanceLevel: models.TicketImportanceLevelMedium,
+					Status: models.TicketStatusWaitingOnCustomer}
+
+				id1, e := repository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+				id2, e := repository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				_, e = db.Exec(context.Background(), `UPDATE tickets SET waiting_since = NOW() WHERE id = ANY($1);`,
+					[]int64{id1, id2})
+				Ω(e).Should(BeNil())
+
+				requirementRepository := models.NewTransitionRequirementRepository(zap.S(), db)
+				Ω(requirementRepository.Save(context.Background(), "Microservice-A", models.TicketStatusClosed,
+					[]models.RequiredField{models.RequiredFieldAssignee})).Should(BeNil())
+
+				_, e = repository.Assign(context.Background(), id2, "agent-a", false)
+				Ω(e).Should(BeNil())
+
+				ids, e := repository.CloseWaiting(context.Background(), time.Now().Add(time.Minute))
+				Ω(e).Should(BeNil())
+				Ω(ids).Should(Equal([]int64{id2}))
+
+				approverRepository := models.NewApproverRepository(zap.S(), db)
+				Ω(approverRepository.Save(context.Background(), "Microservice-A", models.TicketStatusNew,
+					[]string{"lead@example.com"})).Should(BeNil())
+
+				reopened, e := repository.ReopenWaiting(context.Background(), id1, "user@example.com")
+				Ω(e).Should(BeNil())
+				Ω(reopened).Should(BeFalse())
+
+				t, e := repository.LoadByID(context.Background(), id1)
+				Ω(e).Should(BeNil())
+				Ω(t.Status).Should(Equal(models.TicketStatusWaitingOnCustomer))
+			})
+		})
+
+		Context("When CountByStatus called", func() {
+			It("Should count tickets per status and optionally per owner or assignee", func() {
+				ticket1 := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user1@example.com",
+					Subject:         "Technical Problem",
+					Content:         "Hello, i have some issues with REST API Docs!",
+					ImportanceLevel: models.TicketImportanceLevelMedium,
+				}
+
+				_, e := repository.Insert(context.Background(), ticket1)
+				Ω(e).Should(BeNil())
+
+				ticket2 := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user2@example.com",
+					Subject:         "UI Problem",
+					Content:         "Hello, i have some issues with panel!",
+					ImportanceLevel: models.TicketImportanceLevelLow,
+				}
+
+				_, e = repository.Insert(context.Background(), ticket2)
+				Ω(e).Should(BeNil())
+
+				t, e := repository.LoadByID(context.Background(), 2)
+				Ω(e).Should(BeNil())
+				t.Status = models.TicketStatusReplied
+
+				previous, e := repository.Update(context.Background(), t, "")
+				Ω(e).Should(BeNil())
+				Ω(previous.Owner).Should(Equal("user2@example.com"))
+				Ω(previous.Status).Should(Equal(models.TicketStatusNew))
+
+				_, e = repository.Assign(context.Background(), 2, "agent-a", false)
+				Ω(e).Should(BeNil())
+
+				counters, e := repository.CountByStatus(context.Background(), "", "")
+				Ω(e).Should(BeNil())
+				Ω(counters[models.TicketStatusNew]).Should(Equal(int64(1)))
+				Ω(counters[models.TicketStatusReplied]).Should(Equal(int64(1)))
+
+				counters, e = repository.CountByStatus(context.Background(), "user1@example.com", "")
+				Ω(e).Should(BeNil())
+				Ω(counters[models.TicketStatusNew]).Should(Equal(int64(1)))
+				Ω(counters).ShouldNot(HaveKey(models.TicketStatusReplied))
+
+				counters, e = repository.CountByStatus(context.Background(), "", "agent-a")
+				Ω(e).Should(BeNil())
+				Ω(counters[models.TicketStatusReplied]).Should(Equal(int64(1)))
+				Ω(counters).ShouldNot(HaveKey(models.TicketStatusNew))
+
+				counters, e = repository.CountByStatus(context.Background(), "user1@example.com", "agent-a")
+				Ω(e).Should(BeNil())
+				Ω(counters).Should(BeEmpty())
+
+				counters, e = repository.CountByStatus(context.Background(), "user2@example.com", "agent-a")
+				Ω(e).Should(BeNil())
+				Ω(counters).Should(Equal(map[models.TicketStatus]int64{models.TicketStatusReplied: 1}))
+			})
+
+			It("Should keep counters up to date as tickets get unassigned and deleted", func() {
+				ticket := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user1@example.com",
+					Subject:         "Technical Problem",
+					Content:         "Hello, i have some issues with REST API Docs!",
+					ImportanceLevel: models.TicketImportanceLevelMedium,
+				}
+
+				id1, e := repository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+				id2, e := repository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				_, e = repository.Assign(context.Background(), id1, "agent-a", false)
+				Ω(e).Should(BeNil())
+				_, e = repository.Assign(context.Background(), id2, "agent-a", false)
+				Ω(e).Should(BeNil())
+
+				_, e = repository.Unassign(context.Background(), id1, "")
+				Ω(e).Should(BeNil())
+
+				counters, e := repository.CountByStatus(context.Background(), "", "agent-a")
+				Ω(e).Should(BeNil())
+				Ω(counters).Should(Equal(map[models.TicketStatus]int64{models.TicketStatusNew: 1}))
+
+				_, e = repository.DeleteByID(context.Background(), id2)
+				Ω(e).Should(BeNil())
+
+				counters, e = repository.CountByStatus(context.Background(), "", "agent-a")
+				Ω(e).Should(BeNil())
+				Ω(counters).Should(BeEmpty())
+
+				counters, e = repository.CountByStatus(context.Background(), "user1@example.com", "")
+				Ω(e).Should(BeNil())
+				Ω(counters).Should(Equal(map[models.TicketStatus]int64{models.TicketStatusNew: 1}))
+
+				counters, e = repository.CountByStatus(context.Background(), "", "")
+				Ω(e).Should(BeNil())
+				Ω(counters).Should(Equal(map[models.TicketStatus]int64{models.TicketStatusNew: 1}))
+			})
+		})
+
+		Context("When Reindex called", func() {
+			It("Should rebuild search documents of tickets in batches", func() {
+				issuers := []string{"Microservice-A", "Microservice-A", "Microservice-A", "Microservice-B"}
+				for _, issuer := range issuers {
+					ticket := models.Ticket{
+						Issuer:          issuer,
+						Owner:           "user@example.com",
+						Subject:         "Technical Problem",
+						Content:         "Hello, i have some issues with REST API Docs!",
+						ImportanceLevel: models.TicketImportanceLevelMedium,
+					}
+
+					_, e := repository.Insert(context.Background(), ticket)
+					Ω(e).Should(BeNil())
+				}
+
+				_, e := db.Exec(context.Background(), "UPDATE tickets SET search_vector = NULL")
+				Ω(e).Should(BeNil())
+
+				fromDate := "2000-01-01T00:00:00Z"
+				toDate := time.Now().Add(time.Hour).UTC().Format(time.RFC3339Nano)
+
+				total, e := repository.CountForReindex(context.Background(), "Microservice-A", fromDate, toDate)
+				Ω(e).Should(BeNil())
+				Ω(total).Should(Equal(int64(3)))
+
+				lastID, count, e := repository.Reindex(context.Background(), "Microservice-A", fromDate, toDate, 0, 2)
+				Ω(e).Should(BeNil())
+				Ω(lastID).Should(Equal(int64(2)))
+				Ω(count).Should(Equal(2))
+
+				lastID, count, e = repository.Reindex(context.Background(), "Microservice-A", fromDate, toDate,
+					lastID, 2)
+				Ω(e).Should(BeNil())
+				Ω(lastID).Should(Equal(int64(3)))
+				Ω(count).Should(Equal(1))
+
+				var matches int
+				q := "SELECT COUNT(*) FROM tickets WHERE search_vector @@ to_tsquery('simple', 'docs')"
+				Ω(db.QueryRow(context.Background(), q).Scan(&matches)).Should(BeNil())
+				Ω(matches).Should(Equal(3))
+			})
+		})
+
+		Context("When a ticket changes", func() {
+			It("Should broadcast the change on kiosk_ticket_changes channel", func() {
+				connection, e := db.Acquire(context.Background())
+				Ω(e).Should(BeNil())
+				defer connection.Release()
+
+				_, e = connection.Exec(context.Background(), "LISTEN kiosk_ticket_changes")
+				Ω(e).Should(BeNil())
+
+				ticket := models.Ticket{
+					Issuer:          "Microservice-A",
+					Owner:           "user@example.com",
+					Subject:         "Technical Problem",
+					Content:         "Hello, i have some issues with REST API Docs!",
+					ImportanceLevel: models.TicketImportanceLevelMedium,
+				}
+
+				_, err := repository.Insert(context.Background(), ticket)
+				Ω(err).Should(BeNil())
+
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+
+				notification, e := connection.Conn().WaitForNotification(ctx)
+				Ω(e).Should(BeNil())
+				Ω(notification.Payload).Should(MatchJSON(
+					`{"operation":"INSERT","id":1,"owner":"user@example.com","assignee":null}`))
+
+				_, err = repository.Assign(context.Background(), 1, "agent-a", false)
+				Ω(err).Should(BeNil())
+
+				notification, e = connection.Conn().WaitForNotification(ctx)
+				Ω(e).Should(BeNil())
+				Ω(notification.Payload).Should(MatchJSON(`{"operation":"UPDATE","id":1,"owner":"user@example.com",` +
+					`"assignee":"agent-a","previous_assignee":null}`))
 			})
 		})
 	})
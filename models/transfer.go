@@ -0,0 +1,147 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// TransferRepository is the repository implementation of transferring the tickets of an issuer, along with their
+// comments, between kiosk deployments.
+type TransferRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewTransferRepository returns back a newly created and ready to use TransferRepository.
+func NewTransferRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *TransferRepository {
+	return &TransferRepository{logger: logger, db: db}
+}
+
+// Export tries to load the next page of tickets of an issuer after afterID, ordered by id, with their comments oldest
+// first. The tickets of all issuers are loaded when issuer is empty.
+func (r *TransferRepository) Export(ctx context.Context, issuer string, afterID int64, limit int) ([]*Ticket,
+	*errors.Type) {
+
+	q := `SELECT t.id, t.issuer, t.owner, t.subject, t.content, t.metadata #>> '{}', t.importance_level, t.status,
+			t.billable, COALESCE(t.external_id, ''), t.created_at, t.modified_at,
+			COALESCE(json_agg(json_build_object('id', c.id, 'owner', c.owner, 'content', c.content,
+			'metadata', c.metadata, 'authorType', c.author_type, 'source', c.source, 'createdAt', c.created_at,
+			'modifiedAt', c.modified_at) ORDER BY c.created_at, c.id)
+			FILTER (WHERE c.id IS NOT NULL), '[]')
+			FROM tickets AS t LEFT JOIN comments AS c ON c.ticket_id = t.id
+			WHERE ($1 = '' OR t.issuer = $1) AND t.id > $2 GROUP BY t.id ORDER BY t.id LIMIT $3;`
+
+	rows, e := r.db.Query(ctx, q, issuer, afterID, limit)
+	if e != nil {
+		return nil, queryFailed(r.logger, e)
+	}
+	defer rows.Close()
+
+	tickets := make([]*Ticket, 0)
+	for rows.Next() {
+		ticket := &Ticket{}
+		var metadata sql.NullString
+		var comments []byte
+
+		e := rows.Scan(&ticket.ID, &ticket.Issuer, &ticket.Owner, &ticket.Subject, &ticket.Content, &metadata,
+			&ticket.ImportanceLevel, &ticket.Status, &ticket.Billable, &ticket.ExternalID, &ticket.CreatedAt,
+			&ticket.ModifiedAt, &comments)
+		if e != nil {
+			return nil, queryFailed(r.logger, e)
+		}
+
+		ticket.Metadata = metadata.String
+
+		if ticket.Comments, e = decodeComments(ticket.ID, comments); e != nil {
+			return nil, queryFailed(r.logger, e)
+		}
+
+		tickets = append(tickets, ticket)
+	}
+
+	if e := rows.Err(); e != nil {
+		return nil, queryFailed(r.logger, e)
+	}
+
+	return tickets, nil
+}
+
+// Import tries to insert a transferred ticket along with its comments in a single transaction, keeping their original
+// timestamps. Tickets are identified by their issuer and external id, so a ticket imported before is left untouched.
+// It returns back the id of the ticket and whether it got imported by this call.
+func (r *TransferRepository) Import(ctx context.Context, ticket Ticket) (int64, bool, *errors.Type) {
+	tx, e := r.db.Begin(ctx)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return 0, false, et
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	q := `INSERT INTO tickets (issuer, owner, subject, content, metadata, importance_level, status, billable,
+			external_id, waiting_since, resolved_at, created_at, modified_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, CASE WHEN $7 = $11 THEN $10::TIMESTAMP END,
+			CASE WHEN $7 = $12 THEN $10::TIMESTAMP END, $13, $10)
+			ON CONFLICT (issuer, external_id) WHERE external_id IS NOT NULL DO NOTHING RETURNING id;`
+
+	var id int64
+	e = tx.QueryRow(ctx, q, ticket.Issuer, ticket.Owner, ticket.Subject, ticket.Content,
+		metadataDocument(ticket.Metadata), ticket.ImportanceLevel, ticket.Status, ticket.Billable, ticket.ExternalID,
+		ticket.ModifiedAt.UTC(), TicketStatusWaitingOnCustomer, TicketStatusResolved, ticket.CreatedAt.UTC()).Scan(&id)
+	if e == pgx.ErrNoRows {
+		q := `SELECT id FROM tickets WHERE issuer = $1 AND external_id = $2;`
+
+		if e := tx.QueryRow(ctx, q, ticket.Issuer, ticket.ExternalID).Scan(&id); e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return 0, false, et
+		}
+
+		return id, false, nil
+	}
+
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return 0, false, et
+	}
+
+	rows := make([][]interface{}, 0, len(ticket.Comments))
+	for _, c := range ticket.Comments {
+		authorType := string(c.AuthorType)
+		if authorType == "" {
+			authorType = string(CommentAuthorTypeAgent)
+		}
+
+		var source interface{}
+		if c.Source != "" {
+			source = string(c.Source)
+		}
+
+		rows = append(rows, []interface{}{id, c.Owner, c.Content, c.Metadata, authorType, source, c.CreatedAt.UTC(),
+			c.ModifiedAt.UTC()})
+	}
+
+	if len(rows) > 0 {
+		_, e = tx.CopyFrom(ctx, pgx.Identifier{"comments"}, []string{"ticket_id", "owner", "content", "metadata",
+			"author_type", "source", "created_at", "modified_at"}, pgx.CopyFromRows(rows))
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return 0, false, et
+		}
+	}
+
+	if e := tx.Commit(ctx); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return 0, false, et
+	}
+
+	return id, true, nil
+}
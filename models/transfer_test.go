@@ -0,0 +1,119 @@
+package models_test
+
+import (
+	"context"
+	"time"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Transfer", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.TransferRepository
+	var ticketRepository *models.TicketRepository
+	var commentRepository *models.CommentRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewTransferRepository(zap.S(), db)
+			ticketRepository = models.NewTicketRepository(zap.S(), db)
+			commentRepository = models.NewCommentRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	Describe("TransferRepository", func() {
+		Context("When Export called", func() {
+			It("Should return the tickets of the issuer after the id with their comments oldest first", func() {
+				ids := make([]int64, 0)
+				for _, issuer := range []string{"Microservice-A", "Microservice-B", "Microservice-A"} {
+					ticket := models.Ticket{Issuer: issuer, Owner: "user1@example.com", Subject: "Technical Problem",
+						Content: "Hello", ImportanceLevel: models.TicketImportanceLevelHigh}
+
+					id, e := ticketRepository.Insert(context.Background(), ticket)
+					Ω(e).Should(BeNil())
+					ids = append(ids, id)
+				}
+
+				for _, content := range []string{"First", "Second"} {
+					comment := models.Comment{TicketID: ids[2], Owner: "agent1", Content: content}
+					_, e := commentRepository.Insert(context.Background(), comment)
+					Ω(e).Should(BeNil())
+				}
+
+				tickets, e := repository.Export(context.Background(), "Microservice-A", 0, 10)
+				Ω(e).Should(BeNil())
+				Ω(tickets).Should(HaveLen(2))
+				Ω(tickets[0].ID).Should(Equal(ids[0]))
+				Ω(tickets[0].Comments).Should(BeEmpty())
+				Ω(tickets[1].Comments).Should(HaveLen(2))
+				Ω(tickets[1].Comments[0].Content).Should(Equal("First"))
+				Ω(tickets[1].Comments[0].AuthorType).Should(Equal(models.CommentAuthorTypeAgent))
+
+				tickets, e = repository.Export(context.Background(), "Microservice-A", ids[0], 10)
+				Ω(e).Should(BeNil())
+				Ω(tickets).Should(HaveLen(1))
+			})
+		})
+
+		Context("When Import called twice with the same external id", func() {
+			It("Should import the ticket and its comments once, keeping their timestamps", func() {
+				createdAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+				ticket := models.Ticket{Issuer: "Microservice-A", Owner: "user1@example.com",
+					Subject: "Technical Problem", Content: "Hello", ImportanceLevel: models.TicketImportanceLevelLow,
+					Status: models.TicketStatusResolved, ExternalID: "kiosk-a:42"}
+				ticket.CreatedAt = createdAt
+				ticket.ModifiedAt = createdAt.Add(time.Hour)
+
+				comment := &models.Comment{Owner: "user1@example.com", Content: "Thanks",
+					AuthorType: models.CommentAuthorTypeCustomer}
+				comment.CreatedAt = createdAt.Add(time.Minute)
+				comment.ModifiedAt = comment.CreatedAt
+				ticket.Comments = []*models.Comment{comment}
+
+				id, imported, e := repository.Import(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+				Ω(imported).Should(BeTrue())
+
+				again, imported, e := repository.Import(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+				Ω(imported).Should(BeFalse())
+				Ω(again).Should(Equal(id))
+
+				tickets, e := repository.Export(context.Background(), "Microservice-A", 0, 10)
+				Ω(e).Should(BeNil())
+				Ω(tickets).Should(HaveLen(1))
+				Ω(tickets[0].ExternalID).Should(Equal("kiosk-a:42"))
+				Ω(tickets[0].Status).Should(Equal(models.TicketStatusResolved))
+				Ω(tickets[0].CreatedAt.Equal(createdAt)).Should(BeTrue())
+				Ω(tickets[0].Comments).Should(HaveLen(1))
+				Ω(tickets[0].Comments[0].CreatedAt.Equal(comment.CreatedAt)).Should(BeTrue())
+				Ω(tickets[0].Comments[0].AuthorType).Should(Equal(models.CommentAuthorTypeCustomer))
+			})
+		})
+	})
+})
@@ -0,0 +1,276 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// RequiredField model. It is a field a ticket must have before being moved to a status.
+type RequiredField string
+
+// Different required field instances.
+const (
+	// RequiredFieldAgentComment requires a comment of an agent on the ticket, so its customer has been answered.
+	RequiredFieldAgentComment RequiredField = "AGENT_COMMENT"
+	// RequiredFieldTimeSpent requires some work logged on the ticket.
+	RequiredFieldTimeSpent RequiredField = "TIME_SPENT"
+	// RequiredFieldResolutionCategory and RequiredFieldRootCause require the corresponding fields of the resolution.
+	RequiredFieldResolutionCategory RequiredField = "RESOLUTION_CATEGORY"
+	RequiredFieldRootCause          RequiredField = "ROOT_CAUSE"
+	// RequiredFieldAssignee requires the ticket to be assigned to an agent.
+	RequiredFieldAssignee RequiredField = "ASSIGNEE"
+)
+
+// IsValid reports whether the field is one of the known required fields.
+func (f RequiredField) IsValid() bool {
+	switch f {
+	case RequiredFieldAgentComment, RequiredFieldTimeSpent, RequiredFieldResolutionCategory, RequiredFieldRootCause,
+		RequiredFieldAssignee:
+		return true
+	}
+
+	return false
+}
+
+// Description returns back a human readable description of the field.
+func (f RequiredField) Description() string {
+	switch f {
+	case RequiredFieldAgentComment:
+		return "a comment of an agent"
+	case RequiredFieldTimeSpent:
+		return "some logged work"
+	case RequiredFieldResolutionCategory:
+		return "a resolution category"
+	case RequiredFieldRootCause:
+		return "a root cause"
+	case RequiredFieldAssignee:
+		return "an assignee"
+	}
+
+	return string(f)
+}
+
+// TransitionRequirement is the entity model of transition_requirements table. Tickets of the issuer can only be
+// moved to the status once they have the field.
+type TransitionRequirement struct {
+	Issuer string
+	Status TicketStatus
+	Field  RequiredField
+}
+
+// TransitionRequirementRepository is the repository implementation of TransitionRequirement model.
+type TransitionRequirementRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewTransitionRequirementRepository returns back a newly created and ready to use TransitionRequirementRepository.
+func NewTransitionRequirementRepository(logger *zap.SugaredLogger,
+	db *pgxpool.Pool) *TransitionRequirementRepository {
+
+	return &TransitionRequirementRepository{logger: logger, db: db}
+}
+
+// Save tries to replace the fields required by a status of an issuer with the provided ones.
+func (r *TransitionRequirementRepository) Save(ctx context.Context, issuer string, status TicketStatus,
+	fields []RequiredField) *errors.Type {
+
+	begin := `BEGIN;`
+	deleteQ := `DELETE FROM transition_requirements WHERE issuer = $1 AND status = $2;`
+	insertQ := `INSERT INTO transition_requirements (issuer, status, field, created_at) VALUES ($1, $2, $3, NOW())
+			ON CONFLICT DO NOTHING;`
+	commit := `COMMIT;`
+
+	batch := &pgx.Batch{}
+	batch.Queue(begin)
+	batch.Queue(deleteQ, issuer, status)
+	for _, field := range fields {
+		batch.Queue(insertQ, issuer, status, field)
+	}
+	batch.Queue(commit)
+
+	if e := r.db.SendBatch(ctx, batch).Close(); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// LoadByIssuer tries to load the transition requirements of an issuer, ordered by status and field.
+func (r *TransitionRequirementRepository) LoadByIssuer(ctx context.Context, issuer string) ([]*TransitionRequirement,
+	*errors.Type) {
+
+	q := `SELECT issuer, status, field FROM transition_requirements WHERE issuer = $1 ORDER BY status, field;`
+
+	rows, e := r.db.Query(ctx, q, issuer)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	requirements := make([]*TransitionRequirement, 0)
+	for rows.Next() {
+		requirement := &TransitionRequirement{}
+		if e := rows.Scan(&requirement.Issuer, &requirement.Status, &requirement.Field); e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		requirements = append(requirements, requirement)
+	}
+
+	return requirements, nil
+}
+
+// LoadMissing tries to load the fields a ticket is missing to be moved to the status, considering the resolution being
+// captured along with the transition. It is empty when the ticket has all of them, including when the ticket already
+// has the status.
+func (r *TransitionRequirementRepository) LoadMissing(ctx context.Context, ticketID int64, status TicketStatus,
+	resolution Resolution) ([]RequiredField, *errors.Type) {
+
+	return loadMissingFields(ctx, r.logger, r.db, ticketID, status, resolution)
+}
+
+// Delete tries to delete the fields required by a status of an issuer.
+func (r *TransitionRequirementRepository) Delete(ctx context.Context, issuer string, status TicketStatus) *errors.Type {
+	q := `DELETE FROM transition_requirements WHERE issuer = $1 AND status = $2;`
+
+	if _, e := r.db.Exec(ctx, q, issuer, status); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// querier runs queries on either a pool or a transaction.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// loadMissingFields is LoadMissing run on db, which is either a pool or a transaction.
+func loadMissingFields(ctx context.Context, logger *zap.SugaredLogger, db querier, ticketID int64,
+	status TicketStatus, resolution Resolution) ([]RequiredField, *errors.Type) {
+
+	q := `SELECT r.field FROM transition_requirements AS r JOIN tickets AS t ON t.issuer = r.issuer
+			WHERE t.id = $1 AND r.status = $2 AND t.status <> $2 AND NOT CASE r.field
+			WHEN $3 THEN EXISTS (SELECT 1 FROM comments WHERE ticket_id = t.id AND author_type = $4)
+			WHEN $5 THEN t.time_spent_minutes > 0
+			WHEN $6 THEN COALESCE(NULLIF($7, ''), t.resolution_category) IS NOT NULL
+			WHEN $8 THEN COALESCE(NULLIF($9, ''), t.root_cause) IS NOT NULL
+			WHEN $10 THEN t.assignee IS NOT NULL
+			ELSE TRUE END
+			ORDER BY r.field;`
+
+	rows, e := db.Query(ctx, q, ticketID, status, RequiredFieldAgentComment, CommentAuthorTypeAgent,
+		RequiredFieldTimeSpent, RequiredFieldResolutionCategory, resolution.Category, RequiredFieldRootCause,
+		resolution.RootCause, RequiredFieldAssignee)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	fields := make([]RequiredField, 0)
+	for rows.Next() {
+		var field RequiredField
+		if e := rows.Scan(&field); e != nil {
+			et := errors.InternalServerError("unknown", "")
+			logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// MissingFieldsError returns back an error describing every field a ticket is missing to be moved to the status, one
+// error per field, or nil when there is none.
+func MissingFieldsError(status TicketStatus, fields []RequiredField) *errors.Type {
+	var et *errors.Type
+	for _, field := range fields {
+		code := strings.ToLower(string(field)) + ".is_required"
+		message := fmt.Sprintf("Moving the ticket to %v requires %v.", status, field.Description())
+
+		if et == nil {
+			et = errors.PreconditionFailed(code, message)
+		} else {
+			et.Errors = append(et.Errors, errors.Error{Code: code, Message: message})
+		}
+	}
+
+	return et
+}
+
+// checkRequiredFields fails when the ticket, locked by tx for the rest of it, is missing any field required to be
+// moved to the status along with the resolution. Checking within the transaction writing the transition keeps
+// concurrent changes, e.g. unassigning the ticket, from slipping in between.
+func checkRequiredFields(ctx context.Context, logger *zap.SugaredLogger, tx pgx.Tx, ticketID int64,
+	status TicketStatus, resolution Resolution) *errors.Type {
+
+	fields, e := loadMissingFields(ctx, logger, tx, ticketID, status, resolution)
+	if e != nil {
+		return e
+	}
+
+	return MissingFieldsError(status, fields)
+}
+
+// checkTransition is checkRequiredFields failing as well when the transition needs an approval, as transitions
+// guarded by approvers are only made through approved approvals.
+func checkTransition(ctx context.Context, logger *zap.SugaredLogger, tx pgx.Tx, ticketID int64,
+	status TicketStatus, resolution Resolution) *errors.Type {
+
+	if e := checkRequiredFields(ctx, logger, tx, ticketID, status, resolution); e != nil {
+		return e
+	}
+
+	approvers, e := loadTransitionApprovers(ctx, logger, tx, ticketID, status)
+	if e != nil {
+		return e
+	}
+
+	if len(approvers) > 0 {
+		return errors.PreconditionFailed("ticket.approval_required", "")
+	}
+
+	return nil
+}
+
+// transitionable splits the tickets, locked by tx for the rest of it, into the ones checkTransition lets be moved to
+// the status along with the resolution and the ones it refuses.
+func transitionable(ctx context.Context, logger *zap.SugaredLogger, tx pgx.Tx, ticketIDs []int64,
+	status TicketStatus, resolution Resolution) ([]int64, []int64, *errors.Type) {
+
+	allowed := make([]int64, 0, len(ticketIDs))
+	refused := make([]int64, 0)
+	for _, id := range ticketIDs {
+		e := checkTransition(ctx, logger, tx, id, status, resolution)
+		switch {
+		case e == nil:
+			allowed = append(allowed, id)
+		case e.Kind == errors.KindPrecondition:
+			refused = append(refused, id)
+		default:
+			return nil, nil, e
+		}
+	}
+
+	return allowed, refused, nil
+}
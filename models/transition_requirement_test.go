@@ -0,0 +1,122 @@
+package models_test
+
+import (
+	"context"
+	"time"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("TransitionRequirement", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.TransitionRequirementRepository
+	var ticketRepository *models.TicketRepository
+	var commentRepository *models.CommentRepository
+	var workLogRepository *models.WorkLogRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewTransitionRequirementRepository(zap.S(), db)
+			ticketRepository = models.NewTicketRepository(zap.S(), db)
+			commentRepository = models.NewCommentRepository(zap.S(), db)
+			workLogRepository = models.NewWorkLogRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	Describe("TransitionRequirementRepository", func() {
+		Context("When Save and LoadMissing called", func() {
+			It("Should report the required fields until the ticket has them", func() {
+				ticket := models.Ticket{Issuer: "Microservice-A", Owner: "user-1", Subject: "Subject",
+					Content: "Content", ImportanceLevel: models.TicketImportanceLevelHigh}
+				id, e := ticketRepository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				e = repository.Save(context.Background(), "Microservice-A", models.TicketStatusResolved,
+					[]models.RequiredField{models.RequiredFieldAgentComment, models.RequiredFieldTimeSpent})
+				Ω(e).Should(BeNil())
+
+				fields, e := repository.LoadMissing(context.Background(), id, models.TicketStatusResolved,
+					models.Resolution{})
+				Ω(e).Should(BeNil())
+				Ω(fields).Should(Equal([]models.RequiredField{models.RequiredFieldAgentComment,
+					models.RequiredFieldTimeSpent}))
+
+				fields, e = repository.LoadMissing(context.Background(), id, models.TicketStatusClosed,
+					models.Resolution{})
+				Ω(e).Should(BeNil())
+				Ω(fields).Should(BeEmpty())
+
+				_, e = commentRepository.Insert(context.Background(), models.Comment{TicketID: id, Owner: "user-1",
+					Content: "Any news?"})
+				Ω(e).Should(BeNil())
+
+				_, e = workLogRepository.Insert(context.Background(), models.WorkLog{TicketID: id, Agent: "agent-1",
+					Duration: 15 * time.Minute})
+				Ω(e).Should(BeNil())
+
+				fields, e = repository.LoadMissing(context.Background(), id, models.TicketStatusResolved,
+					models.Resolution{})
+				Ω(e).Should(BeNil())
+				Ω(fields).Should(Equal([]models.RequiredField{models.RequiredFieldAgentComment}))
+
+				_, e = commentRepository.Insert(context.Background(), models.Comment{TicketID: id, Owner: "agent-1",
+					Content: "Fixed"})
+				Ω(e).Should(BeNil())
+
+				fields, e = repository.LoadMissing(context.Background(), id, models.TicketStatusResolved,
+					models.Resolution{})
+				Ω(e).Should(BeNil())
+				Ω(fields).Should(BeEmpty())
+
+				e = repository.Save(context.Background(), "Microservice-A", models.TicketStatusReplied,
+					[]models.RequiredField{models.RequiredFieldAssignee})
+				Ω(e).Should(BeNil())
+
+				fields, e = repository.LoadMissing(context.Background(), id, models.TicketStatusReplied,
+					models.Resolution{})
+				Ω(e).Should(BeNil())
+				Ω(fields).Should(Equal([]models.RequiredField{models.RequiredFieldAssignee}))
+
+				_, e = ticketRepository.Assign(context.Background(), id, "agent-1", false)
+				Ω(e).Should(BeNil())
+
+				fields, e = repository.LoadMissing(context.Background(), id, models.TicketStatusReplied,
+					models.Resolution{})
+				Ω(e).Should(BeNil())
+				Ω(fields).Should(BeEmpty())
+
+				e = repository.Delete(context.Background(), "Microservice-A", models.TicketStatusResolved)
+				Ω(e).Should(BeNil())
+
+				requirements, e := repository.LoadByIssuer(context.Background(), "Microservice-A")
+				Ω(e).Should(BeNil())
+				Ω(requirements).Should(BeEmpty())
+			})
+		})
+	})
+})
@@ -0,0 +1,150 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// WebhookSource is the entity model of webhook_sources table. It is a system allowed to act on the tickets of an
+// issuer through inbound webhooks, its deliveries are signed with the secret. Tickets it opens are owned by the owner
+// and get the importance level unless the deliveries provide one.
+type WebhookSource struct {
+	Name            string
+	Issuer          string
+	Owner           string
+	ImportanceLevel TicketImportanceLevel
+	Secret          string
+	CreatedAt       time.Time
+	ModifiedAt      time.Time
+}
+
+// WebhookSourceRepository is the repository implementation of WebhookSource model.
+type WebhookSourceRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewWebhookSourceRepository returns back a newly created and ready to use WebhookSourceRepository.
+func NewWebhookSourceRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *WebhookSourceRepository {
+	return &WebhookSourceRepository{logger: logger, db: db}
+}
+
+// Save tries to insert a webhook source or update it if it already exists.
+func (r *WebhookSourceRepository) Save(ctx context.Context, source WebhookSource) *errors.Type {
+	q := `INSERT INTO webhook_sources (name, issuer, owner, importance_level, secret, created_at, modified_at)
+			VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+			ON CONFLICT (name) DO UPDATE SET issuer = EXCLUDED.issuer, owner = EXCLUDED.owner,
+			importance_level = EXCLUDED.importance_level, secret = EXCLUDED.secret, modified_at = NOW();`
+
+	_, e := r.db.Exec(ctx, q, source.Name, source.Issuer, source.Owner, source.ImportanceLevel, source.Secret)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// LoadByName tries to load a webhook source by its name.
+func (r *WebhookSourceRepository) LoadByName(ctx context.Context, name string) (*WebhookSource, *errors.Type) {
+	q := `SELECT name, issuer, owner, importance_level, secret, created_at, modified_at FROM webhook_sources
+			WHERE name = $1;`
+
+	source := &WebhookSource{}
+	e := r.db.QueryRow(ctx, q, name).Scan(&source.Name, &source.Issuer, &source.Owner, &source.ImportanceLevel,
+		&source.Secret, &source.CreatedAt, &source.ModifiedAt)
+	if e != nil {
+		if e == pgx.ErrNoRows {
+			return nil, errors.NotFound("webhook_source.not_found", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	return source, nil
+}
+
+// DeleteByName tries to delete a webhook source, so its deliveries get rejected from then on. Tickets it opened are
+// left as they are.
+func (r *WebhookSourceRepository) DeleteByName(ctx context.Context, name string) *errors.Type {
+	q := `DELETE FROM webhook_sources WHERE name = $1;`
+
+	if _, e := r.db.Exec(ctx, q, name); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// WebhookTicketRepository is the repository of webhook_tickets table, which links the tickets opened through inbound
+// webhooks to the keys their sources identify them with.
+type WebhookTicketRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewWebhookTicketRepository returns back a newly created and ready to use WebhookTicketRepository.
+func NewWebhookTicketRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *WebhookTicketRepository {
+	return &WebhookTicketRepository{logger: logger, db: db}
+}
+
+// Link tries to link a ticket to the external key of a source. It returns back false when the key is already linked
+// to a ticket, in which case the existing link is left as is.
+func (r *WebhookTicketRepository) Link(ctx context.Context, source, externalKey string, ticketID int64) (bool,
+	*errors.Type) {
+
+	q := `INSERT INTO webhook_tickets (source, external_key, ticket_id, created_at) VALUES ($1, $2, $3, NOW())
+			ON CONFLICT (source, external_key) DO NOTHING;`
+
+	tag, e := r.db.Exec(ctx, q, source, externalKey, ticketID)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return false, et
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// Unlink tries to remove the link of the external key of a source, so the key can be linked to another ticket.
+func (r *WebhookTicketRepository) Unlink(ctx context.Context, source, externalKey string) *errors.Type {
+	q := `DELETE FROM webhook_tickets WHERE source = $1 AND external_key = $2;`
+
+	if _, e := r.db.Exec(ctx, q, source, externalKey); e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// LoadTicketID tries to load the id of the ticket linked to the external key of a source.
+func (r *WebhookTicketRepository) LoadTicketID(ctx context.Context, source, externalKey string) (int64,
+	*errors.Type) {
+
+	q := `SELECT ticket_id FROM webhook_tickets WHERE source = $1 AND external_key = $2;`
+
+	var ticketID int64
+	if e := r.db.QueryRow(ctx, q, source, externalKey).Scan(&ticketID); e != nil {
+		if e == pgx.ErrNoRows {
+			return 0, errors.NotFound("externalKey.not_found", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return 0, et
+	}
+
+	return ticketID, nil
+}
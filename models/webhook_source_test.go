@@ -0,0 +1,107 @@
+package models_test
+
+import (
+	"context"
+	"net/http"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("WebhookSource", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.WebhookSourceRepository
+	var webhookTicketRepository *models.WebhookTicketRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewWebhookSourceRepository(zap.S(), db)
+			webhookTicketRepository = models.NewWebhookTicketRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	Describe("WebhookSourceRepository", func() {
+		Context("When Save called twice for a name", func() {
+			It("Should keep the last source only", func() {
+				source := models.WebhookSource{Name: "prometheus", Issuer: "Microservice-A", Owner: "monitoring",
+					ImportanceLevel: models.TicketImportanceLevelHigh, Secret: "0123456789abcdef"}
+				Ω(repository.Save(context.Background(), source)).Should(BeNil())
+
+				source.ImportanceLevel = models.TicketImportanceLevelCritical
+				Ω(repository.Save(context.Background(), source)).Should(BeNil())
+
+				loaded, e := repository.LoadByName(context.Background(), "prometheus")
+				Ω(e).Should(BeNil())
+				Ω(loaded.Issuer).Should(Equal("Microservice-A"))
+				Ω(loaded.Owner).Should(Equal("monitoring"))
+				Ω(loaded.ImportanceLevel).Should(Equal(models.TicketImportanceLevelCritical))
+				Ω(loaded.Secret).Should(Equal("0123456789abcdef"))
+			})
+		})
+
+		Context("When DeleteByName called", func() {
+			It("Should not load the source anymore", func() {
+				source := models.WebhookSource{Name: "prometheus", Issuer: "Microservice-A", Owner: "monitoring",
+					ImportanceLevel: models.TicketImportanceLevelHigh, Secret: "0123456789abcdef"}
+				Ω(repository.Save(context.Background(), source)).Should(BeNil())
+				Ω(repository.DeleteByName(context.Background(), "prometheus")).Should(BeNil())
+
+				_, e := repository.LoadByName(context.Background(), "prometheus")
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusNotFound))
+			})
+		})
+	})
+
+	Describe("WebhookTicketRepository", func() {
+		Context("When Link called for a linked key", func() {
+			It("Should keep the first link until it is unlinked", func() {
+				linked, e := webhookTicketRepository.Link(context.Background(), "prometheus", "disk-full", 1)
+				Ω(e).Should(BeNil())
+				Ω(linked).Should(BeTrue())
+
+				linked, e = webhookTicketRepository.Link(context.Background(), "prometheus", "disk-full", 2)
+				Ω(e).Should(BeNil())
+				Ω(linked).Should(BeFalse())
+
+				ticketID, e := webhookTicketRepository.LoadTicketID(context.Background(), "prometheus", "disk-full")
+				Ω(e).Should(BeNil())
+				Ω(ticketID).Should(Equal(int64(1)))
+
+				_, e = webhookTicketRepository.LoadTicketID(context.Background(), "grafana", "disk-full")
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusNotFound))
+
+				Ω(webhookTicketRepository.Unlink(context.Background(), "prometheus", "disk-full")).Should(BeNil())
+
+				linked, e = webhookTicketRepository.Link(context.Background(), "prometheus", "disk-full", 2)
+				Ω(e).Should(BeNil())
+				Ω(linked).Should(BeTrue())
+			})
+		})
+	})
+})
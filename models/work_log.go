@@ -0,0 +1,94 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"go.uber.org/zap"
+)
+
+// WorkLog is the entity model of work_logs table. It records time an agent spent on a ticket.
+type WorkLog struct {
+	ID       int64
+	TicketID int64
+	// Issuer is the issuer of the ticket, recorded along with the log so time reports do not depend on the ticket.
+	Issuer string
+	Agent  string
+	// Duration is stored with a minute precision.
+	Duration time.Duration
+	Note     string
+	LoggedAt time.Time
+}
+
+// WorkLogRepository is the repository implementation of WorkLog model.
+type WorkLogRepository struct {
+	logger *zap.SugaredLogger
+	db     *pgxpool.Pool
+}
+
+// NewWorkLogRepository returns back a newly created and ready to use WorkLogRepository.
+func NewWorkLogRepository(logger *zap.SugaredLogger, db *pgxpool.Pool) *WorkLogRepository {
+	return &WorkLogRepository{logger: logger, db: db}
+}
+
+// Insert tries to insert a work log and add its duration to the time spent on its ticket, within the same statement.
+// The returned work log holds the id, issuer and time of the inserted record.
+func (r *WorkLogRepository) Insert(ctx context.Context, log WorkLog) (*WorkLog, *errors.Type) {
+	q := `WITH ticket AS (
+				UPDATE tickets SET time_spent_minutes = time_spent_minutes + $2 WHERE id = $1 RETURNING id, issuer
+			)
+			INSERT INTO work_logs (ticket_id, issuer, agent, minutes, note, logged_at)
+			SELECT id, issuer, $3, $2, NULLIF($4, ''), NOW() FROM ticket RETURNING id, issuer, logged_at;`
+
+	e := r.db.QueryRow(ctx, q, log.TicketID, int(log.Duration/time.Minute), log.Agent, log.Note).Scan(&log.ID,
+		&log.Issuer, &log.LoggedAt)
+	if e != nil {
+		if e == pgx.ErrNoRows {
+			return nil, errors.NotFound("ticket.not_found", "")
+		}
+
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+
+	return &log, nil
+}
+
+// LoadByTicketID tries to load the work logs of a ticket, oldest first.
+func (r *WorkLogRepository) LoadByTicketID(ctx context.Context, ticketID int64) ([]*WorkLog, *errors.Type) {
+	q := `SELECT id, ticket_id, issuer, agent, minutes, note, logged_at FROM work_logs WHERE ticket_id = $1
+			ORDER BY logged_at, id;`
+
+	rows, e := r.db.Query(ctx, q, ticketID)
+	if e != nil {
+		et := errors.InternalServerError("unknown", "")
+		r.logger.Error(et.FingerPrint, ": ", e.Error())
+		return nil, et
+	}
+	defer rows.Close()
+
+	logs := make([]*WorkLog, 0)
+	for rows.Next() {
+		log := &WorkLog{}
+		var minutes int
+		var note sql.NullString
+
+		e := rows.Scan(&log.ID, &log.TicketID, &log.Issuer, &log.Agent, &minutes, &note, &log.LoggedAt)
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			r.logger.Error(et.FingerPrint, ": ", e.Error())
+			return nil, et
+		}
+
+		log.Duration = time.Duration(minutes) * time.Minute
+		log.Note = note.String
+		logs = append(logs, log)
+	}
+
+	return logs, nil
+}
@@ -0,0 +1,102 @@
+package models_test
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("WorkLog", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var repository *models.WorkLogRepository
+	var ticketRepository *models.TicketRepository
+	var reportRepository *models.ReportRepository
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+			repository = models.NewWorkLogRepository(zap.S(), db)
+			ticketRepository = models.NewTicketRepository(zap.S(), db)
+			reportRepository = models.NewReportRepository(zap.S(), db)
+		}
+	})
+
+	AfterEach(func() {
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	Describe("WorkLogRepository", func() {
+		Context("When Insert and LoadByTicketID called", func() {
+			It("Should record work logs and total them on the ticket", func() {
+				ticket := models.Ticket{Issuer: "Microservice-A", Owner: "user-1", Subject: "Subject",
+					Content: "Content", ImportanceLevel: models.TicketImportanceLevelHigh}
+				id, e := ticketRepository.Insert(context.Background(), ticket)
+				Ω(e).Should(BeNil())
+
+				log, e := repository.Insert(context.Background(), models.WorkLog{TicketID: id, Agent: "agent-1",
+					Duration: 30 * time.Minute, Note: "Reproduced the issue"})
+				Ω(e).Should(BeNil())
+				Ω(log.Issuer).Should(Equal("Microservice-A"))
+
+				_, e = repository.Insert(context.Background(), models.WorkLog{TicketID: id, Agent: "agent-2",
+					Duration: 45 * time.Minute})
+				Ω(e).Should(BeNil())
+
+				logs, e := repository.LoadByTicketID(context.Background(), id)
+				Ω(e).Should(BeNil())
+				Ω(logs).Should(HaveLen(2))
+				Ω(logs[0].Note).Should(Equal("Reproduced the issue"))
+				Ω(logs[1].Note).Should(BeEmpty())
+
+				loaded, e := ticketRepository.LoadByID(context.Background(), id)
+				Ω(e).Should(BeNil())
+				Ω(loaded.TimeSpent).Should(Equal(75 * time.Minute))
+
+				from := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339Nano)
+				to := time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano)
+
+				rows, e := reportRepository.TimeSpent(context.Background(), "", "", true, from, to)
+				Ω(e).Should(BeNil())
+				Ω(rows).Should(HaveLen(2))
+				Ω(rows[0].Agent).Should(Equal("agent-1"))
+				Ω(rows[0].TimeSpent).Should(Equal(30 * time.Minute))
+
+				rows, e = reportRepository.TimeSpent(context.Background(), "Microservice-A", "", false, from, to)
+				Ω(e).Should(BeNil())
+				Ω(rows).Should(HaveLen(1))
+				Ω(rows[0].Tickets).Should(Equal(int64(1)))
+				Ω(rows[0].Entries).Should(Equal(int64(2)))
+				Ω(rows[0].TimeSpent).Should(Equal(75 * time.Minute))
+			})
+
+			It("Should not log work on missing tickets", func() {
+				_, e := repository.Insert(context.Background(), models.WorkLog{TicketID: 1000, Agent: "agent-1",
+					Duration: time.Minute})
+				Ω(e).ShouldNot(BeNil())
+				Ω(e.HTTPStatusCode).Should(Equal(http.StatusNotFound))
+			})
+		})
+	})
+})
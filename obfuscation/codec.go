@@ -0,0 +1,194 @@
+// Package obfuscation turns sequential ids into short public ids and back, so ids exposed to customers, e.g. within
+// emails and URLs, do not tell how many records there are or let others be guessed. Public ids follow the hashids
+// scheme, so ids are reversible without any lookups, but they are not encrypted and must not be relied on for access
+// control.
+package obfuscation
+
+import (
+	"math"
+	"strings"
+)
+
+const (
+	alphabet          = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ1234567890"
+	separators        = "cfhistuCFHISTU"
+	separatorDivision = 3.5
+	guardDivision     = 12
+)
+
+// Codec encodes ids into public ids and decodes them back. Codecs with different salts produce different public ids
+// for the same id, public ids are padded to at least the minimum length.
+type Codec struct {
+	salt       []rune
+	minLength  int
+	alphabet   []rune
+	separators []rune
+	guards     []rune
+}
+
+// NewCodec returns back a newly created and ready to use Codec.
+func NewCodec(salt string, minLength int) *Codec {
+	c := &Codec{salt: []rune(salt), minLength: minLength}
+
+	for _, r := range alphabet {
+		if !strings.ContainsRune(separators, r) {
+			c.alphabet = append(c.alphabet, r)
+		}
+	}
+
+	c.separators = shuffle([]rune(separators), c.salt)
+	if float64(len(c.alphabet))/float64(len(c.separators)) > separatorDivision {
+		length := int(math.Ceil(float64(len(c.alphabet)) / separatorDivision))
+		if length > len(c.separators) {
+			diff := length - len(c.separators)
+			c.separators = append(c.separators, c.alphabet[:diff]...)
+			c.alphabet = c.alphabet[diff:]
+		} else {
+			c.separators = c.separators[:length]
+		}
+	}
+
+	c.alphabet = shuffle(c.alphabet, c.salt)
+
+	guards := int(math.Ceil(float64(len(c.alphabet)) / guardDivision))
+	c.guards = c.alphabet[:guards]
+	c.alphabet = c.alphabet[guards:]
+
+	return c
+}
+
+// Encode returns back the public id of the id, which must not be negative.
+func (c *Codec) Encode(id int64) string {
+	lottery := c.alphabet[id%100%int64(len(c.alphabet))]
+	alphabet := c.lotteryAlphabet(lottery)
+
+	encoded := append([]rune{lottery}, digits(id, alphabet)...)
+	if len(encoded) < c.minLength {
+		guard := c.guards[(int(id%100)+int(encoded[0]))%len(c.guards)]
+		encoded = append([]rune{guard}, encoded...)
+
+		if len(encoded) < c.minLength {
+			guard := c.guards[(int(id%100)+int(encoded[2]))%len(c.guards)]
+			encoded = append(encoded, guard)
+		}
+	}
+
+	half := len(alphabet) / 2
+	for len(encoded) < c.minLength {
+		alphabet = shuffle(alphabet, append([]rune(nil), alphabet...))
+
+		padded := make([]rune, 0, len(encoded)+len(alphabet))
+		padded = append(padded, alphabet[half:]...)
+		padded = append(padded, encoded...)
+		padded = append(padded, alphabet[:half]...)
+		encoded = padded
+
+		if excess := len(encoded) - c.minLength; excess > 0 {
+			encoded = encoded[excess/2 : excess/2+c.minLength]
+		}
+	}
+
+	return string(encoded)
+}
+
+// Decode returns back the id of the public id, false when it is not a public id this codec encoded.
+func (c *Codec) Decode(publicID string) (int64, bool) {
+	parts := strings.FieldsFunc(publicID, func(r rune) bool { return containsRune(c.guards, r) })
+
+	breakdown := ""
+	switch {
+	case len(parts) == 1:
+		breakdown = parts[0]
+	case len(parts) == 2 || len(parts) == 3:
+		breakdown = parts[1]
+	}
+
+	runes := []rune(breakdown)
+	if len(runes) < 2 {
+		return 0, false
+	}
+
+	for _, r := range runes[1:] {
+		if containsRune(c.separators, r) {
+			return 0, false
+		}
+	}
+
+	id, ok := number(runes[1:], c.lotteryAlphabet(runes[0]))
+	if !ok || c.Encode(id) != publicID {
+		return 0, false
+	}
+
+	return id, true
+}
+
+// lotteryAlphabet returns back the alphabet digits of ids are written with, which depends on the lottery rune.
+func (c *Codec) lotteryAlphabet(lottery rune) []rune {
+	buffer := make([]rune, 0, 1+len(c.salt)+len(c.alphabet))
+	buffer = append(buffer, lottery)
+	buffer = append(buffer, c.salt...)
+	buffer = append(buffer, c.alphabet...)
+
+	return shuffle(append([]rune(nil), c.alphabet...), buffer[:len(c.alphabet)])
+}
+
+// shuffle shuffles the alphabet in place, consistently for the same salt, and returns it back.
+func shuffle(alphabet, salt []rune) []rune {
+	if len(salt) == 0 {
+		return alphabet
+	}
+
+	for i, v, p := len(alphabet)-1, 0, 0; i > 0; i, v = i-1, v+1 {
+		v %= len(salt)
+		p += int(salt[v])
+		j := (int(salt[v]) + v + p) % i
+		alphabet[i], alphabet[j] = alphabet[j], alphabet[i]
+	}
+
+	return alphabet
+}
+
+// digits writes the id in the base of the alphabet length, with the runes of the alphabet as digits.
+func digits(id int64, alphabet []rune) []rune {
+	base := int64(len(alphabet))
+
+	var written []rune
+	for {
+		written = append([]rune{alphabet[id%base]}, written...)
+		id /= base
+		if id == 0 {
+			return written
+		}
+	}
+}
+
+// number reads back the number written by digits, false when the runes are not digits or the number overflows.
+func number(written, alphabet []rune) (int64, bool) {
+	base := int64(len(alphabet))
+
+	var n int64
+	for _, r := range written {
+		digit := int64(indexRune(alphabet, r))
+		if digit < 0 || n > (math.MaxInt64-digit)/base {
+			return 0, false
+		}
+
+		n = n*base + digit
+	}
+
+	return n, true
+}
+
+func containsRune(runes []rune, r rune) bool {
+	return indexRune(runes, r) >= 0
+}
+
+func indexRune(runes []rune, r rune) int {
+	for i, candidate := range runes {
+		if candidate == r {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,50 @@
+package obfuscation_test
+
+import (
+	"math"
+
+	"github.com/jibitters/kiosk/obfuscation"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Codec", func() {
+	Context("When Encode called", func() {
+		It("Should encode ids the way hashids does", func() {
+			Ω(obfuscation.NewCodec("this is my salt", 0).Encode(12345)).Should(Equal("NkK9"))
+			Ω(obfuscation.NewCodec("this is my salt", 0).Encode(1)).Should(Equal("NV"))
+			Ω(obfuscation.NewCodec("this is my salt", 8).Encode(1)).Should(Equal("gB0NV05e"))
+		})
+
+		It("Should encode ids differently per salt", func() {
+			Ω(obfuscation.NewCodec("issuer-a", 6).Encode(42)).
+				ShouldNot(Equal(obfuscation.NewCodec("issuer-b", 6).Encode(42)))
+		})
+	})
+
+	Context("When Decode called", func() {
+		It("Should decode public ids back to their ids", func() {
+			for _, codec := range []*obfuscation.Codec{obfuscation.NewCodec("", 0),
+				obfuscation.NewCodec("this is my salt", 8), obfuscation.NewCodec("salt", 20)} {
+
+				for _, id := range []int64{0, 1, 99, 100, 12345, 987654321, math.MaxInt64} {
+					decoded, ok := codec.Decode(codec.Encode(id))
+					Ω(ok).Should(BeTrue())
+					Ω(decoded).Should(Equal(id))
+				}
+			}
+		})
+
+		It("Should reject public ids it did not encode", func() {
+			codec := obfuscation.NewCodec("this is my salt", 8)
+
+			for _, publicID := range []string{"", "NkK9", "gB0NV05f", "gB0NV05e0", "zzzzzzzzzzzzzzzzzzzzzzzz"} {
+				_, ok := codec.Decode(publicID)
+				Ω(ok).Should(BeFalse())
+			}
+
+			_, ok := obfuscation.NewCodec("other salt", 8).Decode("gB0NV05e")
+			Ω(ok).Should(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,84 @@
+// Package pagination issues the tokens of next pages. Tokens are opaque to clients and hold the position of the last
+// record of a page, so the next page starts right after it no matter how many records got inserted or updated in
+// between, unlike pages skipping an offset number of records.
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/jibitters/kiosk/models"
+)
+
+// Tokens issues and verifies page tokens. Tokens are signed, so clients can not forge positions, and bound to the
+// list and orders they were issued for, as positions only make sense among records ordered the same way.
+type Tokens struct {
+	key []byte
+}
+
+// NewTokens returns back a newly created and ready to use Tokens. All instances must share the key for tokens issued
+// by one to be verified by the others, an empty key leaves tokens open to forgery.
+func NewTokens(key string) *Tokens {
+	return &Tokens{key: []byte(key)}
+}
+
+// payload is the content of tokens.
+type payload struct {
+	List   string         `json:"l"`
+	Orders []models.Order `json:"o,omitempty"`
+	Cursor models.Cursor  `json:"c"`
+}
+
+// Issue returns back the token of the page following the record at the cursor, among records of the list ordered by
+// orders.
+func (t *Tokens) Issue(list string, orders []models.Order, cursor models.Cursor) string {
+	content, _ := json.Marshal(&payload{List: list, Orders: orders, Cursor: cursor})
+
+	return base64.RawURLEncoding.EncodeToString(content) + "." +
+		base64.RawURLEncoding.EncodeToString(t.sign(content))
+}
+
+// Verify returns back the cursor held by the token and true if it was issued for the list and orders, otherwise false.
+func (t *Tokens) Verify(token, list string, orders []models.Order) (models.Cursor, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return nil, false
+	}
+
+	content, e := base64.RawURLEncoding.DecodeString(parts[0])
+	if e != nil {
+		return nil, false
+	}
+
+	signature, e := base64.RawURLEncoding.DecodeString(parts[1])
+	if e != nil || !hmac.Equal(signature, t.sign(content)) {
+		return nil, false
+	}
+
+	p := &payload{}
+	if e := json.Unmarshal(content, p); e != nil || p.List != list || p.Cursor == nil {
+		return nil, false
+	}
+
+	if len(p.Orders) != len(orders) {
+		return nil, false
+	}
+
+	for i := range orders {
+		if p.Orders[i] != orders[i] {
+			return nil, false
+		}
+	}
+
+	return p.Cursor, true
+}
+
+func (t *Tokens) sign(content []byte) []byte {
+	mac := hmac.New(sha256.New, t.key)
+	_, _ = mac.Write(content)
+
+	return mac.Sum(nil)
+}
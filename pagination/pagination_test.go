@@ -0,0 +1,62 @@
+package pagination_test
+
+import (
+	"strings"
+
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/pagination"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tokens", func() {
+	tokens := pagination.NewTokens("key")
+	modifiedAt, id := "2020-01-01T00:00:00.123456Z", "42"
+	cursor := models.Cursor{&modifiedAt, nil, &id}
+	orders := []models.Order{{Field: "modifiedAt", Descending: true}, {Field: "resolvedAt"}}
+
+	Context("When Verify called", func() {
+		It("Should return back the cursor of tokens issued for the same list and orders", func() {
+			token := tokens.Issue("tickets", orders, cursor)
+
+			verified, ok := tokens.Verify(token, "tickets", []models.Order{{Field: "modifiedAt", Descending: true},
+				{Field: "resolvedAt"}})
+			Ω(ok).Should(BeTrue())
+			Ω(verified).Should(Equal(cursor))
+
+			verified, ok = tokens.Verify(tokens.Issue("tickets", nil, cursor), "tickets", []models.Order{})
+			Ω(ok).Should(BeTrue())
+			Ω(verified).Should(Equal(cursor))
+		})
+
+		It("Should reject tokens issued for other lists or orders", func() {
+			token := tokens.Issue("tickets", orders, cursor)
+
+			_, ok := tokens.Verify(token, "comments", orders)
+			Ω(ok).Should(BeFalse())
+
+			_, ok = tokens.Verify(token, "tickets", orders[:1])
+			Ω(ok).Should(BeFalse())
+
+			_, ok = tokens.Verify(token, "tickets", []models.Order{{Field: "modifiedAt"}, {Field: "resolvedAt"}})
+			Ω(ok).Should(BeFalse())
+		})
+
+		It("Should reject forged and malformed tokens", func() {
+			token := tokens.Issue("tickets", orders, cursor)
+			parts := strings.Split(token, ".")
+
+			forged := pagination.NewTokens("other").Issue("tickets", orders, models.Cursor{nil, nil, &id})
+			_, ok := tokens.Verify(strings.Split(forged, ".")[0]+"."+parts[1], "tickets", orders)
+			Ω(ok).Should(BeFalse())
+
+			_, ok = tokens.Verify(forged, "tickets", orders)
+			Ω(ok).Should(BeFalse())
+
+			for _, malformed := range []string{"", ".", parts[0], token + ".", "!" + token, parts[0] + ".!"} {
+				_, ok = tokens.Verify(malformed, "tickets", orders)
+				Ω(ok).Should(BeFalse())
+			}
+		})
+	})
+})
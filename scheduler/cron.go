@@ -0,0 +1,125 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression with minute, hour, day of month, month and day of week fields.
+type Schedule struct {
+	minutes     uint64
+	hours       uint64
+	daysOfMonth uint64
+	months      uint64
+	daysOfWeek  uint64
+	anyDOM      bool
+	anyDOW      bool
+}
+
+// Parse parses a standard five fields cron expression. Each field accepts `*`, values, ranges, lists and steps, e.g.
+// `*/15 8-18 * * 1,3,5`.
+func Parse(spec string) (*Schedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression should have exactly 5 fields: %v", spec)
+	}
+
+	s := &Schedule{anyDOM: fields[2] == "*", anyDOW: fields[4] == "*"}
+
+	var e error
+	if s.minutes, e = parseField(fields[0], 0, 59); e != nil {
+		return nil, e
+	}
+
+	if s.hours, e = parseField(fields[1], 0, 23); e != nil {
+		return nil, e
+	}
+
+	if s.daysOfMonth, e = parseField(fields[2], 1, 31); e != nil {
+		return nil, e
+	}
+
+	if s.months, e = parseField(fields[3], 1, 12); e != nil {
+		return nil, e
+	}
+
+	if s.daysOfWeek, e = parseField(fields[4], 0, 7); e != nil {
+		return nil, e
+	}
+
+	// Both 0 and 7 stand for sunday.
+	if s.daysOfWeek&(1<<7) != 0 {
+		s.daysOfWeek |= 1
+	}
+
+	return s, nil
+}
+
+// Matches reports whether the provided time, truncated to minutes, is an activation time of the schedule.
+func (s *Schedule) Matches(t time.Time) bool {
+	if s.minutes&(1<<uint(t.Minute())) == 0 || s.hours&(1<<uint(t.Hour())) == 0 ||
+		s.months&(1<<uint(t.Month())) == 0 {
+
+		return false
+	}
+
+	dom := s.daysOfMonth&(1<<uint(t.Day())) != 0
+	dow := s.daysOfWeek&(1<<uint(t.Weekday())) != 0
+
+	// Like cron, when both day fields are restricted a match on either of them is enough.
+	if !s.anyDOM && !s.anyDOW {
+		return dom || dow
+	}
+
+	return dom && dow
+}
+
+func parseField(field string, min, max int) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var e error
+			if step, e = strconv.Atoi(part[i+1:]); e != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step in cron field: %v", field)
+			}
+			part = part[:i]
+		}
+
+		from, to := min, max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var e1, e2 error
+			from, e1 = strconv.Atoi(bounds[0])
+			to, e2 = strconv.Atoi(bounds[1])
+			if e1 != nil || e2 != nil {
+				return 0, fmt.Errorf("invalid range in cron field: %v", field)
+			}
+		default:
+			value, e := strconv.Atoi(part)
+			if e != nil {
+				return 0, fmt.Errorf("invalid value in cron field: %v", field)
+			}
+
+			from = value
+			if step == 1 {
+				to = value
+			}
+		}
+
+		if from < min || to > max || from > to {
+			return 0, fmt.Errorf("out of range value in cron field: %v", field)
+		}
+
+		for v := from; v <= to; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
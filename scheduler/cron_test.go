@@ -0,0 +1,56 @@
+package scheduler_test
+
+import (
+	"time"
+
+	"github.com/jibitters/kiosk/scheduler"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cron", func() {
+	Describe("Parse", func() {
+		It("Should reject malformed expressions", func() {
+			for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *",
+				"a * * * *"} {
+
+				_, e := scheduler.Parse(spec)
+				Ω(e).ShouldNot(BeNil(), spec)
+			}
+		})
+
+		It("Should match every minute with all wildcards", func() {
+			s, e := scheduler.Parse("* * * * *")
+			Ω(e).Should(BeNil())
+			Ω(s.Matches(time.Date(2020, 10, 1, 13, 37, 0, 0, time.UTC))).Should(BeTrue())
+		})
+
+		It("Should match steps, ranges and lists", func() {
+			s, e := scheduler.Parse("*/15 8-18 * * 1,3,5")
+			Ω(e).Should(BeNil())
+
+			// 2020-10-05 is a monday.
+			Ω(s.Matches(time.Date(2020, 10, 5, 8, 30, 0, 0, time.UTC))).Should(BeTrue())
+			Ω(s.Matches(time.Date(2020, 10, 5, 8, 31, 0, 0, time.UTC))).Should(BeFalse())
+			Ω(s.Matches(time.Date(2020, 10, 5, 19, 0, 0, 0, time.UTC))).Should(BeFalse())
+			Ω(s.Matches(time.Date(2020, 10, 6, 8, 30, 0, 0, time.UTC))).Should(BeFalse())
+		})
+
+		It("Should treat 7 as sunday", func() {
+			s, e := scheduler.Parse("0 0 * * 7")
+			Ω(e).Should(BeNil())
+
+			// 2020-10-04 is a sunday.
+			Ω(s.Matches(time.Date(2020, 10, 4, 0, 0, 0, 0, time.UTC))).Should(BeTrue())
+		})
+
+		It("Should match either day field when both are restricted", func() {
+			s, e := scheduler.Parse("0 0 1 * 1")
+			Ω(e).Should(BeNil())
+
+			Ω(s.Matches(time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC))).Should(BeTrue())
+			Ω(s.Matches(time.Date(2020, 10, 5, 0, 0, 0, 0, time.UTC))).Should(BeTrue())
+			Ω(s.Matches(time.Date(2020, 10, 6, 0, 0, 0, 0, time.UTC))).Should(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,112 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Job is a unit of scheduled work. It receives the activation time truncated to minutes.
+type Job func(ctx context.Context, now time.Time)
+
+type entry struct {
+	name     string
+	schedule *Schedule
+	job      Job
+	timeout  time.Duration
+}
+
+// Scheduler runs registered jobs whenever their cron schedules match the current minute.
+type Scheduler struct {
+	logger  *zap.SugaredLogger
+	mutex   sync.Mutex
+	entries []*entry
+	stop    chan struct{}
+	running sync.WaitGroup
+	when    func() bool
+}
+
+// NewScheduler returns back a newly created and ready to use Scheduler.
+func NewScheduler(logger *zap.SugaredLogger) *Scheduler {
+	return &Scheduler{logger: logger, stop: make(chan struct{})}
+}
+
+// Add registers a job with its cron expression. Each run is cancelled when it exceeds the provided timeout.
+func (s *Scheduler) Add(name, spec string, timeout time.Duration, job Job) error {
+	schedule, e := Parse(spec)
+	if e != nil {
+		return e
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.entries = append(s.entries, &entry{name: name, schedule: schedule, job: job, timeout: timeout})
+	s.logger.Info("Scheduled ", name, " job -> ", spec)
+	return nil
+}
+
+// OnlyWhen makes the scheduler skip the ticks at which condition does not hold, e.g. when this instance is not the
+// leader among all instances. Should be called before Start.
+func (s *Scheduler) OnlyWhen(condition func() bool) {
+	s.when = condition
+}
+
+// Start starts ticking at the beginning of every minute.
+func (s *Scheduler) Start() {
+	go s.loop()
+}
+
+func (s *Scheduler) loop() {
+	for {
+		now := time.Now()
+		next := now.Truncate(time.Minute).Add(time.Minute)
+
+		select {
+		case <-s.stop:
+			return
+		case <-time.After(next.Sub(now)):
+			s.tick(next)
+		}
+	}
+}
+
+func (s *Scheduler) tick(now time.Time) {
+	if s.when != nil && !s.when() {
+		s.logger.Debug("Scheduler: skipping the tick of ", now.Format("15:04"))
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, e := range s.entries {
+		if !e.schedule.Matches(now) {
+			continue
+		}
+
+		s.running.Add(1)
+		go func(e *entry) {
+			defer s.running.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					s.logger.Error("Scheduler: ", e.name, " job panicked: ", r)
+				}
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+			defer cancel()
+
+			s.logger.Debug("Scheduler: running ", e.name, " job")
+			e.job(ctx, now)
+		}(e)
+	}
+}
+
+// Stop stops the scheduler and waits for running jobs to finish.
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	s.running.Wait()
+}
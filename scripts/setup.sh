@@ -2,3 +2,4 @@
 
 go get -u golang.org/x/lint/golint
 go get -u github.com/onsi/ginkgo/ginkgo
+go get github.com/golang/mock/mockgen@v1.4.4
@@ -0,0 +1,302 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/models"
+	"go.uber.org/zap"
+)
+
+// Options holds the knobs of seed data generation. Two runs with the same options generate the same data set.
+type Options struct {
+	Seed              int64
+	Tickets           int
+	MaxComments       int
+	Issuers           int
+	Owners            int
+	FromDate          time.Time
+	ToDate            time.Time
+	ImportanceLevels  map[string]int
+	Statuses          map[string]int
+	InsertionPageSize int
+}
+
+// DefaultOptions returns back the default seeding options.
+func DefaultOptions() Options {
+	now := time.Now().UTC().Truncate(24 * time.Hour)
+
+	return Options{
+		Seed:        1,
+		Tickets:     1000,
+		MaxComments: 5,
+		Issuers:     5,
+		Owners:      100,
+		FromDate:    now.AddDate(0, -6, 0),
+		ToDate:      now,
+		ImportanceLevels: map[string]int{
+			string(models.TicketImportanceLevelLow):      40,
+			string(models.TicketImportanceLevelMedium):   35,
+			string(models.TicketImportanceLevelHigh):     20,
+			string(models.TicketImportanceLevelCritical): 5,
+		},
+		Statuses: map[string]int{
+			string(models.TicketStatusNew):      20,
+			string(models.TicketStatusReplied):  25,
+			string(models.TicketStatusResolved): 30,
+			string(models.TicketStatusClosed):   20,
+			string(models.TicketStatusBlocked):  5,
+		},
+		InsertionPageSize: 500,
+	}
+}
+
+// ParseWeights parses a distribution in form of `KEY=WEIGHT,KEY=WEIGHT` into a map. Keys are rejected unless valid,
+// e.g. IsImportanceLevel or IsStatus, so the seeder does not fail on the check constraints halfway through its inserts.
+func ParseWeights(value string, valid func(key string) bool) (map[string]int, error) {
+	weights := make(map[string]int)
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid weight definition: %v", pair)
+		}
+
+		key := strings.ToUpper(parts[0])
+		if !valid(key) {
+			return nil, fmt.Errorf("invalid weight key: %v", pair)
+		}
+
+		weight, e := strconv.Atoi(parts[1])
+		if e != nil || weight < 0 {
+			return nil, fmt.Errorf("invalid weight value: %v", pair)
+		}
+
+		weights[key] = weight
+	}
+
+	return weights, nil
+}
+
+// IsImportanceLevel reports whether the key is a ticket importance level.
+func IsImportanceLevel(key string) bool {
+	return models.TicketImportanceLevel(key).IsValid()
+}
+
+// IsStatus reports whether the key is a ticket status.
+func IsStatus(key string) bool {
+	return models.TicketStatus(key).IsValid()
+}
+
+// Seeder generates and inserts deterministic tickets and comments into database.
+type Seeder struct {
+	logger  *zap.SugaredLogger
+	db      *pgxpool.Pool
+	options Options
+	random  *rand.Rand
+}
+
+// NewSeeder returns back a newly created and ready to use Seeder.
+func NewSeeder(logger *zap.SugaredLogger, db *pgxpool.Pool, options Options) *Seeder {
+	return &Seeder{logger: logger, db: db, options: options, random: rand.New(rand.NewSource(options.Seed))}
+}
+
+// Run generates the configured volume of tickets with their comments and inserts them page by page.
+func (s *Seeder) Run(ctx context.Context) error {
+	if !s.options.ToDate.After(s.options.FromDate) {
+		return fmt.Errorf("to date should be after from date")
+	}
+
+	if s.options.Issuers < 1 || s.options.Owners < 1 || s.options.InsertionPageSize < 1 {
+		return fmt.Errorf("issuers, owners and insertion page size should be positive")
+	}
+
+	for level := range s.options.ImportanceLevels {
+		if !IsImportanceLevel(level) {
+			return fmt.Errorf("unknown importance level: %v", level)
+		}
+	}
+
+	for status := range s.options.Statuses {
+		if !IsStatus(status) {
+			return fmt.Errorf("unknown status: %v", status)
+		}
+	}
+
+	importanceLevels := newDistribution(s.options.ImportanceLevels)
+	statuses := newDistribution(s.options.Statuses)
+	if importanceLevels.total == 0 || statuses.total == 0 {
+		return fmt.Errorf("distributions should have at least one positive weight")
+	}
+
+	inserted := 0
+	for inserted < s.options.Tickets {
+		size := s.options.InsertionPageSize
+		if remaining := s.options.Tickets - inserted; remaining < size {
+			size = remaining
+		}
+
+		tickets := make([]*models.Ticket, 0, size)
+		for i := 0; i < size; i++ {
+			tickets = append(tickets, s.ticket(importanceLevels, statuses))
+		}
+
+		if e := s.insert(ctx, tickets); e != nil {
+			return e
+		}
+
+		inserted += size
+		s.logger.Info("Seeded ", inserted, " of ", s.options.Tickets, " tickets.")
+	}
+
+	return nil
+}
+
+func (s *Seeder) ticket(importanceLevels, statuses *distribution) *models.Ticket {
+	span := s.options.ToDate.Sub(s.options.FromDate)
+	createdAt := s.options.FromDate.Add(time.Duration(s.random.Int63n(int64(span))))
+
+	ticket := &models.Ticket{
+		Issuer:          fmt.Sprintf("Microservice-%d", s.random.Intn(s.options.Issuers)+1),
+		Owner:           fmt.Sprintf("user%d@example.com", s.random.Intn(s.options.Owners)+1),
+		Subject:         s.sentence(3, 8),
+		Content:         s.sentence(20, 120),
+		Metadata:        fmt.Sprintf(`{"ip":"192.168.%d.%d"}`, s.random.Intn(256), s.random.Intn(256)),
+		ImportanceLevel: models.TicketImportanceLevel(importanceLevels.pick(s.random)),
+		Status:          models.TicketStatus(statuses.pick(s.random)),
+	}
+	ticket.CreatedAt = createdAt
+	ticket.ModifiedAt = createdAt
+
+	comments := 0
+	if s.options.MaxComments > 0 {
+		comments = s.random.Intn(s.options.MaxComments + 1)
+	}
+
+	for i := 0; i < comments; i++ {
+		remaining := s.options.ToDate.Sub(ticket.ModifiedAt)
+		if remaining <= 0 {
+			break
+		}
+
+		commentedAt := ticket.ModifiedAt.Add(time.Duration(s.random.Int63n(int64(remaining))))
+		comment := &models.Comment{
+			Owner:   ticket.Owner,
+			Content: s.sentence(5, 60),
+		}
+		if i%2 == 1 {
+			comment.Owner = "support@example.com"
+		}
+		comment.CreatedAt = commentedAt
+		comment.ModifiedAt = commentedAt
+
+		ticket.Comments = append(ticket.Comments, comment)
+		ticket.ModifiedAt = commentedAt
+	}
+
+	return ticket
+}
+
+func (s *Seeder) insert(ctx context.Context, tickets []*models.Ticket) error {
+	q := `INSERT INTO tickets (issuer, owner, subject, content, metadata, importance_level, status, created_at,
+			modified_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id;`
+
+	tx, e := s.db.Begin(ctx)
+	if e != nil {
+		return e
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	batch := &pgx.Batch{}
+	for _, t := range tickets {
+		batch.Queue(q, t.Issuer, t.Owner, t.Subject, t.Content, t.Metadata, t.ImportanceLevel, t.Status, t.CreatedAt,
+			t.ModifiedAt)
+	}
+
+	results := tx.SendBatch(ctx, batch)
+	for _, t := range tickets {
+		if e := results.QueryRow().Scan(&t.ID); e != nil {
+			_ = results.Close()
+			return e
+		}
+	}
+
+	if e := results.Close(); e != nil {
+		return e
+	}
+
+	rows := make([][]interface{}, 0)
+	for _, t := range tickets {
+		for _, c := range t.Comments {
+			rows = append(rows, []interface{}{t.ID, c.Owner, c.Content, c.CreatedAt, c.ModifiedAt})
+		}
+	}
+
+	if len(rows) > 0 {
+		_, e = tx.CopyFrom(ctx, pgx.Identifier{"comments"},
+			[]string{"ticket_id", "owner", "content", "created_at", "modified_at"}, pgx.CopyFromRows(rows))
+		if e != nil {
+			return e
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *Seeder) sentence(min, max int) string {
+	count := min + s.random.Intn(max-min+1)
+	words := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		words = append(words, vocabulary[s.random.Intn(len(vocabulary))])
+	}
+
+	words[0] = strings.Title(words[0])
+	return strings.Join(words, " ") + "."
+}
+
+// distribution is a weighted set of values. Keys are sorted so picks are deterministic regardless of map ordering.
+type distribution struct {
+	keys    []string
+	weights []int
+	total   int
+}
+
+func newDistribution(weights map[string]int) *distribution {
+	d := &distribution{}
+	for k := range weights {
+		d.keys = append(d.keys, k)
+	}
+	sort.Strings(d.keys)
+
+	for _, k := range d.keys {
+		d.weights = append(d.weights, weights[k])
+		d.total += weights[k]
+	}
+
+	return d
+}
+
+func (d *distribution) pick(random *rand.Rand) string {
+	n := random.Intn(d.total)
+	for i, w := range d.weights {
+		if n < w {
+			return d.keys[i]
+		}
+		n -= w
+	}
+
+	return d.keys[len(d.keys)-1]
+}
+
+var vocabulary = []string{
+	"account", "api", "balance", "card", "charge", "confirm", "customer", "delay", "deposit", "docs", "error",
+	"failed", "gateway", "hello", "invoice", "issue", "limit", "login", "merchant", "missing", "mobile", "network",
+	"order", "password", "payment", "pending", "please", "problem", "refund", "request", "response", "settlement",
+	"since", "status", "support", "thanks", "timeout", "transfer", "urgent", "verify", "wallet", "webhook", "yesterday",
+}
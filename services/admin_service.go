@@ -0,0 +1,364 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/jobs"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/pagination"
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// recomputeSLAJob is the kind of jobs resolving the SLA deadlines of tickets again, with data.RecomputeSLARequest
+// payloads.
+const recomputeSLAJob = "tickets.recompute_sla"
+
+// AdminService is a service implementation of the operational actions runbooks call for, e.g. flushing caches,
+// rotating API keys, archiving tickets or recomputing SLA deadlines, so they are taken through the API rather than
+// the database. Every action is recorded in the audit log along with its operator, reason and outcome. Long running
+// actions are enqueued as jobs, their outcome is whether they got enqueued.
+type AdminService struct {
+	logger              *zap.SugaredLogger
+	adminRepository     *models.AdminRepository
+	tenantRepository    *models.TenantRepository
+	slaTargetRepository *models.SLATargetRepository
+	natsClient          *nc.Conn
+	pool                *jobs.Pool
+	pageTokens          *pagination.Tokens
+	auditSink           AuditSink
+	stop                chan struct{}
+}
+
+// NewAdminService returns a newly created and ready to use AdminService. Audit logs are appended to the audit sink as
+// well when one is given.
+func NewAdminService(logger *zap.SugaredLogger, db *pgxpool.Pool, natsClient *nc.Conn, pool *jobs.Pool,
+	pageTokens *pagination.Tokens, auditSink AuditSink) *AdminService {
+
+	return &AdminService{
+		logger:              logger,
+		adminRepository:     models.NewAdminRepository(logger, db),
+		tenantRepository:    models.NewTenantRepository(logger, db),
+		slaTargetRepository: models.NewSLATargetRepository(logger, db),
+		natsClient:          natsClient,
+		pool:                pool,
+		pageTokens:          pageTokens,
+		auditSink:           auditSink,
+		stop:                make(chan struct{}),
+	}
+}
+
+// Start starts the subscriptions so ready to be notified.
+func (s *AdminService) Start() error {
+	s.pool.Register(recomputeSLAJob, time.Hour, s.recomputeSLAJob)
+
+	flushCachesSubscription, e := s.natsClient.QueueSubscribe("kiosk.admin.caches.flush",
+		"kiosk.admin.caches.flush_group", s.flushCaches)
+	if e != nil {
+		return e
+	}
+
+	rotateAPIKeysSubscription, e := s.natsClient.QueueSubscribe("kiosk.admin.api_keys.rotate",
+		"kiosk.admin.api_keys.rotate_group", s.rotateAPIKeys)
+	if e != nil {
+		return e
+	}
+
+	archiveTicketsSubscription, e := s.natsClient.QueueSubscribe("kiosk.admin.tickets.archive",
+		"kiosk.admin.tickets.archive_group", s.archiveTickets)
+	if e != nil {
+		return e
+	}
+
+	recomputeSLASubscription, e := s.natsClient.QueueSubscribe("kiosk.admin.sla.recompute",
+		"kiosk.admin.sla.recompute_group", s.recomputeSLA)
+	if e != nil {
+		return e
+	}
+
+	auditLogsSubscription, e := s.natsClient.QueueSubscribe("kiosk.admin.audit_logs",
+		"kiosk.admin.audit_logs_group", s.auditLogs)
+	if e != nil {
+		return e
+	}
+
+	verifyAuditLogsSubscription, e := s.natsClient.QueueSubscribe("kiosk.admin.audit_logs.verify",
+		"kiosk.admin.audit_logs.verify_group", s.verifyAuditLogs)
+	if e != nil {
+		return e
+	}
+
+	go s.await(flushCachesSubscription, rotateAPIKeysSubscription, archiveTicketsSubscription,
+		recomputeSLASubscription, auditLogsSubscription, verifyAuditLogsSubscription)
+
+	return nil
+}
+
+func (s *AdminService) await(ss ...*nc.Subscription) {
+	<-s.stop
+	s.logger.Debug("AdminService: received stop signal!")
+
+	drain(s.logger, ss)
+	s.stop <- struct{}{}
+}
+
+// flushCaches drops the caches of all kiosk instances, i.e. the ticket counters and the runtime entries, which get
+// loaded again from the database.
+func (s *AdminService) flushCaches(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	flushCachesRequest := &data.FlushCachesRequest{}
+	if e := json.Unmarshal(msg.Data, flushCachesRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := flushCachesRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	e := s.adminRepository.Notify(ctx, []string{ticketChangesChannel, runtimeChangesChannel})
+	s.audit(ctx, models.AdminActionFlushCaches, &flushCachesRequest.AdminRequest, flushCachesRequest, e)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+// rotateAPIKeys replaces the API keys of a tenant with a new one, which is only ever sent back in the reply.
+func (s *AdminService) rotateAPIKeys(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rotateAPIKeysRequest := &data.RotateAPIKeysRequest{}
+	if e := json.Unmarshal(msg.Data, rotateAPIKeysRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := rotateAPIKeysRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	key, apiKey, err := models.GenerateTenantAPIKey(rotateAPIKeysRequest.Issuer)
+	if err != nil {
+		et := errors.InternalServerError("unknown", "")
+		s.logger.Error(et.FingerPrint, ": ", err.Error())
+		s.reply(msg, et)
+		return
+	}
+
+	revoked, e := s.tenantRepository.RotateAPIKey(ctx, *apiKey)
+	s.audit(ctx, models.AdminActionRotateAPIKeys, &rotateAPIKeysRequest.AdminRequest, rotateAPIKeysRequest, e)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.logger.Info("Rotated API keys of tenant ", apiKey.Issuer, " to ", apiKey.Prefix, "...")
+	s.reply(msg, &data.RotateAPIKeysResponse{Issuer: apiKey.Issuer, APIKey: key, RevokedKeys: revoked})
+}
+
+// archiveTickets enqueues the archiving of the closed tickets not modified for the requested duration, see
+// TicketService.ArchiveTickets. A single archiving is pending at a time among all instances.
+func (s *AdminService) archiveTickets(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	archiveTicketsRequest := &data.ArchiveTicketsRequest{}
+	if e := json.Unmarshal(msg.Data, archiveTicketsRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := archiveTicketsRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	olderThan, _ := time.ParseDuration(archiveTicketsRequest.OlderThan)
+	payload := &archiveJobPayload{Before: time.Now().Add(-olderThan)}
+
+	enqueued, e := s.pool.Enqueue(ctx, archiveTicketsJob, archiveTicketsJob, payload)
+	if e == nil && !enqueued {
+		e = errors.PreconditionFailed("archive.in_progress", "")
+	}
+
+	s.audit(ctx, models.AdminActionArchiveTickets, &archiveTicketsRequest.AdminRequest, archiveTicketsRequest, e)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+// recomputeSLA enqueues resolving the SLA deadlines of the tickets created within a date range again. A single
+// recomputation is pending at a time among all instances.
+func (s *AdminService) recomputeSLA(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	recomputeSLARequest := &data.RecomputeSLARequest{}
+	if e := json.Unmarshal(msg.Data, recomputeSLARequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := recomputeSLARequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	enqueued, e := s.pool.Enqueue(ctx, recomputeSLAJob, recomputeSLAJob, recomputeSLARequest)
+	if e == nil && !enqueued {
+		e = errors.PreconditionFailed("sla_recomputation.in_progress", "")
+	}
+
+	s.audit(ctx, models.AdminActionRecomputeSLA, &recomputeSLARequest.AdminRequest, recomputeSLARequest, e)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+func (s *AdminService) recomputeSLAJob(ctx context.Context, payload []byte) error {
+	request := &data.RecomputeSLARequest{}
+	if e := json.Unmarshal(payload, request); e != nil {
+		return e
+	}
+
+	var lastID, recomputed int64
+	for {
+		id, count, e := s.slaTargetRepository.RecomputeDeadlines(ctx, request.Issuer, request.FromDate,
+			request.ToDate, lastID, request.BatchSize)
+		if e != nil {
+			return e
+		}
+
+		lastID = id
+		recomputed += int64(count)
+		if count < request.BatchSize {
+			break
+		}
+	}
+
+	s.logger.Info("Recomputed SLA deadlines of ", recomputed, " tickets on behalf of ", request.Operator)
+	return nil
+}
+
+func (s *AdminService) auditLogs(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	adminAuditLogsRequest := &data.AdminAuditLogsRequest{}
+	if e := json.Unmarshal(msg.Data, adminAuditLogsRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := adminAuditLogsRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	after, e := pageCursor(s.pageTokens, adminAuditLogsRequest.PageToken, auditLogsPageList, nil)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	logs, hasMore, e := s.adminRepository.LoadAuditLogs(ctx, adminAuditLogsRequest.Action, after,
+		adminAuditLogsRequest.Limit)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	adminAuditLogsResponse := &data.AdminAuditLogsResponse{}
+	adminAuditLogsResponse.LoadFromAdminAuditLogs(logs)
+	if hasMore {
+		adminAuditLogsResponse.NextPageToken = s.pageTokens.Issue(auditLogsPageList, nil, logs[len(logs)-1].Cursor())
+	}
+
+	s.reply(msg, adminAuditLogsResponse)
+}
+
+// verifyAuditLogs verifies the chain of audit logs, so tampering with recorded ones gets detected.
+func (s *AdminService) verifyAuditLogs(msg *nc.Msg) {
+	// The whole chain gets read, which takes longer than loading a page of it.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	chain, e := s.adminRepository.VerifyAuditLogs(ctx)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if chain.BrokenAt != 0 {
+		s.logger.Error("Chain of admin audit logs is broken at ", chain.BrokenAt)
+	}
+
+	adminAuditChainResponse := &data.AdminAuditChainResponse{}
+	adminAuditChainResponse.LoadFromAdminAuditChain(chain)
+	s.reply(msg, adminAuditChainResponse)
+}
+
+// audit records an action taken on behalf of the request, with its parameters and the error it failed with, if any.
+// Actions are recorded once taken, so failing to record one can not undo it and it is logged instead.
+func (s *AdminService) audit(ctx context.Context, action models.AdminAction, request *data.AdminRequest,
+	parameters interface{}, e *errors.Type) {
+
+	p, _ := json.Marshal(parameters)
+	log := models.AdminAuditLog{Operator: request.Operator, Action: action, Reason: request.Reason,
+		Parameters: string(p), Succeeded: e == nil}
+	if e != nil {
+		log.Failure = e.Errors[0].Code
+	}
+
+	recorded := s.adminRepository.InsertAuditLog(ctx, &log) == nil
+	if !recorded {
+		log.CreatedAt = time.Now().UTC()
+		s.logger.Warn("Could not record admin action ", action, " of ", request.Operator, " (succeeded: ",
+			log.Succeeded, "): ", string(p))
+	}
+
+	if s.auditSink != nil {
+		if e := s.auditSink.Append(ctx, &log); e != nil {
+			s.logger.Error("Could not append admin action ", action, " of ", request.Operator, " to audit sink: ",
+				e.Error())
+		}
+	}
+
+	if recorded {
+		s.logger.Info("Admin action ", action, " taken by ", request.Operator, " (succeeded: ", log.Succeeded, ")")
+	}
+}
+
+func (s *AdminService) reply(msg *nc.Msg, t interface{}) {
+	reply, _ := json.Marshal(t)
+	_ = msg.Respond(reply)
+}
+
+func (s *AdminService) replyNoContent(msg *nc.Msg) {
+	_ = msg.Respond([]byte(""))
+}
+
+// Stop stops the component and drains its subscriptions, blocking until their in-flight messages are handled.
+func (s *AdminService) Stop() {
+	s.stop <- struct{}{}
+	<-s.stop
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// ApprovalService is a service implementation of approval functionalities. It manages the approvers of issuers and
+// the approvals requested to move their tickets to the statuses those approvers guard.
+type ApprovalService struct {
+	logger                   *zap.SugaredLogger
+	ticketRepository         TicketRepository
+	approverRepository       *models.ApproverRepository
+	ticketApprovalRepository *models.TicketApprovalRepository
+	requirementRepository    *models.TransitionRequirementRepository
+	consistencyRepository    *models.ConsistencyRepository
+	natsClient               *nc.Conn
+	stop                     chan struct{}
+}
+
+// NewApprovalService returns a newly created and ready to use ApprovalService.
+func NewApprovalService(logger *zap.SugaredLogger, db *pgxpool.Pool, natsClient *nc.Conn) *ApprovalService {
+	return &ApprovalService{
+		logger:                   logger,
+		ticketRepository:         models.NewTicketRepository(logger, db),
+		approverRepository:       models.NewApproverRepository(logger, db),
+		ticketApprovalRepository: models.NewTicketApprovalRepository(logger, db),
+		requirementRepository:    models.NewTransitionRequirementRepository(logger, db),
+		consistencyRepository:    models.NewConsistencyRepository(logger, db, nil),
+		natsClient:               natsClient,
+		stop:                     make(chan struct{}),
+	}
+}
+
+// Start starts the subscriptions so ready to be notified.
+func (s *ApprovalService) Start() error {
+	saveApproversSubscription, e := s.natsClient.QueueSubscribe("kiosk.issuers.approvers.save",
+		"kiosk.issuers.approvers.save_group", s.saveApprovers)
+	if e != nil {
+		return e
+	}
+
+	loadApproversSubscription, e := s.natsClient.QueueSubscribe("kiosk.issuers.approvers.load",
+		"kiosk.issuers.approvers.load_group", s.loadApprovers)
+	if e != nil {
+		return e
+	}
+
+	deleteApproversSubscription, e := s.natsClient.QueueSubscribe("kiosk.issuers.approvers.delete",
+		"kiosk.issuers.approvers.delete_group", s.deleteApprovers)
+	if e != nil {
+		return e
+	}
+
+	requestApprovalSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.approvals.request",
+		"kiosk.tickets.approvals.request_group", s.requestApproval)
+	if e != nil {
+		return e
+	}
+
+	approveSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.approvals.approve",
+		"kiosk.tickets.approvals.approve_group", s.approve)
+	if e != nil {
+		return e
+	}
+
+	rejectSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.approvals.reject",
+		"kiosk.tickets.approvals.reject_group", s.reject)
+	if e != nil {
+		return e
+	}
+
+	ticketApprovalsSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.approvals",
+		"kiosk.tickets.approvals_group", s.ticketApprovals)
+	if e != nil {
+		return e
+	}
+
+	go s.await(saveApproversSubscription, loadApproversSubscription, deleteApproversSubscription,
+		requestApprovalSubscription, approveSubscription, rejectSubscription, ticketApprovalsSubscription)
+
+	return nil
+}
+
+func (s *ApprovalService) await(ss ...*nc.Subscription) {
+	<-s.stop
+	s.logger.Debug("ApprovalService: received stop signal!")
+
+	drain(s.logger, ss)
+	s.stop <- struct{}{}
+}
+
+// saveApprovers creates or replaces the approvers of an issuer and status.
+func (s *ApprovalService) saveApprovers(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	saveApproversRequest := &data.SaveApproversRequest{}
+	if e := json.Unmarshal(msg.Data, saveApproversRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := saveApproversRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	e := s.approverRepository.Save(ctx, saveApproversRequest.Issuer, saveApproversRequest.Status,
+		saveApproversRequest.Approvers)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+func (s *ApprovalService) loadApprovers(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	issuerRequest := &data.IssuerRequest{}
+	if e := json.Unmarshal(msg.Data, issuerRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := issuerRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	approvers, e := s.approverRepository.LoadByIssuer(ctx, issuerRequest.Issuer)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	approversResponse := &data.ApproversResponse{}
+	approversResponse.LoadFromApprovers(approvers)
+	s.reply(msg, approversResponse)
+}
+
+func (s *ApprovalService) deleteApprovers(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	approversRequest := &data.ApproversRequest{}
+	if e := json.Unmarshal(msg.Data, approversRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := approversRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if e := s.approverRepository.Delete(ctx, approversRequest.Issuer, approversRequest.Status); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+// requestApproval requests an approval to move a ticket to a status guarded by approvers, who get notified. A ticket
+// has at most one pending approval at a time, and it must already have the fields required by the status.
+func (s *ApprovalService) requestApproval(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	requestApprovalRequest := &data.RequestApprovalRequest{}
+	if e := json.Unmarshal(msg.Data, requestApprovalRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := requestApprovalRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	e := checkTransitionRequirements(ctx, s.requirementRepository, requestApprovalRequest.TicketID,
+		requestApprovalRequest.Status, models.Resolution{})
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	approvers, e := s.approverRepository.LoadForTransition(ctx, requestApprovalRequest.TicketID,
+		requestApprovalRequest.Status)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if len(approvers) == 0 {
+		s.reply(msg, errors.PreconditionFailed("approval.not_required", ""))
+		return
+	}
+
+	approval, e := s.ticketApprovalRepository.Request(ctx, requestApprovalRequest.AsTicketApproval())
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	replyConsistencyToken(ctx, s.consistencyRepository, msg)
+
+	s.publishApprovalEvent(ctx, ticketApprovalRequestedSubject, data.EventTypeTicketApprovalRequested, approval,
+		approvers, approval.RequestedBy)
+}
+
+// approve approves the pending approval of a ticket, which moves the ticket to the requested status.
+func (s *ApprovalService) approve(msg *nc.Msg) {
+	s.decide(msg, models.ApprovalStateApproved, ticketApprovedSubject, data.EventTypeTicketApproved)
+}
+
+// reject rejects the pending approval of a ticket, which leaves the ticket as is.
+func (s *ApprovalService) reject(msg *nc.Msg) {
+	s.decide(msg, models.ApprovalStateRejected, ticketRejectedSubject, data.EventTypeTicketRejected)
+}
+
+// decide decides the pending approval of a ticket on behalf of one of the approvers of its transition.
+func (s *ApprovalService) decide(msg *nc.Msg, state models.ApprovalState, subject string, eventType data.EventType) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	decideApprovalRequest := &data.DecideApprovalRequest{}
+	if e := json.Unmarshal(msg.Data, decideApprovalRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := decideApprovalRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	approval, e := s.ticketApprovalRepository.Decide(ctx, decideApprovalRequest.TicketID,
+		decideApprovalRequest.Approver, state, decideApprovalRequest.Note)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	replyConsistencyToken(ctx, s.consistencyRepository, msg)
+
+	s.publishApprovalEvent(ctx, subject, eventType, approval, nil, approval.DecidedBy)
+}
+
+// ticketApprovals replies the approvals requested for a ticket along with their decisions.
+func (s *ApprovalService) ticketApprovals(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ticketApprovalsRequest := &data.TicketApprovalsRequest{}
+	if e := json.Unmarshal(msg.Data, ticketApprovalsRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := ticketApprovalsRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	approvals, e := s.ticketApprovalRepository.LoadByTicketID(ctx, ticketApprovalsRequest.TicketID)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	ticketApprovalsResponse := &data.TicketApprovalsResponse{}
+	ticketApprovalsResponse.LoadFromTicketApprovals(approvals)
+	s.reply(msg, ticketApprovalsResponse)
+}
+
+// publishApprovalEvent publishes an event about the approval of a ticket, with the current state of the ticket. The
+// previous status is set for approved transitions, so the status change gets published by TicketService.
+func (s *ApprovalService) publishApprovalEvent(ctx context.Context, subject string, eventType data.EventType,
+	approval *models.TicketApproval, approvers []string, actor string) {
+
+	ticket, e := s.ticketRepository.LoadByID(ctx, approval.TicketID)
+	if e != nil {
+		return
+	}
+
+	ticketResponse := &data.TicketResponse{}
+	ticketResponse.LoadFromTicket(ticket)
+
+	approvalResponse := &data.TicketApprovalResponse{}
+	approvalResponse.LoadFromTicketApproval(approval)
+	approvalResponse.Approvers = approvers
+
+	publishEvent(s.logger, s.natsClient, subject, &data.Event{Type: eventType, Ticket: ticketResponse,
+		PreviousStatus: approval.PreviousStatus, Actor: actor, Approval: approvalResponse})
+}
+
+func (s *ApprovalService) reply(msg *nc.Msg, t interface{}) {
+	reply, _ := json.Marshal(t)
+	_ = msg.Respond(reply)
+}
+
+func (s *ApprovalService) replyNoContent(msg *nc.Msg) {
+	_ = msg.Respond([]byte(""))
+}
+
+// Stop stops the component and drains its subscriptions, blocking until their in-flight messages are handled.
+func (s *ApprovalService) Stop() {
+	s.stop <- struct{}{}
+	<-s.stop
+}
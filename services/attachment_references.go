@@ -0,0 +1,47 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// checkAttachmentReferences returns back an error describing every attachment a comment of the ticket can not refer
+// to, one error per attachment, or nil when it can refer to all of them. The attachments are the loaded ones of the
+// ids, those of other tenants are missing, so they are reported as not existing. Only completed attachments can be
+// referred to, their contents are checked against the attachment policy while being uploaded.
+func checkAttachmentReferences(ticketID int64, ids []int64, attachments []*models.Attachment) *errors.Type {
+	loaded := make(map[int64]*models.Attachment, len(attachments))
+	for _, attachment := range attachments {
+		loaded[attachment.ID] = attachment
+	}
+
+	var et *errors.Type
+	for _, id := range ids {
+		var code, message string
+
+		attachment, ok := loaded[id]
+		switch {
+		case !ok:
+			code, message = "attachment.not_exists", fmt.Sprintf("Attachment %v does not exist.", id)
+		case attachment.TicketID != ticketID:
+			code, message = "attachment.not_of_ticket", fmt.Sprintf("Attachment %v belongs to another ticket.", id)
+		case attachment.CompletedAt == nil:
+			code, message = "attachment.not_completed", fmt.Sprintf("Attachment %v is still being uploaded.", id)
+		case attachment.CommentID != 0:
+			code, message = "attachment.of_another_comment",
+				fmt.Sprintf("Attachment %v is attached to another comment.", id)
+		default:
+			continue
+		}
+
+		if et == nil {
+			et = errors.PreconditionFailed(code, message)
+		} else {
+			et.Errors = append(et.Errors, errors.Error{Code: code, Message: message})
+		}
+	}
+
+	return et
+}
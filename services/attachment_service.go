@@ -0,0 +1,513 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/blobs"
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/pagination"
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// incompleteUploadTTL is how long uploads of attachments may stay incomplete before they get deleted.
+const incompleteUploadTTL = 24 * time.Hour
+
+// AttachmentPolicy restricts the files attached to tickets and comments to MaxSize bytes and ContentTypes media types,
+// e.g. image/png. Both the declared media type and the one sniffed off the content must be allowed.
+type AttachmentPolicy struct {
+	MaxSize      int64
+	ContentTypes []string
+}
+
+// allows reports whether the media type of the content type is one of the allowed ones.
+func (p AttachmentPolicy) allows(contentType string) bool {
+	mediaType, _, e := mime.ParseMediaType(contentType)
+	if e != nil {
+		return false
+	}
+
+	for _, allowed := range p.ContentTypes {
+		if mediaType == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AttachmentService is a service implementation of attachments related functionalities, i.e. files attached to
+// tickets and comments. Their contents are uploaded and downloaded in chunks, so neither side holds whole files in a
+// single message. Contents are moved to the blob store once uploaded, if one is configured, and are stored by their
+// hashes, so identical files are stored once.
+type AttachmentService struct {
+	logger               *zap.SugaredLogger
+	attachmentRepository *models.AttachmentRepository
+	natsClient           *nc.Conn
+	store                blobs.Store
+	policy               AttachmentPolicy
+	pageTokens           *pagination.Tokens
+	stop                 chan struct{}
+}
+
+// NewAttachmentService returns a newly created and ready to use AttachmentService. Contents stay in the database when
+// store is nil.
+func NewAttachmentService(logger *zap.SugaredLogger, db *pgxpool.Pool, natsClient *nc.Conn, store blobs.Store,
+	policy AttachmentPolicy, pageTokens *pagination.Tokens) *AttachmentService {
+
+	return &AttachmentService{
+		logger:               logger,
+		attachmentRepository: models.NewAttachmentRepository(logger, db),
+		natsClient:           natsClient,
+		store:                store,
+		policy:               policy,
+		pageTokens:           pageTokens,
+		stop:                 make(chan struct{}),
+	}
+}
+
+// Start starts the subscriptions so ready to be notified.
+func (s *AttachmentService) Start() error {
+	createAttachmentSubscription, e := s.natsClient.QueueSubscribe("kiosk.attachments.create",
+		"kiosk.attachments.create_group", s.create)
+	if e != nil {
+		return e
+	}
+
+	uploadAttachmentSubscription, e := s.natsClient.QueueSubscribe("kiosk.attachments.upload",
+		"kiosk.attachments.upload_group", s.upload)
+	if e != nil {
+		return e
+	}
+
+	loadAttachmentSubscription, e := s.natsClient.QueueSubscribe("kiosk.attachments.load",
+		"kiosk.attachments.load_group", s.load)
+	if e != nil {
+		return e
+	}
+
+	listAttachmentsSubscription, e := s.natsClient.QueueSubscribe("kiosk.attachments.list",
+		"kiosk.attachments.list_group", s.list)
+	if e != nil {
+		return e
+	}
+
+	downloadAttachmentSubscription, e := s.natsClient.QueueSubscribe("kiosk.attachments.download",
+		"kiosk.attachments.download_group", s.download)
+	if e != nil {
+		return e
+	}
+
+	deleteAttachmentSubscription, e := s.natsClient.QueueSubscribe("kiosk.attachments.delete",
+		"kiosk.attachments.delete_group", s.delete)
+	if e != nil {
+		return e
+	}
+
+	go s.await(createAttachmentSubscription, uploadAttachmentSubscription, loadAttachmentSubscription,
+		listAttachmentsSubscription, downloadAttachmentSubscription, deleteAttachmentSubscription)
+
+	return nil
+}
+
+func (s *AttachmentService) await(ss ...*nc.Subscription) {
+	<-s.stop
+	s.logger.Debug("AttachmentService: received stop signal!")
+
+	drain(s.logger, ss)
+	s.stop <- struct{}{}
+}
+
+func (s *AttachmentService) create(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createAttachmentRequest := &data.CreateAttachmentRequest{}
+	if e := json.Unmarshal(msg.Data, createAttachmentRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := createAttachmentRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if !s.policy.allows(createAttachmentRequest.ContentType) {
+		s.reply(msg, errors.InvalidArgument("contentType.not_allowed", ""))
+		return
+	}
+
+	id, e := s.attachmentRepository.Insert(ctx, *createAttachmentRequest.AsAttachment())
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	attachment, e := s.attachmentRepository.LoadByID(ctx, id)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	attachmentResponse := &data.AttachmentResponse{}
+	attachmentResponse.LoadFromAttachment(attachment)
+	s.reply(msg, attachmentResponse)
+}
+
+// upload appends a chunk to the content of an attachment and completes the upload on the last chunk, moving the
+// content to the blob store if one is configured and the same content is not stored already. Uploads violating the
+// policy are given up, deleting what is uploaded so far.
+func (s *AttachmentService) upload(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	uploadAttachmentChunkRequest := &data.UploadAttachmentChunkRequest{}
+	if e := json.Unmarshal(msg.Data, uploadAttachmentChunkRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := uploadAttachmentChunkRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	id, offset, content := uploadAttachmentChunkRequest.ID, uploadAttachmentChunkRequest.Offset,
+		uploadAttachmentChunkRequest.Content
+	if offset == 0 && len(content) > 0 && !s.policy.allows(http.DetectContentType(content)) {
+		s.giveUp(ctx, id)
+		s.reply(msg, errors.InvalidArgument("content.not_allowed", ""))
+		return
+	}
+
+	attachment, e := s.attachmentRepository.Append(ctx, id, offset, content, s.policy.MaxSize)
+	if e != nil {
+		if e.Errors[0].Code == "attachment.too_large" {
+			s.giveUp(ctx, id)
+		}
+
+		s.reply(msg, e)
+		return
+	}
+
+	if !uploadAttachmentChunkRequest.Last {
+		s.replyNoContent(msg)
+		return
+	}
+
+	// Moving the content to the blob store takes longer than appending a chunk.
+	ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	hash, e := s.hash(ctx, attachment)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	storage := models.AttachmentStorageDatabase
+	if s.store != nil && attachment.Size > 0 {
+		storage = models.AttachmentStorageS3
+	}
+
+	storage, fresh, e := s.attachmentRepository.Reference(ctx, id, hash, storage)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	attachment.ContentHash = hash
+	if fresh && storage != models.AttachmentStorageDatabase {
+		if e := s.moveToStore(ctx, attachment); e != nil {
+			s.reply(msg, e)
+			return
+		}
+	}
+
+	if e := s.attachmentRepository.Complete(ctx, id); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if attachment, e = s.attachmentRepository.LoadByID(ctx, id); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	attachmentResponse := &data.AttachmentResponse{}
+	attachmentResponse.LoadFromAttachment(attachment)
+	s.reply(msg, attachmentResponse)
+}
+
+// hash returns back the SHA-256 hash of the uploaded content of the attachment, reading it a chunk at a time.
+func (s *AttachmentService) hash(ctx context.Context, attachment *models.Attachment) (string, *errors.Type) {
+	hash := sha256.New()
+	for offset := int64(0); offset < attachment.Size; offset += data.AttachmentChunkSize {
+		chunk, e := s.attachmentRepository.Read(ctx, attachment.ID, offset, data.AttachmentChunkSize)
+		if e != nil {
+			return "", e
+		}
+
+		_, _ = hash.Write(chunk)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// moveToStore streams the uploaded content of the attachment from the database to the blob store, a chunk at a time.
+func (s *AttachmentService) moveToStore(ctx context.Context, attachment *models.Attachment) *errors.Type {
+	if s.store == nil {
+		et := errors.InternalServerError("unknown", "")
+		s.logger.Error(et.FingerPrint, ": attachment ", attachment.ID, " is stored in ", attachment.Storage,
+			" which is not configured")
+		return et
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		for offset := int64(0); offset < attachment.Size; offset += data.AttachmentChunkSize {
+			chunk, e := s.attachmentRepository.Read(ctx, attachment.ID, offset, data.AttachmentChunkSize)
+			if e != nil {
+				_ = writer.CloseWithError(e)
+				return
+			}
+
+			if _, e := io.Copy(writer, bytes.NewReader(chunk)); e != nil {
+				return
+			}
+		}
+
+		_ = writer.Close()
+	}()
+
+	if e := s.store.Put(ctx, attachment.Key(), reader, attachment.Size, attachment.ContentType); e != nil {
+		_ = reader.CloseWithError(e)
+
+		et := errors.InternalServerError("unknown", "")
+		s.logger.Error(et.FingerPrint, ": could not store attachment ", attachment.ID, ": ", e.Error())
+		return et
+	}
+
+	return nil
+}
+
+// giveUp deletes an attachment whose upload can not be completed.
+func (s *AttachmentService) giveUp(ctx context.Context, id int64) {
+	if _, e := s.attachmentRepository.DeleteByID(ctx, id); e != nil {
+		s.logger.Warn("Could not delete the given up upload of attachment ", id)
+	}
+}
+
+func (s *AttachmentService) load(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	id := &data.ID{}
+	if e := json.Unmarshal(msg.Data, id); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	attachment, e := s.attachmentRepository.LoadByID(ctx, id.ID)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	attachmentResponse := &data.AttachmentResponse{}
+	attachmentResponse.LoadFromAttachment(attachment)
+	s.reply(msg, attachmentResponse)
+}
+
+func (s *AttachmentService) list(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	listAttachmentsRequest := &data.ListAttachmentsRequest{}
+	if e := json.Unmarshal(msg.Data, listAttachmentsRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := listAttachmentsRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	after, e := pageCursor(s.pageTokens, listAttachmentsRequest.PageToken, attachmentsPageList, nil)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	attachments, hasMore, e := s.attachmentRepository.LoadByTicketID(ctx, listAttachmentsRequest.TicketID, after,
+		listAttachmentsRequest.PageSize)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	attachmentsResponse := &data.AttachmentsResponse{}
+	attachmentsResponse.LoadFromAttachments(attachments)
+	if hasMore {
+		attachmentsResponse.NextPageToken = s.pageTokens.Issue(attachmentsPageList, nil,
+			attachments[len(attachments)-1].Cursor())
+	}
+
+	s.reply(msg, attachmentsResponse)
+}
+
+// download returns back a chunk of the content of a completed attachment, starting from the requested offset.
+func (s *AttachmentService) download(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	downloadAttachmentChunkRequest := &data.DownloadAttachmentChunkRequest{}
+	if e := json.Unmarshal(msg.Data, downloadAttachmentChunkRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	attachment, e := s.attachmentRepository.LoadByID(ctx, downloadAttachmentChunkRequest.ID)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if attachment.CompletedAt == nil {
+		s.reply(msg, errors.PreconditionFailed("attachment.not_completed", ""))
+		return
+	}
+
+	offset := downloadAttachmentChunkRequest.Offset
+	if offset < 0 || offset >= attachment.Size {
+		s.reply(msg, &data.DownloadAttachmentChunkResponse{Content: []byte{}})
+		return
+	}
+
+	length := attachment.Size - offset
+	if length > data.AttachmentChunkSize {
+		length = data.AttachmentChunkSize
+	}
+
+	var content []byte
+	if attachment.Storage == models.AttachmentStorageDatabase && attachment.ContentHash != "" {
+		if content, e = s.attachmentRepository.ReadBlob(ctx, attachment.ContentHash, offset, length); e != nil {
+			s.reply(msg, e)
+			return
+		}
+	} else if attachment.Storage == models.AttachmentStorageDatabase {
+		if content, e = s.attachmentRepository.Read(ctx, attachment.ID, offset, length); e != nil {
+			s.reply(msg, e)
+			return
+		}
+	} else {
+		if s.store == nil {
+			et := errors.InternalServerError("unknown", "")
+			s.logger.Error(et.FingerPrint, ": attachment ", attachment.ID, " is stored in ", attachment.Storage,
+				" which is not configured")
+			s.reply(msg, et)
+			return
+		}
+
+		var err error
+		if content, err = s.store.Get(ctx, attachment.Key(), offset, length); err != nil {
+			et := errors.InternalServerError("unknown", "")
+			s.logger.Error(et.FingerPrint, ": could not load attachment ", attachment.ID, ": ", err.Error())
+			s.reply(msg, et)
+			return
+		}
+	}
+
+	s.reply(msg, &data.DownloadAttachmentChunkResponse{Content: content})
+}
+
+// delete deletes an attachment along with its content, unless other attachments refer to the same content. Contents
+// stored by their hashes are deleted from the blob store by DeleteUnreferencedContents.
+func (s *AttachmentService) delete(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	id := &data.ID{}
+	if e := json.Unmarshal(msg.Data, id); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	attachment, e := s.attachmentRepository.DeleteByID(ctx, id.ID)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if attachment != nil && attachment.ContentHash == "" && attachment.Storage != models.AttachmentStorageDatabase &&
+		s.store != nil {
+
+		if e := s.store.Delete(ctx, attachment.Key()); e != nil {
+			s.logger.Warn("Could not delete the content of attachment ", attachment.ID, ": ", e.Error())
+		}
+	}
+
+	s.replyNoContent(msg)
+}
+
+// DeleteIncompleteUploads is a scheduler job that deletes the attachments whose uploads got abandoned.
+func (s *AttachmentService) DeleteIncompleteUploads(ctx context.Context, now time.Time) {
+	deleted, e := s.attachmentRepository.DeleteIncomplete(ctx, now.Add(-incompleteUploadTTL))
+	if e != nil {
+		return
+	}
+
+	if deleted > 0 {
+		s.logger.Info("Deleted ", deleted, " incomplete uploads of attachments")
+	}
+}
+
+// DeleteUnreferencedContents is a scheduler job that deletes the contents no attachment refers to anymore from the
+// blob store.
+func (s *AttachmentService) DeleteUnreferencedContents(ctx context.Context, now time.Time) {
+	if s.store == nil {
+		return
+	}
+
+	deleted := 0
+	for {
+		released, e := s.attachmentRepository.ReleaseBlob(ctx, s.store.Delete)
+		if e != nil || !released {
+			break
+		}
+
+		deleted++
+	}
+
+	if deleted > 0 {
+		s.logger.Info("Deleted ", deleted, " unreferenced contents of attachments")
+	}
+}
+
+func (s *AttachmentService) reply(msg *nc.Msg, t interface{}) {
+	reply, _ := json.Marshal(t)
+	_ = msg.Respond(reply)
+}
+
+func (s *AttachmentService) replyNoContent(msg *nc.Msg) {
+	_ = msg.Respond([]byte(""))
+}
+
+// Stop stops the component and drains its subscriptions, blocking until their in-flight messages are handled.
+func (s *AttachmentService) Stop() {
+	s.stop <- struct{}{}
+	<-s.stop
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jibitters/kiosk/blobs"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/web/data"
+)
+
+// AuditSink appends the audit logs of admin actions, as chained by admin_audit_logs table, to a target kiosk can only
+// append to. The table is owned by the role kiosk connects with, which can get around its append only triggers, so the
+// sink keeps the original records elsewhere to verify the chain of the table against.
+type AuditSink interface {
+	// Append appends the audit log, its id and hashes are empty when it could not be recorded in the table.
+	Append(ctx context.Context, log *models.AdminAuditLog) error
+}
+
+// ObjectAuditSink appends each audit log as an object of its own to an object storage, keyed by its creation time and
+// id, so objects sort in the order they got recorded. The credentials of the storage are meant to only be allowed to
+// put objects, into a bucket with object lock in compliance mode, so kiosk can neither delete nor overwrite them.
+type ObjectAuditSink struct {
+	store  blobs.Store
+	prefix string
+}
+
+// NewObjectAuditSink returns back a newly created and ready to use ObjectAuditSink, keying objects under the prefix.
+func NewObjectAuditSink(store blobs.Store, prefix string) *ObjectAuditSink {
+	return &ObjectAuditSink{store: store, prefix: prefix}
+}
+
+// Append puts the audit log as a JSON object.
+func (s *ObjectAuditSink) Append(ctx context.Context, log *models.AdminAuditLog) error {
+	record := &data.AdminAuditLogResponse{}
+	record.LoadFromAdminAuditLog(log)
+	content, _ := json.Marshal(record)
+
+	key := fmt.Sprintf("%v%v-%020d.json", s.prefix, log.CreatedAt.UTC().Format("20060102T150405.000000Z"), log.ID)
+	return s.store.Put(ctx, key, bytes.NewReader(content), int64(len(content)), "application/json")
+}
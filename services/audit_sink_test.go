@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/jibitters/kiosk/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type memoryStore struct {
+	objects      map[string][]byte
+	contentTypes map[string]string
+}
+
+func (s *memoryStore) Put(_ context.Context, key string, content io.Reader, _ int64, contentType string) error {
+	b, e := ioutil.ReadAll(content)
+	if e != nil {
+		return e
+	}
+
+	s.objects[key] = b
+	s.contentTypes[key] = contentType
+	return nil
+}
+
+func (s *memoryStore) Get(_ context.Context, key string, offset, length int64) ([]byte, error) {
+	return s.objects[key][offset : offset+length], nil
+}
+
+func (s *memoryStore) Delete(_ context.Context, key string) error {
+	delete(s.objects, key)
+	return nil
+}
+
+var _ = Describe("ObjectAuditSink", func() {
+	Context("When appending audit logs", func() {
+		It("Should put one object per audit log holding its hashes, keyed in the order they got recorded", func() {
+			store := &memoryStore{objects: map[string][]byte{}, contentTypes: map[string]string{}}
+			sink := NewObjectAuditSink(store, "admin_audit_logs/")
+
+			createdAt := time.Date(2020, 1, 2, 3, 4, 5, 6000, time.UTC)
+			logs := []*models.AdminAuditLog{
+				{ID: 9, Operator: "ops@example.com", Action: models.AdminActionFlushCaches, Reason: "Stale",
+					Parameters: "{}", Succeeded: true, CreatedAt: createdAt, PreviousHash: "a", Hash: "b"},
+				{ID: 10, Operator: "ops@example.com", Action: models.AdminActionFlushCaches, Reason: "Stale",
+					Parameters: "{}", Succeeded: true, CreatedAt: createdAt, PreviousHash: "b", Hash: "c"},
+			}
+			for _, log := range logs {
+				Ω(sink.Append(context.Background(), log)).Should(BeNil())
+			}
+
+			first := "admin_audit_logs/20200102T030405.000006Z-00000000000000000009.json"
+			second := "admin_audit_logs/20200102T030405.000006Z-00000000000000000010.json"
+			Ω(store.objects).Should(HaveLen(2))
+			Ω(store.objects).Should(HaveKey(first))
+			Ω(store.objects).Should(HaveKey(second))
+			Ω(first < second).Should(BeTrue())
+			Ω(store.contentTypes[second]).Should(Equal("application/json"))
+
+			record := map[string]interface{}{}
+			Ω(json.Unmarshal(store.objects[second], &record)).Should(BeNil())
+			Ω(record["operator"]).Should(Equal("ops@example.com"))
+			Ω(record["previousHash"]).Should(Equal("b"))
+			Ω(record["hash"]).Should(Equal("c"))
+		})
+	})
+})
@@ -3,31 +3,50 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/diffing"
 	"github.com/jibitters/kiosk/errors"
 	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/pagination"
 	"github.com/jibitters/kiosk/web/data"
 	nc "github.com/nats-io/nats.go"
 	"go.uber.org/zap"
 )
 
-// CommentService is a service implementation of comment related functionalities.
+// CommentService is a service implementation of comment related functionalities. It also records the lifecycle
+// events of tickets as system comments in their threads.
 type CommentService struct {
-	logger            *zap.SugaredLogger
-	commentRepository *models.CommentRepository
-	natsClient        *nc.Conn
-	stop              chan struct{}
+	logger                   *zap.SugaredLogger
+	commentRepository        CommentRepository
+	revisionRepository       *models.CommentRevisionRepository
+	consistencyRepository    *models.ConsistencyRepository
+	issuerSettingsRepository *models.IssuerSettingsRepository
+	attachmentRepository     *models.AttachmentRepository
+	natsClient               *nc.Conn
+	pageTokens               *pagination.Tokens
+	identities               *Identities
+	stop                     chan struct{}
 }
 
-// NewCommentService returns a newly created and ready to use CommentService.
-func NewCommentService(logger *zap.SugaredLogger, db *pgxpool.Pool, natsClient *nc.Conn) *CommentService {
+// NewCommentService returns a newly created and ready to use CommentService. The owners of the comments it replies are
+// enriched by the identities, if any.
+func NewCommentService(logger *zap.SugaredLogger, db *pgxpool.Pool, natsClient *nc.Conn,
+	pageTokens *pagination.Tokens, identities *Identities) *CommentService {
+
 	return &CommentService{
-		logger:            logger,
-		commentRepository: models.NewCommentRepository(logger, db),
-		natsClient:        natsClient,
-		stop:              make(chan struct{}),
+		logger:                   logger,
+		commentRepository:        models.NewCommentRepository(logger, db),
+		revisionRepository:       models.NewCommentRevisionRepository(logger, db),
+		consistencyRepository:    models.NewConsistencyRepository(logger, db, nil),
+		issuerSettingsRepository: models.NewIssuerSettingsRepository(logger, db),
+		attachmentRepository:     models.NewAttachmentRepository(logger, db),
+		natsClient:               natsClient,
+		pageTokens:               pageTokens,
+		identities:               identities,
+		stop:                     make(chan struct{}),
 	}
 }
 
@@ -57,7 +76,77 @@ func (s *CommentService) Start() error {
 		return e
 	}
 
-	go s.await(createCommentSubscription, loadCommentSubscription, updateCommentSubscription, deleteCommentSubscription)
+	filterCommentsSubscription, e := s.natsClient.QueueSubscribe("kiosk.comments.filter",
+		"kiosk.comments.filter_group", s.filter)
+	if e != nil {
+		return e
+	}
+
+	diffRevisionsSubscription, e := s.natsClient.QueueSubscribe("kiosk.comments.revisions.diff",
+		"kiosk.comments.revisions.diff_group", s.diffRevisions)
+	if e != nil {
+		return e
+	}
+
+	ticketUpdatedSubscription, e := s.natsClient.QueueSubscribe(ticketUpdatedSubject, "kiosk.system_comments_group",
+		s.onTicketEvent)
+	if e != nil {
+		return e
+	}
+
+	ticketSnoozedSubscription, e := s.natsClient.QueueSubscribe(ticketSnoozedSubject, "kiosk.system_comments_group",
+		s.onTicketEvent)
+	if e != nil {
+		return e
+	}
+
+	ticketUnsnoozedSubscription, e := s.natsClient.QueueSubscribe(ticketUnsnoozedSubject,
+		"kiosk.system_comments_group", s.onTicketEvent)
+	if e != nil {
+		return e
+	}
+
+	ticketEscalatedSubscription, e := s.natsClient.QueueSubscribe(ticketEscalatedSubject,
+		"kiosk.system_comments_group", s.onTicketEvent)
+	if e != nil {
+		return e
+	}
+
+	ticketAssignedSubscription, e := s.natsClient.QueueSubscribe(ticketAssignedSubject,
+		"kiosk.system_comments_group", s.onTicketEvent)
+	if e != nil {
+		return e
+	}
+
+	ticketUnassignedSubscription, e := s.natsClient.QueueSubscribe(ticketUnassignedSubject,
+		"kiosk.system_comments_group", s.onTicketEvent)
+	if e != nil {
+		return e
+	}
+
+	ticketApprovalRequestedSubscription, e := s.natsClient.QueueSubscribe(ticketApprovalRequestedSubject,
+		"kiosk.system_comments_group", s.onTicketEvent)
+	if e != nil {
+		return e
+	}
+
+	ticketApprovedSubscription, e := s.natsClient.QueueSubscribe(ticketApprovedSubject,
+		"kiosk.system_comments_group", s.onTicketEvent)
+	if e != nil {
+		return e
+	}
+
+	ticketRejectedSubscription, e := s.natsClient.QueueSubscribe(ticketRejectedSubject,
+		"kiosk.system_comments_group", s.onTicketEvent)
+	if e != nil {
+		return e
+	}
+
+	go s.await(createCommentSubscription, loadCommentSubscription, updateCommentSubscription, deleteCommentSubscription,
+		filterCommentsSubscription, diffRevisionsSubscription, ticketUpdatedSubscription, ticketSnoozedSubscription,
+		ticketUnsnoozedSubscription, ticketEscalatedSubscription, ticketAssignedSubscription,
+		ticketUnassignedSubscription, ticketApprovalRequestedSubscription, ticketApprovedSubscription,
+		ticketRejectedSubscription)
 
 	return nil
 }
@@ -66,9 +155,8 @@ func (s *CommentService) await(ss ...*nc.Subscription) {
 	<-s.stop
 	s.logger.Debug("CommentService: received stop signal!")
 
-	for _, s := range ss {
-		_ = s.Unsubscribe()
-	}
+	drain(s.logger, ss)
+	s.stop <- struct{}{}
 }
 
 func (s *CommentService) create(msg *nc.Msg) {
@@ -86,12 +174,46 @@ func (s *CommentService) create(msg *nc.Msg) {
 		return
 	}
 
-	if e := s.commentRepository.Insert(ctx, *createCommentRequest.AsComment()); e != nil {
+	if e := s.checkAttachments(ctx, createCommentRequest); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	comment := createCommentRequest.AsComment()
+	id, e := s.commentRepository.Insert(ctx, *comment)
+	if e != nil {
 		s.reply(msg, e)
 		return
 	}
 
-	s.replyNoContent(msg)
+	replyConsistencyToken(ctx, s.consistencyRepository, msg)
+
+	comment.ID = id
+	comment.CreatedAt = time.Now()
+	comment.ModifiedAt = comment.CreatedAt
+
+	// Reload comments whose author type got resolved on insertion, so the event carries it.
+	if comment.AuthorType == "" {
+		if comment, e = s.commentRepository.LoadByID(ctx, id); e != nil {
+			return
+		}
+	}
+
+	publishCommentEvent(s.logger, s.natsClient, commentCreatedSubject, data.EventTypeCommentCreated, comment)
+}
+
+// checkAttachments checks the attachments the comment refers to, replying every violation at once.
+func (s *CommentService) checkAttachments(ctx context.Context, request *data.CreateCommentRequest) *errors.Type {
+	if len(request.AttachmentIDs) == 0 {
+		return nil
+	}
+
+	attachments, e := s.attachmentRepository.LoadByIDs(ctx, request.TicketID, request.AttachmentIDs)
+	if e != nil {
+		return e
+	}
+
+	return checkAttachmentReferences(request.TicketID, request.AttachmentIDs, attachments)
 }
 
 func (s *CommentService) load(msg *nc.Msg) {
@@ -112,9 +234,91 @@ func (s *CommentService) load(msg *nc.Msg) {
 
 	commentResponse := &data.CommentResponse{}
 	commentResponse.LoadFromComment(c)
+	s.identities.EnrichComments(ctx, commentResponse)
 	s.reply(msg, commentResponse)
 }
 
+func (s *CommentService) filter(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filterCommentsRequest := &data.FilterCommentsRequest{}
+	if e := json.Unmarshal(msg.Data, filterCommentsRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := filterCommentsRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if filterCommentsRequest.CountOnly {
+		count, e := s.commentRepository.FilterCount(ctx, filterCommentsRequest.TicketID,
+			filterCommentsRequest.AuthorTypes, filterCommentsRequest.Sources)
+		if e != nil {
+			s.reply(msg, e)
+			return
+		}
+
+		s.reply(msg, &data.FilterCountResponse{Count: &count})
+		return
+	}
+
+	if filterCommentsRequest.Exists {
+		exists, e := s.commentRepository.FilterExists(ctx, filterCommentsRequest.TicketID,
+			filterCommentsRequest.AuthorTypes, filterCommentsRequest.Sources)
+		if e != nil {
+			s.reply(msg, e)
+			return
+		}
+
+		s.reply(msg, &data.FilterCountResponse{Exists: &exists})
+		return
+	}
+
+	if filterCommentsRequest.Windowed() {
+		comments, hasMoreBefore, hasMoreAfter, e := s.commentRepository.FilterAround(ctx,
+			filterCommentsRequest.TicketID, filterCommentsRequest.AuthorTypes, filterCommentsRequest.Sources,
+			filterCommentsRequest.Orders(), filterCommentsRequest.AroundCommentID, filterCommentsRequest.Window)
+		if e != nil {
+			s.reply(msg, e)
+			return
+		}
+
+		filterCommentsResponse := &data.FilterCommentsResponse{HasMoreBefore: hasMoreBefore,
+			HasMoreAfter: hasMoreAfter}
+		filterCommentsResponse.LoadFromComments(comments)
+		s.identities.EnrichComments(ctx, filterCommentsResponse.Comments...)
+		s.reply(msg, filterCommentsResponse)
+		return
+	}
+
+	orders := filterCommentsRequest.Orders()
+	after, e := pageCursor(s.pageTokens, filterCommentsRequest.PageToken, commentsPageList, orders)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	comments, hasMore, e := s.commentRepository.Filter(ctx, filterCommentsRequest.TicketID,
+		filterCommentsRequest.AuthorTypes, filterCommentsRequest.Sources, orders, after, filterCommentsRequest.PageSize)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	filterCommentsResponse := &data.FilterCommentsResponse{}
+	filterCommentsResponse.LoadFromComments(comments)
+	s.identities.EnrichComments(ctx, filterCommentsResponse.Comments...)
+	if hasMore {
+		filterCommentsResponse.NextPageToken = s.pageTokens.Issue(commentsPageList, orders,
+			comments[len(comments)-1].Cursor(orders))
+	}
+
+	s.reply(msg, filterCommentsResponse)
+}
+
 func (s *CommentService) update(msg *nc.Msg) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -130,12 +334,49 @@ func (s *CommentService) update(msg *nc.Msg) {
 		return
 	}
 
-	if e := s.commentRepository.Update(ctx, updateCommentRequest.AsComment()); e != nil {
+	e := s.commentRepository.Update(ctx, updateCommentRequest.AsComment(), updateCommentRequest.Editor)
+	if e != nil {
 		s.reply(msg, e)
 		return
 	}
 
-	s.replyNoContent(msg)
+	replyConsistencyToken(ctx, s.consistencyRepository, msg)
+}
+
+// diffRevisions replies the word by word diff between two revisions of the content of a comment.
+func (s *CommentService) diffRevisions(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	diffCommentRevisionsRequest := &data.DiffCommentRevisionsRequest{}
+	if e := json.Unmarshal(msg.Data, diffCommentRevisionsRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := diffCommentRevisionsRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	id, revA, revB := diffCommentRevisionsRequest.CommentID, diffCommentRevisionsRequest.RevA,
+		diffCommentRevisionsRequest.RevB
+
+	from, e := s.revisionRepository.LoadContent(ctx, id, revA)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	to, e := s.revisionRepository.LoadContent(ctx, id, revB)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	commentRevisionsDiffResponse := &data.CommentRevisionsDiffResponse{}
+	commentRevisionsDiffResponse.LoadFromChanges(id, revA, revB, diffing.Words(from, to))
+	s.reply(msg, commentRevisionsDiffResponse)
 }
 
 func (s *CommentService) delete(msg *nc.Msg) {
@@ -153,7 +394,106 @@ func (s *CommentService) delete(msg *nc.Msg) {
 		return
 	}
 
-	s.replyNoContent(msg)
+	replyConsistencyToken(ctx, s.consistencyRepository, msg)
+}
+
+// onTicketEvent records the lifecycle event of a ticket as a system comment in its thread, unless the issuer of the
+// ticket opted out. System comments are not published as comment events, so they never notify anyone.
+func (s *CommentService) onTicketEvent(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	event := &data.Event{}
+	if e := json.Unmarshal(msg.Data, event); e != nil || event.Ticket == nil {
+		return
+	}
+
+	content := describeLifecycleEvent(event)
+	if content == "" {
+		return
+	}
+
+	settings, e := s.issuerSettingsRepository.LoadByIssuer(ctx, event.Ticket.Issuer)
+	if e != nil || !settings.SystemComments {
+		return
+	}
+
+	metadata, _ := json.Marshal(&struct {
+		Event          data.EventType      `json:"event"`
+		PreviousStatus models.TicketStatus `json:"previousStatus,omitempty"`
+		Status         models.TicketStatus `json:"status"`
+		Actor          string              `json:"actor,omitempty"`
+	}{event.Type, event.PreviousStatus, event.Ticket.Status, event.Actor})
+
+	comment := models.Comment{TicketID: event.Ticket.ID, Owner: systemCommentOwner, Content: content,
+		Metadata: string(metadata), AuthorType: models.CommentAuthorTypeSystem}
+	_, _ = s.commentRepository.Insert(ctx, comment)
+}
+
+// describeLifecycleEvent returns back the content of the system comment of the event, which is empty for events that
+// are not worth recording.
+func describeLifecycleEvent(event *data.Event) string {
+	var description string
+	switch event.Type {
+	case data.EventTypeTicketUpdated:
+		if event.PreviousStatus == "" || event.PreviousStatus == event.Ticket.Status {
+			return ""
+		}
+
+		description = fmt.Sprintf("Status changed from %v to %v", event.PreviousStatus, event.Ticket.Status)
+	case data.EventTypeTicketSnoozed:
+		description = "Snoozed until " + event.Ticket.SnoozedUntil
+	case data.EventTypeTicketUnsnoozed:
+		description = "Returned from snooze"
+	case data.EventTypeTicketEscalated:
+		if event.Escalation == nil {
+			return ""
+		}
+
+		description = fmt.Sprintf("Escalated to level %d, %v", event.Escalation.Level, event.Escalation.Recipient)
+	case data.EventTypeTicketApprovalRequested, data.EventTypeTicketApproved, data.EventTypeTicketRejected:
+		if event.Approval == nil {
+			return ""
+		}
+
+		description = describeApproval(event.Type, event.Approval)
+	case data.EventTypeTicketAssigned:
+		description = "Assigned to " + event.Ticket.Assignee
+		if event.PreviousAssignee != "" {
+			description = fmt.Sprintf("Reassigned from %v to %v", event.PreviousAssignee, event.Ticket.Assignee)
+		}
+	case data.EventTypeTicketUnassigned:
+		description = "Unassigned from " + event.PreviousAssignee
+	default:
+		return ""
+	}
+
+	if event.Actor != "" {
+		description += " by " + event.Actor
+	}
+
+	return description + "."
+}
+
+func describeApproval(eventType data.EventType, approval *data.TicketApprovalResponse) string {
+	var description string
+	switch eventType {
+	case data.EventTypeTicketApprovalRequested:
+		description = fmt.Sprintf("Approval requested to move to %v", approval.Status)
+		if approval.Reason != "" {
+			description += fmt.Sprintf(" (%v)", approval.Reason)
+		}
+	case data.EventTypeTicketApproved:
+		description = fmt.Sprintf("Moving to %v approved", approval.Status)
+	default:
+		description = fmt.Sprintf("Moving to %v rejected", approval.Status)
+	}
+
+	if approval.Note != "" {
+		description += fmt.Sprintf(" (%v)", approval.Note)
+	}
+
+	return description
 }
 
 func (s *CommentService) reply(msg *nc.Msg, t interface{}) {
@@ -161,11 +501,8 @@ func (s *CommentService) reply(msg *nc.Msg, t interface{}) {
 	_ = msg.Respond(reply)
 }
 
-func (s *CommentService) replyNoContent(msg *nc.Msg) {
-	_ = msg.Respond([]byte(""))
-}
-
-// Stop stops the component and it subscriptions.
+// Stop stops the component and drains its subscriptions, blocking until their in-flight messages are handled.
 func (s *CommentService) Stop() {
 	s.stop <- struct{}{}
+	<-s.stop
 }
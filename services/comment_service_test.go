@@ -0,0 +1,184 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/pagination"
+	"github.com/jibitters/kiosk/services/mocks"
+	"github.com/jibitters/kiosk/web/data"
+	"github.com/nats-io/nats-server/v2/server"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	nc "github.com/nats-io/nats.go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("CommentService", func() {
+	var natsServer *server.Server
+	var natsClient *nc.Conn
+	var controller *gomock.Controller
+	var commentRepository *mocks.MockCommentRepository
+	var service *CommentService
+
+	BeforeEach(func() {
+		opts := natsserver.DefaultTestOptions
+		opts.Port = server.RANDOM_PORT
+		natsServer = natsserver.RunServer(&opts)
+
+		client, e := nc.Connect(natsServer.ClientURL())
+		Ω(e).Should(BeNil())
+		natsClient = client
+
+		controller = gomock.NewController(GinkgoT())
+		commentRepository = mocks.NewMockCommentRepository(controller)
+
+		service = &CommentService{
+			logger:            zap.S(),
+			commentRepository: commentRepository,
+			natsClient:        natsClient,
+			pageTokens:        pagination.NewTokens("comments"),
+			stop:              make(chan struct{}),
+		}
+		Ω(service.Start()).Should(BeNil())
+	})
+
+	AfterEach(func() {
+		service.Stop()
+		natsClient.Close()
+		natsServer.Shutdown()
+		controller.Finish()
+	})
+
+	request := func(subject string, in interface{}) []byte {
+		body, _ := json.Marshal(in)
+
+		response, e := natsClient.Request(subject, body, 5*time.Second)
+		Ω(e).Should(BeNil())
+
+		return response.Data
+	}
+
+	Context("When create called with an invalid request", func() {
+		It("Should reply the validation error without storing the comment", func() {
+			reply := &errors.Type{}
+			Ω(json.Unmarshal(request("kiosk.comments.create", &data.CreateCommentRequest{}), reply)).Should(BeNil())
+			Ω(reply.HTTPStatusCode).Should(Equal(http.StatusBadRequest))
+			Ω(reply.Errors[0].Code).Should(Equal("ticketID.invalid"))
+		})
+	})
+
+	Context("When load called for a missing comment", func() {
+		It("Should reply the error of the repository", func() {
+			commentRepository.EXPECT().LoadByID(gomock.Any(), int64(1)).
+				Return(nil, errors.NotFound("comment.not_found", ""))
+
+			reply := &errors.Type{}
+			Ω(json.Unmarshal(request("kiosk.comments.load", &data.ID{ID: 1}), reply)).Should(BeNil())
+			Ω(reply.HTTPStatusCode).Should(Equal(http.StatusNotFound))
+			Ω(reply.Errors[0].Code).Should(Equal("comment.not_found"))
+		})
+	})
+
+	Context("When filter called", func() {
+		It("Should reply the comments of the ticket", func() {
+			comment := &models.Comment{Model: models.Model{ID: 2}, TicketID: 1, Owner: "agent@example.com",
+				Content: "Fixed!", AuthorType: models.CommentAuthorTypeAgent}
+
+			commentRepository.EXPECT().
+				Filter(gomock.Any(), int64(1), []models.CommentAuthorType{models.CommentAuthorTypeAgent}, nil,
+					[]models.Order{{Field: "createdAt"}, {Field: "id", Descending: true}}, nil, 0).
+				Return([]*models.Comment{comment}, false, nil)
+
+			filterCommentsRequest := &data.FilterCommentsRequest{TicketID: 1,
+				AuthorTypes: []models.CommentAuthorType{models.CommentAuthorTypeAgent}, OrderBy: "createdAt,id:desc"}
+
+			reply := &data.FilterCommentsResponse{}
+			Ω(json.Unmarshal(request("kiosk.comments.filter", filterCommentsRequest), reply)).Should(BeNil())
+			Ω(len(reply.Comments)).Should(Equal(1))
+			Ω(reply.Comments[0].ID).Should(Equal(int64(2)))
+			Ω(reply.Comments[0].Content).Should(Equal("Fixed!"))
+			Ω(reply.NextPageToken).Should(BeEmpty())
+		})
+
+		It("Should continue the next page right after the last comment of the page", func() {
+			comment := &models.Comment{Model: models.Model{ID: 2, CreatedAt: time.Now()}, TicketID: 1,
+				Owner: "agent@example.com", Content: "Fixed!", AuthorType: models.CommentAuthorTypeAgent}
+			orders := []models.Order{{Field: "createdAt"}}
+
+			commentRepository.EXPECT().Filter(gomock.Any(), int64(1), nil, nil, orders, nil, 1).
+				Return([]*models.Comment{comment}, true, nil)
+
+			filterCommentsRequest := &data.FilterCommentsRequest{PageRequest: data.PageRequest{PageSize: 1},
+				TicketID: 1, OrderBy: "createdAt"}
+
+			reply := &data.FilterCommentsResponse{}
+			Ω(json.Unmarshal(request("kiosk.comments.filter", filterCommentsRequest), reply)).Should(BeNil())
+			Ω(reply.NextPageToken).ShouldNot(BeEmpty())
+
+			commentRepository.EXPECT().Filter(gomock.Any(), int64(1), nil, nil, orders, comment.Cursor(orders), 1).
+				Return([]*models.Comment{}, false, nil)
+
+			filterCommentsRequest.PageToken = reply.NextPageToken
+			reply = &data.FilterCommentsResponse{}
+			Ω(json.Unmarshal(request("kiosk.comments.filter", filterCommentsRequest), reply)).Should(BeNil())
+			Ω(reply.Comments).Should(BeEmpty())
+			Ω(reply.NextPageToken).Should(BeEmpty())
+		})
+
+		It("Should reply invalid argument for page tokens of other orders", func() {
+			token := pagination.NewTokens("comments").Issue("comments", []models.Order{{Field: "id"}},
+				models.Cursor{nil})
+			filterCommentsRequest := &data.FilterCommentsRequest{PageRequest: data.PageRequest{PageToken: token},
+				TicketID: 1, OrderBy: "createdAt"}
+
+			reply := &errors.Type{}
+			Ω(json.Unmarshal(request("kiosk.comments.filter", filterCommentsRequest), reply)).Should(BeNil())
+			Ω(reply.HTTPStatusCode).Should(Equal(http.StatusBadRequest))
+			Ω(reply.Errors[0].Code).Should(Equal("pageToken.not_valid"))
+		})
+
+		It("Should reply the number of comments in count only mode", func() {
+			commentRepository.EXPECT().FilterCount(gomock.Any(), int64(1), nil, nil).Return(int64(3), nil)
+
+			filterCommentsRequest := &data.FilterCommentsRequest{TicketID: 1, CountOnly: true}
+
+			reply := &data.FilterCountResponse{}
+			Ω(json.Unmarshal(request("kiosk.comments.filter", filterCommentsRequest), reply)).Should(BeNil())
+			Ω(*reply.Count).Should(Equal(int64(3)))
+			Ω(reply.Exists).Should(BeNil())
+		})
+
+		It("Should reply the window of comments around the comment", func() {
+			comment := &models.Comment{Model: models.Model{ID: 2}, TicketID: 1, Owner: "agent@example.com",
+				Content: "Fixed!", AuthorType: models.CommentAuthorTypeAgent}
+
+			commentRepository.EXPECT().
+				FilterAround(gomock.Any(), int64(1), nil, nil, []models.Order{{Field: "createdAt"}}, int64(2), 10).
+				Return([]*models.Comment{comment}, true, false, nil)
+
+			filterCommentsRequest := &data.FilterCommentsRequest{TicketID: 1, OrderBy: "createdAt", AroundCommentID: 2}
+
+			reply := &data.FilterCommentsResponse{}
+			Ω(json.Unmarshal(request("kiosk.comments.filter", filterCommentsRequest), reply)).Should(BeNil())
+			Ω(reply.Comments).Should(HaveLen(1))
+			Ω(reply.Comments[0].ID).Should(Equal(int64(2)))
+			Ω(reply.HasMoreBefore).Should(BeTrue())
+			Ω(reply.HasMoreAfter).Should(BeFalse())
+		})
+
+		It("Should reply invalid argument when ordered by an unknown field", func() {
+			filterCommentsRequest := &data.FilterCommentsRequest{TicketID: 1, OrderBy: "owner"}
+
+			reply := &errors.Type{}
+			Ω(json.Unmarshal(request("kiosk.comments.filter", filterCommentsRequest), reply)).Should(BeNil())
+			Ω(reply.HTTPStatusCode).Should(Equal(http.StatusBadRequest))
+			Ω(reply.Errors[0].Code).Should(Equal("orderBy.field_not_valid"))
+		})
+	})
+})
@@ -0,0 +1,19 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+)
+
+// replyConsistencyToken replies to a successful mutation with the token that lets subsequent reads observe it. The
+// mutation is already persisted, so failing to issue a token results in an empty one rather than an error.
+func replyConsistencyToken(ctx context.Context, consistencyRepository *models.ConsistencyRepository, msg *nc.Msg) {
+	token, _ := consistencyRepository.Token(ctx)
+
+	reply, _ := json.Marshal(&data.ConsistencyToken{ConsistencyToken: token})
+	_ = msg.Respond(reply)
+}
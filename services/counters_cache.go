@@ -0,0 +1,76 @@
+package services
+
+import (
+	"sync"
+
+	"github.com/jibitters/kiosk/models"
+)
+
+// countersKey identifies the counters of the tickets of an owner, assigned to an assignee or both. The empty key holds
+// the counters of all tickets.
+type countersKey struct {
+	owner    string
+	assignee string
+}
+
+// countersCache keeps the ticket counters per owner and assignee. Entries are invalidated whenever a ticket of the
+// owner, or one assigned or unassigned from the assignee, changes on any kiosk instance.
+type countersCache struct {
+	mutex      sync.Mutex
+	counters   map[countersKey]map[models.TicketStatus]int64
+	generation uint64
+}
+
+func newCountersCache() *countersCache {
+	return &countersCache{counters: make(map[countersKey]map[models.TicketStatus]int64)}
+}
+
+// get returns back the cached counters of the key along with the generation to pass to put after a cache miss.
+func (c *countersCache) get(key countersKey) (map[models.TicketStatus]int64, uint64, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	counters, ok := c.counters[key]
+	return counters, c.generation, ok
+}
+
+// put caches the counters unless an invalidation happened since the generation was read, as the counters might
+// already be stale.
+func (c *countersCache) put(key countersKey, counters map[models.TicketStatus]int64, generation uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if generation == c.generation {
+		c.counters[key] = counters
+	}
+}
+
+// invalidate drops the counters a change of a ticket of the owner, assigned to or unassigned from the assignees, can
+// affect.
+func (c *countersCache) invalidate(owner string, assignees ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generation++
+	for key := range c.counters {
+		if key.owner == owner || key.owner == "" && key.assignee == "" {
+			delete(c.counters, key)
+			continue
+		}
+
+		for _, assignee := range assignees {
+			if key.assignee != "" && key.assignee == assignee {
+				delete(c.counters, key)
+				break
+			}
+		}
+	}
+}
+
+func (c *countersCache) invalidateAll() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generation++
+	c.counters = make(map[countersKey]map[models.TicketStatus]int64)
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"github.com/jibitters/kiosk/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("countersCache", func() {
+	counters := map[models.TicketStatus]int64{models.TicketStatusNew: 1}
+
+	Context("When a ticket changes", func() {
+		It("Should drop the counters of its owner and assignees along with the ones of all tickets", func() {
+			cache := newCountersCache()
+
+			keys := []countersKey{{}, {owner: "user@example.com"}, {owner: "other@example.com"},
+				{assignee: "agent-a"}, {assignee: "agent-b"}, {assignee: "agent-c"},
+				{owner: "other@example.com", assignee: "agent-b"}}
+			for _, key := range keys {
+				_, generation, _ := cache.get(key)
+				cache.put(key, counters, generation)
+			}
+
+			cache.invalidate("user@example.com", "agent-a", "agent-b")
+
+			for _, key := range keys {
+				_, _, ok := cache.get(key)
+				kept := key == countersKey{owner: "other@example.com"} || key == countersKey{assignee: "agent-c"}
+				Ω(ok).Should(Equal(kept), "key: %+v", key)
+			}
+		})
+
+		It("Should not cache counters counted before the change", func() {
+			cache := newCountersCache()
+
+			_, generation, _ := cache.get(countersKey{assignee: "agent-a"})
+			cache.invalidate("user@example.com", "agent-a")
+			cache.put(countersKey{assignee: "agent-a"}, counters, generation)
+
+			_, _, ok := cache.get(countersKey{assignee: "agent-a"})
+			Ω(ok).Should(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// DraftService is a service implementation of ticket draft functionalities. Clients autosave the tickets being
+// composed by requesters as drafts, which are removed once their tickets get created or they expire after ttl.
+type DraftService struct {
+	logger                *zap.SugaredLogger
+	ticketDraftRepository *models.TicketDraftRepository
+	natsClient            *nc.Conn
+	ttl                   time.Duration
+	stop                  chan struct{}
+}
+
+// NewDraftService returns a newly created and ready to use DraftService.
+func NewDraftService(logger *zap.SugaredLogger, db *pgxpool.Pool, natsClient *nc.Conn,
+	ttl time.Duration) *DraftService {
+
+	return &DraftService{
+		logger:                logger,
+		ticketDraftRepository: models.NewTicketDraftRepository(logger, db),
+		natsClient:            natsClient,
+		ttl:                   ttl,
+		stop:                  make(chan struct{}),
+	}
+}
+
+// Start starts the subscriptions so ready to be notified.
+func (s *DraftService) Start() error {
+	saveDraftSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.drafts.save",
+		"kiosk.tickets.drafts.save_group", s.save)
+	if e != nil {
+		return e
+	}
+
+	loadDraftSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.drafts.load",
+		"kiosk.tickets.drafts.load_group", s.load)
+	if e != nil {
+		return e
+	}
+
+	deleteDraftSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.drafts.delete",
+		"kiosk.tickets.drafts.delete_group", s.delete)
+	if e != nil {
+		return e
+	}
+
+	ticketCreatedSubscription, e := s.natsClient.QueueSubscribe(ticketCreatedSubject, "kiosk.drafts_group",
+		s.onTicketCreated)
+	if e != nil {
+		return e
+	}
+
+	go s.await(saveDraftSubscription, loadDraftSubscription, deleteDraftSubscription, ticketCreatedSubscription)
+
+	return nil
+}
+
+func (s *DraftService) await(ss ...*nc.Subscription) {
+	<-s.stop
+	s.logger.Debug("DraftService: received stop signal!")
+
+	drain(s.logger, ss)
+	s.stop <- struct{}{}
+}
+
+func (s *DraftService) save(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	saveTicketDraftRequest := &data.SaveTicketDraftRequest{}
+	if e := json.Unmarshal(msg.Data, saveTicketDraftRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := saveTicketDraftRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if e := s.ticketDraftRepository.Save(ctx, *saveTicketDraftRequest.AsTicketDraft()); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+func (s *DraftService) load(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ticketDraftRequest := &data.TicketDraftRequest{}
+	if e := json.Unmarshal(msg.Data, ticketDraftRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := ticketDraftRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	draft, e := s.ticketDraftRepository.Load(ctx, ticketDraftRequest.Issuer, ticketDraftRequest.Owner,
+		time.Now().UTC().Add(-s.ttl))
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	ticketDraftResponse := &data.TicketDraftResponse{}
+	ticketDraftResponse.LoadFromTicketDraft(draft)
+	s.reply(msg, ticketDraftResponse)
+}
+
+func (s *DraftService) delete(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ticketDraftRequest := &data.TicketDraftRequest{}
+	if e := json.Unmarshal(msg.Data, ticketDraftRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := ticketDraftRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if e := s.ticketDraftRepository.Delete(ctx, ticketDraftRequest.Issuer, ticketDraftRequest.Owner); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+// onTicketCreated removes the draft of the requester who submitted the ticket.
+func (s *DraftService) onTicketCreated(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	event := &data.Event{}
+	if e := json.Unmarshal(msg.Data, event); e != nil || event.Ticket == nil {
+		return
+	}
+
+	_ = s.ticketDraftRepository.Delete(ctx, event.Ticket.Issuer, event.Ticket.Owner)
+}
+
+// DeleteExpiredDrafts is a scheduler job that runs every hour and deletes the drafts not modified within the ttl.
+func (s *DraftService) DeleteExpiredDrafts(ctx context.Context, now time.Time) {
+	deleted, e := s.ticketDraftRepository.DeleteExpired(ctx, now.UTC().Add(-s.ttl))
+	if e != nil {
+		return
+	}
+
+	if deleted > 0 {
+		s.logger.Info("Deleted ", deleted, " expired ticket drafts.")
+	}
+}
+
+func (s *DraftService) reply(msg *nc.Msg, t interface{}) {
+	reply, _ := json.Marshal(t)
+	_ = msg.Respond(reply)
+}
+
+func (s *DraftService) replyNoContent(msg *nc.Msg) {
+	_ = msg.Respond([]byte(""))
+}
+
+// Stop stops the component and drains its subscriptions, blocking until their in-flight messages are handled.
+func (s *DraftService) Stop() {
+	s.stop <- struct{}{}
+	<-s.stop
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"time"
+
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// drainTimeout bounds how long stopping a service waits for its in-flight messages.
+const drainTimeout = 30 * time.Second
+
+// drain removes the interest of subscriptions, so their queue groups hand new messages to other instances, and waits
+// until the messages they already received are handled. Requests being processed during a deploy are not dropped this
+// way.
+func drain(logger *zap.SugaredLogger, ss []*nc.Subscription) {
+	for _, s := range ss {
+		if e := s.Drain(); e != nil {
+			logger.Warn("Could not drain subscription of ", s.Subject, ": ", e.Error())
+		}
+	}
+
+	// A drained subscription becomes invalid once the callback of its last pending message has returned.
+	deadline := time.Now().Add(drainTimeout)
+	for _, s := range ss {
+		for s.IsValid() {
+			if time.Now().After(deadline) {
+				logger.Warn("Timed out draining subscription of ", s.Subject)
+				return
+			}
+
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// escalationTiers are the tiers the delays of escalation levels are scaled for, tickets created without a tier use the
+// delays as they are.
+var escalationTiers = []models.CustomerTier{models.CustomerTierGold, models.CustomerTierSilver,
+	models.CustomerTierBronze, ""}
+
+// EscalationService is a service implementation of escalation functionalities. It manages the escalation chains of
+// issuers and escalates their unresolved tickets through the levels of those chains.
+type EscalationService struct {
+	logger                     *zap.SugaredLogger
+	ticketRepository           TicketRepository
+	escalationLevelRepository  *models.EscalationLevelRepository
+	ticketEscalationRepository *models.TicketEscalationRepository
+	natsClient                 *nc.Conn
+	stop                       chan struct{}
+}
+
+// NewEscalationService returns a newly created and ready to use EscalationService.
+func NewEscalationService(logger *zap.SugaredLogger, db *pgxpool.Pool, natsClient *nc.Conn) *EscalationService {
+	return &EscalationService{
+		logger:                     logger,
+		ticketRepository:           models.NewTicketRepository(logger, db),
+		escalationLevelRepository:  models.NewEscalationLevelRepository(logger, db),
+		ticketEscalationRepository: models.NewTicketEscalationRepository(logger, db),
+		natsClient:                 natsClient,
+		stop:                       make(chan struct{}),
+	}
+}
+
+// Start starts the subscriptions so ready to be notified.
+func (s *EscalationService) Start() error {
+	savePolicySubscription, e := s.natsClient.QueueSubscribe("kiosk.issuers.escalations.save",
+		"kiosk.issuers.escalations.save_group", s.savePolicy)
+	if e != nil {
+		return e
+	}
+
+	loadPoliciesSubscription, e := s.natsClient.QueueSubscribe("kiosk.issuers.escalations.load",
+		"kiosk.issuers.escalations.load_group", s.loadPolicies)
+	if e != nil {
+		return e
+	}
+
+	deletePolicySubscription, e := s.natsClient.QueueSubscribe("kiosk.issuers.escalations.delete",
+		"kiosk.issuers.escalations.delete_group", s.deletePolicy)
+	if e != nil {
+		return e
+	}
+
+	ticketEscalationsSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.escalations",
+		"kiosk.tickets.escalations_group", s.ticketEscalations)
+	if e != nil {
+		return e
+	}
+
+	go s.await(savePolicySubscription, loadPoliciesSubscription, deletePolicySubscription,
+		ticketEscalationsSubscription)
+
+	return nil
+}
+
+func (s *EscalationService) await(ss ...*nc.Subscription) {
+	<-s.stop
+	s.logger.Debug("EscalationService: received stop signal!")
+
+	drain(s.logger, ss)
+	s.stop <- struct{}{}
+}
+
+// savePolicy creates or replaces the escalation chain of an issuer and importance level. Tickets already escalated
+// to a level are not escalated to the new level of the same number.
+func (s *EscalationService) savePolicy(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	saveEscalationPolicyRequest := &data.SaveEscalationPolicyRequest{}
+	if e := json.Unmarshal(msg.Data, saveEscalationPolicyRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := saveEscalationPolicyRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	e := s.escalationLevelRepository.Save(ctx, saveEscalationPolicyRequest.Issuer,
+		saveEscalationPolicyRequest.ImportanceLevel, saveEscalationPolicyRequest.AsEscalationLevels())
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+func (s *EscalationService) loadPolicies(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	issuerRequest := &data.IssuerRequest{}
+	if e := json.Unmarshal(msg.Data, issuerRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := issuerRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	levels, e := s.escalationLevelRepository.LoadByIssuer(ctx, issuerRequest.Issuer)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	escalationPoliciesResponse := &data.EscalationPoliciesResponse{}
+	escalationPoliciesResponse.LoadFromEscalationLevels(levels)
+	s.reply(msg, escalationPoliciesResponse)
+}
+
+func (s *EscalationService) deletePolicy(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	escalationPolicyRequest := &data.EscalationPolicyRequest{}
+	if e := json.Unmarshal(msg.Data, escalationPolicyRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := escalationPolicyRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	e := s.escalationLevelRepository.Delete(ctx, escalationPolicyRequest.Issuer,
+		escalationPolicyRequest.ImportanceLevel)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+// ticketEscalations replies the levels a ticket has been escalated to, which are kept even if its escalation chain
+// is changed afterwards.
+func (s *EscalationService) ticketEscalations(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ticketEscalationsRequest := &data.TicketEscalationsRequest{}
+	if e := json.Unmarshal(msg.Data, ticketEscalationsRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := ticketEscalationsRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	escalations, e := s.ticketEscalationRepository.LoadByTicketID(ctx, ticketEscalationsRequest.TicketID)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	ticketEscalationsResponse := &data.TicketEscalationsResponse{}
+	ticketEscalationsResponse.LoadFromTicketEscalations(escalations)
+	s.reply(msg, ticketEscalationsResponse)
+}
+
+// EscalateDueTickets is a scheduler job that escalates the unresolved tickets that have been open for longer than the
+// delays of the levels of their escalation chains, scaled by the tier of each ticket. Every escalation is recorded
+// and published as an event, which notifies the recipient of the level and adds a system comment to the ticket.
+func (s *EscalationService) EscalateDueTickets(ctx context.Context, now time.Time) {
+	levels, e := s.escalationLevelRepository.LoadAll(ctx)
+	if e != nil {
+		return
+	}
+
+	for _, level := range levels {
+		for _, tier := range escalationTiers {
+			ids, e := s.ticketEscalationRepository.ClaimDue(ctx, level, tier, now.Add(-level.DelayFor(tier)), now)
+			if e != nil {
+				return
+			}
+
+			for _, id := range ids {
+				s.publishEscalation(ctx, id, level, now)
+			}
+		}
+	}
+}
+
+// publishEscalation publishes the escalation event of a ticket escalated to the level, with its current state.
+func (s *EscalationService) publishEscalation(ctx context.Context, id int64, level *models.EscalationLevel,
+	now time.Time) {
+
+	ticket, e := s.ticketRepository.LoadByID(ctx, id)
+	if e != nil {
+		return
+	}
+
+	ticketResponse := &data.TicketResponse{}
+	ticketResponse.LoadFromTicket(ticket)
+
+	escalationResponse := &data.TicketEscalationResponse{}
+	escalationResponse.LoadFromTicketEscalation(&models.TicketEscalation{TicketID: id, Level: level.Level,
+		Recipient: level.Recipient, EscalatedAt: now.UTC()})
+
+	publishEvent(s.logger, s.natsClient, ticketEscalatedSubject, &data.Event{Type: data.EventTypeTicketEscalated,
+		Ticket: ticketResponse, Escalation: escalationResponse})
+}
+
+func (s *EscalationService) reply(msg *nc.Msg, t interface{}) {
+	reply, _ := json.Marshal(t)
+	_ = msg.Respond(reply)
+}
+
+func (s *EscalationService) replyNoContent(msg *nc.Msg) {
+	_ = msg.Respond([]byte(""))
+}
+
+// Stop stops the component and drains its subscriptions, blocking until their in-flight messages are handled.
+func (s *EscalationService) Stop() {
+	s.stop <- struct{}{}
+	<-s.stop
+}
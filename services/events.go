@@ -0,0 +1,60 @@
+package services
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// Subjects that events get published on. All of them match the `kiosk.events.>` wildcard.
+const (
+	ticketCreatedSubject    = "kiosk.events.tickets.created"
+	ticketUpdatedSubject    = "kiosk.events.tickets.updated"
+	ticketDeletedSubject    = "kiosk.events.tickets.deleted"
+	ticketSnoozedSubject    = "kiosk.events.tickets.snoozed"
+	ticketUnsnoozedSubject  = "kiosk.events.tickets.unsnoozed"
+	ticketEscalatedSubject  = "kiosk.events.tickets.escalated"
+	ticketAssignedSubject   = "kiosk.events.tickets.assigned"
+	ticketUnassignedSubject = "kiosk.events.tickets.unassigned"
+
+	ticketApprovalRequestedSubject = "kiosk.events.tickets.approval_requested"
+	ticketApprovedSubject          = "kiosk.events.tickets.approved"
+	ticketRejectedSubject          = "kiosk.events.tickets.rejected"
+
+	commentCreatedSubject = "kiosk.events.comments.created"
+)
+
+// publishTicketEvent publishes an event about the ticket, changed by the actor if known. Publishing is best effort,
+// failures are only logged since the change itself has already been persisted.
+func publishTicketEvent(logger *zap.SugaredLogger, natsClient *nc.Conn, subject string, eventType data.EventType,
+	ticket *models.Ticket, previousStatus models.TicketStatus, actor string) {
+
+	ticketResponse := &data.TicketResponse{}
+	ticketResponse.LoadFromTicket(ticket)
+
+	publishEvent(logger, natsClient, subject, &data.Event{Type: eventType, Ticket: ticketResponse,
+		PreviousStatus: previousStatus, Actor: actor})
+}
+
+// publishCommentEvent publishes an event about the comment.
+func publishCommentEvent(logger *zap.SugaredLogger, natsClient *nc.Conn, subject string, eventType data.EventType,
+	comment *models.Comment) {
+
+	commentResponse := &data.CommentResponse{}
+	commentResponse.LoadFromComment(comment)
+
+	publishEvent(logger, natsClient, subject, &data.Event{Type: eventType, Comment: commentResponse})
+}
+
+func publishEvent(logger *zap.SugaredLogger, natsClient *nc.Conn, subject string, event *data.Event) {
+	event.OccurredAt = time.Now().UTC().Format(time.RFC3339Nano)
+
+	payload, _ := json.Marshal(event)
+	if e := natsClient.Publish(subject, payload); e != nil {
+		logger.Warn("Could not publish ", event.Type, " event: ", e.Error())
+	}
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jibitters/kiosk/connectors"
+	"github.com/jibitters/kiosk/web/data"
+	"go.uber.org/zap"
+)
+
+// Identities enriches the owners of comments and assignees of tickets with how the identity provider knows them, so
+// clients do not look each of them up on their own. Identities are cached for the ttl, agents the identity provider
+// does not know included, while failed lookups are retried on the next response. A nil Identities enriches nothing.
+type Identities struct {
+	logger    *zap.SugaredLogger
+	directory connectors.IdentityDirectory
+	ttl       time.Duration
+	mutex     sync.Mutex
+	cached    map[string]cachedIdentity
+	now       func() time.Time
+}
+
+type cachedIdentity struct {
+	identity  *data.IdentityResponse
+	expiresAt time.Time
+}
+
+// NewIdentities returns back a newly created and ready to use Identities, or nil when there is no directory.
+func NewIdentities(logger *zap.SugaredLogger, directory connectors.IdentityDirectory, ttl time.Duration) *Identities {
+	if directory == nil {
+		return nil
+	}
+
+	return &Identities{logger: logger, directory: directory, ttl: ttl, cached: make(map[string]cachedIdentity),
+		now: time.Now}
+}
+
+// EnrichTicket enriches the assignee of the ticket and the owners of its comments.
+func (i *Identities) EnrichTicket(ctx context.Context, ticket *data.TicketResponse) {
+	if i == nil {
+		return
+	}
+
+	if ticket.Assignee != "" {
+		ticket.AssigneeIdentity = i.resolve(ctx, ticket.Assignee)
+	}
+
+	i.EnrichComments(ctx, ticket.Comments...)
+}
+
+// EnrichComments enriches the owners of the comments.
+func (i *Identities) EnrichComments(ctx context.Context, comments ...*data.CommentResponse) {
+	if i == nil {
+		return
+	}
+
+	for _, comment := range comments {
+		comment.OwnerIdentity = i.resolve(ctx, comment.Owner)
+	}
+}
+
+// resolve returns back the cached identity of the user, looking it up once expired.
+func (i *Identities) resolve(ctx context.Context, userName string) *data.IdentityResponse {
+	i.mutex.Lock()
+	cached, ok := i.cached[userName]
+	i.mutex.Unlock()
+
+	if ok && i.now().Before(cached.expiresAt) {
+		return cached.identity
+	}
+
+	identity, e := i.directory.Identity(ctx, userName)
+	if e != nil {
+		i.logger.Warn("Could not resolve identity of ", userName, ": ", e.Error())
+		return nil
+	}
+
+	cached = cachedIdentity{expiresAt: i.now().Add(i.ttl)}
+	if identity != nil {
+		cached.identity = &data.IdentityResponse{DisplayName: identity.DisplayName, Email: identity.Email,
+			AvatarURL: identity.AvatarURL}
+	}
+
+	i.mutex.Lock()
+	i.cached[userName] = cached
+	i.mutex.Unlock()
+
+	return cached.identity
+}
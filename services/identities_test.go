@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jibitters/kiosk/connectors"
+	"github.com/jibitters/kiosk/web/data"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+)
+
+type fakeDirectory struct {
+	identities map[string]*connectors.Identity
+	failing    bool
+	lookups    map[string]int
+}
+
+func (d *fakeDirectory) Identity(_ context.Context, userName string) (*connectors.Identity, error) {
+	d.lookups[userName]++
+	if d.failing {
+		return nil, errors.New("unavailable")
+	}
+
+	return d.identities[userName], nil
+}
+
+var _ = Describe("Identities", func() {
+	var directory *fakeDirectory
+	var identities *Identities
+	var now time.Time
+
+	BeforeEach(func() {
+		directory = &fakeDirectory{lookups: map[string]int{}, identities: map[string]*connectors.Identity{
+			"agent@example.com": {DisplayName: "Agent", Email: "agent@example.com", AvatarURL: "https://a/1.png"},
+		}}
+
+		now = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		identities = NewIdentities(zap.S(), directory, time.Hour)
+		identities.now = func() time.Time { return now }
+	})
+
+	Context("When enriching a ticket", func() {
+		It("Should enrich its assignee and the owners of its comments known to the identity provider", func() {
+			ticket := &data.TicketResponse{Assignee: "agent@example.com", Comments: []*data.CommentResponse{
+				{Owner: "agent@example.com"}, {Owner: "user@example.com"},
+			}}
+
+			identities.EnrichTicket(context.Background(), ticket)
+
+			expected := &data.IdentityResponse{DisplayName: "Agent", Email: "agent@example.com",
+				AvatarURL: "https://a/1.png"}
+			Ω(ticket.AssigneeIdentity).Should(Equal(expected))
+			Ω(ticket.Comments[0].OwnerIdentity).Should(Equal(expected))
+			Ω(ticket.Comments[1].OwnerIdentity).Should(BeNil())
+		})
+
+		It("Should look identities up once within the ttl, unknown ones included", func() {
+			comments := []*data.CommentResponse{{Owner: "agent@example.com"}, {Owner: "user@example.com"}}
+
+			identities.EnrichComments(context.Background(), comments...)
+			identities.EnrichComments(context.Background(), comments...)
+			Ω(directory.lookups).Should(Equal(map[string]int{"agent@example.com": 1, "user@example.com": 1}))
+
+			now = now.Add(time.Hour)
+			identities.EnrichComments(context.Background(), comments[0])
+			Ω(directory.lookups["agent@example.com"]).Should(Equal(2))
+		})
+
+		It("Should leave owners as they are and retry looking them up when the identity provider fails", func() {
+			directory.failing = true
+			comment := &data.CommentResponse{Owner: "agent@example.com"}
+
+			identities.EnrichComments(context.Background(), comment)
+			Ω(comment.OwnerIdentity).Should(BeNil())
+
+			directory.failing = false
+			identities.EnrichComments(context.Background(), comment)
+			Ω(comment.OwnerIdentity).ShouldNot(BeNil())
+			Ω(directory.lookups["agent@example.com"]).Should(Equal(2))
+		})
+	})
+
+	Context("When there is no identity provider", func() {
+		It("Should enrich nothing", func() {
+			identities = NewIdentities(zap.S(), nil, time.Hour)
+			ticket := &data.TicketResponse{Assignee: "agent@example.com"}
+
+			identities.EnrichTicket(context.Background(), ticket)
+			Ω(ticket.AssigneeIdentity).Should(BeNil())
+		})
+	})
+})
@@ -0,0 +1,453 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// IssuerService is a service implementation of issuer related functionalities.
+type IssuerService struct {
+	logger                   *zap.SugaredLogger
+	issuerSettingsRepository *models.IssuerSettingsRepository
+	slaTargetRepository      *models.SLATargetRepository
+	billingRateRepository    *models.BillingRateRepository
+	requirementRepository    *models.TransitionRequirementRepository
+	resolutionRepository     *models.ResolutionCategoryRepository
+	natsClient               *nc.Conn
+	stop                     chan struct{}
+}
+
+// NewIssuerService returns a newly created and ready to use IssuerService.
+func NewIssuerService(logger *zap.SugaredLogger, db *pgxpool.Pool, natsClient *nc.Conn) *IssuerService {
+	return &IssuerService{
+		logger:                   logger,
+		issuerSettingsRepository: models.NewIssuerSettingsRepository(logger, db),
+		slaTargetRepository:      models.NewSLATargetRepository(logger, db),
+		billingRateRepository:    models.NewBillingRateRepository(logger, db),
+		requirementRepository:    models.NewTransitionRequirementRepository(logger, db),
+		resolutionRepository:     models.NewResolutionCategoryRepository(logger, db),
+		natsClient:               natsClient,
+		stop:                     make(chan struct{}),
+	}
+}
+
+// Start starts the subscriptions so ready to be notified.
+func (s *IssuerService) Start() error {
+	saveSettingsSubscription, e := s.natsClient.QueueSubscribe("kiosk.issuers.settings.save",
+		"kiosk.issuers.settings.save_group", s.saveSettings)
+	if e != nil {
+		return e
+	}
+
+	loadSettingsSubscription, e := s.natsClient.QueueSubscribe("kiosk.issuers.settings.load",
+		"kiosk.issuers.settings.load_group", s.loadSettings)
+	if e != nil {
+		return e
+	}
+
+	saveSLATargetSubscription, e := s.natsClient.QueueSubscribe("kiosk.issuers.sla_targets.save",
+		"kiosk.issuers.sla_targets.save_group", s.saveSLATarget)
+	if e != nil {
+		return e
+	}
+
+	listSLATargetsSubscription, e := s.natsClient.QueueSubscribe("kiosk.issuers.sla_targets.list",
+		"kiosk.issuers.sla_targets.list_group", s.listSLATargets)
+	if e != nil {
+		return e
+	}
+
+	saveBillingRateSubscription, e := s.natsClient.QueueSubscribe("kiosk.issuers.billing_rates.save",
+		"kiosk.issuers.billing_rates.save_group", s.saveBillingRate)
+	if e != nil {
+		return e
+	}
+
+	loadBillingRateSubscription, e := s.natsClient.QueueSubscribe("kiosk.issuers.billing_rates.load",
+		"kiosk.issuers.billing_rates.load_group", s.loadBillingRate)
+	if e != nil {
+		return e
+	}
+
+	deleteBillingRateSubscription, e := s.natsClient.QueueSubscribe("kiosk.issuers.billing_rates.delete",
+		"kiosk.issuers.billing_rates.delete_group", s.deleteBillingRate)
+	if e != nil {
+		return e
+	}
+
+	saveRequirementsSubscription, e := s.natsClient.QueueSubscribe("kiosk.issuers.transition_requirements.save",
+		"kiosk.issuers.transition_requirements.save_group", s.saveRequirements)
+	if e != nil {
+		return e
+	}
+
+	loadRequirementsSubscription, e := s.natsClient.QueueSubscribe("kiosk.issuers.transition_requirements.load",
+		"kiosk.issuers.transition_requirements.load_group", s.loadRequirements)
+	if e != nil {
+		return e
+	}
+
+	deleteRequirementsSubscription, e := s.natsClient.QueueSubscribe("kiosk.issuers.transition_requirements.delete",
+		"kiosk.issuers.transition_requirements.delete_group", s.deleteRequirements)
+	if e != nil {
+		return e
+	}
+
+	saveResolutionCategorySubscription, e := s.natsClient.QueueSubscribe("kiosk.issuers.resolution_categories.save",
+		"kiosk.issuers.resolution_categories.save_group", s.saveResolutionCategory)
+	if e != nil {
+		return e
+	}
+
+	listResolutionCategoriesSubscription, e := s.natsClient.QueueSubscribe("kiosk.issuers.resolution_categories.list",
+		"kiosk.issuers.resolution_categories.list_group", s.listResolutionCategories)
+	if e != nil {
+		return e
+	}
+
+	deleteResolutionCategorySubscription, e := s.natsClient.QueueSubscribe(
+		"kiosk.issuers.resolution_categories.delete", "kiosk.issuers.resolution_categories.delete_group",
+		s.deleteResolutionCategory)
+	if e != nil {
+		return e
+	}
+
+	go s.await(saveSettingsSubscription, loadSettingsSubscription, saveSLATargetSubscription,
+		listSLATargetsSubscription, saveBillingRateSubscription, loadBillingRateSubscription,
+		deleteBillingRateSubscription, saveRequirementsSubscription, loadRequirementsSubscription,
+		deleteRequirementsSubscription, saveResolutionCategorySubscription, listResolutionCategoriesSubscription,
+		deleteResolutionCategorySubscription)
+
+	return nil
+}
+
+func (s *IssuerService) await(ss ...*nc.Subscription) {
+	<-s.stop
+	s.logger.Debug("IssuerService: received stop signal!")
+
+	drain(s.logger, ss)
+	s.stop <- struct{}{}
+}
+
+func (s *IssuerService) saveSettings(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	saveIssuerSettingsRequest := &data.SaveIssuerSettingsRequest{}
+	if e := json.Unmarshal(msg.Data, saveIssuerSettingsRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := saveIssuerSettingsRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if e := s.issuerSettingsRepository.Save(ctx, *saveIssuerSettingsRequest.AsIssuerSettings()); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+func (s *IssuerService) loadSettings(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	issuerRequest := &data.IssuerRequest{}
+	if e := json.Unmarshal(msg.Data, issuerRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := issuerRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	settings, e := s.issuerSettingsRepository.LoadByIssuer(ctx, issuerRequest.Issuer)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	issuerSettingsResponse := &data.IssuerSettingsResponse{}
+	issuerSettingsResponse.LoadFromIssuerSettings(settings)
+	s.reply(msg, issuerSettingsResponse)
+}
+
+// saveSLATarget creates or replaces the target of a tier and importance level. Existing tickets keep the deadlines
+// they got on creation.
+func (s *IssuerService) saveSLATarget(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	saveSLATargetRequest := &data.SaveSLATargetRequest{}
+	if e := json.Unmarshal(msg.Data, saveSLATargetRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := saveSLATargetRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if e := s.slaTargetRepository.Save(ctx, *saveSLATargetRequest.AsSLATarget()); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+func (s *IssuerService) listSLATargets(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	targets, e := s.slaTargetRepository.LoadAll(ctx)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	slaTargetsResponse := &data.SLATargetsResponse{}
+	slaTargetsResponse.LoadFromSLATargets(targets)
+	s.reply(msg, slaTargetsResponse)
+}
+
+// saveBillingRate creates or replaces the billing rate of an issuer. Exports price the billable time of any month at
+// the current rate.
+func (s *IssuerService) saveBillingRate(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	saveBillingRateRequest := &data.SaveBillingRateRequest{}
+	if e := json.Unmarshal(msg.Data, saveBillingRateRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := saveBillingRateRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if e := s.billingRateRepository.Save(ctx, *saveBillingRateRequest.AsBillingRate()); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+func (s *IssuerService) loadBillingRate(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	issuerRequest := &data.IssuerRequest{}
+	if e := json.Unmarshal(msg.Data, issuerRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := issuerRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	rate, e := s.billingRateRepository.LoadByIssuer(ctx, issuerRequest.Issuer)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	billingRateResponse := &data.BillingRateResponse{}
+	billingRateResponse.LoadFromBillingRate(rate)
+	s.reply(msg, billingRateResponse)
+}
+
+func (s *IssuerService) deleteBillingRate(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	issuerRequest := &data.IssuerRequest{}
+	if e := json.Unmarshal(msg.Data, issuerRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := issuerRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if e := s.billingRateRepository.DeleteByIssuer(ctx, issuerRequest.Issuer); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+// saveRequirements creates or replaces the fields tickets of an issuer must have before being moved to a status.
+func (s *IssuerService) saveRequirements(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	saveRequirementsRequest := &data.SaveTransitionRequirementsRequest{}
+	if e := json.Unmarshal(msg.Data, saveRequirementsRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := saveRequirementsRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	e := s.requirementRepository.Save(ctx, saveRequirementsRequest.Issuer, saveRequirementsRequest.Status,
+		saveRequirementsRequest.Fields)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+func (s *IssuerService) loadRequirements(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	issuerRequest := &data.IssuerRequest{}
+	if e := json.Unmarshal(msg.Data, issuerRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := issuerRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	requirements, e := s.requirementRepository.LoadByIssuer(ctx, issuerRequest.Issuer)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	requirementsResponse := &data.TransitionRequirementsResponse{}
+	requirementsResponse.LoadFromTransitionRequirements(requirements)
+	s.reply(msg, requirementsResponse)
+}
+
+func (s *IssuerService) deleteRequirements(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	requirementsRequest := &data.TransitionRequirementsRequest{}
+	if e := json.Unmarshal(msg.Data, requirementsRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := requirementsRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if e := s.requirementRepository.Delete(ctx, requirementsRequest.Issuer, requirementsRequest.Status); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+// saveResolutionCategory creates a resolution category or replaces its sub-categories.
+func (s *IssuerService) saveResolutionCategory(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	saveResolutionCategoryRequest := &data.SaveResolutionCategoryRequest{}
+	if e := json.Unmarshal(msg.Data, saveResolutionCategoryRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := saveResolutionCategoryRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if e := s.resolutionRepository.Save(ctx, *saveResolutionCategoryRequest.AsResolutionCategory()); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+func (s *IssuerService) listResolutionCategories(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	categories, e := s.resolutionRepository.LoadAll(ctx)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	resolutionCategoriesResponse := &data.ResolutionCategoriesResponse{}
+	resolutionCategoriesResponse.LoadFromResolutionCategories(categories)
+	s.reply(msg, resolutionCategoriesResponse)
+}
+
+func (s *IssuerService) deleteResolutionCategory(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resolutionCategoryRequest := &data.ResolutionCategoryRequest{}
+	if e := json.Unmarshal(msg.Data, resolutionCategoryRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := resolutionCategoryRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if e := s.resolutionRepository.Delete(ctx, resolutionCategoryRequest.Name); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+func (s *IssuerService) reply(msg *nc.Msg, t interface{}) {
+	reply, _ := json.Marshal(t)
+	_ = msg.Respond(reply)
+}
+
+func (s *IssuerService) replyNoContent(msg *nc.Msg) {
+	_ = msg.Respond([]byte(""))
+}
+
+// Stop stops the component and drains its subscriptions, blocking until their in-flight messages are handled.
+func (s *IssuerService) Stop() {
+	s.stop <- struct{}{}
+	<-s.stop
+}
@@ -0,0 +1,803 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/jobs"
+	"github.com/jibitters/kiosk/mailing"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// NotificationService is a service implementation of notification functionalities. It emails ticket owners about the
+// changes of their tickets, alerts agents about the new tickets matching their saved searches, keeps their delivery
+// receipts and manages the maintenance windows during which those emails are held back.
+type NotificationService struct {
+	logger                      *zap.SugaredLogger
+	ticketRepository            TicketRepository
+	issuerSettingsRepository    *models.IssuerSettingsRepository
+	maintenanceWindowRepository *models.MaintenanceWindowRepository
+	notificationRepository      *models.NotificationRepository
+	preferencesRepository       *models.NotificationPreferencesRepository
+	savedSearchRepository       *models.SavedSearchRepository
+	natsClient                  *nc.Conn
+	mailer                      *mailing.Mailer
+	pool                        *jobs.Pool
+	enabled                     bool
+	deduplicationWindow         time.Duration
+	stop                        chan struct{}
+}
+
+// NewNotificationService returns a newly created and ready to use NotificationService. Ticket events are only
+// consumed when enabled is true, maintenance windows are managed regardless. Notifications identical to one sent
+// within the deduplication window, i.e. of the same recipient, ticket and event type, are dropped, a zero window
+// disables deduplication.
+func NewNotificationService(logger *zap.SugaredLogger, db *pgxpool.Pool, natsClient *nc.Conn,
+	mailer *mailing.Mailer, pool *jobs.Pool, enabled bool, deduplicationWindow time.Duration) *NotificationService {
+
+	return &NotificationService{
+		logger:                      logger,
+		ticketRepository:            models.NewTicketRepository(logger, db),
+		issuerSettingsRepository:    models.NewIssuerSettingsRepository(logger, db),
+		maintenanceWindowRepository: models.NewMaintenanceWindowRepository(logger, db),
+		notificationRepository:      models.NewNotificationRepository(logger, db),
+		preferencesRepository:       models.NewNotificationPreferencesRepository(logger, db),
+		savedSearchRepository:       models.NewSavedSearchRepository(logger, db),
+		natsClient:                  natsClient,
+		mailer:                      mailer,
+		pool:                        pool,
+		enabled:                     enabled,
+		deduplicationWindow:         deduplicationWindow,
+		stop:                        make(chan struct{}),
+	}
+}
+
+// Start starts the subscriptions so ready to be notified.
+func (s *NotificationService) Start() error {
+	s.pool.Register(deliverNotificationJob, 30*time.Second, s.deliverJob)
+
+	createWindowSubscription, e := s.natsClient.QueueSubscribe("kiosk.maintenance_windows.create",
+		"kiosk.maintenance_windows.create_group", s.createWindow)
+	if e != nil {
+		return e
+	}
+
+	listWindowsSubscription, e := s.natsClient.QueueSubscribe("kiosk.maintenance_windows.list",
+		"kiosk.maintenance_windows.list_group", s.listWindows)
+	if e != nil {
+		return e
+	}
+
+	deleteWindowSubscription, e := s.natsClient.QueueSubscribe("kiosk.maintenance_windows.delete",
+		"kiosk.maintenance_windows.delete_group", s.deleteWindow)
+	if e != nil {
+		return e
+	}
+
+	listNotificationsSubscription, e := s.natsClient.QueueSubscribe("kiosk.notifications.list",
+		"kiosk.notifications.list_group", s.listNotifications)
+	if e != nil {
+		return e
+	}
+
+	reportBounceSubscription, e := s.natsClient.QueueSubscribe("kiosk.notifications.bounce",
+		"kiosk.notifications.bounce_group", s.reportBounce)
+	if e != nil {
+		return e
+	}
+
+	savePreferencesSubscription, e := s.natsClient.QueueSubscribe("kiosk.notifications.preferences.save",
+		"kiosk.notifications.preferences.save_group", s.savePreferences)
+	if e != nil {
+		return e
+	}
+
+	loadPreferencesSubscription, e := s.natsClient.QueueSubscribe("kiosk.notifications.preferences.load",
+		"kiosk.notifications.preferences.load_group", s.loadPreferences)
+	if e != nil {
+		return e
+	}
+
+	deletePreferencesSubscription, e := s.natsClient.QueueSubscribe("kiosk.notifications.preferences.delete",
+		"kiosk.notifications.preferences.delete_group", s.deletePreferences)
+	if e != nil {
+		return e
+	}
+
+	createSavedSearchSubscription, e := s.natsClient.QueueSubscribe("kiosk.saved_searches.create",
+		"kiosk.saved_searches.create_group", s.createSavedSearch)
+	if e != nil {
+		return e
+	}
+
+	listSavedSearchesSubscription, e := s.natsClient.QueueSubscribe("kiosk.saved_searches.list",
+		"kiosk.saved_searches.list_group", s.listSavedSearches)
+	if e != nil {
+		return e
+	}
+
+	deleteSavedSearchSubscription, e := s.natsClient.QueueSubscribe("kiosk.saved_searches.delete",
+		"kiosk.saved_searches.delete_group", s.deleteSavedSearch)
+	if e != nil {
+		return e
+	}
+
+	subscriptions := []*nc.Subscription{createWindowSubscription, listWindowsSubscription, deleteWindowSubscription,
+		listNotificationsSubscription, reportBounceSubscription, savePreferencesSubscription,
+		loadPreferencesSubscription, deletePreferencesSubscription, createSavedSearchSubscription,
+		listSavedSearchesSubscription, deleteSavedSearchSubscription}
+
+	if s.enabled {
+		eventsSubscription, e := s.natsClient.QueueSubscribe("kiosk.events.>", "kiosk.notifications_group", s.notify)
+		if e != nil {
+			return e
+		}
+
+		subscriptions = append(subscriptions, eventsSubscription)
+	}
+
+	go s.await(subscriptions...)
+
+	return nil
+}
+
+func (s *NotificationService) await(ss ...*nc.Subscription) {
+	<-s.stop
+	s.logger.Debug("NotificationService: received stop signal!")
+
+	drain(s.logger, ss)
+	s.stop <- struct{}{}
+}
+
+func (s *NotificationService) createWindow(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createMaintenanceWindowRequest := &data.CreateMaintenanceWindowRequest{}
+	if e := json.Unmarshal(msg.Data, createMaintenanceWindowRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := createMaintenanceWindowRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	window := createMaintenanceWindowRequest.AsMaintenanceWindow()
+	if _, e := s.maintenanceWindowRepository.Insert(ctx, *window); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+func (s *NotificationService) listWindows(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	windows, e := s.maintenanceWindowRepository.LoadNotEndedYet(ctx, time.Now())
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	maintenanceWindowsResponse := &data.MaintenanceWindowsResponse{}
+	maintenanceWindowsResponse.LoadFromMaintenanceWindows(windows)
+	s.reply(msg, maintenanceWindowsResponse)
+}
+
+func (s *NotificationService) deleteWindow(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	id := &data.ID{}
+	if e := json.Unmarshal(msg.Data, id); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := s.maintenanceWindowRepository.DeleteByID(ctx, id.ID); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+func (s *NotificationService) listNotifications(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	listNotificationsRequest := &data.ListNotificationsRequest{}
+	if e := json.Unmarshal(msg.Data, listNotificationsRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := listNotificationsRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	notifications, e := s.notificationRepository.LoadByTicketID(ctx, listNotificationsRequest.TicketID)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	notificationsResponse := &data.NotificationsResponse{}
+	notificationsResponse.LoadFromNotifications(notifications)
+	s.reply(msg, notificationsResponse)
+}
+
+func (s *NotificationService) reportBounce(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	reportBounceRequest := &data.ReportBounceRequest{}
+	if e := json.Unmarshal(msg.Data, reportBounceRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := reportBounceRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	e := s.notificationRepository.UpdateStatus(ctx, reportBounceRequest.ID, models.NotificationStatusBounced,
+		reportBounceRequest.Reason)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+func (s *NotificationService) savePreferences(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	saveNotificationPreferencesRequest := &data.SaveNotificationPreferencesRequest{}
+	if e := json.Unmarshal(msg.Data, saveNotificationPreferencesRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := saveNotificationPreferencesRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	preferences := saveNotificationPreferencesRequest.AsNotificationPreferences()
+	if e := s.preferencesRepository.Save(ctx, *preferences); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+func (s *NotificationService) loadPreferences(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	recipientRequest := &data.RecipientRequest{}
+	if e := json.Unmarshal(msg.Data, recipientRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := recipientRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	preferences, e := s.preferencesRepository.LoadByRecipient(ctx, recipientRequest.Recipient)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	notificationPreferencesResponse := &data.NotificationPreferencesResponse{}
+	notificationPreferencesResponse.LoadFromNotificationPreferences(preferences)
+	s.reply(msg, notificationPreferencesResponse)
+}
+
+func (s *NotificationService) deletePreferences(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	recipientRequest := &data.RecipientRequest{}
+	if e := json.Unmarshal(msg.Data, recipientRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := recipientRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if e := s.preferencesRepository.DeleteByRecipient(ctx, recipientRequest.Recipient); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+func (s *NotificationService) createSavedSearch(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createSavedSearchRequest := &data.CreateSavedSearchRequest{}
+	if e := json.Unmarshal(msg.Data, createSavedSearchRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := createSavedSearchRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	id, e := s.savedSearchRepository.Insert(ctx, *createSavedSearchRequest.AsSavedSearch())
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.reply(msg, &data.ID{ID: id})
+}
+
+func (s *NotificationService) listSavedSearches(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	savedSearchesRequest := &data.SavedSearchesRequest{}
+	if e := json.Unmarshal(msg.Data, savedSearchesRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := savedSearchesRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	searches, e := s.savedSearchRepository.LoadByAgent(ctx, savedSearchesRequest.Agent)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	savedSearchesResponse := &data.SavedSearchesResponse{}
+	savedSearchesResponse.LoadFromSavedSearches(searches)
+	s.reply(msg, savedSearchesResponse)
+}
+
+func (s *NotificationService) deleteSavedSearch(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	deleteSavedSearchRequest := &data.DeleteSavedSearchRequest{}
+	if e := json.Unmarshal(msg.Data, deleteSavedSearchRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := deleteSavedSearchRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	e := s.savedSearchRepository.DeleteByID(ctx, deleteSavedSearchRequest.ID, deleteSavedSearchRequest.Agent)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+func (s *NotificationService) notify(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	event := &data.Event{}
+	if e := json.Unmarshal(msg.Data, event); e != nil {
+		s.logger.Warn("Could not parse event on ", msg.Subject, ": ", e.Error())
+		return
+	}
+
+	for _, n := range s.compose(ctx, event) {
+		n.EventType = string(event.Type)
+		s.send(ctx, n)
+	}
+}
+
+// send records the notification and enqueues its delivery, unless its recipient does not accept emails, it duplicates
+// a recent one or it is held.
+func (s *NotificationService) send(ctx context.Context, n *models.Notification) {
+	preferences := s.preferencesOf(ctx, n, make(map[string]*models.NotificationPreferences))
+
+	if !preferences.Accepts(models.NotificationChannelEmail) || s.duplicate(ctx, n) {
+		return
+	}
+
+	id, e := s.notificationRepository.Insert(ctx, *n)
+	if e != nil {
+		return
+	}
+	n.ID = id
+
+	// Held notifications stay queued in the table until ResumeSuppressed or DeliverDigests picks them up.
+	windows, e := s.maintenanceWindowRepository.LoadNotEndedYet(ctx, time.Now())
+	if e == nil && held(n, preferences, windows, time.Now()) {
+		return
+	}
+
+	s.enqueueDelivery(ctx, n)
+}
+
+// duplicate checks whether the recipient has been notified about the same type of events of the ticket within the
+// deduplication window, so a rapid series of updates ends up in a single email. Notifications are not dropped when
+// that can not be told.
+func (s *NotificationService) duplicate(ctx context.Context, n *models.Notification) bool {
+	if s.deduplicationWindow <= 0 || n.EventType == "" {
+		return false
+	}
+
+	notified, e := s.notificationRepository.Notified(ctx, n.Recipient, n.TicketID, n.EventType,
+		time.Now().Add(-s.deduplicationWindow))
+
+	return e == nil && notified
+}
+
+// compose builds the notifications of an event, returns nil if the event does not concern the ticket owner.
+// Escalations notify the recipient of their level instead, approvals their approvers and then their requester, and new
+// tickets also alert the agents whose saved searches they match. Ticket owners get the public ids of their tickets.
+func (s *NotificationService) compose(ctx context.Context, event *data.Event) []*models.Notification {
+	switch event.Type {
+	case data.EventTypeTicketCreated:
+		t := event.Ticket
+		id := s.publicID(ctx, t.Issuer, t.ID)
+		ns := append([]*models.Notification{newNotification(t.ID, t.Issuer, t.ImportanceLevel, t.Owner,
+			fmt.Sprintf("Ticket #%v received: %v", id, t.Subject),
+			fmt.Sprintf("We have received your ticket #%v and will get back to you soon.", id))},
+			s.alerts(ctx, t)...)
+
+		return notifications(ns...)
+
+	case data.EventTypeTicketUpdated:
+		t := event.Ticket
+		if event.PreviousStatus == t.Status {
+			return nil
+		}
+
+		id := s.publicID(ctx, t.Issuer, t.ID)
+		return notifications(newNotification(t.ID, t.Issuer, t.ImportanceLevel, t.Owner,
+			fmt.Sprintf("Ticket #%v is %v", id, t.Status),
+			fmt.Sprintf("The status of your ticket #%v changed from %v to %v.", id, event.PreviousStatus, t.Status)))
+
+	case data.EventTypeTicketEscalated:
+		t := event.Ticket
+		if event.Escalation == nil {
+			return nil
+		}
+
+		return notifications(newNotification(t.ID, t.Issuer, t.ImportanceLevel, event.Escalation.Recipient,
+			fmt.Sprintf("Ticket #%d escalated to level %d: %v", t.ID, event.Escalation.Level, t.Subject),
+			fmt.Sprintf("Ticket #%d of %v has been open since %v without being resolved.\n\n%v", t.ID, t.Issuer,
+				t.CreatedAt, t.Content)))
+
+	case data.EventTypeTicketApprovalRequested:
+		t := event.Ticket
+		if event.Approval == nil {
+			return nil
+		}
+
+		ns := make([]*models.Notification, 0, len(event.Approval.Approvers))
+		for _, approver := range event.Approval.Approvers {
+			ns = append(ns, newNotification(t.ID, t.Issuer, t.ImportanceLevel, approver,
+				fmt.Sprintf("Approval requested for ticket #%d: %v", t.ID, t.Subject),
+				fmt.Sprintf("%v requested to move ticket #%d of %v to %v.\n\n%v", event.Approval.RequestedBy, t.ID,
+					t.Issuer, event.Approval.Status, event.Approval.Reason)))
+		}
+
+		return notifications(ns...)
+
+	case data.EventTypeTicketApproved, data.EventTypeTicketRejected:
+		t := event.Ticket
+		if event.Approval == nil {
+			return nil
+		}
+
+		decision := "approved"
+		if event.Type == data.EventTypeTicketRejected {
+			decision = "rejected"
+		}
+
+		return notifications(newNotification(t.ID, t.Issuer, t.ImportanceLevel, event.Approval.RequestedBy,
+			fmt.Sprintf("Moving ticket #%d to %v %v", t.ID, event.Approval.Status, decision),
+			fmt.Sprintf("%v %v moving ticket #%d to %v.\n\n%v", event.Approval.DecidedBy, decision, t.ID,
+				event.Approval.Status, event.Approval.Note)))
+
+	case data.EventTypeCommentCreated:
+		ticket, e := s.ticketRepository.LoadByID(ctx, event.Comment.TicketID)
+		if e != nil || ticket.Owner == event.Comment.Owner {
+			return nil
+		}
+
+		subject := fmt.Sprintf("New reply on ticket #%v", s.publicID(ctx, ticket.Issuer, ticket.ID))
+		return notifications(newNotification(ticket.ID, ticket.Issuer, ticket.ImportanceLevel, ticket.Owner, subject,
+			event.Comment.Content))
+	}
+
+	return nil
+}
+
+// publicID returns back the id of the ticket as exposed to its owner, falling back to the plain id when the settings
+// of its issuer can not be loaded.
+func (s *NotificationService) publicID(ctx context.Context, issuer string, id int64) string {
+	settings, e := s.issuerSettingsRepository.LoadByIssuer(ctx, issuer)
+	if e != nil {
+		return strconv.FormatInt(id, 10)
+	}
+
+	return settings.PublicTicketID(id)
+}
+
+// alerts builds the notifications of the agents whose saved searches match the new ticket, one per agent no matter how
+// many of their searches it matches. The ticket owner is never alerted about their own ticket.
+func (s *NotificationService) alerts(ctx context.Context, t *data.TicketResponse) []*models.Notification {
+	searches, e := s.savedSearchRepository.LoadMatching(ctx, t.Issuer, t.ImportanceLevel, t.Subject+"\n"+t.Content)
+	if e != nil {
+		return nil
+	}
+
+	alerted := map[string]bool{t.Owner: true}
+	ns := make([]*models.Notification, 0, len(searches))
+	for _, search := range searches {
+		if alerted[search.Agent] {
+			continue
+		}
+		alerted[search.Agent] = true
+
+		ns = append(ns, newNotification(t.ID, t.Issuer, t.ImportanceLevel, search.Agent,
+			fmt.Sprintf("Ticket #%d matches your saved search %v: %v", t.ID, search.Name, t.Subject),
+			fmt.Sprintf("A new %v ticket #%d of %v matches your saved search %v.\n\n%v", t.ImportanceLevel, t.ID,
+				t.Issuer, search.Name, t.Content)))
+	}
+
+	return ns
+}
+
+// notifications returns back the provided notifications, leaving out the nil ones.
+func notifications(ns ...*models.Notification) []*models.Notification {
+	composed := make([]*models.Notification, 0, len(ns))
+	for _, n := range ns {
+		if n != nil {
+			composed = append(composed, n)
+		}
+	}
+
+	return composed
+}
+
+// newNotification returns nil when the recipient is not an email address, e.g. an internal user identifier.
+func newNotification(ticketID int64, issuer string, importanceLevel models.TicketImportanceLevel, recipient, subject,
+	body string) *models.Notification {
+
+	if _, e := mail.ParseAddress(recipient); e != nil {
+		return nil
+	}
+
+	return &models.Notification{TicketID: ticketID, Issuer: issuer, Recipient: recipient, Subject: subject, Body: body,
+		ImportanceLevel: importanceLevel}
+}
+
+// held checks whether the notification should not be delivered individually at the provided time.
+func held(n *models.Notification, preferences *models.NotificationPreferences, windows []*models.MaintenanceWindow,
+	at time.Time) bool {
+
+	return digestible(n, preferences) || preferences.InQuietHours(at) || suppressedBy(windows, n.Issuer, at)
+}
+
+// digestible checks whether the notification waits for the next digest of its recipient. High priority notifications
+// are never batched.
+func digestible(n *models.Notification, preferences *models.NotificationPreferences) bool {
+	return preferences.Mode == models.NotificationModeDigest && n.IsLowPriority()
+}
+
+func suppressedBy(windows []*models.MaintenanceWindow, issuer string, at time.Time) bool {
+	for _, window := range windows {
+		if window.Covers(issuer, at) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ResumeSuppressed is a scheduler job that delivers the notifications held back by maintenance windows or quiet hours
+// which have ended since.
+func (s *NotificationService) ResumeSuppressed(ctx context.Context, now time.Time) {
+	// Notifications queued within the last minute may still be in the middle of their first delivery attempt.
+	queued, windows, ok := s.loadQueued(ctx, now.Add(-time.Minute), now)
+	if !ok {
+		return
+	}
+
+	preferences := make(map[string]*models.NotificationPreferences)
+	for _, n := range queued {
+		p := s.preferencesOf(ctx, n, preferences)
+		if held(n, p, windows, now) {
+			continue
+		}
+
+		s.enqueueDelivery(ctx, n)
+	}
+}
+
+// DeliverDigests is a scheduler job that runs hourly and batches the queued low priority notifications of each
+// recipient in digest mode into a single summary email, once their hourly or daily digest is due.
+func (s *NotificationService) DeliverDigests(ctx context.Context, now time.Time) {
+	queued, windows, ok := s.loadQueued(ctx, now, now)
+	if !ok {
+		return
+	}
+
+	preferences := make(map[string]*models.NotificationPreferences)
+	digests := make(map[string][]*models.Notification)
+	recipients := make([]string, 0)
+	for _, n := range queued {
+		p := s.preferencesOf(ctx, n, preferences)
+		if !digestible(n, p) || !p.DigestDue(now) || p.InQuietHours(now) || suppressedBy(windows, n.Issuer, now) {
+			continue
+		}
+
+		if _, ok := digests[n.Recipient]; !ok {
+			recipients = append(recipients, n.Recipient)
+		}
+		digests[n.Recipient] = append(digests[n.Recipient], n)
+	}
+
+	for _, recipient := range recipients {
+		s.deliverDigest(ctx, recipient, digests[recipient])
+	}
+}
+
+func (s *NotificationService) loadQueued(ctx context.Context, before, now time.Time) ([]*models.Notification,
+	[]*models.MaintenanceWindow, bool) {
+
+	queued, e := s.notificationRepository.LoadQueuedBefore(ctx, before)
+	if e != nil || len(queued) == 0 {
+		return nil, nil, false
+	}
+
+	windows, e := s.maintenanceWindowRepository.LoadNotEndedYet(ctx, now)
+	if e != nil {
+		return nil, nil, false
+	}
+
+	return queued, windows, true
+}
+
+// preferencesOf resolves the preferences of the notification recipient, once per recipient and issuer in a job run.
+func (s *NotificationService) preferencesOf(ctx context.Context, n *models.Notification,
+	cache map[string]*models.NotificationPreferences) *models.NotificationPreferences {
+
+	key := n.Recipient + "\x00" + n.Issuer
+	if p, ok := cache[key]; ok {
+		return p
+	}
+
+	p, e := s.preferencesRepository.Resolve(ctx, n.Recipient, n.Issuer)
+	if e != nil {
+		p = models.DefaultNotificationPreferences(n.Recipient)
+	}
+
+	cache[key] = p
+	return p
+}
+
+// deliverNotificationJob is the kind of jobs delivering a single notification.
+const deliverNotificationJob = "notifications.deliver"
+
+// enqueueDelivery enqueues the delivery of the notification. Enqueuing it again while its delivery is pending has no
+// effect.
+func (s *NotificationService) enqueueDelivery(ctx context.Context, n *models.Notification) {
+	key := deliverNotificationJob + ":" + strconv.FormatInt(n.ID, 10)
+	_, _ = s.pool.Enqueue(ctx, deliverNotificationJob, key, &data.ID{ID: n.ID})
+}
+
+// deliverJob sends a notification and records the outcome as its delivery status. Failed deliveries are retried by
+// the jobs pool, except bounces which would fail again.
+func (s *NotificationService) deliverJob(ctx context.Context, payload []byte) error {
+	id := &data.ID{}
+	if e := json.Unmarshal(payload, id); e != nil {
+		return e
+	}
+
+	n, et := s.notificationRepository.LoadByID(ctx, id.ID)
+	if et != nil {
+		if et.Kind == errors.KindNotFound {
+			return nil
+		}
+
+		return et
+	}
+
+	if n.Status != models.NotificationStatusQueued && n.Status != models.NotificationStatusFailed {
+		return nil
+	}
+
+	e := s.mailer.Send([]string{n.Recipient}, n.Subject, n.Body)
+	s.record(ctx, n.Recipient, []*models.Notification{n}, e)
+
+	if e != nil && !mailing.IsBounce(e) {
+		return e
+	}
+
+	return nil
+}
+
+func (s *NotificationService) deliverDigest(ctx context.Context, recipient string, ns []*models.Notification) {
+	body := &strings.Builder{}
+	for _, n := range ns {
+		fmt.Fprintf(body, "%v\n%v\n\n", n.Subject, n.Body)
+	}
+
+	subject := fmt.Sprintf("%d updates on your tickets", len(ns))
+	e := s.mailer.Send([]string{recipient}, subject, body.String())
+	s.record(ctx, recipient, ns, e)
+}
+
+// record stores the outcome of sending the notifications as their delivery status.
+func (s *NotificationService) record(ctx context.Context, recipient string, ns []*models.Notification, e error) {
+	status, failure := models.NotificationStatusSent, ""
+	if e != nil {
+		s.logger.Warn("Could not notify ", recipient, ": ", e.Error())
+
+		status, failure = models.NotificationStatusFailed, e.Error()
+		if mailing.IsBounce(e) {
+			status = models.NotificationStatusBounced
+		}
+	}
+
+	for _, n := range ns {
+		n.Status, n.Failure = status, failure
+	}
+
+	_ = s.notificationRepository.UpdateStatuses(ctx, ns)
+}
+
+func (s *NotificationService) reply(msg *nc.Msg, t interface{}) {
+	reply, _ := json.Marshal(t)
+	_ = msg.Respond(reply)
+}
+
+func (s *NotificationService) replyNoContent(msg *nc.Msg) {
+	_ = msg.Respond([]byte(""))
+}
+
+// Stop stops the component and drains its subscriptions, blocking until their in-flight messages are handled.
+func (s *NotificationService) Stop() {
+	s.stop <- struct{}{}
+	<-s.stop
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/pagination"
+)
+
+// Lists page tokens are bound to, so tokens of one list can not page another.
+const (
+	ticketsPageList         = "tickets"
+	commentsPageList        = "comments"
+	ticketRevisionsPageList = "ticket_revisions"
+	attachmentsPageList     = "attachments"
+	macrosPageList          = "macros"
+	auditLogsPageList       = "audit_logs"
+)
+
+// pageCursor returns back the cursor held by the page token of the list ordered by orders, or nil when there is no
+// token. Tokens not issued for the list and orders are not valid.
+func pageCursor(tokens *pagination.Tokens, token, list string, orders []models.Order) (models.Cursor, *errors.Type) {
+	if token == "" {
+		return nil, nil
+	}
+
+	cursor, ok := tokens.Verify(token, list, orders)
+	if !ok {
+		return nil, errors.InvalidArgument("pageToken.not_valid", "")
+	}
+
+	return cursor, nil
+}
@@ -0,0 +1,465 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/connectors"
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/jobs"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// Kinds of paging jobs, paging goes through the jobs pool so unreachable providers get retried.
+const (
+	triggerPageJob = "paging.trigger"
+	resolvePageJob = "paging.resolve"
+)
+
+// PagingService is a service implementation of paging functionalities. It pages the on-call staff of issuers with a
+// paging policy when their critical tickets arrive outside business hours, and resolves the incident once the ticket
+// gets resolved, closed or deleted. Such tickets are assigned to the agent on call in the rota of the issuer, if any.
+type PagingService struct {
+	logger                 *zap.SugaredLogger
+	ticketRepository       TicketRepository
+	pagingPolicyRepository *models.PagingPolicyRepository
+	ticketPageRepository   *models.TicketPageRepository
+	onCallRepository       *models.OnCallRepository
+	natsClient             *nc.Conn
+	pool                   *jobs.Pool
+	pagers                 map[models.PagingProvider]connectors.Pager
+	stop                   chan struct{}
+}
+
+// NewPagingService returns a newly created and ready to use PagingService.
+func NewPagingService(logger *zap.SugaredLogger, db *pgxpool.Pool, natsClient *nc.Conn, pool *jobs.Pool,
+	pagers map[models.PagingProvider]connectors.Pager) *PagingService {
+
+	return &PagingService{
+		logger:                 logger,
+		ticketRepository:       models.NewTicketRepository(logger, db),
+		pagingPolicyRepository: models.NewPagingPolicyRepository(logger, db),
+		ticketPageRepository:   models.NewTicketPageRepository(logger, db),
+		onCallRepository:       models.NewOnCallRepository(logger, db),
+		natsClient:             natsClient,
+		pool:                   pool,
+		pagers:                 pagers,
+		stop:                   make(chan struct{}),
+	}
+}
+
+// Start starts the subscriptions so ready to be notified.
+func (s *PagingService) Start() error {
+	s.pool.Register(triggerPageJob, 30*time.Second, s.triggerJob)
+	s.pool.Register(resolvePageJob, 30*time.Second, s.resolveJob)
+
+	savePolicySubscription, e := s.natsClient.QueueSubscribe("kiosk.issuers.paging.save",
+		"kiosk.issuers.paging.save_group", s.savePolicy)
+	if e != nil {
+		return e
+	}
+
+	loadPolicySubscription, e := s.natsClient.QueueSubscribe("kiosk.issuers.paging.load",
+		"kiosk.issuers.paging.load_group", s.loadPolicy)
+	if e != nil {
+		return e
+	}
+
+	deletePolicySubscription, e := s.natsClient.QueueSubscribe("kiosk.issuers.paging.delete",
+		"kiosk.issuers.paging.delete_group", s.deletePolicy)
+	if e != nil {
+		return e
+	}
+
+	createShiftSubscription, e := s.natsClient.QueueSubscribe("kiosk.issuers.on_call.create",
+		"kiosk.issuers.on_call.create_group", s.createShift)
+	if e != nil {
+		return e
+	}
+
+	listShiftsSubscription, e := s.natsClient.QueueSubscribe("kiosk.issuers.on_call.list",
+		"kiosk.issuers.on_call.list_group", s.listShifts)
+	if e != nil {
+		return e
+	}
+
+	deleteShiftSubscription, e := s.natsClient.QueueSubscribe("kiosk.issuers.on_call.delete",
+		"kiosk.issuers.on_call.delete_group", s.deleteShift)
+	if e != nil {
+		return e
+	}
+
+	ticketCreatedSubscription, e := s.natsClient.QueueSubscribe(ticketCreatedSubject, "kiosk.paging_group",
+		s.onTicketCreated)
+	if e != nil {
+		return e
+	}
+
+	ticketUpdatedSubscription, e := s.natsClient.QueueSubscribe(ticketUpdatedSubject, "kiosk.paging_group",
+		s.onTicketUpdated)
+	if e != nil {
+		return e
+	}
+
+	ticketDeletedSubscription, e := s.natsClient.QueueSubscribe(ticketDeletedSubject, "kiosk.paging_group",
+		s.onTicketDeleted)
+	if e != nil {
+		return e
+	}
+
+	go s.await(savePolicySubscription, loadPolicySubscription, deletePolicySubscription, createShiftSubscription,
+		listShiftsSubscription, deleteShiftSubscription, ticketCreatedSubscription, ticketUpdatedSubscription,
+		ticketDeletedSubscription)
+
+	return nil
+}
+
+func (s *PagingService) await(ss ...*nc.Subscription) {
+	<-s.stop
+	s.logger.Debug("PagingService: received stop signal!")
+
+	drain(s.logger, ss)
+	s.stop <- struct{}{}
+}
+
+func (s *PagingService) savePolicy(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	savePagingPolicyRequest := &data.SavePagingPolicyRequest{}
+	if e := json.Unmarshal(msg.Data, savePagingPolicyRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := savePagingPolicyRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if e := s.pagingPolicyRepository.Save(ctx, *savePagingPolicyRequest.AsPagingPolicy()); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+func (s *PagingService) loadPolicy(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	issuerRequest := &data.IssuerRequest{}
+	if e := json.Unmarshal(msg.Data, issuerRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := issuerRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	policy, e := s.pagingPolicyRepository.LoadByIssuer(ctx, issuerRequest.Issuer)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	pagingPolicyResponse := &data.PagingPolicyResponse{}
+	pagingPolicyResponse.LoadFromPagingPolicy(policy)
+	s.reply(msg, pagingPolicyResponse)
+}
+
+func (s *PagingService) deletePolicy(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	issuerRequest := &data.IssuerRequest{}
+	if e := json.Unmarshal(msg.Data, issuerRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := issuerRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if e := s.pagingPolicyRepository.DeleteByIssuer(ctx, issuerRequest.Issuer); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+func (s *PagingService) createShift(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createOnCallShiftRequest := &data.CreateOnCallShiftRequest{}
+	if e := json.Unmarshal(msg.Data, createOnCallShiftRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := createOnCallShiftRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	id, e := s.onCallRepository.Insert(ctx, *createOnCallShiftRequest.AsOnCallShift())
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.reply(msg, &data.ID{ID: id})
+}
+
+// listShifts replies the shifts of the rota of an issuer that have not ended yet.
+func (s *PagingService) listShifts(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	issuerRequest := &data.IssuerRequest{}
+	if e := json.Unmarshal(msg.Data, issuerRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := issuerRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	shifts, e := s.onCallRepository.LoadByIssuer(ctx, issuerRequest.Issuer, time.Now())
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	onCallShiftsResponse := &data.OnCallShiftsResponse{}
+	onCallShiftsResponse.LoadFromOnCallShifts(shifts)
+	s.reply(msg, onCallShiftsResponse)
+}
+
+func (s *PagingService) deleteShift(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	deleteOnCallShiftRequest := &data.DeleteOnCallShiftRequest{}
+	if e := json.Unmarshal(msg.Data, deleteOnCallShiftRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := deleteOnCallShiftRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	e := s.onCallRepository.Delete(ctx, deleteOnCallShiftRequest.Issuer, deleteOnCallShiftRequest.ID)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+// onTicketCreated pages about critical tickets that arrive outside the business hours of their issuer, and assigns
+// them to the agent on call.
+func (s *PagingService) onTicketCreated(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	event := &data.Event{}
+	if e := json.Unmarshal(msg.Data, event); e != nil || event.Ticket == nil {
+		return
+	}
+
+	if event.Ticket.ImportanceLevel != models.TicketImportanceLevelCritical {
+		return
+	}
+
+	policy, e := s.pagingPolicyRepository.LoadByIssuer(ctx, event.Ticket.Issuer)
+	if e != nil || policy.InBusinessHours(time.Now()) {
+		return
+	}
+
+	s.assignToOnCall(ctx, event.Ticket.ID, event.Ticket.Issuer)
+
+	key := triggerPageJob + ":" + strconv.FormatInt(event.Ticket.ID, 10)
+	_, _ = s.pool.Enqueue(ctx, triggerPageJob, key, &data.ID{ID: event.Ticket.ID})
+}
+
+// assignToOnCall assigns the ticket to the agent on call for its issuer right now, unless no shift covers it or the
+// ticket has already been claimed.
+func (s *PagingService) assignToOnCall(ctx context.Context, id int64, issuer string) {
+	agent, e := s.onCallRepository.Current(ctx, issuer, time.Now())
+	if e != nil || agent == "" {
+		return
+	}
+
+	previous, e := s.ticketRepository.Assign(ctx, id, agent, false)
+	if e != nil || previous == agent {
+		return
+	}
+
+	ticket, e := s.ticketRepository.LoadByID(ctx, id)
+	if e != nil {
+		return
+	}
+
+	ticketResponse := &data.TicketResponse{}
+	ticketResponse.LoadFromTicket(ticket)
+	publishEvent(s.logger, s.natsClient, ticketAssignedSubject, &data.Event{Type: data.EventTypeTicketAssigned,
+		Ticket: ticketResponse})
+}
+
+// onTicketUpdated resolves the incidents of tickets that got resolved or closed.
+func (s *PagingService) onTicketUpdated(msg *nc.Msg) {
+	event := &data.Event{}
+	if e := json.Unmarshal(msg.Data, event); e != nil || event.Ticket == nil {
+		return
+	}
+
+	if event.Ticket.Status == models.TicketStatusResolved || event.Ticket.Status == models.TicketStatusClosed {
+		s.enqueueResolve(event.Ticket.ID)
+	}
+}
+
+// onTicketDeleted resolves the incidents of deleted tickets.
+func (s *PagingService) onTicketDeleted(msg *nc.Msg) {
+	event := &data.Event{}
+	if e := json.Unmarshal(msg.Data, event); e != nil || event.Ticket == nil {
+		return
+	}
+
+	s.enqueueResolve(event.Ticket.ID)
+}
+
+// enqueueResolve enqueues resolving the incident of the ticket, unless the ticket was never paged or its incident is
+// already resolved.
+func (s *PagingService) enqueueResolve(ticketID int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	page, e := s.ticketPageRepository.LoadByTicketID(ctx, ticketID)
+	if e != nil || page == nil || page.ResolvedAt != nil {
+		return
+	}
+
+	key := resolvePageJob + ":" + strconv.FormatInt(ticketID, 10)
+	_, _ = s.pool.Enqueue(ctx, resolvePageJob, key, &data.ID{ID: ticketID})
+}
+
+// triggerJob opens the incident of a ticket through the provider of its issuer and records the page. Tickets that got
+// resolved meanwhile are not paged about.
+func (s *PagingService) triggerJob(ctx context.Context, payload []byte) error {
+	id := &data.ID{}
+	if e := json.Unmarshal(payload, id); e != nil {
+		return e
+	}
+
+	ticket, et := s.ticketRepository.LoadByID(ctx, id.ID)
+	if et != nil {
+		if et.Kind == errors.KindNotFound {
+			return nil
+		}
+
+		return et
+	}
+
+	if ticket.Status == models.TicketStatusResolved || ticket.Status == models.TicketStatusClosed {
+		return nil
+	}
+
+	policy, et := s.pagingPolicyRepository.LoadByIssuer(ctx, ticket.Issuer)
+	if et != nil {
+		if et.Kind == errors.KindNotFound {
+			return nil
+		}
+
+		return et
+	}
+
+	pager, ok := s.pagers[policy.Provider]
+	if !ok {
+		return fmt.Errorf("no pager for %v", policy.Provider)
+	}
+
+	incident := connectors.Incident{
+		DedupKey: "kiosk-ticket-" + strconv.FormatInt(ticket.ID, 10),
+		Summary:  fmt.Sprintf("Critical kiosk ticket #%d of %v: %v", ticket.ID, ticket.Issuer, ticket.Subject),
+		Details:  ticket.Content,
+	}
+
+	if e := pager.Trigger(ctx, policy.RoutingKey, incident); e != nil {
+		return e
+	}
+
+	page := models.TicketPage{TicketID: ticket.ID, Provider: policy.Provider, RoutingKey: policy.RoutingKey,
+		DedupKey: incident.DedupKey, PagedAt: time.Now()}
+	if et := s.ticketPageRepository.Insert(ctx, page); et != nil {
+		return et
+	}
+
+	return nil
+}
+
+// resolveJob resolves the incident of a ticket through the provider it was opened at.
+func (s *PagingService) resolveJob(ctx context.Context, payload []byte) error {
+	id := &data.ID{}
+	if e := json.Unmarshal(payload, id); e != nil {
+		return e
+	}
+
+	page, et := s.ticketPageRepository.LoadByTicketID(ctx, id.ID)
+	if et != nil {
+		return et
+	}
+
+	if page == nil || page.ResolvedAt != nil {
+		return nil
+	}
+
+	pager, ok := s.pagers[page.Provider]
+	if !ok {
+		return fmt.Errorf("no pager for %v", page.Provider)
+	}
+
+	if e := pager.Resolve(ctx, page.RoutingKey, page.DedupKey); e != nil {
+		return e
+	}
+
+	if et := s.ticketPageRepository.MarkResolved(ctx, page.TicketID, time.Now()); et != nil {
+		return et
+	}
+
+	return nil
+}
+
+func (s *PagingService) reply(msg *nc.Msg, t interface{}) {
+	reply, _ := json.Marshal(t)
+	_ = msg.Respond(reply)
+}
+
+func (s *PagingService) replyNoContent(msg *nc.Msg) {
+	_ = msg.Respond([]byte(""))
+}
+
+// Stop stops the component and drains its subscriptions, blocking until their in-flight messages are handled.
+func (s *PagingService) Stop() {
+	s.stop <- struct{}{}
+	<-s.stop
+}
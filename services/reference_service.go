@@ -0,0 +1,472 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/connectors"
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// ReferenceService is a service implementation of ticket references functionalities. It links tickets to the issues
+// they escalated to in external systems and, for references attached with sync, keeps the state of those issues and
+// the status of their tickets in sync through the configured connectors. Tickets can also be escalated to new GitHub
+// or Jira issues whose transitions and comments are reported back through webhooks.
+type ReferenceService struct {
+	logger              *zap.SugaredLogger
+	ticketRepository    TicketRepository
+	commentRepository   CommentRepository
+	referenceRepository *models.TicketReferenceRepository
+	natsClient          *nc.Conn
+	connectors          map[models.ReferenceSystem]connectors.Connector
+	stop                chan struct{}
+}
+
+// NewReferenceService returns a newly created and ready to use ReferenceService.
+func NewReferenceService(logger *zap.SugaredLogger, db *pgxpool.Pool, natsClient *nc.Conn,
+	connectors map[models.ReferenceSystem]connectors.Connector) *ReferenceService {
+
+	return &ReferenceService{
+		logger:              logger,
+		ticketRepository:    models.NewTicketRepository(logger, db),
+		commentRepository:   models.NewCommentRepository(logger, db),
+		referenceRepository: models.NewTicketReferenceRepository(logger, db),
+		natsClient:          natsClient,
+		connectors:          connectors,
+		stop:                make(chan struct{}),
+	}
+}
+
+// Start starts the subscriptions so ready to be notified.
+func (s *ReferenceService) Start() error {
+	attachReferenceSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.references.attach",
+		"kiosk.tickets.references.attach_group", s.attach)
+	if e != nil {
+		return e
+	}
+
+	listReferencesSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.references.list",
+		"kiosk.tickets.references.list_group", s.list)
+	if e != nil {
+		return e
+	}
+
+	detachReferenceSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.references.detach",
+		"kiosk.tickets.references.detach_group", s.detach)
+	if e != nil {
+		return e
+	}
+
+	escalateToGitHubSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.escalate.github",
+		"kiosk.tickets.escalate.github_group", s.escalate(models.ReferenceSystemGitHub))
+	if e != nil {
+		return e
+	}
+
+	escalateToJiraSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.escalate.jira",
+		"kiosk.tickets.escalate.jira_group", s.escalate(models.ReferenceSystemJira))
+	if e != nil {
+		return e
+	}
+
+	gitHubWebhookSubscription, e := s.natsClient.QueueSubscribe("kiosk.webhooks.github",
+		"kiosk.webhooks.github_group", s.onWebhook(models.ReferenceSystemGitHub))
+	if e != nil {
+		return e
+	}
+
+	jiraWebhookSubscription, e := s.natsClient.QueueSubscribe("kiosk.webhooks.jira", "kiosk.webhooks.jira_group",
+		s.onWebhook(models.ReferenceSystemJira))
+	if e != nil {
+		return e
+	}
+
+	ticketUpdatedSubscription, e := s.natsClient.QueueSubscribe(ticketUpdatedSubject, "kiosk.references_group",
+		s.onTicketUpdated)
+	if e != nil {
+		return e
+	}
+
+	go s.await(attachReferenceSubscription, listReferencesSubscription, detachReferenceSubscription,
+		escalateToGitHubSubscription, escalateToJiraSubscription, gitHubWebhookSubscription, jiraWebhookSubscription,
+		ticketUpdatedSubscription)
+
+	return nil
+}
+
+func (s *ReferenceService) await(ss ...*nc.Subscription) {
+	<-s.stop
+	s.logger.Debug("ReferenceService: received stop signal!")
+
+	drain(s.logger, ss)
+	s.stop <- struct{}{}
+}
+
+func (s *ReferenceService) attach(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	attachReferenceRequest := &data.AttachReferenceRequest{}
+	if e := json.Unmarshal(msg.Data, attachReferenceRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := attachReferenceRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if _, ok := s.connectors[attachReferenceRequest.System]; attachReferenceRequest.Sync && !ok {
+		s.reply(msg, errors.InvalidArgument("system.not_synchronizable", ""))
+		return
+	}
+
+	if _, e := s.ticketRepository.LoadByID(ctx, attachReferenceRequest.TicketID); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	reference := attachReferenceRequest.AsTicketReference()
+	id, e := s.referenceRepository.Insert(ctx, *reference)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.reply(msg, &data.ID{ID: id})
+}
+
+func (s *ReferenceService) list(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	listReferencesRequest := &data.ListReferencesRequest{}
+	if e := json.Unmarshal(msg.Data, listReferencesRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	references, e := s.referenceRepository.LoadByTicketID(ctx, listReferencesRequest.TicketID)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	ticketReferencesResponse := &data.TicketReferencesResponse{}
+	ticketReferencesResponse.LoadFromTicketReferences(references)
+	s.reply(msg, ticketReferencesResponse)
+}
+
+func (s *ReferenceService) detach(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	id := &data.ID{}
+	if e := json.Unmarshal(msg.Data, id); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := s.referenceRepository.DeleteByID(ctx, id.ID); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+// escalate returns back the handler opening an issue in the external system for a ticket and attaching it to the
+// ticket as a synchronized reference.
+func (s *ReferenceService) escalate(system models.ReferenceSystem) nc.MsgHandler {
+	return func(msg *nc.Msg) {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+
+		escalateTicketRequest := &data.EscalateTicketRequest{}
+		if e := json.Unmarshal(msg.Data, escalateTicketRequest); e != nil {
+			s.reply(msg, errors.InvalidRequestBody())
+			return
+		}
+
+		if e := escalateTicketRequest.Validate(); e != nil {
+			s.reply(msg, e)
+			return
+		}
+
+		escalator, ok := s.connectors[system].(connectors.Escalator)
+		if !ok {
+			s.reply(msg, errors.PreconditionFailed("system.not_configured", ""))
+			return
+		}
+
+		ticket, e := s.ticketRepository.LoadByID(ctx, escalateTicketRequest.TicketID)
+		if e != nil {
+			s.reply(msg, e)
+			return
+		}
+
+		// The customer's identity stays in kiosk, only the problem is handed over.
+		escalation := connectors.Escalation{
+			Target:     escalateTicketRequest.Target,
+			Title:      fmt.Sprintf("[kiosk #%d] %v", ticket.ID, ticket.Subject),
+			Body:       fmt.Sprintf("%v\n\n---\nEscalated from kiosk ticket #%d.", ticket.Content, ticket.ID),
+			Importance: ticket.ImportanceLevel,
+		}
+
+		externalKey, url, err := escalator.Escalate(ctx, escalation)
+		if err != nil {
+			s.logger.Warn("Could not escalate ticket ", ticket.ID, " to ", system, ": ", err.Error())
+			s.reply(msg, errors.Dependency("escalation.failed", "").Wrap(err))
+			return
+		}
+
+		reference := &models.TicketReference{
+			TicketID:    ticket.ID,
+			System:      system,
+			URL:         url,
+			ExternalKey: externalKey,
+			SyncStatus:  models.ReferenceSyncStatusSynced,
+		}
+
+		id, e := s.referenceRepository.Insert(ctx, *reference)
+		if e != nil {
+			s.logger.Error("Escalated ticket ", ticket.ID, " to ", url, " but could not attach it: ", e.Error())
+			s.reply(msg, e)
+			return
+		}
+
+		reference.ID = id
+		reference.CreatedAt = time.Now()
+		reference.ModifiedAt = reference.CreatedAt
+
+		ticketReferenceResponse := &data.TicketReferenceResponse{}
+		ticketReferenceResponse.LoadFromTicketReference(reference)
+		s.reply(msg, ticketReferenceResponse)
+	}
+}
+
+// onWebhook returns back the handler applying the changes the external system reports about synchronized issues to
+// their tickets. Closed issues resolve their tickets, reopened issues reopen their resolved tickets and comments of
+// issues are added as comments of their tickets. Comments of kiosk are never pushed to external systems, so comments
+// can not echo back and forth.
+func (s *ReferenceService) onWebhook(system models.ReferenceSystem) nc.MsgHandler {
+	return func(msg *nc.Msg) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		webhookRequest := &data.WebhookRequest{}
+		if e := json.Unmarshal(msg.Data, webhookRequest); e != nil {
+			s.reply(msg, errors.InvalidRequestBody())
+			return
+		}
+
+		receiver, ok := s.connectors[system].(connectors.WebhookReceiver)
+		if !ok {
+			s.reply(msg, errors.PreconditionFailed("system.not_configured", ""))
+			return
+		}
+
+		event, err := receiver.ReceiveWebhook(webhookRequest.Event, webhookRequest.Signature, webhookRequest.Payload)
+		if err != nil {
+			s.logger.Warn("Rejected ", system, " webhook: ", err.Error())
+			s.reply(msg, errors.Unauthorized(""))
+			return
+		}
+
+		if event == nil {
+			s.replyNoContent(msg)
+			return
+		}
+
+		references, e := s.referenceRepository.LoadSynchronizedByExternalKey(ctx, system, event.ExternalKey)
+		if e != nil {
+			s.reply(msg, e)
+			return
+		}
+
+		for _, reference := range references {
+			switch event.State {
+			case connectors.IssueStateClosed:
+				s.resolve(ctx, reference)
+			case connectors.IssueStateOpen:
+				s.reopen(ctx, reference)
+			}
+
+			if event.Comment != nil {
+				s.comment(ctx, reference, event.Comment)
+			}
+		}
+
+		s.replyNoContent(msg)
+	}
+}
+
+// comment adds the comment of an external issue to the ticket of its reference, skipping the ones kiosk can not hold.
+func (s *ReferenceService) comment(ctx context.Context, reference *models.TicketReference,
+	webhookComment *connectors.WebhookComment) {
+
+	createCommentRequest := &data.CreateCommentRequest{
+		TicketID:   reference.TicketID,
+		Owner:      strings.ToLower(string(reference.System)) + ":" + webhookComment.Author,
+		Content:    webhookComment.Body,
+		AuthorType: models.CommentAuthorTypeAgent,
+		Source:     models.CommentSourceAPI,
+	}
+
+	if e := createCommentRequest.Validate(); e != nil {
+		s.logger.Warn("Skipped ", reference.System, " comment of ", webhookComment.Author, " on ticket ",
+			reference.TicketID, ": ", e.Error())
+		return
+	}
+
+	comment := createCommentRequest.AsComment()
+	id, e := s.commentRepository.Insert(ctx, *comment)
+	if e != nil {
+		return
+	}
+
+	comment.ID = id
+	comment.CreatedAt = time.Now()
+	comment.ModifiedAt = comment.CreatedAt
+	publishCommentEvent(s.logger, s.natsClient, commentCreatedSubject, data.EventTypeCommentCreated, comment)
+}
+
+// onTicketUpdated closes the synchronized external issues of tickets that got resolved or closed, and reopens them
+// when their tickets are reopened.
+func (s *ReferenceService) onTicketUpdated(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	event := &data.Event{}
+	if e := json.Unmarshal(msg.Data, event); e != nil || event.Ticket == nil {
+		return
+	}
+
+	state := issueStateOf(event.Ticket.Status)
+	if event.PreviousStatus == "" || issueStateOf(event.PreviousStatus) == state {
+		return
+	}
+
+	references, e := s.referenceRepository.LoadByTicketID(ctx, event.Ticket.ID)
+	if e != nil {
+		return
+	}
+
+	for _, reference := range references {
+		connector, ok := s.connectors[reference.System]
+		if !ok || reference.SyncStatus == models.ReferenceSyncStatusNone {
+			continue
+		}
+
+		s.record(ctx, reference, connector.SetState(ctx, reference.ExternalKey, state))
+	}
+}
+
+// SyncReferences is a scheduler job that pulls the state of synchronized external issues and resolves the open tickets
+// whose issues got closed.
+func (s *ReferenceService) SyncReferences(ctx context.Context, _ time.Time) {
+	references, e := s.referenceRepository.LoadSynchronized(ctx)
+	if e != nil {
+		return
+	}
+
+	for _, reference := range references {
+		connector, ok := s.connectors[reference.System]
+		if !ok {
+			continue
+		}
+
+		state, err := connector.State(ctx, reference.ExternalKey)
+		s.record(ctx, reference, err)
+
+		if err == nil && state == connectors.IssueStateClosed {
+			s.resolve(ctx, reference)
+		}
+	}
+}
+
+// resolve resolves the ticket of the reference if it is still open.
+func (s *ReferenceService) resolve(ctx context.Context, reference *models.TicketReference) {
+	s.transition(ctx, reference, func(status models.TicketStatus) bool {
+		return issueStateOf(status) == connectors.IssueStateOpen
+	}, models.TicketStatusResolved)
+}
+
+// reopen puts the ticket back into the queue if it is resolved. Closed tickets are final and stay closed.
+func (s *ReferenceService) reopen(ctx context.Context, reference *models.TicketReference) {
+	s.transition(ctx, reference, func(status models.TicketStatus) bool {
+		return status == models.TicketStatusResolved
+	}, models.TicketStatusNew)
+}
+
+// transition moves the ticket of the reference into the provided status if its current status allows. The change is
+// attributed to the external system of the reference.
+func (s *ReferenceService) transition(ctx context.Context, reference *models.TicketReference,
+	allowed func(models.TicketStatus) bool, status models.TicketStatus) {
+
+	ticket, e := s.ticketRepository.LoadByID(ctx, reference.TicketID)
+	if e != nil {
+		return
+	}
+
+	if !allowed(ticket.Status) {
+		return
+	}
+
+	ticket.Status = status
+	actor := strings.ToLower(string(reference.System))
+	previous, e := s.ticketRepository.Update(ctx, ticket, actor)
+	if e != nil {
+		return
+	}
+
+	ticket.ModifiedAt = time.Now()
+	publishTicketEvent(s.logger, s.natsClient, ticketUpdatedSubject, data.EventTypeTicketUpdated, ticket,
+		previous.Status, actor)
+}
+
+// record stores the outcome of synchronizing the reference as its sync status.
+func (s *ReferenceService) record(ctx context.Context, reference *models.TicketReference, e error) {
+	status, failure := models.ReferenceSyncStatusSynced, ""
+	if e != nil {
+		s.logger.Warn("Could not sync ", reference.System, " issue ", reference.ExternalKey, ": ", e.Error())
+		status, failure = models.ReferenceSyncStatusFailed, e.Error()
+	}
+
+	if status != reference.SyncStatus || failure != reference.SyncFailure {
+		_ = s.referenceRepository.UpdateSyncStatus(ctx, reference.ID, status, failure)
+	}
+}
+
+// issueStateOf maps the status of a ticket to the state of its external issues.
+func issueStateOf(status models.TicketStatus) connectors.IssueState {
+	if status == models.TicketStatusResolved || status == models.TicketStatusClosed {
+		return connectors.IssueStateClosed
+	}
+
+	return connectors.IssueStateOpen
+}
+
+func (s *ReferenceService) reply(msg *nc.Msg, t interface{}) {
+	reply, _ := json.Marshal(t)
+	_ = msg.Respond(reply)
+}
+
+func (s *ReferenceService) replyNoContent(msg *nc.Msg) {
+	_ = msg.Respond([]byte(""))
+}
+
+// Stop stops the component and drains its subscriptions, blocking until their in-flight messages are handled.
+func (s *ReferenceService) Stop() {
+	s.stop <- struct{}{}
+	<-s.stop
+}
@@ -0,0 +1,557 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/documents"
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/mailing"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/scheduler"
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+// Different privacy modes of agent metrics.
+const (
+	AgentMetricsNamed         = "NAMED"
+	AgentMetricsPseudonymized = "PSEUDONYMIZED"
+	AgentMetricsDisabled      = "DISABLED"
+)
+
+// AgentMetricsPrivacy configures how agents are identified in their activity metrics. In PSEUDONYMIZED mode agents
+// are reported by pseudonyms derived from their names with PseudonymKey, so they stay stable as long as the key does.
+type AgentMetricsPrivacy struct {
+	Mode         string
+	PseudonymKey string
+}
+
+// SLAComplianceWindow is a sliding window SLA compliance is exported over, named after its duration, e.g. 24h.
+type SLAComplianceWindow struct {
+	Name     string
+	Duration time.Duration
+}
+
+// slaCompliance exports the percentage of tickets within their SLA targets, for Grafana dashboards and alerts.
+var slaCompliance = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "kiosk_sla_compliance_percent",
+	Help: "Percentage of the tickets created within the window that met their SLA target.",
+}, []string{"issuer", "importance_level", "target", "window"})
+
+// ReportService is a service implementation of reporting functionalities.
+type ReportService struct {
+	logger                       *zap.SugaredLogger
+	reportRepository             *models.ReportRepository
+	scheduledReportRepository    *models.ScheduledReportRepository
+	dailyCounterRepository       *models.DailyCounterRepository
+	ticketStatusChangeRepository *models.TicketStatusChangeRepository
+	natsClient                   *nc.Conn
+	mailer                       *mailing.Mailer
+	agentMetricsPrivacy          AgentMetricsPrivacy
+	slaComplianceWindows         []SLAComplianceWindow
+	stop                         chan struct{}
+}
+
+// NewReportService returns a newly created and ready to use ReportService. Reports are queried through reportingDB,
+// whose statement timeout is that of reporting queries. SLA compliance is exported over each of the windows.
+func NewReportService(logger *zap.SugaredLogger, db, reportingDB *pgxpool.Pool, natsClient *nc.Conn,
+	mailer *mailing.Mailer, agentMetricsPrivacy AgentMetricsPrivacy,
+	slaComplianceWindows []SLAComplianceWindow) *ReportService {
+
+	return &ReportService{
+		logger:                       logger,
+		reportRepository:             models.NewReportRepository(logger, reportingDB),
+		scheduledReportRepository:    models.NewScheduledReportRepository(logger, db),
+		dailyCounterRepository:       models.NewDailyCounterRepository(logger, db),
+		ticketStatusChangeRepository: models.NewTicketStatusChangeRepository(logger, db),
+		natsClient:                   natsClient,
+		mailer:                       mailer,
+		agentMetricsPrivacy:          agentMetricsPrivacy,
+		slaComplianceWindows:         slaComplianceWindows,
+		stop:                         make(chan struct{}),
+	}
+}
+
+// Start starts the subscriptions so ready to be notified.
+func (s *ReportService) Start() error {
+	dailyReportSubscription, e := s.natsClient.QueueSubscribe("kiosk.reports.daily",
+		"kiosk.reports.daily_group", s.daily)
+	if e != nil {
+		return e
+	}
+
+	createScheduledReportSubscription, e := s.natsClient.QueueSubscribe("kiosk.reports.schedules.create",
+		"kiosk.reports.schedules.create_group", s.createSchedule)
+	if e != nil {
+		return e
+	}
+
+	listScheduledReportsSubscription, e := s.natsClient.QueueSubscribe("kiosk.reports.schedules.list",
+		"kiosk.reports.schedules.list_group", s.listSchedules)
+	if e != nil {
+		return e
+	}
+
+	deleteScheduledReportSubscription, e := s.natsClient.QueueSubscribe("kiosk.reports.schedules.delete",
+		"kiosk.reports.schedules.delete_group", s.deleteSchedule)
+	if e != nil {
+		return e
+	}
+
+	agentMetricsSubscription, e := s.natsClient.QueueSubscribe("kiosk.reports.agents",
+		"kiosk.reports.agents_group", s.agentMetrics)
+	if e != nil {
+		return e
+	}
+
+	timeReportSubscription, e := s.natsClient.QueueSubscribe("kiosk.reports.time",
+		"kiosk.reports.time_group", s.timeReport)
+	if e != nil {
+		return e
+	}
+
+	billingExportSubscription, e := s.natsClient.QueueSubscribe("kiosk.reports.billing",
+		"kiosk.reports.billing_group", s.billingExport)
+	if e != nil {
+		return e
+	}
+
+	workloadSubscription, e := s.natsClient.QueueSubscribe("kiosk.reports.workload",
+		"kiosk.reports.workload_group", s.workload)
+	if e != nil {
+		return e
+	}
+
+	resolutionReportSubscription, e := s.natsClient.QueueSubscribe("kiosk.reports.resolutions",
+		"kiosk.reports.resolutions_group", s.resolutionReport)
+	if e != nil {
+		return e
+	}
+
+	ticketUpdatedSubscription, e := s.natsClient.QueueSubscribe(ticketUpdatedSubject, "kiosk.reports_group",
+		s.onTicketUpdated)
+	if e != nil {
+		return e
+	}
+
+	go s.await(dailyReportSubscription, createScheduledReportSubscription, listScheduledReportsSubscription,
+		deleteScheduledReportSubscription, agentMetricsSubscription, timeReportSubscription, billingExportSubscription,
+		workloadSubscription, resolutionReportSubscription, ticketUpdatedSubscription)
+
+	return nil
+}
+
+func (s *ReportService) await(ss ...*nc.Subscription) {
+	<-s.stop
+	s.logger.Debug("ReportService: received stop signal!")
+
+	drain(s.logger, ss)
+	s.stop <- struct{}{}
+}
+
+func (s *ReportService) daily(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	dailyReportRequest := &data.DailyReportRequest{}
+	if e := json.Unmarshal(msg.Data, dailyReportRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := dailyReportRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	rows, e := s.reportRepository.Daily(ctx, dailyReportRequest.Issuer, dailyReportRequest.FromDate,
+		dailyReportRequest.ToDate)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	dailyReportResponse := &data.DailyReportResponse{}
+	dailyReportResponse.LoadFromRows(rows)
+	s.reply(msg, dailyReportResponse)
+}
+
+func (s *ReportService) agentMetrics(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	agentMetricsRequest := &data.AgentMetricsRequest{}
+	if e := json.Unmarshal(msg.Data, agentMetricsRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := agentMetricsRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if s.agentMetricsPrivacy.Mode == AgentMetricsDisabled {
+		s.reply(msg, errors.PreconditionFailed("agent_metrics.disabled", ""))
+		return
+	}
+
+	metrics, e := s.reportRepository.AgentMetrics(ctx, agentMetricsRequest.Issuer, agentMetricsRequest.Agent,
+		agentMetricsRequest.FromDate, agentMetricsRequest.ToDate)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if s.agentMetricsPrivacy.Mode == AgentMetricsPseudonymized {
+		for _, m := range metrics {
+			m.Agent = s.pseudonym(m.Agent)
+		}
+	}
+
+	agentMetricsResponse := &data.AgentMetricsResponse{}
+	agentMetricsResponse.LoadFromAgentMetrics(metrics)
+	s.reply(msg, agentMetricsResponse)
+}
+
+// timeReport replies the time logged on tickets per issuer and agent. Agents are identified as the privacy settings
+// of agent metrics allow, and the report is only split per issuer when agent metrics are disabled.
+func (s *ReportService) timeReport(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	timeReportRequest := &data.TimeReportRequest{}
+	if e := json.Unmarshal(msg.Data, timeReportRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := timeReportRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	byAgent := s.agentMetricsPrivacy.Mode != AgentMetricsDisabled
+	if !byAgent && timeReportRequest.Agent != "" {
+		s.reply(msg, errors.PreconditionFailed("agent_metrics.disabled", ""))
+		return
+	}
+
+	rows, e := s.reportRepository.TimeSpent(ctx, timeReportRequest.Issuer, timeReportRequest.Agent, byAgent,
+		timeReportRequest.FromDate, timeReportRequest.ToDate)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if s.agentMetricsPrivacy.Mode == AgentMetricsPseudonymized {
+		for _, row := range rows {
+			row.Agent = s.pseudonym(row.Agent)
+		}
+	}
+
+	timeReportResponse := &data.TimeReportResponse{}
+	timeReportResponse.LoadFromTimeSpentRows(rows)
+	s.reply(msg, timeReportResponse)
+}
+
+// resolutionReport replies the number of tickets resolved per resolution category, sub-category and root cause.
+func (s *ReportService) resolutionReport(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	resolutionReportRequest := &data.ResolutionReportRequest{}
+	if e := json.Unmarshal(msg.Data, resolutionReportRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := resolutionReportRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	rows, e := s.reportRepository.Resolutions(ctx, resolutionReportRequest.Issuer, resolutionReportRequest.FromDate,
+		resolutionReportRequest.ToDate)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	resolutionReportResponse := &data.ResolutionReportResponse{}
+	resolutionReportResponse.LoadFromResolutionRows(rows)
+	s.reply(msg, resolutionReportResponse)
+}
+
+// billingExport replies the billable time and tickets of a month per issuer, priced at their billing rates.
+func (s *ReportService) billingExport(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	billingExportRequest := &data.BillingExportRequest{}
+	if e := json.Unmarshal(msg.Data, billingExportRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := billingExportRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	fromDate, toDate := billingExportRequest.Period()
+	rows, e := s.reportRepository.Billing(ctx, fromDate, toDate)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	billingExportResponse := &data.BillingExportResponse{}
+	billingExportResponse.LoadFromBillingRows(billingExportRequest.Month, rows)
+	s.reply(msg, billingExportResponse)
+}
+
+func (s *ReportService) workload(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	workloadRequest := &data.WorkloadRequest{}
+	if e := json.Unmarshal(msg.Data, workloadRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := workloadRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	rows, e := s.reportRepository.Workload(ctx, workloadRequest.Issuer, workloadRequest.Granularity,
+		workloadRequest.ByIssuer, workloadRequest.FromDate, workloadRequest.ToDate)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	workloadResponse := &data.WorkloadResponse{}
+	workloadResponse.LoadFromRows(workloadRequest.Granularity, rows)
+	s.reply(msg, workloadResponse)
+}
+
+// pseudonym returns back the stable pseudonym of an agent, which can not be reversed without the pseudonym key.
+func (s *ReportService) pseudonym(agent string) string {
+	mac := hmac.New(sha256.New, []byte(s.agentMetricsPrivacy.PseudonymKey))
+	_, _ = mac.Write([]byte(agent))
+
+	return "agent-" + hex.EncodeToString(mac.Sum(nil))[:12]
+}
+
+// onTicketUpdated records the status changes of tickets for the activity metrics of agents.
+func (s *ReportService) onTicketUpdated(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	event := &data.Event{}
+	if e := json.Unmarshal(msg.Data, event); e != nil || event.Ticket == nil {
+		return
+	}
+
+	if event.PreviousStatus == "" || event.PreviousStatus == event.Ticket.Status {
+		return
+	}
+
+	changedAt, e := time.Parse(time.RFC3339Nano, event.OccurredAt)
+	if e != nil {
+		changedAt = time.Now()
+	}
+
+	_ = s.ticketStatusChangeRepository.Insert(ctx, models.TicketStatusChange{
+		TicketID:       event.Ticket.ID,
+		Issuer:         event.Ticket.Issuer,
+		PreviousStatus: event.PreviousStatus,
+		Status:         event.Ticket.Status,
+		Actor:          event.Actor,
+		ChangedAt:      changedAt.UTC(),
+	})
+}
+
+func (s *ReportService) createSchedule(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createScheduledReportRequest := &data.CreateScheduledReportRequest{}
+	if e := json.Unmarshal(msg.Data, createScheduledReportRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := createScheduledReportRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if e := s.scheduledReportRepository.Insert(ctx, *createScheduledReportRequest.AsScheduledReport()); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+func (s *ReportService) listSchedules(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	reports, e := s.scheduledReportRepository.LoadAll(ctx)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	scheduledReportsResponse := &data.ScheduledReportsResponse{}
+	scheduledReportsResponse.LoadFromScheduledReports(reports)
+	s.reply(msg, scheduledReportsResponse)
+}
+
+func (s *ReportService) deleteSchedule(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	id := &data.ID{}
+	if e := json.Unmarshal(msg.Data, id); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := s.scheduledReportRepository.DeleteByID(ctx, id.ID); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+// RunScheduledReports is a scheduler job that runs every minute and emails the scheduled reports whose cron
+// expressions match the activation time.
+func (s *ReportService) RunScheduledReports(ctx context.Context, now time.Time) {
+	reports, e := s.scheduledReportRepository.LoadAll(ctx)
+	if e != nil {
+		return
+	}
+
+	for _, report := range reports {
+		schedule, err := scheduler.Parse(report.Cron)
+		if err != nil {
+			s.logger.Warn("Skipping scheduled report ", report.ID, " with invalid cron: ", report.Cron)
+			continue
+		}
+
+		if !schedule.Matches(now) {
+			continue
+		}
+
+		if err := s.send(ctx, report, now); err != nil {
+			s.logger.Error("Could not send scheduled report ", report.ID, ": ", err.Error())
+		}
+	}
+}
+
+// ReconcileDailyCounters is a scheduler job that runs nightly and verifies the daily ticket counters, which daily
+// reports are read off, against tickets. Drifted counters are fixed and reported, as they point to writers bypassing
+// the triggers keeping them up to date.
+func (s *ReportService) ReconcileDailyCounters(ctx context.Context, _ time.Time) {
+	drifts, e := s.dailyCounterRepository.Reconcile(ctx)
+	if e != nil {
+		return
+	}
+
+	for _, drift := range drifts {
+		s.logger.Warn("Daily ticket counter of ", drift.Day.Format("2006-01-02"), ", ", drift.Issuer, ", ",
+			drift.ImportanceLevel, " and ", drift.Status, " counted ", drift.Counted, " tickets rather than ",
+			drift.Actual)
+	}
+}
+
+// ExportSLACompliance is a scheduler job that exports the SLA compliance of the tickets created within each of the
+// sliding windows as Prometheus gauges, per issuer, importance level and target. Windows failing to compute are left
+// out until the next run, rather than exporting stale values.
+func (s *ReportService) ExportSLACompliance(ctx context.Context, now time.Time) {
+	to := now.UTC()
+	windows := make(map[string][]*models.SLAComplianceRow, len(s.slaComplianceWindows))
+	for _, window := range s.slaComplianceWindows {
+		rows, e := s.reportRepository.SLACompliance(ctx, to.Add(-window.Duration).Format(time.RFC3339Nano),
+			to.Format(time.RFC3339Nano))
+		if e == nil {
+			windows[window.Name] = rows
+		}
+	}
+
+	// Issuers and importance levels without tickets in the windows anymore are dropped.
+	slaCompliance.Reset()
+	for window, rows := range windows {
+		for _, row := range rows {
+			if compliance, ok := row.FirstResponseCompliance(); ok {
+				slaCompliance.WithLabelValues(row.Issuer, string(row.ImportanceLevel), "first_response",
+					window).Set(compliance)
+			}
+
+			if compliance, ok := row.ResolutionCompliance(); ok {
+				slaCompliance.WithLabelValues(row.Issuer, string(row.ImportanceLevel), "resolution",
+					window).Set(compliance)
+			}
+		}
+	}
+}
+
+func (s *ReportService) send(ctx context.Context, report *models.ScheduledReport, now time.Time) error {
+	to := now.UTC()
+	from := to.AddDate(0, 0, -report.PeriodDays)
+
+	rows, e := s.reportRepository.Daily(ctx, report.Issuer, from.Format(time.RFC3339Nano), to.Format(time.RFC3339Nano))
+	if e != nil {
+		return e
+	}
+
+	title := fmt.Sprintf("%v (%v to %v)", report.Name, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	attachment := mailing.Attachment{}
+	switch report.Format {
+	case models.ReportFormatPDF:
+		attachment = mailing.Attachment{Name: "report.pdf", ContentType: "application/pdf",
+			Content: documents.RenderDailyReportPDF(title, rows)}
+	default:
+		content, err := documents.RenderDailyReportCSV(rows)
+		if err != nil {
+			return err
+		}
+
+		attachment = mailing.Attachment{Name: "report.csv", ContentType: "text/csv; charset=utf-8", Content: content}
+	}
+
+	body := fmt.Sprintf("Please find the %v report attached.", title)
+	return s.mailer.Send(report.Recipients, title, body, attachment)
+}
+
+func (s *ReportService) reply(msg *nc.Msg, t interface{}) {
+	reply, _ := json.Marshal(t)
+	_ = msg.Respond(reply)
+}
+
+func (s *ReportService) replyNoContent(msg *nc.Msg) {
+	_ = msg.Respond([]byte(""))
+}
+
+// Stop stops the component and drains its subscriptions, blocking until their in-flight messages are handled.
+func (s *ReportService) Stop() {
+	s.stop <- struct{}{}
+	<-s.stop
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+//go:generate mockgen -source=repositories.go -destination=mocks/repositories.go -package=mocks
+
+// TicketRepository is the storage of tickets that services work with. It is implemented by models.TicketRepository,
+// and by mocks.MockTicketRepository in unit tests of services.
+type TicketRepository interface {
+	Insert(ctx context.Context, ticket models.Ticket) (int64, *errors.Type)
+	LoadByID(ctx context.Context, id int64) (*models.Ticket, *errors.Type)
+	LoadIDByFingerprint(ctx context.Context, issuer, fingerprint string, createdAfter time.Time) (int64, *errors.Type)
+	Update(ctx context.Context, ticket *models.Ticket, editor string) (*models.Ticket, *errors.Type)
+	SetBillable(ctx context.Context, id int64, billable bool) *errors.Type
+	Snooze(ctx context.Context, id int64, until time.Time) *errors.Type
+	Unsnooze(ctx context.Context, id int64, owner string) (bool, *errors.Type)
+	UnsnoozeDue(ctx context.Context, now time.Time) ([]int64, *errors.Type)
+	CloseWaiting(ctx context.Context, silentSince time.Time) ([]int64, *errors.Type)
+	ClaimNudges(ctx context.Context, silentSince, now time.Time) ([]int64, *errors.Type)
+	ReopenWaiting(ctx context.Context, id int64, owner string) (bool, *errors.Type)
+	Assign(ctx context.Context, id int64, assignee string, reassign bool) (string, *errors.Type)
+	Unassign(ctx context.Context, id int64, assignee string) (string, *errors.Type)
+	DeleteByID(ctx context.Context, id int64) (*models.Ticket, *errors.Type)
+	CountByStatus(ctx context.Context, owner, assignee string) (map[models.TicketStatus]int64, *errors.Type)
+	CountForReindex(ctx context.Context, issuer, fromDate, toDate string) (int64, *errors.Type)
+	Reindex(ctx context.Context, issuer, fromDate, toDate string, afterID int64, batchSize int) (int64, int,
+		*errors.Type)
+	Filter(ctx context.Context, issuer, owner string, importanceLevel models.TicketImportanceLevel,
+		status models.TicketStatus, resolution models.Resolution, metadata models.MetadataMatch, snoozed bool,
+		fromDate, toDate string, orders []models.Order, after models.Cursor, pageNumber, pageSize int) ([]*models.Ticket,
+		bool, *errors.Type)
+	FilterCount(ctx context.Context, issuer, owner string, importanceLevel models.TicketImportanceLevel,
+		status models.TicketStatus, resolution models.Resolution, metadata models.MetadataMatch, snoozed bool,
+		fromDate, toDate string) (int64, *errors.Type)
+	FilterExists(ctx context.Context, issuer, owner string, importanceLevel models.TicketImportanceLevel,
+		status models.TicketStatus, resolution models.Resolution, metadata models.MetadataMatch, snoozed bool,
+		fromDate, toDate string) (bool, *errors.Type)
+	FilterEstimate(ctx context.Context, issuer, owner string, importanceLevel models.TicketImportanceLevel,
+		status models.TicketStatus, resolution models.Resolution, metadata models.MetadataMatch, snoozed bool,
+		fromDate, toDate string) (int64, *errors.Type)
+	FilterFacets(ctx context.Context, issuer, owner string, importanceLevel models.TicketImportanceLevel,
+		status models.TicketStatus, resolution models.Resolution, metadata models.MetadataMatch, snoozed bool,
+		fromDate, toDate string) (*models.TicketFacets, *errors.Type)
+	FilterByAssignee(ctx context.Context, issuer, assignee string, status models.TicketStatus, pageNumber,
+		pageSize int) ([]*models.Ticket, bool, *errors.Type)
+	FilterGroups(ctx context.Context, groupBy, issuer, owner string,
+		importanceLevel models.TicketImportanceLevel, status models.TicketStatus, resolution models.Resolution,
+		metadata models.MetadataMatch, snoozed bool, fromDate, toDate string, orders []models.Order, size int) (
+		[]*models.TicketGroup, *errors.Type)
+}
+
+// CommentRepository is the storage of comments that services work with. It is implemented by
+// models.CommentRepository, and by mocks.MockCommentRepository in unit tests of services.
+type CommentRepository interface {
+	Insert(ctx context.Context, comment models.Comment) (int64, *errors.Type)
+	LoadByID(ctx context.Context, id int64) (*models.Comment, *errors.Type)
+	Filter(ctx context.Context, ticketID int64, authorTypes []models.CommentAuthorType,
+		sources []models.CommentSource, orders []models.Order, after models.Cursor, limit int) ([]*models.Comment, bool,
+		*errors.Type)
+	FilterAround(ctx context.Context, ticketID int64, authorTypes []models.CommentAuthorType,
+		sources []models.CommentSource, orders []models.Order, aroundID int64, window int) ([]*models.Comment, bool,
+		bool, *errors.Type)
+	FilterCount(ctx context.Context, ticketID int64, authorTypes []models.CommentAuthorType,
+		sources []models.CommentSource) (int64, *errors.Type)
+	FilterExists(ctx context.Context, ticketID int64, authorTypes []models.CommentAuthorType,
+		sources []models.CommentSource) (bool, *errors.Type)
+	Update(ctx context.Context, comment *models.Comment, editor string) *errors.Type
+	DeleteByID(ctx context.Context, id int64) *errors.Type
+}
+
+var (
+	_ TicketRepository  = (*models.TicketRepository)(nil)
+	_ CommentRepository = (*models.CommentRepository)(nil)
+)
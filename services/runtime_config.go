@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/db/postgres"
+	"github.com/jibitters/kiosk/models"
+	"go.uber.org/zap"
+)
+
+// runtimeChangesChannel is the postgres channel the changes of runtime entries are broadcast on.
+const runtimeChangesChannel = "kiosk_runtime_changes"
+
+// RuntimeConfig keeps the runtime entries in memory, so services read them without a round trip to the database.
+// Changes made through any kiosk instance are propagated to all of them by the database triggers within seconds.
+type RuntimeConfig struct {
+	logger          *zap.SugaredLogger
+	entryRepository *models.RuntimeEntryRepository
+	changesListener *postgres.Listener
+	mutex           sync.RWMutex
+	entries         map[models.RuntimeEntryKind]map[string]*models.RuntimeEntry
+}
+
+// NewRuntimeConfig returns back a newly created RuntimeConfig, ready to use once started.
+func NewRuntimeConfig(logger *zap.SugaredLogger, db *pgxpool.Pool) *RuntimeConfig {
+	c := &RuntimeConfig{
+		logger:          logger,
+		entryRepository: models.NewRuntimeEntryRepository(logger, db),
+		entries:         make(map[models.RuntimeEntryKind]map[string]*models.RuntimeEntry),
+	}
+
+	c.changesListener = postgres.NewListener(logger, db, runtimeChangesChannel, c.onChange, c.reload)
+	return c
+}
+
+// Start loads all entries and then keeps them up to date until Stop gets called.
+func (c *RuntimeConfig) Start() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	entries, e := c.entryRepository.LoadAll(ctx)
+	if e != nil {
+		return e
+	}
+
+	c.replace(entries)
+	c.changesListener.Start()
+	return nil
+}
+
+// Get returns back the entry of the kind with the name, if any.
+func (c *RuntimeConfig) Get(kind models.RuntimeEntryKind, name string) (*models.RuntimeEntry, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	entry, ok := c.entries[kind][name]
+	return entry, ok
+}
+
+// All returns back the entries of the kind ordered by name, or the entries of all kinds if kind is empty.
+func (c *RuntimeConfig) All(kind models.RuntimeEntryKind) []*models.RuntimeEntry {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	entries := make([]*models.RuntimeEntry, 0)
+	for k, named := range c.entries {
+		if kind != "" && k != kind {
+			continue
+		}
+
+		for _, entry := range named {
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Kind != entries[j].Kind {
+			return entries[i].Kind < entries[j].Kind
+		}
+
+		return entries[i].Name < entries[j].Name
+	})
+
+	return entries
+}
+
+// FeatureEnabled reports whether the feature flag with the name is enabled, flags that do not exist are disabled.
+func (c *RuntimeConfig) FeatureEnabled(name string) bool {
+	entry, ok := c.Get(models.RuntimeEntryKindFeatureFlag, name)
+	if !ok {
+		return false
+	}
+
+	var enabled bool
+	_ = json.Unmarshal([]byte(entry.Value), &enabled)
+	return enabled
+}
+
+// onChange handles the changes of runtime entries broadcast by the database triggers, including those made through
+// other kiosk instances.
+func (c *RuntimeConfig) onChange(payload string) {
+	change := &struct {
+		Operation string                  `json:"operation"`
+		Kind      models.RuntimeEntryKind `json:"kind"`
+		Name      string                  `json:"name"`
+	}{}
+
+	if e := json.Unmarshal([]byte(payload), change); e != nil {
+		c.reload()
+		return
+	}
+
+	if change.Operation == "DELETE" {
+		c.forget(change.Kind, change.Name)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	entry, e := c.entryRepository.Load(ctx, change.Kind, change.Name)
+	if e != nil {
+		// Deleted since, the deletion gets handled on its own notification.
+		return
+	}
+
+	c.store(entry)
+}
+
+// store keeps the entry unless a newer version of it is already kept.
+func (c *RuntimeConfig) store(entry *models.RuntimeEntry) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.entries[entry.Kind] == nil {
+		c.entries[entry.Kind] = make(map[string]*models.RuntimeEntry)
+	}
+
+	if current, ok := c.entries[entry.Kind][entry.Name]; !ok || current.Version < entry.Version {
+		c.entries[entry.Kind][entry.Name] = entry
+	}
+}
+
+// forget drops the entry of the kind with the name.
+func (c *RuntimeConfig) forget(kind models.RuntimeEntryKind, name string) {
+	c.mutex.Lock()
+	delete(c.entries[kind], name)
+	c.mutex.Unlock()
+}
+
+// reload loads all entries again, as changes may have been missed.
+func (c *RuntimeConfig) reload() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	entries, e := c.entryRepository.LoadAll(ctx)
+	if e != nil {
+		c.logger.Warn("Could not reload runtime entries, they may be stale until the next change")
+		return
+	}
+
+	c.replace(entries)
+}
+
+func (c *RuntimeConfig) replace(entries []*models.RuntimeEntry) {
+	replaced := make(map[models.RuntimeEntryKind]map[string]*models.RuntimeEntry)
+	for _, entry := range entries {
+		if replaced[entry.Kind] == nil {
+			replaced[entry.Kind] = make(map[string]*models.RuntimeEntry)
+		}
+
+		replaced[entry.Kind][entry.Name] = entry
+	}
+
+	c.mutex.Lock()
+	c.entries = replaced
+	c.mutex.Unlock()
+}
+
+// Stop stops keeping the entries up to date.
+func (c *RuntimeConfig) Stop() {
+	c.changesListener.Stop()
+}
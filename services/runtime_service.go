@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// RuntimeService is a service implementation of runtime entry functionalities, i.e. editing the feature flags,
+// templates and auto-responder rules without a restart. Edits apply to all kiosk instances through their
+// RuntimeConfig within seconds.
+type RuntimeService struct {
+	logger                 *zap.SugaredLogger
+	runtimeEntryRepository *models.RuntimeEntryRepository
+	config                 *RuntimeConfig
+	natsClient             *nc.Conn
+	stop                   chan struct{}
+}
+
+// NewRuntimeService returns a newly created and ready to use RuntimeService.
+func NewRuntimeService(logger *zap.SugaredLogger, db *pgxpool.Pool, natsClient *nc.Conn) *RuntimeService {
+	return &RuntimeService{
+		logger:                 logger,
+		runtimeEntryRepository: models.NewRuntimeEntryRepository(logger, db),
+		config:                 NewRuntimeConfig(logger, db),
+		natsClient:             natsClient,
+		stop:                   make(chan struct{}),
+	}
+}
+
+// Config returns back the runtime entries kept up to date by this service, for other services to read.
+func (s *RuntimeService) Config() *RuntimeConfig {
+	return s.config
+}
+
+// Start loads the runtime entries and starts the subscriptions so ready to be notified.
+func (s *RuntimeService) Start() error {
+	if e := s.config.Start(); e != nil {
+		return e
+	}
+
+	saveEntrySubscription, e := s.natsClient.QueueSubscribe("kiosk.runtime.entries.save",
+		"kiosk.runtime.entries.save_group", s.save)
+	if e != nil {
+		return e
+	}
+
+	loadEntrySubscription, e := s.natsClient.QueueSubscribe("kiosk.runtime.entries.load",
+		"kiosk.runtime.entries.load_group", s.load)
+	if e != nil {
+		return e
+	}
+
+	listEntriesSubscription, e := s.natsClient.QueueSubscribe("kiosk.runtime.entries.list",
+		"kiosk.runtime.entries.list_group", s.list)
+	if e != nil {
+		return e
+	}
+
+	deleteEntrySubscription, e := s.natsClient.QueueSubscribe("kiosk.runtime.entries.delete",
+		"kiosk.runtime.entries.delete_group", s.delete)
+	if e != nil {
+		return e
+	}
+
+	go s.await(saveEntrySubscription, loadEntrySubscription, listEntriesSubscription, deleteEntrySubscription)
+
+	return nil
+}
+
+func (s *RuntimeService) await(ss ...*nc.Subscription) {
+	<-s.stop
+	s.logger.Debug("RuntimeService: received stop signal!")
+
+	drain(s.logger, ss)
+	s.config.Stop()
+	s.stop <- struct{}{}
+}
+
+func (s *RuntimeService) save(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	saveRuntimeEntryRequest := &data.SaveRuntimeEntryRequest{}
+	if e := json.Unmarshal(msg.Data, saveRuntimeEntryRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := saveRuntimeEntryRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	entry, e := s.runtimeEntryRepository.Save(ctx, *saveRuntimeEntryRequest.AsRuntimeEntry())
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	// Read your own writes on this instance, the others catch up on the notification.
+	s.config.store(entry)
+
+	runtimeEntryResponse := &data.RuntimeEntryResponse{}
+	runtimeEntryResponse.LoadFromRuntimeEntry(entry)
+	s.reply(msg, runtimeEntryResponse)
+}
+
+func (s *RuntimeService) load(msg *nc.Msg) {
+	runtimeEntryRequest := &data.RuntimeEntryRequest{}
+	if e := json.Unmarshal(msg.Data, runtimeEntryRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := runtimeEntryRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	entry, ok := s.config.Get(runtimeEntryRequest.Kind, runtimeEntryRequest.Name)
+	if !ok {
+		s.reply(msg, errors.NotFound("runtime_entry.not_found", ""))
+		return
+	}
+
+	runtimeEntryResponse := &data.RuntimeEntryResponse{}
+	runtimeEntryResponse.LoadFromRuntimeEntry(entry)
+	s.reply(msg, runtimeEntryResponse)
+}
+
+func (s *RuntimeService) list(msg *nc.Msg) {
+	listRuntimeEntriesRequest := &data.ListRuntimeEntriesRequest{}
+	if e := json.Unmarshal(msg.Data, listRuntimeEntriesRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := listRuntimeEntriesRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	runtimeEntriesResponse := &data.RuntimeEntriesResponse{}
+	runtimeEntriesResponse.LoadFromRuntimeEntries(s.config.All(listRuntimeEntriesRequest.Kind))
+	s.reply(msg, runtimeEntriesResponse)
+}
+
+func (s *RuntimeService) delete(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	runtimeEntryRequest := &data.RuntimeEntryRequest{}
+	if e := json.Unmarshal(msg.Data, runtimeEntryRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := runtimeEntryRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	e := s.runtimeEntryRepository.Delete(ctx, runtimeEntryRequest.Kind, runtimeEntryRequest.Name)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.config.forget(runtimeEntryRequest.Kind, runtimeEntryRequest.Name)
+	s.replyNoContent(msg)
+}
+
+func (s *RuntimeService) reply(msg *nc.Msg, t interface{}) {
+	reply, _ := json.Marshal(t)
+	_ = msg.Respond(reply)
+}
+
+func (s *RuntimeService) replyNoContent(msg *nc.Msg) {
+	_ = msg.Respond([]byte(""))
+}
+
+// Stop stops the component and drains its subscriptions, blocking until their in-flight messages are handled.
+func (s *RuntimeService) Stop() {
+	s.stop <- struct{}{}
+	<-s.stop
+}
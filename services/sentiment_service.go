@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/connectors"
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// SentimentService is a service implementation of sentiment functionalities. Comments of customers are scored by the
+// configured sentiment analyzer as they get created, and folded into the sentiment of their tickets so leads can find
+// the frustrated ones.
+type SentimentService struct {
+	logger              *zap.SugaredLogger
+	sentimentRepository *models.SentimentRepository
+	natsClient          *nc.Conn
+	analyzer            connectors.SentimentAnalyzer
+	stop                chan struct{}
+}
+
+// NewSentimentService returns a newly created and ready to use SentimentService. The analyzer is nil when none is
+// configured, in which case comments are not scored.
+func NewSentimentService(logger *zap.SugaredLogger, db *pgxpool.Pool, natsClient *nc.Conn,
+	analyzer connectors.SentimentAnalyzer) *SentimentService {
+
+	return &SentimentService{
+		logger:              logger,
+		sentimentRepository: models.NewSentimentRepository(logger, db),
+		natsClient:          natsClient,
+		analyzer:            analyzer,
+		stop:                make(chan struct{}),
+	}
+}
+
+// Start starts the subscriptions so ready to be notified.
+func (s *SentimentService) Start() error {
+	frustratedTicketsSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.frustrated",
+		"kiosk.tickets.frustrated_group", s.frustrated)
+	if e != nil {
+		return e
+	}
+
+	subscriptions := []*nc.Subscription{frustratedTicketsSubscription}
+
+	if s.analyzer != nil {
+		commentCreatedSubscription, e := s.natsClient.QueueSubscribe(commentCreatedSubject, "kiosk.sentiment_group",
+			s.onCommentCreated)
+		if e != nil {
+			return e
+		}
+
+		subscriptions = append(subscriptions, commentCreatedSubscription)
+	}
+
+	go s.await(subscriptions...)
+
+	return nil
+}
+
+func (s *SentimentService) await(ss ...*nc.Subscription) {
+	<-s.stop
+	s.logger.Debug("SentimentService: received stop signal!")
+
+	drain(s.logger, ss)
+	s.stop <- struct{}{}
+}
+
+// onCommentCreated scores the comments of customers and folds their scores into the sentiment of their tickets.
+func (s *SentimentService) onCommentCreated(msg *nc.Msg) {
+	event := &data.Event{}
+	if e := json.Unmarshal(msg.Data, event); e != nil || event.Comment == nil {
+		return
+	}
+
+	if event.Comment.AuthorType != models.CommentAuthorTypeCustomer || strings.TrimSpace(event.Comment.Content) == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	score, e := s.analyzer.Score(ctx, event.Comment.Content)
+	if e != nil {
+		s.logger.Warn("Could not score the sentiment of comment ", event.Comment.ID, ": ", e.Error())
+		return
+	}
+
+	_, _ = s.sentimentRepository.Record(ctx, event.Comment.ID, score)
+}
+
+// frustrated replies the open tickets whose customer sentiment is negative or getting worse, the most negative first.
+func (s *SentimentService) frustrated(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	frustratedTicketsRequest := &data.FrustratedTicketsRequest{}
+	if e := json.Unmarshal(msg.Data, frustratedTicketsRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := frustratedTicketsRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	tickets, e := s.sentimentRepository.LoadFrustrated(ctx, frustratedTicketsRequest.Issuer,
+		*frustratedTicketsRequest.MaxScore, *frustratedTicketsRequest.MaxTrend, frustratedTicketsRequest.Limit)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	frustratedTicketsResponse := &data.FrustratedTicketsResponse{}
+	frustratedTicketsResponse.LoadFromFrustratedTickets(tickets)
+	s.reply(msg, frustratedTicketsResponse)
+}
+
+func (s *SentimentService) reply(msg *nc.Msg, t interface{}) {
+	reply, _ := json.Marshal(t)
+	_ = msg.Respond(reply)
+}
+
+// Stop stops the component and drains its subscriptions, blocking until their in-flight messages are handled.
+func (s *SentimentService) Stop() {
+	s.stop <- struct{}{}
+	<-s.stop
+}
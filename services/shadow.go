@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"time"
+
+	"github.com/jibitters/kiosk/db/postgres"
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+// maxPendingShadowReads bounds the shadow reads in flight, reads sampled beyond that are skipped rather than piling up
+// on a slow shadow.
+const maxPendingShadowReads = 8
+
+// shadowReadTimeout bounds how long a single shadow read may take.
+const shadowReadTimeout = 5 * time.Second
+
+// shadowReadResults counts the shadow reads by their outcome, so a rollout can be judged by its mismatch rate.
+var shadowReadResults = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "kiosk_shadow_reads_total",
+	Help: "Number of reads executed against the shadow ticket repository, by outcome.",
+}, []string{"method", "result"})
+
+// Different outcomes of shadow reads.
+const (
+	shadowReadMatch    = "match"
+	shadowReadMismatch = "mismatch"
+	shadowReadFailed   = "failed"
+	shadowReadSkipped  = "skipped"
+)
+
+// ShadowReads configures executing a percentage of ticket filters against a new implementation of the repository as
+// well, e.g. a rewritten query path or another search backend, to compare its results with the ones actually served
+// before rolling it out. A nil Repository or a zero Percent disables shadow reads.
+type ShadowReads struct {
+	Repository TicketRepository
+	Percent    int
+}
+
+// shadowReader executes the sampled filters of the repositories it wraps against the shadow as well, in the
+// background once served. Shadow results never reach callers, their differences are only logged.
+type shadowReader struct {
+	logger  *zap.SugaredLogger
+	shadow  TicketRepository
+	percent int
+	pending chan struct{}
+}
+
+// newShadowReader returns back a shadow reader as configured, or nil when shadow reads are disabled.
+func newShadowReader(logger *zap.SugaredLogger, shadowReads ShadowReads) *shadowReader {
+	if shadowReads.Repository == nil || shadowReads.Percent <= 0 {
+		return nil
+	}
+
+	return &shadowReader{
+		logger:  logger,
+		shadow:  shadowReads.Repository,
+		percent: shadowReads.Percent,
+		pending: make(chan struct{}, maxPendingShadowReads),
+	}
+}
+
+// wrap returns back a repository shadowing the filters of primary, or primary itself when shadow reads are disabled.
+func (s *shadowReader) wrap(primary TicketRepository) TicketRepository {
+	if s == nil {
+		return primary
+	}
+
+	return &shadowTicketRepository{TicketRepository: primary, shadowReader: s}
+}
+
+// shadowTicketRepository serves everything from the wrapped repository, while its filters are shadowed.
+type shadowTicketRepository struct {
+	TicketRepository
+	*shadowReader
+}
+
+// Filter serves the filter from the primary and shadows it comparing the ids of the tickets in order.
+func (r *shadowTicketRepository) Filter(ctx context.Context, issuer, owner string,
+	importanceLevel models.TicketImportanceLevel, status models.TicketStatus, resolution models.Resolution,
+	metadata models.MetadataMatch, snoozed bool, fromDate, toDate string, orders []models.Order, after models.Cursor,
+	pageNumber, pageSize int) ([]*models.Ticket, bool, *errors.Type) {
+
+	tickets, hasNextPage, e := r.TicketRepository.Filter(ctx, issuer, owner, importanceLevel, status, resolution,
+		metadata, snoozed, fromDate, toDate, orders, after, pageNumber, pageSize)
+	if e != nil {
+		return tickets, hasNextPage, e
+	}
+
+	r.compare(ctx, "Filter", func(ctx context.Context) (string, *errors.Type) {
+		shadowTickets, shadowHasNextPage, e := r.shadow.Filter(ctx, issuer, owner, importanceLevel, status,
+			resolution, metadata, snoozed, fromDate, toDate, orders, after, pageNumber, pageSize)
+		if e != nil {
+			return "", e
+		}
+
+		ids, shadowIDs := ticketIDs(tickets), ticketIDs(shadowTickets)
+		if reflect.DeepEqual(ids, shadowIDs) && hasNextPage == shadowHasNextPage {
+			return "", nil
+		}
+
+		return fmt.Sprintf("ids %v (has next page: %t) but shadow ids %v (has next page: %t)", ids, hasNextPage,
+			shadowIDs, shadowHasNextPage), nil
+	})
+
+	return tickets, hasNextPage, nil
+}
+
+// FilterCount serves the count from the primary and shadows it.
+func (r *shadowTicketRepository) FilterCount(ctx context.Context, issuer, owner string,
+	importanceLevel models.TicketImportanceLevel, status models.TicketStatus, resolution models.Resolution,
+	metadata models.MetadataMatch, snoozed bool, fromDate, toDate string) (int64, *errors.Type) {
+
+	count, e := r.TicketRepository.FilterCount(ctx, issuer, owner, importanceLevel, status, resolution, metadata,
+		snoozed, fromDate, toDate)
+	if e != nil {
+		return count, e
+	}
+
+	r.compare(ctx, "FilterCount", func(ctx context.Context) (string, *errors.Type) {
+		shadowCount, e := r.shadow.FilterCount(ctx, issuer, owner, importanceLevel, status, resolution, metadata,
+			snoozed, fromDate, toDate)
+		return differences(count, shadowCount), e
+	})
+
+	return count, nil
+}
+
+// FilterExists serves the existence from the primary and shadows it.
+func (r *shadowTicketRepository) FilterExists(ctx context.Context, issuer, owner string,
+	importanceLevel models.TicketImportanceLevel, status models.TicketStatus, resolution models.Resolution,
+	metadata models.MetadataMatch, snoozed bool, fromDate, toDate string) (bool, *errors.Type) {
+
+	exists, e := r.TicketRepository.FilterExists(ctx, issuer, owner, importanceLevel, status, resolution, metadata,
+		snoozed, fromDate, toDate)
+	if e != nil {
+		return exists, e
+	}
+
+	r.compare(ctx, "FilterExists", func(ctx context.Context) (string, *errors.Type) {
+		shadowExists, e := r.shadow.FilterExists(ctx, issuer, owner, importanceLevel, status, resolution, metadata,
+			snoozed, fromDate, toDate)
+		return differences(exists, shadowExists), e
+	})
+
+	return exists, nil
+}
+
+// FilterFacets serves the facets from the primary and shadows them.
+func (r *shadowTicketRepository) FilterFacets(ctx context.Context, issuer, owner string,
+	importanceLevel models.TicketImportanceLevel, status models.TicketStatus, resolution models.Resolution,
+	metadata models.MetadataMatch, snoozed bool, fromDate, toDate string) (*models.TicketFacets, *errors.Type) {
+
+	facets, e := r.TicketRepository.FilterFacets(ctx, issuer, owner, importanceLevel, status, resolution, metadata,
+		snoozed, fromDate, toDate)
+	if e != nil {
+		return facets, e
+	}
+
+	r.compare(ctx, "FilterFacets", func(ctx context.Context) (string, *errors.Type) {
+		shadowFacets, e := r.shadow.FilterFacets(ctx, issuer, owner, importanceLevel, status, resolution, metadata,
+			snoozed, fromDate, toDate)
+		return differences(facets, shadowFacets), e
+	})
+
+	return facets, nil
+}
+
+// FilterGroups serves the groups from the primary and shadows them comparing their values, counts and the ids of their
+// tickets.
+func (r *shadowTicketRepository) FilterGroups(ctx context.Context, groupBy, issuer, owner string,
+	importanceLevel models.TicketImportanceLevel, status models.TicketStatus, resolution models.Resolution,
+	metadata models.MetadataMatch, snoozed bool, fromDate, toDate string, orders []models.Order, size int) (
+	[]*models.TicketGroup, *errors.Type) {
+
+	groups, e := r.TicketRepository.FilterGroups(ctx, groupBy, issuer, owner, importanceLevel, status, resolution,
+		metadata, snoozed, fromDate, toDate, orders, size)
+	if e != nil {
+		return groups, e
+	}
+
+	r.compare(ctx, "FilterGroups", func(ctx context.Context) (string, *errors.Type) {
+		shadowGroups, e := r.shadow.FilterGroups(ctx, groupBy, issuer, owner, importanceLevel, status, resolution,
+			metadata, snoozed, fromDate, toDate, orders, size)
+		if e != nil {
+			return "", e
+		}
+
+		return differences(groupSummaries(groups), groupSummaries(shadowGroups)), nil
+	})
+
+	return groups, nil
+}
+
+// compare executes the shadow read of the sampled reads in the background, which tells how its result differs from
+// the one served, or nothing when they are the same. Shadow reads are scoped to the tenant of the served read, but
+// outlive its context.
+func (s *shadowReader) compare(ctx context.Context, method string,
+	read func(ctx context.Context) (string, *errors.Type)) {
+
+	if rand.Intn(100) >= s.percent {
+		return
+	}
+
+	select {
+	case s.pending <- struct{}{}:
+	default:
+		shadowReadResults.WithLabelValues(method, shadowReadSkipped).Inc()
+		return
+	}
+
+	tenant := postgres.TenantOf(ctx)
+	go func() {
+		defer func() { <-s.pending }()
+
+		ctx, cancel := context.WithTimeout(postgres.WithTenant(context.Background(), tenant), shadowReadTimeout)
+		defer cancel()
+
+		diff, e := read(ctx)
+		switch {
+		case e != nil:
+			shadowReadResults.WithLabelValues(method, shadowReadFailed).Inc()
+			s.logger.Warn("Shadow ", method, " failed: ", e.Errors[0].Code)
+		case diff != "":
+			shadowReadResults.WithLabelValues(method, shadowReadMismatch).Inc()
+			s.logger.Warn("Shadow ", method, " of tenant '", tenant, "' differs, primary returned ", diff)
+		default:
+			shadowReadResults.WithLabelValues(method, shadowReadMatch).Inc()
+		}
+	}()
+}
+
+// differences tells how the shadow result differs from the primary one, or nothing when they are deeply equal.
+func differences(primary, shadow interface{}) string {
+	if reflect.DeepEqual(primary, shadow) {
+		return ""
+	}
+
+	p, _ := json.Marshal(primary)
+	s, _ := json.Marshal(shadow)
+	return fmt.Sprintf("%s but shadow %s", p, s)
+}
+
+func ticketIDs(tickets []*models.Ticket) []int64 {
+	ids := make([]int64, 0, len(tickets))
+	for _, ticket := range tickets {
+		ids = append(ids, ticket.ID)
+	}
+
+	return ids
+}
+
+// groupSummary is what groups are compared by, their tickets may differ in fields the shadow does not load the same.
+type groupSummary struct {
+	Value   string  `json:"value"`
+	Count   int64   `json:"count"`
+	Tickets []int64 `json:"tickets"`
+}
+
+func groupSummaries(groups []*models.TicketGroup) []groupSummary {
+	summaries := make([]groupSummary, 0, len(groups))
+	for _, group := range groups {
+		summaries = append(summaries, groupSummary{Value: group.Value, Count: group.Count,
+			Tickets: ticketIDs(group.Tickets)})
+	}
+
+	return summaries
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jibitters/kiosk/db/postgres"
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/services/mocks"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("ShadowReader", func() {
+	var controller *gomock.Controller
+	var primary *mocks.MockTicketRepository
+	var shadow *mocks.MockTicketRepository
+
+	BeforeEach(func() {
+		controller = gomock.NewController(GinkgoT())
+		primary = mocks.NewMockTicketRepository(controller)
+		shadow = mocks.NewMockTicketRepository(controller)
+	})
+
+	AfterEach(func() {
+		controller.Finish()
+	})
+
+	anything := gomock.Any()
+
+	Context("When shadow reads are disabled", func() {
+		It("Should return back the primary itself", func() {
+			reader := newShadowReader(zap.S(), ShadowReads{Repository: shadow})
+			Ω(reader.wrap(primary)).Should(BeIdenticalTo(primary))
+
+			reader = newShadowReader(zap.S(), ShadowReads{Percent: 100})
+			Ω(reader.wrap(primary)).Should(BeIdenticalTo(primary))
+		})
+	})
+
+	Context("When a filter gets sampled", func() {
+		It("Should serve the primary result and execute the shadow read for the same tenant", func() {
+			shadowed := make(chan string, 1)
+
+			shadowFilterCount := func(ctx context.Context, issuer, owner string,
+				importanceLevel models.TicketImportanceLevel, status models.TicketStatus, resolution models.Resolution,
+				metadata models.MetadataMatch, snoozed bool, fromDate, toDate string) (int64, *errors.Type) {
+
+				shadowed <- postgres.TenantOf(ctx)
+				return 4, nil
+			}
+
+			primary.EXPECT().FilterCount(anything, "Microservice-A", anything, anything, anything, anything, anything,
+				anything, anything, anything).Return(int64(3), nil)
+			shadow.EXPECT().FilterCount(anything, "Microservice-A", anything, anything, anything, anything, anything,
+				anything, anything, anything).DoAndReturn(shadowFilterCount)
+
+			repository := newShadowReader(zap.S(), ShadowReads{Repository: shadow, Percent: 100}).wrap(primary)
+			ctx := postgres.WithTenant(context.Background(), "Microservice-A")
+
+			count, e := repository.FilterCount(ctx, "Microservice-A", "", "", "", models.Resolution{}, nil, false,
+				"", "")
+			Ω(e).Should(BeNil())
+			Ω(count).Should(Equal(int64(3)))
+			Eventually(shadowed).Should(Receive(Equal("Microservice-A")))
+		})
+
+		It("Should not execute the shadow read when the primary fails", func() {
+			primary.EXPECT().FilterExists(anything, anything, anything, anything, anything, anything, anything,
+				anything, anything, anything).Return(false, errors.InternalServerError("unknown", ""))
+
+			repository := newShadowReader(zap.S(), ShadowReads{Repository: shadow, Percent: 100}).wrap(primary)
+
+			_, e := repository.FilterExists(context.Background(), "", "", "", "", models.Resolution{}, nil, false,
+				"", "")
+			Ω(e).ShouldNot(BeNil())
+			Ω(e.HTTPStatusCode).Should(Equal(http.StatusInternalServerError))
+		})
+
+		It("Should pass everything else through to the primary only", func() {
+			primary.EXPECT().LoadByID(anything, int64(1)).Return(&models.Ticket{ID: 1}, nil)
+
+			repository := newShadowReader(zap.S(), ShadowReads{Repository: shadow, Percent: 100}).wrap(primary)
+
+			ticket, e := repository.LoadByID(context.Background(), 1)
+			Ω(e).Should(BeNil())
+			Ω(ticket.ID).Should(Equal(int64(1)))
+		})
+	})
+
+	Context("When differences called", func() {
+		It("Should describe different results only", func() {
+			Ω(differences(int64(3), int64(3))).Should(BeEmpty())
+			Ω(differences(int64(3), int64(4))).Should(Equal("3 but shadow 4"))
+
+			groups := []*models.TicketGroup{{Value: "NEW", Count: 2, Tickets: []*models.Ticket{{ID: 1}, {ID: 2}}}}
+			Ω(differences(groupSummaries(groups), groupSummaries(groups))).Should(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/connectors"
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/jobs"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// publishIncidentJob is the kind of jobs publishing the current state of a status page incident.
+const publishIncidentJob = "status_page.publish"
+
+// resolvedIncidentMessage is the message of incidents resolved along with their tickets.
+const resolvedIncidentMessage = "This incident has been resolved."
+
+// StatusPageService is a service implementation of status page functionalities. Tickets can be marked as public
+// incidents which get published on the configured status page, and get resolved there once their tickets get
+// resolved, closed or deleted.
+type StatusPageService struct {
+	logger             *zap.SugaredLogger
+	ticketRepository   TicketRepository
+	incidentRepository *models.StatusPageIncidentRepository
+	natsClient         *nc.Conn
+	pool               *jobs.Pool
+	statusPage         connectors.StatusPage
+	stop               chan struct{}
+}
+
+// NewStatusPageService returns a newly created and ready to use StatusPageService. The status page is nil when none
+// is configured.
+func NewStatusPageService(logger *zap.SugaredLogger, db *pgxpool.Pool, natsClient *nc.Conn, pool *jobs.Pool,
+	statusPage connectors.StatusPage) *StatusPageService {
+
+	return &StatusPageService{
+		logger:             logger,
+		ticketRepository:   models.NewTicketRepository(logger, db),
+		incidentRepository: models.NewStatusPageIncidentRepository(logger, db),
+		natsClient:         natsClient,
+		pool:               pool,
+		statusPage:         statusPage,
+		stop:               make(chan struct{}),
+	}
+}
+
+// Start starts the subscriptions so ready to be notified.
+func (s *StatusPageService) Start() error {
+	s.pool.Register(publishIncidentJob, 30*time.Second, s.publishJob)
+
+	publishIncidentSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.incidents.publish",
+		"kiosk.tickets.incidents.publish_group", s.publish)
+	if e != nil {
+		return e
+	}
+
+	loadIncidentSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.incidents.load",
+		"kiosk.tickets.incidents.load_group", s.load)
+	if e != nil {
+		return e
+	}
+
+	ticketUpdatedSubscription, e := s.natsClient.QueueSubscribe(ticketUpdatedSubject, "kiosk.status_page_group",
+		s.onTicketUpdated)
+	if e != nil {
+		return e
+	}
+
+	ticketDeletedSubscription, e := s.natsClient.QueueSubscribe(ticketDeletedSubject, "kiosk.status_page_group",
+		s.onTicketDeleted)
+	if e != nil {
+		return e
+	}
+
+	go s.await(publishIncidentSubscription, loadIncidentSubscription, ticketUpdatedSubscription,
+		ticketDeletedSubscription)
+
+	return nil
+}
+
+func (s *StatusPageService) await(ss ...*nc.Subscription) {
+	<-s.stop
+	s.logger.Debug("StatusPageService: received stop signal!")
+
+	drain(s.logger, ss)
+	s.stop <- struct{}{}
+}
+
+// publish marks a ticket as a public incident, or posts an update of its incident, and publishes it in background.
+func (s *StatusPageService) publish(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	publishIncidentRequest := &data.PublishIncidentRequest{}
+	if e := json.Unmarshal(msg.Data, publishIncidentRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := publishIncidentRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if s.statusPage == nil {
+		s.reply(msg, errors.PreconditionFailed("status_page.not_configured", ""))
+		return
+	}
+
+	if _, e := s.ticketRepository.LoadByID(ctx, publishIncidentRequest.TicketID); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	incident := publishIncidentRequest.AsStatusPageIncident(s.statusPage.Provider())
+	if e := s.save(ctx, incident); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	saved, e := s.incidentRepository.LoadByTicketID(ctx, incident.TicketID)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	statusPageIncidentResponse := &data.StatusPageIncidentResponse{}
+	statusPageIncidentResponse.LoadFromStatusPageIncident(saved)
+	s.reply(msg, statusPageIncidentResponse)
+}
+
+func (s *StatusPageService) load(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	loadIncidentRequest := &data.LoadIncidentRequest{}
+	if e := json.Unmarshal(msg.Data, loadIncidentRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	incident, e := s.incidentRepository.LoadByTicketID(ctx, loadIncidentRequest.TicketID)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	statusPageIncidentResponse := &data.StatusPageIncidentResponse{}
+	statusPageIncidentResponse.LoadFromStatusPageIncident(incident)
+	s.reply(msg, statusPageIncidentResponse)
+}
+
+// onTicketUpdated resolves the incidents of tickets that got resolved or closed.
+func (s *StatusPageService) onTicketUpdated(msg *nc.Msg) {
+	event := &data.Event{}
+	if e := json.Unmarshal(msg.Data, event); e != nil || event.Ticket == nil {
+		return
+	}
+
+	if event.Ticket.Status == models.TicketStatusResolved || event.Ticket.Status == models.TicketStatusClosed {
+		s.resolve(event.Ticket.ID)
+	}
+}
+
+// onTicketDeleted resolves the incidents of deleted tickets.
+func (s *StatusPageService) onTicketDeleted(msg *nc.Msg) {
+	event := &data.Event{}
+	if e := json.Unmarshal(msg.Data, event); e != nil || event.Ticket == nil {
+		return
+	}
+
+	s.resolve(event.Ticket.ID)
+}
+
+// resolve resolves the incident of the ticket, if the ticket is a public incident that is not resolved yet.
+func (s *StatusPageService) resolve(ticketID int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	incident, e := s.incidentRepository.LoadByTicketID(ctx, ticketID)
+	if e != nil || incident.Status == models.StatusPageIncidentStatusResolved {
+		return
+	}
+
+	incident.Status = models.StatusPageIncidentStatusResolved
+	incident.Message = resolvedIncidentMessage
+	_ = s.save(ctx, incident)
+}
+
+// save stores the incident and enqueues publishing it. A pending publishing job picks up the latest state, so
+// enqueuing again while one is pending has no effect.
+func (s *StatusPageService) save(ctx context.Context, incident *models.StatusPageIncident) *errors.Type {
+	if e := s.incidentRepository.Save(ctx, *incident); e != nil {
+		return e
+	}
+
+	key := publishIncidentJob + ":" + strconv.FormatInt(incident.TicketID, 10)
+	_, e := s.pool.Enqueue(ctx, publishIncidentJob, key, &data.ID{ID: incident.TicketID})
+
+	return e
+}
+
+// publishJob creates or updates the incident of a ticket on the status page and records the outcome. Incidents
+// modified while being published get published again.
+func (s *StatusPageService) publishJob(ctx context.Context, payload []byte) error {
+	id := &data.ID{}
+	if e := json.Unmarshal(payload, id); e != nil {
+		return e
+	}
+
+	if s.statusPage == nil {
+		return fmt.Errorf("no status page is configured")
+	}
+
+	incident, et := s.incidentRepository.LoadByTicketID(ctx, id.ID)
+	if et != nil {
+		return et
+	}
+
+	if incident.Provider != s.statusPage.Provider() {
+		return fmt.Errorf("incident of ticket %d belongs to %v", incident.TicketID, incident.Provider)
+	}
+
+	externalID := incident.ExternalID
+	var e error
+	if externalID == "" {
+		externalID, e = s.statusPage.Create(ctx, incident)
+	} else {
+		e = s.statusPage.Update(ctx, incident)
+	}
+
+	status, failure := models.ReferenceSyncStatusSynced, ""
+	if e != nil {
+		status, failure = models.ReferenceSyncStatusFailed, e.Error()
+	}
+
+	unmodified, et := s.incidentRepository.UpdateSyncStatus(ctx, incident.TicketID, incident.ModifiedAt, externalID,
+		status, failure)
+	if et != nil {
+		return et
+	}
+
+	if e != nil {
+		return e
+	}
+
+	if !unmodified {
+		return fmt.Errorf("incident of ticket %d got modified while being published", incident.TicketID)
+	}
+
+	return nil
+}
+
+func (s *StatusPageService) reply(msg *nc.Msg, t interface{}) {
+	reply, _ := json.Marshal(t)
+	_ = msg.Respond(reply)
+}
+
+// Stop stops the component and drains its subscriptions, blocking until their in-flight messages are handled.
+func (s *StatusPageService) Stop() {
+	s.stop <- struct{}{}
+	<-s.stop
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// TenantService is a service implementation of tenant functionalities. Internal products get onboarded as tenants,
+// with their issuer settings and an API key provisioned at once, and deactivated tenants can not open tickets anymore.
+type TenantService struct {
+	logger           *zap.SugaredLogger
+	tenantRepository *models.TenantRepository
+	natsClient       *nc.Conn
+	stop             chan struct{}
+}
+
+// NewTenantService returns a newly created and ready to use TenantService.
+func NewTenantService(logger *zap.SugaredLogger, db *pgxpool.Pool, natsClient *nc.Conn) *TenantService {
+	return &TenantService{
+		logger:           logger,
+		tenantRepository: models.NewTenantRepository(logger, db),
+		natsClient:       natsClient,
+		stop:             make(chan struct{}),
+	}
+}
+
+// Start starts the subscriptions so ready to be notified.
+func (s *TenantService) Start() error {
+	provisionSubscription, e := s.natsClient.QueueSubscribe("kiosk.tenants.provision",
+		"kiosk.tenants.provision_group", s.provision)
+	if e != nil {
+		return e
+	}
+
+	deactivateSubscription, e := s.natsClient.QueueSubscribe("kiosk.tenants.deactivate",
+		"kiosk.tenants.deactivate_group", s.deactivate)
+	if e != nil {
+		return e
+	}
+
+	loadSubscription, e := s.natsClient.QueueSubscribe("kiosk.tenants.load", "kiosk.tenants.load_group", s.load)
+	if e != nil {
+		return e
+	}
+
+	listSubscription, e := s.natsClient.QueueSubscribe("kiosk.tenants.list", "kiosk.tenants.list_group", s.list)
+	if e != nil {
+		return e
+	}
+
+	authenticateSubscription, e := s.natsClient.QueueSubscribe("kiosk.tenants.authenticate",
+		"kiosk.tenants.authenticate_group", s.authenticate)
+	if e != nil {
+		return e
+	}
+
+	go s.await(provisionSubscription, deactivateSubscription, loadSubscription, listSubscription,
+		authenticateSubscription)
+
+	return nil
+}
+
+func (s *TenantService) await(ss ...*nc.Subscription) {
+	<-s.stop
+	s.logger.Debug("TenantService: received stop signal!")
+
+	drain(s.logger, ss)
+	s.stop <- struct{}{}
+}
+
+func (s *TenantService) provision(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	provisionTenantRequest := &data.ProvisionTenantRequest{}
+	if e := json.Unmarshal(msg.Data, provisionTenantRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := provisionTenantRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	key, apiKey, err := models.GenerateTenantAPIKey(provisionTenantRequest.Issuer)
+	if err != nil {
+		et := errors.InternalServerError("unknown", "")
+		s.logger.Error(et.FingerPrint, ": ", err.Error())
+		s.reply(msg, et)
+		return
+	}
+
+	e := s.tenantRepository.Provision(ctx, *provisionTenantRequest.AsTenant(),
+		*provisionTenantRequest.Settings.AsIssuerSettings(), *apiKey)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	tenant, e := s.tenantRepository.Load(ctx, provisionTenantRequest.Issuer)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.logger.Info("Provisioned tenant ", tenant.Issuer, " with API key ", apiKey.Prefix, "...")
+
+	provisionTenantResponse := &data.ProvisionTenantResponse{APIKey: key}
+	provisionTenantResponse.Tenant.LoadFromTenant(tenant)
+	s.reply(msg, provisionTenantResponse)
+}
+
+func (s *TenantService) deactivate(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	issuerRequest := &data.IssuerRequest{}
+	if e := json.Unmarshal(msg.Data, issuerRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := issuerRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if e := s.tenantRepository.Deactivate(ctx, issuerRequest.Issuer); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.logger.Info("Deactivated tenant ", issuerRequest.Issuer)
+	s.replyNoContent(msg)
+}
+
+func (s *TenantService) load(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	issuerRequest := &data.IssuerRequest{}
+	if e := json.Unmarshal(msg.Data, issuerRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := issuerRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	tenant, e := s.tenantRepository.Load(ctx, issuerRequest.Issuer)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	tenantResponse := &data.TenantResponse{}
+	tenantResponse.LoadFromTenant(tenant)
+	s.reply(msg, tenantResponse)
+}
+
+func (s *TenantService) list(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tenants, e := s.tenantRepository.LoadAll(ctx)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	tenantsResponse := &data.TenantsResponse{}
+	tenantsResponse.LoadFromTenants(tenants)
+	s.reply(msg, tenantsResponse)
+}
+
+// authenticate replies the issuer of an API key, for the gateways in front of kiosk to authenticate the clients of
+// tenants.
+func (s *TenantService) authenticate(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	authenticateTenantRequest := &data.AuthenticateTenantRequest{}
+	if e := json.Unmarshal(msg.Data, authenticateTenantRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := authenticateTenantRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	issuer, e := s.tenantRepository.Authenticate(ctx, authenticateTenantRequest.APIKey)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.reply(msg, &data.AuthenticateTenantResponse{Issuer: issuer})
+}
+
+func (s *TenantService) reply(msg *nc.Msg, t interface{}) {
+	reply, _ := json.Marshal(t)
+	_ = msg.Respond(reply)
+}
+
+func (s *TenantService) replyNoContent(msg *nc.Msg) {
+	_ = msg.Respond([]byte(""))
+}
+
+// Stop stops the component and drains its subscriptions, blocking until their in-flight messages are handled.
+func (s *TenantService) Stop() {
+	s.stop <- struct{}{}
+	<-s.stop
+}
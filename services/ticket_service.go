@@ -6,33 +6,127 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/db/postgres"
+	"github.com/jibitters/kiosk/documents"
 	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/jobs"
 	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/pagination"
 	"github.com/jibitters/kiosk/web/data"
 	nc "github.com/nats-io/nats.go"
 	"go.uber.org/zap"
 )
 
+// archiveBatchSize is the number of tickets archived in a single transaction.
+const archiveBatchSize = 500
+
+// systemCommentOwner is the owner of the comments kiosk adds to tickets on its own.
+const systemCommentOwner = "kiosk"
+
+// ticketChangesChannel is the postgres channel the changes of tickets are broadcast on.
+const ticketChangesChannel = "kiosk_ticket_changes"
+
+// WaitingPolicy configures how tickets waiting on their customers are handled. Their owners get nudged by a reminder
+// comment, and so by email when notifications are enabled, after each NudgeAfter of silence and the tickets get
+// closed after CloseAfter of silence. A zero duration disables the corresponding step.
+type WaitingPolicy struct {
+	NudgeAfter   time.Duration
+	CloseAfter   time.Duration
+	NudgeMessage string
+}
+
 // TicketService is a service implementation of ticket related functionalities.
 type TicketService struct {
-	logger           *zap.SugaredLogger
-	ticketRepository *models.TicketRepository
-	natsClient       *nc.Conn
-	stop             chan struct{}
+	logger                   *zap.SugaredLogger
+	ticketRepository         TicketRepository
+	commentRepository        CommentRepository
+	replicaTicketRepository  TicketRepository
+	readOnlyTicketRepository TicketRepository
+	shadowReader             *shadowReader
+	revisionRepository       *models.TicketRevisionRepository
+	workLogRepository        *models.WorkLogRepository
+	resolutionRepository     *models.ResolutionCategoryRepository
+	consistencyRepository    *models.ConsistencyRepository
+	issuerSettingsRepository *models.IssuerSettingsRepository
+	slaTargetRepository      *models.SLATargetRepository
+	tenantRepository         *models.TenantRepository
+	invariantRepository      *models.InvariantRepository
+	macroRepository          *models.MacroRepository
+	duplicateRepository      *models.DuplicateRepository
+	incidentRepository       *models.IncidentRepository
+	archiveRepository        *models.ArchiveRepository
+	attachmentRepository     *models.AttachmentRepository
+	natsClient               *nc.Conn
+	countersCache            *countersCache
+	changesListener          *postgres.Listener
+	pool                     *jobs.Pool
+	waitingPolicy            WaitingPolicy
+	deduplicationWindow      time.Duration
+	archiveAfter             time.Duration
+	pageTokens               *pagination.Tokens
+	identities               *Identities
+	stop                     chan struct{}
 }
 
-// NewTicketService returns a newly created and ready to use TicketService.
-func NewTicketService(logger *zap.SugaredLogger, db *pgxpool.Pool, natsClient *nc.Conn) *TicketService {
-	return &TicketService{
-		logger:           logger,
-		ticketRepository: models.NewTicketRepository(logger, db),
-		natsClient:       natsClient,
-		stop:             make(chan struct{}),
+// NewTicketService returns a newly created and ready to use TicketService. Filtering tickets is served by the replica
+// when one is provided, otherwise or when it lags behind by readOnly, the pool of the restricted read only role, when
+// one is provided. Tickets created with the fingerprint of a ticket created within the deduplication window are
+// appended to it as comments, a zero window disables deduplication. Closed tickets not modified for archiveAfter are
+// archived, a zero duration disables archiving. Pages of filtered tickets are continued by the page tokens it issues.
+// A percentage of filters is shadowed by the repository of shadowReads, whatever serves them. The assignees and owners
+// of comments of the tickets it replies to agents are enriched by the identities, if any.
+func NewTicketService(logger *zap.SugaredLogger, db, replica, readOnly *pgxpool.Pool, natsClient *nc.Conn,
+	pool *jobs.Pool, waitingPolicy WaitingPolicy, deduplicationWindow, archiveAfter time.Duration,
+	pageTokens *pagination.Tokens, shadowReads ShadowReads, identities *Identities) *TicketService {
+
+	s := &TicketService{
+		logger:                   logger,
+		ticketRepository:         models.NewTicketRepository(logger, db),
+		commentRepository:        models.NewCommentRepository(logger, db),
+		revisionRepository:       models.NewTicketRevisionRepository(logger, db),
+		workLogRepository:        models.NewWorkLogRepository(logger, db),
+		resolutionRepository:     models.NewResolutionCategoryRepository(logger, db),
+		consistencyRepository:    models.NewConsistencyRepository(logger, db, replica),
+		issuerSettingsRepository: models.NewIssuerSettingsRepository(logger, db),
+		slaTargetRepository:      models.NewSLATargetRepository(logger, db),
+		tenantRepository:         models.NewTenantRepository(logger, db),
+		invariantRepository:      models.NewInvariantRepository(logger, db),
+		macroRepository:          models.NewMacroRepository(logger, db),
+		duplicateRepository:      models.NewDuplicateRepository(logger, db),
+		incidentRepository:       models.NewIncidentRepository(logger, db),
+		archiveRepository:        models.NewArchiveRepository(logger, db),
+		attachmentRepository:     models.NewAttachmentRepository(logger, db),
+		natsClient:               natsClient,
+		countersCache:            newCountersCache(),
+		pool:                     pool,
+		waitingPolicy:            waitingPolicy,
+		deduplicationWindow:      deduplicationWindow,
+		archiveAfter:             archiveAfter,
+		pageTokens:               pageTokens,
+		shadowReader:             newShadowReader(logger, shadowReads),
+		identities:               identities,
+		stop:                     make(chan struct{}),
+	}
+
+	if replica != nil {
+		s.replicaTicketRepository = models.NewTicketRepository(logger, replica)
 	}
+
+	if readOnly != nil {
+		s.readOnlyTicketRepository = models.NewTicketRepository(logger, readOnly)
+	}
+
+	s.changesListener = postgres.NewListener(logger, db, ticketChangesChannel, s.onTicketChange,
+		s.countersCache.invalidateAll)
+
+	return s
 }
 
 // Start starts the subscriptions so ready to be notified.
 func (s *TicketService) Start() error {
+	s.pool.Register(reindexTicketsJob, time.Hour, s.reindexJob)
+	s.pool.Register(archiveTicketsJob, 10*time.Minute, s.archiveJob)
+
 	createTicketSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.create",
 		"kiosk.tickets.create_group", s.create)
 	if e != nil {
@@ -45,12 +139,120 @@ func (s *TicketService) Start() error {
 		return e
 	}
 
+	loadPublicTicketSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.public.load",
+		"kiosk.tickets.public.load_group", s.loadPublic)
+	if e != nil {
+		return e
+	}
+
 	updateTicketSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.update",
 		"kiosk.tickets.update_group", s.update)
 	if e != nil {
 		return e
 	}
 
+	ticketRevisionsSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.revisions",
+		"kiosk.tickets.revisions_group", s.revisions)
+	if e != nil {
+		return e
+	}
+
+	logWorkSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.work.log",
+		"kiosk.tickets.work.log_group", s.logWork)
+	if e != nil {
+		return e
+	}
+
+	workLogsSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.work.list",
+		"kiosk.tickets.work.list_group", s.workLogs)
+	if e != nil {
+		return e
+	}
+
+	setBillableSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.billable",
+		"kiosk.tickets.billable_group", s.setBillable)
+	if e != nil {
+		return e
+	}
+
+	createMacroSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.macros.create",
+		"kiosk.tickets.macros.create_group", s.createMacro)
+	if e != nil {
+		return e
+	}
+
+	listMacrosSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.macros.list",
+		"kiosk.tickets.macros.list_group", s.listMacros)
+	if e != nil {
+		return e
+	}
+
+	updateMacroSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.macros.update",
+		"kiosk.tickets.macros.update_group", s.updateMacro)
+	if e != nil {
+		return e
+	}
+
+	deleteMacroSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.macros.delete",
+		"kiosk.tickets.macros.delete_group", s.deleteMacro)
+	if e != nil {
+		return e
+	}
+
+	applyMacroSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.macros.apply",
+		"kiosk.tickets.macros.apply_group", s.applyMacro)
+	if e != nil {
+		return e
+	}
+
+	duplicateTicketsSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.duplicates",
+		"kiosk.tickets.duplicates_group", s.duplicates)
+	if e != nil {
+		return e
+	}
+
+	mergeTicketsSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.merge",
+		"kiosk.tickets.merge_group", s.merge)
+	if e != nil {
+		return e
+	}
+
+	createIncidentSubscription, e := s.natsClient.QueueSubscribe("kiosk.incidents.create",
+		"kiosk.incidents.create_group", s.createIncident)
+	if e != nil {
+		return e
+	}
+
+	loadIncidentSubscription, e := s.natsClient.QueueSubscribe("kiosk.incidents.load",
+		"kiosk.incidents.load_group", s.loadIncident)
+	if e != nil {
+		return e
+	}
+
+	listIncidentsSubscription, e := s.natsClient.QueueSubscribe("kiosk.incidents.list",
+		"kiosk.incidents.list_group", s.listIncidents)
+	if e != nil {
+		return e
+	}
+
+	attachIncidentTicketsSubscription, e := s.natsClient.QueueSubscribe("kiosk.incidents.tickets.attach",
+		"kiosk.incidents.tickets.attach_group", s.attachIncidentTickets)
+	if e != nil {
+		return e
+	}
+
+	detachIncidentTicketSubscription, e := s.natsClient.QueueSubscribe("kiosk.incidents.tickets.detach",
+		"kiosk.incidents.tickets.detach_group", s.detachIncidentTicket)
+	if e != nil {
+		return e
+	}
+
+	resolveIncidentSubscription, e := s.natsClient.QueueSubscribe("kiosk.incidents.resolve",
+		"kiosk.incidents.resolve_group", s.resolveIncident)
+	if e != nil {
+		return e
+	}
+
 	deleteTicketSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.delete",
 		"kiosk.tickets.delete_group", s.delete)
 	if e != nil {
@@ -63,8 +265,78 @@ func (s *TicketService) Start() error {
 		return e
 	}
 
-	go s.await(createTicketSubscription, loadTicketSubscription, updateTicketSubscription, deleteTicketSubscription,
-		filterTicketsSubscription)
+	ticketCountersSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.counters",
+		"kiosk.tickets.counters_group", s.counters)
+	if e != nil {
+		return e
+	}
+
+	exportTicketPDFSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.export_pdf",
+		"kiosk.tickets.export_pdf_group", s.exportPDF)
+	if e != nil {
+		return e
+	}
+
+	renderTicketTextSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.render_text",
+		"kiosk.tickets.render_text_group", s.renderText)
+	if e != nil {
+		return e
+	}
+
+	reindexTicketsSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.reindex",
+		"kiosk.tickets.reindex_group", s.reindex)
+	if e != nil {
+		return e
+	}
+
+	snoozeTicketSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.snooze",
+		"kiosk.tickets.snooze_group", s.snooze)
+	if e != nil {
+		return e
+	}
+
+	assignTicketSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.assign",
+		"kiosk.tickets.assign_group", s.assign)
+	if e != nil {
+		return e
+	}
+
+	unassignTicketSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.unassign",
+		"kiosk.tickets.unassign_group", s.unassign)
+	if e != nil {
+		return e
+	}
+
+	filterByAssigneeSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.filter_by_assignee",
+		"kiosk.tickets.filter_by_assignee_group", s.filterByAssignee)
+	if e != nil {
+		return e
+	}
+
+	commentCreatedSubscription, e := s.natsClient.QueueSubscribe(commentCreatedSubject, "kiosk.snooze_group",
+		s.onCommentCreated)
+	if e != nil {
+		return e
+	}
+
+	ticketApprovedSubscription, e := s.natsClient.QueueSubscribe(ticketApprovedSubject, "kiosk.approvals_group",
+		s.onTicketApproved)
+	if e != nil {
+		return e
+	}
+
+	s.changesListener.Start()
+	go s.await(createTicketSubscription, loadTicketSubscription, loadPublicTicketSubscription,
+		updateTicketSubscription, ticketRevisionsSubscription, logWorkSubscription, workLogsSubscription,
+		setBillableSubscription, createMacroSubscription, listMacrosSubscription, updateMacroSubscription,
+		deleteMacroSubscription, applyMacroSubscription, duplicateTicketsSubscription, mergeTicketsSubscription,
+		createIncidentSubscription, loadIncidentSubscription, listIncidentsSubscription,
+		attachIncidentTicketsSubscription, detachIncidentTicketSubscription, resolveIncidentSubscription,
+		deleteTicketSubscription,
+		filterTicketsSubscription, ticketCountersSubscription, exportTicketPDFSubscription,
+		renderTicketTextSubscription, reindexTicketsSubscription, snoozeTicketSubscription,
+		assignTicketSubscription, unassignTicketSubscription, filterByAssigneeSubscription,
+		commentCreatedSubscription, ticketApprovedSubscription)
 
 	return nil
 }
@@ -73,9 +345,8 @@ func (s *TicketService) await(ss ...*nc.Subscription) {
 	<-s.stop
 	s.logger.Debug("TicketService: received stop signal!")
 
-	for _, s := range ss {
-		_ = s.Unsubscribe()
-	}
+	drain(s.logger, ss)
+	s.stop <- struct{}{}
 }
 
 func (s *TicketService) create(msg *nc.Msg) {
@@ -93,12 +364,90 @@ func (s *TicketService) create(msg *nc.Msg) {
 		return
 	}
 
-	if e := s.ticketRepository.Insert(ctx, *createTicketRequest.AsTicket()); e != nil {
+	// With row level security enabled, the ticket can only be written on behalf of its issuer.
+	ctx = postgres.WithTenant(ctx, createTicketRequest.Issuer)
+
+	active, e := s.tenantRepository.IsActive(ctx, createTicketRequest.Issuer)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if !active {
+		s.reply(msg, errors.PreconditionFailed("tenant.deactivated", ""))
+		return
+	}
+
+	if createTicketRequest.Fingerprint != "" && s.deduplicationWindow > 0 && s.appendDuplicate(ctx, msg,
+		createTicketRequest) {
+
+		return
+	}
+
+	settings, e := s.issuerSettingsRepository.LoadByIssuer(ctx, createTicketRequest.Issuer)
+	if e != nil {
 		s.reply(msg, e)
 		return
 	}
 
-	s.replyNoContent(msg)
+	createTicketRequest.ApplyDefaults(settings)
+
+	ticket := createTicketRequest.AsTicket()
+	target, e := s.slaTargetRepository.Load(ctx, settings.Tier, ticket.ImportanceLevel)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	ticket.Tier = settings.Tier
+	target.Apply(ticket, time.Now())
+
+	id, e := s.ticketRepository.Insert(ctx, *ticket)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	replyConsistencyToken(ctx, s.consistencyRepository, msg)
+
+	ticket.ID = id
+	ticket.CreatedAt = time.Now()
+	ticket.ModifiedAt = ticket.CreatedAt
+	s.publishCounterDelta(ticket.Owner, ticket.Status, 1)
+	publishTicketEvent(s.logger, s.natsClient, ticketCreatedSubject, data.EventTypeTicketCreated, ticket, "", "")
+}
+
+// appendDuplicate appends the ticket being created as a comment of the ticket of the issuer with the same fingerprint
+// created within the deduplication window, if any. It reports whether the request is handled.
+func (s *TicketService) appendDuplicate(ctx context.Context, msg *nc.Msg,
+	createTicketRequest *data.CreateTicketRequest) bool {
+
+	id, e := s.ticketRepository.LoadIDByFingerprint(ctx, createTicketRequest.Issuer, createTicketRequest.Fingerprint,
+		time.Now().UTC().Add(-s.deduplicationWindow))
+	if e != nil {
+		if e.Kind == errors.KindNotFound {
+			return false
+		}
+
+		s.reply(msg, e)
+		return true
+	}
+
+	comment := &models.Comment{TicketID: id, Owner: createTicketRequest.Owner, Content: createTicketRequest.Content,
+		Metadata: createTicketRequest.Metadata, Source: models.CommentSourceAPI}
+	commentID, e := s.commentRepository.Insert(ctx, *comment)
+	if e != nil {
+		s.reply(msg, e)
+		return true
+	}
+
+	replyConsistencyToken(ctx, s.consistencyRepository, msg)
+
+	comment.ID = commentID
+	comment.CreatedAt = time.Now()
+	comment.ModifiedAt = comment.CreatedAt
+	publishCommentEvent(s.logger, s.natsClient, commentCreatedSubject, data.EventTypeCommentCreated, comment)
+	return true
 }
 
 func (s *TicketService) load(msg *nc.Msg) {
@@ -111,12 +460,58 @@ func (s *TicketService) load(msg *nc.Msg) {
 		return
 	}
 
-	t, e := s.ticketRepository.LoadByID(ctx, id.ID)
+	t, e := s.loadByID(ctx, id.ID)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	ticketResponse := &data.TicketResponse{}
+	ticketResponse.LoadFromTicket(t)
+	s.identities.EnrichTicket(ctx, ticketResponse)
+	s.reply(msg, ticketResponse)
+}
+
+// loadPublic loads a ticket of the issuer by its public id, the one customers get within emails and URLs. Tickets of
+// other issuers are not found, even if the public id decodes to their ids.
+func (s *TicketService) loadPublic(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	publicTicketRequest := &data.PublicTicketRequest{}
+	if e := json.Unmarshal(msg.Data, publicTicketRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := publicTicketRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	settings, e := s.issuerSettingsRepository.LoadByIssuer(ctx, publicTicketRequest.Issuer)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	id, ok := settings.TicketID(publicTicketRequest.PublicID)
+	if !ok {
+		s.reply(msg, errors.NotFound("ticket.not_found", ""))
+		return
+	}
+
+	t, e := s.loadByID(ctx, id)
 	if e != nil {
 		s.reply(msg, e)
 		return
 	}
 
+	if t.Issuer != publicTicketRequest.Issuer {
+		s.reply(msg, errors.NotFound("ticket.not_found", ""))
+		return
+	}
+
 	ticketResponse := &data.TicketResponse{}
 	ticketResponse.LoadFromTicket(t)
 	s.reply(msg, ticketResponse)
@@ -137,70 +532,1385 @@ func (s *TicketService) update(msg *nc.Msg) {
 		return
 	}
 
-	if e := s.ticketRepository.Update(ctx, updateTicketRequest.AsTicket()); e != nil {
+	ticket := updateTicketRequest.AsTicket()
+	if ticket.Resolution.Category != "" {
+		exists, e := s.resolutionRepository.Exists(ctx, ticket.Resolution.Category, ticket.Resolution.SubCategory)
+		if e != nil {
+			s.reply(msg, e)
+			return
+		}
+
+		if !exists {
+			s.reply(msg, errors.PreconditionFailed("resolutionCategory.not_found", ""))
+			return
+		}
+	}
+
+	// The transition requirements and approvers are checked by the update itself, with the ticket locked.
+	previous, e := s.ticketRepository.Update(ctx, ticket, updateTicketRequest.Actor)
+	if e != nil {
 		s.reply(msg, e)
 		return
 	}
 
-	s.replyNoContent(msg)
+	replyConsistencyToken(ctx, s.consistencyRepository, msg)
+
+	if previous.Status != ticket.Status {
+		s.publishCounterDelta(previous.Owner, previous.Status, -1)
+		s.publishCounterDelta(previous.Owner, ticket.Status, 1)
+	}
+
+	ticket.Issuer = previous.Issuer
+	ticket.Owner = previous.Owner
+	ticket.ModifiedAt = time.Now()
+	publishTicketEvent(s.logger, s.natsClient, ticketUpdatedSubject, data.EventTypeTicketUpdated, ticket,
+		previous.Status, updateTicketRequest.Actor)
 }
 
-func (s *TicketService) delete(msg *nc.Msg) {
+// revisions replies the previous revisions of the subject and content of a ticket.
+func (s *TicketService) revisions(msg *nc.Msg) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	id := &data.ID{}
-	if e := json.Unmarshal(msg.Data, id); e != nil {
+	ticketRevisionsRequest := &data.TicketRevisionsRequest{}
+	if e := json.Unmarshal(msg.Data, ticketRevisionsRequest); e != nil {
 		s.reply(msg, errors.InvalidRequestBody())
 		return
 	}
 
-	if e := s.ticketRepository.DeleteByID(ctx, id.ID); e != nil {
+	if e := ticketRevisionsRequest.Validate(); e != nil {
 		s.reply(msg, e)
 		return
 	}
 
-	s.replyNoContent(msg)
+	after, e := pageCursor(s.pageTokens, ticketRevisionsRequest.PageToken, ticketRevisionsPageList, nil)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	revisions, hasMore, e := s.revisionRepository.LoadByTicketID(ctx, ticketRevisionsRequest.TicketID, after,
+		ticketRevisionsRequest.PageSize)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	ticketRevisionsResponse := &data.TicketRevisionsResponse{}
+	ticketRevisionsResponse.LoadFromTicketRevisions(ticketRevisionsRequest.TicketID, revisions)
+	if hasMore {
+		ticketRevisionsResponse.NextPageToken = s.pageTokens.Issue(ticketRevisionsPageList, nil,
+			revisions[len(revisions)-1].Cursor())
+	}
+
+	s.reply(msg, ticketRevisionsResponse)
 }
 
-func (s *TicketService) filter(msg *nc.Msg) {
+// logWork records time an agent spent on a ticket and adds it to the total time spent on the ticket.
+func (s *TicketService) logWork(msg *nc.Msg) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	filterTicketsRequest := &data.FilterTicketsRequest{}
-	if e := json.Unmarshal(msg.Data, filterTicketsRequest); e != nil {
+	logWorkRequest := &data.LogWorkRequest{}
+	if e := json.Unmarshal(msg.Data, logWorkRequest); e != nil {
 		s.reply(msg, errors.InvalidRequestBody())
 		return
 	}
 
-	if e := filterTicketsRequest.Validate(); e != nil {
+	if e := logWorkRequest.Validate(); e != nil {
 		s.reply(msg, e)
 		return
 	}
 
-	ts, hasNextPage, e := s.ticketRepository.Filter(ctx, filterTicketsRequest.Issuer, filterTicketsRequest.Owner,
-		filterTicketsRequest.ImportanceLevel, filterTicketsRequest.Status, filterTicketsRequest.FromDate,
-		filterTicketsRequest.ToDate, filterTicketsRequest.PageNumber, filterTicketsRequest.PageSize)
-	if e != nil {
+	if _, e := s.workLogRepository.Insert(ctx, *logWorkRequest.AsWorkLog()); e != nil {
 		s.reply(msg, e)
 		return
 	}
 
-	filterTicketsResponse := &data.FilterTicketsResponse{}
-	filterTicketsResponse.LoadFromTickets(ts, hasNextPage)
-	s.reply(msg, filterTicketsResponse)
+	replyConsistencyToken(ctx, s.consistencyRepository, msg)
 }
 
-func (s *TicketService) reply(msg *nc.Msg, t interface{}) {
-	reply, _ := json.Marshal(t)
-	_ = msg.Respond(reply)
-}
+func (s *TicketService) workLogs(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-func (s *TicketService) replyNoContent(msg *nc.Msg) {
-	_ = msg.Respond([]byte(""))
+	workLogsRequest := &data.WorkLogsRequest{}
+	if e := json.Unmarshal(msg.Data, workLogsRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := workLogsRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	logs, e := s.workLogRepository.LoadByTicketID(ctx, workLogsRequest.TicketID)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	workLogsResponse := &data.WorkLogsResponse{}
+	workLogsResponse.LoadFromWorkLogs(logs)
+	s.reply(msg, workLogsResponse)
 }
 
-// Stop stops the component and it subscriptions.
-func (s *TicketService) Stop() {
-	s.stop <- struct{}{}
+// setBillable flags a ticket as billable or not billable, so its time spent is invoiced or not.
+func (s *TicketService) setBillable(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	setBillableRequest := &data.SetBillableRequest{}
+	if e := json.Unmarshal(msg.Data, setBillableRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := setBillableRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if e := s.ticketRepository.SetBillable(ctx, setBillableRequest.TicketID, setBillableRequest.Billable); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	replyConsistencyToken(ctx, s.consistencyRepository, msg)
+}
+
+func (s *TicketService) createMacro(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createMacroRequest := &data.CreateMacroRequest{}
+	if e := json.Unmarshal(msg.Data, createMacroRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := createMacroRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	id, e := s.macroRepository.Insert(ctx, *createMacroRequest.AsMacro())
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.reply(msg, &data.ID{ID: id})
+}
+
+func (s *TicketService) listMacros(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	listMacrosRequest := &data.ListMacrosRequest{}
+	if e := json.Unmarshal(msg.Data, listMacrosRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := listMacrosRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	after, e := pageCursor(s.pageTokens, listMacrosRequest.PageToken, macrosPageList, nil)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	macros, hasMore, e := s.macroRepository.LoadAll(ctx, after, listMacrosRequest.PageSize)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	macrosResponse := &data.MacrosResponse{}
+	macrosResponse.LoadFromMacros(macros)
+	if hasMore {
+		macrosResponse.NextPageToken = s.pageTokens.Issue(macrosPageList, nil, macros[len(macros)-1].Cursor())
+	}
+
+	s.reply(msg, macrosResponse)
+}
+
+func (s *TicketService) updateMacro(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	updateMacroRequest := &data.UpdateMacroRequest{}
+	if e := json.Unmarshal(msg.Data, updateMacroRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := updateMacroRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if e := s.macroRepository.Update(ctx, *updateMacroRequest.AsMacro()); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+func (s *TicketService) deleteMacro(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	deleteMacroRequest := &data.DeleteMacroRequest{}
+	if e := json.Unmarshal(msg.Data, deleteMacroRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := deleteMacroRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if e := s.macroRepository.DeleteByID(ctx, deleteMacroRequest.ID); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+// applyMacro applies the actions of a macro to a ticket at once. Moving the ticket to the status of the macro is
+// subject to the same transition requirements and approvals updates are, and the usual events are published for the
+// status change and the canned comment. The canned comment is posted in the language best matching the ones asked for.
+func (s *TicketService) applyMacro(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	applyMacroRequest := &data.ApplyMacroRequest{}
+	if e := json.Unmarshal(msg.Data, applyMacroRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := applyMacroRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	macro, e := s.macroRepository.LoadByID(ctx, applyMacroRequest.MacroID)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	macro.Comment = macro.Localize(applyMacroRequest.Languages)
+	previous, commentID, e := s.macroRepository.Apply(ctx, applyMacroRequest.TicketID, macro,
+		applyMacroRequest.Actor)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	replyConsistencyToken(ctx, s.consistencyRepository, msg)
+
+	if macro.Status != "" {
+		if previous.Status != macro.Status {
+			s.publishCounterDelta(previous.Owner, previous.Status, -1)
+			s.publishCounterDelta(previous.Owner, macro.Status, 1)
+		}
+
+		if ticket, e := s.ticketRepository.LoadByID(ctx, applyMacroRequest.TicketID); e == nil {
+			publishTicketEvent(s.logger, s.natsClient, ticketUpdatedSubject, data.EventTypeTicketUpdated, ticket,
+				previous.Status, applyMacroRequest.Actor)
+		}
+	}
+
+	if commentID != 0 {
+		comment := &models.Comment{Model: models.Model{ID: commentID}, TicketID: applyMacroRequest.TicketID,
+			Owner: applyMacroRequest.Actor, Content: macro.Comment, AuthorType: models.CommentAuthorTypeAgent,
+			Source: models.CommentSourceAPI, Revision: 1}
+		comment.CreatedAt = time.Now()
+		comment.ModifiedAt = comment.CreatedAt
+		publishCommentEvent(s.logger, s.natsClient, commentCreatedSubject, data.EventTypeCommentCreated, comment)
+	}
+}
+
+// duplicates clusters the open tickets likely reported for the same issue, i.e. of the same owner and with similar
+// subjects, so they can be merged after incidents.
+func (s *TicketService) duplicates(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	duplicateTicketsRequest := &data.DuplicateTicketsRequest{}
+	if e := json.Unmarshal(msg.Data, duplicateTicketsRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := duplicateTicketsRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	fromDate, _ := time.Parse(time.RFC3339Nano, duplicateTicketsRequest.FromDate)
+	clusters, e := s.duplicateRepository.LoadClusters(ctx, duplicateTicketsRequest.Issuer, fromDate,
+		duplicateTicketsRequest.MinSimilarity, duplicateTicketsRequest.Limit)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	duplicateClustersResponse := &data.DuplicateClustersResponse{}
+	duplicateClustersResponse.LoadFromDuplicateClusters(clusters)
+	s.reply(msg, duplicateClustersResponse)
+}
+
+// merge merges duplicates into a ticket at once, either all of them get merged or none. The usual events are
+// published for the duplicates getting closed and the note posted on the ticket.
+func (s *TicketService) merge(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	mergeTicketsRequest := &data.MergeTicketsRequest{}
+	if e := json.Unmarshal(msg.Data, mergeTicketsRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := mergeTicketsRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	previous, noteID, e := s.duplicateRepository.Merge(ctx, mergeTicketsRequest.TargetID,
+		mergeTicketsRequest.DuplicateIDs, mergeTicketsRequest.Actor)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	replyConsistencyToken(ctx, s.consistencyRepository, msg)
+
+	for _, p := range previous {
+		if p.Status != models.TicketStatusClosed {
+			s.publishCounterDelta(p.Owner, p.Status, -1)
+			s.publishCounterDelta(p.Owner, models.TicketStatusClosed, 1)
+		}
+
+		if ticket, e := s.ticketRepository.LoadByID(ctx, p.ID); e == nil {
+			publishTicketEvent(s.logger, s.natsClient, ticketUpdatedSubject, data.EventTypeTicketUpdated, ticket,
+				p.Status, mergeTicketsRequest.Actor)
+		}
+	}
+
+	if note, e := s.commentRepository.LoadByID(ctx, noteID); e == nil {
+		publishCommentEvent(s.logger, s.natsClient, commentCreatedSubject, data.EventTypeCommentCreated, note)
+	}
+}
+
+func (s *TicketService) createIncident(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createIncidentRequest := &data.CreateIncidentRequest{}
+	if e := json.Unmarshal(msg.Data, createIncidentRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := createIncidentRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	id, e := s.incidentRepository.Insert(ctx, *createIncidentRequest.AsIncident())
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.reply(msg, &data.ID{ID: id})
+}
+
+func (s *TicketService) loadIncident(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	id := &data.ID{}
+	if e := json.Unmarshal(msg.Data, id); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	incident, e := s.incidentRepository.LoadByID(ctx, id.ID)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	incidentResponse := &data.IncidentResponse{}
+	incidentResponse.LoadFromIncident(incident)
+	s.reply(msg, incidentResponse)
+}
+
+func (s *TicketService) listIncidents(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	listIncidentsRequest := &data.ListIncidentsRequest{}
+	if e := json.Unmarshal(msg.Data, listIncidentsRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	incidents, e := s.incidentRepository.LoadAll(ctx, listIncidentsRequest.Resolved)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	incidentsResponse := &data.IncidentsResponse{}
+	incidentsResponse.LoadFromIncidents(incidents)
+	s.reply(msg, incidentsResponse)
+}
+
+func (s *TicketService) attachIncidentTickets(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	attachIncidentTicketsRequest := &data.AttachIncidentTicketsRequest{}
+	if e := json.Unmarshal(msg.Data, attachIncidentTicketsRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := attachIncidentTicketsRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	e := s.incidentRepository.Attach(ctx, attachIncidentTicketsRequest.IncidentID,
+		attachIncidentTicketsRequest.TicketIDs)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+func (s *TicketService) detachIncidentTicket(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	detachIncidentTicketRequest := &data.DetachIncidentTicketRequest{}
+	if e := json.Unmarshal(msg.Data, detachIncidentTicketRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := detachIncidentTicketRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	e := s.incidentRepository.Detach(ctx, detachIncidentTicketRequest.IncidentID,
+		detachIncidentTicketRequest.TicketID)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+// resolveIncident resolves an incident, posting the message rendered per ticket on each of its tickets and resolving
+// them if asked to, at once. Tickets are resolved subject to the same transition requirements and approvals updates
+// are, except the message counts as the comment of an agent, the ones falling short are skipped. The usual events are
+// published for the status changes and the comments.
+func (s *TicketService) resolveIncident(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resolveIncidentRequest := &data.ResolveIncidentRequest{}
+	if e := json.Unmarshal(msg.Data, resolveIncidentRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := resolveIncidentRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	resolution := resolveIncidentRequest.Resolution()
+	if resolution.Category != "" {
+		exists, e := s.resolutionRepository.Exists(ctx, resolution.Category, resolution.SubCategory)
+		if e != nil {
+			s.reply(msg, e)
+			return
+		}
+
+		if !exists {
+			s.reply(msg, errors.PreconditionFailed("resolutionCategory.not_found", ""))
+			return
+		}
+	}
+
+	incident, e := s.incidentRepository.LoadByID(ctx, resolveIncidentRequest.IncidentID)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	tickets, e := s.incidentRepository.LoadTickets(ctx, incident.ID)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	comments, e := s.incidentComments(incident, tickets, resolveIncidentRequest.Message, resolveIncidentRequest.Actor)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	// The transition requirements and approvers are checked by resolving itself, with the tickets locked.
+	resolvable := make([]int64, 0, len(tickets))
+	for _, ticket := range tickets {
+		if resolveIncidentRequest.ResolveTickets && ticket.Status != models.TicketStatusResolved &&
+			ticket.Status != models.TicketStatusClosed {
+
+			resolvable = append(resolvable, ticket.ID)
+		}
+	}
+
+	previous, skipped, e := s.incidentRepository.Resolve(ctx, incident.ID, comments, resolvable, resolution)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	response := &data.ResolveIncidentResponse{ResolvedTicketIDs: make([]int64, 0), SkippedTicketIDs: skipped}
+	response.ConsistencyToken.ConsistencyToken, _ = s.consistencyRepository.Token(ctx)
+	for _, p := range previous {
+		response.ResolvedTicketIDs = append(response.ResolvedTicketIDs, p.ID)
+	}
+	s.reply(msg, response)
+
+	for _, comment := range comments {
+		comment.CreatedAt = time.Now()
+		comment.ModifiedAt = comment.CreatedAt
+		publishCommentEvent(s.logger, s.natsClient, commentCreatedSubject, data.EventTypeCommentCreated, comment)
+	}
+
+	for _, p := range previous {
+		s.publishCounterDelta(p.Owner, p.Status, -1)
+		s.publishCounterDelta(p.Owner, models.TicketStatusResolved, 1)
+
+		if ticket, e := s.ticketRepository.LoadByID(ctx, p.ID); e == nil {
+			publishTicketEvent(s.logger, s.natsClient, ticketUpdatedSubject, data.EventTypeTicketUpdated, ticket,
+				p.Status, resolveIncidentRequest.Actor)
+		}
+	}
+}
+
+// incidentComments renders the message for each ticket of the incident as a comment of the agent, there are none when
+// the message is empty.
+func (s *TicketService) incidentComments(incident *models.Incident, tickets []*models.Ticket, message,
+	agent string) ([]*models.Comment, *errors.Type) {
+
+	comments := make([]*models.Comment, 0, len(tickets))
+	if message == "" {
+		return comments, nil
+	}
+
+	t, e := documents.ParseIncidentMessage(message)
+	if e != nil {
+		return nil, errors.InvalidArgument("message.not_valid", e.Error())
+	}
+
+	for _, ticket := range tickets {
+		content, e := documents.RenderIncidentMessage(t, incident, ticket)
+		if e != nil {
+			return nil, errors.InvalidArgument("message.not_valid", e.Error())
+		}
+
+		comments = append(comments, &models.Comment{TicketID: ticket.ID, Owner: agent, Content: content,
+			AuthorType: models.CommentAuthorTypeAgent, Source: models.CommentSourceAPI, Revision: 1})
+	}
+
+	return comments, nil
+}
+
+func (s *TicketService) delete(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	id := &data.ID{}
+	if e := json.Unmarshal(msg.Data, id); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	deleted, e := s.ticketRepository.DeleteByID(ctx, id.ID)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	replyConsistencyToken(ctx, s.consistencyRepository, msg)
+
+	if deleted != nil {
+		s.publishCounterDelta(deleted.Owner, deleted.Status, -1)
+		publishTicketEvent(s.logger, s.natsClient, ticketDeletedSubject, data.EventTypeTicketDeleted, deleted, "",
+			"")
+	}
+}
+
+// snooze hides a ticket from the active queues until the requested time, or until its owner replies.
+func (s *TicketService) snooze(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	snoozeTicketRequest := &data.SnoozeTicketRequest{}
+	if e := json.Unmarshal(msg.Data, snoozeTicketRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := snoozeTicketRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if e := s.ticketRepository.Snooze(ctx, snoozeTicketRequest.ID, snoozeTicketRequest.UntilTime()); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	replyConsistencyToken(ctx, s.consistencyRepository, msg)
+	s.publishSnoozeEvent(ctx, ticketSnoozedSubject, data.EventTypeTicketSnoozed, snoozeTicketRequest.ID,
+		snoozeTicketRequest.Actor)
+}
+
+// assign assigns a ticket to an agent, e.g. an agent claiming it. Tickets assigned to another agent are only taken over
+// when asked to.
+func (s *TicketService) assign(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assignTicketRequest := &data.AssignTicketRequest{}
+	if e := json.Unmarshal(msg.Data, assignTicketRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := assignTicketRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	previous, e := s.ticketRepository.Assign(ctx, assignTicketRequest.ID, assignTicketRequest.Assignee,
+		assignTicketRequest.Reassign)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	replyConsistencyToken(ctx, s.consistencyRepository, msg)
+	if previous != assignTicketRequest.Assignee {
+		s.publishAssignmentEvent(ctx, ticketAssignedSubject, data.EventTypeTicketAssigned, assignTicketRequest.ID,
+			previous, assignTicketRequest.Actor)
+	}
+}
+
+// unassign unassigns a ticket, e.g. an agent releasing it back to the queues.
+func (s *TicketService) unassign(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	unassignTicketRequest := &data.UnassignTicketRequest{}
+	if e := json.Unmarshal(msg.Data, unassignTicketRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := unassignTicketRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	previous, e := s.ticketRepository.Unassign(ctx, unassignTicketRequest.ID, unassignTicketRequest.Assignee)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	replyConsistencyToken(ctx, s.consistencyRepository, msg)
+	if previous != "" {
+		s.publishAssignmentEvent(ctx, ticketUnassignedSubject, data.EventTypeTicketUnassigned,
+			unassignTicketRequest.ID, previous, unassignTicketRequest.Actor)
+	}
+}
+
+// filterByAssignee replies the tickets assigned to an agent, i.e. the queue of the agent.
+func (s *TicketService) filterByAssignee(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filterByAssigneeRequest := &data.FilterByAssigneeRequest{}
+	if e := json.Unmarshal(msg.Data, filterByAssigneeRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := filterByAssigneeRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	ctx = postgres.WithTenant(ctx, filterByAssigneeRequest.Issuer)
+	repository := s.reader(ctx, filterByAssigneeRequest.ConsistencyToken)
+	tickets, hasNextPage, e := repository.FilterByAssignee(ctx, filterByAssigneeRequest.Issuer,
+		filterByAssigneeRequest.Assignee, filterByAssigneeRequest.Status, filterByAssigneeRequest.PageNumber,
+		filterByAssigneeRequest.PageSize)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	filterTicketsResponse := &data.FilterTicketsResponse{}
+	filterTicketsResponse.LoadFromTickets(tickets, hasNextPage)
+	for _, ticketResponse := range filterTicketsResponse.Tickets {
+		s.identities.EnrichTicket(ctx, ticketResponse)
+	}
+
+	s.reply(msg, filterTicketsResponse)
+}
+
+// onCommentCreated returns snoozed tickets to the active queues, and tickets waiting on their owners back to NEW, once
+// their owners reply.
+func (s *TicketService) onCommentCreated(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	event := &data.Event{}
+	if e := json.Unmarshal(msg.Data, event); e != nil || event.Comment == nil {
+		return
+	}
+
+	unsnoozed, e := s.ticketRepository.Unsnooze(ctx, event.Comment.TicketID, event.Comment.Owner)
+	if e == nil && unsnoozed {
+		s.publishSnoozeEvent(ctx, ticketUnsnoozedSubject, data.EventTypeTicketUnsnoozed, event.Comment.TicketID,
+			event.Comment.Owner)
+	}
+
+	reopened, e := s.ticketRepository.ReopenWaiting(ctx, event.Comment.TicketID, event.Comment.Owner)
+	if e == nil && reopened {
+		s.publishStatusChange(ctx, event.Comment.TicketID, models.TicketStatusWaitingOnCustomer, event.Comment.Owner)
+	}
+}
+
+// onTicketApproved publishes the status change of the tickets moved to another status by approved approvals.
+func (s *TicketService) onTicketApproved(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	event := &data.Event{}
+	if e := json.Unmarshal(msg.Data, event); e != nil || event.Approval == nil || event.PreviousStatus == "" {
+		return
+	}
+
+	if event.PreviousStatus != event.Approval.Status {
+		s.publishStatusChange(ctx, event.Approval.TicketID, event.PreviousStatus, event.Actor)
+	}
+}
+
+// UnsnoozeDueTickets is a scheduler job that runs every minute and returns the tickets whose snooze time has come to
+// the active queues.
+func (s *TicketService) UnsnoozeDueTickets(ctx context.Context, now time.Time) {
+	ids, e := s.ticketRepository.UnsnoozeDue(ctx, now)
+	if e != nil {
+		return
+	}
+
+	for _, id := range ids {
+		s.publishSnoozeEvent(ctx, ticketUnsnoozedSubject, data.EventTypeTicketUnsnoozed, id, systemCommentOwner)
+	}
+}
+
+// HandleWaitingTickets is a scheduler job that closes the tickets whose owners have been silent for too long and nudges
+// the owners of the other waiting tickets, according to the waiting policy.
+func (s *TicketService) HandleWaitingTickets(ctx context.Context, now time.Time) {
+	if s.waitingPolicy.CloseAfter > 0 {
+		ids, e := s.ticketRepository.CloseWaiting(ctx, now.Add(-s.waitingPolicy.CloseAfter))
+		if e != nil {
+			return
+		}
+
+		for _, id := range ids {
+			s.publishStatusChange(ctx, id, models.TicketStatusWaitingOnCustomer, systemCommentOwner)
+		}
+	}
+
+	if s.waitingPolicy.NudgeAfter > 0 {
+		ids, e := s.ticketRepository.ClaimNudges(ctx, now.Add(-s.waitingPolicy.NudgeAfter), now)
+		if e != nil {
+			return
+		}
+
+		for _, id := range ids {
+			s.nudge(ctx, id)
+		}
+	}
+}
+
+// CheckInvariants is a scheduler job that reports the tickets and comments breaking the invariants of the schema, e.g.
+// modified before they got created, as they point to writers bypassing kiosk or to bugs.
+func (s *TicketService) CheckInvariants(ctx context.Context, _ time.Time) {
+	violations, e := s.invariantRepository.Check(ctx, models.Invariants, 10)
+	if e != nil {
+		return
+	}
+
+	for _, violation := range violations {
+		s.logger.Warn("Invariant ", violation.Invariant.Name, " is broken by ", violation.Count, " rows of ",
+			violation.Invariant.Table, ", e.g. ids ", violation.SampleIDs)
+	}
+}
+
+// ArchiveTickets is a scheduler job that moves the closed tickets not modified for the archive duration, along with
+// their comments, to the archive in batches, so the hot tables stay small.
+func (s *TicketService) ArchiveTickets(ctx context.Context, now time.Time) {
+	if s.archiveAfter <= 0 {
+		return
+	}
+
+	_ = s.archive(ctx, now.Add(-s.archiveAfter))
+}
+
+// archiveTicketsJob is the kind of jobs archiving tickets on demand, see archiveJobPayload.
+const archiveTicketsJob = "tickets.archive"
+
+// archiveJobPayload is the payload of archiveTicketsJob jobs, tickets not modified since Before are archived.
+type archiveJobPayload struct {
+	Before time.Time `json:"before"`
+}
+
+func (s *TicketService) archiveJob(ctx context.Context, payload []byte) error {
+	job := &archiveJobPayload{}
+	if e := json.Unmarshal(payload, job); e != nil {
+		return e
+	}
+
+	if e := s.archive(ctx, job.Before); e != nil {
+		return e
+	}
+
+	return nil
+}
+
+// archive archives the closed tickets not modified since before in batches, until none is left.
+func (s *TicketService) archive(ctx context.Context, before time.Time) *errors.Type {
+	for ctx.Err() == nil {
+		tickets, e := s.archiveRepository.Archive(ctx, before, archiveBatchSize)
+		if e != nil {
+			return e
+		}
+
+		for _, ticket := range tickets {
+			s.publishCounterDelta(ticket.Owner, ticket.Status, -1)
+		}
+
+		if len(tickets) < archiveBatchSize {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// nudge adds the reminder comment to a ticket waiting on its owner, whose event notifies the owner.
+func (s *TicketService) nudge(ctx context.Context, ticketID int64) {
+	comment := &models.Comment{TicketID: ticketID, Owner: systemCommentOwner, Content: s.waitingPolicy.NudgeMessage,
+		AuthorType: models.CommentAuthorTypeSystem}
+	id, e := s.commentRepository.Insert(ctx, *comment)
+	if e != nil {
+		s.logger.Warn("Could not nudge the owner of ticket ", ticketID)
+		return
+	}
+
+	comment.ID = id
+	comment.CreatedAt = time.Now()
+	comment.ModifiedAt = comment.CreatedAt
+	publishCommentEvent(s.logger, s.natsClient, commentCreatedSubject, data.EventTypeCommentCreated, comment)
+}
+
+// publishStatusChange publishes the update event and counter deltas of a ticket whose status was changed by kiosk on
+// behalf of the actor.
+func (s *TicketService) publishStatusChange(ctx context.Context, id int64, previousStatus models.TicketStatus,
+	actor string) {
+
+	ticket, e := s.ticketRepository.LoadByID(ctx, id)
+	if e != nil {
+		return
+	}
+
+	s.publishCounterDelta(ticket.Owner, previousStatus, -1)
+	s.publishCounterDelta(ticket.Owner, ticket.Status, 1)
+	publishTicketEvent(s.logger, s.natsClient, ticketUpdatedSubject, data.EventTypeTicketUpdated, ticket,
+		previousStatus, actor)
+}
+
+// publishSnoozeEvent publishes an event about the ticket snoozed or unsnoozed by the actor, with its current state.
+func (s *TicketService) publishSnoozeEvent(ctx context.Context, subject string, eventType data.EventType, id int64,
+	actor string) {
+
+	ticket, e := s.ticketRepository.LoadByID(ctx, id)
+	if e != nil {
+		return
+	}
+
+	publishTicketEvent(s.logger, s.natsClient, subject, eventType, ticket, "", actor)
+}
+
+// publishAssignmentEvent publishes an event about the ticket assigned or unassigned by the actor, with its current
+// state and the agent it was assigned to before, if any.
+func (s *TicketService) publishAssignmentEvent(ctx context.Context, subject string, eventType data.EventType,
+	id int64, previousAssignee, actor string) {
+
+	ticket, e := s.ticketRepository.LoadByID(ctx, id)
+	if e != nil {
+		return
+	}
+
+	ticketResponse := &data.TicketResponse{}
+	ticketResponse.LoadFromTicket(ticket)
+	s.identities.EnrichTicket(ctx, ticketResponse)
+
+	publishEvent(s.logger, s.natsClient, subject, &data.Event{Type: eventType, Ticket: ticketResponse,
+		PreviousAssignee: previousAssignee, Actor: actor})
+}
+
+func (s *TicketService) filter(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filterTicketsRequest := &data.FilterTicketsRequest{}
+	if e := json.Unmarshal(msg.Data, filterTicketsRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := filterTicketsRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	// With row level security enabled, the tickets of other issuers stay out of reach even if a condition is missed.
+	ctx = postgres.WithTenant(ctx, filterTicketsRequest.Issuer)
+
+	repository := s.reader(ctx, filterTicketsRequest.ConsistencyToken)
+	if filterTicketsRequest.CountOnly {
+		count, e := repository.FilterCount(ctx, filterTicketsRequest.Issuer, filterTicketsRequest.Owner,
+			filterTicketsRequest.ImportanceLevel, filterTicketsRequest.Status, filterTicketsRequest.Resolution(),
+			filterTicketsRequest.MetadataMatch(), filterTicketsRequest.Snoozed, filterTicketsRequest.FromDate,
+			filterTicketsRequest.ToDate)
+		if e != nil {
+			s.reply(msg, e)
+			return
+		}
+
+		s.reply(msg, &data.FilterCountResponse{Count: &count})
+		return
+	}
+
+	if filterTicketsRequest.Exists {
+		exists, e := repository.FilterExists(ctx, filterTicketsRequest.Issuer, filterTicketsRequest.Owner,
+			filterTicketsRequest.ImportanceLevel, filterTicketsRequest.Status, filterTicketsRequest.Resolution(),
+			filterTicketsRequest.MetadataMatch(), filterTicketsRequest.Snoozed, filterTicketsRequest.FromDate,
+			filterTicketsRequest.ToDate)
+		if e != nil {
+			s.reply(msg, e)
+			return
+		}
+
+		s.reply(msg, &data.FilterCountResponse{Exists: &exists})
+		return
+	}
+
+	filterTicketsResponse := &data.FilterTicketsResponse{}
+	if filterTicketsRequest.GroupBy != "" {
+		groups, e := repository.FilterGroups(ctx, filterTicketsRequest.GroupBy, filterTicketsRequest.Issuer,
+			filterTicketsRequest.Owner, filterTicketsRequest.ImportanceLevel, filterTicketsRequest.Status,
+			filterTicketsRequest.Resolution(), filterTicketsRequest.MetadataMatch(), filterTicketsRequest.Snoozed,
+			filterTicketsRequest.FromDate, filterTicketsRequest.ToDate, filterTicketsRequest.Orders(),
+			filterTicketsRequest.PageSize)
+		if e != nil {
+			s.reply(msg, e)
+			return
+		}
+
+		filterTicketsResponse.LoadFromTicketGroups(groups)
+	} else {
+		orders := filterTicketsRequest.Orders()
+
+		after, e := pageCursor(s.pageTokens, filterTicketsRequest.PageToken, ticketsPageList, orders)
+		if e != nil {
+			s.reply(msg, e)
+			return
+		}
+
+		ts, hasNextPage, e := repository.Filter(ctx, filterTicketsRequest.Issuer, filterTicketsRequest.Owner,
+			filterTicketsRequest.ImportanceLevel, filterTicketsRequest.Status, filterTicketsRequest.Resolution(),
+			filterTicketsRequest.MetadataMatch(), filterTicketsRequest.Snoozed,
+			filterTicketsRequest.FromDate, filterTicketsRequest.ToDate, orders, after,
+			filterTicketsRequest.PageNumber, filterTicketsRequest.PageSize)
+		if e != nil {
+			s.reply(msg, e)
+			return
+		}
+
+		if len(ts) == 0 && after == nil {
+			archived, hasNextArchivedPage, e := s.filterArchive(ctx, repository, filterTicketsRequest)
+			if e != nil {
+				s.reply(msg, e)
+				return
+			}
+
+			if len(archived) > 0 {
+				ts, hasNextPage = archived, hasNextArchivedPage
+			}
+		}
+
+		// Pages of archived tickets are continued by their page numbers rather than page tokens.
+		filterTicketsResponse.LoadFromTickets(ts, hasNextPage)
+		if hasNextPage && !ts[0].Archived {
+			filterTicketsResponse.NextPageToken = s.pageTokens.Issue(ticketsPageList, orders,
+				ts[len(ts)-1].Cursor(orders))
+		}
+	}
+
+	if filterTicketsRequest.PreviewOnly {
+		filterTicketsResponse.UsePreviews()
+	}
+
+	if filterTicketsRequest.EstimatedTotal {
+		estimate, e := repository.FilterEstimate(ctx, filterTicketsRequest.Issuer, filterTicketsRequest.Owner,
+			filterTicketsRequest.ImportanceLevel, filterTicketsRequest.Status, filterTicketsRequest.Resolution(),
+			filterTicketsRequest.MetadataMatch(), filterTicketsRequest.Snoozed, filterTicketsRequest.FromDate,
+			filterTicketsRequest.ToDate)
+		if e != nil {
+			s.reply(msg, e)
+			return
+		}
+
+		filterTicketsResponse.EstimatedTotal = &estimate
+	}
+
+	if filterTicketsRequest.Facets {
+		facets, e := repository.FilterFacets(ctx, filterTicketsRequest.Issuer, filterTicketsRequest.Owner,
+			filterTicketsRequest.ImportanceLevel, filterTicketsRequest.Status, filterTicketsRequest.Resolution(),
+			filterTicketsRequest.MetadataMatch(), filterTicketsRequest.Snoozed, filterTicketsRequest.FromDate,
+			filterTicketsRequest.ToDate)
+		if e != nil {
+			s.reply(msg, e)
+			return
+		}
+
+		filterTicketsResponse.Facets = &data.TicketFacetsResponse{}
+		filterTicketsResponse.Facets.LoadFromTicketFacets(facets)
+	}
+	s.reply(msg, filterTicketsResponse)
+}
+
+func (s *TicketService) counters(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ticketCountersRequest := &data.TicketCountersRequest{}
+	if e := json.Unmarshal(msg.Data, ticketCountersRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := ticketCountersRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	// Counters are always read from the primary, as invalidations could otherwise outrun the replica and leave stale
+	// counters cached. The cache itself may be invalidated a little after a mutation, so reads that must observe one
+	// skip it.
+	key := countersKey{owner: ticketCountersRequest.Owner, assignee: ticketCountersRequest.Assignee}
+	counters, generation, ok := s.countersCache.get(key)
+	if !ok || ticketCountersRequest.ConsistencyToken != "" {
+		var e *errors.Type
+		counters, e = s.ticketRepository.CountByStatus(ctx, ticketCountersRequest.Owner, ticketCountersRequest.Assignee)
+		if e != nil {
+			s.reply(msg, e)
+			return
+		}
+
+		s.countersCache.put(key, counters, generation)
+	}
+
+	s.reply(msg, &data.TicketCountersResponse{Owner: ticketCountersRequest.Owner,
+		Assignee: ticketCountersRequest.Assignee, Counters: counters})
+}
+
+func (s *TicketService) exportPDF(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	id := &data.ID{}
+	if e := json.Unmarshal(msg.Data, id); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	t, e := s.loadByID(ctx, id.ID)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	attachments, _, e := s.attachmentRepository.LoadByTicketID(ctx, t.ID, nil, 0)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	pdf, err := documents.RenderTicketPDF(t, attachments)
+	if err != nil {
+		et := errors.InternalServerError("unknown", "")
+		s.logger.Error(et.FingerPrint, ": ", err.Error())
+		s.reply(msg, et)
+		return
+	}
+
+	_ = msg.Respond(pdf)
+}
+
+// renderText replies the plaintext transcript of a ticket and its comments.
+func (s *TicketService) renderText(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	id := &data.ID{}
+	if e := json.Unmarshal(msg.Data, id); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	t, e := s.loadByID(ctx, id.ID)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	attachments, _, e := s.attachmentRepository.LoadByTicketID(ctx, t.ID, nil, 0)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	text, err := documents.RenderTicketText(t, attachments)
+	if err != nil {
+		et := errors.InternalServerError("unknown", "")
+		s.logger.Error(et.FingerPrint, ": ", err.Error())
+		s.reply(msg, et)
+		return
+	}
+
+	_ = msg.Respond(text)
+}
+
+// reindexTicketsJob is the kind of jobs rebuilding the search documents of tickets.
+const reindexTicketsJob = "tickets.reindex"
+
+// reindexJobPayload is the payload of reindexTicketsJob jobs.
+type reindexJobPayload struct {
+	Request *data.ReindexTicketsRequest `json:"request"`
+	Total   int64                       `json:"total"`
+}
+
+// reindex rebuilds the search documents of tickets, e.g. to recover from a corrupted index or to apply a change of
+// how documents are built. The reply only carries the number of tickets to reindex, the progress is published on
+// kiosk.tickets.reindex.progress as batches are reindexed.
+func (s *TicketService) reindex(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	reindexTicketsRequest := &data.ReindexTicketsRequest{}
+	if e := json.Unmarshal(msg.Data, reindexTicketsRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := reindexTicketsRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	total, e := s.ticketRepository.CountForReindex(ctx, reindexTicketsRequest.Issuer, reindexTicketsRequest.FromDate,
+		reindexTicketsRequest.ToDate)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	// A single reindex is pending at a time among all instances.
+	payload := &reindexJobPayload{Request: reindexTicketsRequest, Total: total}
+	enqueued, e := s.pool.Enqueue(ctx, reindexTicketsJob, reindexTicketsJob, payload)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if !enqueued {
+		s.reply(msg, errors.PreconditionFailed("reindex.in_progress", ""))
+		return
+	}
+
+	s.reply(msg, &data.ReindexTicketsResponse{Total: total})
+}
+
+func (s *TicketService) reindexJob(ctx context.Context, payload []byte) error {
+	job := &reindexJobPayload{}
+	if e := json.Unmarshal(payload, job); e != nil {
+		return e
+	}
+
+	request := job.Request
+	progress := &data.ReindexProgress{Total: job.Total}
+	for !progress.Done {
+		lastID, count, e := s.ticketRepository.Reindex(ctx, request.Issuer, request.FromDate, request.ToDate,
+			progress.LastID, request.BatchSize)
+		if e != nil {
+			progress.Failure = e.FingerPrint
+			s.publishReindexProgress(progress)
+			return e
+		}
+
+		progress.LastID = lastID
+		progress.Processed += int64(count)
+		progress.Done = count < request.BatchSize
+		s.publishReindexProgress(progress)
+	}
+
+	s.logger.Info("Reindexed ", progress.Processed, " tickets")
+	return nil
+}
+
+func (s *TicketService) publishReindexProgress(progress *data.ReindexProgress) {
+	event, _ := json.Marshal(progress)
+	if e := s.natsClient.Publish("kiosk.tickets.reindex.progress", event); e != nil {
+		s.logger.Warn("Could not publish reindex progress: ", e.Error())
+	}
+}
+
+// loadByID loads a ticket from the hot tables, or from the archive once it got archived, so reads of a single ticket
+// keep finding it.
+func (s *TicketService) loadByID(ctx context.Context, id int64) (*models.Ticket, *errors.Type) {
+	t, e := s.ticketRepository.LoadByID(ctx, id)
+	if e != nil && e.Kind == errors.KindNotFound {
+		return s.archiveRepository.LoadByID(ctx, id)
+	}
+
+	return t, e
+}
+
+// filterArchive filters the archived tickets on behalf of a filter request none of whose tickets are in the hot tables,
+// so tickets looked up after they get archived are still found. Archived tickets can not be matched by metadata and
+// are never snoozed, so such requests are not looked up in the archive.
+func (s *TicketService) filterArchive(ctx context.Context, repository TicketRepository,
+	filterTicketsRequest *data.FilterTicketsRequest) ([]*models.Ticket, bool, *errors.Type) {
+
+	if len(filterTicketsRequest.MetadataMatch()) > 0 || filterTicketsRequest.Snoozed {
+		return nil, false, nil
+	}
+
+	exists, e := repository.FilterExists(ctx, filterTicketsRequest.Issuer, filterTicketsRequest.Owner,
+		filterTicketsRequest.ImportanceLevel, filterTicketsRequest.Status, filterTicketsRequest.Resolution(),
+		filterTicketsRequest.MetadataMatch(), filterTicketsRequest.Snoozed, filterTicketsRequest.FromDate,
+		filterTicketsRequest.ToDate)
+	if e != nil || exists {
+		return nil, false, e
+	}
+
+	return s.archiveRepository.Filter(ctx, filterTicketsRequest.Issuer, filterTicketsRequest.Owner,
+		filterTicketsRequest.ImportanceLevel, filterTicketsRequest.Status, filterTicketsRequest.Resolution(),
+		filterTicketsRequest.FromDate, filterTicketsRequest.ToDate, filterTicketsRequest.PageNumber,
+		filterTicketsRequest.PageSize)
+}
+
+// reader returns back the repository to serve a read with. Reads carrying a consistency token are only served by the
+// replica once it has caught up with the token. Reads on the primary go through the read only role when there is one.
+// Either way, the filters of the repository are shadowed when shadow reads are enabled.
+func (s *TicketService) reader(ctx context.Context, consistencyToken string) TicketRepository {
+	primary := s.ticketRepository
+	if s.readOnlyTicketRepository != nil {
+		primary = s.readOnlyTicketRepository
+	}
+
+	if s.replicaTicketRepository == nil {
+		return s.shadowReader.wrap(primary)
+	}
+
+	if consistencyToken == "" || s.consistencyRepository.ReplicaCaughtUp(ctx, consistencyToken) {
+		return s.shadowReader.wrap(s.replicaTicketRepository)
+	}
+
+	return s.shadowReader.wrap(primary)
+}
+
+// onTicketChange handles the ticket changes broadcast by the database triggers, including those made through other
+// kiosk instances.
+func (s *TicketService) onTicketChange(payload string) {
+	change := &struct {
+		Owner            string `json:"owner"`
+		Assignee         string `json:"assignee"`
+		PreviousAssignee string `json:"previous_assignee"`
+	}{}
+
+	if e := json.Unmarshal([]byte(payload), change); e != nil {
+		s.countersCache.invalidateAll()
+		return
+	}
+
+	s.countersCache.invalidate(change.Owner, change.Assignee, change.PreviousAssignee)
+}
+
+// publishCounterDelta notifies counter listeners (e.g. UI badges) that the number of tickets of an owner in a status
+// has changed, so they can keep their counters up to date without reloading them.
+func (s *TicketService) publishCounterDelta(owner string, status models.TicketStatus, delta int64) {
+	event, _ := json.Marshal(&data.TicketCounterDelta{Owner: owner, Status: status, Delta: delta})
+	if e := s.natsClient.Publish("kiosk.tickets.counters.delta", event); e != nil {
+		s.logger.Warn("Could not publish ticket counter delta: ", e.Error())
+	}
+}
+
+func (s *TicketService) reply(msg *nc.Msg, t interface{}) {
+	reply, _ := json.Marshal(t)
+	_ = msg.Respond(reply)
+}
+
+func (s *TicketService) replyNoContent(msg *nc.Msg) {
+	_ = msg.Respond([]byte(""))
+}
+
+// Stop stops the component and drains its subscriptions, blocking until their in-flight messages are handled.
+func (s *TicketService) Stop() {
+	s.stop <- struct{}{}
+	<-s.stop
+	s.changesListener.Stop()
 }
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/anonymization"
+	"github.com/jibitters/kiosk/db/postgres"
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// TransferService is a service implementation of transfer functionalities. The tickets of an issuer are exported from
+// one kiosk deployment and imported into another one, e.g. by the transfer sub command, when products move between
+// organizations. Exports can also be anonymized, producing datasets for analytics.
+type TransferService struct {
+	logger             *zap.SugaredLogger
+	transferRepository *models.TransferRepository
+	natsClient         *nc.Conn
+	anonymizer         *anonymization.Anonymizer
+	stop               chan struct{}
+}
+
+// NewTransferService returns a newly created and ready to use TransferService.
+func NewTransferService(logger *zap.SugaredLogger, db *pgxpool.Pool, natsClient *nc.Conn,
+	anonymizer *anonymization.Anonymizer) *TransferService {
+
+	return &TransferService{
+		logger:             logger,
+		transferRepository: models.NewTransferRepository(logger, db),
+		natsClient:         natsClient,
+		anonymizer:         anonymizer,
+		stop:               make(chan struct{}),
+	}
+}
+
+// Start starts the subscriptions so ready to be notified.
+func (s *TransferService) Start() error {
+	exportSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.export", "kiosk.tickets.export_group",
+		s.export)
+	if e != nil {
+		return e
+	}
+
+	importSubscription, e := s.natsClient.QueueSubscribe("kiosk.tickets.import", "kiosk.tickets.import_group",
+		s.importTicket)
+	if e != nil {
+		return e
+	}
+
+	go s.await(exportSubscription, importSubscription)
+
+	return nil
+}
+
+func (s *TransferService) await(ss ...*nc.Subscription) {
+	<-s.stop
+	s.logger.Debug("TransferService: received stop signal!")
+
+	drain(s.logger, ss)
+	s.stop <- struct{}{}
+}
+
+func (s *TransferService) export(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	exportTicketsRequest := &data.ExportTicketsRequest{}
+	if e := json.Unmarshal(msg.Data, exportTicketsRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := exportTicketsRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	ctx = postgres.WithTenant(ctx, exportTicketsRequest.Issuer)
+
+	tickets, e := s.transferRepository.Export(ctx, exportTicketsRequest.Issuer, exportTicketsRequest.AfterID,
+		exportTicketsRequest.PageSize)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	exportTicketsResponse := &data.ExportTicketsResponse{}
+	exportTicketsResponse.LoadFromTickets(tickets, exportTicketsRequest.AfterID, exportTicketsRequest.PageSize)
+	if exportTicketsRequest.Anonymized {
+		exportTicketsResponse.Anonymize(s.anonymizer)
+	}
+
+	s.reply(msg, exportTicketsResponse)
+}
+
+func (s *TransferService) importTicket(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	importTicketRequest := &data.ImportTicketRequest{}
+	if e := json.Unmarshal(msg.Data, importTicketRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := importTicketRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	ctx = postgres.WithTenant(ctx, importTicketRequest.Ticket.Issuer)
+
+	id, imported, e := s.transferRepository.Import(ctx, *importTicketRequest.AsTicket())
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.reply(msg, &data.ImportTicketResponse{ID: id, Imported: imported})
+}
+
+func (s *TransferService) reply(msg *nc.Msg, t interface{}) {
+	reply, _ := json.Marshal(t)
+	_ = msg.Respond(reply)
+}
+
+// Stop stops the component and drains its subscriptions, blocking until their in-flight messages are handled.
+func (s *TransferService) Stop() {
+	s.stop <- struct{}{}
+	<-s.stop
+}
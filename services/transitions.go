@@ -0,0 +1,21 @@
+package services
+
+import (
+	"context"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// checkTransitionRequirements returns back an error describing every field the ticket is missing to be moved to the
+// status along with the resolution, one error per field, or nil when it has all of them.
+func checkTransitionRequirements(ctx context.Context, repository *models.TransitionRequirementRepository,
+	ticketID int64, status models.TicketStatus, resolution models.Resolution) *errors.Type {
+
+	fields, e := repository.LoadMissing(ctx, ticketID, status, resolution)
+	if e != nil {
+		return e
+	}
+
+	return models.MissingFieldsError(status, fields)
+}
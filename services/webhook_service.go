@@ -0,0 +1,535 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/web/data"
+	"github.com/jibitters/kiosk/webhooks"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// WebhookService is a service implementation of inbound webhook functionalities. Configured sources, e.g. monitoring
+// systems, open tickets, comment on them and close them through signed webhook deliveries. Prometheus Alertmanager is
+// supported natively, its alert groups open and resolve tickets.
+type WebhookService struct {
+	logger                   *zap.SugaredLogger
+	webhookSourceRepository  *models.WebhookSourceRepository
+	webhookTicketRepository  *models.WebhookTicketRepository
+	ticketRepository         TicketRepository
+	commentRepository        CommentRepository
+	issuerSettingsRepository *models.IssuerSettingsRepository
+	slaTargetRepository      *models.SLATargetRepository
+	natsClient               *nc.Conn
+	maxSkew                  time.Duration
+	stop                     chan struct{}
+}
+
+// NewWebhookService returns a newly created and ready to use WebhookService. Signed deliveries sent further than
+// maxSkew away from now are rejected, so they can not be replayed.
+func NewWebhookService(logger *zap.SugaredLogger, db *pgxpool.Pool, natsClient *nc.Conn,
+	maxSkew time.Duration) *WebhookService {
+
+	return &WebhookService{
+		logger:                   logger,
+		webhookSourceRepository:  models.NewWebhookSourceRepository(logger, db),
+		webhookTicketRepository:  models.NewWebhookTicketRepository(logger, db),
+		ticketRepository:         models.NewTicketRepository(logger, db),
+		commentRepository:        models.NewCommentRepository(logger, db),
+		issuerSettingsRepository: models.NewIssuerSettingsRepository(logger, db),
+		slaTargetRepository:      models.NewSLATargetRepository(logger, db),
+		natsClient:               natsClient,
+		maxSkew:                  maxSkew,
+		stop:                     make(chan struct{}),
+	}
+}
+
+// Start starts the subscriptions so ready to be notified.
+func (s *WebhookService) Start() error {
+	saveSourceSubscription, e := s.natsClient.QueueSubscribe("kiosk.webhooks.sources.save",
+		"kiosk.webhooks.sources.save_group", s.saveSource)
+	if e != nil {
+		return e
+	}
+
+	loadSourceSubscription, e := s.natsClient.QueueSubscribe("kiosk.webhooks.sources.load",
+		"kiosk.webhooks.sources.load_group", s.loadSource)
+	if e != nil {
+		return e
+	}
+
+	deleteSourceSubscription, e := s.natsClient.QueueSubscribe("kiosk.webhooks.sources.delete",
+		"kiosk.webhooks.sources.delete_group", s.deleteSource)
+	if e != nil {
+		return e
+	}
+
+	inboundWebhookSubscription, e := s.natsClient.QueueSubscribe("kiosk.webhooks.inbound",
+		"kiosk.webhooks.inbound_group", s.receive)
+	if e != nil {
+		return e
+	}
+
+	alertmanagerWebhookSubscription, e := s.natsClient.QueueSubscribe("kiosk.webhooks.alertmanager",
+		"kiosk.webhooks.alertmanager_group", s.receiveAlertmanager)
+	if e != nil {
+		return e
+	}
+
+	go s.await(saveSourceSubscription, loadSourceSubscription, deleteSourceSubscription, inboundWebhookSubscription,
+		alertmanagerWebhookSubscription)
+
+	return nil
+}
+
+func (s *WebhookService) await(ss ...*nc.Subscription) {
+	<-s.stop
+	s.logger.Debug("WebhookService: received stop signal!")
+
+	drain(s.logger, ss)
+	s.stop <- struct{}{}
+}
+
+func (s *WebhookService) saveSource(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	saveWebhookSourceRequest := &data.SaveWebhookSourceRequest{}
+	if e := json.Unmarshal(msg.Data, saveWebhookSourceRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := saveWebhookSourceRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if e := s.webhookSourceRepository.Save(ctx, *saveWebhookSourceRequest.AsWebhookSource()); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+func (s *WebhookService) loadSource(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	webhookSourceRequest := &data.WebhookSourceRequest{}
+	if e := json.Unmarshal(msg.Data, webhookSourceRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := webhookSourceRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	source, e := s.webhookSourceRepository.LoadByName(ctx, webhookSourceRequest.Name)
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	webhookSourceResponse := &data.WebhookSourceResponse{}
+	webhookSourceResponse.LoadFromWebhookSource(source)
+	s.reply(msg, webhookSourceResponse)
+}
+
+func (s *WebhookService) deleteSource(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	webhookSourceRequest := &data.WebhookSourceRequest{}
+	if e := json.Unmarshal(msg.Data, webhookSourceRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := webhookSourceRequest.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	if e := s.webhookSourceRepository.DeleteByName(ctx, webhookSourceRequest.Name); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.replyNoContent(msg)
+}
+
+// receive authenticates an inbound webhook delivery by the secret of its source and applies its action to the ticket
+// linked to its external key. The id of the ticket is replied back.
+func (s *WebhookService) receive(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	webhookRequest := &data.WebhookRequest{}
+	if e := json.Unmarshal(msg.Data, webhookRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	source, e := s.authenticate(ctx, webhookRequest.Source, func(secret string) error {
+		return webhooks.VerifyTimestampedSignature(secret, webhookRequest.Signature, webhookRequest.Timestamp,
+			webhookRequest.Payload, time.Now(), s.maxSkew)
+	})
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	webhook := &data.InboundWebhook{}
+	if e := json.Unmarshal(webhookRequest.Payload, webhook); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := webhook.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	var id int64
+	switch webhook.Action {
+	case data.InboundWebhookActionCreate:
+		id, e = s.create(ctx, source, webhook)
+	case data.InboundWebhookActionComment:
+		id, e = s.comment(ctx, source, webhook)
+	case data.InboundWebhookActionClose:
+		id, e = s.transition(ctx, source, webhook, models.TicketStatusClosed)
+	}
+
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.reply(msg, &data.ID{ID: id})
+}
+
+// receiveAlertmanager authenticates a delivery of Alertmanager by the secret of its source, sent as the bearer token,
+// and opens a ticket for the alert group when it starts firing. Alerts joining the group later are added as comments
+// and the ticket gets resolved once the group resolves, so the next time the group fires opens another ticket. Alerts
+// are deduplicated on their fingerprints, so repeated notifications of the same alerts are ignored. The id of the
+// ticket is replied back, if any.
+func (s *WebhookService) receiveAlertmanager(msg *nc.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	webhookRequest := &data.WebhookRequest{}
+	if e := json.Unmarshal(msg.Data, webhookRequest); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	source, e := s.authenticate(ctx, webhookRequest.Source, func(secret string) error {
+		return webhooks.VerifyToken(secret, webhookRequest.Token)
+	})
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	alertmanagerWebhook := &data.AlertmanagerWebhook{}
+	if e := json.Unmarshal(webhookRequest.Payload, alertmanagerWebhook); e != nil {
+		s.reply(msg, errors.InvalidRequestBody())
+		return
+	}
+
+	if e := alertmanagerWebhook.Validate(); e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	externalKey := alertmanagerWebhook.ExternalKey()
+	ticketID, e := s.webhookTicketRepository.LoadTicketID(ctx, source.Name, externalKey)
+	switch {
+	case e != nil && e.Kind != errors.KindNotFound:
+		s.reply(msg, e)
+		return
+	case e != nil && alertmanagerWebhook.Status == data.AlertStatusResolved:
+		s.replyNoContent(msg)
+		return
+	case e != nil:
+		ticketID, e = s.openAlertGroup(ctx, source, alertmanagerWebhook)
+	default:
+		e = s.updateAlertGroup(ctx, source, alertmanagerWebhook, ticketID)
+	}
+
+	if e != nil {
+		s.reply(msg, e)
+		return
+	}
+
+	s.reply(msg, &data.ID{ID: ticketID})
+}
+
+// openAlertGroup opens the ticket of a firing alert group.
+func (s *WebhookService) openAlertGroup(ctx context.Context, source *models.WebhookSource,
+	alertmanagerWebhook *data.AlertmanagerWebhook) (int64, *errors.Type) {
+
+	firing := s.alerts(alertmanagerWebhook.Alerts, data.AlertStatusFiring)
+	webhook := &data.InboundWebhook{
+		Action:          data.InboundWebhookActionCreate,
+		ExternalKey:     alertmanagerWebhook.ExternalKey(),
+		Subject:         alertmanagerWebhook.Subject(),
+		ImportanceLevel: alertmanagerWebhook.ImportanceLevel(),
+		Content:         alertmanagerWebhook.Describe("Alertmanager reports the alert group firing.", firing),
+	}
+
+	ticketID, e := s.create(ctx, source, webhook)
+	if e != nil {
+		return 0, e
+	}
+
+	s.unseen(ctx, source, ticketID, firing)
+	return ticketID, nil
+}
+
+// updateAlertGroup comments the alerts of the group not seen before on its ticket, and resolves the ticket when the
+// group is resolved.
+func (s *WebhookService) updateAlertGroup(ctx context.Context, source *models.WebhookSource,
+	alertmanagerWebhook *data.AlertmanagerWebhook, ticketID int64) *errors.Type {
+
+	webhook := &data.InboundWebhook{ExternalKey: alertmanagerWebhook.ExternalKey()}
+	unseen := s.unseen(ctx, source, ticketID, alertmanagerWebhook.Alerts)
+
+	if alertmanagerWebhook.Status == data.AlertStatusResolved {
+		webhook.Content = alertmanagerWebhook.Describe("Alertmanager reports the alert group resolved.", unseen)
+		if _, e := s.comment(ctx, source, webhook); e != nil {
+			return e
+		}
+
+		if _, e := s.transition(ctx, source, webhook, models.TicketStatusResolved); e != nil {
+			return e
+		}
+
+		return s.webhookTicketRepository.Unlink(ctx, source.Name, webhook.ExternalKey)
+	}
+
+	if len(unseen) == 0 {
+		return nil
+	}
+
+	webhook.Content = alertmanagerWebhook.Describe("Alertmanager reports changes of the alert group.", unseen)
+	_, e := s.comment(ctx, source, webhook)
+	return e
+}
+
+// unseen returns back the alerts not seen on the ticket before and records them as seen. Alerts are told apart by
+// their fingerprints, start times and statuses, so alerts firing again or getting resolved are seen anew.
+func (s *WebhookService) unseen(ctx context.Context, source *models.WebhookSource, ticketID int64,
+	alerts []*data.Alert) []*data.Alert {
+
+	unseen := make([]*data.Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		key := fmt.Sprintf("alert:%d:%s:%d:%s", ticketID, alert.Fingerprint, alert.StartsAt.Unix(), alert.Status)
+		if linked, e := s.webhookTicketRepository.Link(ctx, source.Name, key, ticketID); e != nil || linked {
+			unseen = append(unseen, alert)
+		}
+	}
+
+	return unseen
+}
+
+// alerts returns back the alerts with the status.
+func (s *WebhookService) alerts(alerts []*data.Alert, status string) []*data.Alert {
+	filtered := make([]*data.Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		if alert.Status == status {
+			filtered = append(filtered, alert)
+		}
+	}
+
+	return filtered
+}
+
+// authenticate loads the webhook source with the name and verifies the delivery by its secret. Unknown sources are
+// rejected just like unauthenticated deliveries, so names of sources can not be probed.
+func (s *WebhookService) authenticate(ctx context.Context, name string,
+	verify func(secret string) error) (*models.WebhookSource, *errors.Type) {
+
+	source, e := s.webhookSourceRepository.LoadByName(ctx, name)
+	if e != nil {
+		if e.Kind == errors.KindNotFound {
+			e = errors.Unauthorized("")
+		}
+
+		return nil, e
+	}
+
+	if err := verify(source.Secret); err != nil {
+		s.logger.Warn("Rejected ", source.Name, " inbound webhook: ", err.Error())
+		return nil, errors.Unauthorized("")
+	}
+
+	return source, nil
+}
+
+// create opens a ticket on behalf of the source and links it to the external key of the webhook. Tickets get the
+// defaults and SLA deadlines of their issuer, just like the ones created through the API.
+func (s *WebhookService) create(ctx context.Context, source *models.WebhookSource,
+	webhook *data.InboundWebhook) (int64, *errors.Type) {
+
+	if _, e := s.webhookTicketRepository.LoadTicketID(ctx, source.Name, webhook.ExternalKey); e == nil {
+		return 0, errors.Conflict("externalKey.already_exists", "")
+	} else if e.Kind != errors.KindNotFound {
+		return 0, e
+	}
+
+	importanceLevel := webhook.ImportanceLevel
+	if importanceLevel == "" {
+		importanceLevel = source.ImportanceLevel
+	}
+
+	createTicketRequest := &data.CreateTicketRequest{Issuer: source.Issuer, Owner: source.Owner,
+		Subject: webhook.Subject, Content: webhook.Content, ImportanceLevel: importanceLevel}
+	if e := createTicketRequest.Validate(); e != nil {
+		return 0, e
+	}
+
+	settings, e := s.issuerSettingsRepository.LoadByIssuer(ctx, createTicketRequest.Issuer)
+	if e != nil {
+		return 0, e
+	}
+
+	createTicketRequest.ApplyDefaults(settings)
+
+	ticket := createTicketRequest.AsTicket()
+	target, e := s.slaTargetRepository.Load(ctx, settings.Tier, ticket.ImportanceLevel)
+	if e != nil {
+		return 0, e
+	}
+
+	ticket.Tier = settings.Tier
+	target.Apply(ticket, time.Now())
+
+	id, e := s.ticketRepository.Insert(ctx, *ticket)
+	if e != nil {
+		return 0, e
+	}
+
+	// Concurrent deliveries of the same key may both get here, the later ticket is kept but left unlinked then.
+	if linked, e := s.webhookTicketRepository.Link(ctx, source.Name, webhook.ExternalKey, id); e == nil && !linked {
+		s.logger.Warn("Ticket ", id, " of ", source.Name, " could not be linked to ", webhook.ExternalKey,
+			" as it is already linked to another ticket.")
+	}
+
+	ticket.ID = id
+	ticket.CreatedAt = time.Now()
+	ticket.ModifiedAt = ticket.CreatedAt
+	publishTicketEvent(s.logger, s.natsClient, ticketCreatedSubject, data.EventTypeTicketCreated, ticket, "",
+		source.Name)
+
+	return id, nil
+}
+
+// comment adds the content of the webhook as a comment of the ticket linked to its external key.
+func (s *WebhookService) comment(ctx context.Context, source *models.WebhookSource,
+	webhook *data.InboundWebhook) (int64, *errors.Type) {
+
+	ticketID, e := s.webhookTicketRepository.LoadTicketID(ctx, source.Name, webhook.ExternalKey)
+	if e != nil {
+		return 0, e
+	}
+
+	createCommentRequest := &data.CreateCommentRequest{
+		TicketID:   ticketID,
+		Owner:      s.actor(source, webhook),
+		Content:    webhook.Content,
+		AuthorType: models.CommentAuthorTypeBot,
+		Source:     models.CommentSourceAPI,
+	}
+
+	if e := createCommentRequest.Validate(); e != nil {
+		return 0, e
+	}
+
+	comment := createCommentRequest.AsComment()
+	id, e := s.commentRepository.Insert(ctx, *comment)
+	if e != nil {
+		return 0, e
+	}
+
+	comment.ID = id
+	comment.CreatedAt = time.Now()
+	comment.ModifiedAt = comment.CreatedAt
+	publishCommentEvent(s.logger, s.natsClient, commentCreatedSubject, data.EventTypeCommentCreated, comment)
+
+	return ticketID, nil
+}
+
+// transition moves the ticket linked to the external key of the webhook into the status. Tickets already in the
+// status, and closed tickets, are left as they are.
+func (s *WebhookService) transition(ctx context.Context, source *models.WebhookSource, webhook *data.InboundWebhook,
+	status models.TicketStatus) (int64, *errors.Type) {
+
+	ticketID, e := s.webhookTicketRepository.LoadTicketID(ctx, source.Name, webhook.ExternalKey)
+	if e != nil {
+		return 0, e
+	}
+
+	ticket, e := s.ticketRepository.LoadByID(ctx, ticketID)
+	if e != nil {
+		return 0, e
+	}
+
+	if ticket.Status == status || ticket.Status == models.TicketStatusClosed {
+		return ticketID, nil
+	}
+
+	actor := s.actor(source, webhook)
+	ticket.Status = status
+	previous, e := s.ticketRepository.Update(ctx, ticket, actor)
+	if e != nil {
+		return 0, e
+	}
+
+	ticket.ModifiedAt = time.Now()
+	publishTicketEvent(s.logger, s.natsClient, ticketUpdatedSubject, data.EventTypeTicketUpdated, ticket,
+		previous.Status, actor)
+
+	return ticketID, nil
+}
+
+// actor returns back who made the change, the author of the webhook prefixed with the name of its source if known.
+// It is cut to the 50 bytes owners and actors are limited to.
+func (s *WebhookService) actor(source *models.WebhookSource, webhook *data.InboundWebhook) string {
+	if webhook.Author == "" {
+		return source.Name
+	}
+
+	actor := source.Name + ":" + webhook.Author
+	if len(actor) > 50 {
+		return strings.ToValidUTF8(actor[:50], "")
+	}
+
+	return actor
+}
+
+func (s *WebhookService) reply(msg *nc.Msg, t interface{}) {
+	reply, _ := json.Marshal(t)
+	_ = msg.Respond(reply)
+}
+
+func (s *WebhookService) replyNoContent(msg *nc.Msg) {
+	_ = msg.Respond([]byte(""))
+}
+
+// Stop stops the component and drains its subscriptions, blocking until their in-flight messages are handled.
+func (s *WebhookService) Stop() {
+	s.stop <- struct{}{}
+	<-s.stop
+}
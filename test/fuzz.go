@@ -0,0 +1,66 @@
+package test
+
+import (
+	"math/rand"
+)
+
+// tokens are fragments mutations insert, the ones parsers and validators tend to trip on.
+var tokens = [][]byte{[]byte(`{`), []byte(`}`), []byte(`[`), []byte(`]`), []byte(`"`), []byte(`:`), []byte(`,`),
+	[]byte(`.`), []byte(`\`), []byte(`\u0000`), []byte(`\ud800`), []byte(`null`), []byte(`true`), []byte(`-0`),
+	[]byte(`1e309`), []byte(`9223372036854775808`), []byte(`-9223372036854775809`), []byte(`""`), []byte(`{}`),
+	[]byte(`[]`), []byte("\xff\xfe"), []byte("\u202e"), []byte("0001-01-01T00:00:00Z"), []byte("9999-12-31")}
+
+// Fuzz runs target on inputs mutated from the seeds, iterations of them in total, to catch the panics of parsers and
+// validators on malformed input. The mutations are drawn from seed, e.g. the random seed of the suite, so a failing
+// input can be reproduced by running again with the same seed.
+func Fuzz(seed int64, seeds [][]byte, iterations int, target func(input []byte)) {
+	r := rand.New(rand.NewSource(seed))
+
+	for _, s := range seeds {
+		target(s)
+	}
+
+	for i := 0; i < iterations; i++ {
+		input := append([]byte{}, seeds[r.Intn(len(seeds))]...)
+		for j := r.Intn(4); j >= 0; j-- {
+			input = mutate(r, input, seeds)
+		}
+
+		target(input)
+	}
+}
+
+func mutate(r *rand.Rand, input []byte, seeds [][]byte) []byte {
+	position := 0
+	if len(input) > 0 {
+		position = r.Intn(len(input) + 1)
+	}
+
+	switch r.Intn(5) {
+	case 0:
+		if position < len(input) {
+			input[position] = byte(r.Intn(256))
+		}
+
+		return input
+	case 1:
+		end := position + r.Intn(len(input)-position+1)
+		return append(input[:position], input[end:]...)
+	case 2:
+		other := seeds[r.Intn(len(seeds))]
+		start := r.Intn(len(other) + 1)
+		return insert(input, position, other[start:start+r.Intn(len(other)-start+1)])
+	case 3:
+		end := position + r.Intn(len(input)-position+1)
+		return insert(input, position, append([]byte{}, input[position:end]...))
+	default:
+		return insert(input, position, tokens[r.Intn(len(tokens))])
+	}
+}
+
+func insert(input []byte, position int, fragment []byte) []byte {
+	out := make([]byte, 0, len(input)+len(fragment))
+	out = append(out, input[:position]...)
+	out = append(out, fragment...)
+	return append(out, input[position:]...)
+}
@@ -0,0 +1,144 @@
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jibitters/kiosk/web/data"
+	"go.uber.org/zap"
+)
+
+// Options holds the knobs of a transfer between two kiosk deployments.
+type Options struct {
+	// Source and Destination are the base URLs of the web servers of the deployments, e.g. http://kiosk-a:8080.
+	Source      string
+	Destination string
+	Issuer      string
+	// SourceName prefixes the ids of source tickets to form their external ids within the destination. Tickets
+	// transferred before keep the external id they got on their first transfer.
+	SourceName string
+	// AfterID resumes an interrupted transfer after the last transferred ticket id.
+	AfterID  int64
+	PageSize int
+	Timeout  time.Duration
+}
+
+// Summary holds the outcome of a transfer.
+type Summary struct {
+	Imported int
+	Skipped  int
+	Comments int
+	LastID   int64
+}
+
+// Transferrer copies the tickets of an issuer, along with their comments, from one kiosk deployment to another over
+// their APIs. Transfers can be repeated or resumed safely, tickets already imported are skipped by the destination.
+type Transferrer struct {
+	logger  *zap.SugaredLogger
+	options Options
+	client  *http.Client
+}
+
+// NewTransferrer returns back a newly created and ready to use Transferrer.
+func NewTransferrer(logger *zap.SugaredLogger, options Options) *Transferrer {
+	options.Source = strings.TrimSuffix(options.Source, "/")
+	options.Destination = strings.TrimSuffix(options.Destination, "/")
+
+	return &Transferrer{logger: logger, options: options, client: &http.Client{Timeout: options.Timeout}}
+}
+
+// Run transfers the tickets page by page until there is nothing left or an error occurs. The returned summary holds
+// the id of the last transferred ticket, to resume from in case of errors.
+func (t *Transferrer) Run(ctx context.Context) (Summary, error) {
+	summary := Summary{LastID: t.options.AfterID}
+
+	for {
+		page := &data.ExportTicketsResponse{}
+		if e := t.call(ctx, http.MethodGet, t.exportURL(summary.LastID), nil, page); e != nil {
+			return summary, e
+		}
+
+		for _, ticket := range page.Tickets {
+			externalID := ticket.ExternalID
+			if externalID == "" {
+				externalID = t.options.SourceName + ":" + strconv.FormatInt(ticket.ID, 10)
+			}
+
+			imported := &data.ImportTicketResponse{}
+			in := &data.ImportTicketRequest{ExternalID: externalID, Ticket: ticket}
+			e := t.call(ctx, http.MethodPost, t.options.Destination+"/v1/tickets/import", in, imported)
+			if e != nil {
+				return summary, fmt.Errorf("ticket %v: %w", ticket.ID, e)
+			}
+
+			if imported.Imported {
+				summary.Imported++
+				summary.Comments += len(ticket.Comments)
+			} else {
+				summary.Skipped++
+			}
+
+			summary.LastID = ticket.ID
+		}
+
+		t.logger.Info("Transferred tickets up to ", summary.LastID, ", ", summary.Imported, " imported and ",
+			summary.Skipped, " skipped so far.")
+
+		if !page.HasMore {
+			return summary, nil
+		}
+	}
+}
+
+func (t *Transferrer) exportURL(afterID int64) string {
+	query := url.Values{}
+	query.Set("issuer", t.options.Issuer)
+	query.Set("afterId", strconv.FormatInt(afterID, 10))
+	query.Set("pageSize", strconv.Itoa(t.options.PageSize))
+
+	return t.options.Source + "/v1/tickets/export?" + query.Encode()
+}
+
+func (t *Transferrer) call(ctx context.Context, method, url string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		encoded, e := json.Marshal(in)
+		if e != nil {
+			return e
+		}
+
+		body = bytes.NewReader(encoded)
+	}
+
+	request, e := http.NewRequestWithContext(ctx, method, url, body)
+	if e != nil {
+		return e
+	}
+
+	request.Header.Set("Accept", "application/json")
+	if in != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, e := t.client.Do(request)
+	if e != nil {
+		return e
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		reason, _ := ioutil.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("%v %v: %v %s", method, url, response.Status, reason)
+	}
+
+	return json.NewDecoder(response.Body).Decode(out)
+}
@@ -0,0 +1,178 @@
+package web_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jibitters/kiosk/jobs"
+	"github.com/jibitters/kiosk/pagination"
+	"github.com/jibitters/kiosk/services"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/test/containers"
+	"github.com/jibitters/kiosk/web"
+	"github.com/lireza/lib/configuring"
+	"github.com/nats-io/nats-server/v2/server"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	nc "github.com/nats-io/nats.go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"go.uber.org/zap"
+)
+
+// contract is a conversation recorded from clients of the API, its exchanges are replayed in order against a server
+// backed by a fresh database. Responses must stay the same byte for byte, apart from the volatile values.
+type contract struct {
+	Description string     `json:"description"`
+	Exchanges   []exchange `json:"exchanges"`
+}
+
+type exchange struct {
+	Request struct {
+		Method  string            `json:"method"`
+		Path    string            `json:"path"`
+		Headers map[string]string `json:"headers,omitempty"`
+		Body    string            `json:"body,omitempty"`
+	} `json:"request"`
+	Response struct {
+		Status int `json:"status"`
+		// Headers are the headers verified, others are left out.
+		Headers map[string]string `json:"headers,omitempty"`
+		Body    string            `json:"body"`
+	} `json:"response"`
+}
+
+// volatile masks the values differing between runs, e.g. ids of requests, fingerprints of errors and timestamps.
+var volatile = []struct {
+	pattern *regexp.Regexp
+	mask    string
+}{
+	{regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`), "<uuid>"},
+	{regexp.MustCompile(`[0-9]{4}-[0-9]{2}-[0-9]{2}T[0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?(Z|[+-][0-9]{2}:[0-9]{2})`),
+		"<time>"},
+}
+
+func mask(s string) string {
+	for _, v := range volatile {
+		s = v.pattern.ReplaceAllString(s, v.mask)
+	}
+
+	return s
+}
+
+var _ = Describe("Contract", func() {
+	var pg testcontainers.Container
+	var db *pgxpool.Pool
+	var natsServer *server.Server
+	var natsClient *nc.Conn
+	var ticketService *services.TicketService
+	var commentService *services.CommentService
+	var httpServer *http.Server
+
+	BeforeEach(func() {
+		container, port, e := containers.RunPostgres()
+		if e != nil {
+			Fail(e.Error())
+		} else {
+			pg = container
+		}
+
+		if pool, e := test.ConnectToDatabase(pgHost, port); e != nil {
+			Fail(e.Error())
+		} else {
+			db = pool
+		}
+
+		opts := natsserver.DefaultTestOptions
+		opts.Port = server.RANDOM_PORT
+		natsServer = natsserver.RunServer(&opts)
+
+		client, e := nc.Connect(natsServer.ClientURL())
+		Ω(e).Should(BeNil())
+		natsClient = client
+
+		pageTokens := pagination.NewTokens("contracts")
+		ticketService = services.NewTicketService(zap.S(), db, nil, nil, natsClient,
+			jobs.NewPool(zap.S(), db, "contracts", 1), services.WaitingPolicy{}, 0, 0, pageTokens,
+			services.ShadowReads{}, nil)
+		Ω(ticketService.Start()).Should(BeNil())
+
+		commentService = services.NewCommentService(zap.S(), db, natsClient, pageTokens, nil)
+		Ω(commentService.Start()).Should(BeNil())
+
+		// Requests are served by the handler of the server directly, it is left listening on a random port.
+		_ = os.Setenv("WEB_SERVER_PORT", "0")
+		httpServer = web.StartServer(zap.S(), configuring.New(), natsClient)
+	})
+
+	AfterEach(func() {
+		_ = httpServer.Close()
+		commentService.Stop()
+		ticketService.Stop()
+		natsClient.Close()
+		natsServer.Shutdown()
+		db.Close()
+		_ = containers.Stop(pg)
+	})
+
+	files, _ := filepath.Glob(filepath.Join("testdata", "contracts", "*.json"))
+	for _, file := range files {
+		file := file
+
+		It("Should keep the responses of "+filepath.Base(file)+" compatible", func() {
+			golden, e := ioutil.ReadFile(file)
+			Ω(e).Should(BeNil())
+
+			c := &contract{}
+			Ω(json.Unmarshal(golden, c)).Should(Succeed())
+
+			for i := range c.Exchanges {
+				x := &c.Exchanges[i]
+				step := fmt.Sprintf("exchange %d: %v %v", i+1, x.Request.Method, x.Request.Path)
+
+				request := httptest.NewRequest(x.Request.Method, x.Request.Path, strings.NewReader(x.Request.Body))
+				for name, value := range x.Request.Headers {
+					request.Header.Set(name, value)
+				}
+
+				recorder := httptest.NewRecorder()
+				httpServer.Handler.ServeHTTP(recorder, request)
+
+				if update {
+					x.Response.Status = recorder.Code
+					for name := range x.Response.Headers {
+						x.Response.Headers[name] = mask(recorder.Header().Get(name))
+					}
+					x.Response.Body = mask(recorder.Body.String())
+					continue
+				}
+
+				Ω(recorder.Code).Should(Equal(x.Response.Status), step)
+				for name, value := range x.Response.Headers {
+					Ω(mask(recorder.Header().Get(name))).Should(Equal(value), step+", header "+name)
+				}
+				Ω(mask(recorder.Body.String())).Should(Equal(x.Response.Body), step)
+			}
+
+			if update {
+				out := &bytes.Buffer{}
+				encoder := json.NewEncoder(out)
+				encoder.SetEscapeHTML(false)
+				encoder.SetIndent("", "  ")
+				Ω(encoder.Encode(c)).Should(Succeed())
+				Ω(ioutil.WriteFile(file, out.Bytes(), 0644)).Should(Succeed())
+			}
+		})
+	}
+})
@@ -0,0 +1,241 @@
+package data
+
+import (
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// DefaultRecomputeSLABatchSize is the number of tickets recomputed at once when the request does not specify one.
+const DefaultRecomputeSLABatchSize = 500
+
+// AdminRequest model definition. Every admin request names the operator taking the action and the reason to take it,
+// both of which are recorded in the audit log along with the request. The admin API sets the operator to the one its
+// token is issued to, whatever the request body says.
+type AdminRequest struct {
+	Operator string `json:"operator"`
+	Reason   string `json:"reason"`
+}
+
+// Validate validates the request.
+func (r *AdminRequest) Validate() *errors.Type {
+	r.Operator = normalize(r.Operator)
+	if isBlank(r.Operator) {
+		return errors.InvalidArgument("operator.is_required", "")
+	}
+
+	if len(r.Operator) > 50 {
+		return errors.InvalidArgument("operator.invalid_length", "")
+	}
+
+	r.Reason = normalize(r.Reason)
+	if isBlank(r.Reason) {
+		return errors.InvalidArgument("reason.is_required", "")
+	}
+
+	if len(r.Reason) > 255 {
+		return errors.InvalidArgument("reason.invalid_length", "")
+	}
+
+	return nil
+}
+
+// FlushCachesRequest model definition.
+type FlushCachesRequest struct {
+	AdminRequest
+}
+
+// RotateAPIKeysRequest model definition. It replaces all API keys of the tenant of the issuer with a new one.
+type RotateAPIKeysRequest struct {
+	AdminRequest
+	Issuer string `json:"issuer"`
+}
+
+// Validate validates the request.
+func (r *RotateAPIKeysRequest) Validate() *errors.Type {
+	if e := r.AdminRequest.Validate(); e != nil {
+		return e
+	}
+
+	r.Issuer = normalize(r.Issuer)
+	if isBlank(r.Issuer) {
+		return errors.InvalidArgument("issuer.is_required", "")
+	}
+
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	return nil
+}
+
+// RotateAPIKeysResponse model definition. The API key is only ever sent back here, it can not be recovered later.
+type RotateAPIKeysResponse struct {
+	Issuer      string `json:"issuer"`
+	APIKey      string `json:"apiKey"`
+	RevokedKeys int64  `json:"revokedKeys"`
+}
+
+// ArchiveTicketsRequest model definition. It archives the closed tickets not modified for OlderThan, e.g. 2160h,
+// whether or not archiving is scheduled.
+type ArchiveTicketsRequest struct {
+	AdminRequest
+	OlderThan string `json:"olderThan"`
+}
+
+// Validate validates the request.
+func (r *ArchiveTicketsRequest) Validate() *errors.Type {
+	if e := r.AdminRequest.Validate(); e != nil {
+		return e
+	}
+
+	if isBlank(r.OlderThan) {
+		return errors.InvalidArgument("olderThan.is_required", "")
+	}
+
+	if olderThan, e := time.ParseDuration(r.OlderThan); e != nil || olderThan <= 0 {
+		return errors.InvalidArgument("olderThan.not_valid", "")
+	}
+
+	return nil
+}
+
+// RecomputeSLARequest model definition. It resolves the tier and SLA deadlines of the tickets created within the
+// dates, of the issuer if not empty, again.
+type RecomputeSLARequest struct {
+	AdminRequest
+	Issuer    string `json:"issuer"`
+	FromDate  string `json:"fromDate"`
+	ToDate    string `json:"toDate"`
+	BatchSize int    `json:"batchSize"`
+}
+
+// Validate validates the request.
+func (r *RecomputeSLARequest) Validate() *errors.Type {
+	if e := r.AdminRequest.Validate(); e != nil {
+		return e
+	}
+
+	r.Issuer = normalize(r.Issuer)
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	if isBlank(r.FromDate) {
+		return errors.InvalidArgument("fromDate.is_required", "")
+	}
+
+	if isBlank(r.ToDate) {
+		return errors.InvalidArgument("toDate.is_required", "")
+	}
+
+	fromDate, e := time.Parse(time.RFC3339Nano, r.FromDate)
+	if e != nil {
+		return errors.InvalidArgument("fromDate.not_valid", "")
+	}
+
+	toDate, e := time.Parse(time.RFC3339Nano, r.ToDate)
+	if e != nil || !toDate.After(fromDate) {
+		return errors.InvalidArgument("toDate.not_valid", "")
+	}
+
+	if r.BatchSize == 0 {
+		r.BatchSize = DefaultRecomputeSLABatchSize
+	}
+
+	if r.BatchSize < 1 || r.BatchSize > 5000 {
+		return errors.InvalidArgument("batchSize.not_valid", "")
+	}
+
+	return nil
+}
+
+// AdminAuditLogsRequest model definition. It loads up to Limit audit logs of the action, or of all actions if it is
+// empty, the most recent first. PageToken continues right after the last audit log of the page it was replied with.
+type AdminAuditLogsRequest struct {
+	Action    models.AdminAction `json:"action"`
+	Limit     int                `json:"limit"`
+	PageToken string             `json:"pageToken,omitempty"`
+}
+
+// Validate validates the request.
+func (r *AdminAuditLogsRequest) Validate() *errors.Type {
+	if r.Action != "" && !r.Action.IsValid() {
+		return errors.InvalidArgument("action.not_valid", "")
+	}
+
+	if r.Limit == 0 {
+		r.Limit = 50
+	}
+
+	if r.Limit < 1 || r.Limit > 500 {
+		return errors.InvalidArgument("limit.not_valid", "")
+	}
+
+	if len(r.PageToken) > 1024 {
+		return errors.InvalidArgument("pageToken.invalid_length", "")
+	}
+
+	return nil
+}
+
+// AdminAuditLogResponse model definition.
+type AdminAuditLogResponse struct {
+	ID           int64              `json:"id"`
+	Operator     string             `json:"operator"`
+	Action       models.AdminAction `json:"action"`
+	Reason       string             `json:"reason"`
+	Parameters   string             `json:"parameters"`
+	Succeeded    bool               `json:"succeeded"`
+	Failure      string             `json:"failure,omitempty"`
+	CreatedAt    string             `json:"createdAt"`
+	PreviousHash string             `json:"previousHash,omitempty"`
+	Hash         string             `json:"hash,omitempty"`
+}
+
+// LoadFromAdminAuditLog populates the fields of current model from provided audit log.
+func (r *AdminAuditLogResponse) LoadFromAdminAuditLog(log *models.AdminAuditLog) {
+	r.ID = log.ID
+	r.Operator = log.Operator
+	r.Action = log.Action
+	r.Reason = log.Reason
+	r.Parameters = log.Parameters
+	r.Succeeded = log.Succeeded
+	r.Failure = log.Failure
+	r.CreatedAt = log.CreatedAt.Format(time.RFC3339Nano)
+	r.PreviousHash = log.PreviousHash
+	r.Hash = log.Hash
+}
+
+// AdminAuditLogsResponse model definition. NextPageToken is only set when there are older audit logs.
+type AdminAuditLogsResponse struct {
+	AuditLogs     []*AdminAuditLogResponse `json:"auditLogs"`
+	NextPageToken string                   `json:"nextPageToken,omitempty"`
+}
+
+// LoadFromAdminAuditLogs populates the fields of current model from provided audit logs.
+func (r *AdminAuditLogsResponse) LoadFromAdminAuditLogs(logs []*models.AdminAuditLog) {
+	r.AuditLogs = make([]*AdminAuditLogResponse, 0, len(logs))
+	for _, log := range logs {
+		response := &AdminAuditLogResponse{}
+		response.LoadFromAdminAuditLog(log)
+		r.AuditLogs = append(r.AuditLogs, response)
+	}
+}
+
+// AdminAuditChainResponse model definition. Intact is false when the chain is broken at BrokenAt.
+type AdminAuditChainResponse struct {
+	Intact   bool   `json:"intact"`
+	Verified int64  `json:"verified"`
+	BrokenAt int64  `json:"brokenAt,omitempty"`
+	Head     string `json:"head"`
+}
+
+// LoadFromAdminAuditChain populates the fields of current model from provided audit chain.
+func (r *AdminAuditChainResponse) LoadFromAdminAuditChain(chain *models.AdminAuditChain) {
+	r.Intact = chain.BrokenAt == 0
+	r.Verified = chain.Verified
+	r.BrokenAt = chain.BrokenAt
+	r.Head = chain.Head
+}
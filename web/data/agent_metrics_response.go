@@ -0,0 +1,33 @@
+package data
+
+import "github.com/jibitters/kiosk/models"
+
+// AgentMetricsResponse model definition.
+type AgentMetricsResponse struct {
+	Agents []*AgentMetricsRowResponse `json:"agents"`
+}
+
+// AgentMetricsRowResponse model definition. The average handle time is in seconds.
+type AgentMetricsRowResponse struct {
+	Agent             string  `json:"agent"`
+	TicketsResolved   int64   `json:"ticketsResolved"`
+	AverageHandleTime int64   `json:"averageHandleTime"`
+	RepliesSent       int64   `json:"repliesSent"`
+	TicketsReopened   int64   `json:"ticketsReopened"`
+	ReopenRate        float64 `json:"reopenRate"`
+}
+
+// LoadFromAgentMetrics populates the fields of current model from provided agent metrics.
+func (r *AgentMetricsResponse) LoadFromAgentMetrics(metrics []*models.AgentMetrics) {
+	r.Agents = make([]*AgentMetricsRowResponse, 0, len(metrics))
+	for _, m := range metrics {
+		r.Agents = append(r.Agents, &AgentMetricsRowResponse{
+			Agent:             m.Agent,
+			TicketsResolved:   m.TicketsResolved,
+			AverageHandleTime: int64(m.AverageHandleTime.Seconds()),
+			RepliesSent:       m.RepliesSent,
+			TicketsReopened:   m.TicketsReopened,
+			ReopenRate:        m.ReopenRate(),
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package data
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// Different alert status instances, as Alertmanager reports them.
+const (
+	AlertStatusFiring   = "firing"
+	AlertStatusResolved = "resolved"
+)
+
+// maxListedAlerts is the maximum number of alerts listed in the contents of tickets and comments, the rest are only
+// counted.
+const maxListedAlerts = 20
+
+// AlertmanagerWebhook model definition, the payload of the webhook receiver of Prometheus Alertmanager. It notifies
+// about a group of alerts, along with the labels and annotations the alerts of the group have in common.
+type AlertmanagerWebhook struct {
+	Version           string            `json:"version"`
+	GroupKey          string            `json:"groupKey"`
+	Status            string            `json:"status"`
+	Receiver          string            `json:"receiver"`
+	GroupLabels       map[string]string `json:"groupLabels"`
+	CommonLabels      map[string]string `json:"commonLabels"`
+	CommonAnnotations map[string]string `json:"commonAnnotations"`
+	ExternalURL       string            `json:"externalURL"`
+	Alerts            []*Alert          `json:"alerts"`
+}
+
+// Alert model definition, an alert of an Alertmanager alert group.
+type Alert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// Validate validates the request.
+func (r *AlertmanagerWebhook) Validate() *errors.Type {
+	if r.GroupKey == "" {
+		return errors.InvalidArgument("groupKey.is_required", "")
+	}
+
+	if r.Status != AlertStatusFiring && r.Status != AlertStatusResolved {
+		return errors.InvalidArgument("status.not_valid", "")
+	}
+
+	for _, alert := range r.Alerts {
+		if alert.Fingerprint == "" || len(alert.Fingerprint) > 64 {
+			return errors.InvalidArgument("fingerprint.invalid_length", "")
+		}
+
+		if alert.Status != AlertStatusFiring && alert.Status != AlertStatusResolved {
+			return errors.InvalidArgument("status.not_valid", "")
+		}
+	}
+
+	return nil
+}
+
+// ExternalKey returns back the external key the ticket of the alert group is linked to. Group keys have no length
+// limit, so they are hashed.
+func (r *AlertmanagerWebhook) ExternalKey() string {
+	sum := sha256.Sum256([]byte(r.GroupKey))
+	return "group:" + hex.EncodeToString(sum[:])
+}
+
+// Subject returns back the subject of the ticket of the alert group, the common summary of its alerts if any.
+func (r *AlertmanagerWebhook) Subject() string {
+	subject := r.CommonAnnotations["summary"]
+	if subject == "" {
+		subject = r.CommonLabels["alertname"]
+	}
+
+	if subject == "" {
+		subject = "Alert group " + labels(r.GroupLabels)
+	}
+
+	return truncate(subject, 255)
+}
+
+// ImportanceLevel returns back the importance level of the ticket of the alert group by the common severity label of
+// its alerts. It is empty for unknown severities.
+func (r *AlertmanagerWebhook) ImportanceLevel() models.TicketImportanceLevel {
+	switch strings.ToLower(r.CommonLabels["severity"]) {
+	case "critical", "page":
+		return models.TicketImportanceLevelCritical
+	case "error", "high":
+		return models.TicketImportanceLevelHigh
+	case "warning", "medium":
+		return models.TicketImportanceLevelMedium
+	case "info", "low":
+		return models.TicketImportanceLevelLow
+	}
+
+	return ""
+}
+
+// Describe returns back a plain text description of the alerts of the group, listing at most maxListedAlerts of
+// them. It is cut to the configured limits of contents, so large groups are never rejected.
+func (r *AlertmanagerWebhook) Describe(heading string, alerts []*Alert) string {
+	description := &strings.Builder{}
+	description.WriteString(heading)
+	description.WriteString("\n")
+
+	if common := r.CommonAnnotations["description"]; common != "" {
+		description.WriteString("\n")
+		description.WriteString(truncate(common, 1000))
+		description.WriteString("\n")
+	}
+
+	for i, alert := range alerts {
+		if i == maxListedAlerts {
+			_, _ = fmt.Fprintf(description, "\n... and %d more alerts.\n", len(alerts)-maxListedAlerts)
+			break
+		}
+
+		_, _ = fmt.Fprintf(description, "\n- %s", labels(alert.Labels))
+		if summary := alert.Annotations["summary"]; summary != "" {
+			_, _ = fmt.Fprintf(description, "\n  %s", truncate(summary, 500))
+		}
+
+		_, _ = fmt.Fprintf(description, "\n  Started at: %s", alert.StartsAt.UTC().Format(time.RFC3339))
+		if alert.Status == AlertStatusResolved && !alert.EndsAt.IsZero() {
+			_, _ = fmt.Fprintf(description, "\n  Resolved at: %s", alert.EndsAt.UTC().Format(time.RFC3339))
+		}
+
+		if alert.GeneratorURL != "" {
+			_, _ = fmt.Fprintf(description, "\n  Source: %s", alert.GeneratorURL)
+		}
+	}
+
+	characters := limits.TicketContentCharacters
+	if characters == 0 || (limits.CommentContentCharacters > 0 && limits.CommentContentCharacters < characters) {
+		characters = limits.CommentContentCharacters
+	}
+
+	if runes := []rune(description.String()); characters > 0 && len(runes) > characters {
+		return string(runes[:characters])
+	}
+
+	return description.String()
+}
+
+// labels formats labels as name="value" pairs, sorted by their names.
+func labels(l map[string]string) string {
+	pairs := make([]string, 0, len(l))
+	for name, value := range l {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", name, value))
+	}
+
+	sort.Strings(pairs)
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// truncate cuts the text to at most size bytes, on a rune boundary.
+func truncate(text string, size int) string {
+	if len(text) <= size {
+		return text
+	}
+
+	for size > 0 && !utf8.RuneStart(text[size]) {
+		size--
+	}
+
+	return text[:size]
+}
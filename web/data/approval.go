@@ -0,0 +1,236 @@
+package data
+
+import (
+	"net/mail"
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// maxApprovers is the maximum number of approvers of a status.
+const maxApprovers = 10
+
+// isApprovable checks whether moving tickets to the status can require an approval. Tickets waiting on their
+// customers are moved by kiosk itself as well, so that status can not.
+func isApprovable(status models.TicketStatus) bool {
+	switch status {
+	case models.TicketStatusReplied, models.TicketStatusResolved, models.TicketStatusClosed,
+		models.TicketStatusBlocked:
+		return true
+	}
+
+	return false
+}
+
+// SaveApproversRequest model definition.
+type SaveApproversRequest struct {
+	Issuer    string              `json:"issuer"`
+	Status    models.TicketStatus `json:"status"`
+	Approvers []string            `json:"approvers"`
+}
+
+// Validate validates the request.
+func (r *SaveApproversRequest) Validate() *errors.Type {
+	r.Issuer = normalize(r.Issuer)
+
+	if isBlank(r.Issuer) {
+		return errors.InvalidArgument("issuer.is_required", "")
+	}
+
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	if !isApprovable(r.Status) {
+		return errors.InvalidArgument("status.not_valid", "")
+	}
+
+	if len(r.Approvers) == 0 || len(r.Approvers) > maxApprovers {
+		return errors.InvalidArgument("approvers.invalid_length", "")
+	}
+
+	for _, approver := range r.Approvers {
+		if _, e := mail.ParseAddress(approver); e != nil || len(approver) > 255 {
+			return errors.InvalidArgument("approver.not_valid", "")
+		}
+	}
+
+	return nil
+}
+
+// ApproversRequest model definition.
+type ApproversRequest struct {
+	Issuer string              `json:"issuer"`
+	Status models.TicketStatus `json:"status"`
+}
+
+// Validate validates the request.
+func (r *ApproversRequest) Validate() *errors.Type {
+	if isBlank(r.Issuer) {
+		return errors.InvalidArgument("issuer.is_required", "")
+	}
+
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	if !isApprovable(r.Status) {
+		return errors.InvalidArgument("status.not_valid", "")
+	}
+
+	return nil
+}
+
+// ApproverResponse model definition.
+type ApproverResponse struct {
+	Status   models.TicketStatus `json:"status"`
+	Approver string              `json:"approver"`
+}
+
+// ApproversResponse model definition.
+type ApproversResponse struct {
+	Approvers []*ApproverResponse `json:"approvers"`
+}
+
+// LoadFromApprovers populates the fields of current model from provided approvers.
+func (r *ApproversResponse) LoadFromApprovers(approvers []*models.Approver) {
+	r.Approvers = make([]*ApproverResponse, 0, len(approvers))
+	for _, approver := range approvers {
+		r.Approvers = append(r.Approvers, &ApproverResponse{Status: approver.Status, Approver: approver.Approver})
+	}
+}
+
+// RequestApprovalRequest model definition. Status is the status the ticket is requested to be moved to.
+type RequestApprovalRequest struct {
+	TicketID    int64               `json:"ticketId"`
+	Status      models.TicketStatus `json:"status"`
+	RequestedBy string              `json:"requestedBy"`
+	Reason      string              `json:"reason"`
+}
+
+// Validate validates the request.
+func (r *RequestApprovalRequest) Validate() *errors.Type {
+	if r.TicketID < 1 {
+		return errors.InvalidArgument("ticketId.not_valid", "")
+	}
+
+	if !isApprovable(r.Status) {
+		return errors.InvalidArgument("status.not_valid", "")
+	}
+
+	r.RequestedBy = normalize(r.RequestedBy)
+
+	if isBlank(r.RequestedBy) {
+		return errors.InvalidArgument("requestedBy.is_required", "")
+	}
+
+	if len(r.RequestedBy) > 50 {
+		return errors.InvalidArgument("requestedBy.invalid_length", "")
+	}
+
+	r.Reason = normalize(r.Reason)
+	if len(r.Reason) > 1000 {
+		return errors.InvalidArgument("reason.invalid_length", "")
+	}
+
+	return nil
+}
+
+// AsTicketApproval converts this request model into ticket approval model.
+func (r *RequestApprovalRequest) AsTicketApproval() models.TicketApproval {
+	return models.TicketApproval{TicketID: r.TicketID, Status: r.Status, RequestedBy: r.RequestedBy,
+		Reason: r.Reason}
+}
+
+// DecideApprovalRequest model definition. It approves or rejects the pending approval of the ticket, depending on
+// the subject it is sent on.
+type DecideApprovalRequest struct {
+	TicketID int64  `json:"ticketId"`
+	Approver string `json:"approver"`
+	Note     string `json:"note"`
+}
+
+// Validate validates the request.
+func (r *DecideApprovalRequest) Validate() *errors.Type {
+	if r.TicketID < 1 {
+		return errors.InvalidArgument("ticketId.not_valid", "")
+	}
+
+	if isBlank(r.Approver) {
+		return errors.InvalidArgument("approver.is_required", "")
+	}
+
+	if len(r.Approver) > 255 {
+		return errors.InvalidArgument("approver.invalid_length", "")
+	}
+
+	r.Note = normalize(r.Note)
+	if len(r.Note) > 1000 {
+		return errors.InvalidArgument("note.invalid_length", "")
+	}
+
+	return nil
+}
+
+// TicketApprovalResponse model definition.
+type TicketApprovalResponse struct {
+	ID          int64                `json:"ID"`
+	TicketID    int64                `json:"ticketId"`
+	Status      models.TicketStatus  `json:"status"`
+	State       models.ApprovalState `json:"state"`
+	RequestedBy string               `json:"requestedBy"`
+	Reason      string               `json:"reason,omitempty"`
+	DecidedBy   string               `json:"decidedBy,omitempty"`
+	Note        string               `json:"note,omitempty"`
+	RequestedAt string               `json:"requestedAt"`
+	DecidedAt   string               `json:"decidedAt,omitempty"`
+	// Approvers are who can decide the approval, only set on TICKET_APPROVAL_REQUESTED events.
+	Approvers []string `json:"approvers,omitempty"`
+}
+
+// LoadFromTicketApproval populates the fields of current model from provided ticket approval.
+func (r *TicketApprovalResponse) LoadFromTicketApproval(approval *models.TicketApproval) {
+	r.ID = approval.ID
+	r.TicketID = approval.TicketID
+	r.Status = approval.Status
+	r.State = approval.State
+	r.RequestedBy = approval.RequestedBy
+	r.Reason = approval.Reason
+	r.DecidedBy = approval.DecidedBy
+	r.Note = approval.Note
+	r.RequestedAt = approval.RequestedAt.Format(time.RFC3339Nano)
+
+	if approval.DecidedAt != nil {
+		r.DecidedAt = approval.DecidedAt.Format(time.RFC3339Nano)
+	}
+}
+
+// TicketApprovalsRequest model definition.
+type TicketApprovalsRequest struct {
+	TicketID int64 `json:"ticketId"`
+}
+
+// Validate validates the request.
+func (r *TicketApprovalsRequest) Validate() *errors.Type {
+	if r.TicketID < 1 {
+		return errors.InvalidArgument("ticketId.not_valid", "")
+	}
+
+	return nil
+}
+
+// TicketApprovalsResponse model definition.
+type TicketApprovalsResponse struct {
+	Approvals []*TicketApprovalResponse `json:"approvals"`
+}
+
+// LoadFromTicketApprovals populates the fields of current model from provided ticket approvals.
+func (r *TicketApprovalsResponse) LoadFromTicketApprovals(approvals []*models.TicketApproval) {
+	r.Approvals = make([]*TicketApprovalResponse, 0, len(approvals))
+	for _, approval := range approvals {
+		approvalResponse := &TicketApprovalResponse{}
+		approvalResponse.LoadFromTicketApproval(approval)
+		r.Approvals = append(r.Approvals, approvalResponse)
+	}
+}
@@ -0,0 +1,164 @@
+package data
+
+import (
+	"strings"
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// AttachmentChunkSize is the most bytes of content sent in a single message, well below the payload limit of nats.
+const AttachmentChunkSize = 256 * 1024
+
+// CreateAttachmentRequest model definition. It starts the upload of a file attached to the ticket, or to one of its
+// comments, whose content is then uploaded in chunks.
+type CreateAttachmentRequest struct {
+	TicketID    int64  `json:"ticketId"`
+	CommentID   int64  `json:"commentId"`
+	Owner       string `json:"owner"`
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+}
+
+// Validate validates the request.
+func (r *CreateAttachmentRequest) Validate() *errors.Type {
+	if r.TicketID <= 0 {
+		return errors.InvalidArgument("ticketId.not_valid", "")
+	}
+
+	if r.CommentID < 0 {
+		return errors.InvalidArgument("commentId.not_valid", "")
+	}
+
+	r.Owner = normalize(r.Owner)
+	if isBlank(r.Owner) {
+		return errors.InvalidArgument("owner.is_required", "")
+	}
+
+	if len(r.Owner) > 50 {
+		return errors.InvalidArgument("owner.invalid_length", "")
+	}
+
+	r.Name = normalize(r.Name)
+	if isBlank(r.Name) {
+		return errors.InvalidArgument("name.is_required", "")
+	}
+
+	if len(r.Name) > 255 {
+		return errors.InvalidArgument("name.invalid_length", "")
+	}
+
+	if strings.ContainsAny(r.Name, `/\"`) {
+		return errors.InvalidArgument("name.not_valid", "")
+	}
+
+	r.ContentType = normalize(r.ContentType)
+	if isBlank(r.ContentType) {
+		return errors.InvalidArgument("contentType.is_required", "")
+	}
+
+	if len(r.ContentType) > 100 {
+		return errors.InvalidArgument("contentType.invalid_length", "")
+	}
+
+	return nil
+}
+
+// AsAttachment converts this request model into attachment model. Should be called after Validate.
+func (r *CreateAttachmentRequest) AsAttachment() *models.Attachment {
+	return &models.Attachment{TicketID: r.TicketID, CommentID: r.CommentID, Owner: r.Owner, Name: r.Name,
+		ContentType: r.ContentType}
+}
+
+// UploadAttachmentChunkRequest model definition. Chunks are uploaded in order, each starting at the offset the
+// previous one ended, and the last one completes the upload.
+type UploadAttachmentChunkRequest struct {
+	ID      int64  `json:"id"`
+	Offset  int64  `json:"offset"`
+	Content []byte `json:"content"`
+	Last    bool   `json:"last"`
+}
+
+// Validate validates the request.
+func (r *UploadAttachmentChunkRequest) Validate() *errors.Type {
+	if r.ID <= 0 {
+		return errors.InvalidArgument("id.not_valid", "")
+	}
+
+	if r.Offset < 0 {
+		return errors.InvalidArgument("offset.not_valid", "")
+	}
+
+	if len(r.Content) > AttachmentChunkSize {
+		return errors.InvalidArgument("content.invalid_length", "")
+	}
+
+	if len(r.Content) == 0 && !r.Last {
+		return errors.InvalidArgument("content.is_required", "")
+	}
+
+	return nil
+}
+
+// ListAttachmentsRequest model definition.
+type ListAttachmentsRequest struct {
+	PageRequest
+	TicketID int64 `json:"ticketId"`
+}
+
+// DownloadAttachmentChunkRequest model definition.
+type DownloadAttachmentChunkRequest struct {
+	ID     int64 `json:"id"`
+	Offset int64 `json:"offset"`
+}
+
+// DownloadAttachmentChunkResponse model definition. Content holds up to AttachmentChunkSize bytes, it is empty past
+// the end of the attachment.
+type DownloadAttachmentChunkResponse struct {
+	Content []byte `json:"content"`
+}
+
+// AttachmentResponse model definition.
+type AttachmentResponse struct {
+	ID          int64  `json:"id"`
+	TicketID    int64  `json:"ticketId"`
+	CommentID   int64  `json:"commentId,omitempty"`
+	Owner       string `json:"owner"`
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+	Completed   bool   `json:"completed"`
+	CreatedAt   string `json:"createdAt"`
+	ModifiedAt  string `json:"modifiedAt"`
+}
+
+// LoadFromAttachment populates the fields of current model from provided attachment.
+func (r *AttachmentResponse) LoadFromAttachment(attachment *models.Attachment) {
+	r.ID = attachment.ID
+	r.TicketID = attachment.TicketID
+	r.CommentID = attachment.CommentID
+	r.Owner = attachment.Owner
+	r.Name = attachment.Name
+	r.ContentType = attachment.ContentType
+	r.Size = attachment.Size
+	r.Completed = attachment.CompletedAt != nil
+	r.CreatedAt = attachment.CreatedAt.Format(time.RFC3339Nano)
+	r.ModifiedAt = attachment.ModifiedAt.Format(time.RFC3339Nano)
+}
+
+// AttachmentsResponse model definition. NextPageToken is only set when there is a next page.
+type AttachmentsResponse struct {
+	Attachments   []*AttachmentResponse `json:"attachments"`
+	NextPageToken string                `json:"nextPageToken,omitempty"`
+}
+
+// LoadFromAttachments populates the fields of current model from provided attachments.
+func (r *AttachmentsResponse) LoadFromAttachments(attachments []*models.Attachment) {
+	r.Attachments = make([]*AttachmentResponse, 0, len(attachments))
+	for _, attachment := range attachments {
+		response := &AttachmentResponse{}
+		response.LoadFromAttachment(attachment)
+		r.Attachments = append(r.Attachments, response)
+	}
+}
@@ -0,0 +1,136 @@
+package data
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// currencyPattern matches ISO 4217 currency codes.
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// SaveBillingRateRequest model definition. The hourly rate is in minor units of the currency, e.g. cents.
+type SaveBillingRateRequest struct {
+	Issuer     string `json:"issuer"`
+	HourlyRate int64  `json:"hourlyRate"`
+	Currency   string `json:"currency"`
+}
+
+// Validate validates the request.
+func (r *SaveBillingRateRequest) Validate() *errors.Type {
+	r.Issuer = normalize(r.Issuer)
+	r.Currency = strings.ToUpper(strings.TrimSpace(r.Currency))
+
+	if isBlank(r.Issuer) {
+		return errors.InvalidArgument("issuer.is_required", "")
+	}
+
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	if r.HourlyRate < 1 {
+		return errors.InvalidArgument("hourlyRate.not_valid", "")
+	}
+
+	if !currencyPattern.MatchString(r.Currency) {
+		return errors.InvalidArgument("currency.not_valid", "")
+	}
+
+	return nil
+}
+
+// AsBillingRate converts this request model into billing rate model.
+func (r *SaveBillingRateRequest) AsBillingRate() *models.BillingRate {
+	return &models.BillingRate{Issuer: r.Issuer, HourlyRate: r.HourlyRate, Currency: r.Currency}
+}
+
+// BillingRateResponse model definition.
+type BillingRateResponse struct {
+	Issuer     string `json:"issuer"`
+	HourlyRate int64  `json:"hourlyRate"`
+	Currency   string `json:"currency"`
+}
+
+// LoadFromBillingRate populates the fields of current model from provided billing rate.
+func (r *BillingRateResponse) LoadFromBillingRate(rate *models.BillingRate) {
+	r.Issuer = rate.Issuer
+	r.HourlyRate = rate.HourlyRate
+	r.Currency = rate.Currency
+}
+
+// SetBillableRequest model definition.
+type SetBillableRequest struct {
+	TicketID int64 `json:"ticketId"`
+	Billable bool  `json:"billable"`
+}
+
+// Validate validates the request.
+func (r *SetBillableRequest) Validate() *errors.Type {
+	if r.TicketID < 1 {
+		return errors.InvalidArgument("ticketId.not_valid", "")
+	}
+
+	return nil
+}
+
+// BillingExportRequest model definition. Month is formatted as YYYY-MM in UTC and defaults to the previous month.
+type BillingExportRequest struct {
+	Month string `json:"month"`
+}
+
+// Validate validates the request.
+func (r *BillingExportRequest) Validate() *errors.Type {
+	if r.Month == "" {
+		now := time.Now().UTC()
+		r.Month = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0).Format("2006-01")
+	}
+
+	if _, e := time.Parse("2006-01", r.Month); e != nil {
+		return errors.InvalidArgument("month.not_valid", "")
+	}
+
+	return nil
+}
+
+// Period returns back the from and to dates of the month of a validated request.
+func (r *BillingExportRequest) Period() (string, string) {
+	from, _ := time.Parse("2006-01", r.Month)
+	return from.Format(time.RFC3339Nano), from.AddDate(0, 1, 0).Format(time.RFC3339Nano)
+}
+
+// BillingExportResponse model definition.
+type BillingExportResponse struct {
+	Month string                      `json:"month"`
+	Rows  []*BillingExportRowResponse `json:"rows"`
+}
+
+// BillingExportRowResponse model definition. The hourly rate and amount are in minor units of the currency, and are
+// zero along with an empty currency when the issuer has no billing rate.
+type BillingExportRowResponse struct {
+	Issuer          string `json:"issuer"`
+	BillableTickets int64  `json:"billableTickets"`
+	BillableMinutes int64  `json:"billableMinutes"`
+	HourlyRate      int64  `json:"hourlyRate"`
+	Currency        string `json:"currency,omitempty"`
+	Amount          int64  `json:"amount"`
+}
+
+// LoadFromBillingRows populates the fields of current model from provided billing rows.
+func (r *BillingExportResponse) LoadFromBillingRows(month string, rows []*models.BillingRow) {
+	r.Month = month
+	r.Rows = make([]*BillingExportRowResponse, 0, len(rows))
+	for _, row := range rows {
+		r.Rows = append(r.Rows, &BillingExportRowResponse{
+			Issuer:          row.Issuer,
+			BillableTickets: row.BillableTickets,
+			BillableMinutes: int64(row.BillableTime / time.Minute),
+			HourlyRate:      row.HourlyRate,
+			Currency:        row.Currency,
+			Amount:          row.Amount(),
+		})
+	}
+}
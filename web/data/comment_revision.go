@@ -0,0 +1,57 @@
+package data
+
+import (
+	"github.com/jibitters/kiosk/diffing"
+	"github.com/jibitters/kiosk/errors"
+)
+
+// DiffCommentRevisionsRequest model definition. RevA and RevB are the revisions of the comment content to diff, either
+// previous revisions or the current one.
+type DiffCommentRevisionsRequest struct {
+	CommentID int64 `json:"commentId"`
+	RevA      int   `json:"revA"`
+	RevB      int   `json:"revB"`
+}
+
+// Validate validates the request.
+func (r *DiffCommentRevisionsRequest) Validate() *errors.Type {
+	if r.CommentID < 1 {
+		return errors.InvalidArgument("commentId.not_valid", "")
+	}
+
+	if r.RevA < 1 {
+		return errors.InvalidArgument("revA.not_valid", "")
+	}
+
+	if r.RevB < 1 {
+		return errors.InvalidArgument("revB.not_valid", "")
+	}
+
+	return nil
+}
+
+// DiffChangeResponse model definition.
+type DiffChangeResponse struct {
+	Operation diffing.Operation `json:"operation"`
+	Text      string            `json:"text"`
+}
+
+// CommentRevisionsDiffResponse model definition. Changes turn the content at RevA into the content at RevB, word by
+// word.
+type CommentRevisionsDiffResponse struct {
+	CommentID int64                 `json:"commentId"`
+	RevA      int                   `json:"revA"`
+	RevB      int                   `json:"revB"`
+	Changes   []*DiffChangeResponse `json:"changes"`
+}
+
+// LoadFromChanges populates the fields of current model from provided changes between the revisions of a comment.
+func (r *CommentRevisionsDiffResponse) LoadFromChanges(commentID int64, revA, revB int, changes []diffing.Change) {
+	r.CommentID = commentID
+	r.RevA = revA
+	r.RevB = revB
+	r.Changes = make([]*DiffChangeResponse, 0, len(changes))
+	for _, change := range changes {
+		r.Changes = append(r.Changes, &DiffChangeResponse{Operation: change.Operation, Text: change.Text})
+	}
+}
@@ -0,0 +1,24 @@
+package data
+
+import (
+	"regexp"
+
+	"github.com/jibitters/kiosk/errors"
+)
+
+// ConsistencyToken model definition. Mutations reply with a token that subsequent reads may pass along to observe the
+// mutation, regardless of replica lag and caches.
+type ConsistencyToken struct {
+	ConsistencyToken string `json:"consistencyToken"`
+}
+
+var consistencyTokenPattern = regexp.MustCompile(`^[0-9A-F]{1,8}/[0-9A-F]{1,8}$`)
+
+// validateConsistencyToken validates the optional consistency token of read requests.
+func validateConsistencyToken(token string) *errors.Type {
+	if token != "" && !consistencyTokenPattern.MatchString(token) {
+		return errors.InvalidArgument("consistencyToken.not_valid", "")
+	}
+
+	return nil
+}
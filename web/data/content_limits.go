@@ -0,0 +1,68 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jibitters/kiosk/errors"
+)
+
+// ContentLimits holds the configurable limits of ticket and comment contents. Zero values mean no limit.
+type ContentLimits struct {
+	TicketContentCharacters  int
+	CommentContentCharacters int
+	ContentWords             int
+	PreviewCharacters        int
+}
+
+var limits = DefaultContentLimits()
+
+// DefaultContentLimits returns back the limits used when nothing is configured.
+func DefaultContentLimits() ContentLimits {
+	return ContentLimits{
+		TicketContentCharacters:  5000,
+		CommentContentCharacters: 5000,
+		ContentWords:             0,
+		PreviewCharacters:        200,
+	}
+}
+
+// SetContentLimits replaces the limits that requests are validated against. It should be called once on startup.
+func SetContentLimits(l ContentLimits) {
+	limits = l
+}
+
+// validateContent checks the content against the character and word limits. The returned error message reports both
+// the limit and the actual size, so clients can tell users how much they should shorten the content.
+func validateContent(content string, characters int) *errors.Type {
+	if isBlank(content) {
+		return errors.InvalidArgument("content.is_required", "")
+	}
+
+	if count := utf8.RuneCountInString(content); characters > 0 && count > characters {
+		return errors.InvalidArgument("content.invalid_length",
+			fmt.Sprintf("content has %d characters but at most %d characters are allowed", count, characters))
+	}
+
+	if count := len(strings.Fields(content)); limits.ContentWords > 0 && count > limits.ContentWords {
+		return errors.InvalidArgument("content.too_many_words",
+			fmt.Sprintf("content has %d words but at most %d words are allowed", count, limits.ContentWords))
+	}
+
+	return nil
+}
+
+// preview truncates the content to the configured preview length, cutting on a word boundary when possible.
+func preview(content string) string {
+	if utf8.RuneCountInString(content) <= limits.PreviewCharacters {
+		return content
+	}
+
+	truncated := string([]rune(content)[:limits.PreviewCharacters])
+	if i := strings.LastIndexAny(truncated, " \n\t"); i > len(truncated)/2 {
+		truncated = truncated[:i]
+	}
+
+	return strings.TrimSpace(truncated) + "…"
+}
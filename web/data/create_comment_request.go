@@ -11,15 +11,27 @@ type CreateCommentRequest struct {
 	Owner    string `json:"owner"`
 	Content  string `json:"content"`
 	Metadata string `json:"metadata"`
+	// AuthorType is resolved from the owner of the ticket when empty, SYSTEM is reserved for kiosk itself.
+	AuthorType models.CommentAuthorType `json:"authorType"`
+	// Source is set by the transport layer the comment arrived through.
+	Source models.CommentSource `json:"source"`
+	// AttachmentIDs are the completed attachments of the ticket the comment refers to, they get attached to it.
+	AttachmentIDs []int64 `json:"attachmentIDs"`
 }
 
+// maxCommentAttachments is the number of attachments a comment can refer to at most.
+const maxCommentAttachments = 20
+
 // Validate validates the request.
 func (r *CreateCommentRequest) Validate() *errors.Type {
 	if r.TicketID <= 0 {
 		return errors.InvalidArgument("ticketID.invalid", "")
 	}
 
-	if len(r.Owner) == 0 {
+	r.Owner = normalize(r.Owner)
+	r.Content = normalize(r.Content)
+
+	if isBlank(r.Owner) {
 		return errors.InvalidArgument("owner.is_required", "")
 	}
 
@@ -27,12 +39,33 @@ func (r *CreateCommentRequest) Validate() *errors.Type {
 		return errors.InvalidArgument("owner.invalid_length", "")
 	}
 
-	if len(r.Content) == 0 {
-		return errors.InvalidArgument("content.is_required", "")
+	if e := validateContent(r.Content, limits.CommentContentCharacters); e != nil {
+		return e
+	}
+
+	if r.AuthorType != "" && (!r.AuthorType.IsValid() || r.AuthorType == models.CommentAuthorTypeSystem) {
+		return errors.InvalidArgument("authorType.not_valid", "")
+	}
+
+	if r.Source != "" && !r.Source.IsValid() {
+		return errors.InvalidArgument("source.not_valid", "")
+	}
+
+	if len(r.AttachmentIDs) > maxCommentAttachments {
+		return errors.InvalidArgument("attachmentIDs.too_many", "")
 	}
 
-	if len(r.Content) > 5000 {
-		return errors.InvalidArgument("content.invalid_length", "")
+	referenced := make(map[int64]bool, len(r.AttachmentIDs))
+	for _, id := range r.AttachmentIDs {
+		if id <= 0 {
+			return errors.InvalidArgument("attachmentIDs.invalid", "")
+		}
+
+		if referenced[id] {
+			return errors.InvalidArgument("attachmentIDs.duplicated", "")
+		}
+
+		referenced[id] = true
 	}
 
 	return nil
@@ -41,9 +74,12 @@ func (r *CreateCommentRequest) Validate() *errors.Type {
 // AsComment converts this request model into comment model.
 func (r *CreateCommentRequest) AsComment() *models.Comment {
 	return &models.Comment{
-		TicketID: r.TicketID,
-		Owner:    r.Owner,
-		Content:  r.Content,
-		Metadata: r.Metadata,
+		TicketID:      r.TicketID,
+		Owner:         r.Owner,
+		Content:       r.Content,
+		Metadata:      r.Metadata,
+		AuthorType:    r.AuthorType,
+		Source:        r.Source,
+		AttachmentIDs: r.AttachmentIDs,
 	}
 }
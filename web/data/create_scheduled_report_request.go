@@ -0,0 +1,76 @@
+package data
+
+import (
+	"net/mail"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/scheduler"
+)
+
+// CreateScheduledReportRequest model definition.
+type CreateScheduledReportRequest struct {
+	Name       string              `json:"name"`
+	Cron       string              `json:"cron"`
+	Report     models.ReportType   `json:"report"`
+	Issuer     string              `json:"issuer"`
+	PeriodDays int                 `json:"periodDays"`
+	Format     models.ReportFormat `json:"format"`
+	Recipients []string            `json:"recipients"`
+}
+
+// Validate validates the request.
+func (r *CreateScheduledReportRequest) Validate() *errors.Type {
+	if len(r.Name) == 0 {
+		return errors.InvalidArgument("name.is_required", "")
+	}
+
+	if len(r.Name) > 100 {
+		return errors.InvalidArgument("name.invalid_length", "")
+	}
+
+	if _, e := scheduler.Parse(r.Cron); e != nil || len(r.Cron) > 100 {
+		return errors.InvalidArgument("cron.not_valid", "")
+	}
+
+	if r.Report != models.ReportTypeDaily {
+		return errors.InvalidArgument("report.not_valid", "")
+	}
+
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	if r.PeriodDays < 1 || r.PeriodDays > 366 {
+		return errors.InvalidArgument("periodDays.not_valid", "")
+	}
+
+	if r.Format != models.ReportFormatCSV && r.Format != models.ReportFormatPDF {
+		return errors.InvalidArgument("format.not_valid", "")
+	}
+
+	if len(r.Recipients) == 0 {
+		return errors.InvalidArgument("recipients.is_required", "")
+	}
+
+	for _, recipient := range r.Recipients {
+		if _, e := mail.ParseAddress(recipient); e != nil {
+			return errors.InvalidArgument("recipients.not_valid", "")
+		}
+	}
+
+	return nil
+}
+
+// AsScheduledReport converts this request model into scheduled report model.
+func (r *CreateScheduledReportRequest) AsScheduledReport() *models.ScheduledReport {
+	return &models.ScheduledReport{
+		Name:       r.Name,
+		Cron:       r.Cron,
+		Report:     r.Report,
+		Issuer:     r.Issuer,
+		PeriodDays: r.PeriodDays,
+		Format:     r.Format,
+		Recipients: r.Recipients,
+	}
+}
@@ -1,6 +1,8 @@
 package data
 
 import (
+	"strings"
+
 	"github.com/jibitters/kiosk/errors"
 	"github.com/jibitters/kiosk/models"
 )
@@ -13,11 +15,22 @@ type CreateTicketRequest struct {
 	Content         string                       `json:"content"`
 	Metadata        string                       `json:"metadata"`
 	ImportanceLevel models.TicketImportanceLevel `json:"importanceLevel"`
+	Status          models.TicketStatus          `json:"status"`
+	// Fingerprint identifies the ticket for automated issuers, e.g. an alert. Tickets created again with the same
+	// fingerprint within the deduplication window are appended to the existing ones as comments.
+	Fingerprint string `json:"fingerprint"`
+	// Billable tickets are invoiced by the time logged on them.
+	Billable bool `json:"billable"`
 }
 
 // Validate validates the request.
 func (r *CreateTicketRequest) Validate() *errors.Type {
-	if len(r.Issuer) == 0 {
+	r.Issuer = normalize(r.Issuer)
+	r.Owner = normalize(r.Owner)
+	r.Subject = normalize(r.Subject)
+	r.Content = normalize(r.Content)
+
+	if isBlank(r.Issuer) {
 		return errors.InvalidArgument("issuer.is_required", "")
 	}
 
@@ -25,7 +38,7 @@ func (r *CreateTicketRequest) Validate() *errors.Type {
 		return errors.InvalidArgument("issuer.invalid_length", "")
 	}
 
-	if len(r.Owner) == 0 {
+	if isBlank(r.Owner) {
 		return errors.InvalidArgument("owner.is_required", "")
 	}
 
@@ -33,7 +46,7 @@ func (r *CreateTicketRequest) Validate() *errors.Type {
 		return errors.InvalidArgument("owner.invalid_length", "")
 	}
 
-	if len(r.Subject) == 0 {
+	if isBlank(r.Subject) {
 		return errors.InvalidArgument("subject.is_required", "")
 	}
 
@@ -41,25 +54,38 @@ func (r *CreateTicketRequest) Validate() *errors.Type {
 		return errors.InvalidArgument("subject.invalid_length", "")
 	}
 
-	if len(r.Content) == 0 {
-		return errors.InvalidArgument("content.is_required", "")
+	if e := validateContent(r.Content, limits.TicketContentCharacters); e != nil {
+		return e
 	}
 
-	if len(r.Content) > 5000 {
-		return errors.InvalidArgument("content.invalid_length", "")
+	// Importance level and status are optional, the issuer defaults get applied when they are missing.
+	if r.ImportanceLevel != "" && !r.ImportanceLevel.IsValid() {
+		return errors.InvalidArgument("importanceLevel.not_valid", "")
 	}
 
-	if r.ImportanceLevel != models.TicketImportanceLevelLow &&
-		r.ImportanceLevel != models.TicketImportanceLevelMedium &&
-		r.ImportanceLevel != models.TicketImportanceLevelHigh &&
-		r.ImportanceLevel != models.TicketImportanceLevelCritical {
+	if r.Status != "" && !r.Status.IsValid() {
+		return errors.InvalidArgument("status.not_valid", "")
+	}
 
-		return errors.InvalidArgument("importanceLevel.not_valid", "")
+	r.Fingerprint = strings.TrimSpace(r.Fingerprint)
+	if len(r.Fingerprint) > 255 {
+		return errors.InvalidArgument("fingerprint.invalid_length", "")
 	}
 
 	return nil
 }
 
+// ApplyDefaults fills the missing importance level and status with the defaults of the issuer.
+func (r *CreateTicketRequest) ApplyDefaults(settings *models.IssuerSettings) {
+	if r.ImportanceLevel == "" {
+		r.ImportanceLevel = settings.DefaultImportanceLevel
+	}
+
+	if r.Status == "" {
+		r.Status = settings.DefaultStatus
+	}
+}
+
 // AsTicket converts this request model into ticket model.
 func (r *CreateTicketRequest) AsTicket() *models.Ticket {
 	return &models.Ticket{
@@ -69,5 +95,8 @@ func (r *CreateTicketRequest) AsTicket() *models.Ticket {
 		Content:         r.Content,
 		Metadata:        r.Metadata,
 		ImportanceLevel: r.ImportanceLevel,
+		Status:          r.Status,
+		Fingerprint:     r.Fingerprint,
+		Billable:        r.Billable,
 	}
 }
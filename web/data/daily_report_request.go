@@ -0,0 +1,31 @@
+package data
+
+import (
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+)
+
+// DailyReportRequest model definition.
+type DailyReportRequest struct {
+	Issuer   string `json:"issuer"`
+	FromDate string `json:"fromDate"`
+	ToDate   string `json:"toDate"`
+}
+
+// Validate validates the request.
+func (r *DailyReportRequest) Validate() *errors.Type {
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	if r.FromDate == "" {
+		r.FromDate = time.Now().UTC().AddDate(0, 0, -30).Format(time.RFC3339Nano)
+	}
+
+	if r.ToDate == "" {
+		r.ToDate = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package data
+
+import "github.com/jibitters/kiosk/models"
+
+// DailyReportResponse model definition.
+type DailyReportResponse struct {
+	Rows []*DailyReportRowResponse `json:"rows"`
+}
+
+// DailyReportRowResponse model definition.
+type DailyReportRowResponse struct {
+	Day             string                       `json:"day"`
+	Issuer          string                       `json:"issuer"`
+	ImportanceLevel models.TicketImportanceLevel `json:"importanceLevel"`
+	Status          models.TicketStatus          `json:"status"`
+	Count           int64                        `json:"count"`
+}
+
+// LoadFromRows populates the fields of current model from provided report rows.
+func (r *DailyReportResponse) LoadFromRows(rows []*models.DailyReportRow) {
+	r.Rows = make([]*DailyReportRowResponse, 0, len(rows))
+	for _, row := range rows {
+		r.Rows = append(r.Rows, &DailyReportRowResponse{
+			Day:             row.Day.Format("2006-01-02"),
+			Issuer:          row.Issuer,
+			ImportanceLevel: row.ImportanceLevel,
+			Status:          row.Status,
+			Count:           row.Count,
+		})
+	}
+}
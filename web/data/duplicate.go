@@ -0,0 +1,140 @@
+package data
+
+import (
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// DefaultDuplicateMinSimilarity is how similar the subjects of tickets must be to be clustered as duplicates when the
+// request does not specify it, from 0 for unrelated to 1 for identical subjects.
+const DefaultDuplicateMinSimilarity = 0.8
+
+// DuplicateTicketsRequest model definition. The open tickets created after FromDate, the last week when omitted, are
+// clustered.
+type DuplicateTicketsRequest struct {
+	Issuer        string  `json:"issuer"`
+	FromDate      string  `json:"fromDate"`
+	MinSimilarity float64 `json:"minSimilarity"`
+	Limit         int     `json:"limit"`
+}
+
+// Validate validates the request.
+func (r *DuplicateTicketsRequest) Validate() *errors.Type {
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	if r.FromDate == "" {
+		r.FromDate = time.Now().UTC().Add(-7 * 24 * time.Hour).Format(time.RFC3339Nano)
+	}
+
+	if _, e := time.Parse(time.RFC3339Nano, r.FromDate); e != nil {
+		return errors.InvalidArgument("fromDate.not_valid", "")
+	}
+
+	if r.MinSimilarity == 0 {
+		r.MinSimilarity = DefaultDuplicateMinSimilarity
+	}
+
+	if r.MinSimilarity < 0 || r.MinSimilarity > 1 {
+		return errors.InvalidArgument("minSimilarity.not_valid", "")
+	}
+
+	if r.Limit == 0 {
+		r.Limit = 50
+	}
+
+	if r.Limit < 1 || r.Limit > 200 {
+		return errors.InvalidArgument("limit.not_valid", "")
+	}
+
+	return nil
+}
+
+// DuplicateTicketResponse model definition.
+type DuplicateTicketResponse struct {
+	TicketID        int64                        `json:"ticketId"`
+	Subject         string                       `json:"subject"`
+	ImportanceLevel models.TicketImportanceLevel `json:"importanceLevel"`
+	Status          models.TicketStatus          `json:"status"`
+	CreatedAt       string                       `json:"createdAt"`
+}
+
+// DuplicateClusterResponse model definition. TargetID is the oldest ticket of the cluster, the one to merge the rest
+// into.
+type DuplicateClusterResponse struct {
+	Issuer   string                     `json:"issuer"`
+	Owner    string                     `json:"owner"`
+	TargetID int64                      `json:"targetId"`
+	Tickets  []*DuplicateTicketResponse `json:"tickets"`
+}
+
+// DuplicateClustersResponse model definition.
+type DuplicateClustersResponse struct {
+	Clusters []*DuplicateClusterResponse `json:"clusters"`
+}
+
+// LoadFromDuplicateClusters populates the fields of current model from provided duplicate clusters.
+func (r *DuplicateClustersResponse) LoadFromDuplicateClusters(clusters []*models.DuplicateCluster) {
+	r.Clusters = make([]*DuplicateClusterResponse, 0, len(clusters))
+	for _, cluster := range clusters {
+		response := &DuplicateClusterResponse{Issuer: cluster.Issuer, Owner: cluster.Owner,
+			TargetID: cluster.Tickets[0].ID, Tickets: make([]*DuplicateTicketResponse, 0, len(cluster.Tickets))}
+
+		for _, ticket := range cluster.Tickets {
+			response.Tickets = append(response.Tickets, &DuplicateTicketResponse{
+				TicketID:        ticket.ID,
+				Subject:         ticket.Subject,
+				ImportanceLevel: ticket.ImportanceLevel,
+				Status:          ticket.Status,
+				CreatedAt:       ticket.CreatedAt.Format(time.RFC3339Nano),
+			})
+		}
+
+		r.Clusters = append(r.Clusters, response)
+	}
+}
+
+// MergeTicketsRequest model definition. The duplicates are merged into the target on behalf of the actor.
+type MergeTicketsRequest struct {
+	TargetID     int64   `json:"targetId"`
+	DuplicateIDs []int64 `json:"duplicateIds"`
+	Actor        string  `json:"actor"`
+}
+
+// Validate validates the request.
+func (r *MergeTicketsRequest) Validate() *errors.Type {
+	if r.TargetID <= 0 {
+		return errors.InvalidArgument("targetId.not_valid", "")
+	}
+
+	if len(r.DuplicateIDs) == 0 {
+		return errors.InvalidArgument("duplicateIds.is_required", "")
+	}
+
+	if len(r.DuplicateIDs) > 500 {
+		return errors.InvalidArgument("duplicateIds.invalid_length", "")
+	}
+
+	seen := make(map[int64]bool, len(r.DuplicateIDs))
+	for _, id := range r.DuplicateIDs {
+		if id <= 0 || id == r.TargetID || seen[id] {
+			return errors.InvalidArgument("duplicateIds.not_valid", "")
+		}
+
+		seen[id] = true
+	}
+
+	r.Actor = normalize(r.Actor)
+	if isBlank(r.Actor) {
+		return errors.InvalidArgument("actor.is_required", "")
+	}
+
+	if len(r.Actor) > 50 {
+		return errors.InvalidArgument("actor.invalid_length", "")
+	}
+
+	return nil
+}
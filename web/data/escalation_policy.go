@@ -0,0 +1,169 @@
+package data
+
+import (
+	"net/mail"
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// maxEscalationLevels is the maximum number of levels of an escalation chain.
+const maxEscalationLevels = 5
+
+// EscalationLevelRequest model definition.
+type EscalationLevelRequest struct {
+	DelayMinutes int    `json:"delayMinutes"`
+	Recipient    string `json:"recipient"`
+}
+
+// SaveEscalationPolicyRequest model definition. Levels are numbered by their order, starting at 1.
+type SaveEscalationPolicyRequest struct {
+	Issuer          string                       `json:"issuer"`
+	ImportanceLevel models.TicketImportanceLevel `json:"importanceLevel"`
+	Levels          []*EscalationLevelRequest    `json:"levels"`
+}
+
+// Validate validates the request.
+func (r *SaveEscalationPolicyRequest) Validate() *errors.Type {
+	r.Issuer = normalize(r.Issuer)
+
+	if isBlank(r.Issuer) {
+		return errors.InvalidArgument("issuer.is_required", "")
+	}
+
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	if !r.ImportanceLevel.IsValid() {
+		return errors.InvalidArgument("importanceLevel.not_valid", "")
+	}
+
+	if len(r.Levels) == 0 || len(r.Levels) > maxEscalationLevels {
+		return errors.InvalidArgument("levels.invalid_length", "")
+	}
+
+	previousDelay := 0
+	for _, level := range r.Levels {
+		if level == nil || level.DelayMinutes <= previousDelay {
+			return errors.InvalidArgument("delayMinutes.not_valid", "")
+		}
+
+		previousDelay = level.DelayMinutes
+
+		if _, e := mail.ParseAddress(level.Recipient); e != nil || len(level.Recipient) > 255 {
+			return errors.InvalidArgument("recipient.not_valid", "")
+		}
+	}
+
+	return nil
+}
+
+// AsEscalationLevels converts this request model into escalation level models.
+func (r *SaveEscalationPolicyRequest) AsEscalationLevels() []models.EscalationLevel {
+	levels := make([]models.EscalationLevel, 0, len(r.Levels))
+	for i, level := range r.Levels {
+		levels = append(levels, models.EscalationLevel{
+			Issuer:          r.Issuer,
+			ImportanceLevel: r.ImportanceLevel,
+			Level:           i + 1,
+			Delay:           time.Duration(level.DelayMinutes) * time.Minute,
+			Recipient:       level.Recipient,
+		})
+	}
+
+	return levels
+}
+
+// EscalationPolicyRequest model definition.
+type EscalationPolicyRequest struct {
+	Issuer          string                       `json:"issuer"`
+	ImportanceLevel models.TicketImportanceLevel `json:"importanceLevel"`
+}
+
+// Validate validates the request.
+func (r *EscalationPolicyRequest) Validate() *errors.Type {
+	if isBlank(r.Issuer) {
+		return errors.InvalidArgument("issuer.is_required", "")
+	}
+
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	if !r.ImportanceLevel.IsValid() {
+		return errors.InvalidArgument("importanceLevel.not_valid", "")
+	}
+
+	return nil
+}
+
+// EscalationLevelResponse model definition.
+type EscalationLevelResponse struct {
+	ImportanceLevel models.TicketImportanceLevel `json:"importanceLevel"`
+	Level           int                          `json:"level"`
+	DelayMinutes    int                          `json:"delayMinutes"`
+	Recipient       string                       `json:"recipient"`
+}
+
+// EscalationPoliciesResponse model definition.
+type EscalationPoliciesResponse struct {
+	Levels []*EscalationLevelResponse `json:"levels"`
+}
+
+// LoadFromEscalationLevels populates the fields of current model from provided escalation levels.
+func (r *EscalationPoliciesResponse) LoadFromEscalationLevels(levels []*models.EscalationLevel) {
+	r.Levels = make([]*EscalationLevelResponse, 0, len(levels))
+	for _, level := range levels {
+		r.Levels = append(r.Levels, &EscalationLevelResponse{
+			ImportanceLevel: level.ImportanceLevel,
+			Level:           level.Level,
+			DelayMinutes:    int(level.Delay / time.Minute),
+			Recipient:       level.Recipient,
+		})
+	}
+}
+
+// TicketEscalationResponse model definition.
+type TicketEscalationResponse struct {
+	Level       int    `json:"level"`
+	Recipient   string `json:"recipient"`
+	EscalatedAt string `json:"escalatedAt"`
+}
+
+// LoadFromTicketEscalation populates the fields of current model from provided ticket escalation.
+func (r *TicketEscalationResponse) LoadFromTicketEscalation(escalation *models.TicketEscalation) {
+	r.Level = escalation.Level
+	r.Recipient = escalation.Recipient
+	r.EscalatedAt = escalation.EscalatedAt.Format(time.RFC3339Nano)
+}
+
+// TicketEscalationsRequest model definition.
+type TicketEscalationsRequest struct {
+	TicketID int64 `json:"ticketId"`
+}
+
+// Validate validates the request.
+func (r *TicketEscalationsRequest) Validate() *errors.Type {
+	if r.TicketID < 1 {
+		return errors.InvalidArgument("ticketId.not_valid", "")
+	}
+
+	return nil
+}
+
+// TicketEscalationsResponse model definition.
+type TicketEscalationsResponse struct {
+	Escalations []*TicketEscalationResponse `json:"escalations"`
+}
+
+// LoadFromTicketEscalations populates the fields of current model from provided ticket escalations.
+func (r *TicketEscalationsResponse) LoadFromTicketEscalations(escalations []*models.TicketEscalation) {
+	r.Escalations = make([]*TicketEscalationResponse, 0, len(escalations))
+	for _, escalation := range escalations {
+		escalationResponse := &TicketEscalationResponse{}
+		escalationResponse.LoadFromTicketEscalation(escalation)
+		r.Escalations = append(r.Escalations, escalationResponse)
+	}
+}
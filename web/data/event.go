@@ -0,0 +1,40 @@
+package data
+
+import "github.com/jibitters/kiosk/models"
+
+// EventType model.
+type EventType string
+
+// Different event type instances.
+const (
+	EventTypeTicketCreated    EventType = "TICKET_CREATED"
+	EventTypeTicketUpdated    EventType = "TICKET_UPDATED"
+	EventTypeTicketDeleted    EventType = "TICKET_DELETED"
+	EventTypeTicketSnoozed    EventType = "TICKET_SNOOZED"
+	EventTypeTicketUnsnoozed  EventType = "TICKET_UNSNOOZED"
+	EventTypeTicketEscalated  EventType = "TICKET_ESCALATED"
+	EventTypeTicketAssigned   EventType = "TICKET_ASSIGNED"
+	EventTypeTicketUnassigned EventType = "TICKET_UNASSIGNED"
+	EventTypeCommentCreated   EventType = "COMMENT_CREATED"
+
+	EventTypeTicketApprovalRequested EventType = "TICKET_APPROVAL_REQUESTED"
+	EventTypeTicketApproved          EventType = "TICKET_APPROVED"
+	EventTypeTicketRejected          EventType = "TICKET_REJECTED"
+)
+
+// Event model definition. Events are published on `kiosk.events.*` subjects after successful changes.
+type Event struct {
+	Type           EventType           `json:"type"`
+	Ticket         *TicketResponse     `json:"ticket,omitempty"`
+	Comment        *CommentResponse    `json:"comment,omitempty"`
+	PreviousStatus models.TicketStatus `json:"previousStatus,omitempty"`
+	// Actor is who made the change, if known.
+	Actor string `json:"actor,omitempty"`
+	// Escalation is the level the ticket got escalated to, only set on TICKET_ESCALATED events.
+	Escalation *TicketEscalationResponse `json:"escalation,omitempty"`
+	// PreviousAssignee is who the ticket was assigned to before, only set on assignment events when it was assigned.
+	PreviousAssignee string `json:"previousAssignee,omitempty"`
+	// Approval is the approval requested or decided, only set on approval events.
+	Approval   *TicketApprovalResponse `json:"approval,omitempty"`
+	OccurredAt string                  `json:"occurredAt"`
+}
@@ -0,0 +1,121 @@
+package data
+
+import (
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// FilterCommentsRequest model definition.
+type FilterCommentsRequest struct {
+	PageRequest
+	TicketID int64 `json:"ticketID"`
+	// AuthorTypes and Sources restrict the comments to those of the provided author types and sources, if not empty.
+	AuthorTypes []models.CommentAuthorType `json:"authorTypes"`
+	Sources     []models.CommentSource     `json:"sources"`
+	// CountOnly and Exists reply back the number of matching comments or whether there is any, instead of the comments.
+	CountOnly bool `json:"countOnly,omitempty"`
+	Exists    bool `json:"exists,omitempty"`
+	// OrderBy orders comments by up to three keys, e.g. createdAt,id, the newest first when empty.
+	OrderBy string `json:"orderBy,omitempty"`
+	// AroundCommentID narrows the comments down to Window comments right before and right after the comment, along
+	// with it, so long threads can be opened at any of their comments. Latest does the same around the newest comment.
+	AroundCommentID int64 `json:"aroundCommentId,omitempty"`
+	Latest          bool  `json:"latest,omitempty"`
+	Window          int   `json:"window,omitempty"`
+}
+
+// Validate validates the request.
+func (r *FilterCommentsRequest) Validate() *errors.Type {
+	if r.TicketID <= 0 {
+		return errors.InvalidArgument("ticketID.invalid", "")
+	}
+
+	for _, authorType := range r.AuthorTypes {
+		if !authorType.IsValid() {
+			return errors.InvalidArgument("authorTypes.not_valid", "")
+		}
+	}
+
+	for _, source := range r.Sources {
+		if !source.IsValid() {
+			return errors.InvalidArgument("sources.not_valid", "")
+		}
+	}
+
+	if r.CountOnly && r.Exists {
+		return errors.InvalidArgument("countOnly.exclusive_with_exists", "")
+	}
+
+	if _, e := parseOrderBy(r.OrderBy, models.CommentOrderColumns); e != nil {
+		return e
+	}
+
+	if e := r.PageRequest.Validate(); e != nil {
+		return e
+	}
+
+	return r.validateWindow()
+}
+
+func (r *FilterCommentsRequest) validateWindow() *errors.Type {
+	if r.AroundCommentID < 0 {
+		return errors.InvalidArgument("aroundCommentId.not_valid", "")
+	}
+
+	if !r.Windowed() {
+		return nil
+	}
+
+	if r.AroundCommentID != 0 && r.Latest {
+		return errors.InvalidArgument("aroundCommentId.exclusive_with_latest", "")
+	}
+
+	if r.CountOnly || r.Exists {
+		return errors.InvalidArgument("aroundCommentId.exclusive_with_count", "")
+	}
+
+	if r.PageSize != 0 || r.PageToken != "" {
+		return errors.InvalidArgument("aroundCommentId.exclusive_with_page", "")
+	}
+
+	if r.Window == 0 {
+		r.Window = 10
+	}
+
+	if r.Window < 1 || r.Window > 50 {
+		return errors.InvalidArgument("window.not_valid", "")
+	}
+
+	return nil
+}
+
+// Windowed returns back whether only a window of comments around a comment is requested.
+func (r *FilterCommentsRequest) Windowed() bool {
+	return r.AroundCommentID != 0 || r.Latest
+}
+
+// Orders returns back the keys comments are ordered by. Should be called after Validate.
+func (r *FilterCommentsRequest) Orders() []models.Order {
+	orders, _ := parseOrderBy(r.OrderBy, models.CommentOrderColumns)
+	return orders
+}
+
+// FilterCommentsResponse model definition.
+type FilterCommentsResponse struct {
+	Comments []*CommentResponse `json:"comments"`
+	// HasMoreBefore and HasMoreAfter tell whether there are more comments on each side of the window, if requested.
+	HasMoreBefore bool `json:"hasMoreBefore,omitempty"`
+	HasMoreAfter  bool `json:"hasMoreAfter,omitempty"`
+	// NextPageToken is only set when there is a next page, it is only valid for the same order.
+	NextPageToken string `json:"nextPageToken,omitempty"`
+}
+
+// LoadFromComments populates the fields of current model from provided comments.
+func (r *FilterCommentsResponse) LoadFromComments(comments []*models.Comment) {
+	r.Comments = make([]*CommentResponse, 0, len(comments))
+	for _, comment := range comments {
+		commentResponse := &CommentResponse{}
+		commentResponse.LoadFromComment(comment)
+		r.Comments = append(r.Comments, commentResponse)
+	}
+}
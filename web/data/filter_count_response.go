@@ -0,0 +1,8 @@
+package data
+
+// FilterCountResponse model definition, replied back instead of the filtered records to filters in count only or
+// exists only modes. Only the field of the requested mode is set.
+type FilterCountResponse struct {
+	Count  *int64 `json:"count,omitempty"`
+	Exists *bool  `json:"exists,omitempty"`
+}
@@ -1,6 +1,7 @@
 package data
 
 import (
+	"strings"
 	"time"
 
 	"github.com/jibitters/kiosk/errors"
@@ -13,14 +14,46 @@ type FilterTicketsRequest struct {
 	Owner           string                       `json:"owner"`
 	ImportanceLevel models.TicketImportanceLevel `json:"importanceLevel"`
 	Status          models.TicketStatus          `json:"status"`
-	FromDate        string                       `json:"fromDate"`
-	ToDate          string                       `json:"toDate"`
-	PageNumber      int                          `json:"pageNumber"`
-	PageSize        int                          `json:"pageSize"`
+	// ResolutionCategory, ResolutionSubCategory and RootCause are optional, empty values match any.
+	ResolutionCategory    string           `json:"resolutionCategory"`
+	ResolutionSubCategory string           `json:"resolutionSubCategory"`
+	RootCause             models.RootCause `json:"rootCause"`
+	FromDate              string           `json:"fromDate"`
+	ToDate                string           `json:"toDate"`
+	// PageNumber pages are skipped unless PageToken is given, which starts the page right after the last ticket of the
+	// page it was returned back with, so paging stays stable while tickets get created and updated.
+	PageNumber  int    `json:"pageNumber"`
+	PageToken   string `json:"pageToken,omitempty"`
+	PageSize    int    `json:"pageSize"`
+	PreviewOnly bool   `json:"previewOnly"`
+	// Snoozed lists the snoozed tickets instead of the active ones.
+	Snoozed bool `json:"snoozed"`
+	// Metadata matches tickets by their metadata, up to five paths, e.g. owner_ip or customer.plan, to their values.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// CountOnly and Exists reply back the number of matching tickets or whether there is any, instead of the tickets.
+	// Like EstimatedTotal, Facets and GroupBy, they only cover the hot tickets, archived ones are left out.
+	CountOnly bool `json:"countOnly,omitempty"`
+	Exists    bool `json:"exists,omitempty"`
+	// EstimatedTotal adds the number of matching tickets across all pages to the response, estimated by the query
+	// planner so it stays fast for filters matching millions of tickets.
+	EstimatedTotal bool `json:"estimatedTotal,omitempty"`
+	// Facets adds the number of matching tickets per status and importance level to the response.
+	Facets bool `json:"facets,omitempty"`
+	// GroupBy groups tickets by status, importance or assignee, each group holding its number of tickets and its first
+	// PageSize tickets. The filter of the grouped field may be left empty to get all of its groups, unassigned tickets
+	// are grouped under an empty assignee.
+	GroupBy string `json:"groupBy,omitempty"`
+	// OrderBy orders tickets by up to three keys, e.g. createdAt:desc,id, the most recently modified first when empty.
+	OrderBy string `json:"orderBy,omitempty"`
+	// ConsistencyToken makes the read observe the mutation that issued it.
+	ConsistencyToken string `json:"consistencyToken,omitempty"`
 }
 
 // Validate validates the request.
 func (r *FilterTicketsRequest) Validate() *errors.Type {
+	r.Issuer = normalize(r.Issuer)
+	r.Owner = normalize(r.Owner)
+
 	if len(r.Issuer) > 50 {
 		return errors.InvalidArgument("issuer.invalid_length", "")
 	}
@@ -29,7 +62,14 @@ func (r *FilterTicketsRequest) Validate() *errors.Type {
 		return errors.InvalidArgument("owner.invalid_length", "")
 	}
 
-	if r.ImportanceLevel != models.TicketImportanceLevelLow &&
+	if r.GroupBy != "" {
+		if _, ok := models.TicketGroupColumns[r.GroupBy]; !ok {
+			return errors.InvalidArgument("groupBy.field_not_valid", "")
+		}
+	}
+
+	if !(r.GroupBy == "importance" && r.ImportanceLevel == "") &&
+		r.ImportanceLevel != models.TicketImportanceLevelLow &&
 		r.ImportanceLevel != models.TicketImportanceLevelMedium &&
 		r.ImportanceLevel != models.TicketImportanceLevelHigh &&
 		r.ImportanceLevel != models.TicketImportanceLevelCritical {
@@ -37,15 +77,29 @@ func (r *FilterTicketsRequest) Validate() *errors.Type {
 		return errors.InvalidArgument("importanceLevel.not_valid", "")
 	}
 
-	if r.Status != models.TicketStatusNew &&
+	if !(r.GroupBy == "status" && r.Status == "") &&
+		r.Status != models.TicketStatusNew &&
 		r.Status != models.TicketStatusReplied &&
 		r.Status != models.TicketStatusResolved &&
 		r.Status != models.TicketStatusClosed &&
-		r.Status != models.TicketStatusBlocked {
+		r.Status != models.TicketStatusBlocked &&
+		r.Status != models.TicketStatusWaitingOnCustomer {
 
 		return errors.InvalidArgument("status.not_valid", "")
 	}
 
+	if len(r.ResolutionCategory) > 50 || len(r.ResolutionSubCategory) > 50 {
+		return errors.InvalidArgument("resolutionCategory.invalid_length", "")
+	}
+
+	if r.RootCause != "" && !r.RootCause.IsValid() {
+		return errors.InvalidArgument("rootCause.not_valid", "")
+	}
+
+	if e := r.validateMetadata(); e != nil {
+		return e
+	}
+
 	if r.FromDate == "" {
 		r.FromDate = "2000-01-01T00:00:00Z"
 	}
@@ -54,13 +108,73 @@ func (r *FilterTicketsRequest) Validate() *errors.Type {
 		r.ToDate = time.Now().UTC().Format(time.RFC3339Nano)
 	}
 
-	if r.PageNumber < 1 {
-		return errors.InvalidArgument("pageNumber.not_valid", "")
+	if r.CountOnly && r.Exists {
+		return errors.InvalidArgument("countOnly.exclusive_with_exists", "")
+	}
+
+	if r.GroupBy != "" && (r.CountOnly || r.Exists) {
+		return errors.InvalidArgument("groupBy.exclusive_with_count", "")
+	}
+
+	// Pages do not apply when only counting or checking the existence of tickets, groups only hold their first page.
+	if !r.CountOnly && !r.Exists {
+		if r.PageNumber < 1 && r.PageToken == "" && r.GroupBy == "" {
+			return errors.InvalidArgument("pageNumber.not_valid", "")
+		}
+
+		if len(r.PageToken) > 1024 {
+			return errors.InvalidArgument("pageToken.invalid_length", "")
+		}
+
+		if r.PageSize < 1 || r.PageSize > 25 {
+			return errors.InvalidArgument("pageSize.not_valid", "")
+		}
+	}
+
+	if _, e := parseOrderBy(r.OrderBy, models.TicketOrderColumns); e != nil {
+		return e
 	}
 
-	if r.PageSize < 1 || r.PageSize > 25 {
-		return errors.InvalidArgument("pageSize.not_valid", "")
+	return validateConsistencyToken(r.ConsistencyToken)
+}
+
+// Resolution returns back the resolution tickets are filtered by.
+func (r *FilterTicketsRequest) Resolution() models.Resolution {
+	return models.Resolution{Category: r.ResolutionCategory, SubCategory: r.ResolutionSubCategory,
+		RootCause: r.RootCause}
+}
+
+// MetadataMatch returns back the metadata tickets are filtered by.
+func (r *FilterTicketsRequest) MetadataMatch() models.MetadataMatch {
+	return r.Metadata
+}
+
+func (r *FilterTicketsRequest) validateMetadata() *errors.Type {
+	if len(r.Metadata) > 5 {
+		return errors.InvalidArgument("metadata.too_many_paths", "")
+	}
+
+	for path, value := range r.Metadata {
+		if len(path) > 255 || len(value) > 255 {
+			return errors.InvalidArgument("metadata.invalid_length", "")
+		}
+
+		for _, segment := range strings.Split(path, ".") {
+			if isBlank(segment) {
+				return errors.InvalidArgument("metadata.path_not_valid", "")
+			}
+		}
+	}
+
+	if _, ok := r.MetadataMatch().Document(); !ok {
+		return errors.InvalidArgument("metadata.path_conflict", "")
 	}
 
 	return nil
 }
+
+// Orders returns back the keys tickets are ordered by. Should be called after Validate.
+func (r *FilterTicketsRequest) Orders() []models.Order {
+	orders, _ := parseOrderBy(r.OrderBy, models.TicketOrderColumns)
+	return orders
+}
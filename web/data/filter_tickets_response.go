@@ -6,6 +6,27 @@ import "github.com/jibitters/kiosk/models"
 type FilterTicketsResponse struct {
 	Tickets     []*TicketResponse `json:"tickets,omitempty"`
 	HasNextPage bool              `json:"hasNextPage"`
+	// NextPageToken is only set when there is a next page, it is opaque and only valid for the same filter.
+	NextPageToken string `json:"nextPageToken,omitempty"`
+	// Groups is only set when grouping tickets, instead of Tickets.
+	Groups []*TicketGroupResponse `json:"groups,omitempty"`
+	// EstimatedTotal is only set when requested, it is an estimate and may well be off from the actual number.
+	EstimatedTotal *int64 `json:"estimatedTotal,omitempty"`
+	// Facets is only set when requested.
+	Facets *TicketFacetsResponse `json:"facets,omitempty"`
+}
+
+// TicketFacetsResponse model definition. Each facet counts the matching tickets regardless of its own field, so the
+// counts of other values show what switching to them would match.
+type TicketFacetsResponse struct {
+	Statuses         map[models.TicketStatus]int64          `json:"statuses"`
+	ImportanceLevels map[models.TicketImportanceLevel]int64 `json:"importanceLevels"`
+}
+
+// LoadFromTicketFacets populates the fields of current model from provided facets.
+func (r *TicketFacetsResponse) LoadFromTicketFacets(facets *models.TicketFacets) {
+	r.Statuses = facets.Statuses
+	r.ImportanceLevels = facets.ImportanceLevels
 }
 
 // LoadFromTickets populates the fields of current model from provided tickets.
@@ -18,3 +39,39 @@ func (r *FilterTicketsResponse) LoadFromTickets(tickets []*models.Ticket, HasNex
 
 	r.HasNextPage = HasNextPage
 }
+
+// TicketGroupResponse model definition. Tickets are the first tickets of the group, Count is the number of all of
+// them.
+type TicketGroupResponse struct {
+	Value   string            `json:"value"`
+	Count   int64             `json:"count"`
+	Tickets []*TicketResponse `json:"tickets"`
+}
+
+// LoadFromTicketGroups populates the fields of current model from provided ticket groups.
+func (r *FilterTicketsResponse) LoadFromTicketGroups(groups []*models.TicketGroup) {
+	r.Groups = make([]*TicketGroupResponse, 0, len(groups))
+	for _, group := range groups {
+		groupResponse := &TicketGroupResponse{Value: group.Value, Count: group.Count}
+		for _, t := range group.Tickets {
+			ticketResponse := &TicketResponse{}
+			ticketResponse.LoadFromTicket(t)
+			groupResponse.Tickets = append(groupResponse.Tickets, ticketResponse)
+		}
+
+		r.Groups = append(r.Groups, groupResponse)
+	}
+}
+
+// UsePreviews replaces the contents of all tickets and comments with their truncated previews.
+func (r *FilterTicketsResponse) UsePreviews() {
+	for _, t := range r.Tickets {
+		t.UsePreviews()
+	}
+
+	for _, group := range r.Groups {
+		for _, t := range group.Tickets {
+			t.UsePreviews()
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package data
+
+import (
+	"time"
+
+	"github.com/jibitters/kiosk/documents"
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// CreateIncidentRequest model definition.
+type CreateIncidentRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// Validate validates the request.
+func (r *CreateIncidentRequest) Validate() *errors.Type {
+	r.Title = normalize(r.Title)
+	if isBlank(r.Title) {
+		return errors.InvalidArgument("title.is_required", "")
+	}
+
+	if len(r.Title) > 255 {
+		return errors.InvalidArgument("title.invalid_length", "")
+	}
+
+	r.Description = normalize(r.Description)
+	if isBlank(r.Description) {
+		r.Description = ""
+		return nil
+	}
+
+	return validateContent(r.Description, limits.TicketContentCharacters)
+}
+
+// AsIncident converts this request model into incident model. Should be called after Validate.
+func (r *CreateIncidentRequest) AsIncident() *models.Incident {
+	return &models.Incident{Title: r.Title, Description: r.Description}
+}
+
+// ListIncidentsRequest model definition. Resolved incidents are listed along with the open ones when asked for.
+type ListIncidentsRequest struct {
+	Resolved bool `json:"resolved"`
+}
+
+// AttachIncidentTicketsRequest model definition.
+type AttachIncidentTicketsRequest struct {
+	IncidentID int64   `json:"incidentId"`
+	TicketIDs  []int64 `json:"ticketIds"`
+}
+
+// Validate validates the request.
+func (r *AttachIncidentTicketsRequest) Validate() *errors.Type {
+	if r.IncidentID <= 0 {
+		return errors.InvalidArgument("incidentId.not_valid", "")
+	}
+
+	if len(r.TicketIDs) == 0 {
+		return errors.InvalidArgument("ticketIds.is_required", "")
+	}
+
+	if len(r.TicketIDs) > 500 {
+		return errors.InvalidArgument("ticketIds.invalid_length", "")
+	}
+
+	seen := make(map[int64]bool, len(r.TicketIDs))
+	for _, id := range r.TicketIDs {
+		if id <= 0 || seen[id] {
+			return errors.InvalidArgument("ticketIds.not_valid", "")
+		}
+
+		seen[id] = true
+	}
+
+	return nil
+}
+
+// DetachIncidentTicketRequest model definition.
+type DetachIncidentTicketRequest struct {
+	IncidentID int64 `json:"incidentId"`
+	TicketID   int64 `json:"ticketId"`
+}
+
+// Validate validates the request.
+func (r *DetachIncidentTicketRequest) Validate() *errors.Type {
+	if r.IncidentID <= 0 {
+		return errors.InvalidArgument("incidentId.not_valid", "")
+	}
+
+	if r.TicketID <= 0 {
+		return errors.InvalidArgument("ticketId.not_valid", "")
+	}
+
+	return nil
+}
+
+// ResolveIncidentRequest model definition. Resolving an incident posts the message, if not empty, on each of its
+// tickets on behalf of the actor and resolves the tickets along with the resolution, if asked to. The message is a
+// template rendered per ticket, see documents.IncidentMessage.
+type ResolveIncidentRequest struct {
+	IncidentID            int64            `json:"incidentId"`
+	Message               string           `json:"message"`
+	ResolveTickets        bool             `json:"resolveTickets"`
+	ResolutionCategory    string           `json:"resolutionCategory"`
+	ResolutionSubCategory string           `json:"resolutionSubCategory"`
+	RootCause             models.RootCause `json:"rootCause"`
+	Actor                 string           `json:"actor"`
+}
+
+// Validate validates the request.
+func (r *ResolveIncidentRequest) Validate() *errors.Type {
+	if r.IncidentID <= 0 {
+		return errors.InvalidArgument("incidentId.not_valid", "")
+	}
+
+	r.Message = normalize(r.Message)
+	if isBlank(r.Message) {
+		r.Message = ""
+	} else {
+		if e := validateContent(r.Message, limits.CommentContentCharacters); e != nil {
+			return e
+		}
+
+		if _, e := documents.ParseIncidentMessage(r.Message); e != nil {
+			return errors.InvalidArgument("message.not_valid", e.Error())
+		}
+	}
+
+	r.ResolutionCategory = normalize(r.ResolutionCategory)
+	r.ResolutionSubCategory = normalize(r.ResolutionSubCategory)
+
+	if r.ResolutionSubCategory != "" && r.ResolutionCategory == "" {
+		return errors.InvalidArgument("resolutionCategory.is_required", "")
+	}
+
+	if r.RootCause != "" && !r.RootCause.IsValid() {
+		return errors.InvalidArgument("rootCause.not_valid", "")
+	}
+
+	r.Actor = normalize(r.Actor)
+	if isBlank(r.Actor) {
+		return errors.InvalidArgument("actor.is_required", "")
+	}
+
+	if len(r.Actor) > 50 {
+		return errors.InvalidArgument("actor.invalid_length", "")
+	}
+
+	return nil
+}
+
+// Resolution returns back the resolution the tickets get resolved with.
+func (r *ResolveIncidentRequest) Resolution() models.Resolution {
+	return models.Resolution{Category: r.ResolutionCategory, SubCategory: r.ResolutionSubCategory,
+		RootCause: r.RootCause}
+}
+
+// ResolveIncidentResponse model definition. Tickets missing what resolving them requires, or needing an approval to
+// get resolved, are skipped and left for agents to resolve one by one.
+type ResolveIncidentResponse struct {
+	ConsistencyToken
+	ResolvedTicketIDs []int64 `json:"resolvedTicketIds"`
+	SkippedTicketIDs  []int64 `json:"skippedTicketIds"`
+}
+
+// IncidentResponse model definition.
+type IncidentResponse struct {
+	ID          int64   `json:"id"`
+	Title       string  `json:"title"`
+	Description string  `json:"description,omitempty"`
+	ResolvedAt  string  `json:"resolvedAt,omitempty"`
+	TicketIDs   []int64 `json:"ticketIds"`
+	CreatedAt   string  `json:"createdAt"`
+	ModifiedAt  string  `json:"modifiedAt"`
+}
+
+// LoadFromIncident populates the fields of current model from provided incident.
+func (r *IncidentResponse) LoadFromIncident(incident *models.Incident) {
+	r.ID = incident.ID
+	r.Title = incident.Title
+	r.Description = incident.Description
+
+	if incident.ResolvedAt != nil {
+		r.ResolvedAt = incident.ResolvedAt.Format(time.RFC3339Nano)
+	}
+
+	r.TicketIDs = incident.TicketIDs
+	r.CreatedAt = incident.CreatedAt.Format(time.RFC3339Nano)
+	r.ModifiedAt = incident.ModifiedAt.Format(time.RFC3339Nano)
+}
+
+// IncidentsResponse model definition.
+type IncidentsResponse struct {
+	Incidents []*IncidentResponse `json:"incidents"`
+}
+
+// LoadFromIncidents populates the fields of current model from provided incidents.
+func (r *IncidentsResponse) LoadFromIncidents(incidents []*models.Incident) {
+	r.Incidents = make([]*IncidentResponse, 0, len(incidents))
+	for _, incident := range incidents {
+		response := &IncidentResponse{}
+		response.LoadFromIncident(incident)
+		r.Incidents = append(r.Incidents, response)
+	}
+}
@@ -0,0 +1,171 @@
+package data
+
+import (
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// IssuerRequest model definition.
+type IssuerRequest struct {
+	Issuer string `json:"issuer"`
+}
+
+// Validate validates the request.
+func (r *IssuerRequest) Validate() *errors.Type {
+	if isBlank(r.Issuer) {
+		return errors.InvalidArgument("issuer.is_required", "")
+	}
+
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	return nil
+}
+
+// PublicTicketRequest model definition. It looks a ticket of the issuer up by its public id.
+type PublicTicketRequest struct {
+	Issuer   string `json:"issuer"`
+	PublicID string `json:"publicId"`
+}
+
+// Validate validates the request.
+func (r *PublicTicketRequest) Validate() *errors.Type {
+	if isBlank(r.Issuer) {
+		return errors.InvalidArgument("issuer.is_required", "")
+	}
+
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	if isBlank(r.PublicID) {
+		return errors.InvalidArgument("publicId.is_required", "")
+	}
+
+	if len(r.PublicID) > 40 {
+		return errors.InvalidArgument("publicId.invalid_length", "")
+	}
+
+	return nil
+}
+
+// SaveIssuerSettingsRequest model definition.
+type SaveIssuerSettingsRequest struct {
+	Issuer                 string                       `json:"issuer"`
+	DefaultImportanceLevel models.TicketImportanceLevel `json:"defaultImportanceLevel"`
+	DefaultStatus          models.TicketStatus          `json:"defaultStatus"`
+	NotificationMode       models.NotificationMode      `json:"notificationMode"`
+	DigestFrequency        models.DigestFrequency       `json:"digestFrequency"`
+	Tier                   models.CustomerTier          `json:"tier"`
+	// SystemComments is true when omitted.
+	SystemComments *bool `json:"systemComments"`
+	// PublicIDSalt obfuscates the ids of tickets exposed to customers, padded to PublicIDMinLength characters.
+	PublicIDSalt      string `json:"publicIdSalt"`
+	PublicIDMinLength int    `json:"publicIdMinLength"`
+}
+
+// Validate validates the request.
+func (r *SaveIssuerSettingsRequest) Validate() *errors.Type {
+	r.Issuer = normalize(r.Issuer)
+
+	if isBlank(r.Issuer) {
+		return errors.InvalidArgument("issuer.is_required", "")
+	}
+
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	if !r.DefaultImportanceLevel.IsValid() {
+		return errors.InvalidArgument("defaultImportanceLevel.not_valid", "")
+	}
+
+	if !r.DefaultStatus.IsValid() {
+		return errors.InvalidArgument("defaultStatus.not_valid", "")
+	}
+
+	if r.NotificationMode == "" {
+		r.NotificationMode = models.NotificationModeImmediate
+	}
+
+	if !r.NotificationMode.IsValid() {
+		return errors.InvalidArgument("notificationMode.not_valid", "")
+	}
+
+	if r.DigestFrequency == "" {
+		r.DigestFrequency = models.DigestFrequencyDaily
+	}
+
+	if !r.DigestFrequency.IsValid() {
+		return errors.InvalidArgument("digestFrequency.not_valid", "")
+	}
+
+	if r.Tier == "" {
+		r.Tier = models.CustomerTierBronze
+	}
+
+	if !r.Tier.IsValid() {
+		return errors.InvalidArgument("tier.not_valid", "")
+	}
+
+	if r.SystemComments == nil {
+		enabled := true
+		r.SystemComments = &enabled
+	}
+
+	if len(r.PublicIDSalt) > 64 {
+		return errors.InvalidArgument("publicIdSalt.invalid_length", "")
+	}
+
+	if r.PublicIDMinLength < 0 || r.PublicIDMinLength > 20 {
+		return errors.InvalidArgument("publicIdMinLength.not_valid", "")
+	}
+
+	if r.PublicIDMinLength > 0 && r.PublicIDSalt == "" {
+		return errors.InvalidArgument("publicIdSalt.is_required", "")
+	}
+
+	return nil
+}
+
+// AsIssuerSettings converts this request model into issuer settings model.
+func (r *SaveIssuerSettingsRequest) AsIssuerSettings() *models.IssuerSettings {
+	return &models.IssuerSettings{
+		Issuer:                 r.Issuer,
+		DefaultImportanceLevel: r.DefaultImportanceLevel,
+		DefaultStatus:          r.DefaultStatus,
+		NotificationMode:       r.NotificationMode,
+		DigestFrequency:        r.DigestFrequency,
+		Tier:                   r.Tier,
+		SystemComments:         *r.SystemComments,
+		PublicIDSalt:           r.PublicIDSalt,
+		PublicIDMinLength:      r.PublicIDMinLength,
+	}
+}
+
+// IssuerSettingsResponse model definition.
+type IssuerSettingsResponse struct {
+	Issuer                 string                       `json:"issuer"`
+	DefaultImportanceLevel models.TicketImportanceLevel `json:"defaultImportanceLevel"`
+	DefaultStatus          models.TicketStatus          `json:"defaultStatus"`
+	NotificationMode       models.NotificationMode      `json:"notificationMode"`
+	DigestFrequency        models.DigestFrequency       `json:"digestFrequency"`
+	Tier                   models.CustomerTier          `json:"tier"`
+	SystemComments         bool                         `json:"systemComments"`
+	PublicIDSalt           string                       `json:"publicIdSalt,omitempty"`
+	PublicIDMinLength      int                          `json:"publicIdMinLength,omitempty"`
+}
+
+// LoadFromIssuerSettings populates the fields of current model from provided issuer settings.
+func (r *IssuerSettingsResponse) LoadFromIssuerSettings(settings *models.IssuerSettings) {
+	r.Issuer = settings.Issuer
+	r.DefaultImportanceLevel = settings.DefaultImportanceLevel
+	r.DefaultStatus = settings.DefaultStatus
+	r.NotificationMode = settings.NotificationMode
+	r.DigestFrequency = settings.DigestFrequency
+	r.Tier = settings.Tier
+	r.SystemComments = settings.SystemComments
+	r.PublicIDSalt = settings.PublicIDSalt
+	r.PublicIDMinLength = settings.PublicIDMinLength
+}
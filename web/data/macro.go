@@ -0,0 +1,224 @@
+package data
+
+import (
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+	"golang.org/x/text/language"
+)
+
+// CreateMacroRequest model definition. Macros move tickets to the status, if not empty, and post the comment, if not
+// empty, at once. At least one of them must be given. Translations of the comment are keyed by their locales, e.g.
+// fa-IR, the comment is posted when none of them matches the languages of the agent applying the macro.
+type CreateMacroRequest struct {
+	Name         string              `json:"name"`
+	Status       models.TicketStatus `json:"status"`
+	Comment      string              `json:"comment"`
+	Translations map[string]string   `json:"translations"`
+}
+
+// Validate validates the request.
+func (r *CreateMacroRequest) Validate() *errors.Type {
+	r.Name = normalize(r.Name)
+	r.Comment = normalize(r.Comment)
+
+	if e := validateMacro(r.Name, r.Status, r.Comment); e != nil {
+		return e
+	}
+
+	translations, e := validateTranslations(r.Comment, r.Translations)
+	r.Translations = translations
+	return e
+}
+
+// AsMacro converts this request model into macro model. Should be called after Validate.
+func (r *CreateMacroRequest) AsMacro() *models.Macro {
+	return &models.Macro{Name: r.Name, Status: r.Status, Comment: r.Comment, Translations: r.Translations}
+}
+
+// UpdateMacroRequest model definition. It replaces the name, the actions and the translations of the macro.
+type UpdateMacroRequest struct {
+	ID           int64               `json:"id"`
+	Name         string              `json:"name"`
+	Status       models.TicketStatus `json:"status"`
+	Comment      string              `json:"comment"`
+	Translations map[string]string   `json:"translations"`
+}
+
+// Validate validates the request.
+func (r *UpdateMacroRequest) Validate() *errors.Type {
+	if r.ID <= 0 {
+		return errors.InvalidArgument("id.not_valid", "")
+	}
+
+	r.Name = normalize(r.Name)
+	r.Comment = normalize(r.Comment)
+
+	if e := validateMacro(r.Name, r.Status, r.Comment); e != nil {
+		return e
+	}
+
+	translations, e := validateTranslations(r.Comment, r.Translations)
+	r.Translations = translations
+	return e
+}
+
+// AsMacro converts this request model into macro model. Should be called after Validate.
+func (r *UpdateMacroRequest) AsMacro() *models.Macro {
+	return &models.Macro{Model: models.Model{ID: r.ID}, Name: r.Name, Status: r.Status, Comment: r.Comment,
+		Translations: r.Translations}
+}
+
+func validateMacro(name string, status models.TicketStatus, comment string) *errors.Type {
+	if isBlank(name) {
+		return errors.InvalidArgument("name.is_required", "")
+	}
+
+	if len(name) > 100 {
+		return errors.InvalidArgument("name.invalid_length", "")
+	}
+
+	if status != "" && !status.IsValid() {
+		return errors.InvalidArgument("status.not_valid", "")
+	}
+
+	if isBlank(comment) {
+		if status == "" {
+			return errors.InvalidArgument("macro.actions_required", "")
+		}
+
+		return nil
+	}
+
+	return validateContent(comment, limits.CommentContentCharacters)
+}
+
+// validateTranslations validates the translations of the comment and returns them back keyed by their canonical
+// locales, so fa-ir and fa-IR can not both be given.
+func validateTranslations(comment string, translations map[string]string) (map[string]string, *errors.Type) {
+	canonical := make(map[string]string, len(translations))
+	if len(translations) == 0 {
+		return canonical, nil
+	}
+
+	if isBlank(comment) {
+		return nil, errors.InvalidArgument("comment.is_required", "translations need the comment to fall back to")
+	}
+
+	if len(translations) > 10 {
+		return nil, errors.InvalidArgument("translations.invalid_length", "")
+	}
+
+	for locale, translation := range translations {
+		tag, e := language.Parse(locale)
+		if e != nil || len(locale) > 35 {
+			return nil, errors.InvalidArgument("translations.not_valid", "")
+		}
+
+		if _, ok := canonical[tag.String()]; ok {
+			return nil, errors.InvalidArgument("translations.not_valid", "")
+		}
+
+		translation = normalize(translation)
+		if e := validateContent(translation, limits.CommentContentCharacters); e != nil {
+			return nil, e
+		}
+
+		canonical[tag.String()] = translation
+	}
+
+	return canonical, nil
+}
+
+// DeleteMacroRequest model definition.
+type DeleteMacroRequest struct {
+	ID int64 `json:"id"`
+}
+
+// Validate validates the request.
+func (r *DeleteMacroRequest) Validate() *errors.Type {
+	if r.ID <= 0 {
+		return errors.InvalidArgument("id.not_valid", "")
+	}
+
+	return nil
+}
+
+// ApplyMacroRequest model definition. The canned comment of the macro, if any, is posted on behalf of the actor, in the
+// language best matching Languages, an Accept-Language styled list of languages, e.g. fa-IR, en;q=0.8.
+type ApplyMacroRequest struct {
+	TicketID  int64  `json:"ticketId"`
+	MacroID   int64  `json:"macroId"`
+	Actor     string `json:"actor"`
+	Languages string `json:"languages"`
+}
+
+// Validate validates the request.
+func (r *ApplyMacroRequest) Validate() *errors.Type {
+	if r.TicketID <= 0 {
+		return errors.InvalidArgument("ticketId.not_valid", "")
+	}
+
+	if r.MacroID <= 0 {
+		return errors.InvalidArgument("macroId.not_valid", "")
+	}
+
+	r.Actor = normalize(r.Actor)
+	if isBlank(r.Actor) {
+		return errors.InvalidArgument("actor.is_required", "")
+	}
+
+	if len(r.Actor) > 50 {
+		return errors.InvalidArgument("actor.invalid_length", "")
+	}
+
+	if len(r.Languages) > 255 {
+		return errors.InvalidArgument("languages.invalid_length", "")
+	}
+
+	return nil
+}
+
+// MacroResponse model definition.
+type MacroResponse struct {
+	ID           int64               `json:"id"`
+	Name         string              `json:"name"`
+	Status       models.TicketStatus `json:"status,omitempty"`
+	Comment      string              `json:"comment,omitempty"`
+	Translations map[string]string   `json:"translations,omitempty"`
+	CreatedAt    string              `json:"createdAt"`
+	ModifiedAt   string              `json:"modifiedAt"`
+}
+
+// LoadFromMacro populates the fields of current model from provided macro.
+func (r *MacroResponse) LoadFromMacro(macro *models.Macro) {
+	r.ID = macro.ID
+	r.Name = macro.Name
+	r.Status = macro.Status
+	r.Comment = macro.Comment
+	r.Translations = macro.Translations
+	r.CreatedAt = macro.CreatedAt.Format(time.RFC3339Nano)
+	r.ModifiedAt = macro.ModifiedAt.Format(time.RFC3339Nano)
+}
+
+// ListMacrosRequest model definition.
+type ListMacrosRequest struct {
+	PageRequest
+}
+
+// MacrosResponse model definition. NextPageToken is only set when there is a next page.
+type MacrosResponse struct {
+	Macros        []*MacroResponse `json:"macros"`
+	NextPageToken string           `json:"nextPageToken,omitempty"`
+}
+
+// LoadFromMacros populates the fields of current model from provided macros.
+func (r *MacrosResponse) LoadFromMacros(macros []*models.Macro) {
+	r.Macros = make([]*MacroResponse, 0, len(macros))
+	for _, macro := range macros {
+		response := &MacroResponse{}
+		response.LoadFromMacro(macro)
+		r.Macros = append(r.Macros, response)
+	}
+}
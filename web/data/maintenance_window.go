@@ -0,0 +1,93 @@
+package data
+
+import (
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// CreateMaintenanceWindowRequest model definition.
+type CreateMaintenanceWindowRequest struct {
+	Issuer   string `json:"issuer"`
+	StartsAt string `json:"startsAt"`
+	EndsAt   string `json:"endsAt"`
+	Reason   string `json:"reason"`
+}
+
+// Validate validates the request.
+func (r *CreateMaintenanceWindowRequest) Validate() *errors.Type {
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	startsAt, e := time.Parse(time.RFC3339, r.StartsAt)
+	if e != nil {
+		return errors.InvalidArgument("startsAt.not_valid", "")
+	}
+
+	endsAt, e := time.Parse(time.RFC3339, r.EndsAt)
+	if e != nil {
+		return errors.InvalidArgument("endsAt.not_valid", "")
+	}
+
+	if !endsAt.After(startsAt) {
+		return errors.InvalidArgument("endsAt.not_after_startsAt", "")
+	}
+
+	if len(r.Reason) > 255 {
+		return errors.InvalidArgument("reason.invalid_length", "")
+	}
+
+	return nil
+}
+
+// AsMaintenanceWindow converts this request model into maintenance window model. Should be called after Validate.
+func (r *CreateMaintenanceWindowRequest) AsMaintenanceWindow() *models.MaintenanceWindow {
+	startsAt, _ := time.Parse(time.RFC3339, r.StartsAt)
+	endsAt, _ := time.Parse(time.RFC3339, r.EndsAt)
+
+	return &models.MaintenanceWindow{
+		Issuer:   r.Issuer,
+		StartsAt: startsAt,
+		EndsAt:   endsAt,
+		Reason:   r.Reason,
+	}
+}
+
+// MaintenanceWindowResponse model definition.
+type MaintenanceWindowResponse struct {
+	ID         int64  `json:"ID"`
+	Issuer     string `json:"issuer,omitempty"`
+	StartsAt   string `json:"startsAt"`
+	EndsAt     string `json:"endsAt"`
+	Reason     string `json:"reason"`
+	CreatedAt  string `json:"createdAt"`
+	ModifiedAt string `json:"modifiedAt"`
+}
+
+// LoadFromMaintenanceWindow populates the fields of current model from provided maintenance window.
+func (r *MaintenanceWindowResponse) LoadFromMaintenanceWindow(window *models.MaintenanceWindow) {
+	r.ID = window.ID
+	r.Issuer = window.Issuer
+	r.StartsAt = window.StartsAt.Format(time.RFC3339Nano)
+	r.EndsAt = window.EndsAt.Format(time.RFC3339Nano)
+	r.Reason = window.Reason
+	r.CreatedAt = window.CreatedAt.Format(time.RFC3339Nano)
+	r.ModifiedAt = window.ModifiedAt.Format(time.RFC3339Nano)
+}
+
+// MaintenanceWindowsResponse model definition.
+type MaintenanceWindowsResponse struct {
+	MaintenanceWindows []*MaintenanceWindowResponse `json:"maintenanceWindows"`
+}
+
+// LoadFromMaintenanceWindows populates the fields of current model from provided maintenance windows.
+func (r *MaintenanceWindowsResponse) LoadFromMaintenanceWindows(windows []*models.MaintenanceWindow) {
+	r.MaintenanceWindows = make([]*MaintenanceWindowResponse, 0, len(windows))
+	for _, window := range windows {
+		response := &MaintenanceWindowResponse{}
+		response.LoadFromMaintenanceWindow(window)
+		r.MaintenanceWindows = append(r.MaintenanceWindows, response)
+	}
+}
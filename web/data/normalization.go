@@ -0,0 +1,27 @@
+package data
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// invisibles are the zero width characters that carry no meaning in ticket texts. Zero width joiner and non-joiner
+// are deliberately kept: the former glues emoji sequences together and the latter is part of Persian orthography.
+var invisibles = strings.NewReplacer(
+	"\u200b", "", // Zero width space
+	"\u2060", "", // Word joiner
+	"\ufeff", "", // Zero width no-break space (BOM)
+)
+
+// normalize strips invisible characters and converts the text into Unicode normalization form C, so visually equal
+// texts are stored and searched with the same bytes.
+func normalize(text string) string {
+	return norm.NFC.String(invisibles.Replace(text))
+}
+
+// isBlank reports whether the text has nothing but Unicode white spaces, e.g. no-break or ideographic spaces.
+func isBlank(text string) bool {
+	return strings.TrimFunc(text, unicode.IsSpace) == ""
+}
@@ -0,0 +1,76 @@
+package data
+
+import (
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// ListNotificationsRequest model definition.
+type ListNotificationsRequest struct {
+	TicketID int64 `json:"ticketId"`
+}
+
+// Validate validates the request.
+func (r *ListNotificationsRequest) Validate() *errors.Type {
+	if r.TicketID <= 0 {
+		return errors.InvalidArgument("ticketId.not_valid", "")
+	}
+
+	return nil
+}
+
+// ReportBounceRequest model definition. Used by bounce processors to report asynchronously bounced notifications.
+type ReportBounceRequest struct {
+	ID     int64  `json:"ID"`
+	Reason string `json:"reason"`
+}
+
+// Validate validates the request.
+func (r *ReportBounceRequest) Validate() *errors.Type {
+	if r.ID <= 0 {
+		return errors.InvalidArgument("ID.not_valid", "")
+	}
+
+	return nil
+}
+
+// NotificationResponse model definition.
+type NotificationResponse struct {
+	ID         int64                     `json:"ID"`
+	TicketID   int64                     `json:"ticketId"`
+	Recipient  string                    `json:"recipient"`
+	Subject    string                    `json:"subject"`
+	Status     models.NotificationStatus `json:"status"`
+	Failure    string                    `json:"failure,omitempty"`
+	CreatedAt  string                    `json:"createdAt"`
+	ModifiedAt string                    `json:"modifiedAt"`
+}
+
+// LoadFromNotification populates the fields of current model from provided notification.
+func (r *NotificationResponse) LoadFromNotification(notification *models.Notification) {
+	r.ID = notification.ID
+	r.TicketID = notification.TicketID
+	r.Recipient = notification.Recipient
+	r.Subject = notification.Subject
+	r.Status = notification.Status
+	r.Failure = notification.Failure
+	r.CreatedAt = notification.CreatedAt.Format(time.RFC3339Nano)
+	r.ModifiedAt = notification.ModifiedAt.Format(time.RFC3339Nano)
+}
+
+// NotificationsResponse model definition.
+type NotificationsResponse struct {
+	Notifications []*NotificationResponse `json:"notifications"`
+}
+
+// LoadFromNotifications populates the fields of current model from provided notifications.
+func (r *NotificationsResponse) LoadFromNotifications(notifications []*models.Notification) {
+	r.Notifications = make([]*NotificationResponse, 0, len(notifications))
+	for _, notification := range notifications {
+		response := &NotificationResponse{}
+		response.LoadFromNotification(notification)
+		r.Notifications = append(r.Notifications, response)
+	}
+}
@@ -0,0 +1,137 @@
+package data
+
+import (
+	"net/mail"
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// RecipientRequest model definition.
+type RecipientRequest struct {
+	Recipient string `json:"recipient"`
+}
+
+// Validate validates the request.
+func (r *RecipientRequest) Validate() *errors.Type {
+	if isBlank(r.Recipient) {
+		return errors.InvalidArgument("recipient.is_required", "")
+	}
+
+	if len(r.Recipient) > 255 {
+		return errors.InvalidArgument("recipient.invalid_length", "")
+	}
+
+	return nil
+}
+
+// SaveNotificationPreferencesRequest model definition.
+type SaveNotificationPreferencesRequest struct {
+	Recipient       string                       `json:"recipient"`
+	Channels        []models.NotificationChannel `json:"channels"`
+	Mode            models.NotificationMode      `json:"mode"`
+	DigestFrequency models.DigestFrequency       `json:"digestFrequency"`
+	QuietHoursStart string                       `json:"quietHoursStart"`
+	QuietHoursEnd   string                       `json:"quietHoursEnd"`
+	TimeZone        string                       `json:"timeZone"`
+}
+
+// Validate validates the request.
+func (r *SaveNotificationPreferencesRequest) Validate() *errors.Type {
+	if _, e := mail.ParseAddress(r.Recipient); e != nil {
+		return errors.InvalidArgument("recipient.not_valid", "")
+	}
+
+	if len(r.Recipient) > 255 {
+		return errors.InvalidArgument("recipient.invalid_length", "")
+	}
+
+	// An empty list of channels is valid and means the recipient opted out of all notifications.
+	for _, channel := range r.Channels {
+		if !channel.IsValid() {
+			return errors.InvalidArgument("channels.not_valid", "")
+		}
+	}
+
+	if r.Mode == "" {
+		r.Mode = models.NotificationModeImmediate
+	}
+
+	if !r.Mode.IsValid() {
+		return errors.InvalidArgument("mode.not_valid", "")
+	}
+
+	if r.DigestFrequency == "" {
+		r.DigestFrequency = models.DigestFrequencyDaily
+	}
+
+	if !r.DigestFrequency.IsValid() {
+		return errors.InvalidArgument("digestFrequency.not_valid", "")
+	}
+
+	if (r.QuietHoursStart == "") != (r.QuietHoursEnd == "") {
+		return errors.InvalidArgument("quietHours.incomplete", "")
+	}
+
+	if r.QuietHoursStart != "" {
+		if !isClock(r.QuietHoursStart) {
+			return errors.InvalidArgument("quietHoursStart.not_valid", "")
+		}
+
+		if !isClock(r.QuietHoursEnd) {
+			return errors.InvalidArgument("quietHoursEnd.not_valid", "")
+		}
+	}
+
+	if r.TimeZone == "" {
+		r.TimeZone = "UTC"
+	}
+
+	if _, e := time.LoadLocation(r.TimeZone); e != nil || len(r.TimeZone) > 50 {
+		return errors.InvalidArgument("timeZone.not_valid", "")
+	}
+
+	return nil
+}
+
+// isClock checks whether the value is a zero padded HH:MM clock time, so clock times compare correctly as strings.
+func isClock(value string) bool {
+	t, e := time.Parse("15:04", value)
+	return e == nil && t.Format("15:04") == value
+}
+
+// AsNotificationPreferences converts this request model into notification preferences model.
+func (r *SaveNotificationPreferencesRequest) AsNotificationPreferences() *models.NotificationPreferences {
+	return &models.NotificationPreferences{
+		Recipient:       r.Recipient,
+		Channels:        r.Channels,
+		Mode:            r.Mode,
+		DigestFrequency: r.DigestFrequency,
+		QuietHoursStart: r.QuietHoursStart,
+		QuietHoursEnd:   r.QuietHoursEnd,
+		TimeZone:        r.TimeZone,
+	}
+}
+
+// NotificationPreferencesResponse model definition.
+type NotificationPreferencesResponse struct {
+	Recipient       string                       `json:"recipient"`
+	Channels        []models.NotificationChannel `json:"channels"`
+	Mode            models.NotificationMode      `json:"mode"`
+	DigestFrequency models.DigestFrequency       `json:"digestFrequency"`
+	QuietHoursStart string                       `json:"quietHoursStart,omitempty"`
+	QuietHoursEnd   string                       `json:"quietHoursEnd,omitempty"`
+	TimeZone        string                       `json:"timeZone"`
+}
+
+// LoadFromNotificationPreferences populates the fields of current model from provided notification preferences.
+func (r *NotificationPreferencesResponse) LoadFromNotificationPreferences(preferences *models.NotificationPreferences) {
+	r.Recipient = preferences.Recipient
+	r.Channels = preferences.Channels
+	r.Mode = preferences.Mode
+	r.DigestFrequency = preferences.DigestFrequency
+	r.QuietHoursStart = preferences.QuietHoursStart
+	r.QuietHoursEnd = preferences.QuietHoursEnd
+	r.TimeZone = preferences.TimeZone
+}
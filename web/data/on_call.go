@@ -0,0 +1,124 @@
+package data
+
+import (
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// CreateOnCallShiftRequest model definition.
+type CreateOnCallShiftRequest struct {
+	Issuer   string `json:"issuer"`
+	Agent    string `json:"agent"`
+	StartsAt string `json:"startsAt"`
+	EndsAt   string `json:"endsAt"`
+}
+
+// Validate validates the request.
+func (r *CreateOnCallShiftRequest) Validate() *errors.Type {
+	r.Issuer = normalize(r.Issuer)
+	if isBlank(r.Issuer) {
+		return errors.InvalidArgument("issuer.is_required", "")
+	}
+
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	r.Agent = normalize(r.Agent)
+	if isBlank(r.Agent) {
+		return errors.InvalidArgument("agent.is_required", "")
+	}
+
+	if len(r.Agent) > 50 {
+		return errors.InvalidArgument("agent.invalid_length", "")
+	}
+
+	startsAt, e := time.Parse(time.RFC3339, r.StartsAt)
+	if e != nil {
+		return errors.InvalidArgument("startsAt.not_valid", "")
+	}
+
+	endsAt, e := time.Parse(time.RFC3339, r.EndsAt)
+	if e != nil {
+		return errors.InvalidArgument("endsAt.not_valid", "")
+	}
+
+	if !endsAt.After(startsAt) {
+		return errors.InvalidArgument("endsAt.not_after_startsAt", "")
+	}
+
+	return nil
+}
+
+// AsOnCallShift converts this request model into on-call shift model. Should be called after Validate.
+func (r *CreateOnCallShiftRequest) AsOnCallShift() *models.OnCallShift {
+	startsAt, _ := time.Parse(time.RFC3339, r.StartsAt)
+	endsAt, _ := time.Parse(time.RFC3339, r.EndsAt)
+
+	return &models.OnCallShift{
+		Issuer:   r.Issuer,
+		Agent:    r.Agent,
+		StartsAt: startsAt,
+		EndsAt:   endsAt,
+	}
+}
+
+// DeleteOnCallShiftRequest model definition.
+type DeleteOnCallShiftRequest struct {
+	Issuer string `json:"issuer"`
+	ID     int64  `json:"ID"`
+}
+
+// Validate validates the request.
+func (r *DeleteOnCallShiftRequest) Validate() *errors.Type {
+	if isBlank(r.Issuer) {
+		return errors.InvalidArgument("issuer.is_required", "")
+	}
+
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	if r.ID <= 0 {
+		return errors.InvalidArgument("ID.invalid", "")
+	}
+
+	return nil
+}
+
+// OnCallShiftResponse model definition.
+type OnCallShiftResponse struct {
+	ID        int64  `json:"ID"`
+	Issuer    string `json:"issuer"`
+	Agent     string `json:"agent"`
+	StartsAt  string `json:"startsAt"`
+	EndsAt    string `json:"endsAt"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// LoadFromOnCallShift populates the fields of current model from provided on-call shift.
+func (r *OnCallShiftResponse) LoadFromOnCallShift(shift *models.OnCallShift) {
+	r.ID = shift.ID
+	r.Issuer = shift.Issuer
+	r.Agent = shift.Agent
+	r.StartsAt = shift.StartsAt.Format(time.RFC3339Nano)
+	r.EndsAt = shift.EndsAt.Format(time.RFC3339Nano)
+	r.CreatedAt = shift.CreatedAt.Format(time.RFC3339Nano)
+}
+
+// OnCallShiftsResponse model definition.
+type OnCallShiftsResponse struct {
+	Shifts []*OnCallShiftResponse `json:"shifts"`
+}
+
+// LoadFromOnCallShifts populates the fields of current model from provided on-call shifts.
+func (r *OnCallShiftsResponse) LoadFromOnCallShifts(shifts []*models.OnCallShift) {
+	r.Shifts = make([]*OnCallShiftResponse, 0, len(shifts))
+	for _, shift := range shifts {
+		response := &OnCallShiftResponse{}
+		response.LoadFromOnCallShift(shift)
+		r.Shifts = append(r.Shifts, response)
+	}
+}
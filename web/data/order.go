@@ -0,0 +1,47 @@
+package data
+
+import (
+	"strings"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// maxOrderKeys is the most keys a list can be ordered by.
+const maxOrderKeys = 3
+
+// parseOrderBy parses the order_by value of list requests, comma separated keys of a field and an optional direction,
+// e.g. createdAt:desc,id. Fields must be whitelisted by columns and keys are ascending unless stated otherwise. An
+// empty value leaves the default order of the list in place.
+func parseOrderBy(orderBy string, columns map[string]string) ([]models.Order, *errors.Type) {
+	if strings.TrimSpace(orderBy) == "" {
+		return nil, nil
+	}
+
+	keys := strings.Split(orderBy, ",")
+	if len(keys) > maxOrderKeys {
+		return nil, errors.InvalidArgument("orderBy.too_many_keys", "")
+	}
+
+	orders := make([]models.Order, 0, len(keys))
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		field, direction := strings.TrimSpace(key), ""
+		if i := strings.Index(field, ":"); i >= 0 {
+			field, direction = strings.TrimSpace(field[:i]), strings.ToLower(strings.TrimSpace(field[i+1:]))
+		}
+
+		if _, ok := columns[field]; !ok || seen[field] {
+			return nil, errors.InvalidArgument("orderBy.field_not_valid", "")
+		}
+
+		if direction != "" && direction != "asc" && direction != "desc" {
+			return nil, errors.InvalidArgument("orderBy.direction_not_valid", "")
+		}
+
+		seen[field] = true
+		orders = append(orders, models.Order{Field: field, Descending: direction == "desc"})
+	}
+
+	return orders, nil
+}
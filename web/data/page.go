@@ -0,0 +1,24 @@
+package data
+
+import "github.com/jibitters/kiosk/errors"
+
+// PageRequest model definition. Lists reply back up to PageSize records, all of them when it is zero, along with the
+// token of the next page when there is one. Passing it back as PageToken continues right after the last record of the
+// page, so paging stays stable while records get created and updated.
+type PageRequest struct {
+	PageSize  int    `json:"pageSize,omitempty"`
+	PageToken string `json:"pageToken,omitempty"`
+}
+
+// Validate validates the request.
+func (r *PageRequest) Validate() *errors.Type {
+	if r.PageSize < 0 || r.PageSize > 100 {
+		return errors.InvalidArgument("pageSize.not_valid", "")
+	}
+
+	if len(r.PageToken) > 1024 {
+		return errors.InvalidArgument("pageToken.invalid_length", "")
+	}
+
+	return nil
+}
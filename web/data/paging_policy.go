@@ -0,0 +1,115 @@
+package data
+
+import (
+	"strings"
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// SavePagingPolicyRequest model definition.
+type SavePagingPolicyRequest struct {
+	Issuer             string                `json:"issuer"`
+	Provider           models.PagingProvider `json:"provider"`
+	RoutingKey         string                `json:"routingKey"`
+	BusinessHoursStart string                `json:"businessHoursStart"`
+	BusinessHoursEnd   string                `json:"businessHoursEnd"`
+	// BusinessDays are days of week, Sunday is 0. Defaults to Monday through Friday.
+	BusinessDays []time.Weekday `json:"businessDays"`
+	TimeZone     string         `json:"timeZone"`
+}
+
+// Validate validates the request.
+func (r *SavePagingPolicyRequest) Validate() *errors.Type {
+	r.Issuer = normalize(r.Issuer)
+	r.RoutingKey = strings.TrimSpace(r.RoutingKey)
+
+	if isBlank(r.Issuer) {
+		return errors.InvalidArgument("issuer.is_required", "")
+	}
+
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	if !r.Provider.IsValid() {
+		return errors.InvalidArgument("provider.not_valid", "")
+	}
+
+	if r.RoutingKey == "" || len(r.RoutingKey) > 255 {
+		return errors.InvalidArgument("routingKey.invalid_length", "")
+	}
+
+	if !isClock(r.BusinessHoursStart) {
+		return errors.InvalidArgument("businessHoursStart.not_valid", "")
+	}
+
+	if !isClock(r.BusinessHoursEnd) || r.BusinessHoursEnd == r.BusinessHoursStart {
+		return errors.InvalidArgument("businessHoursEnd.not_valid", "")
+	}
+
+	if r.BusinessDays == nil {
+		r.BusinessDays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	}
+
+	for _, day := range r.BusinessDays {
+		if day < time.Sunday || day > time.Saturday {
+			return errors.InvalidArgument("businessDays.not_valid", "")
+		}
+	}
+
+	if r.TimeZone == "" {
+		r.TimeZone = "UTC"
+	}
+
+	if _, e := time.LoadLocation(r.TimeZone); e != nil || len(r.TimeZone) > 50 {
+		return errors.InvalidArgument("timeZone.not_valid", "")
+	}
+
+	return nil
+}
+
+// AsPagingPolicy converts this request model into paging policy model.
+func (r *SavePagingPolicyRequest) AsPagingPolicy() *models.PagingPolicy {
+	return &models.PagingPolicy{
+		Issuer:             r.Issuer,
+		Provider:           r.Provider,
+		RoutingKey:         r.RoutingKey,
+		BusinessHoursStart: r.BusinessHoursStart,
+		BusinessHoursEnd:   r.BusinessHoursEnd,
+		BusinessDays:       r.BusinessDays,
+		TimeZone:           r.TimeZone,
+	}
+}
+
+// PagingPolicyResponse model definition. The routing key is a credential, so only its last characters are returned.
+type PagingPolicyResponse struct {
+	Issuer             string                `json:"issuer"`
+	Provider           models.PagingProvider `json:"provider"`
+	RoutingKey         string                `json:"routingKey"`
+	BusinessHoursStart string                `json:"businessHoursStart"`
+	BusinessHoursEnd   string                `json:"businessHoursEnd"`
+	BusinessDays       []time.Weekday        `json:"businessDays"`
+	TimeZone           string                `json:"timeZone"`
+}
+
+// LoadFromPagingPolicy populates the fields of current model from provided paging policy.
+func (r *PagingPolicyResponse) LoadFromPagingPolicy(policy *models.PagingPolicy) {
+	r.Issuer = policy.Issuer
+	r.Provider = policy.Provider
+	r.RoutingKey = mask(policy.RoutingKey)
+	r.BusinessHoursStart = policy.BusinessHoursStart
+	r.BusinessHoursEnd = policy.BusinessHoursEnd
+	r.BusinessDays = policy.BusinessDays
+	r.TimeZone = policy.TimeZone
+}
+
+// mask hides all but the last four characters of a secret.
+func mask(secret string) string {
+	if len(secret) <= 4 {
+		return strings.Repeat("*", len(secret))
+	}
+
+	return strings.Repeat("*", len(secret)-4) + secret[len(secret)-4:]
+}
@@ -0,0 +1,59 @@
+package data
+
+import (
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+)
+
+// DefaultReindexBatchSize is the number of tickets reindexed at once when the request does not specify one.
+const DefaultReindexBatchSize = 500
+
+// ReindexTicketsRequest model definition.
+type ReindexTicketsRequest struct {
+	Issuer    string `json:"issuer"`
+	FromDate  string `json:"fromDate"`
+	ToDate    string `json:"toDate"`
+	BatchSize int    `json:"batchSize"`
+}
+
+// Validate validates the request.
+func (r *ReindexTicketsRequest) Validate() *errors.Type {
+	r.Issuer = normalize(r.Issuer)
+
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	if r.FromDate == "" {
+		r.FromDate = "2000-01-01T00:00:00Z"
+	}
+
+	if r.ToDate == "" {
+		r.ToDate = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+
+	if r.BatchSize == 0 {
+		r.BatchSize = DefaultReindexBatchSize
+	}
+
+	if r.BatchSize < 1 || r.BatchSize > 5000 {
+		return errors.InvalidArgument("batchSize.not_valid", "")
+	}
+
+	return nil
+}
+
+// ReindexTicketsResponse model definition.
+type ReindexTicketsResponse struct {
+	Total int64 `json:"total"`
+}
+
+// ReindexProgress model definition. Published after each reindexed batch, the last one being either done or failed.
+type ReindexProgress struct {
+	Total     int64  `json:"total"`
+	Processed int64  `json:"processed"`
+	LastID    int64  `json:"lastId"`
+	Done      bool   `json:"done"`
+	Failure   string `json:"failure,omitempty"`
+}
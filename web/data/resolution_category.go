@@ -0,0 +1,83 @@
+package data
+
+import (
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// SaveResolutionCategoryRequest model definition.
+type SaveResolutionCategoryRequest struct {
+	Name          string   `json:"name"`
+	SubCategories []string `json:"subCategories"`
+}
+
+// Validate validates the request.
+func (r *SaveResolutionCategoryRequest) Validate() *errors.Type {
+	r.Name = normalize(r.Name)
+
+	if isBlank(r.Name) {
+		return errors.InvalidArgument("name.is_required", "")
+	}
+
+	if len(r.Name) > 50 {
+		return errors.InvalidArgument("name.invalid_length", "")
+	}
+
+	for i, subCategory := range r.SubCategories {
+		r.SubCategories[i] = normalize(subCategory)
+
+		if isBlank(r.SubCategories[i]) || len(r.SubCategories[i]) > 50 {
+			return errors.InvalidArgument("subCategory.invalid_length", "")
+		}
+	}
+
+	return nil
+}
+
+// AsResolutionCategory converts this request model into resolution category model.
+func (r *SaveResolutionCategoryRequest) AsResolutionCategory() *models.ResolutionCategory {
+	subCategories := r.SubCategories
+	if subCategories == nil {
+		subCategories = make([]string, 0)
+	}
+
+	return &models.ResolutionCategory{Name: r.Name, SubCategories: subCategories}
+}
+
+// ResolutionCategoryRequest model definition.
+type ResolutionCategoryRequest struct {
+	Name string `json:"name"`
+}
+
+// Validate validates the request.
+func (r *ResolutionCategoryRequest) Validate() *errors.Type {
+	if isBlank(r.Name) {
+		return errors.InvalidArgument("name.is_required", "")
+	}
+
+	if len(r.Name) > 50 {
+		return errors.InvalidArgument("name.invalid_length", "")
+	}
+
+	return nil
+}
+
+// ResolutionCategoryResponse model definition.
+type ResolutionCategoryResponse struct {
+	Name          string   `json:"name"`
+	SubCategories []string `json:"subCategories"`
+}
+
+// ResolutionCategoriesResponse model definition.
+type ResolutionCategoriesResponse struct {
+	Categories []*ResolutionCategoryResponse `json:"categories"`
+}
+
+// LoadFromResolutionCategories populates the fields of current model from provided resolution categories.
+func (r *ResolutionCategoriesResponse) LoadFromResolutionCategories(categories []*models.ResolutionCategory) {
+	r.Categories = make([]*ResolutionCategoryResponse, 0, len(categories))
+	for _, category := range categories {
+		r.Categories = append(r.Categories, &ResolutionCategoryResponse{Name: category.Name,
+			SubCategories: category.SubCategories})
+	}
+}
@@ -0,0 +1,58 @@
+package data
+
+import (
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// ResolutionReportRequest model definition.
+type ResolutionReportRequest struct {
+	Issuer   string `json:"issuer"`
+	FromDate string `json:"fromDate"`
+	ToDate   string `json:"toDate"`
+}
+
+// Validate validates the request.
+func (r *ResolutionReportRequest) Validate() *errors.Type {
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	if r.FromDate == "" {
+		r.FromDate = time.Now().UTC().AddDate(0, 0, -30).Format(time.RFC3339Nano)
+	}
+
+	if r.ToDate == "" {
+		r.ToDate = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+
+	return nil
+}
+
+// ResolutionReportResponse model definition.
+type ResolutionReportResponse struct {
+	Rows []*ResolutionReportRowResponse `json:"rows"`
+}
+
+// ResolutionReportRowResponse model definition.
+type ResolutionReportRowResponse struct {
+	Category    string           `json:"category"`
+	SubCategory string           `json:"subCategory"`
+	RootCause   models.RootCause `json:"rootCause"`
+	Tickets     int64            `json:"tickets"`
+}
+
+// LoadFromResolutionRows populates the fields of current model from provided resolution rows.
+func (r *ResolutionReportResponse) LoadFromResolutionRows(rows []*models.ResolutionRow) {
+	r.Rows = make([]*ResolutionReportRowResponse, 0, len(rows))
+	for _, row := range rows {
+		r.Rows = append(r.Rows, &ResolutionReportRowResponse{
+			Category:    row.Category,
+			SubCategory: row.SubCategory,
+			RootCause:   row.RootCause,
+			Tickets:     row.Tickets,
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package data
+
+import (
+	"encoding/json"
+	"regexp"
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// runtimeEntryNamePattern is the form of runtime entry names, they appear in URLs.
+var runtimeEntryNamePattern = regexp.MustCompile(`^[a-z0-9_.-]{1,100}$`)
+
+// maxRuntimeEntryValueLength bounds the size of runtime entry values, as all of them are kept in memory.
+const maxRuntimeEntryValueLength = 64 * 1024
+
+// SaveRuntimeEntryRequest model definition.
+type SaveRuntimeEntryRequest struct {
+	Kind  models.RuntimeEntryKind `json:"kind"`
+	Name  string                  `json:"name"`
+	Value json.RawMessage         `json:"value"`
+	// Version is the current version of the entry being edited, zero overwrites whatever is saved.
+	Version int64 `json:"version"`
+}
+
+// Validate validates the request.
+func (r *SaveRuntimeEntryRequest) Validate() *errors.Type {
+	identifier := &RuntimeEntryRequest{Kind: r.Kind, Name: r.Name}
+	if e := identifier.Validate(); e != nil {
+		return e
+	}
+
+	if len(r.Value) == 0 || !json.Valid(r.Value) {
+		return errors.InvalidArgument("value.not_valid", "")
+	}
+
+	if len(r.Value) > maxRuntimeEntryValueLength {
+		return errors.InvalidArgument("value.invalid_length", "")
+	}
+
+	if r.Kind == models.RuntimeEntryKindFeatureFlag {
+		var enabled bool
+		if e := json.Unmarshal(r.Value, &enabled); e != nil {
+			return errors.InvalidArgument("value.not_valid", "")
+		}
+	}
+
+	if r.Version < 0 {
+		return errors.InvalidArgument("version.not_valid", "")
+	}
+
+	return nil
+}
+
+// AsRuntimeEntry converts this request model into runtime entry model. Should be called after Validate.
+func (r *SaveRuntimeEntryRequest) AsRuntimeEntry() *models.RuntimeEntry {
+	return &models.RuntimeEntry{Kind: r.Kind, Name: r.Name, Value: string(r.Value), Version: r.Version}
+}
+
+// RuntimeEntryRequest model definition, it identifies a runtime entry.
+type RuntimeEntryRequest struct {
+	Kind models.RuntimeEntryKind `json:"kind"`
+	Name string                  `json:"name"`
+}
+
+// Validate validates the request.
+func (r *RuntimeEntryRequest) Validate() *errors.Type {
+	if !r.Kind.IsValid() {
+		return errors.InvalidArgument("kind.not_valid", "")
+	}
+
+	if !runtimeEntryNamePattern.MatchString(r.Name) {
+		return errors.InvalidArgument("name.not_valid", "")
+	}
+
+	return nil
+}
+
+// ListRuntimeEntriesRequest model definition, an empty kind lists the entries of all kinds.
+type ListRuntimeEntriesRequest struct {
+	Kind models.RuntimeEntryKind `json:"kind"`
+}
+
+// Validate validates the request.
+func (r *ListRuntimeEntriesRequest) Validate() *errors.Type {
+	if r.Kind != "" && !r.Kind.IsValid() {
+		return errors.InvalidArgument("kind.not_valid", "")
+	}
+
+	return nil
+}
+
+// RuntimeEntryResponse model definition.
+type RuntimeEntryResponse struct {
+	Kind       models.RuntimeEntryKind `json:"kind"`
+	Name       string                  `json:"name"`
+	Value      json.RawMessage         `json:"value"`
+	Version    int64                   `json:"version"`
+	ModifiedAt string                  `json:"modifiedAt"`
+}
+
+// LoadFromRuntimeEntry populates the fields of current model from provided entry.
+func (r *RuntimeEntryResponse) LoadFromRuntimeEntry(entry *models.RuntimeEntry) {
+	r.Kind = entry.Kind
+	r.Name = entry.Name
+	r.Value = json.RawMessage(entry.Value)
+	r.Version = entry.Version
+	r.ModifiedAt = entry.ModifiedAt.Format(time.RFC3339Nano)
+}
+
+// RuntimeEntriesResponse model definition.
+type RuntimeEntriesResponse struct {
+	RuntimeEntries []RuntimeEntryResponse `json:"runtimeEntries"`
+}
+
+// LoadFromRuntimeEntries populates the fields of current model from provided entries.
+func (r *RuntimeEntriesResponse) LoadFromRuntimeEntries(entries []*models.RuntimeEntry) {
+	r.RuntimeEntries = make([]RuntimeEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		response := RuntimeEntryResponse{}
+		response.LoadFromRuntimeEntry(entry)
+		r.RuntimeEntries = append(r.RuntimeEntries, response)
+	}
+}
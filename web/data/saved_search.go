@@ -0,0 +1,143 @@
+package data
+
+import (
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// CreateSavedSearchRequest model definition. The agent gets notified at its email address about every new ticket
+// matching the search, so at least one of query, issuer and importance level must be given.
+type CreateSavedSearchRequest struct {
+	Agent           string                       `json:"agent"`
+	Name            string                       `json:"name"`
+	Query           string                       `json:"query"`
+	Issuer          string                       `json:"issuer"`
+	ImportanceLevel models.TicketImportanceLevel `json:"importanceLevel"`
+}
+
+// Validate validates the request.
+func (r *CreateSavedSearchRequest) Validate() *errors.Type {
+	r.Name = normalize(r.Name)
+	// Queries are matched as a whole, surrounding spaces would only make them miss.
+	r.Query = strings.TrimSpace(normalize(r.Query))
+	r.Issuer = normalize(r.Issuer)
+
+	if _, e := mail.ParseAddress(r.Agent); e != nil || len(r.Agent) > 255 {
+		return errors.InvalidArgument("agent.not_valid", "")
+	}
+
+	if isBlank(r.Name) {
+		return errors.InvalidArgument("name.is_required", "")
+	}
+
+	if len(r.Name) > 100 {
+		return errors.InvalidArgument("name.invalid_length", "")
+	}
+
+	if len(r.Query) > 255 {
+		return errors.InvalidArgument("query.invalid_length", "")
+	}
+
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	if r.ImportanceLevel != "" && !r.ImportanceLevel.IsValid() {
+		return errors.InvalidArgument("importanceLevel.not_valid", "")
+	}
+
+	if isBlank(r.Query) && isBlank(r.Issuer) && r.ImportanceLevel == "" {
+		return errors.InvalidArgument("savedSearch.criteria_required", "")
+	}
+
+	return nil
+}
+
+// AsSavedSearch converts this request model into saved search model. Should be called after Validate.
+func (r *CreateSavedSearchRequest) AsSavedSearch() *models.SavedSearch {
+	return &models.SavedSearch{
+		Agent:           r.Agent,
+		Name:            r.Name,
+		Query:           r.Query,
+		Issuer:          r.Issuer,
+		ImportanceLevel: r.ImportanceLevel,
+	}
+}
+
+// SavedSearchesRequest model definition.
+type SavedSearchesRequest struct {
+	Agent string `json:"agent"`
+}
+
+// Validate validates the request.
+func (r *SavedSearchesRequest) Validate() *errors.Type {
+	if isBlank(r.Agent) {
+		return errors.InvalidArgument("agent.is_required", "")
+	}
+
+	if len(r.Agent) > 255 {
+		return errors.InvalidArgument("agent.invalid_length", "")
+	}
+
+	return nil
+}
+
+// DeleteSavedSearchRequest model definition. Agents may only delete their own saved searches.
+type DeleteSavedSearchRequest struct {
+	ID    int64  `json:"id"`
+	Agent string `json:"agent"`
+}
+
+// Validate validates the request.
+func (r *DeleteSavedSearchRequest) Validate() *errors.Type {
+	if r.ID <= 0 {
+		return errors.InvalidArgument("id.not_valid", "")
+	}
+
+	if isBlank(r.Agent) {
+		return errors.InvalidArgument("agent.is_required", "")
+	}
+
+	return nil
+}
+
+// SavedSearchResponse model definition.
+type SavedSearchResponse struct {
+	ID              int64                        `json:"id"`
+	Agent           string                       `json:"agent"`
+	Name            string                       `json:"name"`
+	Query           string                       `json:"query,omitempty"`
+	Issuer          string                       `json:"issuer,omitempty"`
+	ImportanceLevel models.TicketImportanceLevel `json:"importanceLevel,omitempty"`
+	CreatedAt       string                       `json:"createdAt"`
+}
+
+// LoadFromSavedSearch populates the fields of current model from provided saved search.
+func (r *SavedSearchResponse) LoadFromSavedSearch(search *models.SavedSearch) {
+	r.ID = search.ID
+	r.Agent = search.Agent
+	r.Name = search.Name
+	r.Query = search.Query
+	r.Issuer = search.Issuer
+	r.ImportanceLevel = search.ImportanceLevel
+	r.CreatedAt = search.CreatedAt.Format(time.RFC3339Nano)
+}
+
+// SavedSearchesResponse model definition.
+type SavedSearchesResponse struct {
+	SavedSearches []*SavedSearchResponse `json:"savedSearches"`
+}
+
+// LoadFromSavedSearches populates the fields of current model from provided saved searches.
+func (r *SavedSearchesResponse) LoadFromSavedSearches(searches []*models.SavedSearch) {
+	r.SavedSearches = make([]*SavedSearchResponse, 0, len(searches))
+	for _, search := range searches {
+		response := &SavedSearchResponse{}
+		response.LoadFromSavedSearch(search)
+		r.SavedSearches = append(r.SavedSearches, response)
+	}
+}
@@ -0,0 +1,50 @@
+package data
+
+import (
+	"time"
+
+	"github.com/jibitters/kiosk/models"
+)
+
+// ScheduledReportResponse model definition.
+type ScheduledReportResponse struct {
+	ID         int64               `json:"ID"`
+	Name       string              `json:"name"`
+	Cron       string              `json:"cron"`
+	Report     models.ReportType   `json:"report"`
+	Issuer     string              `json:"issuer,omitempty"`
+	PeriodDays int                 `json:"periodDays"`
+	Format     models.ReportFormat `json:"format"`
+	Recipients []string            `json:"recipients"`
+	CreatedAt  string              `json:"createdAt"`
+	ModifiedAt string              `json:"modifiedAt"`
+}
+
+// LoadFromScheduledReport populates the fields of current model from provided scheduled report.
+func (r *ScheduledReportResponse) LoadFromScheduledReport(report *models.ScheduledReport) {
+	r.ID = report.ID
+	r.Name = report.Name
+	r.Cron = report.Cron
+	r.Report = report.Report
+	r.Issuer = report.Issuer
+	r.PeriodDays = report.PeriodDays
+	r.Format = report.Format
+	r.Recipients = report.Recipients
+	r.CreatedAt = report.CreatedAt.Format(time.RFC3339Nano)
+	r.ModifiedAt = report.ModifiedAt.Format(time.RFC3339Nano)
+}
+
+// ScheduledReportsResponse model definition.
+type ScheduledReportsResponse struct {
+	ScheduledReports []*ScheduledReportResponse `json:"scheduledReports"`
+}
+
+// LoadFromScheduledReports populates the fields of current model from provided scheduled reports.
+func (r *ScheduledReportsResponse) LoadFromScheduledReports(reports []*models.ScheduledReport) {
+	r.ScheduledReports = make([]*ScheduledReportResponse, 0, len(reports))
+	for _, report := range reports {
+		response := &ScheduledReportResponse{}
+		response.LoadFromScheduledReport(report)
+		r.ScheduledReports = append(r.ScheduledReports, response)
+	}
+}
@@ -0,0 +1,93 @@
+package data
+
+import (
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// Default thresholds of frustrated tickets, a ticket is frustrated once its sentiment score or trend drops to them.
+const (
+	DefaultFrustrationMaxScore = -0.5
+	DefaultFrustrationMaxTrend = -0.3
+)
+
+// FrustratedTicketsRequest model definition. Thresholds are optional and defaulted when omitted.
+type FrustratedTicketsRequest struct {
+	Issuer   string   `json:"issuer"`
+	MaxScore *float64 `json:"maxScore"`
+	MaxTrend *float64 `json:"maxTrend"`
+	Limit    int      `json:"limit"`
+}
+
+// Validate validates the request.
+func (r *FrustratedTicketsRequest) Validate() *errors.Type {
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	if r.MaxScore == nil {
+		maxScore := DefaultFrustrationMaxScore
+		r.MaxScore = &maxScore
+	}
+
+	if *r.MaxScore < -1 || *r.MaxScore > 1 {
+		return errors.InvalidArgument("maxScore.not_valid", "")
+	}
+
+	if r.MaxTrend == nil {
+		maxTrend := DefaultFrustrationMaxTrend
+		r.MaxTrend = &maxTrend
+	}
+
+	if *r.MaxTrend < -2 || *r.MaxTrend > 0 {
+		return errors.InvalidArgument("maxTrend.not_valid", "")
+	}
+
+	if r.Limit == 0 {
+		r.Limit = 50
+	}
+
+	if r.Limit < 1 || r.Limit > 200 {
+		return errors.InvalidArgument("limit.not_valid", "")
+	}
+
+	return nil
+}
+
+// FrustratedTicketResponse model definition.
+type FrustratedTicketResponse struct {
+	TicketID          int64                        `json:"ticketID"`
+	Issuer            string                       `json:"issuer"`
+	Owner             string                       `json:"owner"`
+	Subject           string                       `json:"subject"`
+	ImportanceLevel   models.TicketImportanceLevel `json:"importanceLevel"`
+	Status            models.TicketStatus          `json:"status"`
+	SentimentScore    float64                      `json:"sentimentScore"`
+	SentimentTrend    float64                      `json:"sentimentTrend"`
+	SentimentScoredAt string                       `json:"sentimentScoredAt"`
+}
+
+// FrustratedTicketsResponse model definition.
+type FrustratedTicketsResponse struct {
+	Tickets []*FrustratedTicketResponse `json:"tickets"`
+}
+
+// LoadFromFrustratedTickets populates the fields of current model from provided frustrated tickets.
+func (r *FrustratedTicketsResponse) LoadFromFrustratedTickets(tickets []*models.FrustratedTicket) {
+	r.Tickets = make([]*FrustratedTicketResponse, 0, len(tickets))
+	for _, ticket := range tickets {
+		r.Tickets = append(r.Tickets, &FrustratedTicketResponse{
+			TicketID:          ticket.TicketID,
+			Issuer:            ticket.Issuer,
+			Owner:             ticket.Owner,
+			Subject:           ticket.Subject,
+			ImportanceLevel:   ticket.ImportanceLevel,
+			Status:            ticket.Status,
+			SentimentScore:    ticket.Sentiment.Score,
+			SentimentTrend:    ticket.Sentiment.Trend,
+			SentimentScoredAt: ticket.Sentiment.ScoredAt.Format(time.RFC3339Nano),
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package data
+
+import (
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// SaveSLATargetRequest model definition.
+type SaveSLATargetRequest struct {
+	Tier                 models.CustomerTier          `json:"tier"`
+	ImportanceLevel      models.TicketImportanceLevel `json:"importanceLevel"`
+	FirstResponseMinutes int                          `json:"firstResponseMinutes"`
+	ResolutionMinutes    int                          `json:"resolutionMinutes"`
+}
+
+// Validate validates the request.
+func (r *SaveSLATargetRequest) Validate() *errors.Type {
+	if !r.Tier.IsValid() {
+		return errors.InvalidArgument("tier.not_valid", "")
+	}
+
+	if !r.ImportanceLevel.IsValid() {
+		return errors.InvalidArgument("importanceLevel.not_valid", "")
+	}
+
+	if r.FirstResponseMinutes < 1 {
+		return errors.InvalidArgument("firstResponseMinutes.not_valid", "")
+	}
+
+	if r.ResolutionMinutes < r.FirstResponseMinutes {
+		return errors.InvalidArgument("resolutionMinutes.not_valid", "")
+	}
+
+	return nil
+}
+
+// AsSLATarget converts this request model into SLA target model.
+func (r *SaveSLATargetRequest) AsSLATarget() *models.SLATarget {
+	return &models.SLATarget{
+		Tier:            r.Tier,
+		ImportanceLevel: r.ImportanceLevel,
+		FirstResponse:   time.Duration(r.FirstResponseMinutes) * time.Minute,
+		Resolution:      time.Duration(r.ResolutionMinutes) * time.Minute,
+	}
+}
+
+// SLATargetResponse model definition.
+type SLATargetResponse struct {
+	Tier                 models.CustomerTier          `json:"tier"`
+	ImportanceLevel      models.TicketImportanceLevel `json:"importanceLevel"`
+	FirstResponseMinutes int                          `json:"firstResponseMinutes"`
+	ResolutionMinutes    int                          `json:"resolutionMinutes"`
+}
+
+// SLATargetsResponse model definition.
+type SLATargetsResponse struct {
+	Targets []*SLATargetResponse `json:"targets"`
+}
+
+// LoadFromSLATargets populates the fields of current model from provided SLA targets.
+func (r *SLATargetsResponse) LoadFromSLATargets(targets []*models.SLATarget) {
+	r.Targets = make([]*SLATargetResponse, 0, len(targets))
+	for _, target := range targets {
+		r.Targets = append(r.Targets, &SLATargetResponse{
+			Tier:                 target.Tier,
+			ImportanceLevel:      target.ImportanceLevel,
+			FirstResponseMinutes: int(target.FirstResponse / time.Minute),
+			ResolutionMinutes:    int(target.Resolution / time.Minute),
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package data
+
+import (
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+)
+
+// SnoozeTicketRequest model definition.
+type SnoozeTicketRequest struct {
+	ID int64 `json:"ID"`
+	// Until is the RFC 3339 time the ticket returns to the active queues at, unless its owner replies earlier.
+	Until string `json:"until"`
+	// Actor is who snoozes the ticket, it is recorded in the system comments of the ticket.
+	Actor string `json:"actor"`
+}
+
+// Validate validates the request.
+func (r *SnoozeTicketRequest) Validate() *errors.Type {
+	if r.ID <= 0 {
+		return errors.InvalidArgument("ID.invalid", "")
+	}
+
+	until, e := time.Parse(time.RFC3339, r.Until)
+	if e != nil || !until.After(time.Now()) {
+		return errors.InvalidArgument("until.not_valid", "")
+	}
+
+	r.Actor = normalize(r.Actor)
+	if len(r.Actor) > 50 {
+		return errors.InvalidArgument("actor.invalid_length", "")
+	}
+
+	return nil
+}
+
+// UntilTime returns back the parsed snooze time, should be called after Validate.
+func (r *SnoozeTicketRequest) UntilTime() time.Time {
+	until, _ := time.Parse(time.RFC3339, r.Until)
+	return until
+}
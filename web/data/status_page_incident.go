@@ -0,0 +1,88 @@
+package data
+
+import (
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// PublishIncidentRequest model definition.
+type PublishIncidentRequest struct {
+	TicketID int64                           `json:"ticketId"`
+	Title    string                          `json:"title"`
+	Message  string                          `json:"message"`
+	Status   models.StatusPageIncidentStatus `json:"status"`
+}
+
+// Validate validates the request.
+func (r *PublishIncidentRequest) Validate() *errors.Type {
+	r.Title = normalize(r.Title)
+	r.Message = normalize(r.Message)
+
+	if r.TicketID < 1 {
+		return errors.InvalidArgument("ticketId.not_valid", "")
+	}
+
+	if isBlank(r.Title) || len(r.Title) > 255 {
+		return errors.InvalidArgument("title.invalid_length", "")
+	}
+
+	if isBlank(r.Message) || len(r.Message) > 5000 {
+		return errors.InvalidArgument("message.invalid_length", "")
+	}
+
+	if r.Status == "" {
+		r.Status = models.StatusPageIncidentStatusInvestigating
+	}
+
+	if !r.Status.IsValid() {
+		return errors.InvalidArgument("status.not_valid", "")
+	}
+
+	return nil
+}
+
+// AsStatusPageIncident converts this request model into status page incident model.
+func (r *PublishIncidentRequest) AsStatusPageIncident(provider models.StatusPageProvider) *models.StatusPageIncident {
+	return &models.StatusPageIncident{
+		TicketID: r.TicketID,
+		Provider: provider,
+		Title:    r.Title,
+		Message:  r.Message,
+		Status:   r.Status,
+	}
+}
+
+// LoadIncidentRequest model definition.
+type LoadIncidentRequest struct {
+	TicketID int64 `json:"ticketId"`
+}
+
+// StatusPageIncidentResponse model definition.
+type StatusPageIncidentResponse struct {
+	TicketID    int64                           `json:"ticketId"`
+	Provider    models.StatusPageProvider       `json:"provider"`
+	ExternalID  string                          `json:"externalId,omitempty"`
+	Title       string                          `json:"title"`
+	Message     string                          `json:"message"`
+	Status      models.StatusPageIncidentStatus `json:"status"`
+	SyncStatus  models.ReferenceSyncStatus      `json:"syncStatus"`
+	SyncFailure string                          `json:"syncFailure,omitempty"`
+	CreatedAt   string                          `json:"createdAt"`
+	ModifiedAt  string                          `json:"modifiedAt"`
+}
+
+// LoadFromStatusPageIncident populates the fields of current model from provided status page incident.
+func (r *StatusPageIncidentResponse) LoadFromStatusPageIncident(incident *models.StatusPageIncident) {
+	r.TicketID = incident.TicketID
+	r.Provider = incident.Provider
+	r.ExternalID = incident.ExternalID
+	r.Title = incident.Title
+	r.Message = incident.Message
+	r.Status = incident.Status
+	r.SyncStatus = incident.SyncStatus
+	r.SyncFailure = incident.SyncFailure
+	r.CreatedAt = incident.CreatedAt.Format(time.RFC3339Nano)
+	r.ModifiedAt = incident.ModifiedAt.Format(time.RFC3339Nano)
+}
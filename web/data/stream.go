@@ -0,0 +1,117 @@
+package data
+
+import "github.com/jibitters/kiosk/errors"
+
+// StreamAction model.
+type StreamAction string
+
+// Different stream action instances.
+const (
+	StreamActionSubscribe   StreamAction = "SUBSCRIBE"
+	StreamActionUnsubscribe StreamAction = "UNSUBSCRIBE"
+)
+
+// StreamMessageType model.
+type StreamMessageType string
+
+// Different stream message type instances.
+const (
+	StreamMessageTypeSubscribed   StreamMessageType = "SUBSCRIBED"
+	StreamMessageTypeUnsubscribed StreamMessageType = "UNSUBSCRIBED"
+	StreamMessageTypeEvent        StreamMessageType = "EVENT"
+	StreamMessageTypeError        StreamMessageType = "ERROR"
+)
+
+// StreamRequest model definition. It is what browsers send over the event stream to manage their subscriptions, each
+// subscription is identified by an id chosen by the browser.
+type StreamRequest struct {
+	Action StreamAction `json:"action"`
+	ID     string       `json:"id"`
+	Filter StreamFilter `json:"filter"`
+}
+
+// Validate validates the request.
+func (r *StreamRequest) Validate() *errors.Type {
+	if r.Action != StreamActionSubscribe && r.Action != StreamActionUnsubscribe {
+		return errors.InvalidArgument("action.not_valid", "")
+	}
+
+	if isBlank(r.ID) {
+		return errors.InvalidArgument("id.is_required", "")
+	}
+
+	if len(r.ID) > 50 {
+		return errors.InvalidArgument("id.invalid_length", "")
+	}
+
+	if len(r.Filter.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	if len(r.Filter.Owner) > 50 {
+		return errors.InvalidArgument("owner.invalid_length", "")
+	}
+
+	if r.Filter.TicketID < 0 {
+		return errors.InvalidArgument("ticketID.not_valid", "")
+	}
+
+	return nil
+}
+
+// StreamFilter model definition. It selects the events of a subscription, empty fields match any event. Issuer and
+// owner only match events about a ticket, as comment events do not carry them.
+type StreamFilter struct {
+	Types    []EventType `json:"types"`
+	Issuer   string      `json:"issuer"`
+	Owner    string      `json:"owner"`
+	TicketID int64       `json:"ticketID"`
+}
+
+// Matches reports whether the event is selected by the filter.
+func (f *StreamFilter) Matches(event *Event) bool {
+	if len(f.Types) > 0 && !containsEventType(f.Types, event.Type) {
+		return false
+	}
+
+	if f.Issuer != "" && (event.Ticket == nil || event.Ticket.Issuer != f.Issuer) {
+		return false
+	}
+
+	if f.Owner != "" && (event.Ticket == nil || event.Ticket.Owner != f.Owner) {
+		return false
+	}
+
+	if f.TicketID != 0 {
+		switch {
+		case event.Ticket != nil:
+			return event.Ticket.ID == f.TicketID
+		case event.Comment != nil:
+			return event.Comment.TicketID == f.TicketID
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsEventType(types []EventType, eventType EventType) bool {
+	for _, t := range types {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StreamMessage model definition. It is what the event stream sends to browsers, events carry the ids of all
+// subscriptions they matched.
+type StreamMessage struct {
+	Type          StreamMessageType `json:"type"`
+	ID            string            `json:"id,omitempty"`
+	Subscriptions []string          `json:"subscriptions,omitempty"`
+	Event         *Event            `json:"event,omitempty"`
+	Error         *errors.Type      `json:"error,omitempty"`
+}
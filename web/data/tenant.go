@@ -0,0 +1,119 @@
+package data
+
+import (
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// ProvisionTenantRequest model definition. The settings default to those of issuers that have not customized
+// anything, their tier selects the SLA targets of the tenant.
+type ProvisionTenantRequest struct {
+	Issuer   string                    `json:"issuer"`
+	Name     string                    `json:"name"`
+	Settings SaveIssuerSettingsRequest `json:"settings"`
+}
+
+// Validate validates the request.
+func (r *ProvisionTenantRequest) Validate() *errors.Type {
+	r.Issuer = normalize(r.Issuer)
+	r.Name = normalize(r.Name)
+
+	if isBlank(r.Issuer) {
+		return errors.InvalidArgument("issuer.is_required", "")
+	}
+
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	if isBlank(r.Name) {
+		r.Name = r.Issuer
+	}
+
+	if len(r.Name) > 100 {
+		return errors.InvalidArgument("name.invalid_length", "")
+	}
+
+	defaults := models.DefaultIssuerSettings(r.Issuer)
+	r.Settings.Issuer = r.Issuer
+
+	if r.Settings.DefaultImportanceLevel == "" {
+		r.Settings.DefaultImportanceLevel = defaults.DefaultImportanceLevel
+	}
+
+	if r.Settings.DefaultStatus == "" {
+		r.Settings.DefaultStatus = defaults.DefaultStatus
+	}
+
+	return r.Settings.Validate()
+}
+
+// AsTenant converts this request model into tenant model. Should be called after Validate.
+func (r *ProvisionTenantRequest) AsTenant() *models.Tenant {
+	return &models.Tenant{Issuer: r.Issuer, Name: r.Name, Active: true}
+}
+
+// TenantResponse model definition.
+type TenantResponse struct {
+	Issuer        string `json:"issuer"`
+	Name          string `json:"name"`
+	Active        bool   `json:"active"`
+	CreatedAt     string `json:"createdAt"`
+	ModifiedAt    string `json:"modifiedAt"`
+	DeactivatedAt string `json:"deactivatedAt,omitempty"`
+}
+
+// LoadFromTenant populates the fields of current model from provided tenant.
+func (r *TenantResponse) LoadFromTenant(tenant *models.Tenant) {
+	r.Issuer = tenant.Issuer
+	r.Name = tenant.Name
+	r.Active = tenant.Active
+	r.CreatedAt = tenant.CreatedAt.Format(time.RFC3339Nano)
+	r.ModifiedAt = tenant.ModifiedAt.Format(time.RFC3339Nano)
+
+	if tenant.DeactivatedAt != nil {
+		r.DeactivatedAt = tenant.DeactivatedAt.Format(time.RFC3339Nano)
+	}
+}
+
+// ProvisionTenantResponse model definition. The API key is only ever sent back here, it can not be recovered later.
+type ProvisionTenantResponse struct {
+	Tenant TenantResponse `json:"tenant"`
+	APIKey string         `json:"apiKey"`
+}
+
+// TenantsResponse model definition.
+type TenantsResponse struct {
+	Tenants []TenantResponse `json:"tenants"`
+}
+
+// LoadFromTenants populates the fields of current model from provided tenants.
+func (r *TenantsResponse) LoadFromTenants(tenants []*models.Tenant) {
+	r.Tenants = make([]TenantResponse, 0, len(tenants))
+	for _, tenant := range tenants {
+		response := TenantResponse{}
+		response.LoadFromTenant(tenant)
+		r.Tenants = append(r.Tenants, response)
+	}
+}
+
+// AuthenticateTenantRequest model definition.
+type AuthenticateTenantRequest struct {
+	APIKey string `json:"apiKey"`
+}
+
+// Validate validates the request.
+func (r *AuthenticateTenantRequest) Validate() *errors.Type {
+	if isBlank(r.APIKey) || len(r.APIKey) > 255 {
+		return errors.Unauthorized("")
+	}
+
+	return nil
+}
+
+// AuthenticateTenantResponse model definition.
+type AuthenticateTenantResponse struct {
+	Issuer string `json:"issuer"`
+}
@@ -0,0 +1,112 @@
+package data
+
+import (
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// AssignTicketRequest model definition.
+type AssignTicketRequest struct {
+	ID       int64  `json:"ID"`
+	Assignee string `json:"assignee"`
+	// Reassign takes the ticket over from the agent it is assigned to, if any, otherwise assigning it fails.
+	Reassign bool `json:"reassign"`
+	// Actor is who assigns the ticket, e.g. the assignee claiming it, it is recorded in the system comments of the
+	// ticket.
+	Actor string `json:"actor"`
+}
+
+// Validate validates the request.
+func (r *AssignTicketRequest) Validate() *errors.Type {
+	if r.ID <= 0 {
+		return errors.InvalidArgument("ID.invalid", "")
+	}
+
+	r.Assignee = normalize(r.Assignee)
+	if isBlank(r.Assignee) {
+		return errors.InvalidArgument("assignee.is_required", "")
+	}
+
+	if len(r.Assignee) > 50 {
+		return errors.InvalidArgument("assignee.invalid_length", "")
+	}
+
+	r.Actor = normalize(r.Actor)
+	if len(r.Actor) > 50 {
+		return errors.InvalidArgument("actor.invalid_length", "")
+	}
+
+	return nil
+}
+
+// UnassignTicketRequest model definition.
+type UnassignTicketRequest struct {
+	ID int64 `json:"ID"`
+	// Assignee is optional, when given the ticket is only unassigned if it is assigned to that agent, so agents only
+	// release their own tickets.
+	Assignee string `json:"assignee"`
+	// Actor is who unassigns the ticket, it is recorded in the system comments of the ticket.
+	Actor string `json:"actor"`
+}
+
+// Validate validates the request.
+func (r *UnassignTicketRequest) Validate() *errors.Type {
+	if r.ID <= 0 {
+		return errors.InvalidArgument("ID.invalid", "")
+	}
+
+	r.Assignee = normalize(r.Assignee)
+	if len(r.Assignee) > 50 {
+		return errors.InvalidArgument("assignee.invalid_length", "")
+	}
+
+	r.Actor = normalize(r.Actor)
+	if len(r.Actor) > 50 {
+		return errors.InvalidArgument("actor.invalid_length", "")
+	}
+
+	return nil
+}
+
+// FilterByAssigneeRequest model definition. It lists the tickets assigned to the assignee, optionally of a single
+// issuer and status, the most recently modified first.
+type FilterByAssigneeRequest struct {
+	Issuer     string              `json:"issuer"`
+	Assignee   string              `json:"assignee"`
+	Status     models.TicketStatus `json:"status"`
+	PageNumber int                 `json:"pageNumber"`
+	PageSize   int                 `json:"pageSize"`
+	// ConsistencyToken makes the read observe the mutation that issued it, e.g. the assignment of a ticket.
+	ConsistencyToken string `json:"consistencyToken,omitempty"`
+}
+
+// Validate validates the request.
+func (r *FilterByAssigneeRequest) Validate() *errors.Type {
+	r.Issuer = normalize(r.Issuer)
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	r.Assignee = normalize(r.Assignee)
+	if isBlank(r.Assignee) {
+		return errors.InvalidArgument("assignee.is_required", "")
+	}
+
+	if len(r.Assignee) > 50 {
+		return errors.InvalidArgument("assignee.invalid_length", "")
+	}
+
+	if r.Status != "" && !r.Status.IsValid() {
+		return errors.InvalidArgument("status.not_valid", "")
+	}
+
+	if r.PageNumber < 1 {
+		return errors.InvalidArgument("pageNumber.not_valid", "")
+	}
+
+	if r.PageSize < 1 || r.PageSize > 25 {
+		return errors.InvalidArgument("pageSize.not_valid", "")
+	}
+
+	return validateConsistencyToken(r.ConsistencyToken)
+}
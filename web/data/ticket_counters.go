@@ -0,0 +1,42 @@
+package data
+
+import (
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// TicketCountersRequest model definition. Only the tickets of Owner and assigned to Assignee are counted, if not empty.
+type TicketCountersRequest struct {
+	Owner    string `json:"owner"`
+	Assignee string `json:"assignee,omitempty"`
+	// ConsistencyToken makes the read observe the mutation that issued it.
+	ConsistencyToken string `json:"consistencyToken,omitempty"`
+}
+
+// Validate validates the request.
+func (r *TicketCountersRequest) Validate() *errors.Type {
+	if len(r.Owner) > 50 {
+		return errors.InvalidArgument("owner.invalid_length", "")
+	}
+
+	if len(r.Assignee) > 50 {
+		return errors.InvalidArgument("assignee.invalid_length", "")
+	}
+
+	return validateConsistencyToken(r.ConsistencyToken)
+}
+
+// TicketCountersResponse model definition.
+type TicketCountersResponse struct {
+	Owner    string                        `json:"owner,omitempty"`
+	Assignee string                        `json:"assignee,omitempty"`
+	Counters map[models.TicketStatus]int64 `json:"counters"`
+}
+
+// TicketCounterDelta model definition. Published whenever the number of tickets in a status changes for an owner.
+// Listeners of the counters of an assignee reload them on the assignment and update events of tickets instead.
+type TicketCounterDelta struct {
+	Owner  string              `json:"owner"`
+	Status models.TicketStatus `json:"status"`
+	Delta  int64               `json:"delta"`
+}
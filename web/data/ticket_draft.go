@@ -0,0 +1,109 @@
+package data
+
+import (
+	"time"
+	"unicode/utf8"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// SaveTicketDraftRequest model definition. Drafts are partially composed tickets, so only the requester is required.
+type SaveTicketDraftRequest struct {
+	Issuer          string                       `json:"issuer"`
+	Owner           string                       `json:"owner"`
+	Subject         string                       `json:"subject"`
+	Content         string                       `json:"content"`
+	Metadata        string                       `json:"metadata"`
+	ImportanceLevel models.TicketImportanceLevel `json:"importanceLevel"`
+}
+
+// Validate validates the request.
+func (r *SaveTicketDraftRequest) Validate() *errors.Type {
+	requester := &TicketDraftRequest{Issuer: r.Issuer, Owner: r.Owner}
+	if e := requester.Validate(); e != nil {
+		return e
+	}
+
+	r.Issuer, r.Owner = requester.Issuer, requester.Owner
+
+	if len(r.Subject) > 255 {
+		return errors.InvalidArgument("subject.invalid_length", "")
+	}
+
+	if limits.TicketContentCharacters > 0 && utf8.RuneCountInString(r.Content) > limits.TicketContentCharacters {
+		return errors.InvalidArgument("content.invalid_length", "")
+	}
+
+	if r.ImportanceLevel != "" && !r.ImportanceLevel.IsValid() {
+		return errors.InvalidArgument("importanceLevel.not_valid", "")
+	}
+
+	return nil
+}
+
+// AsTicketDraft converts this request model into ticket draft model.
+func (r *SaveTicketDraftRequest) AsTicketDraft() *models.TicketDraft {
+	return &models.TicketDraft{
+		Issuer:          r.Issuer,
+		Owner:           r.Owner,
+		Subject:         r.Subject,
+		Content:         r.Content,
+		Metadata:        r.Metadata,
+		ImportanceLevel: r.ImportanceLevel,
+	}
+}
+
+// TicketDraftRequest model definition, it identifies the draft of a requester.
+type TicketDraftRequest struct {
+	Issuer string `json:"issuer"`
+	Owner  string `json:"owner"`
+}
+
+// Validate validates the request.
+func (r *TicketDraftRequest) Validate() *errors.Type {
+	r.Issuer = normalize(r.Issuer)
+	r.Owner = normalize(r.Owner)
+
+	if isBlank(r.Issuer) {
+		return errors.InvalidArgument("issuer.is_required", "")
+	}
+
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	if isBlank(r.Owner) {
+		return errors.InvalidArgument("owner.is_required", "")
+	}
+
+	if len(r.Owner) > 50 {
+		return errors.InvalidArgument("owner.invalid_length", "")
+	}
+
+	return nil
+}
+
+// TicketDraftResponse model definition.
+type TicketDraftResponse struct {
+	Issuer          string                       `json:"issuer"`
+	Owner           string                       `json:"owner"`
+	Subject         string                       `json:"subject"`
+	Content         string                       `json:"content"`
+	Metadata        string                       `json:"metadata"`
+	ImportanceLevel models.TicketImportanceLevel `json:"importanceLevel,omitempty"`
+	CreatedAt       string                       `json:"createdAt"`
+	ModifiedAt      string                       `json:"modifiedAt"`
+}
+
+// LoadFromTicketDraft populates the fields of current model from provided draft.
+func (r *TicketDraftResponse) LoadFromTicketDraft(draft *models.TicketDraft) {
+	r.Issuer = draft.Issuer
+	r.Owner = draft.Owner
+	r.Subject = draft.Subject
+	r.Content = draft.Content
+	r.Metadata = draft.Metadata
+	r.ImportanceLevel = draft.ImportanceLevel
+	r.CreatedAt = draft.CreatedAt.Format(time.RFC3339Nano)
+	r.ModifiedAt = draft.ModifiedAt.Format(time.RFC3339Nano)
+}
@@ -0,0 +1,144 @@
+package data
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// AttachReferenceRequest model definition.
+type AttachReferenceRequest struct {
+	TicketID    int64                  `json:"ticketId"`
+	System      models.ReferenceSystem `json:"system"`
+	URL         string                 `json:"url"`
+	ExternalKey string                 `json:"externalKey"`
+	// Sync keeps the state of the external issue and the status of the ticket in sync.
+	Sync bool `json:"sync"`
+}
+
+// Validate validates the request.
+func (r *AttachReferenceRequest) Validate() *errors.Type {
+	r.ExternalKey = normalize(r.ExternalKey)
+
+	if r.TicketID < 1 {
+		return errors.InvalidArgument("ticketId.not_valid", "")
+	}
+
+	if !r.System.IsValid() {
+		return errors.InvalidArgument("system.not_valid", "")
+	}
+
+	if len(r.URL) > 2048 {
+		return errors.InvalidArgument("url.invalid_length", "")
+	}
+
+	if u, e := url.Parse(r.URL); e != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.InvalidArgument("url.not_valid", "")
+	}
+
+	if r.ExternalKey == "" || len(r.ExternalKey) > 255 {
+		return errors.InvalidArgument("externalKey.invalid_length", "")
+	}
+
+	return nil
+}
+
+// AsTicketReference converts this request model into ticket reference model. Should be called after Validate.
+func (r *AttachReferenceRequest) AsTicketReference() *models.TicketReference {
+	status := models.ReferenceSyncStatusNone
+	if r.Sync {
+		status = models.ReferenceSyncStatusPending
+	}
+
+	return &models.TicketReference{
+		TicketID:    r.TicketID,
+		System:      r.System,
+		URL:         r.URL,
+		ExternalKey: r.ExternalKey,
+		SyncStatus:  status,
+	}
+}
+
+// EscalateTicketRequest model definition.
+type EscalateTicketRequest struct {
+	TicketID int64 `json:"ticketId"`
+	// Target is where the issue gets opened, a repository in owner/repository form for GitHub and a project key for
+	// Jira. Empty means the configured default one.
+	Target string `json:"target"`
+}
+
+// Validate validates the request.
+func (r *EscalateTicketRequest) Validate() *errors.Type {
+	r.Target = normalize(r.Target)
+
+	if r.TicketID < 1 {
+		return errors.InvalidArgument("ticketId.not_valid", "")
+	}
+
+	if len(r.Target) > 255 {
+		return errors.InvalidArgument("target.invalid_length", "")
+	}
+
+	return nil
+}
+
+// WebhookRequest model definition, a webhook delivery forwarded as is along with its headers of interest.
+type WebhookRequest struct {
+	// Source is the name of the webhook source the delivery is addressed to, it is set for inbound webhooks only.
+	Source    string `json:"source,omitempty"`
+	Event     string `json:"event"`
+	Signature string `json:"signature"`
+	// Timestamp is the unix timestamp the delivery got sent at, it is signed along with the payload of inbound webhooks.
+	Timestamp string `json:"timestamp,omitempty"`
+	// Token is the bearer token of the delivery, for senders that authenticate by tokens instead of signatures.
+	Token   string `json:"token,omitempty"`
+	Payload []byte `json:"payload"`
+}
+
+// ListReferencesRequest model definition.
+type ListReferencesRequest struct {
+	TicketID int64 `json:"ticketId"`
+}
+
+// TicketReferenceResponse model definition.
+type TicketReferenceResponse struct {
+	ID          int64                      `json:"ID"`
+	TicketID    int64                      `json:"ticketId"`
+	System      models.ReferenceSystem     `json:"system"`
+	URL         string                     `json:"url"`
+	ExternalKey string                     `json:"externalKey"`
+	SyncStatus  models.ReferenceSyncStatus `json:"syncStatus"`
+	SyncFailure string                     `json:"syncFailure,omitempty"`
+	CreatedAt   string                     `json:"createdAt"`
+	ModifiedAt  string                     `json:"modifiedAt"`
+}
+
+// LoadFromTicketReference populates the fields of current model from provided ticket reference.
+func (r *TicketReferenceResponse) LoadFromTicketReference(reference *models.TicketReference) {
+	r.ID = reference.ID
+	r.TicketID = reference.TicketID
+	r.System = reference.System
+	r.URL = reference.URL
+	r.ExternalKey = reference.ExternalKey
+	r.SyncStatus = reference.SyncStatus
+	r.SyncFailure = reference.SyncFailure
+	r.CreatedAt = reference.CreatedAt.Format(time.RFC3339Nano)
+	r.ModifiedAt = reference.ModifiedAt.Format(time.RFC3339Nano)
+}
+
+// TicketReferencesResponse model definition.
+type TicketReferencesResponse struct {
+	References []*TicketReferenceResponse `json:"references"`
+}
+
+// LoadFromTicketReferences populates the fields of current model from provided ticket references.
+func (r *TicketReferencesResponse) LoadFromTicketReferences(references []*models.TicketReference) {
+	r.References = make([]*TicketReferenceResponse, 0, len(references))
+	for _, reference := range references {
+		response := &TicketReferenceResponse{}
+		response.LoadFromTicketReference(reference)
+		r.References = append(r.References, response)
+	}
+}
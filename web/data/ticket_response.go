@@ -12,13 +12,38 @@ type TicketResponse struct {
 	Issuer          string                       `json:"issuer"`
 	Owner           string                       `json:"owner"`
 	Subject         string                       `json:"subject"`
-	Content         string                       `json:"content"`
+	Content         string                       `json:"content,omitempty"`
+	ContentPreview  string                       `json:"contentPreview,omitempty"`
 	Metadata        string                       `json:"metadata,omitempty"`
 	ImportanceLevel models.TicketImportanceLevel `json:"importanceLevel"`
 	Status          models.TicketStatus          `json:"status"`
-	Comments        []*CommentResponse           `json:"comments,omitempty"`
-	CreatedAt       string                       `json:"createdAt"`
-	ModifiedAt      string                       `json:"modifiedAt"`
+	Tier            models.CustomerTier          `json:"tier,omitempty"`
+	// FirstResponseDueAt and ResolutionDueAt are the SLA deadlines of the ticket, if any.
+	FirstResponseDueAt string               `json:"firstResponseDueAt,omitempty"`
+	ResolutionDueAt    string               `json:"resolutionDueAt,omitempty"`
+	SnoozedUntil       string               `json:"snoozedUntil,omitempty"`
+	Revision           int                  `json:"revision,omitempty"`
+	TimeSpentMinutes   int                  `json:"timeSpentMinutes"`
+	Billable           bool                 `json:"billable"`
+	ApprovalState      models.ApprovalState `json:"approvalState,omitempty"`
+	Assignee           string               `json:"assignee,omitempty"`
+	// AssigneeIdentity is how the assignee is known to the identity provider, if it is configured and knows them.
+	AssigneeIdentity *IdentityResponse `json:"assigneeIdentity,omitempty"`
+	// ResolutionCategory, ResolutionSubCategory and RootCause are how the ticket got resolved, if captured.
+	ResolutionCategory    string           `json:"resolutionCategory,omitempty"`
+	ResolutionSubCategory string           `json:"resolutionSubCategory,omitempty"`
+	RootCause             models.RootCause `json:"rootCause,omitempty"`
+	ResolvedAt            string           `json:"resolvedAt,omitempty"`
+	// SentimentScore and SentimentTrend are the sentiment of the customer, they are omitted until it is scored.
+	SentimentScore *float64 `json:"sentimentScore,omitempty"`
+	SentimentTrend *float64 `json:"sentimentTrend,omitempty"`
+	// MergedInto is the id of the ticket this one got merged into as a duplicate, if merged.
+	MergedInto int64 `json:"mergedInto,omitempty"`
+	// Archived tells the ticket is loaded from the archive, archived tickets are read only.
+	Archived   bool               `json:"archived,omitempty"`
+	Comments   []*CommentResponse `json:"comments,omitempty"`
+	CreatedAt  string             `json:"createdAt"`
+	ModifiedAt string             `json:"modifiedAt"`
 }
 
 // LoadFromTicket populates the fields of current model from provided ticket.
@@ -31,6 +56,40 @@ func (r *TicketResponse) LoadFromTicket(ticket *models.Ticket) {
 	r.Metadata = ticket.Metadata
 	r.ImportanceLevel = ticket.ImportanceLevel
 	r.Status = ticket.Status
+	r.Tier = ticket.Tier
+
+	if ticket.FirstResponseDueAt != nil {
+		r.FirstResponseDueAt = ticket.FirstResponseDueAt.Format(time.RFC3339Nano)
+	}
+
+	if ticket.ResolutionDueAt != nil {
+		r.ResolutionDueAt = ticket.ResolutionDueAt.Format(time.RFC3339Nano)
+	}
+
+	if ticket.SnoozedUntil != nil {
+		r.SnoozedUntil = ticket.SnoozedUntil.Format(time.RFC3339Nano)
+	}
+
+	r.Revision = ticket.Revision
+	r.TimeSpentMinutes = int(ticket.TimeSpent / time.Minute)
+	r.Billable = ticket.Billable
+	r.ApprovalState = ticket.ApprovalState
+	r.Assignee = ticket.Assignee
+	r.ResolutionCategory = ticket.Resolution.Category
+	r.ResolutionSubCategory = ticket.Resolution.SubCategory
+	r.RootCause = ticket.Resolution.RootCause
+
+	if ticket.ResolvedAt != nil {
+		r.ResolvedAt = ticket.ResolvedAt.Format(time.RFC3339Nano)
+	}
+
+	if ticket.Sentiment != nil {
+		r.SentimentScore = &ticket.Sentiment.Score
+		r.SentimentTrend = &ticket.Sentiment.Trend
+	}
+
+	r.MergedInto = ticket.MergedInto
+	r.Archived = ticket.Archived
 
 	for _, c := range ticket.Comments {
 		cr := &CommentResponse{}
@@ -42,17 +101,42 @@ func (r *TicketResponse) LoadFromTicket(ticket *models.Ticket) {
 	r.ModifiedAt = ticket.ModifiedAt.Format(time.RFC3339Nano)
 }
 
+// UsePreviews replaces the contents of the ticket and its comments with their truncated previews.
+func (r *TicketResponse) UsePreviews() {
+	r.ContentPreview, r.Content = preview(r.Content), ""
+	for _, c := range r.Comments {
+		c.ContentPreview, c.Content = preview(c.Content), ""
+	}
+}
+
 // CommentResponse model definition.
 type CommentResponse struct {
-	ID         int64  `json:"ID"`
-	TicketID   int64  `json:"ticketID"`
-	Owner      string `json:"owner"`
-	Content    string `json:"content"`
-	Metadata   string `json:"metadata,omitempty"`
+	ID       int64  `json:"ID"`
+	TicketID int64  `json:"ticketID"`
+	Owner    string `json:"owner"`
+	// OwnerIdentity is how the owner is known to the identity provider, if it is configured and knows them.
+	OwnerIdentity  *IdentityResponse        `json:"ownerIdentity,omitempty"`
+	Content        string                   `json:"content,omitempty"`
+	ContentPreview string                   `json:"contentPreview,omitempty"`
+	Metadata       string                   `json:"metadata,omitempty"`
+	AuthorType     models.CommentAuthorType `json:"authorType"`
+	Source         models.CommentSource     `json:"source,omitempty"`
+	// Revision is the revision of the content, Edited tells whether it got edited since posted and EditedAt when it
+	// got edited last.
+	Revision   int    `json:"revision"`
+	Edited     bool   `json:"edited"`
+	EditedAt   string `json:"editedAt,omitempty"`
 	CreatedAt  string `json:"createdAt"`
 	ModifiedAt string `json:"modifiedAt"`
 }
 
+// IdentityResponse model definition.
+type IdentityResponse struct {
+	DisplayName string `json:"displayName,omitempty"`
+	Email       string `json:"email,omitempty"`
+	AvatarURL   string `json:"avatarURL,omitempty"`
+}
+
 // LoadFromComment populates the fields of current model from provided comment.
 func (r *CommentResponse) LoadFromComment(comment *models.Comment) {
 	r.ID = comment.ID
@@ -60,6 +144,13 @@ func (r *CommentResponse) LoadFromComment(comment *models.Comment) {
 	r.Owner = comment.Owner
 	r.Content = comment.Content
 	r.Metadata = comment.Metadata
+	r.AuthorType = comment.AuthorType
+	r.Source = comment.Source
+	r.Revision = comment.Revision
 	r.CreatedAt = comment.CreatedAt.Format(time.RFC3339Nano)
 	r.ModifiedAt = comment.ModifiedAt.Format(time.RFC3339Nano)
+
+	if comment.EditedAt != nil {
+		r.Edited, r.EditedAt = true, comment.EditedAt.Format(time.RFC3339Nano)
+	}
 }
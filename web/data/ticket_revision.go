@@ -0,0 +1,60 @@
+package data
+
+import (
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// TicketRevisionsRequest model definition.
+type TicketRevisionsRequest struct {
+	PageRequest
+	TicketID int64 `json:"ticketId"`
+}
+
+// Validate validates the request.
+func (r *TicketRevisionsRequest) Validate() *errors.Type {
+	if r.TicketID < 1 {
+		return errors.InvalidArgument("ticketId.not_valid", "")
+	}
+
+	return r.PageRequest.Validate()
+}
+
+// TicketRevisionResponse model definition.
+type TicketRevisionResponse struct {
+	Revision   int    `json:"revision"`
+	Subject    string `json:"subject"`
+	Content    string `json:"content"`
+	Editor     string `json:"editor,omitempty"`
+	ReplacedAt string `json:"replacedAt"`
+}
+
+// LoadFromTicketRevision populates the fields of current model from provided ticket revision.
+func (r *TicketRevisionResponse) LoadFromTicketRevision(revision *models.TicketRevision) {
+	r.Revision = revision.Revision
+	r.Subject = revision.Subject
+	r.Content = revision.Content
+	r.Editor = revision.Editor
+	r.ReplacedAt = revision.ReplacedAt.Format(time.RFC3339Nano)
+}
+
+// TicketRevisionsResponse model definition. Revisions are the previous revisions of the ticket, oldest first, the
+// current one is the ticket itself. NextPageToken is only set when there is a next page.
+type TicketRevisionsResponse struct {
+	TicketID      int64                     `json:"ticketId"`
+	Revisions     []*TicketRevisionResponse `json:"revisions"`
+	NextPageToken string                    `json:"nextPageToken,omitempty"`
+}
+
+// LoadFromTicketRevisions populates the fields of current model from provided ticket revisions.
+func (r *TicketRevisionsResponse) LoadFromTicketRevisions(ticketID int64, revisions []*models.TicketRevision) {
+	r.TicketID = ticketID
+	r.Revisions = make([]*TicketRevisionResponse, 0, len(revisions))
+	for _, revision := range revisions {
+		response := &TicketRevisionResponse{}
+		response.LoadFromTicketRevision(revision)
+		r.Revisions = append(r.Revisions, response)
+	}
+}
@@ -0,0 +1,65 @@
+package data
+
+import (
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// TimeReportRequest model definition.
+type TimeReportRequest struct {
+	Issuer   string `json:"issuer"`
+	Agent    string `json:"agent"`
+	FromDate string `json:"fromDate"`
+	ToDate   string `json:"toDate"`
+}
+
+// Validate validates the request.
+func (r *TimeReportRequest) Validate() *errors.Type {
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	if len(r.Agent) > 50 {
+		return errors.InvalidArgument("agent.invalid_length", "")
+	}
+
+	if r.FromDate == "" {
+		r.FromDate = time.Now().UTC().AddDate(0, 0, -30).Format(time.RFC3339Nano)
+	}
+
+	if r.ToDate == "" {
+		r.ToDate = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+
+	return nil
+}
+
+// TimeReportResponse model definition.
+type TimeReportResponse struct {
+	Rows []*TimeReportRowResponse `json:"rows"`
+}
+
+// TimeReportRowResponse model definition. The agent is omitted when agents are not reported.
+type TimeReportRowResponse struct {
+	Issuer  string `json:"issuer"`
+	Agent   string `json:"agent,omitempty"`
+	Tickets int64  `json:"tickets"`
+	Entries int64  `json:"entries"`
+	Minutes int64  `json:"minutes"`
+}
+
+// LoadFromTimeSpentRows populates the fields of current model from provided time spent rows.
+func (r *TimeReportResponse) LoadFromTimeSpentRows(rows []*models.TimeSpentRow) {
+	r.Rows = make([]*TimeReportRowResponse, 0, len(rows))
+	for _, row := range rows {
+		r.Rows = append(r.Rows, &TimeReportRowResponse{
+			Issuer:  row.Issuer,
+			Agent:   row.Agent,
+			Tickets: row.Tickets,
+			Entries: row.Entries,
+			Minutes: int64(row.TimeSpent / time.Minute),
+		})
+	}
+}
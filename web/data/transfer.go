@@ -0,0 +1,250 @@
+package data
+
+import (
+	"time"
+
+	"github.com/jibitters/kiosk/anonymization"
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// ExportTicketsRequest model definition. Tickets are exported in pages ordered by id, each page starting after the
+// last id of the previous one.
+type ExportTicketsRequest struct {
+	Issuer   string `json:"issuer"`
+	AfterID  int64  `json:"afterId"`
+	PageSize int    `json:"pageSize"`
+	// Anonymized exports datasets for analytics, the tickets of all issuers are exported when no issuer is given.
+	Anonymized bool `json:"anonymized"`
+}
+
+// Validate validates the request.
+func (r *ExportTicketsRequest) Validate() *errors.Type {
+	r.Issuer = normalize(r.Issuer)
+
+	if isBlank(r.Issuer) && !r.Anonymized {
+		return errors.InvalidArgument("issuer.is_required", "")
+	}
+
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	if r.AfterID < 0 {
+		return errors.InvalidArgument("afterId.not_valid", "")
+	}
+
+	if r.PageSize == 0 {
+		r.PageSize = 100
+	}
+
+	if r.PageSize < 1 || r.PageSize > 500 {
+		return errors.InvalidArgument("pageSize.not_valid", "")
+	}
+
+	return nil
+}
+
+// TransferredComment model definition, a comment as it is exported from a kiosk deployment.
+type TransferredComment struct {
+	Owner      string                   `json:"owner"`
+	Content    string                   `json:"content"`
+	Metadata   string                   `json:"metadata"`
+	AuthorType models.CommentAuthorType `json:"authorType"`
+	Source     models.CommentSource     `json:"source,omitempty"`
+	CreatedAt  string                   `json:"createdAt"`
+	ModifiedAt string                   `json:"modifiedAt"`
+}
+
+// TransferredTicket model definition, a ticket as it is exported from a kiosk deployment along with its comments.
+type TransferredTicket struct {
+	ID int64 `json:"id"`
+	// ExternalID identifies the ticket the exported one originates from, if it got transferred itself.
+	ExternalID      string                       `json:"externalId,omitempty"`
+	Issuer          string                       `json:"issuer"`
+	Owner           string                       `json:"owner"`
+	Subject         string                       `json:"subject"`
+	Content         string                       `json:"content"`
+	Metadata        string                       `json:"metadata"`
+	ImportanceLevel models.TicketImportanceLevel `json:"importanceLevel"`
+	Status          models.TicketStatus          `json:"status"`
+	Billable        bool                         `json:"billable"`
+	CreatedAt       string                       `json:"createdAt"`
+	ModifiedAt      string                       `json:"modifiedAt"`
+	Comments        []TransferredComment         `json:"comments"`
+}
+
+// LoadFromTicket populates the fields of current model from provided ticket.
+func (r *TransferredTicket) LoadFromTicket(ticket *models.Ticket) {
+	r.ID = ticket.ID
+	r.ExternalID = ticket.ExternalID
+	r.Issuer = ticket.Issuer
+	r.Owner = ticket.Owner
+	r.Subject = ticket.Subject
+	r.Content = ticket.Content
+	r.Metadata = ticket.Metadata
+	r.ImportanceLevel = ticket.ImportanceLevel
+	r.Status = ticket.Status
+	r.Billable = ticket.Billable
+	r.CreatedAt = ticket.CreatedAt.Format(time.RFC3339Nano)
+	r.ModifiedAt = ticket.ModifiedAt.Format(time.RFC3339Nano)
+
+	r.Comments = make([]TransferredComment, 0, len(ticket.Comments))
+	for _, comment := range ticket.Comments {
+		r.Comments = append(r.Comments, TransferredComment{
+			Owner:      comment.Owner,
+			Content:    comment.Content,
+			Metadata:   comment.Metadata,
+			AuthorType: comment.AuthorType,
+			Source:     comment.Source,
+			CreatedAt:  comment.CreatedAt.Format(time.RFC3339Nano),
+			ModifiedAt: comment.ModifiedAt.Format(time.RFC3339Nano),
+		})
+	}
+}
+
+// ExportTicketsResponse model definition.
+type ExportTicketsResponse struct {
+	Tickets []TransferredTicket `json:"tickets"`
+	// LastID is the after id of the next page, it equals the requested one when there is nothing left.
+	LastID  int64 `json:"lastId"`
+	HasMore bool  `json:"hasMore"`
+}
+
+// LoadFromTickets populates the fields of current model from provided tickets of a page after afterID.
+func (r *ExportTicketsResponse) LoadFromTickets(tickets []*models.Ticket, afterID int64, pageSize int) {
+	r.LastID = afterID
+	r.Tickets = make([]TransferredTicket, 0, len(tickets))
+	for _, ticket := range tickets {
+		transferred := TransferredTicket{}
+		transferred.LoadFromTicket(ticket)
+		r.Tickets = append(r.Tickets, transferred)
+		r.LastID = ticket.ID
+	}
+
+	r.HasMore = len(tickets) == pageSize
+}
+
+// Anonymize strips the personal data of the exported tickets while keeping their structure and timings. Owners are
+// replaced by stable pseudonyms, so the tickets and comments of the same person can still be told apart, personal data
+// within subjects, contents and metadata is redacted, and external ids are dropped.
+func (r *ExportTicketsResponse) Anonymize(anonymizer *anonymization.Anonymizer) {
+	for i := range r.Tickets {
+		ticket := &r.Tickets[i]
+		ticket.ExternalID = ""
+		ticket.Owner = anonymizer.Pseudonym("owner", ticket.Owner)
+		ticket.Subject = anonymizer.Redact(ticket.Subject)
+		ticket.Content = anonymizer.Redact(ticket.Content)
+		ticket.Metadata = anonymizer.Redact(ticket.Metadata)
+
+		for j := range ticket.Comments {
+			comment := &ticket.Comments[j]
+			comment.Owner = anonymizer.Pseudonym("owner", comment.Owner)
+			comment.Content = anonymizer.Redact(comment.Content)
+			comment.Metadata = anonymizer.Redact(comment.Metadata)
+		}
+	}
+}
+
+// ImportTicketRequest model definition. The external id identifies the ticket within the destination, importing it
+// again is a no-op.
+type ImportTicketRequest struct {
+	ExternalID string            `json:"externalId"`
+	Ticket     TransferredTicket `json:"ticket"`
+}
+
+// Validate validates the request.
+func (r *ImportTicketRequest) Validate() *errors.Type {
+	if isBlank(r.ExternalID) {
+		return errors.InvalidArgument("externalId.is_required", "")
+	}
+
+	if len(r.ExternalID) > 100 {
+		return errors.InvalidArgument("externalId.invalid_length", "")
+	}
+
+	ticket := &r.Ticket
+	if isBlank(ticket.Issuer) || len(ticket.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.not_valid", "")
+	}
+
+	if isBlank(ticket.Owner) || len(ticket.Owner) > 50 {
+		return errors.InvalidArgument("owner.not_valid", "")
+	}
+
+	if isBlank(ticket.Subject) || len(ticket.Subject) > 255 {
+		return errors.InvalidArgument("subject.not_valid", "")
+	}
+
+	if !ticket.ImportanceLevel.IsValid() {
+		return errors.InvalidArgument("importanceLevel.not_valid", "")
+	}
+
+	if !ticket.Status.IsValid() {
+		return errors.InvalidArgument("status.not_valid", "")
+	}
+
+	if !isRFC3339(ticket.CreatedAt) || !isRFC3339(ticket.ModifiedAt) {
+		return errors.InvalidArgument("ticket.timestamps_not_valid", "")
+	}
+
+	for _, comment := range ticket.Comments {
+		if isBlank(comment.Owner) || len(comment.Owner) > 50 {
+			return errors.InvalidArgument("comment.owner_not_valid", "")
+		}
+
+		if comment.AuthorType != "" && !comment.AuthorType.IsValid() {
+			return errors.InvalidArgument("comment.authorType_not_valid", "")
+		}
+
+		if comment.Source != "" && !comment.Source.IsValid() {
+			return errors.InvalidArgument("comment.source_not_valid", "")
+		}
+
+		if !isRFC3339(comment.CreatedAt) || !isRFC3339(comment.ModifiedAt) {
+			return errors.InvalidArgument("comment.timestamps_not_valid", "")
+		}
+	}
+
+	return nil
+}
+
+// AsTicket converts this request model into ticket model. Should be called after Validate.
+func (r *ImportTicketRequest) AsTicket() *models.Ticket {
+	ticket := &models.Ticket{
+		Issuer:          r.Ticket.Issuer,
+		Owner:           r.Ticket.Owner,
+		Subject:         r.Ticket.Subject,
+		Content:         r.Ticket.Content,
+		Metadata:        r.Ticket.Metadata,
+		ImportanceLevel: r.Ticket.ImportanceLevel,
+		Status:          r.Ticket.Status,
+		Billable:        r.Ticket.Billable,
+		ExternalID:      r.ExternalID,
+	}
+
+	ticket.CreatedAt, _ = time.Parse(time.RFC3339Nano, r.Ticket.CreatedAt)
+	ticket.ModifiedAt, _ = time.Parse(time.RFC3339Nano, r.Ticket.ModifiedAt)
+
+	for _, c := range r.Ticket.Comments {
+		comment := &models.Comment{Owner: c.Owner, Content: c.Content, Metadata: c.Metadata, AuthorType: c.AuthorType,
+			Source: c.Source}
+		comment.CreatedAt, _ = time.Parse(time.RFC3339Nano, c.CreatedAt)
+		comment.ModifiedAt, _ = time.Parse(time.RFC3339Nano, c.ModifiedAt)
+		ticket.Comments = append(ticket.Comments, comment)
+	}
+
+	return ticket
+}
+
+// ImportTicketResponse model definition.
+type ImportTicketResponse struct {
+	ID int64 `json:"id"`
+	// Imported is false when the ticket had already been imported.
+	Imported bool `json:"imported"`
+}
+
+func isRFC3339(value string) bool {
+	_, e := time.Parse(time.RFC3339Nano, value)
+	return e == nil
+}
@@ -0,0 +1,87 @@
+package data
+
+import (
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// SaveTransitionRequirementsRequest model definition.
+type SaveTransitionRequirementsRequest struct {
+	Issuer string                 `json:"issuer"`
+	Status models.TicketStatus    `json:"status"`
+	Fields []models.RequiredField `json:"fields"`
+}
+
+// Validate validates the request.
+func (r *SaveTransitionRequirementsRequest) Validate() *errors.Type {
+	r.Issuer = normalize(r.Issuer)
+
+	if isBlank(r.Issuer) {
+		return errors.InvalidArgument("issuer.is_required", "")
+	}
+
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	if !r.Status.IsValid() || r.Status == models.TicketStatusNew {
+		return errors.InvalidArgument("status.not_valid", "")
+	}
+
+	if len(r.Fields) == 0 {
+		return errors.InvalidArgument("fields.is_required", "")
+	}
+
+	for _, field := range r.Fields {
+		if !field.IsValid() {
+			return errors.InvalidArgument("field.not_valid", "")
+		}
+	}
+
+	return nil
+}
+
+// TransitionRequirementsRequest model definition.
+type TransitionRequirementsRequest struct {
+	Issuer string              `json:"issuer"`
+	Status models.TicketStatus `json:"status"`
+}
+
+// Validate validates the request.
+func (r *TransitionRequirementsRequest) Validate() *errors.Type {
+	if isBlank(r.Issuer) {
+		return errors.InvalidArgument("issuer.is_required", "")
+	}
+
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	if !r.Status.IsValid() {
+		return errors.InvalidArgument("status.not_valid", "")
+	}
+
+	return nil
+}
+
+// TransitionRequirementResponse model definition.
+type TransitionRequirementResponse struct {
+	Status models.TicketStatus  `json:"status"`
+	Field  models.RequiredField `json:"field"`
+}
+
+// TransitionRequirementsResponse model definition.
+type TransitionRequirementsResponse struct {
+	Requirements []*TransitionRequirementResponse `json:"requirements"`
+}
+
+// LoadFromTransitionRequirements populates the fields of current model from provided transition requirements.
+func (r *TransitionRequirementsResponse) LoadFromTransitionRequirements(
+	requirements []*models.TransitionRequirement) {
+
+	r.Requirements = make([]*TransitionRequirementResponse, 0, len(requirements))
+	for _, requirement := range requirements {
+		r.Requirements = append(r.Requirements, &TransitionRequirementResponse{Status: requirement.Status,
+			Field: requirement.Field})
+	}
+}
@@ -9,6 +9,10 @@ import (
 type UpdateCommentRequest struct {
 	ID       int64  `json:"ID"`
 	Metadata string `json:"metadata"`
+	// Content replaces the content of the comment, it is optional and empty means the content is left as is.
+	Content string `json:"content"`
+	// Editor is who makes the change, it is recorded as the editor of the previous revision of the content.
+	Editor string `json:"editor"`
 }
 
 // Validate validates the request.
@@ -17,6 +21,18 @@ func (r *UpdateCommentRequest) Validate() *errors.Type {
 		return errors.InvalidArgument("ID.invalid", "")
 	}
 
+	r.Content = normalize(r.Content)
+	if r.Content != "" {
+		if e := validateContent(r.Content, limits.CommentContentCharacters); e != nil {
+			return e
+		}
+	}
+
+	r.Editor = normalize(r.Editor)
+	if len(r.Editor) > 50 {
+		return errors.InvalidArgument("editor.invalid_length", "")
+	}
+
 	return nil
 }
 
@@ -25,5 +41,6 @@ func (r *UpdateCommentRequest) AsComment() *models.Comment {
 	return &models.Comment{
 		Model:    models.Model{ID: r.ID},
 		Metadata: r.Metadata,
+		Content:  r.Content,
 	}
 }
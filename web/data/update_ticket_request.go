@@ -7,11 +7,21 @@ import (
 
 // UpdateTicketRequest model definition.
 type UpdateTicketRequest struct {
-	ID              int64                        `json:"ID"`
-	Subject         string                       `json:"subject"`
+	ID      int64  `json:"ID"`
+	Subject string `json:"subject"`
+	// Content replaces the content of the ticket, it is optional and empty means the content is left as is.
+	Content         string                       `json:"content"`
 	Metadata        string                       `json:"metadata"`
 	ImportanceLevel models.TicketImportanceLevel `json:"importanceLevel"`
 	Status          models.TicketStatus          `json:"status"`
+	// Actor is who makes the change, it is recorded in the system comments of the ticket and as the editor of its
+	// previous revision.
+	Actor string `json:"actor"`
+	// ResolutionCategory, ResolutionSubCategory and RootCause capture how the ticket got resolved. They are optional
+	// and empty values keep the current ones, the category must be one of the resolution categories.
+	ResolutionCategory    string           `json:"resolutionCategory"`
+	ResolutionSubCategory string           `json:"resolutionSubCategory"`
+	RootCause             models.RootCause `json:"rootCause"`
 }
 
 // Validate validates the request.
@@ -20,7 +30,9 @@ func (r *UpdateTicketRequest) Validate() *errors.Type {
 		return errors.InvalidArgument("ID.invalid", "")
 	}
 
-	if len(r.Subject) == 0 {
+	r.Subject = normalize(r.Subject)
+
+	if isBlank(r.Subject) {
 		return errors.InvalidArgument("subject.is_required", "")
 	}
 
@@ -28,6 +40,13 @@ func (r *UpdateTicketRequest) Validate() *errors.Type {
 		return errors.InvalidArgument("subject.invalid_length", "")
 	}
 
+	r.Content = normalize(r.Content)
+	if r.Content != "" {
+		if e := validateContent(r.Content, limits.TicketContentCharacters); e != nil {
+			return e
+		}
+	}
+
 	if r.ImportanceLevel != models.TicketImportanceLevelLow &&
 		r.ImportanceLevel != models.TicketImportanceLevelMedium &&
 		r.ImportanceLevel != models.TicketImportanceLevelHigh &&
@@ -39,11 +58,28 @@ func (r *UpdateTicketRequest) Validate() *errors.Type {
 	if r.Status != models.TicketStatusReplied &&
 		r.Status != models.TicketStatusResolved &&
 		r.Status != models.TicketStatusClosed &&
-		r.Status != models.TicketStatusBlocked {
+		r.Status != models.TicketStatusBlocked &&
+		r.Status != models.TicketStatusWaitingOnCustomer {
 
 		return errors.InvalidArgument("status.not_valid", "")
 	}
 
+	r.Actor = normalize(r.Actor)
+	if len(r.Actor) > 50 {
+		return errors.InvalidArgument("actor.invalid_length", "")
+	}
+
+	r.ResolutionCategory = normalize(r.ResolutionCategory)
+	r.ResolutionSubCategory = normalize(r.ResolutionSubCategory)
+
+	if r.ResolutionSubCategory != "" && r.ResolutionCategory == "" {
+		return errors.InvalidArgument("resolutionCategory.is_required", "")
+	}
+
+	if r.RootCause != "" && !r.RootCause.IsValid() {
+		return errors.InvalidArgument("rootCause.not_valid", "")
+	}
+
 	return nil
 }
 
@@ -52,8 +88,11 @@ func (r *UpdateTicketRequest) AsTicket() *models.Ticket {
 	return &models.Ticket{
 		Model:           models.Model{ID: r.ID},
 		Subject:         r.Subject,
+		Content:         r.Content,
 		Metadata:        r.Metadata,
 		ImportanceLevel: r.ImportanceLevel,
 		Status:          r.Status,
+		Resolution: models.Resolution{Category: r.ResolutionCategory, SubCategory: r.ResolutionSubCategory,
+			RootCause: r.RootCause},
 	}
 }
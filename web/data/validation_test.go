@@ -0,0 +1,176 @@
+package data_test
+
+import (
+	"encoding/json"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/test"
+	"github.com/jibitters/kiosk/web/data"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// validator is a request decoded from the body of NATS messages and validated by services.
+type validator interface {
+	Validate() *errors.Type
+}
+
+// requests maps constructors of requests to seeds of their bodies.
+var requests = map[string]struct {
+	new   func() validator
+	seeds []string
+}{
+	"CreateTicketRequest": {func() validator { return &data.CreateTicketRequest{} }, []string{
+		`{"issuer":"Microservice-A","owner":"user@example.com","subject":"Technical Problem",` +
+			`"content":"Hello, i have some issues with REST API Docs!","metadata":"{\"ip\":\"192.168.1.1\"}",` +
+			`"importanceLevel":"MEDIUM","status":"NEW","fingerprint":"alert-1","billable":true}`,
+	}},
+	"UpdateTicketRequest": {func() validator { return &data.UpdateTicketRequest{} }, []string{
+		`{"ID":1,"subject":"Technical Problem","content":"Fixed now.","metadata":"{}","importanceLevel":"HIGH",` +
+			`"status":"RESOLVED","actor":"agent@example.com","resolutionCategory":"Bug","rootCause":"CODE"}`,
+	}},
+	"FilterTicketsRequest": {func() validator { return &data.FilterTicketsRequest{} }, []string{
+		`{"issuer":"Microservice-A","owner":"user@example.com","importanceLevel":"LOW","status":"NEW",` +
+			`"fromDate":"2020-01-01T00:00:00Z","toDate":"2020-02-01T00:00:00Z","pageNumber":1,"pageSize":10,` +
+			`"metadata":{"owner_ip":"10.0.0.1","customer.plan":"gold"},"groupBy":"status",` +
+			`"orderBy":"createdAt:desc,id"}`,
+		`{"issuer":"Microservice-A","importanceLevel":"LOW","status":"NEW","pageToken":"eyJsIjoidGlja2V0cyJ9.c2ln",` +
+			`"pageSize":10}`,
+	}},
+	"CreateCommentRequest": {func() validator { return &data.CreateCommentRequest{} }, []string{
+		`{"ticketID":1,"owner":"user@example.com","content":"Any news?","metadata":"{}","authorType":"CUSTOMER"}`,
+		`{"ticketID":1,"owner":"user@example.com","content":"See the screenshots.","attachmentIDs":[1,2]}`,
+	}},
+	"UpdateCommentRequest": {func() validator { return &data.UpdateCommentRequest{} }, []string{
+		`{"ID":1,"ticketID":1,"content":"Any news on this?","metadata":"{}"}`,
+	}},
+	"FilterCommentsRequest": {func() validator { return &data.FilterCommentsRequest{} }, []string{
+		`{"ticketID":1,"pageNumber":1,"pageSize":10}`,
+		`{"ticketID":1,"orderBy":"createdAt","aroundCommentId":2,"window":5}`,
+		`{"ticketID":1,"orderBy":"createdAt","pageSize":10,"pageToken":"eyJsIjoiY29tbWVudHMifQ.c2ln"}`,
+	}},
+	"TicketRevisionsRequest": {func() validator { return &data.TicketRevisionsRequest{} }, []string{
+		`{"ticketId":1,"pageSize":10,"pageToken":"eyJsIjoidGlja2V0X3JldmlzaW9ucyJ9.c2ln"}`,
+	}},
+	"ListMacrosRequest": {func() validator { return &data.ListMacrosRequest{} }, []string{
+		`{"pageSize":10,"pageToken":"eyJsIjoibWFjcm9zIn0.c2ln"}`,
+	}},
+	"SnoozeTicketRequest": {func() validator { return &data.SnoozeTicketRequest{} }, []string{
+		`{"ID":1,"until":"2030-01-01T00:00:00Z"}`,
+	}},
+	"AssignTicketRequest": {func() validator { return &data.AssignTicketRequest{} }, []string{
+		`{"ID":1,"assignee":"agent-a","actor":"agent-a"}`,
+		`{"ID":1,"assignee":"agent-b","reassign":true,"actor":"lead@example.com"}`,
+	}},
+	"UnassignTicketRequest": {func() validator { return &data.UnassignTicketRequest{} }, []string{
+		`{"ID":1,"assignee":"agent-a","actor":"agent-a"}`,
+	}},
+	"FilterByAssigneeRequest": {func() validator { return &data.FilterByAssigneeRequest{} }, []string{
+		`{"issuer":"Microservice-A","assignee":"agent-a","status":"NEW","pageNumber":1,"pageSize":10}`,
+	}},
+	"CreateMacroRequest": {func() validator { return &data.CreateMacroRequest{} }, []string{
+		`{"name":"Ask for logs","status":"WAITING_ON_CUSTOMER","comment":"Could you send us the logs?"}`,
+		`{"name":"Ask for logs","comment":"Could you send us the logs?","translations":{"fa-IR":"لاگ‌ها؟"}}`,
+	}},
+	"ApplyMacroRequest": {func() validator { return &data.ApplyMacroRequest{} }, []string{
+		`{"ticketId":1,"macroId":1,"actor":"agent@example.com"}`,
+		`{"ticketId":1,"macroId":1,"actor":"agent@example.com","languages":"fa-IR, en;q=0.8"}`,
+	}},
+	"DuplicateTicketsRequest": {func() validator { return &data.DuplicateTicketsRequest{} }, []string{
+		`{"issuer":"Microservice-A","fromDate":"2020-10-01T00:00:00Z","minSimilarity":0.7,"limit":20}`,
+	}},
+	"MergeTicketsRequest": {func() validator { return &data.MergeTicketsRequest{} }, []string{
+		`{"targetId":1,"duplicateIds":[2,3],"actor":"agent@example.com"}`,
+	}},
+	"SaveIssuerSettingsRequest": {func() validator { return &data.SaveIssuerSettingsRequest{} }, []string{
+		`{"issuer":"Microservice-A","defaultImportanceLevel":"LOW","defaultStatus":"NEW","tier":"GOLD",` +
+			`"publicIdSalt":"salt","publicIdMinLength":6}`,
+	}},
+	"PublicTicketRequest": {func() validator { return &data.PublicTicketRequest{} }, []string{
+		`{"issuer":"Microservice-A","publicId":"zkWe3e"}`,
+	}},
+	"CreateIncidentRequest": {func() validator { return &data.CreateIncidentRequest{} }, []string{
+		`{"title":"Payments are down","description":"Card payments fail with timeouts."}`,
+	}},
+	"AttachIncidentTicketsRequest": {func() validator { return &data.AttachIncidentTicketsRequest{} }, []string{
+		`{"incidentId":1,"ticketIds":[1,2,3]}`,
+	}},
+	"ResolveIncidentRequest": {func() validator { return &data.ResolveIncidentRequest{} }, []string{
+		`{"incidentId":1,"message":"Hi {{.Ticket.Owner}}, {{.Incident.Title}} is resolved.","resolveTickets":true,` +
+			`"actor":"agent@example.com"}`,
+	}},
+	"ImportTicketRequest": {func() validator { return &data.ImportTicketRequest{} }, []string{
+		`{"ticket":{"issuer":"Microservice-A","owner":"user@example.com","subject":"Technical Problem",` +
+			`"content":"Hello!","importanceLevel":"LOW","status":"NEW","comments":[{"owner":"user@example.com",` +
+			`"content":"Any news?","authorType":"CUSTOMER"}]}}`,
+	}},
+	"CreateAttachmentRequest": {func() validator { return &data.CreateAttachmentRequest{} }, []string{
+		`{"ticketId":1,"commentId":2,"owner":"user@example.com","name":"invoice.pdf","contentType":"application/pdf"}`,
+	}},
+	"UploadAttachmentChunkRequest": {func() validator { return &data.UploadAttachmentChunkRequest{} }, []string{
+		`{"id":1,"offset":0,"content":"JVBERi0xLjQK","last":true}`,
+	}},
+	"ListAttachmentsRequest": {func() validator { return &data.ListAttachmentsRequest{} }, []string{
+		`{"ticketId":1,"pageSize":10,"pageToken":"eyJsIjoiYXR0YWNobWVudHMifQ.c2ln"}`,
+	}},
+	"RotateAPIKeysRequest": {func() validator { return &data.RotateAPIKeysRequest{} }, []string{
+		`{"operator":"ops@example.com","reason":"Leaked in a build log","issuer":"Microservice-A"}`,
+	}},
+	"ArchiveTicketsRequest": {func() validator { return &data.ArchiveTicketsRequest{} }, []string{
+		`{"operator":"ops@example.com","reason":"Catching up after an outage","olderThan":"2160h"}`,
+	}},
+	"AdminAuditLogsRequest": {func() validator { return &data.AdminAuditLogsRequest{} }, []string{
+		`{"action":"FLUSH_CACHES","limit":20,"pageToken":"eyJsIjoiYXVkaXRfbG9ncyJ9.c2ln"}`,
+	}},
+	"RecomputeSLARequest": {func() validator { return &data.RecomputeSLARequest{} }, []string{
+		`{"operator":"ops@example.com","reason":"Corrected gold targets","issuer":"Microservice-A",` +
+			`"fromDate":"2020-01-01T00:00:00Z","toDate":"2020-02-01T00:00:00Z","batchSize":100}`,
+	}},
+	"CreateOnCallShiftRequest": {func() validator { return &data.CreateOnCallShiftRequest{} }, []string{
+		`{"issuer":"Microservice-A","agent":"agent-a","startsAt":"2030-01-01T18:00:00Z",` +
+			`"endsAt":"2030-01-02T09:00:00Z"}`,
+	}},
+	"DeleteOnCallShiftRequest": {func() validator { return &data.DeleteOnCallShiftRequest{} }, []string{
+		`{"issuer":"Microservice-A","ID":1}`,
+	}},
+}
+
+var _ = Describe("Validation", func() {
+	for name, request := range requests {
+		name, request := name, request
+
+		Context("When "+name+" decoded and validated", func() {
+			seeds := make([][]byte, 0, len(request.seeds)+1)
+			for _, seed := range request.seeds {
+				seeds = append(seeds, []byte(seed))
+			}
+			seeds = append(seeds, []byte(`{}`))
+
+			It("Should not panic on malformed bodies", func() {
+				test.Fuzz(GinkgoRandomSeed(), seeds, 2000, func(input []byte) {
+					Ω(func() {
+						r := request.new()
+						if e := json.Unmarshal(input, r); e == nil {
+							_ = r.Validate()
+						}
+					}).ShouldNot(Panic(), "body: %q", input)
+				})
+			})
+
+			It("Should accept the requests it accepted once again", func() {
+				test.Fuzz(GinkgoRandomSeed(), seeds, 2000, func(input []byte) {
+					r := request.new()
+					if e := json.Unmarshal(input, r); e != nil || r.Validate() != nil {
+						return
+					}
+
+					validated, _ := json.Marshal(r)
+					again := request.new()
+					Ω(json.Unmarshal(validated, again)).Should(Succeed(), "body: %q", input)
+					Ω(again.Validate()).Should(BeNil(), "body: %q", input)
+					Ω(json.Marshal(again)).Should(MatchJSON(validated), "body: %q", input)
+				})
+			})
+		})
+	}
+})
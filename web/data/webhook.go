@@ -0,0 +1,163 @@
+package data
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// webhookSourceNamePattern is the form of webhook source names, they appear in URLs and in owners of comments.
+var webhookSourceNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,50}$`)
+
+// minWebhookSecretLength is the minimum length of the secrets webhook deliveries are signed with.
+const minWebhookSecretLength = 16
+
+// SaveWebhookSourceRequest model definition.
+type SaveWebhookSourceRequest struct {
+	Name   string `json:"name"`
+	Issuer string `json:"issuer"`
+	// Owner owns the tickets the source opens, defaults to the name of the source.
+	Owner string `json:"owner"`
+	// ImportanceLevel is given to the tickets the source opens without one, defaults to MEDIUM.
+	ImportanceLevel models.TicketImportanceLevel `json:"importanceLevel"`
+	Secret          string                       `json:"secret"`
+}
+
+// Validate validates the request.
+func (r *SaveWebhookSourceRequest) Validate() *errors.Type {
+	r.Issuer = normalize(r.Issuer)
+	r.Owner = normalize(r.Owner)
+
+	if !webhookSourceNamePattern.MatchString(r.Name) {
+		return errors.InvalidArgument("name.not_valid", "")
+	}
+
+	if isBlank(r.Issuer) {
+		return errors.InvalidArgument("issuer.is_required", "")
+	}
+
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	if isBlank(r.Owner) {
+		r.Owner = r.Name
+	}
+
+	if len(r.Owner) > 50 {
+		return errors.InvalidArgument("owner.invalid_length", "")
+	}
+
+	if r.ImportanceLevel == "" {
+		r.ImportanceLevel = models.TicketImportanceLevelMedium
+	}
+
+	if !r.ImportanceLevel.IsValid() {
+		return errors.InvalidArgument("importanceLevel.not_valid", "")
+	}
+
+	if len(r.Secret) < minWebhookSecretLength || len(r.Secret) > 255 {
+		return errors.InvalidArgument("secret.invalid_length", "")
+	}
+
+	return nil
+}
+
+// AsWebhookSource converts this request model into webhook source model. Should be called after Validate.
+func (r *SaveWebhookSourceRequest) AsWebhookSource() *models.WebhookSource {
+	return &models.WebhookSource{
+		Name:            r.Name,
+		Issuer:          r.Issuer,
+		Owner:           r.Owner,
+		ImportanceLevel: r.ImportanceLevel,
+		Secret:          r.Secret,
+	}
+}
+
+// WebhookSourceRequest model definition.
+type WebhookSourceRequest struct {
+	Name string `json:"name"`
+}
+
+// Validate validates the request.
+func (r *WebhookSourceRequest) Validate() *errors.Type {
+	if !webhookSourceNamePattern.MatchString(r.Name) {
+		return errors.InvalidArgument("name.not_valid", "")
+	}
+
+	return nil
+}
+
+// WebhookSourceResponse model definition. The secret of the source is never sent back.
+type WebhookSourceResponse struct {
+	Name            string                       `json:"name"`
+	Issuer          string                       `json:"issuer"`
+	Owner           string                       `json:"owner"`
+	ImportanceLevel models.TicketImportanceLevel `json:"importanceLevel"`
+	CreatedAt       string                       `json:"createdAt"`
+	ModifiedAt      string                       `json:"modifiedAt"`
+}
+
+// LoadFromWebhookSource populates the fields of current model from provided webhook source.
+func (r *WebhookSourceResponse) LoadFromWebhookSource(source *models.WebhookSource) {
+	r.Name = source.Name
+	r.Issuer = source.Issuer
+	r.Owner = source.Owner
+	r.ImportanceLevel = source.ImportanceLevel
+	r.CreatedAt = source.CreatedAt.Format(time.RFC3339Nano)
+	r.ModifiedAt = source.ModifiedAt.Format(time.RFC3339Nano)
+}
+
+// InboundWebhookAction is what an inbound webhook delivery does to a ticket.
+type InboundWebhookAction string
+
+// Different inbound webhook action instances.
+const (
+	InboundWebhookActionCreate  InboundWebhookAction = "CREATE"
+	InboundWebhookActionComment InboundWebhookAction = "COMMENT"
+	InboundWebhookActionClose   InboundWebhookAction = "CLOSE"
+)
+
+// InboundWebhook model definition, the payload of inbound webhook deliveries. The external key is how the source
+// identifies the ticket, tickets are opened for new keys and commented on or closed by the keys they were opened for.
+type InboundWebhook struct {
+	Action      InboundWebhookAction `json:"action"`
+	ExternalKey string               `json:"externalKey"`
+	// Subject and ImportanceLevel are used by CREATE only.
+	Subject         string                       `json:"subject"`
+	ImportanceLevel models.TicketImportanceLevel `json:"importanceLevel"`
+	// Content is the content of the ticket for CREATE and of the comment for COMMENT.
+	Content string `json:"content"`
+	// Author is who made the change at the source, if known.
+	Author string `json:"author"`
+}
+
+// Validate validates the request. Subject and content are validated along with the tickets and comments they end up
+// in.
+func (r *InboundWebhook) Validate() *errors.Type {
+	r.ExternalKey = strings.TrimSpace(r.ExternalKey)
+	r.Author = normalize(r.Author)
+
+	if r.Action != InboundWebhookActionCreate && r.Action != InboundWebhookActionComment &&
+		r.Action != InboundWebhookActionClose {
+
+		return errors.InvalidArgument("action.not_valid", "")
+	}
+
+	if r.ExternalKey == "" || len(r.ExternalKey) > 255 {
+		return errors.InvalidArgument("externalKey.invalid_length", "")
+	}
+
+	if r.ImportanceLevel != "" && !r.ImportanceLevel.IsValid() {
+		return errors.InvalidArgument("importanceLevel.not_valid", "")
+	}
+
+	if len(r.Author) > 50 {
+		return errors.InvalidArgument("author.invalid_length", "")
+	}
+
+	return nil
+}
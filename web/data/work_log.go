@@ -0,0 +1,110 @@
+package data
+
+import (
+	"time"
+	"unicode/utf8"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// maxWorkLogMinutes is the longest duration a single work log may record.
+const maxWorkLogMinutes = 24 * 60
+
+// LogWorkRequest model definition.
+type LogWorkRequest struct {
+	TicketID        int64  `json:"ticketId"`
+	Agent           string `json:"agent"`
+	DurationMinutes int    `json:"durationMinutes"`
+	Note            string `json:"note"`
+}
+
+// Validate validates the request.
+func (r *LogWorkRequest) Validate() *errors.Type {
+	if r.TicketID < 1 {
+		return errors.InvalidArgument("ticketId.not_valid", "")
+	}
+
+	r.Agent = normalize(r.Agent)
+	r.Note = normalize(r.Note)
+
+	if isBlank(r.Agent) {
+		return errors.InvalidArgument("agent.is_required", "")
+	}
+
+	if len(r.Agent) > 50 {
+		return errors.InvalidArgument("agent.invalid_length", "")
+	}
+
+	if r.DurationMinutes < 1 || r.DurationMinutes > maxWorkLogMinutes {
+		return errors.InvalidArgument("durationMinutes.not_valid", "")
+	}
+
+	if utf8.RuneCountInString(r.Note) > 1000 {
+		return errors.InvalidArgument("note.invalid_length", "")
+	}
+
+	return nil
+}
+
+// AsWorkLog converts this request model into work log model.
+func (r *LogWorkRequest) AsWorkLog() *models.WorkLog {
+	return &models.WorkLog{
+		TicketID: r.TicketID,
+		Agent:    r.Agent,
+		Duration: time.Duration(r.DurationMinutes) * time.Minute,
+		Note:     r.Note,
+	}
+}
+
+// WorkLogsRequest model definition.
+type WorkLogsRequest struct {
+	TicketID int64 `json:"ticketId"`
+}
+
+// Validate validates the request.
+func (r *WorkLogsRequest) Validate() *errors.Type {
+	if r.TicketID < 1 {
+		return errors.InvalidArgument("ticketId.not_valid", "")
+	}
+
+	return nil
+}
+
+// WorkLogResponse model definition.
+type WorkLogResponse struct {
+	ID              int64  `json:"ID"`
+	TicketID        int64  `json:"ticketId"`
+	Agent           string `json:"agent"`
+	DurationMinutes int    `json:"durationMinutes"`
+	Note            string `json:"note,omitempty"`
+	LoggedAt        string `json:"loggedAt"`
+}
+
+// LoadFromWorkLog populates the fields of current model from provided work log.
+func (r *WorkLogResponse) LoadFromWorkLog(log *models.WorkLog) {
+	r.ID = log.ID
+	r.TicketID = log.TicketID
+	r.Agent = log.Agent
+	r.DurationMinutes = int(log.Duration / time.Minute)
+	r.Note = log.Note
+	r.LoggedAt = log.LoggedAt.Format(time.RFC3339Nano)
+}
+
+// WorkLogsResponse model definition.
+type WorkLogsResponse struct {
+	Logs         []*WorkLogResponse `json:"logs"`
+	TotalMinutes int                `json:"totalMinutes"`
+}
+
+// LoadFromWorkLogs populates the fields of current model from provided work logs.
+func (r *WorkLogsResponse) LoadFromWorkLogs(logs []*models.WorkLog) {
+	r.Logs = make([]*WorkLogResponse, 0, len(logs))
+	for _, log := range logs {
+		logResponse := &WorkLogResponse{}
+		logResponse.LoadFromWorkLog(log)
+
+		r.Logs = append(r.Logs, logResponse)
+		r.TotalMinutes += logResponse.DurationMinutes
+	}
+}
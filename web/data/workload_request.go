@@ -0,0 +1,68 @@
+package data
+
+import (
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+)
+
+// maxWorkloadPeriods bounds the number of periods of a workload series, so hourly series span at most about a year.
+const maxWorkloadPeriods = 9000
+
+// WorkloadRequest model definition.
+type WorkloadRequest struct {
+	Issuer      string                     `json:"issuer"`
+	Granularity models.WorkloadGranularity `json:"granularity"`
+	ByIssuer    bool                       `json:"byIssuer"`
+	FromDate    string                     `json:"fromDate"`
+	ToDate      string                     `json:"toDate"`
+}
+
+// Validate validates the request.
+func (r *WorkloadRequest) Validate() *errors.Type {
+	if len(r.Issuer) > 50 {
+		return errors.InvalidArgument("issuer.invalid_length", "")
+	}
+
+	if r.Granularity == "" {
+		r.Granularity = models.WorkloadGranularityDay
+	}
+
+	if !r.Granularity.IsValid() {
+		return errors.InvalidArgument("granularity.invalid", "")
+	}
+
+	if r.FromDate == "" {
+		r.FromDate = time.Now().UTC().AddDate(0, 0, -30).Format(time.RFC3339Nano)
+	}
+
+	if r.ToDate == "" {
+		r.ToDate = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+
+	fromDate, e := time.Parse(time.RFC3339Nano, r.FromDate)
+	if e != nil {
+		return errors.InvalidArgument("fromDate.not_valid", "")
+	}
+
+	toDate, e := time.Parse(time.RFC3339Nano, r.ToDate)
+	if e != nil {
+		return errors.InvalidArgument("toDate.not_valid", "")
+	}
+
+	if !toDate.After(fromDate) {
+		return errors.InvalidArgument("toDate.not_after_fromDate", "")
+	}
+
+	period := 24 * time.Hour
+	if r.Granularity == models.WorkloadGranularityHour {
+		period = time.Hour
+	}
+
+	if toDate.Sub(fromDate)/period > maxWorkloadPeriods {
+		return errors.InvalidArgument("period.too_long", "")
+	}
+
+	return nil
+}
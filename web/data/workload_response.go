@@ -0,0 +1,35 @@
+package data
+
+import (
+	"time"
+
+	"github.com/jibitters/kiosk/models"
+)
+
+// WorkloadResponse model definition.
+type WorkloadResponse struct {
+	Granularity models.WorkloadGranularity `json:"granularity"`
+	Rows        []*WorkloadRowResponse     `json:"rows"`
+}
+
+// WorkloadRowResponse model definition. The period is the start of the period in RFC3339 format.
+type WorkloadRowResponse struct {
+	Period      string `json:"period"`
+	Issuer      string `json:"issuer,omitempty"`
+	Arrivals    int64  `json:"arrivals"`
+	Resolutions int64  `json:"resolutions"`
+}
+
+// LoadFromRows populates the fields of current model from provided workload rows.
+func (r *WorkloadResponse) LoadFromRows(granularity models.WorkloadGranularity, rows []*models.WorkloadRow) {
+	r.Granularity = granularity
+	r.Rows = make([]*WorkloadRowResponse, 0, len(rows))
+	for _, row := range rows {
+		r.Rows = append(r.Rows, &WorkloadRowResponse{
+			Period:      row.Period.UTC().Format(time.RFC3339),
+			Issuer:      row.Issuer,
+			Arrivals:    row.Arrivals,
+			Resolutions: row.Resolutions,
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package web_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/web/data"
+	"github.com/jibitters/kiosk/web/handlers"
+	"github.com/nats-io/nats-server/v2/server"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	nc "github.com/nats-io/nats.go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Tickets CSV export", func() {
+	var natsServer *server.Server
+	var natsClient *nc.Conn
+	var httpServer *httptest.Server
+
+	// pages are replied to the filter requests one after another, the ones after the first after the delay.
+	var pages []interface{}
+	var delay time.Duration
+
+	BeforeEach(func() {
+		opts := natsserver.DefaultTestOptions
+		opts.Port = server.RANDOM_PORT
+		natsServer = natsserver.RunServer(&opts)
+
+		client, e := nc.Connect(natsServer.ClientURL())
+		Ω(e).Should(BeNil())
+		natsClient = client
+
+		_, e = natsClient.Subscribe("kiosk.tickets.filter", func(msg *nc.Msg) {
+			filterTicketsRequest := &data.FilterTicketsRequest{}
+			_ = json.Unmarshal(msg.Data, filterTicketsRequest)
+
+			if filterTicketsRequest.PageNumber > 1 {
+				time.Sleep(delay)
+			}
+
+			reply, _ := json.Marshal(pages[filterTicketsRequest.PageNumber-1])
+			_ = msg.Respond(reply)
+		})
+		Ω(e).Should(BeNil())
+
+		httpServer = httptest.NewUnstartedServer(handlers.NewTicketHandler(zap.S(), natsClient).ExportCSV())
+		httpServer.Config.ConnContext = handlers.ConnContext
+		httpServer.Config.WriteTimeout = 100 * time.Millisecond
+		httpServer.Start()
+	})
+
+	AfterEach(func() {
+		httpServer.Close()
+		natsClient.Close()
+		natsServer.Shutdown()
+	})
+
+	page := func(id int64, hasNextPage bool) *data.FilterTicketsResponse {
+		return &data.FilterTicketsResponse{Tickets: []*data.TicketResponse{{ID: id, Issuer: "Microservice-A"}},
+			HasNextPage: hasNextPage}
+	}
+
+	Context("When a page fails to load", func() {
+		It("Should cut the export short with a trailing error row", func() {
+			pages, delay = []interface{}{page(1, true), errors.InternalServerError("unknown", "")}, 0
+
+			response, e := http.Get(httpServer.URL)
+			Ω(e).Should(BeNil())
+			defer response.Body.Close()
+			Ω(response.StatusCode).Should(Equal(http.StatusOK))
+
+			body, e := ioutil.ReadAll(response.Body)
+			Ω(e).ShouldNot(BeNil())
+			Ω(string(body)).Should(ContainSubstring("\n1,Microservice-A,"))
+			Ω(string(body)).Should(MatchRegexp(`\n#error,unknown,[0-9a-f-]{36},\n$`))
+		})
+	})
+
+	Context("When exporting for longer than the write timeout", func() {
+		It("Should export all pages", func() {
+			// Pages after the first are written after the write timeout since the request got read.
+			pages, delay = []interface{}{page(1, true), page(2, true), page(3, false)}, 60*time.Millisecond
+
+			response, e := http.Get(httpServer.URL)
+			Ω(e).Should(BeNil())
+			defer response.Body.Close()
+
+			body, e := ioutil.ReadAll(response.Body)
+			Ω(e).Should(BeNil())
+			Ω(string(body)).Should(ContainSubstring("\n3,Microservice-A,"))
+			Ω(string(body)).ShouldNot(ContainSubstring("#error"))
+		})
+	})
+})
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// AdminToken is an admin API token along with the name of the operator it is issued to.
+type AdminToken struct {
+	Operator string
+	Token    string
+}
+
+// Admin configures the admin API runbooks call, which is disabled when Tokens is empty. Requests must carry one of
+// the tokens as an Authorization bearer header.
+type Admin struct {
+	Tokens []AdminToken
+}
+
+// authenticate returns back the operator of the token the request carries, if it carries any of the tokens.
+func (a *Admin) authenticate(r *http.Request) (string, bool) {
+	authorization := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, "Bearer ") {
+		return "", false
+	}
+
+	token := strings.TrimPrefix(authorization, "Bearer ")
+	for _, t := range a.Tokens {
+		if subtle.ConstantTimeCompare([]byte(t.Token), []byte(token)) == 1 {
+			return t.Operator, true
+		}
+	}
+
+	return "", false
+}
+
+// AdminHandler is the handler implementation of admin actions related resource.
+type AdminHandler struct {
+	logger     *zap.SugaredLogger
+	natsClient *nc.Conn
+	admin      Admin
+}
+
+// NewAdminHandler returns back a newly created and ready to use AdminHandler.
+func NewAdminHandler(logger *zap.SugaredLogger, natsClient *nc.Conn, admin Admin) *AdminHandler {
+	return &AdminHandler{logger: logger, natsClient: natsClient, admin: admin}
+}
+
+// FlushCaches drops the caches of all kiosk instances.
+func (h *AdminHandler) FlushCaches() http.HandlerFunc {
+	return h.authenticated(func(w http.ResponseWriter, r *http.Request, operator string) {
+		in := attributeToOperator(r, operator)
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.admin.caches.flush", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	})
+}
+
+// RotateAPIKeys replaces the API keys of a tenant with a new one.
+func (h *AdminHandler) RotateAPIKeys() http.HandlerFunc {
+	return h.authenticated(func(w http.ResponseWriter, r *http.Request, operator string) {
+		in := attributeToOperator(r, operator)
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.admin.api_keys.rotate", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	})
+}
+
+// ArchiveTickets enqueues archiving the closed tickets not modified for the requested duration.
+func (h *AdminHandler) ArchiveTickets() http.HandlerFunc {
+	return h.authenticated(func(w http.ResponseWriter, r *http.Request, operator string) {
+		in := attributeToOperator(r, operator)
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.admin.tickets.archive", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	})
+}
+
+// RecomputeSLA enqueues resolving the SLA deadlines of the tickets created within a date range again.
+func (h *AdminHandler) RecomputeSLA() http.HandlerFunc {
+	return h.authenticated(func(w http.ResponseWriter, r *http.Request, operator string) {
+		in := attributeToOperator(r, operator)
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.admin.sla.recompute", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	})
+}
+
+// AuditLogs returns back the most recent audit logs of the admin actions, optionally of a single action. Older ones are
+// continued by pageToken.
+func (h *AdminHandler) AuditLogs() http.HandlerFunc {
+	return h.authenticated(func(w http.ResponseWriter, r *http.Request, _ string) {
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		adminAuditLogsRequest := data.AdminAuditLogsRequest{
+			Action:    models.AdminAction(r.URL.Query().Get("action")),
+			Limit:     limit,
+			PageToken: r.URL.Query().Get("pageToken"),
+		}
+
+		in, _ := json.Marshal(adminAuditLogsRequest)
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.admin.audit_logs", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	})
+}
+
+// VerifyAuditLogs verifies the chain of audit logs, reporting the first audit log tampered with, if any.
+func (h *AdminHandler) VerifyAuditLogs() http.HandlerFunc {
+	return h.authenticated(func(w http.ResponseWriter, r *http.Request, _ string) {
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.admin.audit_logs.verify", []byte("{}"))
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	})
+}
+
+// authenticated rejects the requests not carrying any of the admin tokens before they reach the handler, which gets
+// the operator of the token.
+func (h *AdminHandler) authenticated(handler func(http.ResponseWriter, *http.Request, string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		operator, ok := h.admin.authenticate(r)
+		if !ok {
+			writeError(w, errors.Unauthorized(""))
+			return
+		}
+
+		handler(w, r, operator)
+	}
+}
+
+// attributeToOperator reads the request body and sets its operator to the one of the token authenticating the
+// request, whatever the body says, so audit logs can not be attributed to someone else. Malformed bodies are left
+// intact, so they get reported by the service.
+func attributeToOperator(r *http.Request, operator string) []byte {
+	in, _ := ioutil.ReadAll(r.Body)
+
+	body := make(map[string]json.RawMessage)
+	if e := json.Unmarshal(in, &body); e != nil || body == nil {
+		return in
+	}
+
+	body["operator"], _ = json.Marshal(operator)
+	out, _ := json.Marshal(body)
+
+	return out
+}
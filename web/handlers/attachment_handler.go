@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// AttachmentHandler is the handler implementation of attachments related resource.
+type AttachmentHandler struct {
+	logger     *zap.SugaredLogger
+	natsClient *nc.Conn
+}
+
+// NewAttachmentHandler returns back a newly created and ready to use AttachmentHandler.
+func NewAttachmentHandler(logger *zap.SugaredLogger, natsClient *nc.Conn) *AttachmentHandler {
+	return &AttachmentHandler{logger: logger, natsClient: natsClient}
+}
+
+// Upload attaches the file sent as the body of the request, with its type in the Content-Type header, to the ticket
+// or one of its comments. The body is streamed to the attachment service in chunks, uploads cut short are cleaned up
+// by the service later on.
+func (h *AttachmentHandler) Upload() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ticketID, _ := strconv.ParseInt(r.URL.Query().Get("ticketId"), 10, 64)
+		commentID, _ := strconv.ParseInt(r.URL.Query().Get("commentId"), 10, 64)
+		createAttachmentRequest := data.CreateAttachmentRequest{
+			TicketID:    ticketID,
+			CommentID:   commentID,
+			Owner:       r.URL.Query().Get("owner"),
+			Name:        r.URL.Query().Get("name"),
+			ContentType: r.Header.Get("Content-Type"),
+		}
+
+		in, _ := json.Marshal(createAttachmentRequest)
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.attachments.create", in)
+		if !ok {
+			return
+		}
+
+		attachmentResponse := &data.AttachmentResponse{}
+		_ = json.Unmarshal(response.Data, attachmentResponse)
+
+		reader := bufio.NewReaderSize(r.Body, data.AttachmentChunkSize)
+		chunk := make([]byte, data.AttachmentChunkSize)
+		var offset int64
+		for {
+			n, e := io.ReadFull(reader, chunk)
+			if e != nil && e != io.EOF && e != io.ErrUnexpectedEOF {
+				et := errors.InvalidRequestBody()
+				h.logger.Warn(et.FingerPrint, ": Could not read attachment content: ", e.Error())
+
+				writeError(w, et)
+				return
+			}
+
+			// The chunk is the last one when nothing is left to read after it.
+			_, e = reader.Peek(1)
+			uploadAttachmentChunkRequest := data.UploadAttachmentChunkRequest{
+				ID:      attachmentResponse.ID,
+				Offset:  offset,
+				Content: chunk[:n],
+				Last:    e != nil,
+			}
+
+			in, _ = json.Marshal(uploadAttachmentChunkRequest)
+			response, ok = request(h.logger, h.natsClient, w, r, "kiosk.attachments.upload", in)
+			if !ok {
+				return
+			}
+
+			if uploadAttachmentChunkRequest.Last {
+				_, _ = w.Write(response.Data)
+				return
+			}
+
+			offset += int64(n)
+		}
+	}
+}
+
+// Load returns back the attachment with provided id, or the attachments of the ticket with provided ticket id, a page
+// of them with pageSize, continued by pageToken.
+func (h *AttachmentHandler) Load() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		subject := "kiosk.attachments.load"
+		id, _ := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+		in, _ := json.Marshal(data.ID{ID: id})
+
+		if r.URL.Query().Get("id") == "" {
+			subject = "kiosk.attachments.list"
+			ticketID, _ := strconv.ParseInt(r.URL.Query().Get("ticketId"), 10, 64)
+			in, _ = json.Marshal(data.ListAttachmentsRequest{PageRequest: pageRequest(r), TicketID: ticketID})
+		}
+
+		response, ok := request(h.logger, h.natsClient, w, r, subject, in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// Download streams the content of the attachment with provided id, chunk by chunk.
+func (h *AttachmentHandler) Download() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+
+		in, _ := json.Marshal(data.ID{ID: id})
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.attachments.load", in)
+		if !ok {
+			return
+		}
+
+		attachmentResponse := &data.AttachmentResponse{}
+		_ = json.Unmarshal(response.Data, attachmentResponse)
+
+		if !attachmentResponse.Completed {
+			writeError(w, errors.PreconditionFailed("attachment.not_completed", ""))
+			return
+		}
+
+		disposition := mime.FormatMediaType("attachment", map[string]string{"filename": attachmentResponse.Name})
+		w.Header().Set("Content-Type", attachmentResponse.ContentType)
+		w.Header().Set("Content-Length", strconv.FormatInt(attachmentResponse.Size, 10))
+		w.Header().Set("Content-Disposition", disposition)
+		w.WriteHeader(http.StatusOK)
+
+		for offset := int64(0); offset < attachmentResponse.Size; {
+			in, _ = json.Marshal(data.DownloadAttachmentChunkRequest{ID: id, Offset: offset})
+
+			// Headers are already sent, so on failures the only option is to cut the stream short.
+			response, e := h.natsClient.RequestWithContext(r.Context(), "kiosk.attachments.download", in)
+			if e != nil {
+				h.logger.Warn("Could not load next chunk of attachment ", id, ": ", e.Error())
+				return
+			}
+
+			if et, isError := errors.FromEnvelope(response.Data); isError {
+				h.logger.Warn(et.FingerPrint, ": Could not load next chunk of attachment ", id)
+				return
+			}
+
+			downloadAttachmentChunkResponse := &data.DownloadAttachmentChunkResponse{}
+			_ = json.Unmarshal(response.Data, downloadAttachmentChunkResponse)
+			if len(downloadAttachmentChunkResponse.Content) == 0 {
+				return
+			}
+
+			if _, e := w.Write(downloadAttachmentChunkResponse.Content); e != nil {
+				return
+			}
+
+			offset += int64(len(downloadAttachmentChunkResponse.Content))
+		}
+	}
+}
+
+// Delete deletes the attachment with provided id.
+func (h *AttachmentHandler) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+
+		in, _ := json.Marshal(data.ID{ID: id})
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.attachments.delete", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
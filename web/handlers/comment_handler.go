@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 
-	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/web/data"
 	nc "github.com/nats-io/nats.go"
 	"go.uber.org/zap"
 )
@@ -26,27 +28,82 @@ func (h *CommentHandler) Create() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		in, _ := ioutil.ReadAll(r.Body)
 
-		response, e := h.natsClient.RequestWithContext(r.Context(), "kiosk.comments.create", in)
-		if e != nil {
-			if e == nc.ErrTimeout {
-				et := errors.RequestTimeout("")
-				writeError(w, et)
-			} else {
-				et := errors.InternalServerError("unknown", "")
-				h.logger.Error(et.FingerPrint, ": ", e.Error())
-				writeError(w, et)
-			}
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.comments.create", attributeToAPI(in))
+		if !ok {
+			return
+		}
 
+		writeConsistencyToken(w, response)
+		writeNoContent(w)
+	}
+}
+
+// Filter returns back the comments of a ticket, optionally restricted to some author types and sources and ordered by
+// the keys of order_by, e.g. createdAt for the oldest or createdAt:desc for the newest first. With count_only or
+// exists only their number or whether there is any is returned back. With around_comment_id or latest only the window
+// comments right before and after the comment, or the newest one, are returned back. Otherwise a page of them is
+// returned back with pageSize, continued by pageToken.
+func (h *CommentHandler) Filter() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ticketID, _ := strconv.ParseInt(r.URL.Query().Get("ticketId"), 10, 64)
+
+		countOnly, _ := strconv.ParseBool(r.URL.Query().Get("count_only"))
+		exists, _ := strconv.ParseBool(r.URL.Query().Get("exists"))
+
+		aroundCommentID, _ := strconv.ParseInt(r.URL.Query().Get("around_comment_id"), 10, 64)
+		latest, _ := strconv.ParseBool(r.URL.Query().Get("latest"))
+		window, _ := strconv.Atoi(r.URL.Query().Get("window"))
+
+		filterCommentsRequest := data.FilterCommentsRequest{PageRequest: pageRequest(r), TicketID: ticketID,
+			CountOnly: countOnly, Exists: exists, OrderBy: r.URL.Query().Get("order_by"),
+			AroundCommentID: aroundCommentID, Latest: latest, Window: window}
+		for _, authorType := range r.URL.Query()["authorType"] {
+			filterCommentsRequest.AuthorTypes = append(filterCommentsRequest.AuthorTypes,
+				models.CommentAuthorType(authorType))
+		}
+
+		for _, source := range r.URL.Query()["source"] {
+			filterCommentsRequest.Sources = append(filterCommentsRequest.Sources, models.CommentSource(source))
+		}
+
+		in, _ := json.Marshal(filterCommentsRequest)
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.comments.filter", in)
+		if !ok {
 			return
 		}
 
-		et := &errors.Type{}
-		_ = json.Unmarshal(response.Data, et)
-		if et.FingerPrint != "" {
-			writeError(w, et)
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// attributeToAPI marks the comment of the request body as arrived through the API. Malformed bodies are left intact,
+// so they get reported by the service.
+func attributeToAPI(in []byte) []byte {
+	createCommentRequest := &data.CreateCommentRequest{}
+	if e := json.Unmarshal(in, createCommentRequest); e != nil {
+		return in
+	}
+
+	createCommentRequest.Source = models.CommentSourceAPI
+	out, _ := json.Marshal(createCommentRequest)
+
+	return out
+}
+
+// DiffRevisions returns back the word by word diff between the revisions rev_a and rev_b of the content of the
+// comment with id comment_id.
+func (h *CommentHandler) DiffRevisions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		commentID, _ := strconv.ParseInt(r.URL.Query().Get("comment_id"), 10, 64)
+		revA, _ := strconv.Atoi(r.URL.Query().Get("rev_a"))
+		revB, _ := strconv.Atoi(r.URL.Query().Get("rev_b"))
+
+		in, _ := json.Marshal(data.DiffCommentRevisionsRequest{CommentID: commentID, RevA: revA, RevB: revB})
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.comments.revisions.diff", in)
+		if !ok {
 			return
 		}
 
-		writeNoContent(w)
+		_, _ = w.Write(response.Data)
 	}
 }
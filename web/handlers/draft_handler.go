@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// DraftHandler is the handler implementation of ticket drafts related resource.
+type DraftHandler struct {
+	logger     *zap.SugaredLogger
+	natsClient *nc.Conn
+}
+
+// NewDraftHandler returns back a newly created and ready to use DraftHandler.
+func NewDraftHandler(logger *zap.SugaredLogger, natsClient *nc.Conn) *DraftHandler {
+	return &DraftHandler{logger: logger, natsClient: natsClient}
+}
+
+// Save autosaves the draft of a requester, replacing the previous one.
+func (h *DraftHandler) Save() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.drafts.save", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
+
+// Load returns back the draft of a requester.
+func (h *DraftHandler) Load() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := json.Marshal(h.requester(r))
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.drafts.load", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// Delete discards the draft of a requester.
+func (h *DraftHandler) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := json.Marshal(h.requester(r))
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.drafts.delete", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
+
+func (h *DraftHandler) requester(r *http.Request) *data.TicketDraftRequest {
+	return &data.TicketDraftRequest{Issuer: r.URL.Query().Get("issuer"), Owner: r.URL.Query().Get("owner")}
+}
@@ -1,11 +1,20 @@
 package handlers
 
 import (
+	"context"
+	"encoding/csv"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
 	"go.uber.org/zap"
 )
 
@@ -39,8 +48,115 @@ func writeNoContent(w http.ResponseWriter) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// ConsistencyTokenHeader carries the consistency token issued by mutations, reads that pass it along observe them.
+const ConsistencyTokenHeader = "X-Consistency-Token"
+
+func writeConsistencyToken(w http.ResponseWriter, response *nc.Msg) {
+	token := &data.ConsistencyToken{}
+	_ = json.Unmarshal(response.Data, token)
+
+	if token.ConsistencyToken != "" {
+		w.Header().Set(ConsistencyTokenHeader, token.ConsistencyToken)
+	}
+}
+
+// writeError writes the error along with the id of the request, see RequestIDMiddleware.
 func writeError(w http.ResponseWriter, e *errors.Type) {
+	if e.RequestID == "" {
+		e.RequestID = w.Header().Get(RequestIDHeader)
+	}
+
+	if delay, ok := e.RetryDelay(); ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+	}
+
 	out, _ := json.Marshal(e)
 	w.WriteHeader(e.HTTPStatusCode)
 	_, _ = w.Write(out)
 }
+
+// request sends the provided payload to the subject and returns back the response if it is not an error. Otherwise
+// the error is written to the response writer and ok will be false.
+func request(logger *zap.SugaredLogger, natsClient *nc.Conn, w http.ResponseWriter, r *http.Request, subject string,
+	in []byte) (response *nc.Msg, ok bool) {
+
+	response, e := natsClient.RequestWithContext(r.Context(), subject, in)
+	if e != nil {
+		if e == nc.ErrTimeout {
+			et := errors.RequestTimeout("")
+			writeError(w, et)
+		} else {
+			et := errors.InternalServerError("unknown", "")
+			logger.Error(et.FingerPrint, ": request ", w.Header().Get(RequestIDHeader), ": ", e.Error())
+			writeError(w, et)
+		}
+
+		return nil, false
+	}
+
+	if et, isError := errors.FromEnvelope(response.Data); isError {
+		// Services log internal errors by their fingerprints, which are tied to the request ids here.
+		if et.Kind == errors.KindInternal {
+			logger.Warn(et.FingerPrint, ": request ", w.Header().Get(RequestIDHeader), " failed on ", subject)
+		}
+
+		writeError(w, et)
+		return nil, false
+	}
+
+	return response, true
+}
+
+// pageRequest returns back the page of lists requested by the pageSize and pageToken params.
+func pageRequest(r *http.Request) data.PageRequest {
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+
+	return data.PageRequest{PageSize: pageSize, PageToken: r.URL.Query().Get("pageToken")}
+}
+
+// newCSVWriter sets the CSV download headers and returns back a writer on top of the response. Since the content
+// length is unknown the response is sent using chunked transfer encoding as the writer gets flushed.
+func newCSVWriter(w http.ResponseWriter, filename string) *csv.Writer {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%v"`, filename))
+	w.WriteHeader(http.StatusOK)
+
+	return csv.NewWriter(w)
+}
+
+// flushCSV flushes buffered records down to the client.
+func flushCSV(w http.ResponseWriter, writer *csv.Writer) {
+	writer.Flush()
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// abortCSV appends a trailing row carrying the error to the records written so far and aborts the response, so the
+// connection gets closed before the end of the chunked response and clients see the CSV cut short rather than complete.
+func abortCSV(w http.ResponseWriter, writer *csv.Writer, e *errors.Type) {
+	if e.RequestID == "" {
+		e.RequestID = w.Header().Get(RequestIDHeader)
+	}
+
+	_ = writer.Write([]string{"#error", e.Errors[0].Code, e.FingerPrint, e.RequestID})
+	flushCSV(w, writer)
+	panic(http.ErrAbortHandler)
+}
+
+// connContextKey is the key of the connection of requests within their contexts, see ConnContext.
+type connContextKey struct{}
+
+// ConnContext keeps the connection requests are read from within their contexts, so handlers of long responses can
+// replace the write deadline the server sets per request. It is meant to be the ConnContext of the server.
+func ConnContext(ctx context.Context, conn net.Conn) context.Context {
+	return context.WithValue(ctx, connContextKey{}, conn)
+}
+
+// extendWriteDeadline replaces the write deadline of the connection of the request, set by the server to its write
+// timeout from when the request got read, so long responses are only cut off when a part of them stalls for wait.
+func extendWriteDeadline(r *http.Request, wait time.Duration) {
+	if conn, ok := r.Context().Value(connContextKey{}).(net.Conn); ok {
+		_ = conn.SetWriteDeadline(time.Now().Add(wait))
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// IncidentHandler is the handler implementation of incidents related resource, i.e. outages many tickets are
+// attached to.
+type IncidentHandler struct {
+	logger     *zap.SugaredLogger
+	natsClient *nc.Conn
+}
+
+// NewIncidentHandler returns back a newly created and ready to use IncidentHandler.
+func NewIncidentHandler(logger *zap.SugaredLogger, natsClient *nc.Conn) *IncidentHandler {
+	return &IncidentHandler{logger: logger, natsClient: natsClient}
+}
+
+// Create creates an incident and returns back its id.
+func (h *IncidentHandler) Create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.incidents.create", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// Load returns back the incident identified by the id query parameter along with the ids of its tickets, or lists the
+// open incidents, or the resolved ones with resolved=true, when no id is given.
+func (h *IncidentHandler) Load() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+		resolved, _ := strconv.ParseBool(r.URL.Query().Get("resolved"))
+
+		subject := "kiosk.incidents.load"
+		in, _ := json.Marshal(data.ID{ID: id})
+		if id == 0 {
+			subject = "kiosk.incidents.list"
+			in, _ = json.Marshal(data.ListIncidentsRequest{Resolved: resolved})
+		}
+
+		response, ok := request(h.logger, h.natsClient, w, r, subject, in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// Attach attaches tickets to an open incident.
+func (h *IncidentHandler) Attach() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.incidents.tickets.attach", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
+
+// Detach detaches the ticket identified by the ticketId query parameter from the open incident identified by the
+// incidentId query parameter.
+func (h *IncidentHandler) Detach() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		incidentID, _ := strconv.ParseInt(r.URL.Query().Get("incidentId"), 10, 64)
+		ticketID, _ := strconv.ParseInt(r.URL.Query().Get("ticketId"), 10, 64)
+
+		in, _ := json.Marshal(data.DetachIncidentTicketRequest{IncidentID: incidentID, TicketID: ticketID})
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.incidents.tickets.detach", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
+
+// Resolve resolves an incident, posting the templated message on all of its tickets and resolving them if asked to.
+// It returns back the ids of the tickets it resolved and the ones it skipped for missing requirements or approvals.
+func (h *IncidentHandler) Resolve() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.incidents.resolve", in)
+		if !ok {
+			return
+		}
+
+		writeConsistencyToken(w, response)
+		_, _ = w.Write(response.Data)
+	}
+}
@@ -0,0 +1,366 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// IssuerHandler is the handler implementation of issuers related resource.
+type IssuerHandler struct {
+	logger     *zap.SugaredLogger
+	natsClient *nc.Conn
+}
+
+// NewIssuerHandler returns back a newly created and ready to use IssuerHandler.
+func NewIssuerHandler(logger *zap.SugaredLogger, natsClient *nc.Conn) *IssuerHandler {
+	return &IssuerHandler{logger: logger, natsClient: natsClient}
+}
+
+// SaveSettings creates or replaces the settings of an issuer.
+func (h *IssuerHandler) SaveSettings() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.issuers.settings.save", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
+
+// LoadSettings returns back the settings of an issuer.
+func (h *IssuerHandler) LoadSettings() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := json.Marshal(data.IssuerRequest{Issuer: r.URL.Query().Get("issuer")})
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.issuers.settings.load", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// SavePagingPolicy creates or replaces the paging policy of an issuer.
+func (h *IssuerHandler) SavePagingPolicy() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.issuers.paging.save", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
+
+// LoadPagingPolicy returns back the paging policy of an issuer.
+func (h *IssuerHandler) LoadPagingPolicy() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := json.Marshal(data.IssuerRequest{Issuer: r.URL.Query().Get("issuer")})
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.issuers.paging.load", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// DeletePagingPolicy deletes the paging policy of an issuer, so its staff does not get paged anymore.
+func (h *IssuerHandler) DeletePagingPolicy() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := json.Marshal(data.IssuerRequest{Issuer: r.URL.Query().Get("issuer")})
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.issuers.paging.delete", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
+
+// CreateOnCallShift adds a shift to the on-call rota of an issuer.
+func (h *IssuerHandler) CreateOnCallShift() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.issuers.on_call.create", in)
+		if !ok {
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// OnCallShifts returns back the current and upcoming shifts of the on-call rota of an issuer.
+func (h *IssuerHandler) OnCallShifts() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := json.Marshal(data.IssuerRequest{Issuer: r.URL.Query().Get("issuer")})
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.issuers.on_call.list", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// DeleteOnCallShift removes a shift from the on-call rota of an issuer.
+func (h *IssuerHandler) DeleteOnCallShift() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+		in, _ := json.Marshal(data.DeleteOnCallShiftRequest{Issuer: r.URL.Query().Get("issuer"), ID: id})
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.issuers.on_call.delete", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
+
+// SaveEscalationPolicy creates or replaces the escalation chain of an issuer and importance level.
+func (h *IssuerHandler) SaveEscalationPolicy() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.issuers.escalations.save", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
+
+// LoadEscalationPolicies returns back the escalation chains of an issuer, for all of its importance levels.
+func (h *IssuerHandler) LoadEscalationPolicies() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := json.Marshal(data.IssuerRequest{Issuer: r.URL.Query().Get("issuer")})
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.issuers.escalations.load", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// DeleteEscalationPolicy deletes the escalation chain of an issuer and importance level, so its tickets do not get
+// escalated anymore.
+func (h *IssuerHandler) DeleteEscalationPolicy() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		in, _ := json.Marshal(data.EscalationPolicyRequest{Issuer: query.Get("issuer"),
+			ImportanceLevel: models.TicketImportanceLevel(query.Get("importanceLevel"))})
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.issuers.escalations.delete", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
+
+// SaveApprovers creates or replaces the approvers of an issuer and status.
+func (h *IssuerHandler) SaveApprovers() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.issuers.approvers.save", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
+
+// LoadApprovers returns back the approvers of an issuer, for all of its statuses.
+func (h *IssuerHandler) LoadApprovers() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := json.Marshal(data.IssuerRequest{Issuer: r.URL.Query().Get("issuer")})
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.issuers.approvers.load", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// DeleteApprovers deletes the approvers of an issuer and status, so its tickets get moved to that status freely.
+func (h *IssuerHandler) DeleteApprovers() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		in, _ := json.Marshal(data.ApproversRequest{Issuer: query.Get("issuer"),
+			Status: models.TicketStatus(query.Get("status"))})
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.issuers.approvers.delete", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
+
+// SaveTransitionRequirements creates or replaces the fields tickets of an issuer must have before being moved to a
+// status.
+func (h *IssuerHandler) SaveTransitionRequirements() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.issuers.transition_requirements.save", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
+
+// LoadTransitionRequirements returns back the transition requirements of an issuer, for all of its statuses.
+func (h *IssuerHandler) LoadTransitionRequirements() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := json.Marshal(data.IssuerRequest{Issuer: r.URL.Query().Get("issuer")})
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.issuers.transition_requirements.load", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// DeleteTransitionRequirements deletes the fields required by a status of an issuer.
+func (h *IssuerHandler) DeleteTransitionRequirements() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		in, _ := json.Marshal(data.TransitionRequirementsRequest{Issuer: query.Get("issuer"),
+			Status: models.TicketStatus(query.Get("status"))})
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.issuers.transition_requirements.delete", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
+
+// SaveBillingRate creates or replaces the billing rate of an issuer.
+func (h *IssuerHandler) SaveBillingRate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.issuers.billing_rates.save", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
+
+// LoadBillingRate returns back the billing rate of an issuer.
+func (h *IssuerHandler) LoadBillingRate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := json.Marshal(data.IssuerRequest{Issuer: r.URL.Query().Get("issuer")})
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.issuers.billing_rates.load", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// DeleteBillingRate deletes the billing rate of an issuer.
+func (h *IssuerHandler) DeleteBillingRate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := json.Marshal(data.IssuerRequest{Issuer: r.URL.Query().Get("issuer")})
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.issuers.billing_rates.delete", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
+
+// SaveSLATarget creates or replaces the SLA target of a customer tier and importance level.
+func (h *IssuerHandler) SaveSLATarget() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.issuers.sla_targets.save", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
+
+// ListSLATargets returns back the SLA targets of all customer tiers.
+func (h *IssuerHandler) ListSLATargets() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.issuers.sla_targets.list", []byte("{}"))
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// SaveResolutionCategory creates a resolution category or replaces its sub-categories.
+func (h *IssuerHandler) SaveResolutionCategory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.issuers.resolution_categories.save", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
+
+// ListResolutionCategories returns back the resolution categories along with their sub-categories.
+func (h *IssuerHandler) ListResolutionCategories() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.issuers.resolution_categories.list",
+			[]byte("{}"))
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// DeleteResolutionCategory deletes a resolution category.
+func (h *IssuerHandler) DeleteResolutionCategory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := json.Marshal(data.ResolutionCategoryRequest{Name: r.URL.Query().Get("name")})
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.issuers.resolution_categories.delete", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// MaintenanceWindowHandler is the handler implementation of maintenance windows related resource.
+type MaintenanceWindowHandler struct {
+	logger     *zap.SugaredLogger
+	natsClient *nc.Conn
+}
+
+// NewMaintenanceWindowHandler returns back a newly created and ready to use MaintenanceWindowHandler.
+func NewMaintenanceWindowHandler(logger *zap.SugaredLogger, natsClient *nc.Conn) *MaintenanceWindowHandler {
+	return &MaintenanceWindowHandler{logger: logger, natsClient: natsClient}
+}
+
+// Create defines a new maintenance window.
+func (h *MaintenanceWindowHandler) Create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.maintenance_windows.create", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
+
+// List returns back the ongoing and upcoming maintenance windows.
+func (h *MaintenanceWindowHandler) List() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.maintenance_windows.list", []byte("{}"))
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// Delete deletes the maintenance window with provided id.
+func (h *MaintenanceWindowHandler) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+
+		in, _ := json.Marshal(data.ID{ID: id})
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.maintenance_windows.delete", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
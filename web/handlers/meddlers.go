@@ -1,13 +1,52 @@
 package handlers
 
-import "net/http"
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CORS configures the cross-origin requests browsers may make, e.g. from web based consoles. Cross-origin requests
+// are not allowed when AllowedOrigins is empty, and an origin of "*" allows all origins.
+type CORS struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// allows reports whether the origin may make cross-origin requests.
+func (c *CORS) allows(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SecurityHeaders configures the standard security headers added to responses. Strict-Transport-Security is only
+// added when HSTSMaxAge is positive, as kiosk is often served over plain HTTP behind a TLS terminating proxy.
+type SecurityHeaders struct {
+	Enabled    bool
+	HSTSMaxAge time.Duration
+}
 
 // Meddlers holds different middleware implementations and provide some components for use in implementations.
-type Meddlers struct{}
+type Meddlers struct {
+	cors            CORS
+	securityHeaders SecurityHeaders
+}
 
 // NewMeddlers returns a newly created and ready to use Meddlers.
-func NewMeddlers() *Meddlers {
-	return &Meddlers{}
+func NewMeddlers(cors CORS, securityHeaders SecurityHeaders) *Meddlers {
+	return &Meddlers{cors: cors, securityHeaders: securityHeaders}
 }
 
 // JSONContentTypeHeaderMiddleware adds application/json content type header to responses.
@@ -17,3 +56,72 @@ func (ms *Meddlers) JSONContentTypeHeaderMiddleware(handler http.Handler) http.H
 		handler.ServeHTTP(w, r)
 	})
 }
+
+// RequestIDHeader carries the id kiosk generates for every request, it is also sent back in the bodies of errors so
+// their screenshots are searchable in logs.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDMiddleware generates an id for every request and sends it back in RequestIDHeader. It must wrap all other
+// middlewares, so every response carries the id.
+func (ms *Meddlers) RequestIDMiddleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(RequestIDHeader, uuid.New().String())
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// CORSMiddleware allows the cross-origin requests of the configured origins and answers their preflight requests. It
+// must wrap the router, since preflight requests match none of the routes.
+func (ms *Meddlers) CORSMiddleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		w.Header().Add("Vary", "Origin")
+
+		if origin == "" || !ms.cors.allows(origin) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		if ms.cors.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(ms.cors.AllowedMethods, ", "))
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(ms.cors.AllowedHeaders, ", "))
+			if ms.cors.MaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(ms.cors.MaxAge.Seconds())))
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if len(ms.cors.ExposedHeaders) > 0 {
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join(ms.cors.ExposedHeaders, ", "))
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// SecurityHeadersMiddleware adds the standard security headers to responses, if enabled. Kiosk only serves data, so
+// responses may neither be framed nor load any content.
+func (ms *Meddlers) SecurityHeadersMiddleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ms.securityHeaders.Enabled {
+			w.Header().Set("X-Content-Type-Options", "nosniff")
+			w.Header().Set("X-Frame-Options", "DENY")
+			w.Header().Set("Referrer-Policy", "no-referrer")
+			w.Header().Set("Content-Security-Policy", "default-src 'none'; frame-ancestors 'none'")
+
+			if ms.securityHeaders.HSTSMaxAge > 0 {
+				w.Header().Set("Strict-Transport-Security",
+					"max-age="+strconv.Itoa(int(ms.securityHeaders.HSTSMaxAge.Seconds())))
+			}
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// NotificationHandler is the handler implementation of notifications related resource.
+type NotificationHandler struct {
+	logger     *zap.SugaredLogger
+	natsClient *nc.Conn
+}
+
+// NewNotificationHandler returns back a newly created and ready to use NotificationHandler.
+func NewNotificationHandler(logger *zap.SugaredLogger, natsClient *nc.Conn) *NotificationHandler {
+	return &NotificationHandler{logger: logger, natsClient: natsClient}
+}
+
+// List returns back the notifications sent about a ticket along with their delivery statuses.
+func (h *NotificationHandler) List() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ticketID, _ := strconv.ParseInt(r.URL.Query().Get("ticketId"), 10, 64)
+
+		in, _ := json.Marshal(data.ListNotificationsRequest{TicketID: ticketID})
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.notifications.list", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// ReportBounce marks a notification as bounced.
+func (h *NotificationHandler) ReportBounce() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.notifications.bounce", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
+
+// SavePreferences creates or replaces the notification preferences of a recipient.
+func (h *NotificationHandler) SavePreferences() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.notifications.preferences.save", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
+
+// LoadPreferences returns back the notification preferences of a recipient.
+func (h *NotificationHandler) LoadPreferences() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := json.Marshal(data.RecipientRequest{Recipient: r.URL.Query().Get("recipient")})
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.notifications.preferences.load", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// DeletePreferences deletes the notification preferences of a recipient, so the defaults apply again.
+func (h *NotificationHandler) DeletePreferences() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := json.Marshal(data.RecipientRequest{Recipient: r.URL.Query().Get("recipient")})
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.notifications.preferences.delete", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
+
+// CreateSavedSearch subscribes an agent to the new tickets matching a saved search.
+func (h *NotificationHandler) CreateSavedSearch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.saved_searches.create", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// ListSavedSearches returns back the saved searches of an agent.
+func (h *NotificationHandler) ListSavedSearches() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := json.Marshal(data.SavedSearchesRequest{Agent: r.URL.Query().Get("agent")})
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.saved_searches.list", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// DeleteSavedSearch unsubscribes an agent from one of their saved searches.
+func (h *NotificationHandler) DeleteSavedSearch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+
+		in, _ := json.Marshal(data.DeleteSavedSearchRequest{ID: id, Agent: r.URL.Query().Get("agent")})
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.saved_searches.delete", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// ReferenceHandler is the handler implementation of ticket references related resource.
+type ReferenceHandler struct {
+	logger     *zap.SugaredLogger
+	natsClient *nc.Conn
+}
+
+// NewReferenceHandler returns back a newly created and ready to use ReferenceHandler.
+func NewReferenceHandler(logger *zap.SugaredLogger, natsClient *nc.Conn) *ReferenceHandler {
+	return &ReferenceHandler{logger: logger, natsClient: natsClient}
+}
+
+// Attach links a ticket to an external issue and returns back the id of the reference.
+func (h *ReferenceHandler) Attach() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.references.attach", in)
+		if !ok {
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// List returns back the external references of a ticket.
+func (h *ReferenceHandler) List() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ticketID, _ := strconv.ParseInt(r.URL.Query().Get("ticketId"), 10, 64)
+
+		in, _ := json.Marshal(data.ListReferencesRequest{TicketID: ticketID})
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.references.list", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// Detach deletes the reference with provided id.
+func (h *ReferenceHandler) Detach() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+
+		in, _ := json.Marshal(data.ID{ID: id})
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.references.detach", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
+
+// EscalateToGitHub opens a GitHub issue for a ticket and returns back the reference linking them.
+func (h *ReferenceHandler) EscalateToGitHub() http.HandlerFunc {
+	return h.escalate("kiosk.tickets.escalate.github")
+}
+
+// EscalateToJira opens a Jira issue for a ticket and returns back the reference linking them.
+func (h *ReferenceHandler) EscalateToJira() http.HandlerFunc {
+	return h.escalate("kiosk.tickets.escalate.jira")
+}
+
+func (h *ReferenceHandler) escalate(subject string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		response, ok := request(h.logger, h.natsClient, w, r, subject, in)
+		if !ok {
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// GitHubWebhook receives the issues and issue_comment webhook deliveries of GitHub.
+func (h *ReferenceHandler) GitHubWebhook() http.HandlerFunc {
+	return h.webhook("kiosk.webhooks.github", "X-GitHub-Event", "X-Hub-Signature-256")
+}
+
+// JiraWebhook receives the issue updated and comment created webhook deliveries of Jira.
+func (h *ReferenceHandler) JiraWebhook() http.HandlerFunc {
+	return h.webhook("kiosk.webhooks.jira", "", "X-Hub-Signature")
+}
+
+// webhook forwards deliveries along with their event and signature headers, the event header is optional.
+func (h *ReferenceHandler) webhook(subject, eventHeader, signatureHeader string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := ioutil.ReadAll(r.Body)
+
+		webhookRequest := data.WebhookRequest{Signature: r.Header.Get(signatureHeader), Payload: payload}
+		if eventHeader != "" {
+			webhookRequest.Event = r.Header.Get(eventHeader)
+		}
+
+		in, _ := json.Marshal(webhookRequest)
+		if _, ok := request(h.logger, h.natsClient, w, r, subject, in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jibitters/kiosk/documents"
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// ReportHandler is the handler implementation of reports related resource.
+type ReportHandler struct {
+	logger     *zap.SugaredLogger
+	natsClient *nc.Conn
+}
+
+// NewReportHandler returns back a newly created and ready to use ReportHandler.
+func NewReportHandler(logger *zap.SugaredLogger, natsClient *nc.Conn) *ReportHandler {
+	return &ReportHandler{logger: logger, natsClient: natsClient}
+}
+
+// Daily returns back the number of created tickets per day, issuer, importance level and status.
+func (h *ReportHandler) Daily() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response, ok := h.daily(w, r)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// DailyCSV streams the daily report as CSV.
+func (h *ReportHandler) DailyCSV() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response, ok := h.daily(w, r)
+		if !ok {
+			return
+		}
+
+		dailyReportResponse := &data.DailyReportResponse{}
+		_ = json.Unmarshal(response.Data, dailyReportResponse)
+
+		writer := newCSVWriter(w, "daily-report.csv")
+		_ = writer.Write([]string{"day", "issuer", "importanceLevel", "status", "count"})
+		for i, row := range dailyReportResponse.Rows {
+			_ = writer.Write([]string{row.Day, documents.CSVCell(row.Issuer), string(row.ImportanceLevel),
+				string(row.Status), strconv.FormatInt(row.Count, 10)})
+
+			if i%500 == 499 {
+				flushCSV(w, writer)
+			}
+		}
+		flushCSV(w, writer)
+	}
+}
+
+func (h *ReportHandler) daily(w http.ResponseWriter, r *http.Request) (*nc.Msg, bool) {
+	dailyReportRequest := data.DailyReportRequest{
+		Issuer:   r.URL.Query().Get("issuer"),
+		FromDate: r.URL.Query().Get("fromDate"),
+		ToDate:   r.URL.Query().Get("toDate"),
+	}
+
+	in, _ := json.Marshal(dailyReportRequest)
+	return request(h.logger, h.natsClient, w, r, "kiosk.reports.daily", in)
+}
+
+// AgentMetrics returns back the activity metrics of agents, identified as the privacy settings allow.
+func (h *ReportHandler) AgentMetrics() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agentMetricsRequest := data.AgentMetricsRequest{
+			Issuer:   r.URL.Query().Get("issuer"),
+			Agent:    r.URL.Query().Get("agent"),
+			FromDate: r.URL.Query().Get("fromDate"),
+			ToDate:   r.URL.Query().Get("toDate"),
+		}
+
+		in, _ := json.Marshal(agentMetricsRequest)
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.reports.agents", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// TimeReport returns back the time logged on tickets per issuer and agent, identified as the privacy settings allow.
+func (h *ReportHandler) TimeReport() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		timeReportRequest := data.TimeReportRequest{
+			Issuer:   r.URL.Query().Get("issuer"),
+			Agent:    r.URL.Query().Get("agent"),
+			FromDate: r.URL.Query().Get("fromDate"),
+			ToDate:   r.URL.Query().Get("toDate"),
+		}
+
+		in, _ := json.Marshal(timeReportRequest)
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.reports.time", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// Resolutions returns back the number of tickets resolved per resolution category, sub-category and root cause.
+func (h *ReportHandler) Resolutions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resolutionReportRequest := data.ResolutionReportRequest{
+			Issuer:   r.URL.Query().Get("issuer"),
+			FromDate: r.URL.Query().Get("fromDate"),
+			ToDate:   r.URL.Query().Get("toDate"),
+		}
+
+		in, _ := json.Marshal(resolutionReportRequest)
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.reports.resolutions", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// Billing returns back the monthly billing export of billable time and tickets per issuer.
+func (h *ReportHandler) Billing() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response, ok := h.billing(w, r)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// BillingCSV streams the monthly billing export as CSV.
+func (h *ReportHandler) BillingCSV() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response, ok := h.billing(w, r)
+		if !ok {
+			return
+		}
+
+		billingExportResponse := &data.BillingExportResponse{}
+		_ = json.Unmarshal(response.Data, billingExportResponse)
+
+		writer := newCSVWriter(w, "billing-"+billingExportResponse.Month+".csv")
+		_ = writer.Write([]string{"month", "issuer", "billableTickets", "billableMinutes", "hourlyRate", "currency",
+			"amount"})
+		for _, row := range billingExportResponse.Rows {
+			_ = writer.Write([]string{billingExportResponse.Month, documents.CSVCell(row.Issuer),
+				strconv.FormatInt(row.BillableTickets, 10), strconv.FormatInt(row.BillableMinutes, 10),
+				strconv.FormatInt(row.HourlyRate, 10), row.Currency, strconv.FormatInt(row.Amount, 10)})
+		}
+		flushCSV(w, writer)
+	}
+}
+
+func (h *ReportHandler) billing(w http.ResponseWriter, r *http.Request) (*nc.Msg, bool) {
+	in, _ := json.Marshal(data.BillingExportRequest{Month: r.URL.Query().Get("month")})
+	return request(h.logger, h.natsClient, w, r, "kiosk.reports.billing", in)
+}
+
+// Workload returns back the series of ticket arrivals and resolutions per period, and optionally per issuer.
+func (h *ReportHandler) Workload() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response, ok := h.workload(w, r)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// WorkloadCSV streams the workload series as CSV, the format most forecasting tools import.
+func (h *ReportHandler) WorkloadCSV() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response, ok := h.workload(w, r)
+		if !ok {
+			return
+		}
+
+		workloadResponse := &data.WorkloadResponse{}
+		_ = json.Unmarshal(response.Data, workloadResponse)
+
+		writer := newCSVWriter(w, "workload.csv")
+		_ = writer.Write([]string{"period", "issuer", "arrivals", "resolutions"})
+		for i, row := range workloadResponse.Rows {
+			_ = writer.Write([]string{row.Period, documents.CSVCell(row.Issuer), strconv.FormatInt(row.Arrivals, 10),
+				strconv.FormatInt(row.Resolutions, 10)})
+
+			if i%500 == 499 {
+				flushCSV(w, writer)
+			}
+		}
+		flushCSV(w, writer)
+	}
+}
+
+func (h *ReportHandler) workload(w http.ResponseWriter, r *http.Request) (*nc.Msg, bool) {
+	byIssuer, _ := strconv.ParseBool(r.URL.Query().Get("byIssuer"))
+	workloadRequest := data.WorkloadRequest{
+		Issuer:      r.URL.Query().Get("issuer"),
+		Granularity: models.WorkloadGranularity(strings.ToUpper(r.URL.Query().Get("granularity"))),
+		ByIssuer:    byIssuer,
+		FromDate:    r.URL.Query().Get("fromDate"),
+		ToDate:      r.URL.Query().Get("toDate"),
+	}
+
+	in, _ := json.Marshal(workloadRequest)
+	return request(h.logger, h.natsClient, w, r, "kiosk.reports.workload", in)
+}
+
+// CreateSchedule schedules a report to be emailed periodically.
+func (h *ReportHandler) CreateSchedule() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.reports.schedules.create", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
+
+// ListSchedules returns back all scheduled reports.
+func (h *ReportHandler) ListSchedules() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.reports.schedules.list", []byte("{}"))
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// DeleteSchedule deletes the scheduled report with provided id.
+func (h *ReportHandler) DeleteSchedule() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+
+		in, _ := json.Marshal(data.ID{ID: id})
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.reports.schedules.delete", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
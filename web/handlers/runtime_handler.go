@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/jibitters/kiosk/models"
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// RuntimeHandler is the handler implementation of runtime entries related resource.
+type RuntimeHandler struct {
+	logger     *zap.SugaredLogger
+	natsClient *nc.Conn
+}
+
+// NewRuntimeHandler returns back a newly created and ready to use RuntimeHandler.
+func NewRuntimeHandler(logger *zap.SugaredLogger, natsClient *nc.Conn) *RuntimeHandler {
+	return &RuntimeHandler{logger: logger, natsClient: natsClient}
+}
+
+// Save creates or replaces a runtime entry, the change applies to all kiosk instances.
+func (h *RuntimeHandler) Save() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.runtime.entries.save", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// Load returns back the runtime entry identified by the kind and name query parameters, or lists the entries of the
+// kind when no name is given.
+func (h *RuntimeHandler) Load() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		kind := models.RuntimeEntryKind(r.URL.Query().Get("kind"))
+		name := r.URL.Query().Get("name")
+
+		subject := "kiosk.runtime.entries.load"
+		in, _ := json.Marshal(&data.RuntimeEntryRequest{Kind: kind, Name: name})
+		if name == "" {
+			subject = "kiosk.runtime.entries.list"
+			in, _ = json.Marshal(&data.ListRuntimeEntriesRequest{Kind: kind})
+		}
+
+		response, ok := request(h.logger, h.natsClient, w, r, subject, in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// Delete removes the runtime entry identified by the kind and name query parameters.
+func (h *RuntimeHandler) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := json.Marshal(&data.RuntimeEntryRequest{
+			Kind: models.RuntimeEntryKind(r.URL.Query().Get("kind")),
+			Name: r.URL.Query().Get("name"),
+		})
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.runtime.entries.delete", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// StatusPageHandler is the handler implementation of status page incidents related resource.
+type StatusPageHandler struct {
+	logger     *zap.SugaredLogger
+	natsClient *nc.Conn
+}
+
+// NewStatusPageHandler returns back a newly created and ready to use StatusPageHandler.
+func NewStatusPageHandler(logger *zap.SugaredLogger, natsClient *nc.Conn) *StatusPageHandler {
+	return &StatusPageHandler{logger: logger, natsClient: natsClient}
+}
+
+// Publish marks a ticket as a public incident, or updates its incident, and returns back the incident.
+func (h *StatusPageHandler) Publish() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.incidents.publish", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// Load returns back the public incident of a ticket.
+func (h *StatusPageHandler) Load() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ticketID, _ := strconv.ParseInt(r.URL.Query().Get("ticketId"), 10, 64)
+
+		in, _ := json.Marshal(data.LoadIncidentRequest{TicketID: ticketID})
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.incidents.load", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
@@ -0,0 +1,465 @@
+package handlers
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/jibitters/kiosk/errors"
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+const (
+	// eventsSubject matches the subjects of all events.
+	eventsSubject = "kiosk.events.>"
+
+	streamWriteWait      = 10 * time.Second
+	streamPongWait       = 60 * time.Second
+	streamPingPeriod     = streamPongWait * 9 / 10
+	streamMaxRequestSize = 4096
+	// streamBufferSize bounds the messages waiting to be written to a browser, browsers falling further behind get
+	// disconnected so they can not hold up the events of others.
+	streamBufferSize = 256
+)
+
+// Stream configures the event stream browsers connect to over WebSocket, or as server-sent events. The stream is
+// disabled when Tokens is empty. Browsers can not set headers on WebSocket handshakes nor EventSource requests, so the
+// token is passed as the token query parameter, unless an Authorization bearer header can be set. WebSocket handshakes
+// are only accepted from AllowedOrigins, an origin of "*" allows all origins.
+type Stream struct {
+	Tokens           []string
+	AllowedOrigins   []string
+	MaxSubscriptions int
+}
+
+// authenticates reports whether the request carries one of the tokens.
+func (s *Stream) authenticates(r *http.Request) bool {
+	token := r.URL.Query().Get("token")
+	if authorization := r.Header.Get("Authorization"); strings.HasPrefix(authorization, "Bearer ") {
+		token = strings.TrimPrefix(authorization, "Bearer ")
+	}
+
+	if token == "" {
+		return false
+	}
+
+	for _, t := range s.Tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allows reports whether browsers of the origin may connect.
+func (s *Stream) allows(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	for _, allowed := range s.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StreamHandler is the handler implementation of the event stream, it bridges events to browsers over WebSocket or
+// as server-sent events.
+type StreamHandler struct {
+	logger     *zap.SugaredLogger
+	natsClient *nc.Conn
+	stream     Stream
+	upgrader   websocket.Upgrader
+}
+
+// NewStreamHandler returns back a newly created and ready to use StreamHandler.
+func NewStreamHandler(logger *zap.SugaredLogger, natsClient *nc.Conn, stream Stream) *StreamHandler {
+	return &StreamHandler{
+		logger:     logger,
+		natsClient: natsClient,
+		stream:     stream,
+		upgrader:   websocket.Upgrader{HandshakeTimeout: 5 * time.Second, CheckOrigin: stream.allows},
+	}
+}
+
+// Stream upgrades the request to a WebSocket connection that streams the events matching the subscriptions the
+// browser makes over it, until either side closes it.
+func (h *StreamHandler) Stream() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !h.stream.authenticates(r) {
+			writeError(w, errors.Unauthorized(""))
+			return
+		}
+
+		conn, e := h.upgrader.Upgrade(w, r, nil)
+		if e != nil {
+			// The upgrader has already replied the error.
+			return
+		}
+
+		newStreamConnection(h.logger, h.natsClient, conn, h.stream.MaxSubscriptions).serve()
+	}
+}
+
+// Events streams the events matching the filter of the query parameters as server-sent events, for clients and
+// proxies that can not use WebSocket. The filter is that of a WebSocket subscription, with comma separated types, and
+// the events carry the id parameter as their subscription.
+func (h *StreamHandler) Events() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !h.stream.authenticates(r) {
+			writeError(w, errors.Unauthorized(""))
+			return
+		}
+
+		streamRequest := streamRequestOf(r.URL.Query())
+		if e := streamRequest.Validate(); e != nil {
+			writeError(w, e)
+			return
+		}
+
+		// The response outlives the write timeout of the server, so it is written on the hijacked connection with
+		// deadlines of its own.
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			writeError(w, errors.NotImplemented())
+			return
+		}
+
+		conn, buffered, e := hijacker.Hijack()
+		if e != nil {
+			et := errors.InternalServerError("unknown", "")
+			h.logger.Error(et.FingerPrint, ": ", e.Error())
+			writeError(w, et)
+			return
+		}
+
+		newEventSource(h.logger, h.natsClient, conn, buffered, streamRequest).serve(w.Header())
+	}
+}
+
+// streamRequestOf returns back the subscription request described by the query parameters.
+func streamRequestOf(query url.Values) *data.StreamRequest {
+	ticketID, _ := strconv.ParseInt(query.Get("ticketID"), 10, 64)
+	streamRequest := &data.StreamRequest{Action: data.StreamActionSubscribe, ID: query.Get("id"),
+		Filter: data.StreamFilter{Issuer: query.Get("issuer"), Owner: query.Get("owner"), TicketID: ticketID}}
+
+	if streamRequest.ID == "" {
+		streamRequest.ID = "events"
+	}
+
+	for _, t := range strings.Split(query.Get("types"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			streamRequest.Filter.Types = append(streamRequest.Filter.Types, data.EventType(t))
+		}
+	}
+
+	return streamRequest
+}
+
+// streamConnection is a WebSocket connection of a browser along with its subscriptions. Reads happen on the handler
+// goroutine and writes on a goroutine of their own, as WebSocket connections support one concurrent reader and writer.
+type streamConnection struct {
+	logger           *zap.SugaredLogger
+	natsClient       *nc.Conn
+	conn             *websocket.Conn
+	maxSubscriptions int
+	mutex            sync.RWMutex
+	filters          map[string]data.StreamFilter
+	outgoing         chan *data.StreamMessage
+	done             chan struct{}
+	closeOnce        sync.Once
+}
+
+func newStreamConnection(logger *zap.SugaredLogger, natsClient *nc.Conn, conn *websocket.Conn,
+	maxSubscriptions int) *streamConnection {
+
+	return &streamConnection{
+		logger:           logger,
+		natsClient:       natsClient,
+		conn:             conn,
+		maxSubscriptions: maxSubscriptions,
+		filters:          make(map[string]data.StreamFilter),
+		outgoing:         make(chan *data.StreamMessage, streamBufferSize),
+		done:             make(chan struct{}),
+	}
+}
+
+// serve serves the connection until it gets closed.
+func (c *streamConnection) serve() {
+	subscription, e := c.natsClient.Subscribe(eventsSubject, c.onEvent)
+	if e != nil {
+		c.logger.Error("Could not subscribe the event stream: ", e.Error())
+		c.close()
+		return
+	}
+
+	go c.writeLoop()
+	c.readLoop()
+
+	_ = subscription.Unsubscribe()
+	close(c.done)
+}
+
+// readLoop handles the requests of the browser until the connection gets closed.
+func (c *streamConnection) readLoop() {
+	c.conn.SetReadLimit(streamMaxRequestSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(streamPongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(streamPongWait))
+	})
+
+	for {
+		_, reader, e := c.conn.NextReader()
+		if e != nil {
+			return
+		}
+
+		in, e := ioutil.ReadAll(reader)
+		if e != nil {
+			return
+		}
+
+		streamRequest := &data.StreamRequest{}
+		if e := json.Unmarshal(in, streamRequest); e != nil {
+			c.send(&data.StreamMessage{Type: data.StreamMessageTypeError, Error: errors.InvalidRequestBody()})
+			continue
+		}
+
+		c.handle(streamRequest)
+	}
+}
+
+// handle subscribes or unsubscribes as requested, replying the outcome.
+func (c *streamConnection) handle(streamRequest *data.StreamRequest) {
+	if e := streamRequest.Validate(); e != nil {
+		c.send(&data.StreamMessage{Type: data.StreamMessageTypeError, ID: streamRequest.ID, Error: e})
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	_, exists := c.filters[streamRequest.ID]
+
+	switch streamRequest.Action {
+	case data.StreamActionSubscribe:
+		if !exists && len(c.filters) >= c.maxSubscriptions {
+			c.send(&data.StreamMessage{Type: data.StreamMessageTypeError, ID: streamRequest.ID,
+				Error: errors.PreconditionFailed("subscriptions.too_many", "")})
+			return
+		}
+
+		c.filters[streamRequest.ID] = streamRequest.Filter
+		c.send(&data.StreamMessage{Type: data.StreamMessageTypeSubscribed, ID: streamRequest.ID})
+	case data.StreamActionUnsubscribe:
+		if !exists {
+			c.send(&data.StreamMessage{Type: data.StreamMessageTypeError, ID: streamRequest.ID,
+				Error: errors.NotFound("subscription.not_found", "")})
+			return
+		}
+
+		delete(c.filters, streamRequest.ID)
+		c.send(&data.StreamMessage{Type: data.StreamMessageTypeUnsubscribed, ID: streamRequest.ID})
+	}
+}
+
+// onEvent sends the event to the browser if it matches any of its subscriptions.
+func (c *streamConnection) onEvent(msg *nc.Msg) {
+	event := &data.Event{}
+	if e := json.Unmarshal(msg.Data, event); e != nil {
+		return
+	}
+
+	c.mutex.RLock()
+	subscriptions := make([]string, 0)
+	for id, filter := range c.filters {
+		if filter.Matches(event) {
+			subscriptions = append(subscriptions, id)
+		}
+	}
+	c.mutex.RUnlock()
+
+	if len(subscriptions) == 0 {
+		return
+	}
+
+	sort.Strings(subscriptions)
+	c.send(&data.StreamMessage{Type: data.StreamMessageTypeEvent, Subscriptions: subscriptions, Event: event})
+}
+
+// send queues the message to be written to the browser. Browsers too slow to keep up get disconnected.
+func (c *streamConnection) send(message *data.StreamMessage) {
+	select {
+	case c.outgoing <- message:
+	case <-c.done:
+	default:
+		c.logger.Warn("Disconnecting a slow event stream consumer")
+		c.close()
+	}
+}
+
+// writeLoop writes the queued messages to the browser and pings it periodically, until the connection gets closed.
+func (c *streamConnection) writeLoop() {
+	ticker := time.NewTicker(streamPingPeriod)
+	defer ticker.Stop()
+	defer c.close()
+
+	for {
+		select {
+		case message := <-c.outgoing:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+			if e := c.conn.WriteJSON(message); e != nil {
+				return
+			}
+		case <-ticker.C:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+			if e := c.conn.WriteMessage(websocket.PingMessage, nil); e != nil {
+				return
+			}
+		case <-c.done:
+			closeMessage := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+			_ = c.conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(streamWriteWait))
+			return
+		}
+	}
+}
+
+// close closes the underlying connection, which stops both loops.
+func (c *streamConnection) close() {
+	c.closeOnce.Do(func() { _ = c.conn.Close() })
+}
+
+// eventSource is a hijacked HTTP connection server-sent events are written to, along with its only subscription.
+// Writes happen on the handler goroutine, while another one reads the connection to notice the client going away.
+type eventSource struct {
+	logger     *zap.SugaredLogger
+	natsClient *nc.Conn
+	conn       net.Conn
+	buffered   *bufio.ReadWriter
+	id         string
+	filter     data.StreamFilter
+	sequence   int64
+	outgoing   chan *data.StreamMessage
+	done       chan struct{}
+	closeOnce  sync.Once
+}
+
+func newEventSource(logger *zap.SugaredLogger, natsClient *nc.Conn, conn net.Conn, buffered *bufio.ReadWriter,
+	streamRequest *data.StreamRequest) *eventSource {
+
+	return &eventSource{
+		logger:     logger,
+		natsClient: natsClient,
+		conn:       conn,
+		buffered:   buffered,
+		id:         streamRequest.ID,
+		filter:     streamRequest.Filter,
+		outgoing:   make(chan *data.StreamMessage, streamBufferSize),
+		done:       make(chan struct{}),
+	}
+}
+
+// serve writes the response headers and then the events, until the connection gets closed.
+func (s *eventSource) serve(header http.Header) {
+	defer s.close()
+
+	header.Set("Content-Type", "text/event-stream; charset=utf-8")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "close")
+	header.Set("X-Accel-Buffering", "no")
+
+	_ = s.conn.SetDeadline(time.Time{})
+	_ = s.conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+	_, _ = s.buffered.WriteString("HTTP/1.1 200 OK\r\n")
+	_ = header.Write(s.buffered)
+	_, _ = s.buffered.WriteString("\r\nretry: 5000\n\n")
+	if e := s.buffered.Flush(); e != nil {
+		return
+	}
+
+	subscription, e := s.natsClient.Subscribe(eventsSubject, s.onEvent)
+	if e != nil {
+		s.logger.Error("Could not subscribe the event stream: ", e.Error())
+		return
+	}
+	defer func() { _ = subscription.Unsubscribe() }()
+
+	go s.watch()
+	s.writeLoop()
+}
+
+// watch reads the connection until the client goes away, clients are not expected to send anything.
+func (s *eventSource) watch() {
+	defer close(s.done)
+
+	_, _ = ioutil.ReadAll(s.buffered)
+	s.close()
+}
+
+// onEvent sends the event to the client if it matches the subscription.
+func (s *eventSource) onEvent(msg *nc.Msg) {
+	event := &data.Event{}
+	if e := json.Unmarshal(msg.Data, event); e != nil || !s.filter.Matches(event) {
+		return
+	}
+
+	select {
+	case s.outgoing <- &data.StreamMessage{Type: data.StreamMessageTypeEvent, Subscriptions: []string{s.id},
+		Event: event}:
+	case <-s.done:
+	default:
+		s.logger.Warn("Disconnecting a slow event stream consumer")
+		s.close()
+	}
+}
+
+// writeLoop writes the queued events to the client, along with comments that keep idle connections open.
+func (s *eventSource) writeLoop() {
+	ticker := time.NewTicker(streamPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case message := <-s.outgoing:
+			out, _ := json.Marshal(message)
+			s.sequence++
+
+			_ = s.conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+			_, _ = fmt.Fprintf(s.buffered, "id: %v\ndata: %s\n\n", s.sequence, out)
+			if e := s.buffered.Flush(); e != nil {
+				return
+			}
+		case <-ticker.C:
+			_ = s.conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+			_, _ = s.buffered.WriteString(": ping\n\n")
+			if e := s.buffered.Flush(); e != nil {
+				return
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// close closes the underlying connection, which stops both the writes and the watch.
+func (s *eventSource) close() {
+	s.closeOnce.Do(func() { _ = s.conn.Close() })
+}
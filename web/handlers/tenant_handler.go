@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// TenantHandler is the handler implementation of tenants related resource.
+type TenantHandler struct {
+	logger     *zap.SugaredLogger
+	natsClient *nc.Conn
+}
+
+// NewTenantHandler returns back a newly created and ready to use TenantHandler.
+func NewTenantHandler(logger *zap.SugaredLogger, natsClient *nc.Conn) *TenantHandler {
+	return &TenantHandler{logger: logger, natsClient: natsClient}
+}
+
+// Provision onboards a tenant along with its issuer settings, the API key in the response is never shown again.
+func (h *TenantHandler) Provision() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tenants.provision", in)
+		if !ok {
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// Load returns back the tenant of the issuer query parameter, or lists all tenants when no issuer is given.
+func (h *TenantHandler) Load() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		issuer := r.URL.Query().Get("issuer")
+
+		subject := "kiosk.tenants.load"
+		if issuer == "" {
+			subject = "kiosk.tenants.list"
+		}
+
+		in, _ := json.Marshal(&data.IssuerRequest{Issuer: issuer})
+
+		response, ok := request(h.logger, h.natsClient, w, r, subject, in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// Deactivate deactivates the tenant of the issuer query parameter and revokes its API keys.
+func (h *TenantHandler) Deactivate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := json.Marshal(&data.IssuerRequest{Issuer: r.URL.Query().Get("issuer")})
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.tenants.deactivate", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
@@ -2,10 +2,14 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/jibitters/kiosk/documents"
 	"github.com/jibitters/kiosk/errors"
 	"github.com/jibitters/kiosk/models"
 	"github.com/jibitters/kiosk/web/data"
@@ -13,6 +17,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// csvPageWriteWait bounds how long writing a page of an export may take, in place of the write timeout of the server.
+const csvPageWriteWait = 10 * time.Second
+
 // TicketHandler is the handler implementation of tickets related resource.
 type TicketHandler struct {
 	logger     *zap.SugaredLogger
@@ -29,32 +36,320 @@ func (h *TicketHandler) Create() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		in, _ := ioutil.ReadAll(r.Body)
 
-		response, e := h.natsClient.RequestWithContext(r.Context(), "kiosk.tickets.create", in)
-		if e != nil {
-			if e == nc.ErrTimeout {
-				et := errors.RequestTimeout("")
-				writeError(w, et)
-			} else {
-				et := errors.InternalServerError("unknown", "")
-				h.logger.Error(et.FingerPrint, ": ", e.Error())
-				writeError(w, et)
-			}
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.create", in)
+		if !ok {
+			return
+		}
+
+		writeConsistencyToken(w, response)
+		writeNoContent(w)
+	}
+}
+
+// Snooze hides a ticket from the active queues until the provided time, or until its owner replies.
+func (h *TicketHandler) Snooze() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.snooze", in)
+		if !ok {
+			return
+		}
+
+		writeConsistencyToken(w, response)
+		writeNoContent(w)
+	}
+}
+
+// Assign assigns a ticket to an agent, unless it is already assigned to another one and it is not reassigned.
+func (h *TicketHandler) Assign() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.assign", in)
+		if !ok {
+			return
+		}
+
+		writeConsistencyToken(w, response)
+		writeNoContent(w)
+	}
+}
+
+// Unassign releases a ticket from its assignee.
+func (h *TicketHandler) Unassign() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.unassign", in)
+		if !ok {
+			return
+		}
+
+		writeConsistencyToken(w, response)
+		writeNoContent(w)
+	}
+}
+
+// Assigned returns back the tickets assigned to an agent, the most recently modified first.
+func (h *TicketHandler) Assigned() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pageNumber, _ := strconv.Atoi(r.URL.Query().Get("pageNumber"))
+		pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+
+		in, _ := json.Marshal(data.FilterByAssigneeRequest{
+			Issuer:           r.URL.Query().Get("issuer"),
+			Assignee:         r.URL.Query().Get("assignee"),
+			Status:           models.TicketStatus(r.URL.Query().Get("status")),
+			PageNumber:       pageNumber,
+			PageSize:         pageSize,
+			ConsistencyToken: r.Header.Get(ConsistencyTokenHeader),
+		})
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.filter_by_assignee", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// LogWork records time an agent spent on a ticket.
+func (h *TicketHandler) LogWork() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.work.log", in)
+		if !ok {
+			return
+		}
+
+		writeConsistencyToken(w, response)
+		writeNoContent(w)
+	}
+}
+
+// WorkLogs returns back the work logs of the ticket with provided id, along with their total.
+func (h *TicketHandler) WorkLogs() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ticketID, _ := strconv.ParseInt(r.URL.Query().Get("ticketId"), 10, 64)
+
+		in, _ := json.Marshal(data.WorkLogsRequest{TicketID: ticketID})
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.work.list", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// SetBillable flags a ticket as billable or not billable.
+func (h *TicketHandler) SetBillable() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.billable", in)
+		if !ok {
+			return
+		}
+
+		writeConsistencyToken(w, response)
+		writeNoContent(w)
+	}
+}
+
+// CreateMacro creates a macro, a named bundle of actions agents apply to tickets at once.
+func (h *TicketHandler) CreateMacro() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.macros.create", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// Macros returns back the macros ordered by name, a page of them with pageSize, continued by pageToken.
+func (h *TicketHandler) Macros() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := json.Marshal(data.ListMacrosRequest{PageRequest: pageRequest(r)})
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.macros.list", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// UpdateMacro replaces the name and the actions of a macro.
+func (h *TicketHandler) UpdateMacro() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.macros.update", in); !ok {
+			return
+		}
 
+		writeNoContent(w)
+	}
+}
+
+// DeleteMacro deletes a macro.
+func (h *TicketHandler) DeleteMacro() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+
+		in, _ := json.Marshal(data.DeleteMacroRequest{ID: id})
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.macros.delete", in); !ok {
 			return
 		}
 
-		et := &errors.Type{}
-		_ = json.Unmarshal(response.Data, et)
-		if et.FingerPrint != "" {
-			writeError(w, et)
+		writeNoContent(w)
+	}
+}
+
+// ApplyMacro applies the actions of a macro to a ticket at once, either all of them take effect or none. The canned
+// comment is posted in the languages of the Accept-Language header, unless the body asks for others.
+func (h *TicketHandler) ApplyMacro() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		applyMacroRequest := data.ApplyMacroRequest{}
+		if e := json.Unmarshal(in, &applyMacroRequest); e == nil && applyMacroRequest.Languages == "" {
+			applyMacroRequest.Languages = r.Header.Get("Accept-Language")
+			in, _ = json.Marshal(applyMacroRequest)
+		}
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.macros.apply", in)
+		if !ok {
 			return
 		}
 
+		writeConsistencyToken(w, response)
 		writeNoContent(w)
 	}
 }
 
-// Filter filters tickets based on provided criteria values.
+// Duplicates returns back clusters of open tickets likely reported for the same issue, i.e. of the same owner and with
+// similar subjects, the largest first.
+func (h *TicketHandler) Duplicates() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		minSimilarity, _ := strconv.ParseFloat(r.URL.Query().Get("minSimilarity"), 64)
+
+		duplicateTicketsRequest := data.DuplicateTicketsRequest{Issuer: r.URL.Query().Get("issuer"),
+			FromDate: r.URL.Query().Get("fromDate"), MinSimilarity: minSimilarity, Limit: limit}
+
+		in, _ := json.Marshal(duplicateTicketsRequest)
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.duplicates", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// Merge merges duplicate tickets into a ticket at once, either all of them get merged or none.
+func (h *TicketHandler) Merge() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.merge", in)
+		if !ok {
+			return
+		}
+
+		writeConsistencyToken(w, response)
+		writeNoContent(w)
+	}
+}
+
+// LoadPublic returns back the ticket of the issuer query parameter identified by the publicId query parameter, the id
+// of the ticket customers get within emails and URLs.
+func (h *TicketHandler) LoadPublic() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := json.Marshal(data.PublicTicketRequest{Issuer: r.URL.Query().Get("issuer"),
+			PublicID: r.URL.Query().Get("publicId")})
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.public.load", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// RequestApproval requests an approval to move a ticket to a status guarded by approvers.
+func (h *TicketHandler) RequestApproval() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.approvals.request", in)
+		if !ok {
+			return
+		}
+
+		writeConsistencyToken(w, response)
+		writeNoContent(w)
+	}
+}
+
+// Approve approves the pending approval of a ticket, which moves the ticket to the requested status.
+func (h *TicketHandler) Approve() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.approvals.approve", in)
+		if !ok {
+			return
+		}
+
+		writeConsistencyToken(w, response)
+		writeNoContent(w)
+	}
+}
+
+// Reject rejects the pending approval of a ticket.
+func (h *TicketHandler) Reject() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.approvals.reject", in)
+		if !ok {
+			return
+		}
+
+		writeConsistencyToken(w, response)
+		writeNoContent(w)
+	}
+}
+
+// Approvals returns back the approvals requested for the ticket with provided id, along with their decisions.
+func (h *TicketHandler) Approvals() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ticketID, _ := strconv.ParseInt(r.URL.Query().Get("ticketId"), 10, 64)
+
+		in, _ := json.Marshal(data.TicketApprovalsRequest{TicketID: ticketID})
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.approvals", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// Filter filters tickets based on provided criteria values. With count_only or exists only the number of matching
+// tickets or whether there is any is returned back, and with group_by the tickets are grouped by status, importance or
+// assignee.
+// Tickets are matched by their metadata with metadata prefixed params, e.g. metadata.owner_ip=10.0.0.1.
 func (h *TicketHandler) Filter() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		issuer := r.URL.Query().Get("issuer")
@@ -65,30 +360,40 @@ func (h *TicketHandler) Filter() http.HandlerFunc {
 		toDate := r.URL.Query().Get("toDate")
 		pageNumber, _ := strconv.Atoi(r.URL.Query().Get("pageNumber"))
 		pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+		previewOnly, _ := strconv.ParseBool(r.URL.Query().Get("previewOnly"))
+		snoozed, _ := strconv.ParseBool(r.URL.Query().Get("snoozed"))
+		countOnly, _ := strconv.ParseBool(r.URL.Query().Get("count_only"))
+		exists, _ := strconv.ParseBool(r.URL.Query().Get("exists"))
+		estimatedTotal, _ := strconv.ParseBool(r.URL.Query().Get("estimated_total"))
+		facets, _ := strconv.ParseBool(r.URL.Query().Get("facets"))
+		resolutionCategory := r.URL.Query().Get("resolutionCategory")
+		resolutionSubCategory := r.URL.Query().Get("resolutionSubCategory")
+		rootCause := r.URL.Query().Get("rootCause")
+
+		metadata := make(map[string]string)
+		for key := range r.URL.Query() {
+			if path := strings.TrimPrefix(key, "metadata."); path != key {
+				metadata[path] = r.URL.Query().Get(key)
+			}
+		}
 
 		filterTicketsRequest := data.FilterTicketsRequest{Issuer: issuer, Owner: owner,
 			ImportanceLevel: models.TicketImportanceLevel(importanceLevel), Status: models.TicketStatus(status),
-			FromDate: fromDate, ToDate: toDate, PageNumber: pageNumber, PageSize: pageSize}
+			ResolutionCategory: resolutionCategory, ResolutionSubCategory: resolutionSubCategory,
+			RootCause: models.RootCause(rootCause), FromDate: fromDate, ToDate: toDate, PageNumber: pageNumber,
+			PageToken: r.URL.Query().Get("pageToken"), PageSize: pageSize, PreviewOnly: previewOnly, Snoozed: snoozed,
+			Metadata: metadata, CountOnly: countOnly, Exists: exists, EstimatedTotal: estimatedTotal, Facets: facets,
+			GroupBy: r.URL.Query().Get("group_by"), OrderBy: r.URL.Query().Get("order_by"),
+			ConsistencyToken: r.Header.Get(ConsistencyTokenHeader)}
 
 		in, _ := json.Marshal(filterTicketsRequest)
-		response, e := h.natsClient.RequestWithContext(r.Context(), "kiosk.tickets.filter", in)
-		if e != nil {
-			if e == nc.ErrTimeout {
-				et := errors.RequestTimeout("")
-				writeError(w, et)
-			} else {
-				et := errors.InternalServerError("unknown", "")
-				h.logger.Error(et.FingerPrint, ": ", e.Error())
-				writeError(w, et)
-			}
-
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.filter", in)
+		if !ok {
 			return
 		}
 
-		et := &errors.Type{}
-		_ = json.Unmarshal(response.Data, et)
-		if et.FingerPrint != "" {
-			writeError(w, et)
+		if countOnly || exists {
+			_, _ = w.Write(response.Data)
 			return
 		}
 
@@ -97,3 +402,189 @@ func (h *TicketHandler) Filter() http.HandlerFunc {
 		write(w, filterTicketsResponse)
 	}
 }
+
+// Counters returns back the number of tickets per status, optionally restricted to an owner or to an assignee, so
+// agents can tell the size of their own queues.
+func (h *TicketHandler) Counters() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ticketCountersRequest := data.TicketCountersRequest{Owner: r.URL.Query().Get("owner"),
+			Assignee: r.URL.Query().Get("assignee"), ConsistencyToken: r.Header.Get(ConsistencyTokenHeader)}
+
+		in, _ := json.Marshal(ticketCountersRequest)
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.counters", in)
+		if !ok {
+			return
+		}
+
+		ticketCountersResponse := &data.TicketCountersResponse{}
+		_ = json.Unmarshal(response.Data, ticketCountersResponse)
+		write(w, ticketCountersResponse)
+	}
+}
+
+// Frustrated returns back the open tickets whose customer sentiment is negative or getting worse, the most negative
+// first.
+func (h *TicketHandler) Frustrated() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		frustratedTicketsRequest := data.FrustratedTicketsRequest{Issuer: r.URL.Query().Get("issuer"), Limit: limit}
+
+		if maxScore, e := strconv.ParseFloat(r.URL.Query().Get("maxScore"), 64); e == nil {
+			frustratedTicketsRequest.MaxScore = &maxScore
+		}
+
+		if maxTrend, e := strconv.ParseFloat(r.URL.Query().Get("maxTrend"), 64); e == nil {
+			frustratedTicketsRequest.MaxTrend = &maxTrend
+		}
+
+		in, _ := json.Marshal(frustratedTicketsRequest)
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.frustrated", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// Revisions returns back the previous revisions of the subject and content of the ticket with provided id, a page of
+// them with pageSize, continued by pageToken.
+func (h *TicketHandler) Revisions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ticketID, _ := strconv.ParseInt(r.URL.Query().Get("ticketId"), 10, 64)
+
+		in, _ := json.Marshal(data.TicketRevisionsRequest{PageRequest: pageRequest(r), TicketID: ticketID})
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.revisions", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// Escalations returns back the levels the ticket with provided id has been escalated to.
+func (h *TicketHandler) Escalations() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ticketID, _ := strconv.ParseInt(r.URL.Query().Get("ticketId"), 10, 64)
+
+		in, _ := json.Marshal(data.TicketEscalationsRequest{TicketID: ticketID})
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.escalations", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// ExportPDF renders the ticket with provided id and its comments into a PDF document.
+func (h *TicketHandler) ExportPDF() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+
+		in, _ := json.Marshal(data.ID{ID: id})
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.export_pdf", in)
+		if !ok {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="ticket-%d.pdf"`, id))
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// ExportText renders the ticket with provided id and its comments into a plaintext transcript.
+func (h *TicketHandler) ExportText() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+
+		in, _ := json.Marshal(data.ID{ID: id})
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.render_text", in)
+		if !ok {
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// ExportCSV streams the tickets matching the provided criteria values as CSV, page by page. A page failing to load
+// cuts the export short, with a trailing `#error` row carrying the error code, fingerprint and request id, and the
+// connection closed before the end of the chunked response, so clients can not take the CSV as complete.
+func (h *TicketHandler) ExportCSV() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		snoozed, _ := strconv.ParseBool(r.URL.Query().Get("snoozed"))
+		filterTicketsRequest := data.FilterTicketsRequest{
+			Issuer:           r.URL.Query().Get("issuer"),
+			Owner:            r.URL.Query().Get("owner"),
+			ImportanceLevel:  models.TicketImportanceLevel(r.URL.Query().Get("importanceLevel")),
+			Status:           models.TicketStatus(r.URL.Query().Get("status")),
+			FromDate:         r.URL.Query().Get("fromDate"),
+			ToDate:           r.URL.Query().Get("toDate"),
+			PageNumber:       1,
+			PageSize:         25,
+			Snoozed:          snoozed,
+			OrderBy:          r.URL.Query().Get("order_by"),
+			ConsistencyToken: r.Header.Get(ConsistencyTokenHeader),
+		}
+
+		// Pin the upper bound, so tickets modified during the export do not shift the pages.
+		if filterTicketsRequest.ToDate == "" {
+			filterTicketsRequest.ToDate = time.Now().UTC().Format(time.RFC3339Nano)
+		}
+
+		in, _ := json.Marshal(filterTicketsRequest)
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.filter", in)
+		if !ok {
+			return
+		}
+
+		writer := newCSVWriter(w, "tickets.csv")
+		_ = writer.Write([]string{"ID", "issuer", "owner", "subject", "content", "metadata", "importanceLevel",
+			"status", "comments", "createdAt", "modifiedAt"})
+
+		for {
+			// Exports outlive the write timeout of the server, so each page gets a deadline of its own instead.
+			extendWriteDeadline(r, csvPageWriteWait)
+
+			filterTicketsResponse := &data.FilterTicketsResponse{}
+			_ = json.Unmarshal(response.Data, filterTicketsResponse)
+
+			for _, t := range filterTicketsResponse.Tickets {
+				_ = writer.Write([]string{strconv.FormatInt(t.ID, 10), documents.CSVCell(t.Issuer),
+					documents.CSVCell(t.Owner), documents.CSVCell(t.Subject), documents.CSVCell(t.Content),
+					documents.CSVCell(t.Metadata), string(t.ImportanceLevel), string(t.Status),
+					strconv.Itoa(len(t.Comments)), t.CreatedAt, t.ModifiedAt})
+			}
+			flushCSV(w, writer)
+
+			if !filterTicketsResponse.HasNextPage {
+				return
+			}
+
+			filterTicketsRequest.PageNumber++
+			in, _ = json.Marshal(filterTicketsRequest)
+
+			// Headers are already sent, so on failures the only option is to cut the stream short.
+			var e error
+			response, e = h.natsClient.RequestWithContext(r.Context(), "kiosk.tickets.filter", in)
+			if e != nil {
+				et := errors.InternalServerError("unknown", "")
+				if e == nc.ErrTimeout {
+					et = errors.RequestTimeout("")
+				}
+
+				h.logger.Warn(et.FingerPrint, ": Could not load next page of tickets for csv export: ", e.Error())
+				abortCSV(w, writer, et)
+			}
+
+			if et, isError := errors.FromEnvelope(response.Data); isError {
+				h.logger.Warn(et.FingerPrint, ": Could not load next page of tickets for csv export")
+				abortCSV(w, writer, et)
+			}
+		}
+	}
+}
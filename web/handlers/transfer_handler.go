@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// TransferHandler is the handler implementation of transferring tickets between kiosk deployments.
+type TransferHandler struct {
+	logger     *zap.SugaredLogger
+	natsClient *nc.Conn
+}
+
+// NewTransferHandler returns back a newly created and ready to use TransferHandler.
+func NewTransferHandler(logger *zap.SugaredLogger, natsClient *nc.Conn) *TransferHandler {
+	return &TransferHandler{logger: logger, natsClient: natsClient}
+}
+
+// Export returns back the next page of tickets of an issuer, with their comments. Anonymized exports strip the personal
+// data of the tickets, for analytics.
+func (h *TransferHandler) Export() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		afterID, _ := strconv.ParseInt(r.URL.Query().Get("afterId"), 10, 64)
+		pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+		anonymized, _ := strconv.ParseBool(r.URL.Query().Get("anonymized"))
+
+		in, _ := json.Marshal(&data.ExportTicketsRequest{Issuer: r.URL.Query().Get("issuer"), AfterID: afterID,
+			PageSize: pageSize, Anonymized: anonymized})
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.export", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// Import imports a ticket exported from another kiosk deployment, unless it has already been imported.
+func (h *TransferHandler) Import() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.tickets.import", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/jibitters/kiosk/web/data"
+	nc "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// signatureHeader carries the sha256=<hex> HMAC signature of inbound webhook deliveries, signing the timestamp of
+// timestampHeader and the payload as `timestamp.payload`.
+const (
+	signatureHeader = "X-Kiosk-Signature"
+	timestampHeader = "X-Kiosk-Timestamp"
+)
+
+// WebhookHandler is the handler implementation of inbound webhooks related resource.
+type WebhookHandler struct {
+	logger     *zap.SugaredLogger
+	natsClient *nc.Conn
+}
+
+// NewWebhookHandler returns back a newly created and ready to use WebhookHandler.
+func NewWebhookHandler(logger *zap.SugaredLogger, natsClient *nc.Conn) *WebhookHandler {
+	return &WebhookHandler{logger: logger, natsClient: natsClient}
+}
+
+// SaveSource saves an inbound webhook source, replacing the previous one with the same name.
+func (h *WebhookHandler) SaveSource() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := ioutil.ReadAll(r.Body)
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.webhooks.sources.save", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
+
+// LoadSource returns back the inbound webhook source with provided name, without its secret.
+func (h *WebhookHandler) LoadSource() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := json.Marshal(data.WebhookSourceRequest{Name: r.URL.Query().Get("name")})
+
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.webhooks.sources.load", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// DeleteSource deletes the inbound webhook source with provided name.
+func (h *WebhookHandler) DeleteSource() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in, _ := json.Marshal(data.WebhookSourceRequest{Name: r.URL.Query().Get("name")})
+
+		if _, ok := request(h.logger, h.natsClient, w, r, "kiosk.webhooks.sources.delete", in); !ok {
+			return
+		}
+
+		writeNoContent(w)
+	}
+}
+
+// Receive receives the deliveries of the inbound webhook source named by the source query parameter and returns back
+// the id of the ticket they acted on.
+func (h *WebhookHandler) Receive() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := ioutil.ReadAll(r.Body)
+
+		webhookRequest := data.WebhookRequest{Source: r.URL.Query().Get("source"),
+			Signature: r.Header.Get(signatureHeader), Timestamp: r.Header.Get(timestampHeader), Payload: payload}
+
+		in, _ := json.Marshal(webhookRequest)
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.webhooks.inbound", in)
+		if !ok {
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
+
+// ReceiveAlertmanager receives the deliveries of Prometheus Alertmanager addressed to the inbound webhook source named
+// by the source query parameter. Alertmanager authenticates by the secret of the source as its bearer token.
+func (h *WebhookHandler) ReceiveAlertmanager() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := ioutil.ReadAll(r.Body)
+
+		webhookRequest := data.WebhookRequest{Source: r.URL.Query().Get("source"),
+			Token: strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), Payload: payload}
+
+		in, _ := json.Marshal(webhookRequest)
+		response, ok := request(h.logger, h.natsClient, w, r, "kiosk.webhooks.alertmanager", in)
+		if !ok {
+			return
+		}
+
+		if len(response.Data) == 0 {
+			writeNoContent(w)
+			return
+		}
+
+		_, _ = w.Write(response.Data)
+	}
+}
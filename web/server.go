@@ -3,6 +3,7 @@ package web
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -14,11 +15,85 @@ import (
 )
 
 const (
-	v1       = "/v1"
-	echo     = "/echo"
-	tickets  = "/tickets"
-	comments = "/comments"
-	metrics  = "/metrics"
+	v1            = "/v1"
+	echo          = "/echo"
+	tickets       = "/tickets"
+	comments      = "/comments"
+	counters      = "/counters"
+	pdf           = "/pdf"
+	text          = "/text"
+	csv           = "/csv"
+	references    = "/references"
+	reports       = "/reports"
+	daily         = "/daily"
+	schedules     = "/schedules"
+	agents        = "/agents"
+	workload      = "/workload"
+	issuers       = "/issuers"
+	settings      = "/settings"
+	windows       = "/maintenance_windows"
+	notifications = "/notifications"
+	bounces       = "/bounces"
+	preferences   = "/preferences"
+	savedSearches = "/saved_searches"
+	metrics       = "/metrics"
+	escalations   = "/escalations"
+	github        = "/github"
+	jira          = "/jira"
+	webhooks      = "/webhooks"
+	sources       = "/sources"
+	inbound       = "/inbound"
+	alertmanager  = "/alertmanager"
+	paging        = "/paging"
+	onCall        = "/on_call"
+	incident      = "/incident"
+	snooze        = "/snooze"
+	assign        = "/assign"
+	unassign      = "/unassign"
+	assigned      = "/assigned"
+	slaTargets    = "/sla_targets"
+	drafts        = "/drafts"
+	revisions     = "/revisions"
+	diff          = "/diff"
+	work          = "/work"
+	timeSpent     = "/time"
+	billable      = "/billable"
+	billing       = "/billing"
+	billingRates  = "/billing_rates"
+	approvals     = "/approvals"
+	approve       = "/approve"
+	reject        = "/reject"
+	approvers     = "/approvers"
+	requirements  = "/transition_requirements"
+	resolutions   = "/resolutions"
+	categories    = "/resolution_categories"
+	frustrated    = "/frustrated"
+	stream        = "/stream"
+	events        = "/events"
+	runtime       = "/runtime"
+	entries       = "/entries"
+	tenants       = "/tenants"
+	export        = "/export"
+	imports       = "/import"
+	macros        = "/macros"
+	apply         = "/apply"
+	duplicates    = "/duplicates"
+	merge         = "/merge"
+	incidents     = "/incidents"
+	public        = "/public"
+	resolve       = "/resolve"
+	attachments   = "/attachments"
+	content       = "/content"
+	admin         = "/admin"
+	caches        = "/caches"
+	flush         = "/flush"
+	apiKeys       = "/api_keys"
+	rotate        = "/rotate"
+	archive       = "/archive"
+	sla           = "/sla"
+	recompute     = "/recompute"
+	auditLogs     = "/audit_logs"
+	verify        = "/verify"
 )
 
 // StartServer setups and then runs an HTTP server.
@@ -37,15 +112,19 @@ func StartServer(logger *zap.SugaredLogger, config *configuring.Config, natsClie
 	logger.Info("web.server.write_timeout -> ", writeTimeout)
 	logger.Info("web.server.idle_timeout -> ", idleTimeout)
 
-	router := setupRoutes(logger, natsClient)
+	cors := corsOf(logger, config)
+	meddlers := handlers.NewMeddlers(cors, securityHeadersOf(logger, config))
+	router := setupRoutes(logger, natsClient, meddlers, streamOf(logger, config, cors), adminOf(logger, config))
 
+	handler := meddlers.CORSMiddleware(meddlers.SecurityHeadersMiddleware(router))
 	server := &http.Server{
 		Addr:              fmt.Sprintf("%v:%v", host, port),
-		Handler:           router,
+		Handler:           meddlers.RequestIDMiddleware(handler),
 		ReadTimeout:       readTimeout,
 		ReadHeaderTimeout: readHeaderTimeout,
 		WriteTimeout:      writeTimeout,
 		IdleTimeout:       idleTimeout,
+		ConnContext:       handlers.ConnContext,
 	}
 
 	go func() { _ = server.ListenAndServe() }()
@@ -54,7 +133,88 @@ func StartServer(logger *zap.SugaredLogger, config *configuring.Config, natsClie
 	return server
 }
 
-func setupRoutes(logger *zap.SugaredLogger, natsClient *nc.Conn) *mux.Router {
+// corsOf returns back the CORS policy configured in config instance, cross-origin requests are not allowed unless
+// some origins are configured.
+func corsOf(logger *zap.SugaredLogger, config *configuring.Config) handlers.CORS {
+	cors := handlers.CORS{
+		AllowedOrigins: config.Get("web.cors.allowed_origins").SliceOfStringOrElse([]string{}),
+		AllowedMethods: config.Get("web.cors.allowed_methods").SliceOfStringOrElse([]string{http.MethodGet,
+			http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}),
+		AllowedHeaders: config.Get("web.cors.allowed_headers").SliceOfStringOrElse([]string{"Content-Type",
+			"Authorization", handlers.ConsistencyTokenHeader}),
+		ExposedHeaders: config.Get("web.cors.exposed_headers").SliceOfStringOrElse([]string{
+			handlers.ConsistencyTokenHeader, handlers.RequestIDHeader, "Content-Disposition"}),
+		AllowCredentials: config.Get("web.cors.allow_credentials").StringOrElse("false") == "true",
+		MaxAge:           config.Get("web.cors.max_age").DurationOrElse(10 * time.Minute),
+	}
+
+	logger.Info("web.cors.allowed_origins -> ", cors.AllowedOrigins)
+	logger.Info("web.cors.allowed_methods -> ", cors.AllowedMethods)
+	logger.Info("web.cors.allowed_headers -> ", cors.AllowedHeaders)
+	logger.Info("web.cors.exposed_headers -> ", cors.ExposedHeaders)
+	logger.Info("web.cors.allow_credentials -> ", cors.AllowCredentials)
+	logger.Info("web.cors.max_age -> ", cors.MaxAge)
+
+	return cors
+}
+
+// securityHeadersOf returns back the security headers configuration in config instance.
+func securityHeadersOf(logger *zap.SugaredLogger, config *configuring.Config) handlers.SecurityHeaders {
+	securityHeaders := handlers.SecurityHeaders{
+		Enabled:    config.Get("web.security_headers.enabled").StringOrElse("true") == "true",
+		HSTSMaxAge: config.Get("web.security_headers.hsts_max_age").DurationOrElse(0),
+	}
+
+	logger.Info("web.security_headers.enabled -> ", securityHeaders.Enabled)
+	logger.Info("web.security_headers.hsts_max_age -> ", securityHeaders.HSTSMaxAge)
+
+	return securityHeaders
+}
+
+// streamOf returns back the event stream configuration in config instance. Browsers may connect from the origins
+// allowed to make cross-origin requests.
+func streamOf(logger *zap.SugaredLogger, config *configuring.Config, cors handlers.CORS) handlers.Stream {
+	stream := handlers.Stream{
+		Tokens:           config.Get("web.stream.tokens").SliceOfStringOrElse([]string{}),
+		AllowedOrigins:   cors.AllowedOrigins,
+		MaxSubscriptions: config.Get("web.stream.max_subscriptions").IntOrElse(20),
+	}
+
+	logger.Info("web.stream.tokens -> ", len(stream.Tokens), " token(s)")
+	logger.Info("web.stream.max_subscriptions -> ", stream.MaxSubscriptions)
+
+	return stream
+}
+
+// adminOf returns back the admin API configuration in config instance. Tokens are configured as operator:token, the
+// operator being who the audit logs of the actions taken by the token get attributed to. Tokens of no or too long
+// operators are ignored.
+func adminOf(logger *zap.SugaredLogger, config *configuring.Config) handlers.Admin {
+	admin := handlers.Admin{}
+	for i, t := range config.Get("web.admin.tokens").SliceOfStringOrElse([]string{}) {
+		parts := strings.SplitN(t, ":", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			logger.Warn("web.admin.tokens[", i, "] is not an operator:token pair, ignoring it")
+			continue
+		}
+
+		operator := strings.TrimSpace(parts[0])
+		if operator == "" || len(operator) > 50 {
+			logger.Warn("web.admin.tokens[", i, "] has no or a too long operator, ignoring it")
+			continue
+		}
+
+		admin.Tokens = append(admin.Tokens, handlers.AdminToken{Operator: operator, Token: parts[1]})
+	}
+
+	logger.Info("web.admin.tokens -> ", len(admin.Tokens), " token(s)")
+
+	return admin
+}
+
+func setupRoutes(logger *zap.SugaredLogger, natsClient *nc.Conn, meddlers *handlers.Meddlers,
+	streamConfig handlers.Stream, adminConfig handlers.Admin) *mux.Router {
+
 	// Router
 	router := mux.NewRouter().
 		PathPrefix(v1).
@@ -62,7 +222,6 @@ func setupRoutes(logger *zap.SugaredLogger, natsClient *nc.Conn) *mux.Router {
 		Subrouter()
 
 	// Meddlers
-	meddlers := handlers.NewMeddlers()
 	router.Use(meddlers.JSONContentTypeHeaderMiddleware)
 
 	// Echo handler
@@ -71,13 +230,189 @@ func setupRoutes(logger *zap.SugaredLogger, natsClient *nc.Conn) *mux.Router {
 
 	// Ticket handler
 	ticketHandler := handlers.NewTicketHandler(logger, natsClient)
+	router.Methods(http.MethodGet).Path(tickets + counters).HandlerFunc(ticketHandler.Counters())
+	router.Methods(http.MethodGet).Path(tickets + pdf).HandlerFunc(ticketHandler.ExportPDF())
+	router.Methods(http.MethodGet).Path(tickets + text).HandlerFunc(ticketHandler.ExportText())
+	router.Methods(http.MethodGet).Path(tickets + csv).HandlerFunc(ticketHandler.ExportCSV())
+	router.Methods(http.MethodPost).Path(tickets + snooze).HandlerFunc(ticketHandler.Snooze())
+	router.Methods(http.MethodPost).Path(tickets + assign).HandlerFunc(ticketHandler.Assign())
+	router.Methods(http.MethodPost).Path(tickets + unassign).HandlerFunc(ticketHandler.Unassign())
+	router.Methods(http.MethodGet).Path(tickets + assigned).HandlerFunc(ticketHandler.Assigned())
+	router.Methods(http.MethodGet).Path(tickets + revisions).HandlerFunc(ticketHandler.Revisions())
+	router.Methods(http.MethodGet).Path(tickets + frustrated).HandlerFunc(ticketHandler.Frustrated())
+	router.Methods(http.MethodGet).Path(tickets + escalations).HandlerFunc(ticketHandler.Escalations())
+	router.Methods(http.MethodPost).Path(tickets + approvals).HandlerFunc(ticketHandler.RequestApproval())
+	router.Methods(http.MethodPost).Path(tickets + approvals + approve).HandlerFunc(ticketHandler.Approve())
+	router.Methods(http.MethodPost).Path(tickets + approvals + reject).HandlerFunc(ticketHandler.Reject())
+	router.Methods(http.MethodGet).Path(tickets + approvals).HandlerFunc(ticketHandler.Approvals())
+	router.Methods(http.MethodPost).Path(tickets + work).HandlerFunc(ticketHandler.LogWork())
+	router.Methods(http.MethodGet).Path(tickets + work).HandlerFunc(ticketHandler.WorkLogs())
+	router.Methods(http.MethodPut).Path(tickets + billable).HandlerFunc(ticketHandler.SetBillable())
+	router.Methods(http.MethodPost).Path(tickets + macros).HandlerFunc(ticketHandler.CreateMacro())
+	router.Methods(http.MethodGet).Path(tickets + macros).HandlerFunc(ticketHandler.Macros())
+	router.Methods(http.MethodPut).Path(tickets + macros).HandlerFunc(ticketHandler.UpdateMacro())
+	router.Methods(http.MethodDelete).Path(tickets + macros).HandlerFunc(ticketHandler.DeleteMacro())
+	router.Methods(http.MethodPost).Path(tickets + macros + apply).HandlerFunc(ticketHandler.ApplyMacro())
+	router.Methods(http.MethodGet).Path(tickets + duplicates).HandlerFunc(ticketHandler.Duplicates())
+	router.Methods(http.MethodPost).Path(tickets + merge).HandlerFunc(ticketHandler.Merge())
+	router.Methods(http.MethodGet).Path(tickets + public).HandlerFunc(ticketHandler.LoadPublic())
+
+	// Reference handler, registered ahead of the ticket prefix routes.
+	referenceHandler := handlers.NewReferenceHandler(logger, natsClient)
+	router.Methods(http.MethodPost).Path(tickets + references).HandlerFunc(referenceHandler.Attach())
+	router.Methods(http.MethodGet).Path(tickets + references).HandlerFunc(referenceHandler.List())
+	router.Methods(http.MethodDelete).Path(tickets + references).HandlerFunc(referenceHandler.Detach())
+	router.Methods(http.MethodPost).Path(tickets + escalations + github).
+		HandlerFunc(referenceHandler.EscalateToGitHub())
+	router.Methods(http.MethodPost).Path(tickets + escalations + jira).HandlerFunc(referenceHandler.EscalateToJira())
+	router.Methods(http.MethodPost).Path(webhooks + github).HandlerFunc(referenceHandler.GitHubWebhook())
+	router.Methods(http.MethodPost).Path(webhooks + jira).HandlerFunc(referenceHandler.JiraWebhook())
+
+	// Webhook handler
+	webhookHandler := handlers.NewWebhookHandler(logger, natsClient)
+	router.Methods(http.MethodPut).Path(webhooks + sources).HandlerFunc(webhookHandler.SaveSource())
+	router.Methods(http.MethodGet).Path(webhooks + sources).HandlerFunc(webhookHandler.LoadSource())
+	router.Methods(http.MethodDelete).Path(webhooks + sources).HandlerFunc(webhookHandler.DeleteSource())
+	router.Methods(http.MethodPost).Path(webhooks + inbound).HandlerFunc(webhookHandler.Receive())
+	router.Methods(http.MethodPost).Path(webhooks + alertmanager).HandlerFunc(webhookHandler.ReceiveAlertmanager())
+
+	// Status page handler, registered ahead of the ticket prefix routes.
+	statusPageHandler := handlers.NewStatusPageHandler(logger, natsClient)
+	router.Methods(http.MethodPut).Path(tickets + incident).HandlerFunc(statusPageHandler.Publish())
+	router.Methods(http.MethodGet).Path(tickets + incident).HandlerFunc(statusPageHandler.Load())
+
+	// Draft handler, registered ahead of the ticket prefix routes.
+	draftHandler := handlers.NewDraftHandler(logger, natsClient)
+	router.Methods(http.MethodPut).Path(tickets + drafts).HandlerFunc(draftHandler.Save())
+	router.Methods(http.MethodGet).Path(tickets + drafts).HandlerFunc(draftHandler.Load())
+	router.Methods(http.MethodDelete).Path(tickets + drafts).HandlerFunc(draftHandler.Delete())
+
+	// Transfer handler, registered ahead of the ticket prefix routes.
+	transferHandler := handlers.NewTransferHandler(logger, natsClient)
+	router.Methods(http.MethodGet).Path(tickets + export).HandlerFunc(transferHandler.Export())
+	router.Methods(http.MethodPost).Path(tickets + imports).HandlerFunc(transferHandler.Import())
+
 	router.Methods(http.MethodPost).PathPrefix(tickets).HandlerFunc(ticketHandler.Create())
 	router.Methods(http.MethodGet).PathPrefix(tickets).HandlerFunc(ticketHandler.Filter())
 
 	// Comment handler
 	commentHandler := handlers.NewCommentHandler(logger, natsClient)
+	router.Methods(http.MethodGet).Path(comments).HandlerFunc(commentHandler.Filter())
+	router.Methods(http.MethodGet).Path(comments + revisions + diff).HandlerFunc(commentHandler.DiffRevisions())
 	router.Methods(http.MethodPost).PathPrefix(comments).HandlerFunc(commentHandler.Create())
 
+	// Attachment handler
+	attachmentHandler := handlers.NewAttachmentHandler(logger, natsClient)
+	router.Methods(http.MethodPost).Path(attachments).HandlerFunc(attachmentHandler.Upload())
+	router.Methods(http.MethodGet).Path(attachments).HandlerFunc(attachmentHandler.Load())
+	router.Methods(http.MethodGet).Path(attachments + content).HandlerFunc(attachmentHandler.Download())
+	router.Methods(http.MethodDelete).Path(attachments).HandlerFunc(attachmentHandler.Delete())
+
+	// Report handler
+	reportHandler := handlers.NewReportHandler(logger, natsClient)
+	router.Methods(http.MethodGet).Path(reports + daily).HandlerFunc(reportHandler.Daily())
+	router.Methods(http.MethodGet).Path(reports + daily + csv).HandlerFunc(reportHandler.DailyCSV())
+	router.Methods(http.MethodGet).Path(reports + workload).HandlerFunc(reportHandler.Workload())
+	router.Methods(http.MethodGet).Path(reports + workload + csv).HandlerFunc(reportHandler.WorkloadCSV())
+	router.Methods(http.MethodGet).Path(reports + agents).HandlerFunc(reportHandler.AgentMetrics())
+	router.Methods(http.MethodGet).Path(reports + timeSpent).HandlerFunc(reportHandler.TimeReport())
+	router.Methods(http.MethodGet).Path(reports + resolutions).HandlerFunc(reportHandler.Resolutions())
+	router.Methods(http.MethodGet).Path(reports + billing).HandlerFunc(reportHandler.Billing())
+	router.Methods(http.MethodGet).Path(reports + billing + csv).HandlerFunc(reportHandler.BillingCSV())
+	router.Methods(http.MethodPost).Path(reports + schedules).HandlerFunc(reportHandler.CreateSchedule())
+	router.Methods(http.MethodGet).Path(reports + schedules).HandlerFunc(reportHandler.ListSchedules())
+	router.Methods(http.MethodDelete).Path(reports + schedules).HandlerFunc(reportHandler.DeleteSchedule())
+
+	// Issuer handler
+	issuerHandler := handlers.NewIssuerHandler(logger, natsClient)
+	router.Methods(http.MethodPut).Path(issuers + settings).HandlerFunc(issuerHandler.SaveSettings())
+	router.Methods(http.MethodGet).Path(issuers + settings).HandlerFunc(issuerHandler.LoadSettings())
+	router.Methods(http.MethodPut).Path(issuers + paging).HandlerFunc(issuerHandler.SavePagingPolicy())
+	router.Methods(http.MethodGet).Path(issuers + paging).HandlerFunc(issuerHandler.LoadPagingPolicy())
+	router.Methods(http.MethodDelete).Path(issuers + paging).HandlerFunc(issuerHandler.DeletePagingPolicy())
+	router.Methods(http.MethodPost).Path(issuers + onCall).HandlerFunc(issuerHandler.CreateOnCallShift())
+	router.Methods(http.MethodGet).Path(issuers + onCall).HandlerFunc(issuerHandler.OnCallShifts())
+	router.Methods(http.MethodDelete).Path(issuers + onCall).HandlerFunc(issuerHandler.DeleteOnCallShift())
+	router.Methods(http.MethodPut).Path(issuers + slaTargets).HandlerFunc(issuerHandler.SaveSLATarget())
+	router.Methods(http.MethodGet).Path(issuers + slaTargets).HandlerFunc(issuerHandler.ListSLATargets())
+	router.Methods(http.MethodPut).Path(issuers + billingRates).HandlerFunc(issuerHandler.SaveBillingRate())
+	router.Methods(http.MethodGet).Path(issuers + billingRates).HandlerFunc(issuerHandler.LoadBillingRate())
+	router.Methods(http.MethodDelete).Path(issuers + billingRates).HandlerFunc(issuerHandler.DeleteBillingRate())
+	router.Methods(http.MethodPut).Path(issuers + escalations).HandlerFunc(issuerHandler.SaveEscalationPolicy())
+	router.Methods(http.MethodGet).Path(issuers + escalations).HandlerFunc(issuerHandler.LoadEscalationPolicies())
+	router.Methods(http.MethodDelete).Path(issuers + escalations).HandlerFunc(issuerHandler.DeleteEscalationPolicy())
+	router.Methods(http.MethodPut).Path(issuers + approvers).HandlerFunc(issuerHandler.SaveApprovers())
+	router.Methods(http.MethodGet).Path(issuers + approvers).HandlerFunc(issuerHandler.LoadApprovers())
+	router.Methods(http.MethodDelete).Path(issuers + approvers).HandlerFunc(issuerHandler.DeleteApprovers())
+	router.Methods(http.MethodPut).Path(issuers + requirements).HandlerFunc(issuerHandler.SaveTransitionRequirements())
+	router.Methods(http.MethodGet).Path(issuers + requirements).HandlerFunc(issuerHandler.LoadTransitionRequirements())
+	router.Methods(http.MethodDelete).Path(issuers + requirements).
+		HandlerFunc(issuerHandler.DeleteTransitionRequirements())
+	router.Methods(http.MethodPut).Path(issuers + categories).HandlerFunc(issuerHandler.SaveResolutionCategory())
+	router.Methods(http.MethodGet).Path(issuers + categories).HandlerFunc(issuerHandler.ListResolutionCategories())
+	router.Methods(http.MethodDelete).Path(issuers + categories).HandlerFunc(issuerHandler.DeleteResolutionCategory())
+
+	// Maintenance window handler
+	maintenanceWindowHandler := handlers.NewMaintenanceWindowHandler(logger, natsClient)
+	router.Methods(http.MethodPost).Path(windows).HandlerFunc(maintenanceWindowHandler.Create())
+	router.Methods(http.MethodGet).Path(windows).HandlerFunc(maintenanceWindowHandler.List())
+	router.Methods(http.MethodDelete).Path(windows).HandlerFunc(maintenanceWindowHandler.Delete())
+
+	// Notification handler
+	notificationHandler := handlers.NewNotificationHandler(logger, natsClient)
+	router.Methods(http.MethodGet).Path(notifications).HandlerFunc(notificationHandler.List())
+	router.Methods(http.MethodPost).Path(notifications + bounces).HandlerFunc(notificationHandler.ReportBounce())
+	router.Methods(http.MethodPut).Path(notifications + preferences).
+		HandlerFunc(notificationHandler.SavePreferences())
+	router.Methods(http.MethodGet).Path(notifications + preferences).
+		HandlerFunc(notificationHandler.LoadPreferences())
+	router.Methods(http.MethodDelete).Path(notifications + preferences).
+		HandlerFunc(notificationHandler.DeletePreferences())
+	router.Methods(http.MethodPost).Path(notifications + savedSearches).
+		HandlerFunc(notificationHandler.CreateSavedSearch())
+	router.Methods(http.MethodGet).Path(notifications + savedSearches).
+		HandlerFunc(notificationHandler.ListSavedSearches())
+	router.Methods(http.MethodDelete).Path(notifications + savedSearches).
+		HandlerFunc(notificationHandler.DeleteSavedSearch())
+
+	// Tenant handler
+	tenantHandler := handlers.NewTenantHandler(logger, natsClient)
+	router.Methods(http.MethodPost).Path(tenants).HandlerFunc(tenantHandler.Provision())
+	router.Methods(http.MethodGet).Path(tenants).HandlerFunc(tenantHandler.Load())
+	router.Methods(http.MethodDelete).Path(tenants).HandlerFunc(tenantHandler.Deactivate())
+
+	// Incident handler
+	incidentHandler := handlers.NewIncidentHandler(logger, natsClient)
+	router.Methods(http.MethodPost).Path(incidents).HandlerFunc(incidentHandler.Create())
+	router.Methods(http.MethodGet).Path(incidents).HandlerFunc(incidentHandler.Load())
+	router.Methods(http.MethodPost).Path(incidents + tickets).HandlerFunc(incidentHandler.Attach())
+	router.Methods(http.MethodDelete).Path(incidents + tickets).HandlerFunc(incidentHandler.Detach())
+	router.Methods(http.MethodPost).Path(incidents + resolve).HandlerFunc(incidentHandler.Resolve())
+
+	// Runtime handler
+	runtimeHandler := handlers.NewRuntimeHandler(logger, natsClient)
+	router.Methods(http.MethodPut).Path(runtime + entries).HandlerFunc(runtimeHandler.Save())
+	router.Methods(http.MethodGet).Path(runtime + entries).HandlerFunc(runtimeHandler.Load())
+	router.Methods(http.MethodDelete).Path(runtime + entries).HandlerFunc(runtimeHandler.Delete())
+
+	// Stream handler, the stream is disabled unless some tokens are configured.
+	if len(streamConfig.Tokens) > 0 {
+		streamHandler := handlers.NewStreamHandler(logger, natsClient, streamConfig)
+		router.Methods(http.MethodGet).Path(stream).HandlerFunc(streamHandler.Stream())
+		router.Methods(http.MethodGet).Path(stream + events).HandlerFunc(streamHandler.Events())
+	}
+
+	// Admin handler, the admin API is disabled unless some tokens are configured.
+	if len(adminConfig.Tokens) > 0 {
+		adminHandler := handlers.NewAdminHandler(logger, natsClient, adminConfig)
+		router.Methods(http.MethodPost).Path(admin + caches + flush).HandlerFunc(adminHandler.FlushCaches())
+		router.Methods(http.MethodPost).Path(admin + apiKeys + rotate).HandlerFunc(adminHandler.RotateAPIKeys())
+		router.Methods(http.MethodPost).Path(admin + tickets + archive).HandlerFunc(adminHandler.ArchiveTickets())
+		router.Methods(http.MethodPost).Path(admin + sla + recompute).HandlerFunc(adminHandler.RecomputeSLA())
+		router.Methods(http.MethodGet).Path(admin + auditLogs).HandlerFunc(adminHandler.AuditLogs())
+		router.Methods(http.MethodGet).Path(admin + auditLogs + verify).HandlerFunc(adminHandler.VerifyAuditLogs())
+	}
+
 	// Metrics handler
 	router.Handle(metrics, promhttp.Handler())
 
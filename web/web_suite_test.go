@@ -0,0 +1,24 @@
+package web_test
+
+import (
+	"flag"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var pgHost string
+
+// update records the responses of the server as the golden responses of the contracts, instead of verifying them.
+var update bool
+
+func init() {
+	flag.StringVar(&pgHost, "pg.host", "localhost", "")
+	flag.BoolVar(&update, "contracts.update", false, "")
+}
+
+func TestWeb(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Web Suite")
+}
@@ -0,0 +1,81 @@
+// Package webhooks holds the helpers shared by the receivers of webhook deliveries.
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// signaturePrefix is the scheme of signatures, the hex encoded HMAC-SHA256 of payloads follows it.
+const signaturePrefix = "sha256="
+
+// Sign returns back the sha256=<hex> HMAC signature of a payload, as GitHub, Jira and kiosk itself sign them.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature verifies the sha256=<hex> HMAC signature of a payload in constant time. Deliveries are never
+// accepted without a secret, as anyone could forge their signatures then.
+func VerifySignature(secret, signature string, payload []byte) error {
+	if secret == "" {
+		return errors.New("no webhook secret is configured")
+	}
+
+	if !hmac.Equal([]byte(Sign(secret, payload)), []byte(signature)) {
+		return errors.New("signature mismatch")
+	}
+
+	return nil
+}
+
+// SignTimestamped returns back the sha256=<hex> HMAC signature of a payload sent at the unix timestamp, signing
+// `timestamp.payload`, so the signature does not hold for the same payload sent at another time.
+func SignTimestamped(secret string, timestamp int64, payload []byte) string {
+	return Sign(secret, append([]byte(strconv.FormatInt(timestamp, 10)+"."), payload...))
+}
+
+// VerifyTimestampedSignature verifies the signature of a payload sent at the unix timestamp, see SignTimestamped, in
+// constant time. Deliveries sent further than maxSkew away from now are rejected as well, so captured deliveries can
+// not be replayed later on. Deliveries are never accepted without a secret.
+func VerifyTimestampedSignature(secret, signature, timestamp string, payload []byte, now time.Time,
+	maxSkew time.Duration) error {
+
+	if secret == "" {
+		return errors.New("no webhook secret is configured")
+	}
+
+	sentAt, e := strconv.ParseInt(timestamp, 10, 64)
+	if e != nil {
+		return errors.New("invalid timestamp")
+	}
+
+	if !hmac.Equal([]byte(SignTimestamped(secret, sentAt, payload)), []byte(signature)) {
+		return errors.New("signature mismatch")
+	}
+
+	if skew := now.Sub(time.Unix(sentAt, 0)); skew > maxSkew || skew < -maxSkew {
+		return errors.New("timestamp out of the allowed skew")
+	}
+
+	return nil
+}
+
+// VerifyToken verifies the bearer token of a delivery in constant time, for senders that can not sign their payloads,
+// e.g. Prometheus Alertmanager. Deliveries are never accepted without a secret.
+func VerifyToken(secret, token string) error {
+	if secret == "" {
+		return errors.New("no webhook secret is configured")
+	}
+
+	if !hmac.Equal([]byte(secret), []byte(token)) {
+		return errors.New("token mismatch")
+	}
+
+	return nil
+}
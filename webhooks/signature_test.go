@@ -0,0 +1,81 @@
+package webhooks_test
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/jibitters/kiosk/webhooks"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Signature", func() {
+	payload := []byte(`{"action":"CREATE","externalKey":"disk-full"}`)
+
+	Context("When VerifySignature called", func() {
+		It("Should accept the signature of the same secret and payload", func() {
+			signature := webhooks.Sign("secret", payload)
+
+			Ω(signature).Should(HavePrefix("sha256="))
+			Ω(webhooks.VerifySignature("secret", signature, payload)).Should(Succeed())
+		})
+
+		It("Should reject tampered payloads, other secrets and missing signatures", func() {
+			signature := webhooks.Sign("secret", payload)
+
+			Ω(webhooks.VerifySignature("secret", signature, []byte(`{"action":"CLOSE"}`))).ShouldNot(Succeed())
+			Ω(webhooks.VerifySignature("another", signature, payload)).ShouldNot(Succeed())
+			Ω(webhooks.VerifySignature("secret", "", payload)).ShouldNot(Succeed())
+		})
+
+		It("Should reject every signature when no secret is configured", func() {
+			Ω(webhooks.VerifySignature("", webhooks.Sign("", payload), payload)).ShouldNot(Succeed())
+		})
+	})
+
+	Context("When VerifyTimestampedSignature called", func() {
+		sentAt := time.Unix(1602844800, 0)
+		timestamp := strconv.FormatInt(sentAt.Unix(), 10)
+
+		It("Should accept the signature of the same secret, timestamp and payload within the skew", func() {
+			signature := webhooks.SignTimestamped("secret", sentAt.Unix(), payload)
+
+			Ω(signature).Should(HavePrefix("sha256="))
+			Ω(webhooks.VerifyTimestampedSignature("secret", signature, timestamp, payload,
+				sentAt.Add(5*time.Minute), 5*time.Minute)).Should(Succeed())
+			Ω(webhooks.VerifyTimestampedSignature("secret", signature, timestamp, payload,
+				sentAt.Add(-5*time.Minute), 5*time.Minute)).Should(Succeed())
+		})
+
+		It("Should reject deliveries replayed after the skew or sent too far ahead", func() {
+			signature := webhooks.SignTimestamped("secret", sentAt.Unix(), payload)
+
+			Ω(webhooks.VerifyTimestampedSignature("secret", signature, timestamp, payload,
+				sentAt.Add(5*time.Minute+time.Second), 5*time.Minute)).ShouldNot(Succeed())
+			Ω(webhooks.VerifyTimestampedSignature("secret", signature, timestamp, payload,
+				sentAt.Add(-5*time.Minute-time.Second), 5*time.Minute)).ShouldNot(Succeed())
+		})
+
+		It("Should reject other timestamps, untimestamped signatures and missing timestamps", func() {
+			signature := webhooks.SignTimestamped("secret", sentAt.Unix(), payload)
+			later := strconv.FormatInt(sentAt.Unix()+1, 10)
+
+			Ω(webhooks.VerifyTimestampedSignature("secret", signature, later, payload, sentAt,
+				time.Minute)).ShouldNot(Succeed())
+			Ω(webhooks.VerifyTimestampedSignature("secret", webhooks.Sign("secret", payload), timestamp, payload,
+				sentAt, time.Minute)).ShouldNot(Succeed())
+			Ω(webhooks.VerifyTimestampedSignature("secret", signature, "", payload, sentAt,
+				time.Minute)).ShouldNot(Succeed())
+			Ω(webhooks.VerifyTimestampedSignature("", webhooks.SignTimestamped("", sentAt.Unix(), payload), timestamp,
+				payload, sentAt, time.Minute)).ShouldNot(Succeed())
+		})
+	})
+
+	Context("When VerifyToken called", func() {
+		It("Should accept the secret itself only", func() {
+			Ω(webhooks.VerifyToken("secret", "secret")).Should(Succeed())
+			Ω(webhooks.VerifyToken("secret", "Secret")).ShouldNot(Succeed())
+			Ω(webhooks.VerifyToken("", "")).ShouldNot(Succeed())
+		})
+	})
+})